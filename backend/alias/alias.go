@@ -41,5 +41,6 @@ func NewFs(name, root string) (fs.Fs, error) {
 	}
 
 	root = filepath.ToSlash(root)
-	return fsInfo.NewFs(configName, path.Join(fsPath, root))
+	f, err := fsInfo.NewFs(configName, path.Join(fsPath, root))
+	return fs.Restrict(configName, f), err
 }
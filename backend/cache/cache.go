@@ -6,10 +6,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"os/signal"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -20,10 +22,12 @@ import (
 	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/config/obscure"
+	"github.com/ncw/rclone/fs/filter"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/rc"
 	"github.com/ncw/rclone/fs/walk"
 	"github.com/ncw/rclone/lib/atexit"
+	"github.com/ncw/rclone/lib/evict"
 	"github.com/pkg/errors"
 	"golang.org/x/time/rate"
 )
@@ -51,6 +55,8 @@ const (
 	DefCacheTmpWaitTime = "15m"
 	// DefCacheDbWaitTime defines how long the cache backend should wait for the DB to be available
 	DefCacheDbWaitTime = 1 * time.Second
+	// DefCacheMinFreeSpace is the free space chunks are removed to keep on the disk
+	DefCacheMinFreeSpace = "off"
 )
 
 // Globals
@@ -71,6 +77,8 @@ var (
 	cacheTempWritePath      = flags.StringP("cache-tmp-upload-path", "", "", "Directory to keep temporary files until they are uploaded to the cloud storage")
 	cacheTempWaitTime       = flags.StringP("cache-tmp-wait-time", "", DefCacheTmpWaitTime, "How long should files be stored in local cache before being uploaded")
 	cacheDbWaitTime         = flags.DurationP("cache-db-wait-time", "", DefCacheDbWaitTime, "How long to wait for the DB to be available - 0 is unlimited")
+	cacheMinFreeSpace       = flags.StringP("cache-min-free-space", "", DefCacheMinFreeSpace, "Remove the oldest chunks until the disk has this much free")
+	cachePin                = flags.StringArrayP("cache-pin", "", nil, "Never remove chunks of files matching this glob")
 )
 
 // Register with Fs
@@ -79,6 +87,8 @@ func init() {
 		Name:        "cache",
 		Description: "Cache a remote",
 		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
 		Options: []fs.Option{{
 			Name: "remote",
 			Help: "Remote to cache.\nNormally should contain a ':' and a path, eg \"myremote:path/to/dir\",\n\"myremote:bucket\" or maybe \"myremote:\" (not recommended).",
@@ -143,6 +153,22 @@ func init() {
 				},
 			},
 			Optional: true,
+		}, {
+			Name: "min_free_space",
+			Help: "The minimum free space to keep on the disk holding the chunks. When the free space falls below this, the oldest chunks will be deleted. \nDefault: " + DefCacheMinFreeSpace,
+			Examples: []fs.OptionExample{
+				{
+					Value: "off",
+					Help:  "Don't check the free space",
+				}, {
+					Value: "1G",
+					Help:  "1 GB",
+				}, {
+					Value: "10G",
+					Help:  "10 GB",
+				},
+			},
+			Optional: true,
 		}},
 	})
 }
@@ -160,6 +186,8 @@ type Fs struct {
 	fileAge            time.Duration
 	chunkSize          int64
 	chunkTotalSize     int64
+	minFreeSpace       int64
+	pin                []string
 	chunkCleanInterval time.Duration
 	readRetries        int
 	totalWorkers       int
@@ -193,6 +221,9 @@ func NewFs(name, rootPath string) (fs.Fs, error) {
 	if strings.HasPrefix(remote, name+":") {
 		return nil, errors.New("can't point cache remote at itself - check the value of the remote setting")
 	}
+	if fs.Config.NoSpool {
+		return nil, errors.Wrap(fs.ErrorSpoolingNotAllowed, "the cache remote stores the data it reads and writes on local disk")
+	}
 
 	rpath, err := parseRootPath(rootPath)
 	if err != nil {
@@ -230,6 +261,15 @@ func NewFs(name, rootPath string) (fs.Fs, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to understand chunk total size %v", chunkTotalSizeString)
 	}
+	var minFreeSpace fs.SizeSuffix
+	minFreeSpaceString := config.FileGet(name, "min_free_space", DefCacheMinFreeSpace)
+	if *cacheMinFreeSpace != DefCacheMinFreeSpace {
+		minFreeSpaceString = *cacheMinFreeSpace
+	}
+	err = minFreeSpace.Set(minFreeSpaceString)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to understand min free space %v", minFreeSpaceString)
+	}
 	chunkCleanIntervalStr := *cacheChunkCleanInterval
 	chunkCleanInterval, err := time.ParseDuration(chunkCleanIntervalStr)
 	if err != nil {
@@ -258,6 +298,8 @@ func NewFs(name, rootPath string) (fs.Fs, error) {
 		fileAge:            infoDuration,
 		chunkSize:          int64(chunkSize),
 		chunkTotalSize:     int64(chunkTotalSize),
+		minFreeSpace:       int64(minFreeSpace),
+		pin:                *cachePin,
 		chunkCleanInterval: chunkCleanInterval,
 		readRetries:        *cacheReadRetries,
 		totalWorkers:       *cacheTotalWorkers,
@@ -353,6 +395,7 @@ func NewFs(name, rootPath string) (fs.Fs, error) {
 	fs.Infof(name, "Chunk Memory: %v", f.chunkMemory)
 	fs.Infof(name, "Chunk Size: %v", fs.SizeSuffix(f.chunkSize))
 	fs.Infof(name, "Chunk Total Size: %v", fs.SizeSuffix(f.chunkTotalSize))
+	fs.Infof(name, "Min Free Space: %v", fs.SizeSuffix(f.minFreeSpace))
 	fs.Infof(name, "Chunk Clean Interval: %v", f.chunkCleanInterval.String())
 	fs.Infof(name, "Workers: %v", f.totalWorkers)
 	fs.Infof(name, "File Age: %v", f.fileAge.String())
@@ -373,7 +416,7 @@ func NewFs(name, rootPath string) (fs.Fs, error) {
 		fs.Infof(name, "Upload Temp Rest Time: %v", f.tempWriteWait.String())
 		fs.Infof(name, "Upload Temp FS: %v", f.tempWritePath)
 		f.backgroundRunner, _ = initBackgroundUploader(f)
-		go f.backgroundRunner.run()
+		go f.backgroundRunner.run(context.TODO())
 	}
 
 	go func() {
@@ -437,6 +480,27 @@ Eg
 `,
 	})
 
+	rc.Add(rc.Call{
+		Path:  "cache/fetch",
+		Fn:    f.httpFetch,
+		Title: "Fetch files into the cache",
+		Help: `
+Download files into the cache backend ahead of use, eg before a media
+server scans them. Supports either a directory or a file. Directories
+are fetched recursively and the global filters apply.
+Params:
+  - remote = path to remote (required)
+  - include = only fetch files matching this glob (optional)
+  - chunks = number of chunks to fetch from the start of each file (optional, default all)
+  - concurrency = number of files to fetch at once (optional, default --checkers)
+
+Eg
+
+    rclone rc cache/fetch remote=path/to/sub/folder/ chunks=2
+    rclone rc cache/fetch remote=/ include=*.mkv concurrency=2
+`,
+	})
+
 	rc.Add(rc.Call{
 		Path:  "cache/stats",
 		Fn:    f.httpStats,
@@ -449,7 +513,7 @@ Show statistics for the cache remote.
 	return f, fsErr
 }
 
-func (f *Fs) httpStats(in rc.Params) (out rc.Params, err error) {
+func (f *Fs) httpStats(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	out = make(rc.Params)
 	m, err := f.Stats()
 	if err != nil {
@@ -460,7 +524,7 @@ func (f *Fs) httpStats(in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
-func (f *Fs) httpExpireRemote(in rc.Params) (out rc.Params, err error) {
+func (f *Fs) httpExpireRemote(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 	out = make(rc.Params)
 	remoteInt, ok := in["remote"]
 	if !ok {
@@ -514,6 +578,158 @@ func (f *Fs) httpExpireRemote(in rc.Params) (out rc.Params, err error) {
 	return out, nil
 }
 
+// intParam reads the integer parameter key from in returning def if
+// it isn't set
+func intParam(in rc.Params, key string, def int) (int, error) {
+	value, ok := in[key]
+	if !ok {
+		return def, nil
+	}
+	switch x := value.(type) {
+	case string:
+		i, err := strconv.Atoi(x)
+		if err != nil {
+			return 0, errors.Wrapf(err, "couldn't parse %s", key)
+		}
+		return i, nil
+	case float64:
+		return int(x), nil
+	}
+	return 0, errors.Errorf("%s must be an integer, got %T", key, value)
+}
+
+func (f *Fs) httpFetch(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	out = make(rc.Params)
+	remoteInt, ok := in["remote"]
+	if !ok {
+		return out, errors.Errorf("remote is needed")
+	}
+	remote, ok := remoteInt.(string)
+	if !ok {
+		return out, errors.Errorf("remote must be a string")
+	}
+	remote = strings.Trim(remote, "/")
+	chunks, err := intParam(in, "chunks", -1)
+	if err != nil {
+		return out, err
+	}
+	concurrency, err := intParam(in, "concurrency", fs.Config.Checkers)
+	if err != nil {
+		return out, err
+	}
+	if concurrency < 1 {
+		return out, errors.Errorf("concurrency must be at least 1")
+	}
+	include := filter.Active
+	if glob, ok := in["include"].(string); ok && glob != "" {
+		opt := filter.DefaultOpt
+		opt.IncludeRule = []string{glob}
+		include, err = filter.NewFilter(&opt)
+		if err != nil {
+			return out, errors.Wrap(err, "bad include")
+		}
+	}
+
+	// if it's wrapped by crypt we need to check what format we got
+	if cryptFs, yes := f.isWrappedByCrypt(); yes {
+		_, err := cryptFs.DecryptFileName(remote)
+		// if it failed to decrypt then it is a decrypted format and we need to encrypt it
+		if err != nil {
+			remote = cryptFs.EncryptFileName(remote)
+		}
+	}
+
+	var objects []fs.Object
+	if o, err := f.NewObject(ctx, remote); err == nil {
+		objects = append(objects, o)
+	} else {
+		err = walk.Walk(ctx, f, remote, false, fs.Config.MaxDepth, func(dir string, entries fs.DirEntries, err error) error {
+			if err != nil {
+				return err
+			}
+			entries.ForObject(func(o fs.Object) {
+				if include.IncludeObject(o) {
+					objects = append(objects, o)
+				}
+			})
+			return nil
+		})
+		if err != nil {
+			return out, errors.WithMessage(err, "error listing files to fetch")
+		}
+	}
+
+	limit := int64(-1)
+	if chunks >= 0 {
+		limit = int64(chunks) * f.chunkSize
+	}
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		fetched    int
+		totalBytes int64
+		errs       int
+		lastErr    error
+		toFetch    = make(chan fs.Object, concurrency)
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for o := range toFetch {
+				n, err := f.fetchObject(ctx, o, limit)
+				mu.Lock()
+				totalBytes += n
+				if err != nil {
+					fs.Errorf(o, "failed to fetch into cache: %v", err)
+					errs++
+					lastErr = err
+				} else {
+					fetched++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, o := range objects {
+		toFetch <- o
+	}
+	close(toFetch)
+	wg.Wait()
+
+	out["files"] = fetched
+	out["bytes"] = totalBytes
+	out["errors"] = errs
+	if lastErr != nil {
+		return out, errors.Wrapf(lastErr, "failed to fetch %d files", errs)
+	}
+	out["status"] = "ok"
+	return out, nil
+}
+
+// fetchObject reads up to limit bytes of o, or all of it if limit is
+// < 0, so the chunks are stored in the cache
+func (f *Fs) fetchObject(ctx context.Context, o fs.Object, limit int64) (n int64, err error) {
+	if limit == 0 {
+		return 0, nil
+	}
+	fs.Debugf(o, "fetching into cache")
+	in, err := o.Open(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var r io.Reader = in
+	if limit > 0 {
+		r = io.LimitReader(in, limit)
+	}
+	n, err = io.Copy(ioutil.Discard, r)
+	closeErr := in.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
 // receiveChangeNotify is a wrapper to notifications sent from the wrapped FS about changed files
 func (f *Fs) receiveChangeNotify(forgetPath string, entryType fs.EntryType) {
 	if crypt, yes := f.isWrappedByCrypt(); yes {
@@ -629,7 +845,7 @@ func (f *Fs) TempUploadWaitTime() time.Duration {
 }
 
 // NewObject finds the Object at remote.
-func (f *Fs) NewObject(remote string) (fs.Object, error) {
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 	var err error
 
 	fs.Debugf(f, "new object '%s'", remote)
@@ -649,16 +865,16 @@ func (f *Fs) NewObject(remote string) (fs.Object, error) {
 	var obj fs.Object
 	err = nil
 	if f.tempWritePath != "" {
-		obj, err = f.tempFs.NewObject(remote)
+		obj, err = f.tempFs.NewObject(ctx, remote)
 		// not found in temp fs
 		if err != nil {
 			fs.Debugf(remote, "find: not found in local cache fs")
-			obj, err = f.Fs.NewObject(remote)
+			obj, err = f.Fs.NewObject(ctx, remote)
 		} else {
 			fs.Debugf(obj, "find: found in local cache fs")
 		}
 	} else {
-		obj, err = f.Fs.NewObject(remote)
+		obj, err = f.Fs.NewObject(ctx, remote)
 	}
 
 	// not found in either fs
@@ -674,7 +890,7 @@ func (f *Fs) NewObject(remote string) (fs.Object, error) {
 }
 
 // List the objects and directories in dir into entries
-func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	fs.Debugf(f, "list '%s'", dir)
 	cd := ShallowDirectory(f, dir)
 
@@ -705,7 +921,7 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 			fs.Debugf(dir, "list: temp fs entries: %v", queuedEntries)
 
 			for _, queuedRemote := range queuedEntries {
-				queuedEntry, err := f.tempFs.NewObject(f.cleanRootFromPath(queuedRemote))
+				queuedEntry, err := f.tempFs.NewObject(ctx, f.cleanRootFromPath(queuedRemote))
 				if err != nil {
 					fs.Debugf(dir, "list: temp file not found in local fs: %v", err)
 					continue
@@ -718,7 +934,7 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 	}
 
 	// search from the source
-	entries, err = f.Fs.List(dir)
+	entries, err = f.Fs.List(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
@@ -773,8 +989,8 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 	return cachedEntries, nil
 }
 
-func (f *Fs) recurse(dir string, list *walk.ListRHelper) error {
-	entries, err := f.List(dir)
+func (f *Fs) recurse(ctx context.Context, dir string, list *walk.ListRHelper) error {
+	entries, err := f.List(ctx, dir)
 	if err != nil {
 		return err
 	}
@@ -782,7 +998,7 @@ func (f *Fs) recurse(dir string, list *walk.ListRHelper) error {
 	for i := 0; i < len(entries); i++ {
 		innerDir, ok := entries[i].(fs.Directory)
 		if ok {
-			err := f.recurse(innerDir.Remote(), list)
+			err := f.recurse(ctx, innerDir.Remote(), list)
 			if err != nil {
 				return err
 			}
@@ -799,14 +1015,14 @@ func (f *Fs) recurse(dir string, list *walk.ListRHelper) error {
 
 // ListR lists the objects and directories of the Fs starting
 // from dir recursively into out.
-func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
+func (f *Fs) ListR(ctx context.Context, dir string, callback fs.ListRCallback) (err error) {
 	fs.Debugf(f, "list recursively from '%s'", dir)
 
 	// we check if the source FS supports ListR
 	// if it does, we'll use that to get all the entries, cache them and return
 	do := f.Fs.Features().ListR
 	if do != nil {
-		return do(dir, func(entries fs.DirEntries) error {
+		return do(ctx, dir, func(entries fs.DirEntries) error {
 			// we got called back with a set of entries so let's cache them and call the original callback
 			for _, entry := range entries {
 				switch o := entry.(type) {
@@ -826,7 +1042,7 @@ func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
 
 	// if we're here, we're gonna do a standard recursive traversal and cache everything
 	list := walk.NewListRHelper(callback)
-	err = f.recurse(dir, list)
+	err = f.recurse(ctx, dir, list)
 	if err != nil {
 		return err
 	}
@@ -835,9 +1051,9 @@ func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
 }
 
 // Mkdir makes the directory (container, bucket)
-func (f *Fs) Mkdir(dir string) error {
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
 	fs.Debugf(f, "mkdir '%s'", dir)
-	err := f.Fs.Mkdir(dir)
+	err := f.Fs.Mkdir(ctx, dir)
 	if err != nil {
 		return err
 	}
@@ -865,7 +1081,7 @@ func (f *Fs) Mkdir(dir string) error {
 }
 
 // Rmdir removes the directory (container, bucket) if empty
-func (f *Fs) Rmdir(dir string) error {
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 	fs.Debugf(f, "rmdir '%s'", dir)
 
 	if f.tempWritePath != "" {
@@ -875,9 +1091,9 @@ func (f *Fs) Rmdir(dir string) error {
 
 		// we check if the source exists on the remote and make the same move on it too if it does
 		// otherwise, we skip this step
-		_, err := f.UnWrap().List(dir)
+		_, err := f.UnWrap().List(ctx, dir)
 		if err == nil {
-			err := f.Fs.Rmdir(dir)
+			err := f.Fs.Rmdir(ctx, dir)
 			if err != nil {
 				return err
 			}
@@ -885,7 +1101,7 @@ func (f *Fs) Rmdir(dir string) error {
 		}
 
 		var queuedEntries []*Object
-		err = walk.Walk(f.tempFs, dir, true, -1, func(path string, entries fs.DirEntries, err error) error {
+		err = walk.Walk(ctx, f.tempFs, dir, true, -1, func(path string, entries fs.DirEntries, err error) error {
 			for _, o := range entries {
 				if oo, ok := o.(fs.Object); ok {
 					co := ObjectFromOriginal(f, oo)
@@ -905,7 +1121,7 @@ func (f *Fs) Rmdir(dir string) error {
 			}
 		}
 	} else {
-		err := f.Fs.Rmdir(dir)
+		err := f.Fs.Rmdir(ctx, dir)
 		if err != nil {
 			return err
 		}
@@ -936,7 +1152,7 @@ func (f *Fs) Rmdir(dir string) error {
 
 // DirMove moves src, srcRemote to this remote at dstRemote
 // using server side move operations.
-func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
+func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
 	fs.Debugf(f, "move dir '%s'/'%s' -> '%s'/'%s'", src.Root(), srcRemote, f.Root(), dstRemote)
 
 	do := f.Fs.Features().DirMove
@@ -958,8 +1174,8 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 		f.backgroundRunner.pause()
 		defer f.backgroundRunner.play()
 
-		_, errInWrap := srcFs.UnWrap().List(srcRemote)
-		_, errInTemp := f.tempFs.List(srcRemote)
+		_, errInWrap := srcFs.UnWrap().List(ctx, srcRemote)
+		_, errInTemp := f.tempFs.List(ctx, srcRemote)
 		// not found in either fs
 		if errInWrap != nil && errInTemp != nil {
 			return fs.ErrorDirNotFound
@@ -968,7 +1184,7 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 		// we check if the source exists on the remote and make the same move on it too if it does
 		// otherwise, we skip this step
 		if errInWrap == nil {
-			err := do(srcFs.UnWrap(), srcRemote, dstRemote)
+			err := do(ctx, srcFs.UnWrap(), srcRemote, dstRemote)
 			if err != nil {
 				return err
 			}
@@ -981,7 +1197,7 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 		}
 
 		var queuedEntries []*Object
-		err := walk.Walk(f.tempFs, srcRemote, true, -1, func(path string, entries fs.DirEntries, err error) error {
+		err := walk.Walk(ctx, f.tempFs, srcRemote, true, -1, func(path string, entries fs.DirEntries, err error) error {
 			for _, o := range entries {
 				if oo, ok := o.(fs.Object); ok {
 					co := ObjectFromOriginal(f, oo)
@@ -1005,16 +1221,16 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 			fs.Errorf(srcRemote, "dirmove: can't move dir in temp fs")
 			return fs.ErrorCantDirMove
 		}
-		err = do(f.tempFs, srcRemote, dstRemote)
+		err = do(ctx, f.tempFs, srcRemote, dstRemote)
 		if err != nil {
 			return err
 		}
-		err = f.cache.ReconcileTempUploads(f)
+		err = f.cache.ReconcileTempUploads(ctx, f)
 		if err != nil {
 			return err
 		}
 	} else {
-		err := do(srcFs.UnWrap(), srcRemote, dstRemote)
+		err := do(ctx, srcFs.UnWrap(), srcRemote, dstRemote)
 		if err != nil {
 			return err
 		}
@@ -1120,10 +1336,10 @@ func (f *Fs) cacheReader(u io.Reader, src fs.ObjectInfo, originalRead func(inn i
 	}
 }
 
-type putFn func(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
+type putFn func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
 
 // put in to the remote path
-func (f *Fs) put(in io.Reader, src fs.ObjectInfo, options []fs.OpenOption, put putFn) (fs.Object, error) {
+func (f *Fs) put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options []fs.OpenOption, put putFn) (fs.Object, error) {
 	var err error
 	var obj fs.Object
 
@@ -1134,7 +1350,7 @@ func (f *Fs) put(in io.Reader, src fs.ObjectInfo, options []fs.OpenOption, put p
 		_ = f.cache.ExpireDir(parentCd)
 		f.notifyChangeUpstreamIfNeeded(parentCd.Remote(), fs.EntryDirectory)
 
-		obj, err = f.tempFs.Put(in, src, options...)
+		obj, err = f.tempFs.Put(ctx, in, src, options...)
 		if err != nil {
 			fs.Errorf(obj, "put: failed to upload in temp fs: %v", err)
 			return nil, err
@@ -1149,14 +1365,14 @@ func (f *Fs) put(in io.Reader, src fs.ObjectInfo, options []fs.OpenOption, put p
 		// if cache writes is enabled write it first through cache
 	} else if f.cacheWrites {
 		f.cacheReader(in, src, func(inn io.Reader) {
-			obj, err = put(inn, src, options...)
+			obj, err = put(ctx, inn, src, options...)
 		})
 		if err == nil {
 			fs.Debugf(obj, "put: uploaded to remote fs and saved in cache")
 		}
 		// last option: save it directly in remote fs
 	} else {
-		obj, err = put(in, src, options...)
+		obj, err = put(ctx, in, src, options...)
 		if err == nil {
 			fs.Debugf(obj, "put: uploaded to remote fs")
 		}
@@ -1185,33 +1401,33 @@ func (f *Fs) put(in io.Reader, src fs.ObjectInfo, options []fs.OpenOption, put p
 }
 
 // Put in to the remote path with the modTime given of the given size
-func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	fs.Debugf(f, "put data at '%s'", src.Remote())
-	return f.put(in, src, options, f.Fs.Put)
+	return f.put(ctx, in, src, options, f.Fs.Put)
 }
 
 // PutUnchecked uploads the object
-func (f *Fs) PutUnchecked(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) PutUnchecked(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	do := f.Fs.Features().PutUnchecked
 	if do == nil {
 		return nil, errors.New("can't PutUnchecked")
 	}
 	fs.Debugf(f, "put data unchecked in '%s'", src.Remote())
-	return f.put(in, src, options, do)
+	return f.put(ctx, in, src, options, do)
 }
 
 // PutStream uploads the object
-func (f *Fs) PutStream(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	do := f.Fs.Features().PutStream
 	if do == nil {
 		return nil, errors.New("can't PutStream")
 	}
 	fs.Debugf(f, "put data streaming in '%s'", src.Remote())
-	return f.put(in, src, options, do)
+	return f.put(ctx, in, src, options, do)
 }
 
 // Copy src to this remote using server side copy operations.
-func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	fs.Debugf(f, "copy obj '%s' -> '%s'", src, remote)
 
 	do := f.Fs.Features().Copy
@@ -1231,7 +1447,7 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 		return nil, fs.ErrorCantCopy
 	}
 	// refresh from source or abort
-	if err := srcObj.refreshFromSource(false); err != nil {
+	if err := srcObj.refreshFromSource(ctx, false); err != nil {
 		fs.Errorf(f, "can't copy %v - %v", src, err)
 		return nil, fs.ErrorCantCopy
 	}
@@ -1250,7 +1466,7 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 		}
 	}
 
-	obj, err := do(srcObj.Object, remote)
+	obj, err := do(ctx, srcObj.Object, remote)
 	if err != nil {
 		fs.Errorf(srcObj, "error moving in cache: %v", err)
 		return nil, err
@@ -1285,7 +1501,7 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 }
 
 // Move src to this remote using server side move operations.
-func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	fs.Debugf(f, "moving obj '%s' -> %s", src, remote)
 
 	// if source fs doesn't support move abort
@@ -1306,7 +1522,7 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 		return nil, fs.ErrorCantMove
 	}
 	// refresh from source or abort
-	if err := srcObj.refreshFromSource(false); err != nil {
+	if err := srcObj.refreshFromSource(ctx, false); err != nil {
 		fs.Errorf(f, "can't move %v - %v", src, err)
 		return nil, fs.ErrorCantMove
 	}
@@ -1342,7 +1558,7 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 		fs.Debugf(srcObj, "move: queued file moved to %v", remote)
 	}
 
-	obj, err := do(srcObj.Object, remote)
+	obj, err := do(ctx, srcObj.Object, remote)
 	if err != nil {
 		fs.Errorf(srcObj, "error moving: %v", err)
 		return nil, err
@@ -1389,7 +1605,7 @@ func (f *Fs) Hashes() hash.Set {
 }
 
 // Purge all files in the root and the root directory
-func (f *Fs) Purge() error {
+func (f *Fs) Purge(ctx context.Context) error {
 	fs.Infof(f, "purging cache")
 	f.cache.Purge()
 
@@ -1398,7 +1614,7 @@ func (f *Fs) Purge() error {
 		return nil
 	}
 
-	err := do()
+	err := do(ctx)
 	if err != nil {
 		return err
 	}
@@ -1407,7 +1623,7 @@ func (f *Fs) Purge() error {
 }
 
 // CleanUp the trash in the Fs
-func (f *Fs) CleanUp() error {
+func (f *Fs) CleanUp(ctx context.Context) error {
 	f.CleanUpCache(false)
 
 	do := f.Fs.Features().CleanUp
@@ -1415,16 +1631,16 @@ func (f *Fs) CleanUp() error {
 		return nil
 	}
 
-	return do()
+	return do(ctx)
 }
 
 // About gets quota information from the Fs
-func (f *Fs) About() (*fs.Usage, error) {
+func (f *Fs) About(ctx context.Context) (*fs.Usage, error) {
 	do := f.Fs.Features().About
 	if do == nil {
 		return nil, errors.New("About not supported")
 	}
-	return do()
+	return do(ctx)
 }
 
 // Stats returns stats about the cache storage
@@ -1456,7 +1672,11 @@ func (f *Fs) CleanUpCache(ignoreLastTs bool) {
 	defer f.cleanupMu.Unlock()
 
 	if ignoreLastTs || time.Now().After(f.lastChunkCleanup.Add(f.chunkCleanInterval)) {
-		f.cache.CleanChunksBySize(f.chunkTotalSize)
+		f.cache.CleanChunksByPolicy(&evict.Policy{
+			MaxSize:      f.chunkTotalSize,
+			MinFreeSpace: f.minFreeSpace,
+			Pin:          f.pin,
+		})
 		f.lastChunkCleanup = time.Now()
 	}
 }
@@ -3,6 +3,7 @@
 package cache
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"sync"
@@ -58,7 +59,7 @@ type Handle struct {
 }
 
 // NewObjectHandle returns a new Handle for an existing Object
-func NewObjectHandle(o *Object, cfs *Fs) *Handle {
+func NewObjectHandle(ctx context.Context, o *Object, cfs *Fs) *Handle {
 	r := &Handle{
 		cachedObject:  o,
 		cfs:           cfs,
@@ -74,7 +75,7 @@ func NewObjectHandle(o *Object, cfs *Fs) *Handle {
 	// create a larger buffer to queue up requests
 	r.preloadQueue = make(chan int64, r.cfs.totalWorkers*10)
 	r.confirmReading = make(chan bool)
-	r.startReadWorkers()
+	r.startReadWorkers(ctx)
 	return r
 }
 
@@ -94,7 +95,7 @@ func (r *Handle) String() string {
 }
 
 // startReadWorkers will start the worker pool
-func (r *Handle) startReadWorkers() {
+func (r *Handle) startReadWorkers(ctx context.Context) {
 	if r.hasAtLeastOneWorker() {
 		return
 	}
@@ -112,11 +113,11 @@ func (r *Handle) startReadWorkers() {
 		}
 	}
 
-	r.scaleWorkers(totalWorkers)
+	r.scaleWorkers(ctx, totalWorkers)
 }
 
 // scaleOutWorkers will increase the worker pool count by the provided amount
-func (r *Handle) scaleWorkers(desired int) {
+func (r *Handle) scaleWorkers(ctx context.Context, desired int) {
 	current := len(r.workers)
 	if current == desired {
 		return
@@ -134,7 +135,7 @@ func (r *Handle) scaleWorkers(desired int) {
 				ch: r.preloadQueue,
 				id: current + i,
 			}
-			go w.run()
+			go w.run(ctx)
 
 			r.workers = append(r.workers, w)
 		}
@@ -145,7 +146,7 @@ func (r *Handle) scaleWorkers(desired int) {
 	}
 }
 
-func (r *Handle) confirmExternalReading() {
+func (r *Handle) confirmExternalReading(ctx context.Context) {
 	// if we have a max value of workers
 	// or there's no external confirmation available
 	// then we skip this step
@@ -157,17 +158,17 @@ func (r *Handle) confirmExternalReading() {
 		return
 	}
 	fs.Infof(r, "confirmed reading by external reader")
-	r.scaleWorkers(r.cacheFs().totalMaxWorkers)
+	r.scaleWorkers(ctx, r.cacheFs().totalMaxWorkers)
 }
 
 // queueOffset will send an offset to the workers if it's different from the last one
-func (r *Handle) queueOffset(offset int64) {
+func (r *Handle) queueOffset(ctx context.Context, offset int64) {
 	if offset != r.preloadOffset {
 		// clean past in-memory chunks
 		if r.UseMemory {
 			go r.memory.CleanChunksByNeed(offset)
 		}
-		r.confirmExternalReading()
+		r.confirmExternalReading(ctx)
 		r.preloadOffset = offset
 
 		// clear the past seen chunks
@@ -207,7 +208,7 @@ func (r *Handle) hasAtLeastOneWorker() bool {
 // getChunk is called by the FS to retrieve a specific chunk of known start and size from where it can find it
 // it can be from transient or persistent cache
 // it will also build the chunk from the cache's specific chunk boundaries and build the final desired chunk in a buffer
-func (r *Handle) getChunk(chunkStart int64) ([]byte, error) {
+func (r *Handle) getChunk(ctx context.Context, chunkStart int64) ([]byte, error) {
 	var data []byte
 	var err error
 
@@ -216,7 +217,7 @@ func (r *Handle) getChunk(chunkStart int64) ([]byte, error) {
 
 	// we align the start offset of the first chunk to a likely chunk in the storage
 	chunkStart = chunkStart - offset
-	r.queueOffset(chunkStart)
+	r.queueOffset(ctx, chunkStart)
 	found := false
 
 	if r.UseMemory {
@@ -280,7 +281,7 @@ func (r *Handle) Read(p []byte) (n int, err error) {
 		return 0, io.EOF
 	}
 	currentOffset := r.offset
-	buf, err = r.getChunk(currentOffset)
+	buf, err = r.getChunk(context.TODO(), currentOffset)
 	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		fs.Errorf(r, "(%v/%v) error (%v) response", currentOffset, r.cachedObject.Size(), err)
 	}
@@ -343,7 +344,7 @@ func (r *Handle) Seek(offset int64, whence int) (int64, error) {
 	if chunkStart >= r.cacheFs().chunkSize {
 		chunkStart = chunkStart - r.cacheFs().chunkSize
 	}
-	r.queueOffset(chunkStart)
+	r.queueOffset(context.TODO(), chunkStart)
 
 	return r.offset, err
 }
@@ -366,12 +367,12 @@ func (w *worker) String() string {
 //   - if it supports seeking it will seek to the desired offset and return the same reader
 //   - if it doesn't support seeking it will close a possible existing one and open at the desired offset
 //   - if there's no reader associated with this worker, it will create one
-func (w *worker) reader(offset, end int64, closeOpen bool) (io.ReadCloser, error) {
+func (w *worker) reader(ctx context.Context, offset, end int64, closeOpen bool) (io.ReadCloser, error) {
 	var err error
 	r := w.rc
 	if w.rc == nil {
 		r, err = w.r.cacheFs().openRateLimited(func() (io.ReadCloser, error) {
-			return w.r.cachedObject.Object.Open(&fs.RangeOption{Start: offset, End: end - 1})
+			return w.r.cachedObject.Object.Open(ctx, &fs.RangeOption{Start: offset, End: end - 1})
 		})
 		if err != nil {
 			return nil, err
@@ -391,7 +392,7 @@ func (w *worker) reader(offset, end int64, closeOpen bool) (io.ReadCloser, error
 
 	_ = w.rc.Close()
 	return w.r.cacheFs().openRateLimited(func() (io.ReadCloser, error) {
-		r, err = w.r.cachedObject.Object.Open(&fs.RangeOption{Start: offset, End: end - 1})
+		r, err = w.r.cachedObject.Object.Open(ctx, &fs.RangeOption{Start: offset, End: end - 1})
 		if err != nil {
 			return nil, err
 		}
@@ -412,7 +413,7 @@ func (w *worker) setRunning(f bool) {
 }
 
 // run is the main loop for the worker which receives offsets to preload
-func (w *worker) run() {
+func (w *worker) run(ctx context.Context) {
 	var err error
 	var data []byte
 	defer w.setRunning(false)
@@ -459,11 +460,11 @@ func (w *worker) run() {
 		//	chunkEnd = w.r.cachedObject.Size()
 		//}
 
-		w.download(chunkStart, chunkEnd, 0)
+		w.download(ctx, chunkStart, chunkEnd, 0)
 	}
 }
 
-func (w *worker) download(chunkStart, chunkEnd int64, retry int) {
+func (w *worker) download(ctx context.Context, chunkStart, chunkEnd int64, retry int) {
 	var err error
 	var data []byte
 
@@ -480,15 +481,15 @@ func (w *worker) download(chunkStart, chunkEnd int64, retry int) {
 	if retry > 0 {
 		closeOpen = true
 	}
-	w.rc, err = w.reader(chunkStart, chunkEnd, closeOpen)
+	w.rc, err = w.reader(ctx, chunkStart, chunkEnd, closeOpen)
 	// we seem to be getting only errors so we abort
 	if err != nil {
 		fs.Errorf(w, "object open failed %v: %v", chunkStart, err)
-		err = w.r.cachedObject.refreshFromSource(true)
+		err = w.r.cachedObject.refreshFromSource(ctx, true)
 		if err != nil {
 			fs.Errorf(w, "%v", err)
 		}
-		w.download(chunkStart, chunkEnd, retry+1)
+		w.download(ctx, chunkStart, chunkEnd, retry+1)
 		return
 	}
 
@@ -497,11 +498,11 @@ func (w *worker) download(chunkStart, chunkEnd int64, retry int) {
 	sourceRead, err = io.ReadFull(w.rc, data)
 	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
 		fs.Errorf(w, "failed to read chunk %v: %v", chunkStart, err)
-		err = w.r.cachedObject.refreshFromSource(true)
+		err = w.r.cachedObject.refreshFromSource(ctx, true)
 		if err != nil {
 			fs.Errorf(w, "%v", err)
 		}
-		w.download(chunkStart, chunkEnd, retry+1)
+		w.download(ctx, chunkStart, chunkEnd, retry+1)
 		return
 	}
 	data = data[:sourceRead] // reslice to remove extra garbage
@@ -593,7 +594,7 @@ func (b *backgroundWriter) notify(remote string, status int, err error) {
 	}
 }
 
-func (b *backgroundWriter) run() {
+func (b *backgroundWriter) run(ctx context.Context) {
 	state := 0
 	for {
 		b.mu.Lock()
@@ -623,7 +624,7 @@ func (b *backgroundWriter) run() {
 		remote := b.fs.cleanRootFromPath(absPath)
 		b.notify(remote, BackgroundUploadStarted, nil)
 		fs.Infof(remote, "background upload: started upload")
-		err = operations.MoveFile(b.fs.UnWrap(), b.fs.tempFs, remote, remote)
+		err = operations.MoveFile(ctx, b.fs.UnWrap(), b.fs.tempFs, remote, remote)
 		if err != nil {
 			b.notify(remote, BackgroundUploadError, err)
 			_ = b.fs.cache.rollbackPendingUpload(absPath)
@@ -3,6 +3,7 @@
 package cache
 
 import (
+	"context"
 	"time"
 
 	"bytes"
@@ -21,6 +22,7 @@ import (
 	bolt "github.com/coreos/bbolt"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/walk"
+	"github.com/ncw/rclone/lib/evict"
 	"github.com/pkg/errors"
 )
 
@@ -89,6 +91,8 @@ type Persistent struct {
 	cleanupMux   sync.Mutex
 	tempQueueMux sync.Mutex
 	features     *Features
+	accessMu     sync.Mutex           // protects accessed
+	accessed     map[string]time.Time // when chunks were last read, by chunk path, since the last cleanup
 }
 
 // newPersistent builds a new wrapper and connects to the bolt.DB file
@@ -97,6 +101,7 @@ func newPersistent(dbPath, chunkPath string, f *Features) (*Persistent, error) {
 		dbPath:   dbPath,
 		dataPath: chunkPath,
 		features: f,
+		accessed: make(map[string]time.Time),
 	}
 
 	err := b.connect()
@@ -449,12 +454,18 @@ func (b *Persistent) HasChunk(cachedObject *Object, offset int64) bool {
 func (b *Persistent) GetChunk(cachedObject *Object, offset int64) ([]byte, error) {
 	var data []byte
 
-	fp := path.Join(b.dataPath, cachedObject.abs(), strconv.FormatInt(offset, 10))
-	data, err := ioutil.ReadFile(fp)
+	name := path.Join(cachedObject.abs(), strconv.FormatInt(offset, 10))
+	data, err := ioutil.ReadFile(path.Join(b.dataPath, name))
 	if err != nil {
 		return nil, err
 	}
 
+	// record the read so the cleanup keeps the chunks used most
+	// recently rather than those stored most recently
+	b.accessMu.Lock()
+	b.accessed[name] = time.Now()
+	b.accessMu.Unlock()
+
 	return data, err
 }
 
@@ -518,55 +529,99 @@ func (b *Persistent) CleanChunksByNeed(offset int64) {
 	// noop: we want to clean a Bolt DB by time only
 }
 
-// CleanChunksBySize will cleanup chunks after the total size passes a certain point
-func (b *Persistent) CleanChunksBySize(maxSize int64) {
+// CleanChunksByPolicy will cleanup the least recently read or stored
+// chunks which aren't pinned until the total size of the chunks and the free
+// space on the disk are within the limits of the policy
+func (b *Persistent) CleanChunksByPolicy(policy *evict.Policy) {
 	b.cleanupMux.Lock()
 	defer b.cleanupMux.Unlock()
 	var cntChunks int
 	var roughlyCleaned fs.SizeSuffix
 
+	// take the reads since the last cleanup
+	b.accessMu.Lock()
+	accessed := b.accessed
+	b.accessed = make(map[string]time.Time)
+	b.accessMu.Unlock()
+
+	free := int64(-1)
+	if policy.MinFreeSpace > 0 {
+		var err error
+		free, err = evict.FreeSpace(b.dataPath)
+		if err != nil {
+			fs.Errorf("cache-cleanup", "ignoring minimum free space: %v", err)
+		}
+	}
+
 	err := b.db.Update(func(tx *bolt.Tx) error {
 		dataTsBucket := tx.Bucket([]byte(DataTsBucket))
 		if dataTsBucket == nil {
 			return errors.Errorf("Couldn't open (%v) bucket", DataTsBucket)
 		}
-		// iterate through ts
+		// iterate through ts collecting the chunks and the ts
+		// entries for each as a chunk may be stored more than once
+		var items []evict.Item
+		index := make(map[string]int)
+		keys := make(map[string][][]byte)
+		infos := make(map[string]chunkInfo)
 		c := dataTsBucket.Cursor()
-		totalSize := int64(0)
 		for k, v := c.First(); k != nil; k, v = c.Next() {
 			var ci chunkInfo
 			err := json.Unmarshal(v, &ci)
 			if err != nil {
 				continue
 			}
-
-			totalSize += ci.Size
+			name := path.Join(ci.Path, strconv.FormatInt(ci.Offset, 10))
+			ts := time.Unix(0, btoi(k))
+			keys[name] = append(keys[name], append([]byte(nil), k...))
+			if i, ok := index[name]; ok {
+				items[i].ATime = ts
+				continue
+			}
+			index[name] = len(items)
+			infos[name] = ci
+			items = append(items, evict.Item{Name: name, Size: ci.Size, ATime: ts})
 		}
 
-		if totalSize > maxSize {
-			needToClean := totalSize - maxSize
-			roughlyCleaned = fs.SizeSuffix(needToClean)
-			for k, v := c.First(); k != nil; k, v = c.Next() {
-				var ci chunkInfo
-				err := json.Unmarshal(v, &ci)
+		// replace the ts entries of the chunks read since they
+		// were stored with one for the time of the last read
+		for name, at := range accessed {
+			i, ok := index[name]
+			if !ok || !at.After(items[i].ATime) {
+				continue
+			}
+			enc, err := json.Marshal(infos[name])
+			if err != nil {
+				continue
+			}
+			for _, k := range keys[name] {
+				err = dataTsBucket.Delete(k)
 				if err != nil {
-					continue
+					fs.Errorf(name, "failed deleting chunk ts during cleanup: %v", err)
 				}
-				// delete this ts entry
-				err = c.Delete()
+			}
+			k := itob(at.UnixNano())
+			err = dataTsBucket.Put(k, enc)
+			if err != nil {
+				fs.Errorf(name, "failed updating chunk ts during cleanup: %v", err)
+			}
+			keys[name] = [][]byte{k}
+			items[i].ATime = at
+		}
+
+		for _, item := range policy.Choose(items, free) {
+			// delete the ts entries for this chunk
+			for _, k := range keys[item.Name] {
+				err := dataTsBucket.Delete(k)
 				if err != nil {
-					fs.Errorf(ci.Path, "failed deleting chunk ts during cleanup (%v): %v", ci.Offset, err)
-					continue
-				}
-				err = os.Remove(path.Join(b.dataPath, ci.Path, strconv.FormatInt(ci.Offset, 10)))
-				if err == nil {
-					cntChunks++
-					needToClean -= ci.Size
-					if needToClean <= 0 {
-						break
-					}
+					fs.Errorf(item.Name, "failed deleting chunk ts during cleanup: %v", err)
 				}
 			}
+			err := os.Remove(path.Join(b.dataPath, item.Name))
+			if err == nil {
+				cntChunks++
+				roughlyCleaned += fs.SizeSuffix(item.Size)
+			}
 		}
 		if cntChunks > 0 {
 			fs.Infof("cache-cleanup", "chunks %v, est. size: %v", cntChunks, roughlyCleaned.String())
@@ -989,7 +1044,7 @@ func (b *Persistent) SetPendingUploadToStarted(remote string) error {
 }
 
 // ReconcileTempUploads will recursively look for all the files in the temp directory and add them to the queue
-func (b *Persistent) ReconcileTempUploads(cacheFs *Fs) error {
+func (b *Persistent) ReconcileTempUploads(ctx context.Context, cacheFs *Fs) error {
 	return b.db.Update(func(tx *bolt.Tx) error {
 		_ = tx.DeleteBucket([]byte(tempBucket))
 		bucket, err := tx.CreateBucketIfNotExists([]byte(tempBucket))
@@ -998,7 +1053,7 @@ func (b *Persistent) ReconcileTempUploads(cacheFs *Fs) error {
 		}
 
 		var queuedEntries []fs.Object
-		err = walk.Walk(cacheFs.tempFs, "", true, -1, func(path string, entries fs.DirEntries, err error) error {
+		err = walk.Walk(ctx, cacheFs.tempFs, "", true, -1, func(path string, entries fs.DirEntries, err error) error {
 			for _, o := range entries {
 				if oo, ok := o.(fs.Object); ok {
 					queuedEntries = append(queuedEntries, oo)
@@ -0,0 +1,602 @@
+// Package combine provides an Fs which presents several remotes as
+// directories of a single tree
+package combine
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/pkg/errors"
+)
+
+// Register with Fs
+func init() {
+	fs.Register(&fs.RegInfo{
+		Name:        "combine",
+		Description: "Combine several remotes into one directory tree",
+		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
+		Options: []fs.Option{{
+			Name: "upstreams",
+			Help: "Upstreams for combining, separated by spaces, in the form dir=remote:path.\nEg \"photos=opendrive:Photos docs=s3:bucket/docs\".\nQuote an upstream containing spaces, eg \"\\\"My Drive=drive:\\\"\".",
+		}},
+	})
+}
+
+// upstream is a remote which appears as a directory of the Fs
+type upstream struct {
+	dir    string // directory of the Fs this appears as, "" for the root
+	remote string // the remote as configured, eg "s3:bucket/docs"
+	f      fs.Fs  // the Fs for the remote
+}
+
+// parseUpstreams parses the upstreams setting of the remote called
+// name into a map of remotes indexed by directory
+func parseUpstreams(name, upstreams string) (map[string]string, error) {
+	r := csv.NewReader(strings.NewReader(upstreams))
+	r.Comma = ' '
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse upstreams")
+	}
+	remotes := make(map[string]string)
+	for _, record := range records {
+		for _, item := range record {
+			if item == "" {
+				continue
+			}
+			equals := strings.IndexRune(item, '=')
+			if equals < 0 {
+				return nil, errors.Errorf("upstream %q should be in the form dir=remote:path", item)
+			}
+			dir, remote := item[:equals], item[equals+1:]
+			switch {
+			case dir == "" || dir == "." || dir == "..":
+				return nil, errors.Errorf("upstream %q needs a directory name", item)
+			case strings.ContainsRune(dir, '/'):
+				return nil, errors.Errorf("upstream %q directory can't contain \"/\"", item)
+			case remote == "":
+				return nil, errors.Errorf("upstream %q can't point to an empty remote", item)
+			case strings.HasPrefix(remote, name+":"):
+				return nil, errors.Errorf("upstream %q can't point combine remote at itself", item)
+			}
+			if _, found := remotes[dir]; found {
+				return nil, errors.Errorf("duplicate upstream directory %q", dir)
+			}
+			remotes[dir] = remote
+		}
+	}
+	if len(remotes) == 0 {
+		return nil, errors.New("combine needs at least one upstream - check the value of the upstreams setting")
+	}
+	return remotes, nil
+}
+
+// newUpstreamFs makes the Fs for path rpath on remote
+func newUpstreamFs(remote, rpath string) (fs.Fs, error) {
+	fsInfo, configName, fsPath, err := fs.ParseRemote(remote)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fsInfo.NewFs(configName, path.Join(fsPath, rpath))
+	return fs.Restrict(configName, f), err
+}
+
+// NewFs contstructs an Fs from the path.
+//
+// If the root is inside one of the upstreams then only that upstream
+// is used, otherwise the Fs lists the upstreams as its directories.
+func NewFs(name, root string) (fs.Fs, error) {
+	remotes, err := parseUpstreams(name, config.FileGet(name, "upstreams"))
+	if err != nil {
+		return nil, err
+	}
+	root = strings.Trim(path.Clean(root), "/")
+	if root == "." {
+		root = ""
+	}
+	f := &Fs{
+		name:      name,
+		root:      root,
+		upstreams: make(map[string]*upstream, len(remotes)),
+	}
+	var isFileErr error
+	if root == "" {
+		for dir, remote := range remotes {
+			uFs, err := newUpstreamFs(remote, "")
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to make upstream %q", dir)
+			}
+			f.upstreams[dir] = &upstream{dir: dir, remote: remote, f: uFs}
+		}
+	} else {
+		dir, rpath := root, ""
+		if i := strings.IndexRune(root, '/'); i >= 0 {
+			dir, rpath = root[:i], root[i+1:]
+		}
+		remote, found := remotes[dir]
+		if !found {
+			return nil, errors.Errorf("directory %q not found - it must be one of the upstreams", dir)
+		}
+		uFs, err := newUpstreamFs(remote, rpath)
+		if err == fs.ErrorIsFile {
+			// the root is a file so point at the parent directory
+			isFileErr = err
+			f.root = path.Dir(root)
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to make upstream %q", dir)
+		}
+		f.upstreams[""] = &upstream{remote: remote, f: uFs}
+	}
+
+	// the features here are ones we could support, and they are
+	// ANDed with the ones from all the upstreams
+	f.features = (&fs.Features{
+		CaseInsensitive:         true,
+		DuplicateFiles:          true,
+		ReadMimeType:            true,
+		WriteMimeType:           true,
+		CanHaveEmptyDirectories: true,
+		BucketBased:             true,
+	}).Fill(f)
+	var canCopy, canMove, canDirMove, canPutByHash bool
+	for _, u := range f.upstreams {
+		f.features.Mask(u.f)
+		features := u.f.Features()
+		canCopy = canCopy || features.Copy != nil
+		canMove = canMove || features.Move != nil
+		canDirMove = canDirMove || features.DirMove != nil
+		canPutByHash = canPutByHash || features.PutByHash != nil
+	}
+	// server side operations are worth doing if any of the
+	// upstreams supports them - the others return errors so the
+	// operations are done another way
+	if canCopy {
+		f.features.Copy = f.Copy
+	}
+	if canMove {
+		f.features.Move = f.Move
+	}
+	if canDirMove {
+		f.features.DirMove = f.DirMove
+	}
+	if canPutByHash {
+		f.features.PutByHash = f.PutByHash
+	}
+	f.features.DisableList(fs.Config.DisableFeatures)
+	return f, isFileErr
+}
+
+// Fs represents several remotes combined into one tree
+type Fs struct {
+	name      string
+	root      string
+	features  *fs.Features         // optional features
+	upstreams map[string]*upstream // by directory, or "" if root is in one
+}
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	return f.root
+}
+
+// String returns a description of the FS
+func (f *Fs) String() string {
+	return fmt.Sprintf("Combined '%s:%s'", f.name, f.root)
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// Precision of the ModTimes in this Fs - the coarsest of the
+// upstreams
+func (f *Fs) Precision() time.Duration {
+	var precision time.Duration
+	for _, u := range f.upstreams {
+		if p := u.f.Precision(); p > precision {
+			precision = p
+		}
+	}
+	return precision
+}
+
+// Hashes returns the hashes supported by all the upstreams
+func (f *Fs) Hashes() hash.Set {
+	set := hash.Supported
+	for _, u := range f.upstreams {
+		set = set.Overlap(u.f.Hashes())
+	}
+	return set
+}
+
+// find returns the upstream which remote is on and the path of remote
+// on it.
+//
+// It returns ok false if remote isn't on an upstream - either because
+// it is the root listing the upstreams or because there is no upstream
+// for its first directory.
+func (f *Fs) find(remote string) (u *upstream, uRemote string, ok bool) {
+	if u, ok = f.upstreams[""]; ok {
+		return u, remote, true
+	}
+	dir := remote
+	if i := strings.IndexRune(remote, '/'); i >= 0 {
+		dir, uRemote = remote[:i], remote[i+1:]
+	}
+	u, ok = f.upstreams[dir]
+	return u, uRemote, ok
+}
+
+// dirs returns the sorted directories of the upstreams
+func (f *Fs) dirs() []string {
+	dirs := make([]string, 0, len(f.upstreams))
+	for dir := range f.upstreams {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// errorRoot is returned for operations which need an upstream on the
+// root of the Fs
+func (f *Fs) errorRoot(remote string) error {
+	return errors.Errorf("can't use %q - only the directories %s can be used in the root of a combine remote", remote, strings.Join(f.dirs(), ", "))
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	u, uDir, ok := f.find(dir)
+	if !ok {
+		if dir != "" {
+			return nil, fs.ErrorDirNotFound
+		}
+		for _, dir := range f.dirs() {
+			entries = append(entries, fs.NewDir(dir, time.Time{}))
+		}
+		return entries, nil
+	}
+	entries, err = u.f.List(ctx, uDir)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		switch x := entry.(type) {
+		case fs.Object:
+			entries[i] = f.newObject(u, x)
+		case fs.Directory:
+			entries[i] = fs.NewDir(u.path(x.Remote()), x.ModTime()).SetID(x.ID()).SetSize(x.Size()).SetItems(x.Items())
+		default:
+			return nil, errors.Errorf("Unknown object type %T", entry)
+		}
+	}
+	return entries, nil
+}
+
+// NewObject finds the Object at remote.  If it can't be found
+// it returns the error ErrorObjectNotFound.
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	u, uRemote, ok := f.find(remote)
+	if !ok || uRemote == "" && u.dir != "" {
+		return nil, fs.ErrorObjectNotFound
+	}
+	o, err := u.f.NewObject(ctx, uRemote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// findObject returns the upstream for an object to be stored at
+// remote and its path on it
+func (f *Fs) findObject(remote string) (u *upstream, uRemote string, err error) {
+	u, uRemote, ok := f.find(remote)
+	if !ok || uRemote == "" && u.dir != "" {
+		return nil, "", f.errorRoot(remote)
+	}
+	return u, uRemote, nil
+}
+
+// Put in to the remote path with the modTime given of the given size
+//
+// May create the object even if it returns an error - if so
+// will return the object and the error, otherwise will return
+// nil and the error
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	u, uRemote, err := f.findObject(src.Remote())
+	if err != nil {
+		return nil, err
+	}
+	o, err := u.f.Put(ctx, in, newObjectInfo(src, uRemote), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	u, uRemote, err := f.findObject(src.Remote())
+	if err != nil {
+		return nil, err
+	}
+	do := u.f.Features().PutStream
+	if do == nil {
+		return nil, errors.Errorf("upstream %q can't stream uploads", u.remote)
+	}
+	o, err := do(ctx, in, newObjectInfo(src, uRemote), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// PutByHash creates the object from content the upstream already has
+// with the same hash as src
+func (f *Fs) PutByHash(ctx context.Context, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	u, uRemote, err := f.findObject(src.Remote())
+	if err != nil {
+		return nil, err
+	}
+	do := u.f.Features().PutByHash
+	if do == nil {
+		return nil, fs.ErrorCantPutByHash
+	}
+	o, err := do(ctx, newObjectInfo(src, uRemote), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// Mkdir makes the directory (container, bucket)
+//
+// Shouldn't return an error if it already exists
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
+	u, uDir, ok := f.find(dir)
+	if !ok {
+		if dir == "" {
+			return nil
+		}
+		return f.errorRoot(dir)
+	}
+	return u.f.Mkdir(ctx, uDir)
+}
+
+// Rmdir removes the directory (container, bucket) if empty
+//
+// Return an error if it doesn't exist or isn't empty
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	u, uDir, ok := f.find(dir)
+	if !ok {
+		if dir == "" {
+			return errors.New("can't remove the root of a combine remote")
+		}
+		return fs.ErrorDirNotFound
+	}
+	return u.f.Rmdir(ctx, uDir)
+}
+
+// Purge all files in the root and the root directory
+//
+// Implement this if you have a way of deleting all the files
+// quicker than just running Remove() on the result of List()
+//
+// Return an error if it doesn't exist
+func (f *Fs) Purge(ctx context.Context) error {
+	u, ok := f.upstreams[""]
+	if !ok {
+		return fs.ErrorCantPurge
+	}
+	do := u.f.Features().Purge
+	if do == nil {
+		return fs.ErrorCantPurge
+	}
+	return do(ctx)
+}
+
+// Copy src to this remote using server side copy operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantCopy
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		return nil, fs.ErrorCantCopy
+	}
+	u, uRemote, err := f.findObject(remote)
+	if err != nil {
+		return nil, err
+	}
+	do := u.f.Features().Copy
+	if do == nil || srcObj.Object.Fs().Name() != u.f.Name() {
+		return nil, fs.ErrorCantCopy
+	}
+	o, err := do(ctx, srcObj.Object, uRemote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// Move src to this remote using server side move operations.
+//
+// This is stored with the remote path given
+//
+// It returns the destination Object and a possible error
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantMove
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	srcObj, ok := src.(*Object)
+	if !ok {
+		return nil, fs.ErrorCantMove
+	}
+	u, uRemote, err := f.findObject(remote)
+	if err != nil {
+		return nil, err
+	}
+	do := u.f.Features().Move
+	if do == nil || srcObj.Object.Fs().Name() != u.f.Name() {
+		return nil, fs.ErrorCantMove
+	}
+	o, err := do(ctx, srcObj.Object, uRemote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(u, o), nil
+}
+
+// DirMove moves src, srcRemote to this remote at dstRemote
+// using server side move operations.
+//
+// Will only be called if src.Fs().Name() == f.Name()
+//
+// If it isn't possible then return fs.ErrorCantDirMove
+//
+// If destination exists then return fs.ErrorDirExists
+func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
+	srcFs, ok := src.(*Fs)
+	if !ok {
+		fs.Debugf(f, "Can't move directory - not same remote type")
+		return fs.ErrorCantDirMove
+	}
+	srcU, srcURemote, ok := srcFs.find(srcRemote)
+	if !ok || srcURemote == "" && srcU.dir != "" {
+		return fs.ErrorCantDirMove
+	}
+	u, uRemote, ok := f.find(dstRemote)
+	if !ok || uRemote == "" && u.dir != "" {
+		return fs.ErrorCantDirMove
+	}
+	do := u.f.Features().DirMove
+	if do == nil || srcU.f.Name() != u.f.Name() {
+		return fs.ErrorCantDirMove
+	}
+	return do(ctx, srcU.f, srcURemote, uRemote)
+}
+
+// DirCacheFlush resets the directory caches of the upstreams
+func (f *Fs) DirCacheFlush() {
+	for _, u := range f.upstreams {
+		if do := u.f.Features().DirCacheFlush; do != nil {
+			do()
+		}
+	}
+}
+
+// path returns the path in the Fs of uRemote on the upstream
+func (u *upstream) path(uRemote string) string {
+	if u.dir == "" {
+		return uRemote
+	}
+	if uRemote == "" {
+		return u.dir
+	}
+	return u.dir + "/" + uRemote
+}
+
+// ObjectInfo describes a source object with its path on an upstream
+type ObjectInfo struct {
+	fs.ObjectInfo
+	remote string
+}
+
+func newObjectInfo(src fs.ObjectInfo, uRemote string) *ObjectInfo {
+	return &ObjectInfo{
+		ObjectInfo: src,
+		remote:     uRemote,
+	}
+}
+
+// Remote returns the path of the object on the upstream
+func (o *ObjectInfo) Remote() string {
+	return o.remote
+}
+
+// Object describes an object on one of the upstreams
+type Object struct {
+	fs.Object
+	f      *Fs
+	remote string
+}
+
+func (f *Fs) newObject(u *upstream, o fs.Object) *Object {
+	return &Object{
+		Object: o,
+		f:      f,
+		remote: u.path(o.Remote()),
+	}
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *Object) Remote() string {
+	return o.remote
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	return o.Object.Update(ctx, in, newObjectInfo(src, o.Object.Remote()), options...)
+}
+
+// UnWrap returns the wrapped Object
+func (o *Object) UnWrap() fs.Object {
+	return o.Object
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs              = (*Fs)(nil)
+	_ fs.Purger          = (*Fs)(nil)
+	_ fs.PutStreamer     = (*Fs)(nil)
+	_ fs.PutByHasher     = (*Fs)(nil)
+	_ fs.Copier          = (*Fs)(nil)
+	_ fs.Mover           = (*Fs)(nil)
+	_ fs.DirMover        = (*Fs)(nil)
+	_ fs.DirCacheFlusher = (*Fs)(nil)
+	_ fs.Object          = (*Object)(nil)
+	_ fs.ObjectUnWrapper = (*Object)(nil)
+)
@@ -0,0 +1,118 @@
+package combine
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUpstreams(t *testing.T) {
+	remotes, err := parseUpstreams("combine", `photos=opendrive:Photos  docs=s3:bucket/docs "My Drive=drive:"`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"photos":   "opendrive:Photos",
+		"docs":     "s3:bucket/docs",
+		"My Drive": "drive:",
+	}, remotes)
+
+	for _, upstreams := range []string{
+		"",
+		"photos",
+		"=remote:",
+		"photos=",
+		"a/b=remote:",
+		"photos=combine:photos",
+		"photos=a: photos=b:",
+	} {
+		_, err := parseUpstreams("combine", upstreams)
+		assert.Error(t, err, upstreams)
+	}
+}
+
+func TestCombine(t *testing.T) {
+	ctx := context.Background()
+	tempdir, err := ioutil.TempDir("", "rclone-combine-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(tempdir))
+	}()
+	config.FileSet("TestCombineInternal", "type", "combine")
+	config.FileSet("TestCombineInternal", "upstreams", "one="+filepath.Join(tempdir, "one")+" two="+filepath.Join(tempdir, "two"))
+
+	f, err := fs.NewFs("TestCombineInternal:")
+	require.NoError(t, err)
+
+	// the root lists the upstreams
+	entries, err := f.List(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entries))
+	assert.Equal(t, "one", entries[0].Remote())
+	assert.Equal(t, "two", entries[1].Remote())
+	_, err = f.List(ctx, "three")
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+
+	// files can only be put in the upstreams
+	put := func(remote string) (fs.Object, error) {
+		contents := []byte("hello " + remote)
+		src := object.NewStaticObjectInfo(remote, time.Now(), int64(len(contents)), true, nil, nil)
+		return f.Put(ctx, bytes.NewReader(contents), src)
+	}
+	_, err = put("file.txt")
+	assert.Error(t, err)
+	_, err = put("three/file.txt")
+	assert.Error(t, err)
+	o, err := put("one/dir/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "one/dir/file.txt", o.Remote())
+	_, err = os.Stat(filepath.Join(tempdir, "one", "dir", "file.txt"))
+	assert.NoError(t, err)
+
+	// the paths are mapped back from the upstreams
+	entries, err = f.List(ctx, "one/dir")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(entries))
+	assert.Equal(t, "one/dir/file.txt", entries[0].Remote())
+	o, err = f.NewObject(ctx, "one/dir/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "one/dir/file.txt", o.Remote())
+	_, err = f.NewObject(ctx, "one")
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+
+	// moving between upstreams works
+	_, err = operations.Move(ctx, f, nil, "two/moved.txt", o)
+	require.NoError(t, err)
+	_, err = f.NewObject(ctx, "one/dir/file.txt")
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+	o, err = f.NewObject(ctx, "two/moved.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("hello one/dir/file.txt")), o.Size())
+
+	// the root of an upstream uses only that upstream
+	sub, err := fs.NewFs("TestCombineInternal:two")
+	require.NoError(t, err)
+	assert.Equal(t, "two", sub.Root())
+	o, err = sub.NewObject(ctx, "moved.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "moved.txt", o.Remote())
+	sub, err = fs.NewFs("TestCombineInternal:two/moved.txt")
+	assert.Equal(t, fs.ErrorIsFile, err)
+	assert.Equal(t, "two", sub.Root())
+	_, err = fs.NewFs("TestCombineInternal:three")
+	assert.Error(t, err)
+
+	assert.Error(t, f.Mkdir(ctx, "three"))
+	assert.NoError(t, f.Mkdir(ctx, ""))
+	assert.Error(t, f.Rmdir(ctx, ""))
+}
@@ -0,0 +1,26 @@
+// Test Combine filesystem interface
+package combine_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ncw/rclone/backend/combine"
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/fstest/fstests"
+)
+
+// TestIntegration runs integration tests against the remote
+func TestIntegration(t *testing.T) {
+	tempdir := filepath.Join(os.TempDir(), "rclone-combine-test")
+	name := "TestCombine"
+	fstests.Run(t, &fstests.Opt{
+		RemoteName: name + ":dir1",
+		NilObject:  (*combine.Object)(nil),
+		ExtraConfig: []fstests.ExtraConfigItem{
+			{Name: name, Key: "type", Value: "combine"},
+			{Name: name, Key: "upstreams", Value: "dir1=" + filepath.Join(tempdir, "dir1") + " dir2=" + filepath.Join(tempdir, "dir2")},
+		},
+	})
+}
@@ -37,6 +37,10 @@ const (
 	encryptedSuffix     = ".bin" // when file name encryption is off we add this suffix to make sure the cloud provider doesn't process the file
 )
 
+// maxSeekSkip is how far a decrypter seeks forwards by reading the
+// stream it has open rather than opening it again
+const maxSeekSkip = 8 * blockDataSize
+
 // Errors returned by cipher
 var (
 	ErrorBadDecryptUTF8          = errors.New("bad decryption - utf-8 invalid")
@@ -722,6 +726,9 @@ type decrypter struct {
 	err          error
 	limit        int64 // limit of bytes to read, -1 for unlimited
 	open         OpenRangeSeek
+	bufOffset    int64 // offset in the decrypted file of the start of buf
+	nextOffset   int64 // offset in the decrypted file of the next block in rc
+	rcUnlimited  bool  // set if rc reads to the end of the file
 }
 
 // newDecrypter creates a new file handle decrypting on the fly
@@ -758,9 +765,11 @@ func (c *cipher) newDecrypterSeek(open OpenRangeSeek, offset, limit int64) (fh *
 	doRangeSeek := false
 	setLimit := false
 	// Open initially with no seek
+	rcUnlimited := false
 	if offset == 0 && limit < 0 {
 		// If no offset or limit then open whole file
 		rc, err = open(0, -1)
+		rcUnlimited = true
 	} else if offset == 0 {
 		// If no offset open the header + limit worth of the file
 		_, underlyingLimit, _, _ := calculateUnderlying(offset, limit)
@@ -780,6 +789,7 @@ func (c *cipher) newDecrypterSeek(open OpenRangeSeek, offset, limit int64) (fh *
 		return nil, err
 	}
 	fh.open = open // will be called by fh.RangeSeek
+	fh.rcUnlimited = rcUnlimited
 	if doRangeSeek {
 		_, err = fh.RangeSeek(offset, io.SeekStart, limit)
 		if err != nil {
@@ -824,6 +834,8 @@ func (fh *decrypter) fillBuffer() (err error) {
 	}
 	fh.bufIndex = 0
 	fh.bufSize = n - blockHeaderSize
+	fh.bufOffset = fh.nextOffset
+	fh.nextOffset += blockDataSize
 	fh.nonce.increment()
 	return nil
 }
@@ -917,6 +929,9 @@ func (fh *decrypter) RangeSeek(offset int64, whence int, limit int64) (int64, er
 		fh.unFinish()
 	} else if fh.err != nil {
 		return 0, fh.err
+	} else if limit < 0 && fh.seekInStream(offset) {
+		fh.limit = limit
+		return offset, nil
 	}
 
 	underlyingOffset, underlyingLimit, discard, blocks := calculateUnderlying(offset, limit)
@@ -946,6 +961,8 @@ func (fh *decrypter) RangeSeek(offset int64, whence int, limit int64) (int64, er
 		// Set the file handle
 		fh.rc = rc
 	}
+	fh.nextOffset = blocks * blockDataSize
+	fh.rcUnlimited = underlyingLimit < 0
 
 	// Fill the buffer
 	err := fh.fillBuffer()
@@ -965,6 +982,28 @@ func (fh *decrypter) RangeSeek(offset int64, whence int, limit int64) (int64, er
 	return offset, nil
 }
 
+// seekInStream seeks to offset without reopening the underlying
+// stream if offset is in the block already decrypted or a short way
+// after it.  It returns false if the stream needs reopening.
+//
+// Call with fh.mu held
+func (fh *decrypter) seekInStream(offset int64) bool {
+	if !fh.rcUnlimited || offset < fh.bufOffset || offset-fh.nextOffset >= maxSeekSkip {
+		return false
+	}
+	// Read and decrypt blocks until we reach the one with offset
+	for offset >= fh.nextOffset {
+		if fh.fillBuffer() != nil {
+			return false
+		}
+	}
+	if offset > fh.bufOffset+int64(fh.bufSize) {
+		return false
+	}
+	fh.bufIndex = int(offset - fh.bufOffset)
+	return true
+}
+
 // Seek implements the io.Seeker interface
 func (fh *decrypter) Seek(offset int64, whence int) (int64, error) {
 	return fh.RangeSeek(offset, whence, -1)
@@ -1089,6 +1089,59 @@ func TestNewDecrypterSeekLimit(t *testing.T) {
 	}
 }
 
+func TestDecrypterSeekInStream(t *testing.T) {
+	c, err := newCipher(NameEncryptionStandard, "", "", true)
+	assert.NoError(t, err)
+	c.cryptoRand = &zeroes{} // nodge the crypto rand generator
+
+	// Make random data of 20 blocks and a bit
+	const dataSize = 20*blockDataSize + 100
+	plaintext, err := ioutil.ReadAll(newRandomSource(dataSize))
+	require.NoError(t, err)
+	encrypted, err := c.EncryptData(bytes.NewBuffer(plaintext))
+	require.NoError(t, err)
+	ciphertext, err := ioutil.ReadAll(encrypted)
+	require.NoError(t, err)
+
+	// Count the opens of the underlying stream
+	opens := 0
+	open := func(underlyingOffset, underlyingLimit int64) (io.ReadCloser, error) {
+		opens++
+		end := len(ciphertext)
+		if underlyingLimit >= 0 && int(underlyingOffset+underlyingLimit) < end {
+			end = int(underlyingOffset + underlyingLimit)
+		}
+		return ioutil.NopCloser(bytes.NewBuffer(ciphertext[int(underlyingOffset):end])), nil
+	}
+
+	fh, err := c.DecryptDataSeek(open, 0, -1)
+	require.NoError(t, err)
+	buf := make([]byte, 1000)
+	for _, test := range []struct {
+		offset int64
+		opens  int
+	}{
+		{0, 1},                             // start of the stream
+		{100, 1},                           // forwards in the block
+		{50, 1},                            // backwards in the block
+		{3*blockDataSize + 10, 1},          // forwards a few blocks
+		{3*blockDataSize + 5, 1},           // backwards in the block
+		{2 * blockDataSize, 2},             // backwards out of the block
+		{3*blockDataSize + maxSeekSkip, 3}, // too far forwards
+		{dataSize - 50, 4},                 // too far forwards
+		{dataSize - 60, 4},                 // backwards in the last block
+	} {
+		what := fmt.Sprintf("offset %d", test.offset)
+		_, err = fh.Seek(test.offset, io.SeekStart)
+		require.NoError(t, err, what)
+		n, err := io.ReadFull(fh, buf[:50])
+		require.NoError(t, err, what)
+		assert.Equal(t, plaintext[test.offset:test.offset+int64(n)], buf[:n], what)
+		assert.Equal(t, test.opens, opens, what)
+	}
+	require.NoError(t, fh.Close())
+}
+
 func TestDecrypterCalculateUnderlying(t *testing.T) {
 	for _, test := range []struct {
 		offset, limit           int64
@@ -8,6 +8,7 @@ package drive
 // * files with / in name
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -109,6 +110,8 @@ func init() {
 		Name:        "drive",
 		Description: "Google Drive",
 		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
 		Config: func(name string) {
 			var err error
 			// Fill in the scopes
@@ -203,6 +206,7 @@ type Object struct {
 	bytes        int64  // size of the object
 	modifiedDate string // RFC3339 time it was last modified
 	isDocument   bool   // if set this is a Google doc
+	trashed      bool   // if set this is in the trash
 	mimeType     string
 }
 
@@ -252,6 +256,19 @@ func shouldRetry(err error) (again bool, errOut error) {
 	return again, err
 }
 
+// isNotFound returns true if err says the file with the ID used
+// doesn't exist
+func isNotFound(err error) bool {
+	gerr, ok := errors.Cause(err).(*googleapi.Error)
+	return ok && gerr.Code == http.StatusNotFound
+}
+
+// trashedOnly returns true if only the files in the trash should be
+// listed, either with --trash or --drive-trashed-only
+func trashedOnly() bool {
+	return fs.Config.Trash || *driveTrashedOnly
+}
+
 // parseParse parses a drive 'url'
 func parseDrivePath(path string) (root string, err error) {
 	root = strings.Trim(path, "/")
@@ -271,8 +288,8 @@ type listFn func(*drive.File) bool
 func (f *Fs) list(dirID string, title string, directoriesOnly bool, filesOnly bool, includeAll bool, fn listFn) (found bool, err error) {
 	var query []string
 	if !includeAll {
-		q := "trashed=" + strconv.FormatBool(*driveTrashedOnly)
-		if *driveTrashedOnly {
+		q := "trashed=" + strconv.FormatBool(trashedOnly())
+		if trashedOnly() {
 			q = fmt.Sprintf("(mimeType='%s' or %s)", driveFolderType, q)
 		}
 		query = append(query, q)
@@ -415,7 +432,7 @@ func configTeamDrive(name string) error {
 	listTeamDrives := svc.Teamdrives.List().PageSize(100)
 	for {
 		var teamDrives *drive.TeamDriveList
-		err = newPacer().Call(func() (bool, error) {
+		err = newPacer(name).Call(func() (bool, error) {
 			teamDrives, err = listTeamDrives.Do()
 			return shouldRetry(err)
 		})
@@ -441,12 +458,12 @@ func configTeamDrive(name string) error {
 	return nil
 }
 
-// newPacer makes a pacer configured for drive
-func newPacer() *pacer.Pacer {
-	return pacer.New().SetMinSleep(minSleep).SetPacer(pacer.GoogleDrivePacer)
+// newPacer makes a pacer configured for drive for the remote name
+func newPacer(name string) *pacer.Pacer {
+	return pacer.New().SetRemote(name).SetMinSleep(minSleep).SetPacer(pacer.GoogleDrivePacer)
 }
 
-func getServiceAccountClient(keyJsonfilePath string) (*http.Client, error) {
+func getServiceAccountClient(name, keyJsonfilePath string) (*http.Client, error) {
 	data, err := ioutil.ReadFile(os.ExpandEnv(keyJsonfilePath))
 	if err != nil {
 		return nil, errors.Wrap(err, "error opening credentials file")
@@ -458,7 +475,7 @@ func getServiceAccountClient(keyJsonfilePath string) (*http.Client, error) {
 	if *driveImpersonate != "" {
 		conf.Subject = *driveImpersonate
 	}
-	ctxWithSpecialClient := oauthutil.Context(fshttp.NewClient(fs.Config))
+	ctxWithSpecialClient := oauthutil.Context(fshttp.NewRemoteClient(fs.Config, name))
 	return oauth2.NewClient(ctxWithSpecialClient, conf.TokenSource(ctxWithSpecialClient)), nil
 }
 
@@ -468,7 +485,7 @@ func createOAuthClient(name string) (*http.Client, error) {
 
 	serviceAccountPath := config.FileGet(name, "service_account_file")
 	if serviceAccountPath != "" {
-		oAuthClient, err = getServiceAccountClient(serviceAccountPath)
+		oAuthClient, err = getServiceAccountClient(name, serviceAccountPath)
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create oauth client from service account")
 		}
@@ -504,7 +521,7 @@ func NewFs(name, path string) (fs.Fs, error) {
 	f := &Fs{
 		name:  name,
 		root:  root,
-		pacer: newPacer(),
+		pacer: newPacer(name),
 	}
 	f.teamDriveID = config.FileGet(name, "team_drive")
 	f.isTeamDrive = f.teamDriveID != ""
@@ -513,6 +530,7 @@ func NewFs(name, path string) (fs.Fs, error) {
 		ReadMimeType:            true,
 		WriteMimeType:           true,
 		CanHaveEmptyDirectories: true,
+		Trash:                   true,
 	}).Fill(f)
 
 	// Create a new authorized Drive client.
@@ -535,6 +553,7 @@ func NewFs(name, path string) (fs.Fs, error) {
 	}
 
 	f.dirCache = dircache.New(root, f.rootFolderID, f)
+	f.dirCache.Persist(name)
 
 	// Parse extensions
 	err = f.parseExtensions(*driveExtensions)
@@ -547,20 +566,21 @@ func NewFs(name, path string) (fs.Fs, error) {
 	}
 
 	// Find the current root
-	err = f.dirCache.FindRoot(false)
+	err = f.dirCache.FindRoot(context.TODO(), false)
 	if err != nil {
 		// Assume it is a file
 		newRoot, remote := dircache.SplitPath(root)
 		tempF := *f
 		tempF.dirCache = dircache.New(newRoot, f.rootFolderID, &tempF)
+		tempF.dirCache.Persist(name)
 		tempF.root = newRoot
 		// Make new Fs which is the parent
-		err = tempF.dirCache.FindRoot(false)
+		err = tempF.dirCache.FindRoot(context.TODO(), false)
 		if err != nil {
 			// No root so return old f
 			return f, nil
 		}
-		entries, err := tempF.List("")
+		entries, err := tempF.List(context.TODO(), "")
 		if err != nil {
 			// unable to list folder so return old f
 			return f, nil
@@ -585,7 +605,7 @@ func NewFs(name, path string) (fs.Fs, error) {
 // Return an Object from a path
 //
 // If it can't be found it returns the error fs.ErrorObjectNotFound.
-func (f *Fs) newObjectWithInfo(remote string, info *drive.File) (fs.Object, error) {
+func (f *Fs) newObjectWithInfo(ctx context.Context, remote string, info *drive.File) (fs.Object, error) {
 	o := &Object{
 		fs:     f,
 		remote: remote,
@@ -593,7 +613,7 @@ func (f *Fs) newObjectWithInfo(remote string, info *drive.File) (fs.Object, erro
 	if info != nil {
 		o.setMetaData(info)
 	} else {
-		err := o.readMetaData() // reads info and meta, returning an error
+		err := o.readMetaData(ctx) // reads info and meta, returning an error
 		if err != nil {
 			return nil, err
 		}
@@ -603,12 +623,12 @@ func (f *Fs) newObjectWithInfo(remote string, info *drive.File) (fs.Object, erro
 
 // NewObject finds the Object at remote.  If it can't be found
 // it returns the error fs.ErrorObjectNotFound.
-func (f *Fs) NewObject(remote string) (fs.Object, error) {
-	return f.newObjectWithInfo(remote, nil)
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	return f.newObjectWithInfo(ctx, remote, nil)
 }
 
 // FindLeaf finds a directory of name leaf in the folder with ID pathID
-func (f *Fs) FindLeaf(pathID, leaf string) (pathIDOut string, found bool, err error) {
+func (f *Fs) FindLeaf(ctx context.Context, pathID, leaf string) (pathIDOut string, found bool, err error) {
 	// Find the leaf in pathID
 	found, err = f.list(pathID, leaf, true, false, false, func(item *drive.File) bool {
 		if item.Name == leaf {
@@ -621,7 +641,7 @@ func (f *Fs) FindLeaf(pathID, leaf string) (pathIDOut string, found bool, err er
 }
 
 // CreateDir makes a directory with pathID as parent and name leaf
-func (f *Fs) CreateDir(pathID, leaf string) (newID string, err error) {
+func (f *Fs) CreateDir(ctx context.Context, pathID, leaf string) (newID string, err error) {
 	// fmt.Println("Making", path)
 	// Define the metadata for the directory we are going to create.
 	createInfo := &drive.File{
@@ -701,63 +721,62 @@ func (f *Fs) findExportFormat(filepath string, exportMimeTypes []string) (extens
 //
 // This should return ErrDirNotFound if the directory isn't
 // found.
-func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
-	err = f.dirCache.FindRoot(false)
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	err = f.dirCache.FindRoot(ctx, false)
 	if err != nil {
 		return nil, err
 	}
-	directoryID, err := f.dirCache.FindDir(dir, false)
-	if err != nil {
-		return nil, err
-	}
-
 	var iErr error
-	_, err = f.list(directoryID, "", false, false, false, func(item *drive.File) bool {
-		remote := path.Join(dir, item.Name)
-		switch {
-		case item.MimeType == driveFolderType:
-			// cache the directory ID for later lookups
-			f.dirCache.Put(remote, item.Id)
-			when, _ := time.Parse(timeFormatIn, item.ModifiedTime)
-			d := fs.NewDir(remote, when).SetID(item.Id)
-			entries = append(entries, d)
-		case *driveAuthOwnerOnly && !isAuthOwned(item):
-			// ignore object
-		case item.Md5Checksum != "" || item.Size > 0:
-			// If item has MD5 sum or a length it is a file stored on drive
-			o, err := f.newObjectWithInfo(remote, item)
-			if err != nil {
-				iErr = err
-				return true
-			}
-			entries = append(entries, o)
-		case *driveSkipGdocs:
-			fs.Debugf(remote, "Skipping google document type %q", item.MimeType)
-		default:
-			exportMimeTypes, isDocument := f.exportFormats()[item.MimeType]
-			if !isDocument {
-				fs.Debugf(remote, "Ignoring unknown document type %q", item.MimeType)
-				break
-			}
-			// If item has export links then it is a google doc
-			extension, exportMimeType := f.findExportFormat(remote, exportMimeTypes)
-			if extension == "" {
-				fs.Debugf(remote, "No export formats found for %q", item.MimeType)
-				break
-			}
-			o, err := f.newObjectWithInfo(remote+"."+extension, item)
-			if err != nil {
-				iErr = err
-				return true
+	err = f.dirCache.UseDir(ctx, dir, isNotFound, func(directoryID string) (err error) {
+		entries, iErr = nil, nil
+		_, err = f.list(directoryID, "", false, false, false, func(item *drive.File) bool {
+			remote := path.Join(dir, item.Name)
+			switch {
+			case item.MimeType == driveFolderType:
+				// cache the directory ID for later lookups
+				f.dirCache.Put(remote, item.Id)
+				when, _ := time.Parse(timeFormatIn, item.ModifiedTime)
+				d := fs.NewDir(remote, when).SetID(item.Id)
+				entries = append(entries, d)
+			case *driveAuthOwnerOnly && !isAuthOwned(item):
+				// ignore object
+			case item.Md5Checksum != "" || item.Size > 0:
+				// If item has MD5 sum or a length it is a file stored on drive
+				o, err := f.newObjectWithInfo(ctx, remote, item)
+				if err != nil {
+					iErr = err
+					return true
+				}
+				entries = append(entries, o)
+			case *driveSkipGdocs:
+				fs.Debugf(remote, "Skipping google document type %q", item.MimeType)
+			default:
+				exportMimeTypes, isDocument := f.exportFormats()[item.MimeType]
+				if !isDocument {
+					fs.Debugf(remote, "Ignoring unknown document type %q", item.MimeType)
+					break
+				}
+				// If item has export links then it is a google doc
+				extension, exportMimeType := f.findExportFormat(remote, exportMimeTypes)
+				if extension == "" {
+					fs.Debugf(remote, "No export formats found for %q", item.MimeType)
+					break
+				}
+				o, err := f.newObjectWithInfo(ctx, remote+"."+extension, item)
+				if err != nil {
+					iErr = err
+					return true
+				}
+				obj := o.(*Object)
+				obj.url = fmt.Sprintf("%sfiles/%s/export?mimeType=%s", f.svc.BasePath, item.Id, url.QueryEscape(exportMimeType))
+				obj.isDocument = true
+				obj.mimeType = exportMimeType
+				obj.bytes = -1
+				entries = append(entries, o)
 			}
-			obj := o.(*Object)
-			obj.url = fmt.Sprintf("%sfiles/%s/export?mimeType=%s", f.svc.BasePath, item.Id, url.QueryEscape(exportMimeType))
-			obj.isDocument = true
-			obj.mimeType = exportMimeType
-			obj.bytes = -1
-			entries = append(entries, o)
-		}
-		return false
+			return false
+		})
+		return err
 	})
 	if err != nil {
 		return nil, err
@@ -772,7 +791,7 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 // finished Object which must have setMetaData called on it
 //
 // Used to create new objects
-func (f *Fs) createFileInfo(remote string, modTime time.Time, size int64) (*Object, *drive.File, error) {
+func (f *Fs) createFileInfo(ctx context.Context, remote string, modTime time.Time, size int64) (*Object, *drive.File, error) {
 	// Temporary Object under construction
 	o := &Object{
 		fs:     f,
@@ -780,7 +799,7 @@ func (f *Fs) createFileInfo(remote string, modTime time.Time, size int64) (*Obje
 		bytes:  size,
 	}
 
-	leaf, directoryID, err := f.dirCache.FindRootAndPath(remote, true)
+	leaf, directoryID, err := f.dirCache.FindRootAndPath(ctx, remote, true)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -801,34 +820,34 @@ func (f *Fs) createFileInfo(remote string, modTime time.Time, size int64) (*Obje
 // Copy the reader in to the new object which is returned
 //
 // The new object may have been created if an error is returned
-func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
-	exisitingObj, err := f.newObjectWithInfo(src.Remote(), nil)
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	exisitingObj, err := f.newObjectWithInfo(ctx, src.Remote(), nil)
 	switch err {
 	case nil:
-		return exisitingObj, exisitingObj.Update(in, src, options...)
+		return exisitingObj, exisitingObj.Update(ctx, in, src, options...)
 	case fs.ErrorObjectNotFound:
 		// Not found so create it
-		return f.PutUnchecked(in, src, options...)
+		return f.PutUnchecked(ctx, in, src, options...)
 	default:
 		return nil, err
 	}
 }
 
 // PutStream uploads to the remote path with the modTime given of indeterminate size
-func (f *Fs) PutStream(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
-	return f.Put(in, src, options...)
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.Put(ctx, in, src, options...)
 }
 
 // PutUnchecked uploads the object
 //
 // This will create a duplicate if we upload a new file without
 // checking to see if there is one already - use Put() for that.
-func (f *Fs) PutUnchecked(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) PutUnchecked(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	remote := src.Remote()
 	size := src.Size()
 	modTime := src.ModTime()
 
-	o, createInfo, err := f.createFileInfo(remote, modTime, size)
+	o, createInfo, err := f.createFileInfo(ctx, remote, modTime, size)
 	if err != nil {
 		return nil, err
 	}
@@ -857,7 +876,7 @@ func (f *Fs) PutUnchecked(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOpt
 
 // MergeDirs merges the contents of all the directories passed
 // in into the first one and rmdirs the other directories.
-func (f *Fs) MergeDirs(dirs []fs.Directory) error {
+func (f *Fs) MergeDirs(ctx context.Context, dirs []fs.Directory) error {
 	if len(dirs) < 2 {
 		return nil
 	}
@@ -895,17 +914,33 @@ func (f *Fs) MergeDirs(dirs []fs.Directory) error {
 }
 
 // Mkdir creates the container if it doesn't exist
-func (f *Fs) Mkdir(dir string) error {
-	err := f.dirCache.FindRoot(true)
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
+	err := f.dirCache.FindRoot(ctx, true)
 	if err != nil {
 		return err
 	}
 	if dir != "" {
-		_, err = f.dirCache.FindDir(dir, true)
+		_, err = f.dirCache.FindDir(ctx, dir, true)
 	}
 	return err
 }
 
+// DirSetModTime sets the modification time of the directory dir
+// which must exist
+func (f *Fs) DirSetModTime(ctx context.Context, dir string, modTime time.Time) error {
+	directoryID, err := f.dirCache.FindDir(ctx, dir, false)
+	if err != nil {
+		return err
+	}
+	updateInfo := &drive.File{
+		ModifiedTime: modTime.Format(timeFormatOut),
+	}
+	return f.pacer.Call(func() (bool, error) {
+		_, err = f.svc.Files.Update(directoryID, updateInfo).Fields("id").SupportsTeamDrives(f.isTeamDrive).Do()
+		return shouldRetry(err)
+	})
+}
+
 // Rmdir deletes a directory unconditionally by ID
 func (f *Fs) rmdir(directoryID string, useTrash bool) error {
 	return f.pacer.Call(func() (bool, error) {
@@ -925,10 +960,10 @@ func (f *Fs) rmdir(directoryID string, useTrash bool) error {
 // Rmdir deletes a directory
 //
 // Returns an error if it isn't empty
-func (f *Fs) Rmdir(dir string) error {
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 	root := path.Join(f.root, dir)
 	dc := f.dirCache
-	directoryID, err := dc.FindDir(dir, false)
+	directoryID, err := dc.FindDir(ctx, dir, false)
 	if err != nil {
 		return err
 	}
@@ -978,7 +1013,7 @@ func (f *Fs) Precision() time.Duration {
 // Will only be called if src.Fs().Name() == f.Name()
 //
 // If it isn't possible then return fs.ErrorCantCopy
-func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	srcObj, ok := src.(*Object)
 	if !ok {
 		fs.Debugf(src, "Can't copy - not same remote type")
@@ -988,7 +1023,7 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 		return nil, errors.New("can't copy a Google document")
 	}
 
-	o, createInfo, err := f.createFileInfo(remote, srcObj.ModTime(), srcObj.bytes)
+	o, createInfo, err := f.createFileInfo(ctx, remote, srcObj.ModTime(), srcObj.bytes)
 	if err != nil {
 		return nil, err
 	}
@@ -1011,11 +1046,11 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 // Optional interface: Only implement this if you have a way of
 // deleting all the files quicker than just running Remove() on the
 // result of List()
-func (f *Fs) Purge() error {
+func (f *Fs) Purge(ctx context.Context) error {
 	if f.root == "" {
 		return errors.New("can't purge root directory")
 	}
-	err := f.dirCache.FindRoot(false)
+	err := f.dirCache.FindRoot(ctx, false)
 	if err != nil {
 		return err
 	}
@@ -1038,7 +1073,7 @@ func (f *Fs) Purge() error {
 }
 
 // CleanUp empties the trash
-func (f *Fs) CleanUp() error {
+func (f *Fs) CleanUp(ctx context.Context) error {
 	err := f.pacer.Call(func() (bool, error) {
 		err := f.svc.Files.EmptyTrash().Do()
 		return shouldRetry(err)
@@ -1051,7 +1086,7 @@ func (f *Fs) CleanUp() error {
 }
 
 // About gets quota information
-func (f *Fs) About() (*fs.Usage, error) {
+func (f *Fs) About(ctx context.Context) (*fs.Usage, error) {
 	var about *drive.About
 	var err error
 	err = f.pacer.Call(func() (bool, error) {
@@ -1083,7 +1118,7 @@ func (f *Fs) About() (*fs.Usage, error) {
 // Will only be called if src.Fs().Name() == f.Name()
 //
 // If it isn't possible then return fs.ErrorCantMove
-func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	srcObj, ok := src.(*Object)
 	if !ok {
 		fs.Debugf(src, "Can't move - not same remote type")
@@ -1092,13 +1127,13 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 	if srcObj.isDocument {
 		return nil, errors.New("can't move a Google document")
 	}
-	_, srcParentID, err := srcObj.fs.dirCache.FindPath(src.Remote(), false)
+	_, srcParentID, err := srcObj.fs.dirCache.FindPath(ctx, src.Remote(), false)
 	if err != nil {
 		return nil, err
 	}
 
 	// Temporary Object under construction
-	dstObj, dstInfo, err := f.createFileInfo(remote, srcObj.ModTime(), srcObj.bytes)
+	dstObj, dstInfo, err := f.createFileInfo(ctx, remote, srcObj.ModTime(), srcObj.bytes)
 	if err != nil {
 		return nil, err
 	}
@@ -1120,8 +1155,16 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
-func (f *Fs) PublicLink(remote string) (link string, err error) {
-	id, err := f.dirCache.FindDir(remote, false)
+//
+// Drive can't make links for anyone which expire or need a password.
+func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, password string) (link string, err error) {
+	if expire.IsSet() {
+		return "", fs.ErrorLinkExpiryUnsupported
+	}
+	if password != "" {
+		return "", fs.ErrorLinkPasswordUnsupported
+	}
+	id, err := f.dirCache.FindDir(ctx, remote, false)
 	if err == nil {
 		fs.Debugf(f, "attempting to share directory '%s'", remote)
 	} else {
@@ -1130,7 +1173,7 @@ func (f *Fs) PublicLink(remote string) (link string, err error) {
 			fs:     f,
 			remote: remote,
 		}
-		if err = o.readMetaData(); err != nil {
+		if err = o.readMetaData(ctx); err != nil {
 			return
 		}
 		id = o.id
@@ -1162,7 +1205,7 @@ func (f *Fs) PublicLink(remote string) (link string, err error) {
 // If it isn't possible then return fs.ErrorCantDirMove
 //
 // If destination exists then return fs.ErrorDirExists
-func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
+func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
 	srcFs, ok := src.(*Fs)
 	if !ok {
 		fs.Debugf(srcFs, "Can't move directory - not same remote type")
@@ -1178,14 +1221,14 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 	}
 
 	// find the root src directory
-	err := srcFs.dirCache.FindRoot(false)
+	err := srcFs.dirCache.FindRoot(ctx, false)
 	if err != nil {
 		return err
 	}
 
 	// find the root dst directory
 	if dstRemote != "" {
-		err = f.dirCache.FindRoot(true)
+		err = f.dirCache.FindRoot(ctx, true)
 		if err != nil {
 			return err
 		}
@@ -1201,14 +1244,14 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 	if dstRemote == "" {
 		findPath = f.root
 	}
-	leaf, dstDirectoryID, err = f.dirCache.FindPath(findPath, true)
+	leaf, dstDirectoryID, err = f.dirCache.FindPath(ctx, findPath, true)
 	if err != nil {
 		return err
 	}
 
 	// Check destination does not exist
 	if dstRemote != "" {
-		_, err = f.dirCache.FindDir(dstRemote, false)
+		_, err = f.dirCache.FindDir(ctx, dstRemote, false)
 		if err == fs.ErrorDirNotFound {
 			// OK
 		} else if err != nil {
@@ -1223,14 +1266,14 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 	if srcRemote == "" {
 		srcDirectoryID, err = srcFs.dirCache.RootParentID()
 	} else {
-		_, srcDirectoryID, err = srcFs.dirCache.FindPath(srcRemote, false)
+		_, srcDirectoryID, err = srcFs.dirCache.FindPath(ctx, srcRemote, false)
 	}
 	if err != nil {
 		return err
 	}
 
 	// Find ID of src
-	srcID, err := srcFs.dirCache.FindDir(srcRemote, false)
+	srcID, err := srcFs.dirCache.FindDir(ctx, srcRemote, false)
 	if err != nil {
 		return err
 	}
@@ -1247,6 +1290,10 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 		return err
 	}
 	srcFs.dirCache.FlushDir(srcRemote)
+	if dstRemote != "" {
+		// Forget that the destination wasn't found
+		f.dirCache.FlushDir(dstRemote)
+	}
 	return nil
 }
 
@@ -1314,6 +1361,12 @@ func (f *Fs) changeNotifyRunner(notifyFunc func(string, fs.EntryType), pollInter
 					pathsToClear = append(pathsToClear, entryType{path: path, entryType: fs.EntryObject})
 				} else {
 					pathsToClear = append(pathsToClear, entryType{path: path, entryType: fs.EntryDirectory})
+					// The directory may have been renamed or
+					// deleted so its ID and those of its
+					// children may be stale
+					if path != "" {
+						f.dirCache.FlushDir(path)
+					}
 				}
 				continue
 			}
@@ -1360,6 +1413,193 @@ func (f *Fs) changeNotifyRunner(notifyFunc func(string, fs.EntryType), pollInter
 	}
 }
 
+// ListChanges calls fn for each change to the files under the root
+// since cursor and returns the cursor to read the next changes from.
+//
+// The cursor is the changes page token and the time it was made
+// separated by a ":" so that files created since can be told apart
+// from modified ones.
+//
+// Files which are removed, rather than trashed, are only reported
+// if they were directories already in the directory cache as the
+// changes list doesn't say where they were.
+func (f *Fs) ListChanges(ctx context.Context, cursor string, fn func(fs.Change) error) (newCursor string, err error) {
+	now := time.Now()
+	if cursor == "" {
+		var startPageToken *drive.StartPageToken
+		err = f.pacer.Call(func() (bool, error) {
+			startPageToken, err = f.svc.Changes.GetStartPageToken().SupportsTeamDrives(f.isTeamDrive).Do()
+			return shouldRetry(err)
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get start page token")
+		}
+		return makeChangesCursor(startPageToken.StartPageToken, now), nil
+	}
+	pageToken, since, err := parseChangesCursor(cursor)
+	if err != nil {
+		return "", err
+	}
+	c := changesResolver{
+		f:    f,
+		dirs: make(map[string]changesDir),
+	}
+	err = c.findRoot()
+	if err != nil {
+		return "", err
+	}
+	for {
+		var changeList *drive.ChangeList
+		err = f.pacer.Call(func() (bool, error) {
+			changesCall := f.svc.Changes.List(pageToken).Fields("nextPageToken,newStartPageToken,changes(fileId,removed,file(name,parents,mimeType,trashed,createdTime))")
+			if *driveListChunk > 0 {
+				changesCall = changesCall.PageSize(*driveListChunk)
+			}
+			changeList, err = changesCall.SupportsTeamDrives(f.isTeamDrive).Do()
+			return shouldRetry(err)
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "failed to list changes")
+		}
+		for _, change := range changeList.Changes {
+			item, ok, err := c.translate(change, since)
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				continue
+			}
+			err = fn(item)
+			if err != nil {
+				return "", err
+			}
+		}
+		if changeList.NewStartPageToken != "" {
+			return makeChangesCursor(changeList.NewStartPageToken, now), nil
+		}
+		if changeList.NextPageToken == "" {
+			return "", errors.New("changes list didn't return a page token")
+		}
+		pageToken = changeList.NextPageToken
+	}
+}
+
+// makeChangesCursor makes a cursor for ListChanges
+func makeChangesCursor(pageToken string, t time.Time) string {
+	return pageToken + ":" + strconv.FormatInt(t.Unix(), 10)
+}
+
+// parseChangesCursor parses a cursor made by makeChangesCursor
+func parseChangesCursor(cursor string) (pageToken string, t time.Time, err error) {
+	colon := strings.LastIndex(cursor, ":")
+	if colon <= 0 {
+		return "", t, errors.Errorf("bad changes cursor %q", cursor)
+	}
+	seconds, err := strconv.ParseInt(cursor[colon+1:], 10, 64)
+	if err != nil {
+		return "", t, errors.Errorf("bad changes cursor %q", cursor)
+	}
+	return cursor[:colon], time.Unix(seconds, 0), nil
+}
+
+// changesDir is the path of a directory found while resolving changes
+type changesDir struct {
+	path   string // path relative to the root
+	inRoot bool   // set if the directory is under the root
+}
+
+// changesResolver works out the paths of the files in the changes list
+type changesResolver struct {
+	f      *Fs
+	rootID string                // the real ID of the root directory
+	dirs   map[string]changesDir // directories looked up so far by ID
+}
+
+// findRoot reads the real ID of the root, which may be an alias such
+// as "root", so it can be found in the parents of changed files
+func (c *changesResolver) findRoot() (err error) {
+	var info *drive.File
+	err = c.f.pacer.Call(func() (bool, error) {
+		info, err = c.f.svc.Files.Get(c.f.dirCache.RootID()).Fields("id").SupportsTeamDrives(c.f.isTeamDrive).Do()
+		return shouldRetry(err)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to read root directory")
+	}
+	c.rootID = info.Id
+	return nil
+}
+
+// dirPath returns the path of the directory with id and whether it
+// is under the root
+func (c *changesResolver) dirPath(id string) (dir changesDir, err error) {
+	if id == c.rootID {
+		return changesDir{inRoot: true}, nil
+	}
+	if dirPath, ok := c.f.dirCache.GetInv(id); ok {
+		return changesDir{path: dirPath, inRoot: true}, nil
+	}
+	if dir, ok := c.dirs[id]; ok {
+		return dir, nil
+	}
+	var info *drive.File
+	err = c.f.pacer.Call(func() (bool, error) {
+		info, err = c.f.svc.Files.Get(id).Fields("name,parents").SupportsTeamDrives(c.f.isTeamDrive).Do()
+		return shouldRetry(err)
+	})
+	if err != nil {
+		return dir, errors.Wrap(err, "failed to read parent directory")
+	}
+	if len(info.Parents) > 0 {
+		dir, err = c.dirPath(info.Parents[0])
+		if err != nil {
+			return dir, err
+		}
+		dir.path = path.Join(dir.path, strings.Replace(info.Name, "/", "／", -1))
+	}
+	c.dirs[id] = dir
+	return dir, nil
+}
+
+// translate turns a drive change into an fs.Change returning false
+// if it isn't under the root or can't be found
+func (c *changesResolver) translate(change *drive.Change, since time.Time) (item fs.Change, ok bool, err error) {
+	if change.Removed || change.File == nil {
+		dirPath, found := c.f.dirCache.GetInv(change.FileId)
+		if !found {
+			fs.Debugf(c.f, "Ignoring removed file with ID %q as its path is unknown", change.FileId)
+			return item, false, nil
+		}
+		if dirPath != "" {
+			c.f.dirCache.FlushDir(dirPath)
+		}
+		return fs.Change{Path: dirPath, EntryType: fs.EntryDirectory, Action: fs.ChangeDeleted}, true, nil
+	}
+	file := change.File
+	if len(file.Parents) == 0 {
+		return item, false, nil
+	}
+	dir, err := c.dirPath(file.Parents[0])
+	if err != nil {
+		return item, false, err
+	}
+	if !dir.inRoot {
+		return item, false, nil
+	}
+	item.Path = path.Join(dir.path, strings.Replace(file.Name, "/", "／", -1))
+	item.EntryType = fs.EntryObject
+	if file.MimeType == driveFolderType {
+		item.EntryType = fs.EntryDirectory
+	}
+	item.Action = fs.ChangeModified
+	if file.Trashed {
+		item.Action = fs.ChangeDeleted
+	} else if created, err := time.Parse(time.RFC3339, file.CreatedTime); err == nil && !created.Before(since) {
+		item.Action = fs.ChangeCreated
+	}
+	return item, true, nil
+}
+
 // DirCacheFlush resets the directory cache - used in testing as an
 // optional interface
 func (f *Fs) DirCacheFlush() {
@@ -1416,15 +1656,16 @@ func (o *Object) setMetaData(info *drive.File) {
 		o.modifiedDate = info.ModifiedTime
 	}
 	o.mimeType = info.MimeType
+	o.trashed = info.Trashed
 }
 
 // readMetaData gets the info if it hasn't already been fetched
-func (o *Object) readMetaData() (err error) {
+func (o *Object) readMetaData(ctx context.Context) (err error) {
 	if o.id != "" {
 		return nil
 	}
 
-	leaf, directoryID, err := o.fs.dirCache.FindRootAndPath(o.remote, false)
+	leaf, directoryID, err := o.fs.dirCache.FindRootAndPath(ctx, o.remote, false)
 	if err != nil {
 		if err == fs.ErrorDirNotFound {
 			return fs.ErrorObjectNotFound
@@ -1454,7 +1695,7 @@ func (o *Object) readMetaData() (err error) {
 // It attempts to read the objects mtime and if that isn't present the
 // LastModified returned in the http headers
 func (o *Object) ModTime() time.Time {
-	err := o.readMetaData()
+	err := o.readMetaData(context.TODO())
 	if err != nil {
 		fs.Debugf(o, "Failed to read metadata: %v", err)
 		return time.Now()
@@ -1468,8 +1709,8 @@ func (o *Object) ModTime() time.Time {
 }
 
 // SetModTime sets the modification time of the drive fs object
-func (o *Object) SetModTime(modTime time.Time) error {
-	err := o.readMetaData()
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	err := o.readMetaData(ctx)
 	if err != nil {
 		return err
 	}
@@ -1567,7 +1808,7 @@ func (file *openFile) Close() (err error) {
 var _ io.ReadCloser = &openFile{}
 
 // Open an object for read
-func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
 	_, res, err := o.httpResponse("GET", options)
 	if err != nil {
 		return nil, errors.Wrap(err, "open file failed")
@@ -1587,7 +1828,7 @@ func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
 // Copy the reader into the object updating modTime and size
 //
 // The new object may have been created if an error is returned
-func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
 	size := src.Size()
 	modTime := src.ModTime()
 	if o.isDocument {
@@ -1622,7 +1863,7 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 }
 
 // Remove an object
-func (o *Object) Remove() error {
+func (o *Object) Remove(ctx context.Context) error {
 	if o.isDocument {
 		return errors.New("can't delete a google document")
 	}
@@ -1641,9 +1882,30 @@ func (o *Object) Remove() error {
 	return err
 }
 
+// RestoreTrash moves the object out of the trash back to the
+// directory it was deleted from
+func (o *Object) RestoreTrash(ctx context.Context) error {
+	if !o.trashed {
+		return errors.New("not in the trash")
+	}
+	info := drive.File{
+		Trashed:         false,
+		ForceSendFields: []string{"Trashed"},
+	}
+	err := o.fs.pacer.Call(func() (bool, error) {
+		_, err := o.fs.svc.Files.Update(o.id, &info).Fields("").SupportsTeamDrives(o.fs.isTeamDrive).Do()
+		return shouldRetry(err)
+	})
+	if err != nil {
+		return err
+	}
+	o.trashed = false
+	return nil
+}
+
 // MimeType of an Object if known, "" otherwise
 func (o *Object) MimeType() string {
-	err := o.readMetaData()
+	err := o.readMetaData(context.TODO())
 	if err != nil {
 		fs.Debugf(o, "Failed to read metadata: %v", err)
 		return ""
@@ -1662,10 +1924,13 @@ var (
 	_ fs.DirMover        = (*Fs)(nil)
 	_ fs.DirCacheFlusher = (*Fs)(nil)
 	_ fs.ChangeNotifier  = (*Fs)(nil)
+	_ fs.ChangeLister    = (*Fs)(nil)
 	_ fs.PutUncheckeder  = (*Fs)(nil)
 	_ fs.PublicLinker    = (*Fs)(nil)
 	_ fs.MergeDirser     = (*Fs)(nil)
 	_ fs.Abouter         = (*Fs)(nil)
+	_ fs.DirSetModTimer  = (*Fs)(nil)
 	_ fs.Object          = (*Object)(nil)
 	_ fs.MimeTyper       = (*Object)(nil)
+	_ fs.TrashRestorer   = (*Object)(nil)
 )
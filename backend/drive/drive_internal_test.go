@@ -3,9 +3,12 @@ package drive
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"google.golang.org/api/drive/v3"
 
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/lib/dircache"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -110,3 +113,74 @@ func TestInternalFindExportFormat(t *testing.T) {
 		assert.Equal(t, test.wantMimeType, gotMimeType)
 	}
 }
+
+func TestInternalChangesCursor(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	cursor := makeChangesCursor("1234", now)
+	assert.Equal(t, "1234:1500000000", cursor)
+	pageToken, since, err := parseChangesCursor(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", pageToken)
+	assert.Equal(t, now, since)
+
+	for _, bad := range []string{"", "1234", ":1500000000", "1234:potato"} {
+		_, _, err = parseChangesCursor(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestInternalChangesTranslate(t *testing.T) {
+	f := new(Fs)
+	f.dirCache = dircache.New("", "rootID", f)
+	f.dirCache.Put("dir", "dirID")
+	c := changesResolver{
+		f:      f,
+		rootID: "rootID",
+		dirs: map[string]changesDir{
+			"outsideID": {},
+		},
+	}
+	since := time.Unix(1500000000, 0)
+	before := "2017-07-14T02:39:00Z"
+	after := "2017-07-14T02:41:00Z"
+	for _, test := range []struct {
+		change *drive.Change
+		want   fs.Change
+		wantOK bool
+	}{
+		{
+			change: &drive.Change{File: &drive.File{Name: "new.txt", Parents: []string{"rootID"}, CreatedTime: after}},
+			want:   fs.Change{Path: "new.txt", EntryType: fs.EntryObject, Action: fs.ChangeCreated},
+			wantOK: true,
+		},
+		{
+			change: &drive.Change{File: &drive.File{Name: "old.txt", Parents: []string{"dirID"}, CreatedTime: before}},
+			want:   fs.Change{Path: "dir/old.txt", EntryType: fs.EntryObject, Action: fs.ChangeModified},
+			wantOK: true,
+		},
+		{
+			change: &drive.Change{File: &drive.File{Name: "sub", Parents: []string{"dirID"}, MimeType: driveFolderType, Trashed: true, CreatedTime: before}},
+			want:   fs.Change{Path: "dir/sub", EntryType: fs.EntryDirectory, Action: fs.ChangeDeleted},
+			wantOK: true,
+		},
+		{
+			change: &drive.Change{FileId: "dirID", Removed: true},
+			want:   fs.Change{Path: "dir", EntryType: fs.EntryDirectory, Action: fs.ChangeDeleted},
+			wantOK: true,
+		},
+		{
+			change: &drive.Change{FileId: "unknownID", Removed: true},
+		},
+		{
+			change: &drive.Change{File: &drive.File{Name: "elsewhere.txt", Parents: []string{"outsideID"}, CreatedTime: after}},
+		},
+		{
+			change: &drive.Change{File: &drive.File{Name: "orphan.txt", CreatedTime: after}},
+		},
+	} {
+		got, ok, err := c.translate(test.change, since)
+		assert.NoError(t, err)
+		assert.Equal(t, test.wantOK, ok)
+		assert.Equal(t, test.want, got)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strconv"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/lib/readers"
 	"github.com/pkg/errors"
@@ -198,7 +199,7 @@ func (rx *resumableUpload) Upload() (*drive.File, error) {
 	var StatusCode int
 	var err error
 	buf := make([]byte, int(chunkSize))
-	for start < rx.ContentLength {
+	for part := int64(1); start < rx.ContentLength; part++ {
 		reqSize := rx.ContentLength - start
 		if reqSize >= int64(chunkSize) {
 			reqSize = int64(chunkSize)
@@ -206,7 +207,9 @@ func (rx *resumableUpload) Upload() (*drive.File, error) {
 		chunk := readers.NewRepeatableLimitReaderBuffer(rx.Media, buf, reqSize)
 
 		// Transfer the chunk
+		timer := accounting.NewChunkTimer(rx.f.name, rx.remote, part)
 		err = rx.f.pacer.Call(func() (bool, error) {
+			timer.Try()
 			fs.Debugf(rx.remote, "Sending chunk %d length %d", start, reqSize)
 			StatusCode, err = rx.transferChunk(start, chunk, reqSize)
 			again, err := shouldRetry(err)
@@ -216,6 +219,7 @@ func (rx *resumableUpload) Upload() (*drive.File, error) {
 			}
 			return again, err
 		})
+		timer.Done(err)
 		if err != nil {
 			return nil, err
 		}
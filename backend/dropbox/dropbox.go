@@ -22,9 +22,14 @@ of path_display and all will be well.
 */
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
 	"path"
 	"regexp"
 	"strings"
@@ -35,6 +40,7 @@ import (
 	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/sharing"
 	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/users"
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/config/obscure"
@@ -105,6 +111,8 @@ func init() {
 		Name:        "dropbox",
 		Description: "Dropbox",
 		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
 		Config: func(name string) {
 			err := oauthutil.ConfigNoOffline("dropbox", name, dropboxConfig)
 			if err != nil {
@@ -124,15 +132,16 @@ func init() {
 
 // Fs represents a remote dropbox server
 type Fs struct {
-	name           string         // name of this remote
-	root           string         // the path we are working on
-	features       *fs.Features   // optional features
-	srv            files.Client   // the connection to the dropbox server
-	sharingClient  sharing.Client // as above, but for generating sharing links
-	users          users.Client   // as above, but for accessing user information
-	slashRoot      string         // root with "/" prefix, lowercase
-	slashRootSlash string         // root with "/" prefix and postfix, lowercase
-	pacer          *pacer.Pacer   // To pace the API calls
+	name           string          // name of this remote
+	root           string          // the path we are working on
+	features       *fs.Features    // optional features
+	srv            files.Client    // the connection to the dropbox server
+	sharingClient  sharing.Client  // as above, but for generating sharing links
+	users          users.Client    // as above, but for accessing user information
+	slashRoot      string          // root with "/" prefix, lowercase
+	slashRootSlash string          // root with "/" prefix and postfix, lowercase
+	pacer          *pacer.Pacer    // To pace the API calls
+	rpc            dropbox.Context // for the API calls the SDK can't make
 }
 
 // Object describes a dropbox object
@@ -218,7 +227,8 @@ func NewFs(name, root string) (fs.Fs, error) {
 		srv:           srv,
 		sharingClient: sharingClient,
 		users:         users,
-		pacer:         pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		rpc:           dropbox.NewContext(config),
+		pacer:         pacer.New().SetRemote(name).SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
 	}
 	f.features = (&fs.Features{
 		CaseInsensitive:         true,
@@ -324,7 +334,7 @@ func (f *Fs) newObjectWithInfo(remote string, info *files.FileMetadata) (fs.Obje
 
 // NewObject finds the Object at remote.  If it can't be found
 // it returns the error fs.ErrorObjectNotFound.
-func (f *Fs) NewObject(remote string) (fs.Object, error) {
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 	return f.newObjectWithInfo(remote, nil)
 }
 
@@ -337,7 +347,7 @@ func (f *Fs) NewObject(remote string) (fs.Object, error) {
 //
 // This should return ErrDirNotFound if the directory isn't
 // found.
-func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	root := f.slashRoot
 	if dir != "" {
 		root += "/" + dir
@@ -439,22 +449,22 @@ func (rc *readCloser) Close() error {
 // Copy the reader in to the new object which is returned
 //
 // The new object may have been created if an error is returned
-func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	// Temporary Object under construction
 	o := &Object{
 		fs:     f,
 		remote: src.Remote(),
 	}
-	return o, o.Update(in, src, options...)
+	return o, o.Update(ctx, in, src, options...)
 }
 
 // PutStream uploads to the remote path with the modTime given of indeterminate size
-func (f *Fs) PutStream(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
-	return f.Put(in, src, options...)
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.Put(ctx, in, src, options...)
 }
 
 // Mkdir creates the container if it doesn't exist
-func (f *Fs) Mkdir(dir string) error {
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
 	root := path.Join(f.slashRoot, dir)
 
 	// can't create or run metadata on root
@@ -484,7 +494,7 @@ func (f *Fs) Mkdir(dir string) error {
 // Rmdir deletes the container
 //
 // Returns an error if it isn't empty
-func (f *Fs) Rmdir(dir string) error {
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 	root := path.Join(f.slashRoot, dir)
 
 	// can't remove root
@@ -540,7 +550,7 @@ func (f *Fs) Precision() time.Duration {
 // Will only be called if src.Fs().Name() == f.Name()
 //
 // If it isn't possible then return fs.ErrorCantCopy
-func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	srcObj, ok := src.(*Object)
 	if !ok {
 		fs.Debugf(src, "Can't copy - not same remote type")
@@ -585,7 +595,7 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 // Optional interface: Only implement this if you have a way of
 // deleting all the files quicker than just running Remove() on the
 // result of List()
-func (f *Fs) Purge() (err error) {
+func (f *Fs) Purge(ctx context.Context) (err error) {
 	// Let dropbox delete the filesystem tree
 	err = f.pacer.Call(func() (bool, error) {
 		_, err = f.srv.DeleteV2(&files.DeleteArg{Path: f.slashRoot})
@@ -603,7 +613,7 @@ func (f *Fs) Purge() (err error) {
 // Will only be called if src.Fs().Name() == f.Name()
 //
 // If it isn't possible then return fs.ErrorCantMove
-func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	srcObj, ok := src.(*Object)
 	if !ok {
 		fs.Debugf(src, "Can't move - not same remote type")
@@ -643,15 +653,35 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
-func (f *Fs) PublicLink(remote string) (link string, err error) {
+//
+// Links which expire or need a password need a paid Dropbox account.
+// If the link exists already its expiry and password are changed to
+// those asked for.
+func (f *Fs) PublicLink(ctx context.Context, remote string, expire fs.Duration, password string) (link string, err error) {
 	absPath := "/" + path.Join(f.Root(), remote)
 	fs.Debugf(f, "attempting to share '%s' (absolute path: %s)", remote, absPath)
-	createArg := sharing.CreateSharedLinkWithSettingsArg{
+	settings := &linkSettings{
+		RequestedVisibility: &sharing.RequestedVisibility{Tagged: dropbox.Tagged{Tag: sharing.RequestedVisibilityPublic}},
+	}
+	if expire.IsSet() {
+		// Dropbox links expire to the second
+		expires := time.Now().Add(time.Duration(expire)).UTC().Round(time.Second)
+		settings.Expires = &expires
+	}
+	if password != "" {
+		settings.RequestedVisibility.Tag = sharing.RequestedVisibilityPassword
+		settings.LinkPassword = password
+	}
+	restrict := expire.IsSet() || password != ""
+	createArg := createLinkArg{
 		Path: absPath,
 	}
+	if restrict {
+		createArg.Settings = settings
+	}
 	var linkRes sharing.IsSharedLinkMetadata
 	err = f.pacer.Call(func() (bool, error) {
-		linkRes, err = f.sharingClient.CreateSharedLinkWithSettings(&createArg)
+		linkRes, err = f.linkCall("create_shared_link_with_settings", &createArg)
 		return shouldRetry(err)
 	})
 
@@ -674,20 +704,117 @@ func (f *Fs) PublicLink(remote string) (link string, err error) {
 			return
 		}
 		linkRes = listRes.Links[0]
+		expires, hasPassword := linkRestrictions(linkRes)
+		if restrict || expires || hasPassword {
+			// Change the settings of the existing link to
+			// those asked for
+			modifyArg := modifyLinkArg{
+				Settings:         settings,
+				RemoveExpiration: expires && !expire.IsSet(),
+			}
+			modifyArg.URL, err = linkURL(linkRes)
+			if err != nil {
+				return "", err
+			}
+			err = f.pacer.Call(func() (bool, error) {
+				linkRes, err = f.linkCall("modify_shared_link_settings", &modifyArg)
+				return shouldRetry(err)
+			})
+			if err != nil {
+				return "", errors.Wrap(err, "failed to change link settings")
+			}
+		}
 	}
 	if err == nil {
-		switch res := linkRes.(type) {
-		case *sharing.FileLinkMetadata:
-			link = res.Url
-		case *sharing.FolderLinkMetadata:
-			link = res.Url
-		default:
-			err = fmt.Errorf("Don't know how to extract link, response has unknown format: %T", res)
-		}
+		link, err = linkURL(linkRes)
 	}
 	return
 }
 
+// linkSettings are the settings of a shared link.  These are sent
+// instead of sharing.SharedLinkSettings as the SDK always sends its
+// expiry time, even when it isn't set.
+type linkSettings struct {
+	RequestedVisibility *sharing.RequestedVisibility `json:"requested_visibility,omitempty"`
+	LinkPassword        string                       `json:"link_password,omitempty"`
+	Expires             *time.Time                   `json:"expires,omitempty"`
+}
+
+// createLinkArg is the argument of create_shared_link_with_settings
+type createLinkArg struct {
+	Path     string        `json:"path"`
+	Settings *linkSettings `json:"settings,omitempty"`
+}
+
+// modifyLinkArg is the argument of modify_shared_link_settings
+type modifyLinkArg struct {
+	URL              string        `json:"url"`
+	Settings         *linkSettings `json:"settings"`
+	RemoveExpiration bool          `json:"remove_expiration"`
+}
+
+// linkCall calls the sharing API route with arg, returning the shared
+// link it makes or changes
+func (f *Fs) linkCall(route string, arg interface{}) (sharing.IsSharedLinkMetadata, error) {
+	body, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+	headers := map[string]string{
+		"Content-Type": "application/json",
+	}
+	req, err := f.rpc.NewRequest("api", "rpc", true, "sharing", route, headers, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.rpc.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer fs.CheckClose(resp.Body, &err)
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return sharing.IsSharedLinkMetadataFromJSON(body)
+	}
+	var apiError dropbox.APIError
+	if json.Unmarshal(body, &apiError) == nil && apiError.ErrorSummary != "" {
+		return nil, apiError
+	}
+	return nil, dropbox.APIError{ErrorSummary: string(body)}
+}
+
+// linkURL returns the URL of the shared link in linkRes
+func linkURL(linkRes sharing.IsSharedLinkMetadata) (string, error) {
+	switch res := linkRes.(type) {
+	case *sharing.FileLinkMetadata:
+		return res.Url, nil
+	case *sharing.FolderLinkMetadata:
+		return res.Url, nil
+	}
+	return "", fmt.Errorf("Don't know how to extract link, response has unknown format: %T", linkRes)
+}
+
+// linkRestrictions returns whether the shared link in linkRes expires
+// and whether it needs a password
+func linkRestrictions(linkRes sharing.IsSharedLinkMetadata) (expires, hasPassword bool) {
+	var md *sharing.SharedLinkMetadata
+	switch res := linkRes.(type) {
+	case *sharing.FileLinkMetadata:
+		md = &res.SharedLinkMetadata
+	case *sharing.FolderLinkMetadata:
+		md = &res.SharedLinkMetadata
+	default:
+		return false, false
+	}
+	expires = !md.Expires.IsZero()
+	hasPassword = md.LinkPermissions != nil && md.LinkPermissions.ResolvedVisibility != nil &&
+		md.LinkPermissions.ResolvedVisibility.Tag == sharing.ResolvedVisibilityPassword
+	return expires, hasPassword
+}
+
 // DirMove moves src, srcRemote to this remote at dstRemote
 // using server side move operations.
 //
@@ -696,7 +823,7 @@ func (f *Fs) PublicLink(remote string) (link string, err error) {
 // If it isn't possible then return fs.ErrorCantDirMove
 //
 // If destination exists then return fs.ErrorDirExists
-func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
+func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
 	srcFs, ok := src.(*Fs)
 	if !ok {
 		fs.Debugf(srcFs, "Can't move directory - not same remote type")
@@ -732,7 +859,7 @@ func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
 }
 
 // About gets quota information
-func (f *Fs) About() (usage *fs.Usage, err error) {
+func (f *Fs) About(ctx context.Context) (usage *fs.Usage, err error) {
 	var q *users.SpaceUsage
 	err = f.pacer.Call(func() (bool, error) {
 		q, err = f.users.GetSpaceUsage()
@@ -858,7 +985,7 @@ func (o *Object) ModTime() time.Time {
 // SetModTime sets the modification time of the local fs object
 //
 // Commits the datastore
-func (o *Object) SetModTime(modTime time.Time) error {
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
 	// Dropbox doesn't have a way of doing this so returning this
 	// error will cause the file to be deleted first then
 	// re-uploaded to set the time.
@@ -871,7 +998,7 @@ func (o *Object) Storable() bool {
 }
 
 // Open an object for read
-func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
 	headers := fs.OpenOptionHeaders(options)
 	arg := files.DownloadArg{Path: o.remotePath(), ExtraHeaders: headers}
 	err = o.fs.pacer.Call(func() (bool, error) {
@@ -918,7 +1045,9 @@ func (o *Object) uploadChunked(in0 io.Reader, commitInfo *files.CommitInfo, size
 	fmtChunk(1, false)
 	var res *files.UploadSessionStartResult
 	chunk := readers.NewRepeatableLimitReaderBuffer(in, buf, chunkSize)
+	timer := accounting.NewChunkTimer(o.fs.name, o, 1)
 	err = o.fs.pacer.Call(func() (bool, error) {
+		timer.Try()
 		// seek to the start in case this is a retry
 		if _, err = chunk.Seek(0, io.SeekStart); err != nil {
 			return false, nil
@@ -926,6 +1055,7 @@ func (o *Object) uploadChunked(in0 io.Reader, commitInfo *files.CommitInfo, size
 		res, err = o.fs.srv.UploadSessionStart(&files.UploadSessionStartArg{}, chunk)
 		return shouldRetry(err)
 	})
+	timer.Done(err)
 	if err != nil {
 		return nil, err
 	}
@@ -954,7 +1084,9 @@ func (o *Object) uploadChunked(in0 io.Reader, commitInfo *files.CommitInfo, size
 		cursor.Offset = in.BytesRead()
 		fmtChunk(currentChunk, false)
 		chunk = readers.NewRepeatableLimitReaderBuffer(in, buf, chunkSize)
+		timer = accounting.NewChunkTimer(o.fs.name, o, int64(currentChunk))
 		err = o.fs.pacer.Call(func() (bool, error) {
+			timer.Try()
 			// seek to the start in case this is a retry
 			if _, err = chunk.Seek(0, io.SeekStart); err != nil {
 				return false, nil
@@ -963,6 +1095,7 @@ func (o *Object) uploadChunked(in0 io.Reader, commitInfo *files.CommitInfo, size
 			// after the first chunk is uploaded, we retry everything
 			return err != nil, err
 		})
+		timer.Done(err)
 		if err != nil {
 			return nil, err
 		}
@@ -977,7 +1110,9 @@ func (o *Object) uploadChunked(in0 io.Reader, commitInfo *files.CommitInfo, size
 	}
 	fmtChunk(currentChunk, true)
 	chunk = readers.NewRepeatableReaderBuffer(in, buf)
+	timer = accounting.NewChunkTimer(o.fs.name, o, int64(currentChunk))
 	err = o.fs.pacer.Call(func() (bool, error) {
+		timer.Try()
 		// seek to the start in case this is a retry
 		if _, err = chunk.Seek(0, io.SeekStart); err != nil {
 			return false, nil
@@ -986,6 +1121,7 @@ func (o *Object) uploadChunked(in0 io.Reader, commitInfo *files.CommitInfo, size
 		// after the first chunk is uploaded, we retry everything
 		return err != nil, err
 	})
+	timer.Done(err)
 	if err != nil {
 		return nil, err
 	}
@@ -997,7 +1133,7 @@ func (o *Object) uploadChunked(in0 io.Reader, commitInfo *files.CommitInfo, size
 // Copy the reader into the object updating modTime and size
 //
 // The new object may have been created if an error is returned
-func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
 	remote := o.remotePath()
 	if ignoredFiles.MatchString(remote) {
 		fs.Logf(o, "File name disallowed - not uploading")
@@ -1026,7 +1162,7 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 }
 
 // Remove an object
-func (o *Object) Remove() (err error) {
+func (o *Object) Remove(ctx context.Context) (err error) {
 	err = o.fs.pacer.Call(func() (bool, error) {
 		_, err = o.fs.srv.DeleteV2(&files.DeleteArg{Path: o.remotePath()})
 		return shouldRetry(err)
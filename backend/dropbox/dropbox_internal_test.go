@@ -0,0 +1,33 @@
+package dropbox
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox"
+	"github.com/dropbox/dropbox-sdk-go-unofficial/dropbox/sharing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkSettingsJSON(t *testing.T) {
+	settings := &linkSettings{
+		RequestedVisibility: &sharing.RequestedVisibility{Tagged: dropbox.Tagged{Tag: sharing.RequestedVisibilityPassword}},
+		LinkPassword:        "secret",
+	}
+	// no expiry is sent unless one is set
+	out, err := json.Marshal(createLinkArg{Path: "/file", Settings: settings})
+	require.NoError(t, err)
+	assert.Equal(t, `{"path":"/file","settings":{"requested_visibility":{".tag":"password"},"link_password":"secret"}}`, string(out))
+
+	expires := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	settings.Expires = &expires
+	out, err = json.Marshal(modifyLinkArg{URL: "https://link", Settings: settings})
+	require.NoError(t, err)
+	assert.Equal(t, `{"url":"https://link","settings":{"requested_visibility":{".tag":"password"},"link_password":"secret","expires":"2019-01-02T03:04:05Z"},"remove_expiration":false}`, string(out))
+
+	out, err = json.Marshal(createLinkArg{Path: "/file"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"path":"/file"}`, string(out))
+}
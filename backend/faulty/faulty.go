@@ -0,0 +1,398 @@
+// Package faulty provides wrappers for Fs and Object which inject
+// errors, latency and truncated reads for testing
+package faulty
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/pkg/errors"
+)
+
+// Register with Fs
+func init() {
+	fs.Register(&fs.RegInfo{
+		Name:        "faulty",
+		Description: "Inject errors and latency into a remote for testing",
+		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
+		Options: []fs.Option{{
+			Name: "remote",
+			Help: "Remote to inject faults into.\nNormally should contain a ':' and a path, eg \"myremote:path/to/dir\",\n\"myremote:bucket\" or maybe \"myremote:\".",
+		}, {
+			Name:     "error_rate",
+			Help:     "Probability of each operation failing with a retriable error, eg 0.1 for 10%.",
+			Optional: true,
+		}, {
+			Name:     "truncate_rate",
+			Help:     "Probability of each download being cut short, eg 0.1 for 10%.",
+			Optional: true,
+		}, {
+			Name:     "latency",
+			Help:     "Delay added to each operation, eg 100ms.",
+			Optional: true,
+		}, {
+			Name:     "seed",
+			Help:     "Seed for the random number generator to make runs repeatable - leave blank for random.",
+			Optional: true,
+		}},
+	})
+}
+
+// errorInjected is returned when a fault is injected
+var errorInjected = fserrors.RetryErrorf("faulty: injected error")
+
+// parseRate reads a probability from the config
+func parseRate(name, key string) (float64, error) {
+	value := config.FileGet(name, key)
+	if value == "" {
+		return 0, nil
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 1 {
+		return 0, errors.Errorf("%s must be a number between 0 and 1, got %q", key, value)
+	}
+	return rate, nil
+}
+
+// NewFs contstructs an Fs from the path, container:path
+func NewFs(name, rpath string) (fs.Fs, error) {
+	remote := config.FileGet(name, "remote")
+	if remote == "" {
+		return nil, errors.New("faulty can't point to an empty remote - check the value of the remote setting")
+	}
+	if strings.HasPrefix(remote, name+":") {
+		return nil, errors.New("can't point faulty remote at itself - check the value of the remote setting")
+	}
+	errorRate, err := parseRate(name, "error_rate")
+	if err != nil {
+		return nil, err
+	}
+	truncateRate, err := parseRate(name, "truncate_rate")
+	if err != nil {
+		return nil, err
+	}
+	var latency time.Duration
+	if value := config.FileGet(name, "latency"); value != "" {
+		latency, err = time.ParseDuration(value)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse latency")
+		}
+	}
+	seed := time.Now().UnixNano()
+	if value := config.FileGet(name, "seed"); value != "" {
+		seed, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse seed")
+		}
+	}
+	remotePath := path.Join(remote, rpath)
+	wrappedFs, err := fs.NewFs(remotePath)
+	if err != fs.ErrorIsFile && err != nil {
+		return nil, errors.Wrapf(err, "failed to make remote %q to wrap", remotePath)
+	}
+	f := &Fs{
+		Fs:           wrappedFs,
+		name:         name,
+		root:         rpath,
+		errorRate:    errorRate,
+		truncateRate: truncateRate,
+		latency:      latency,
+		rnd:          rand.New(rand.NewSource(seed)),
+	}
+	// the features here are ones we could support, and they are
+	// ANDed with the ones from wrappedFs
+	f.features = (&fs.Features{
+		CaseInsensitive:         true,
+		DuplicateFiles:          true,
+		ReadMimeType:            true,
+		WriteMimeType:           true,
+		BucketBased:             true,
+		CanHaveEmptyDirectories: true,
+	}).Fill(f).Mask(wrappedFs).WrapsFs(f, wrappedFs)
+	return f, err
+}
+
+// Fs represents a wrapped fs.Fs
+type Fs struct {
+	fs.Fs
+	name         string
+	root         string
+	features     *fs.Features  // optional features
+	errorRate    float64       // probability of an operation failing
+	truncateRate float64       // probability of a download being truncated
+	latency      time.Duration // added to each operation
+
+	mu  sync.Mutex // protects rnd
+	rnd *rand.Rand // source of faults
+}
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	return f.root
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// String returns a description of the FS
+func (f *Fs) String() string {
+	return fmt.Sprintf("Faulty '%s:%s'", f.name, f.root)
+}
+
+// chance returns true with probability rate
+func (f *Fs) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rnd.Float64() < rate
+}
+
+// fault sleeps for the latency then returns an error with
+// probability errorRate
+func (f *Fs) fault(what string, o interface{}) error {
+	if f.latency > 0 {
+		time.Sleep(f.latency)
+	}
+	if f.chance(f.errorRate) {
+		fs.Debugf(o, "Injecting error into %s", what)
+		return errorInjected
+	}
+	return nil
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	if err = f.fault("List", dir); err != nil {
+		return nil, err
+	}
+	entries, err = f.Fs.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		if o, ok := entry.(fs.Object); ok {
+			entries[i] = f.newObject(o)
+		}
+	}
+	return entries, nil
+}
+
+// NewObject finds the Object at remote.
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	if err := f.fault("NewObject", remote); err != nil {
+		return nil, err
+	}
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+type putFn func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
+
+// put implements Put or PutStream
+func (f *Fs) put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options []fs.OpenOption, put putFn) (fs.Object, error) {
+	if err := f.fault("Put", src.Remote()); err != nil {
+		return nil, err
+	}
+	o, err := put(ctx, in, src, options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// Put in to the remote path with the modTime given of the given size
+//
+// May create the object even if it returns an error - if so
+// will return the object and the error, otherwise will return
+// nil and the error
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.put(ctx, in, src, options, f.Fs.Put)
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.put(ctx, in, src, options, f.Fs.Features().PutStream)
+}
+
+// Mkdir makes the directory (container, bucket)
+//
+// Shouldn't return an error if it already exists
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
+	if err := f.fault("Mkdir", dir); err != nil {
+		return err
+	}
+	return f.Fs.Mkdir(ctx, dir)
+}
+
+// Rmdir removes the directory (container, bucket) if empty
+//
+// Return an error if it doesn't exist or isn't empty
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	if err := f.fault("Rmdir", dir); err != nil {
+		return err
+	}
+	return f.Fs.Rmdir(ctx, dir)
+}
+
+// Purge all files in the root and the root directory
+//
+// Implement this if you have a way of deleting all the files
+// quicker than just running Remove() on the result of List()
+//
+// Return an error if it doesn't exist
+func (f *Fs) Purge(ctx context.Context) error {
+	do := f.Fs.Features().Purge
+	if do == nil {
+		return fs.ErrorCantPurge
+	}
+	if err := f.fault("Purge", f); err != nil {
+		return err
+	}
+	return do(ctx)
+}
+
+// UnWrap returns the Fs that this Fs is wrapping
+func (f *Fs) UnWrap() fs.Fs {
+	return f.Fs
+}
+
+// Object describes a wrapped object which has faults injected
+type Object struct {
+	fs.Object
+	f *Fs
+}
+
+func (f *Fs) newObject(o fs.Object) *Object {
+	return &Object{
+		Object: o,
+		f:      f,
+	}
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.Remote()
+}
+
+// Open an object for read, truncating the data returned with
+// probability truncateRate
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	if err := o.f.fault("Open", o); err != nil {
+		return nil, err
+	}
+	in, err := o.Object.Open(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	if !o.f.chance(o.f.truncateRate) {
+		return in, nil
+	}
+	o.f.mu.Lock()
+	limit := o.f.rnd.Int63n(o.Size() + 1)
+	o.f.mu.Unlock()
+	fs.Debugf(o, "Truncating read after %d bytes", limit)
+	return &truncatedReader{in: in, left: limit}, nil
+}
+
+// SetModTime sets the modification time of the object
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	if err := o.f.fault("SetModTime", o); err != nil {
+		return err
+	}
+	return o.Object.SetModTime(ctx, modTime)
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	if err := o.f.fault("Update", o); err != nil {
+		return err
+	}
+	return o.Object.Update(ctx, in, src, options...)
+}
+
+// Remove an object
+func (o *Object) Remove(ctx context.Context) error {
+	if err := o.f.fault("Remove", o); err != nil {
+		return err
+	}
+	return o.Object.Remove(ctx)
+}
+
+// UnWrap returns the wrapped Object
+func (o *Object) UnWrap() fs.Object {
+	return o.Object
+}
+
+// truncatedReader returns io.ErrUnexpectedEOF after left bytes
+type truncatedReader struct {
+	in   io.ReadCloser
+	left int64
+}
+
+// Read bytes from the reader until left runs out
+func (r *truncatedReader) Read(p []byte) (n int, err error) {
+	if r.left <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err = r.in.Read(p)
+	r.left -= int64(n)
+	return n, err
+}
+
+// Close the underlying reader
+func (r *truncatedReader) Close() error {
+	return r.in.Close()
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs              = (*Fs)(nil)
+	_ fs.Purger          = (*Fs)(nil)
+	_ fs.PutStreamer     = (*Fs)(nil)
+	_ fs.UnWrapper       = (*Fs)(nil)
+	_ fs.Object          = (*Object)(nil)
+	_ fs.ObjectUnWrapper = (*Object)(nil)
+)
@@ -0,0 +1,33 @@
+package faulty
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncatedReader(t *testing.T) {
+	in := ioutil.NopCloser(strings.NewReader("0123456789"))
+	r := &truncatedReader{in: in, left: 4}
+	data, err := ioutil.ReadAll(r)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+	assert.Equal(t, "0123", string(data))
+	assert.NoError(t, r.Close())
+}
+
+func TestChance(t *testing.T) {
+	f := &Fs{rnd: rand.New(rand.NewSource(1))}
+	assert.False(t, f.chance(0))
+	assert.True(t, f.chance(1))
+	hits := 0
+	for i := 0; i < 1000; i++ {
+		if f.chance(0.5) {
+			hits++
+		}
+	}
+	assert.InDelta(t, 500, hits, 100)
+}
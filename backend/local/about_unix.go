@@ -3,6 +3,7 @@
 package local
 
 import (
+	"context"
 	"syscall"
 
 	"github.com/ncw/rclone/fs"
@@ -10,7 +11,7 @@ import (
 )
 
 // About gets quota information
-func (f *Fs) About() (*fs.Usage, error) {
+func (f *Fs) About(ctx context.Context) (*fs.Usage, error) {
 	var s syscall.Statfs_t
 	err := syscall.Statfs(f.root, &s)
 	if err != nil {
@@ -2,6 +2,7 @@
 package local
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -29,6 +30,7 @@ var (
 	skipSymlinks   = flags.BoolP("skip-links", "", false, "Don't warn about skipped symlinks.")
 	noUTFNorm      = flags.BoolP("local-no-unicode-normalization", "", false, "Don't apply unicode normalization to paths and filenames")
 	noCheckUpdated = flags.BoolP("local-no-check-updated", "", false, "Don't check to see if the files change during upload")
+	translateTrail = flags.BoolP("local-windows-translate-trailing", "", false, "On Windows translate trailing dots and spaces in file names to _")
 )
 
 // Constants
@@ -40,6 +42,8 @@ func init() {
 		Name:        "local",
 		Description: "Local Disk",
 		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
 		Options: []fs.Option{{
 			Name:     "nounc",
 			Help:     "Disable UNC (long path names) conversion on Windows",
@@ -64,6 +68,7 @@ type Fs struct {
 	wmu         sync.Mutex          // used for locking access to 'warned'.
 	warned      map[string]struct{} // whether we have warned about this string
 	nounc       bool                // Skip UNC conversion on Windows
+	trailing    bool                // Translate trailing dots and spaces on Windows
 	// do os.Lstat or os.Stat
 	lstat          func(name string) (os.FileInfo, error)
 	dirNames       *mapper    // directory name mapping
@@ -96,6 +101,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		name:     name,
 		warned:   make(map[string]struct{}),
 		nounc:    nounc == "true",
+		trailing: *translateTrail,
 		dev:      devUnset,
 		lstat:    os.Lstat,
 		dirNames: newMapper(),
@@ -104,6 +110,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 	f.features = (&fs.Features{
 		CaseInsensitive:         f.caseInsensitive(),
 		CanHaveEmptyDirectories: true,
+		SlowHash:                true,
 	}).Fill(f)
 	if *followSymlinks {
 		f.lstat = os.Stat
@@ -195,7 +202,7 @@ func (f *Fs) newObjectWithInfo(remote, dstPath string, info os.FileInfo) (fs.Obj
 
 // NewObject finds the Object at remote.  If it can't be found
 // it returns the error ErrorObjectNotFound.
-func (f *Fs) NewObject(remote string) (fs.Object, error) {
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
 	return f.newObjectWithInfo(remote, "", nil)
 }
 
@@ -208,7 +215,7 @@ func (f *Fs) NewObject(remote string) (fs.Object, error) {
 //
 // This should return ErrDirNotFound if the directory isn't
 // found.
-func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	dir = f.dirNames.Load(dir)
 	fsDirPath := f.cleanPath(filepath.Join(f.root, dir))
 	remote := f.cleanRemote(dir)
@@ -328,11 +335,11 @@ func (m *mapper) Save(in, out string) string {
 }
 
 // Put the Object to the local filesystem
-func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	remote := src.Remote()
 	// Temporary Object under construction - info filled in by Update()
 	o := f.newObject(remote, "")
-	err := o.Update(in, src, options...)
+	err := o.Update(ctx, in, src, options...)
 	if err != nil {
 		return nil, err
 	}
@@ -340,12 +347,12 @@ func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.
 }
 
 // PutStream uploads to the remote path with the modTime given of indeterminate size
-func (f *Fs) PutStream(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
-	return f.Put(in, src, options...)
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.Put(ctx, in, src, options...)
 }
 
 // Mkdir creates the directory if it doesn't exist
-func (f *Fs) Mkdir(dir string) error {
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
 	// FIXME: https://github.com/syncthing/syncthing/blob/master/lib/osutil/mkdirall_windows.go
 	root := f.cleanPath(filepath.Join(f.root, dir))
 	err := os.MkdirAll(root, 0777)
@@ -362,10 +369,16 @@ func (f *Fs) Mkdir(dir string) error {
 	return nil
 }
 
+// DirSetModTime sets the modification time of the directory dir
+func (f *Fs) DirSetModTime(ctx context.Context, dir string, modTime time.Time) error {
+	root := f.cleanPath(filepath.Join(f.root, dir))
+	return os.Chtimes(root, modTime, modTime)
+}
+
 // Rmdir removes the directory
 //
 // If it isn't empty it will return an error
-func (f *Fs) Rmdir(dir string) error {
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 	root := f.cleanPath(filepath.Join(f.root, dir))
 	return os.Remove(root)
 }
@@ -435,7 +448,7 @@ func (f *Fs) readPrecision() (precision time.Duration) {
 // Optional interface: Only implement this if you have a way of
 // deleting all the files quicker than just running Remove() on the
 // result of List()
-func (f *Fs) Purge() error {
+func (f *Fs) Purge(ctx context.Context) error {
 	fi, err := f.lstat(f.root)
 	if err != nil {
 		return err
@@ -455,7 +468,7 @@ func (f *Fs) Purge() error {
 // Will only be called if src.Fs().Name() == f.Name()
 //
 // If it isn't possible then return fs.ErrorCantMove
-func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
+func (f *Fs) Move(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
 	srcObj, ok := src.(*Object)
 	if !ok {
 		fs.Debugf(src, "Can't move - not same remote type")
@@ -514,7 +527,7 @@ func (f *Fs) Move(src fs.Object, remote string) (fs.Object, error) {
 // If it isn't possible then return fs.ErrorCantDirMove
 //
 // If destination exists then return fs.ErrorDirExists
-func (f *Fs) DirMove(src fs.Fs, srcRemote, dstRemote string) error {
+func (f *Fs) DirMove(ctx context.Context, src fs.Fs, srcRemote, dstRemote string) error {
 	srcFs, ok := src.(*Fs)
 	if !ok {
 		fs.Debugf(srcFs, "Can't move directory - not same remote type")
@@ -592,27 +605,54 @@ func (o *Object) Hash(r hash.Type) (string, error) {
 	hashes := o.hashes
 	o.fs.objectHashesMu.Unlock()
 
+	// hashes not in hash.Default are only read when asked for
+	set := hash.Default
+	if hash.Supported.Contains(r) {
+		set.Add(r)
+	}
 	if !o.modTime.Equal(oldtime) || oldsize != o.size || hashes == nil {
-		hashes = make(map[hash.Type]string)
-		in, err := os.Open(o.path)
+		hashes, err = o.streamHashes(set)
 		if err != nil {
-			return "", errors.Wrap(err, "hash: failed to open")
+			return "", err
 		}
-		hashes, err = hash.Stream(in)
-		closeErr := in.Close()
+		o.fs.objectHashesMu.Lock()
+		o.hashes = hashes
+		o.fs.objectHashesMu.Unlock()
+	} else if _, found := hashes[r]; !found && set.Contains(r) {
+		sums, err := o.streamHashes(hash.NewHashSet(r))
 		if err != nil {
-			return "", errors.Wrap(err, "hash: failed to read")
+			return "", err
 		}
-		if closeErr != nil {
-			return "", errors.Wrap(closeErr, "hash: failed to close")
+		newHashes := make(map[hash.Type]string, len(hashes)+1)
+		for ht, sum := range hashes {
+			newHashes[ht] = sum
 		}
+		newHashes[r] = sums[r]
 		o.fs.objectHashesMu.Lock()
-		o.hashes = hashes
+		o.hashes = newHashes
 		o.fs.objectHashesMu.Unlock()
+		hashes = newHashes
 	}
 	return hashes[r], nil
 }
 
+// streamHashes reads the file to compute the hashes in set
+func (o *Object) streamHashes(set hash.Set) (map[hash.Type]string, error) {
+	in, err := os.Open(o.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "hash: failed to open")
+	}
+	hashes, err := hash.StreamTypes(in, set)
+	closeErr := in.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "hash: failed to read")
+	}
+	if closeErr != nil {
+		return nil, errors.Wrap(closeErr, "hash: failed to close")
+	}
+	return hashes, nil
+}
+
 // Size returns the size of an object in bytes
 func (o *Object) Size() int64 {
 	return o.size
@@ -624,7 +664,7 @@ func (o *Object) ModTime() time.Time {
 }
 
 // SetModTime sets the modification time of the local fs object
-func (o *Object) SetModTime(modTime time.Time) error {
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
 	err := os.Chtimes(o.path, modTime, modTime)
 	if err != nil {
 		return err
@@ -633,6 +673,42 @@ func (o *Object) SetModTime(modTime time.Time) error {
 	return o.lstat()
 }
 
+// Metadata returns the times of the file - mtime and, where the OS
+// provides them, atime and btime
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	info, err := o.fs.lstat(o.path)
+	if err != nil {
+		return nil, err
+	}
+	metadata := fs.Metadata{}
+	metadata.SetTime("mtime", info.ModTime())
+	readTimes(info, metadata)
+	return metadata, nil
+}
+
+// SetMetadata sets the times of the file from the metadata.  The
+// btime can only be set on Windows.
+func (o *Object) SetMetadata(ctx context.Context, metadata fs.Metadata) error {
+	if btime, ok := metadata.Time("btime"); ok {
+		err := setBirthTime(o.path, btime)
+		if err != nil {
+			return errors.Wrap(err, "failed to set creation time")
+		}
+	}
+	if atime, ok := metadata.Time("atime"); ok {
+		mtime, ok := metadata.Time("mtime")
+		if !ok {
+			mtime = o.modTime
+		}
+		err := os.Chtimes(o.path, atime, mtime)
+		if err != nil {
+			return err
+		}
+	}
+	// Re-read metadata
+	return o.lstat()
+}
+
 // Storable returns a boolean showing if this object is storable
 func (o *Object) Storable() bool {
 	// Check for control characters in the remote name and show non storable
@@ -710,9 +786,9 @@ func (file *localOpenFile) Close() (err error) {
 }
 
 // Open an object for read
-func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
 	var offset, limit int64 = 0, -1
-	hashes := hash.Supported
+	hashes := hash.Default
 	for _, option := range options {
 		switch x := option.(type) {
 		case *fs.SeekOption:
@@ -760,8 +836,8 @@ func (o *Object) mkdirAll() error {
 }
 
 // Update the object from in with modTime and size
-func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
-	hashes := hash.Supported
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	hashes := hash.Default
 	for _, option := range options {
 		switch x := option.(type) {
 		case *fs.HashesOption:
@@ -805,7 +881,7 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 	o.fs.objectHashesMu.Unlock()
 
 	// Set the mtime
-	err = o.SetModTime(src.ModTime())
+	err = o.SetModTime(ctx, src.ModTime())
 	if err != nil {
 		return err
 	}
@@ -839,7 +915,7 @@ func (o *Object) lstat() error {
 }
 
 // Remove an object
-func (o *Object) Remove() error {
+func (o *Object) Remove(ctx context.Context) error {
 	return remove(o.path)
 }
 
@@ -925,7 +1001,49 @@ func uncPath(s string) string {
 	return s
 }
 
-// cleanWindowsName will clean invalid Windows characters replacing them with _
+// windowsReserved matches the names Windows reserves for devices,
+// which may have an extension
+var windowsReserved = regexp.MustCompile(`(?i)^(CON|PRN|AUX|NUL|COM[1-9]|LPT[1-9])(\..*)?$`)
+
+// cleanWindowsElement escapes a reserved device name by adding _, eg
+// "CON.txt" becomes "CON_.txt", and if trailing is set translates
+// trailing dots and spaces, which Windows strips, to _
+func cleanWindowsElement(elem string, trailing bool) string {
+	if elem == "." || elem == ".." {
+		return elem
+	}
+	if match := windowsReserved.FindStringSubmatch(elem); match != nil {
+		elem = match[1] + "_" + match[2]
+	}
+	if trailing {
+		trimmed := strings.TrimRight(elem, ". ")
+		elem = trimmed + strings.Repeat("_", len(elem)-len(trimmed))
+	}
+	return elem
+}
+
+// cleanWindowsElements applies cleanWindowsElement to each element
+// of the path
+func cleanWindowsElements(name string, trailing bool) string {
+	var out []byte
+	start := 0
+	for i := 0; i <= len(name); i++ {
+		if i == len(name) || name[i] == '\\' || name[i] == '/' {
+			out = append(out, cleanWindowsElement(name[start:i], trailing)...)
+			if i < len(name) {
+				out = append(out, name[i])
+			}
+			start = i + 1
+		}
+	}
+	return string(out)
+}
+
+// cleanWindowsName will clean invalid Windows characters replacing
+// them with _ and escape the names Windows reserves for devices.
+//
+// If f has trailing set then trailing dots and spaces are replaced
+// with _ too.
 func cleanWindowsName(f *Fs, name string) string {
 	original := name
 	var name2 string
@@ -945,13 +1063,13 @@ func cleanWindowsName(f *Fs, name string) string {
 		name = name[colonAt+1:]
 	}
 
-	name2 += strings.Map(func(r rune) rune {
+	name2 += cleanWindowsElements(strings.Map(func(r rune) rune {
 		switch r {
 		case '<', '>', '"', '|', '?', '*', ':':
 			return '_'
 		}
 		return r
-	}, name)
+	}, name), f != nil && f.trailing)
 
 	if name2 != original && f != nil {
 		f.wmu.Lock()
@@ -966,10 +1084,11 @@ func cleanWindowsName(f *Fs, name string) string {
 
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Purger      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.Mover       = &Fs{}
-	_ fs.DirMover    = &Fs{}
-	_ fs.Object      = &Object{}
+	_ fs.Fs             = &Fs{}
+	_ fs.Purger         = &Fs{}
+	_ fs.PutStreamer    = &Fs{}
+	_ fs.Mover          = &Fs{}
+	_ fs.DirMover       = &Fs{}
+	_ fs.DirSetModTimer = &Fs{}
+	_ fs.Object         = &Object{}
 )
@@ -1,12 +1,15 @@
 package local
 
 import (
+	"context"
 	"os"
 	"path"
 	"testing"
 	"time"
 
+	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fstest"
 	"github.com/ncw/rclone/lib/readers"
 	"github.com/stretchr/testify/assert"
@@ -74,3 +77,66 @@ func TestUpdatingCheck(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+// Test the times are read and set as metadata and copied with --metadata
+func TestMetadata(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	t1 := time.Date(2010, 1, 2, 3, 4, 5, 6000, time.UTC)
+	t2 := time.Date(2011, 2, 3, 4, 5, 6, 7000, time.UTC)
+	r.WriteFile("file", "content", t1)
+
+	o, err := r.Flocal.NewObject(ctx, "file")
+	require.NoError(t, err)
+	metadata, err := fs.GetMetadata(ctx, o)
+	require.NoError(t, err)
+	mtime, ok := metadata.Time("mtime")
+	require.True(t, ok)
+	assert.True(t, mtime.Equal(t1))
+
+	metadata = fs.Metadata{}
+	metadata.SetTime("atime", t2)
+	require.NoError(t, fs.SetMetadata(ctx, o, metadata))
+	metadata, err = fs.GetMetadata(ctx, o)
+	require.NoError(t, err)
+	mtime, _ = metadata.Time("mtime")
+	assert.True(t, mtime.Equal(t1), "mtime should be unchanged")
+	atime, ok := metadata.Time("atime")
+	if !ok {
+		t.Skip("access time not supported on this OS")
+	}
+	assert.True(t, atime.Equal(t2))
+
+	// copying with --metadata keeps the access time
+	fs.Config.Metadata = true
+	defer func() {
+		fs.Config.Metadata = false
+	}()
+	newDst, err := operations.Copy(ctx, r.Fremote, nil, "file", o)
+	require.NoError(t, err)
+	metadata, err = fs.GetMetadata(ctx, newDst)
+	require.NoError(t, err)
+	atime, _ = metadata.Time("atime")
+	assert.True(t, atime.Equal(t2))
+}
+
+func TestHashOnlyWhenAsked(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.WriteFile("file", "content", time.Now())
+	o, err := r.Flocal.NewObject(context.Background(), "file")
+	require.NoError(t, err)
+	obj := o.(*Object)
+
+	md5sum, err := obj.Hash(hash.MD5)
+	require.NoError(t, err)
+	assert.Equal(t, "9a0364b9e99bb480dd25e1f0284c8555", md5sum)
+	_, found := obj.hashes[hash.SHA256]
+	assert.False(t, found, "SHA-256 shouldn't be read unless asked for")
+
+	sha256sum, err := obj.Hash(hash.SHA256)
+	require.NoError(t, err)
+	assert.Equal(t, "ed7002b439e9ac845f22357d822bac1444730fbdb6016d3ec9432297b9ec9f73", sha256sum)
+	assert.Equal(t, md5sum, obj.hashes[hash.MD5])
+}
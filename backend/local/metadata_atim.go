@@ -0,0 +1,28 @@
+// File time reading functions
+
+// +build dragonfly linux openbsd solaris
+
+package local
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// readTimes adds the access time of the file to metadata.  The
+// creation time isn't available on these systems.
+func readTimes(fi os.FileInfo, metadata fs.Metadata) {
+	statT, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	metadata.SetTime("atime", time.Unix(int64(statT.Atim.Sec), int64(statT.Atim.Nsec)))
+}
+
+// setBirthTime can't set the creation time on these systems
+func setBirthTime(name string, t time.Time) error {
+	return nil
+}
@@ -0,0 +1,29 @@
+// File time reading functions
+
+// +build darwin freebsd netbsd
+
+package local
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// readTimes adds the access and creation times of the file to
+// metadata
+func readTimes(fi os.FileInfo, metadata fs.Metadata) {
+	statT, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return
+	}
+	metadata.SetTime("atime", time.Unix(int64(statT.Atimespec.Sec), int64(statT.Atimespec.Nsec)))
+	metadata.SetTime("btime", time.Unix(int64(statT.Birthtimespec.Sec), int64(statT.Birthtimespec.Nsec)))
+}
+
+// setBirthTime can't set the creation time on these systems
+func setBirthTime(name string, t time.Time) error {
+	return nil
+}
@@ -0,0 +1,21 @@
+// File time reading functions
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package local
+
+import (
+	"os"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// readTimes can't read any more times of the file on these systems
+func readTimes(fi os.FileInfo, metadata fs.Metadata) {
+}
+
+// setBirthTime can't set the creation time on these systems
+func setBirthTime(name string, t time.Time) error {
+	return nil
+}
@@ -0,0 +1,41 @@
+// File time reading functions
+
+// +build windows
+
+package local
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// readTimes adds the access and creation times of the file to
+// metadata
+func readTimes(fi os.FileInfo, metadata fs.Metadata) {
+	data, ok := fi.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return
+	}
+	metadata.SetTime("atime", time.Unix(0, data.LastAccessTime.Nanoseconds()))
+	metadata.SetTime("btime", time.Unix(0, data.CreationTime.Nanoseconds()))
+}
+
+// setBirthTime sets the creation time of the file
+func setBirthTime(name string, t time.Time) error {
+	pathp, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	h, err := syscall.CreateFile(pathp, syscall.FILE_WRITE_ATTRIBUTES, syscall.FILE_SHARE_WRITE, nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = syscall.Close(h)
+	}()
+	ctime := syscall.NsecToFiletime(t.UnixNano())
+	return syscall.SetFileTime(h, &ctime, nil, nil)
+}
@@ -78,6 +78,10 @@ var testsWindows = [][2]string{
 	{"/temp/file.txt", "/temp/file.txt"},
 	{`!\"#¤%&/()=;:*^?+-`, "!\\_#¤%&/()=;__^_+-"},
 	{`<>"|?*:&\<>"|?*:&\<>"|?*:&`, "_______&\\_______&\\_______&"},
+	{`c:\temp\CON`, `c:\temp\CON_`},
+	{`\\?\c:\nul.txt\com1\Lpt9.tar.gz`, `\\?\c:\nul_.txt\com1_\Lpt9_.tar.gz`},
+	{`c:\console\CONx\aux\file`, `c:\console\CONx\aux_\file`},
+	{`c:\temp\dots...\spaces  `, `c:\temp\dots...\spaces  `},
 }
 
 func TestCleanWindows(t *testing.T) {
@@ -89,3 +93,24 @@ func TestCleanWindows(t *testing.T) {
 		}
 	}
 }
+
+// Test Windows trailing dot and space replacements
+var testsWindowsTrailing = [][2]string{
+	{`c:\temp\dots...\spaces  `, `c:\temp\dots___\spaces__`},
+	{`c:\temp\.\..\file. txt`, `c:\temp\.\..\file. txt`},
+	{`\\?\c:\CON.\file`, `\\?\c:\CON__\file`},
+}
+
+func TestCleanWindowsTrailing(t *testing.T) {
+	f := &Fs{
+		warned:   make(map[string]struct{}),
+		trailing: true,
+	}
+	for _, test := range testsWindowsTrailing {
+		got := cleanWindowsName(f, test[0])
+		expect := test[1]
+		if got != expect {
+			t.Fatalf("got %q, expected %q", got, expect)
+		}
+	}
+}
@@ -14,6 +14,7 @@ What happens if you CTRL-C a multipart upload
 */
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
@@ -43,6 +44,7 @@ import (
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/walk"
 	"github.com/ncw/rclone/lib/rest"
+	"github.com/ncw/rclone/lib/version"
 	"github.com/ncw/swift"
 	"github.com/pkg/errors"
 )
@@ -53,6 +55,8 @@ func init() {
 		Name:        "s3",
 		Description: "Amazon S3 Compliant Storage Providers (AWS, Ceph, Dreamhost, IBM COS, Minio)",
 		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
 		Options: []fs.Option{{
 			Name: fs.ConfigProvider,
 			Help: "Choose your S3 provider.",
@@ -532,6 +536,7 @@ const (
 	maxRetries     = 10                            // number of retries to make of operations
 	maxSizeForCopy = 5 * 1024 * 1024 * 1024        // The maximum size of object we can COPY
 	maxFileSize    = 5 * 1024 * 1024 * 1024 * 1024 // largest possible upload file size
+	maxUploadAge   = 24 * time.Hour                // multipart uploads older than this are aborted by CleanUp
 )
 
 // Globals
@@ -573,6 +578,8 @@ type Object struct {
 	lastModified time.Time          // Last modified
 	meta         map[string]*string // The object metadata if known - may be nil
 	mimeType     string             // MimeType of object - may be ""
+	storageClass string             // storage class of the object - may be ""
+	versionID    *string            // version of the object if it is an old one - nil otherwise
 }
 
 // ------------------------------------------------------------
@@ -683,7 +690,7 @@ func s3Connection(name string) (*s3.S3, *session.Session, error) {
 		WithMaxRetries(maxRetries).
 		WithCredentials(cred).
 		WithEndpoint(endpoint).
-		WithHTTPClient(fshttp.NewClient(fs.Config)).
+		WithHTTPClient(fshttp.NewRemoteClient(fs.Config, name)).
 		WithS3ForcePathStyle(true)
 	// awsConfig.WithLogLevel(aws.LogDebugWithSigning)
 	ses := session.New()
@@ -729,6 +736,10 @@ func NewFs(name, root string) (fs.Fs, error) {
 		ReadMimeType:  true,
 		WriteMimeType: true,
 		BucketBased:   true,
+		SetTier:       true,
+		GetTier:       true,
+		Versions:      true,
+		ObjectLock:    true,
 	}).Fill(f)
 	if *s3ACL != "" {
 		f.acl = *s3ACL
@@ -747,6 +758,11 @@ func NewFs(name, root string) (fs.Fs, error) {
 			Key:    &directory,
 		}
 		_, err = f.c.HeadObject(&req)
+		if err != nil && fs.Config.Versions {
+			if t, baseKey := version.Remove(directory); !t.IsZero() {
+				_, err = f.findVersion(context.TODO(), baseKey, t)
+			}
+		}
 		if err == nil {
 			f.root = path.Dir(directory)
 			if f.root == "." {
@@ -758,17 +774,17 @@ func NewFs(name, root string) (fs.Fs, error) {
 			return f, fs.ErrorIsFile
 		}
 	}
-	// f.listMultipartUploads()
 	return f, nil
 }
 
 // Return an Object from a path
 //
 //If it can't be found it returns the error ErrorObjectNotFound.
-func (f *Fs) newObjectWithInfo(remote string, info *s3.Object) (fs.Object, error) {
+func (f *Fs) newObjectWithInfo(ctx context.Context, remote string, info *s3.Object, versionID *string) (fs.Object, error) {
 	o := &Object{
-		fs:     f,
-		remote: remote,
+		fs:        f,
+		remote:    remote,
+		versionID: versionID,
 	}
 	if info != nil {
 		// Set info but not meta
@@ -780,8 +796,9 @@ func (f *Fs) newObjectWithInfo(remote string, info *s3.Object) (fs.Object, error
 		}
 		o.etag = aws.StringValue(info.ETag)
 		o.bytes = aws.Int64Value(info.Size)
+		o.storageClass = aws.StringValue(info.StorageClass)
 	} else {
-		err := o.readMetaData() // reads info and meta, returning an error
+		err := o.readMetaData(ctx) // reads info and meta, returning an error
 		if err != nil {
 			return nil, err
 		}
@@ -791,19 +808,76 @@ func (f *Fs) newObjectWithInfo(remote string, info *s3.Object) (fs.Object, error
 
 // NewObject finds the Object at remote.  If it can't be found
 // it returns the error fs.ErrorObjectNotFound.
-func (f *Fs) NewObject(remote string) (fs.Object, error) {
-	return f.newObjectWithInfo(remote, nil)
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	if fs.Config.Versions {
+		if t, baseRemote := version.Remove(remote); !t.IsZero() {
+			info, err := f.findVersion(ctx, f.root+baseRemote, t)
+			if err != nil {
+				return nil, err
+			}
+			return f.newObjectWithInfo(ctx, remote, versionToObject(info), info.VersionId)
+		}
+	}
+	return f.newObjectWithInfo(ctx, remote, nil, nil)
+}
+
+// findVersion finds the old version of key which was made at time t
+//
+// If it can't be found it returns the error ErrorObjectNotFound.
+func (f *Fs) findVersion(ctx context.Context, key string, t time.Time) (*s3.ObjectVersion, error) {
+	req := s3.ListObjectVersionsInput{
+		Bucket: &f.bucket,
+		Prefix: &key,
+	}
+	for {
+		resp, err := f.c.ListObjectVersionsWithContext(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range resp.Versions {
+			if aws.StringValue(info.Key) != key || aws.BoolValue(info.IsLatest) {
+				continue
+			}
+			if version.Equal(aws.TimeValue(info.LastModified), t) {
+				return info, nil
+			}
+		}
+		if !aws.BoolValue(resp.IsTruncated) {
+			break
+		}
+		req.KeyMarker = resp.NextKeyMarker
+		req.VersionIdMarker = resp.NextVersionIdMarker
+	}
+	return nil, fs.ErrorObjectNotFound
+}
+
+// versionToObject converts an ObjectVersion into the Object listings
+// return so they can be treated the same
+func versionToObject(info *s3.ObjectVersion) *s3.Object {
+	return &s3.Object{
+		ETag:         info.ETag,
+		Key:          info.Key,
+		LastModified: info.LastModified,
+		Owner:        info.Owner,
+		Size:         info.Size,
+		StorageClass: info.StorageClass,
+	}
 }
 
 // listFn is called from list to handle an object.
-type listFn func(remote string, object *s3.Object, isDirectory bool) error
+//
+// versionID is set if the object is an old version
+type listFn func(remote string, object *s3.Object, versionID *string, isDirectory bool) error
 
 // list the objects into the function supplied
 //
 // dir is the starting directory, "" for root
 //
 // Set recurse to read sub directories
-func (f *Fs) list(dir string, recurse bool, fn listFn) error {
+func (f *Fs) list(ctx context.Context, dir string, recurse bool, fn listFn) error {
+	if fs.Config.Versions {
+		return f.listVersions(ctx, dir, recurse, fn)
+	}
 	root := f.root
 	if dir != "" {
 		root += dir + "/"
@@ -823,7 +897,7 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 			MaxKeys:   &maxKeys,
 			Marker:    marker,
 		}
-		resp, err := f.c.ListObjects(&req)
+		resp, err := f.c.ListObjectsWithContext(ctx, &req)
 		if err != nil {
 			if awsErr, ok := err.(awserr.RequestFailure); ok {
 				if awsErr.StatusCode() == http.StatusNotFound {
@@ -848,7 +922,7 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 				if strings.HasSuffix(remote, "/") {
 					remote = remote[:len(remote)-1]
 				}
-				err = fn(remote, &s3.Object{Key: &remote}, true)
+				err = fn(remote, &s3.Object{Key: &remote}, nil, true)
 				if err != nil {
 					return err
 				}
@@ -866,14 +940,14 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 				if recurse {
 					// add a directory in if --fast-list since will have no prefixes
 					remote = remote[:len(remote)-1]
-					err = fn(remote, &s3.Object{Key: &remote}, true)
+					err = fn(remote, &s3.Object{Key: &remote}, nil, true)
 					if err != nil {
 						return err
 					}
 				}
 				continue // skip directory marker
 			}
-			err = fn(remote, object, false)
+			err = fn(remote, object, nil, false)
 			if err != nil {
 				return err
 			}
@@ -894,8 +968,94 @@ func (f *Fs) list(dir string, recurse bool, fn listFn) error {
 	return nil
 }
 
+// listVersions lists all the versions of the objects into the
+// function supplied.  Old versions have a version string added to
+// their remote and delete markers are skipped.
+//
+// dir is the starting directory, "" for root
+//
+// Set recurse to read sub directories
+func (f *Fs) listVersions(ctx context.Context, dir string, recurse bool, fn listFn) error {
+	root := f.root
+	if dir != "" {
+		root += dir + "/"
+	}
+	maxKeys := int64(listChunkSize)
+	delimiter := ""
+	if !recurse {
+		delimiter = "/"
+	}
+	req := s3.ListObjectVersionsInput{
+		Bucket:    &f.bucket,
+		Delimiter: &delimiter,
+		Prefix:    &root,
+		MaxKeys:   &maxKeys,
+	}
+	rootLength := len(f.root)
+	for {
+		resp, err := f.c.ListObjectVersionsWithContext(ctx, &req)
+		if err != nil {
+			if awsErr, ok := err.(awserr.RequestFailure); ok {
+				if awsErr.StatusCode() == http.StatusNotFound {
+					err = fs.ErrorDirNotFound
+				}
+			}
+			return err
+		}
+		if !recurse {
+			for _, commonPrefix := range resp.CommonPrefixes {
+				remote := aws.StringValue(commonPrefix.Prefix)
+				if !strings.HasPrefix(remote, f.root) {
+					fs.Logf(f, "Odd name received %q", remote)
+					continue
+				}
+				remote = strings.TrimSuffix(remote[rootLength:], "/")
+				err = fn(remote, &s3.Object{Key: &remote}, nil, true)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		for _, info := range resp.Versions {
+			key := aws.StringValue(info.Key)
+			if !strings.HasPrefix(key, f.root) {
+				fs.Logf(f, "Odd name received %q", key)
+				continue
+			}
+			remote := key[rootLength:]
+			// is this a directory marker?
+			if (strings.HasSuffix(remote, "/") || remote == "") && aws.Int64Value(info.Size) == 0 {
+				if recurse && aws.BoolValue(info.IsLatest) && remote != "" {
+					// add a directory in if --fast-list since will have no prefixes
+					remote = remote[:len(remote)-1]
+					err = fn(remote, &s3.Object{Key: &remote}, nil, true)
+					if err != nil {
+						return err
+					}
+				}
+				continue // skip directory marker
+			}
+			var versionID *string
+			if !aws.BoolValue(info.IsLatest) {
+				remote = version.Add(remote, aws.TimeValue(info.LastModified))
+				versionID = info.VersionId
+			}
+			err = fn(remote, versionToObject(info), versionID, false)
+			if err != nil {
+				return err
+			}
+		}
+		if !aws.BoolValue(resp.IsTruncated) {
+			break
+		}
+		req.KeyMarker = resp.NextKeyMarker
+		req.VersionIdMarker = resp.NextVersionIdMarker
+	}
+	return nil
+}
+
 // Convert a list item into a DirEntry
-func (f *Fs) itemToDirEntry(remote string, object *s3.Object, isDirectory bool) (fs.DirEntry, error) {
+func (f *Fs) itemToDirEntry(ctx context.Context, remote string, object *s3.Object, versionID *string, isDirectory bool) (fs.DirEntry, error) {
 	if isDirectory {
 		size := int64(0)
 		if object.Size != nil {
@@ -904,7 +1064,7 @@ func (f *Fs) itemToDirEntry(remote string, object *s3.Object, isDirectory bool)
 		d := fs.NewDir(remote, time.Time{}).SetSize(size)
 		return d, nil
 	}
-	o, err := f.newObjectWithInfo(remote, object)
+	o, err := f.newObjectWithInfo(ctx, remote, object, versionID)
 	if err != nil {
 		return nil, err
 	}
@@ -922,10 +1082,10 @@ func (f *Fs) markBucketOK() {
 }
 
 // listDir lists files and directories to out
-func (f *Fs) listDir(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) listDir(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	// List the objects and directories
-	err = f.list(dir, false, func(remote string, object *s3.Object, isDirectory bool) error {
-		entry, err := f.itemToDirEntry(remote, object, isDirectory)
+	err = f.list(ctx, dir, false, func(remote string, object *s3.Object, versionID *string, isDirectory bool) error {
+		entry, err := f.itemToDirEntry(ctx, remote, object, versionID, isDirectory)
 		if err != nil {
 			return err
 		}
@@ -943,12 +1103,12 @@ func (f *Fs) listDir(dir string) (entries fs.DirEntries, err error) {
 }
 
 // listBuckets lists the buckets to out
-func (f *Fs) listBuckets(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) listBuckets(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	if dir != "" {
 		return nil, fs.ErrorListBucketRequired
 	}
 	req := s3.ListBucketsInput{}
-	resp, err := f.c.ListBuckets(&req)
+	resp, err := f.c.ListBucketsWithContext(ctx, &req)
 	if err != nil {
 		return nil, err
 	}
@@ -968,11 +1128,11 @@ func (f *Fs) listBuckets(dir string) (entries fs.DirEntries, err error) {
 //
 // This should return ErrDirNotFound if the directory isn't
 // found.
-func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	if f.bucket == "" {
-		return f.listBuckets(dir)
+		return f.listBuckets(ctx, dir)
 	}
-	return f.listDir(dir)
+	return f.listDir(ctx, dir)
 }
 
 // ListR lists the objects and directories of the Fs starting
@@ -991,13 +1151,13 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 //
 // Don't implement this unless you have a more efficient way
 // of listing recursively that doing a directory traversal.
-func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
+func (f *Fs) ListR(ctx context.Context, dir string, callback fs.ListRCallback) (err error) {
 	if f.bucket == "" {
 		return fs.ErrorListBucketRequired
 	}
 	list := walk.NewListRHelper(callback)
-	err = f.list(dir, true, func(remote string, object *s3.Object, isDirectory bool) error {
-		entry, err := f.itemToDirEntry(remote, object, isDirectory)
+	err = f.list(ctx, dir, true, func(remote string, object *s3.Object, versionID *string, isDirectory bool) error {
+		entry, err := f.itemToDirEntry(ctx, remote, object, versionID, isDirectory)
 		if err != nil {
 			return err
 		}
@@ -1012,28 +1172,28 @@ func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
 }
 
 // Put the Object into the bucket
-func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	// Temporary Object under construction
 	fs := &Object{
 		fs:     f,
 		remote: src.Remote(),
 	}
-	return fs, fs.Update(in, src, options...)
+	return fs, fs.Update(ctx, in, src, options...)
 }
 
 // PutStream uploads to the remote path with the modTime given of indeterminate size
-func (f *Fs) PutStream(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
-	return f.Put(in, src, options...)
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.Put(ctx, in, src, options...)
 }
 
 // Check if the bucket exists
 //
 // NB this can return incorrect results if called immediately after bucket deletion
-func (f *Fs) dirExists() (bool, error) {
+func (f *Fs) dirExists(ctx context.Context) (bool, error) {
 	req := s3.HeadBucketInput{
 		Bucket: &f.bucket,
 	}
-	_, err := f.c.HeadBucket(&req)
+	_, err := f.c.HeadBucketWithContext(ctx, &req)
 	if err == nil {
 		return true, nil
 	}
@@ -1046,14 +1206,14 @@ func (f *Fs) dirExists() (bool, error) {
 }
 
 // Mkdir creates the bucket if it doesn't exist
-func (f *Fs) Mkdir(dir string) error {
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
 	f.bucketOKMu.Lock()
 	defer f.bucketOKMu.Unlock()
 	if f.bucketOK {
 		return nil
 	}
 	if !f.bucketDeleted {
-		exists, err := f.dirExists()
+		exists, err := f.dirExists(ctx)
 		if err == nil {
 			f.bucketOK = exists
 		}
@@ -1070,7 +1230,7 @@ func (f *Fs) Mkdir(dir string) error {
 			LocationConstraint: &f.locationConstraint,
 		}
 	}
-	_, err := f.c.CreateBucket(&req)
+	_, err := f.c.CreateBucketWithContext(ctx, &req)
 	if err, ok := err.(awserr.Error); ok {
 		if err.Code() == "BucketAlreadyOwnedByYou" {
 			err = nil
@@ -1086,7 +1246,7 @@ func (f *Fs) Mkdir(dir string) error {
 // Rmdir deletes the bucket if the fs is at the root
 //
 // Returns an error if it isn't empty
-func (f *Fs) Rmdir(dir string) error {
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
 	f.bucketOKMu.Lock()
 	defer f.bucketOKMu.Unlock()
 	if f.root != "" || dir != "" {
@@ -1095,7 +1255,7 @@ func (f *Fs) Rmdir(dir string) error {
 	req := s3.DeleteBucketInput{
 		Bucket: &f.bucket,
 	}
-	_, err := f.c.DeleteBucket(&req)
+	_, err := f.c.DeleteBucketWithContext(ctx, &req)
 	if err == nil {
 		f.bucketOK = false
 		f.bucketDeleted = true
@@ -1103,6 +1263,50 @@ func (f *Fs) Rmdir(dir string) error {
 	return err
 }
 
+// CleanUp aborts any multipart uploads in the remote which were
+// started more than maxUploadAge ago.
+//
+// S3 keeps the parts of abandoned multipart uploads (and charges for
+// them) until they are aborted.  Recent uploads are left alone as
+// they may still be in progress.
+func (f *Fs) CleanUp(ctx context.Context) error {
+	req := s3.ListMultipartUploadsInput{
+		Bucket: &f.bucket,
+		Prefix: &f.root,
+	}
+	var errs []error
+	err := f.c.ListMultipartUploadsPagesWithContext(ctx, &req, func(resp *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		for _, upload := range resp.Uploads {
+			key := aws.StringValue(upload.Key)
+			initiated := aws.TimeValue(upload.Initiated)
+			if time.Since(initiated) < maxUploadAge {
+				fs.Debugf(f, "Not aborting multipart upload of %q started at %v", key, initiated)
+				continue
+			}
+			fs.Infof(f, "Aborting multipart upload of %q started at %v", key, initiated)
+			_, err := f.c.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   &f.bucket,
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "failed to abort multipart upload of %q", key))
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to list multipart uploads")
+	}
+	for _, err := range errs {
+		fs.Errorf(f, "%v", err)
+	}
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
 // Precision of the remote
 func (f *Fs) Precision() time.Duration {
 	return time.Nanosecond
@@ -1123,8 +1327,8 @@ func pathEscape(s string) string {
 // Will only be called if src.Fs().Name() == f.Name()
 //
 // If it isn't possible then return fs.ErrorCantCopy
-func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
-	err := f.Mkdir("")
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	err := f.Mkdir(ctx, "")
 	if err != nil {
 		return nil, err
 	}
@@ -1136,17 +1340,20 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 	srcFs := srcObj.fs
 	key := f.root + remote
 	source := pathEscape(srcFs.bucket + "/" + srcFs.root + srcObj.remote)
+	if srcObj.versionID != nil {
+		source = pathEscape(srcFs.bucket+"/"+srcFs.root+srcObj.baseRemote()) + "?versionId=" + *srcObj.versionID
+	}
 	req := s3.CopyObjectInput{
 		Bucket:            &f.bucket,
 		Key:               &key,
 		CopySource:        &source,
 		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
 	}
-	_, err = f.c.CopyObject(&req)
+	_, err = f.c.CopyObjectWithContext(ctx, &req)
 	if err != nil {
 		return nil, err
 	}
-	return f.NewObject(remote)
+	return f.NewObject(ctx, remote)
 }
 
 // Hashes returns the supported hash sets.
@@ -1176,6 +1383,15 @@ func (o *Object) Remote() string {
 
 var matchMd5 = regexp.MustCompile(`^[0-9a-f]{32}$`)
 
+// baseRemote returns the remote without any version string
+func (o *Object) baseRemote() string {
+	if o.versionID == nil {
+		return o.remote
+	}
+	_, remote := version.Remove(o.remote)
+	return remote
+}
+
 // Hash returns the Md5sum of an object returning a lowercase hex string
 func (o *Object) Hash(t hash.Type) (string, error) {
 	if t != hash.MD5 {
@@ -1184,7 +1400,7 @@ func (o *Object) Hash(t hash.Type) (string, error) {
 	hash := strings.Trim(strings.ToLower(o.etag), `"`)
 	// Check the etag is a valid md5sum
 	if !matchMd5.MatchString(hash) {
-		err := o.readMetaData()
+		err := o.readMetaData(context.TODO())
 		if err != nil {
 			return "", err
 		}
@@ -1210,16 +1426,17 @@ func (o *Object) Size() int64 {
 // readMetaData gets the metadata if it hasn't already been fetched
 //
 // it also sets the info
-func (o *Object) readMetaData() (err error) {
+func (o *Object) readMetaData(ctx context.Context) (err error) {
 	if o.meta != nil {
 		return nil
 	}
-	key := o.fs.root + o.remote
+	key := o.fs.root + o.baseRemote()
 	req := s3.HeadObjectInput{
-		Bucket: &o.fs.bucket,
-		Key:    &key,
+		Bucket:    &o.fs.bucket,
+		Key:       &key,
+		VersionId: o.versionID,
 	}
-	resp, err := o.fs.c.HeadObject(&req)
+	resp, err := o.fs.c.HeadObjectWithContext(ctx, &req)
 	if err != nil {
 		if awsErr, ok := err.(awserr.RequestFailure); ok {
 			if awsErr.StatusCode() == http.StatusNotFound {
@@ -1244,6 +1461,7 @@ func (o *Object) readMetaData() (err error) {
 		o.lastModified = *resp.LastModified
 	}
 	o.mimeType = aws.StringValue(resp.ContentType)
+	o.storageClass = aws.StringValue(resp.StorageClass)
 	return nil
 }
 
@@ -1255,7 +1473,7 @@ func (o *Object) ModTime() time.Time {
 	if fs.Config.UseServerModTime {
 		return o.lastModified
 	}
-	err := o.readMetaData()
+	err := o.readMetaData(context.TODO())
 	if err != nil {
 		fs.Logf(o, "Failed to read metadata: %v", err)
 		return time.Now()
@@ -1275,8 +1493,11 @@ func (o *Object) ModTime() time.Time {
 }
 
 // SetModTime sets the modification time of the local fs object
-func (o *Object) SetModTime(modTime time.Time) error {
-	err := o.readMetaData()
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	if fs.Config.Versions {
+		return fs.ErrorNotWithVersions
+	}
+	err := o.readMetaData(ctx)
 	if err != nil {
 		return err
 	}
@@ -1303,7 +1524,7 @@ func (o *Object) SetModTime(modTime time.Time) error {
 		Metadata:          o.meta,
 		MetadataDirective: &directive,
 	}
-	_, err = o.fs.c.CopyObject(&req)
+	_, err = o.fs.c.CopyObjectWithContext(ctx, &req)
 	return err
 }
 
@@ -1313,11 +1534,12 @@ func (o *Object) Storable() bool {
 }
 
 // Open an object for read
-func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
-	key := o.fs.root + o.remote
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
+	key := o.fs.root + o.baseRemote()
 	req := s3.GetObjectInput{
-		Bucket: &o.fs.bucket,
-		Key:    &key,
+		Bucket:    &o.fs.bucket,
+		Key:       &key,
+		VersionId: o.versionID,
 	}
 	for _, option := range options {
 		switch option.(type) {
@@ -1330,7 +1552,7 @@ func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
 			}
 		}
 	}
-	resp, err := o.fs.c.GetObject(&req)
+	resp, err := o.fs.c.GetObjectWithContext(ctx, &req)
 	if err, ok := err.(awserr.RequestFailure); ok {
 		if err.Code() == "InvalidObjectState" {
 			return nil, errors.Errorf("Object in GLACIER, restore first: %v", key)
@@ -1343,8 +1565,11 @@ func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
 }
 
 // Update the Object from in with modTime and size
-func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
-	err := o.fs.Mkdir("")
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	if fs.Config.Versions {
+		return fs.ErrorNotWithVersions
+	}
+	err := o.fs.Mkdir(ctx, "")
 	if err != nil {
 		return err
 	}
@@ -1407,31 +1632,65 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 	if o.fs.storageClass != "" {
 		req.StorageClass = &o.fs.storageClass
 	}
-	_, err = uploader.Upload(&req)
+	// Send the MD5 with single part uploads so S3 rejects them if
+	// they are corrupted - multipart uploads have an MD5 per part
+	if size >= 0 && size < uploader.PartSize {
+		if md5sum := fs.UploadHash(src, hash.MD5); md5sum != "" {
+			md5bytes, err := hex.DecodeString(md5sum)
+			if err == nil {
+				req.ContentMD5 = aws.String(base64.StdEncoding.EncodeToString(md5bytes))
+			}
+		}
+	}
+	var requestOptions []request.Option
+	for _, option := range options {
+		if retention, ok := option.(*fs.RetentionOption); ok {
+			requestOptions = append(requestOptions, objectLockOption(retention))
+		}
+	}
+	_, err = uploader.UploadWithContext(ctx, &req, s3manager.WithUploaderRequestOptions(requestOptions...))
 	if err != nil {
 		return err
 	}
 
 	// Read the metadata from the newly created object
 	o.meta = nil // wipe old metadata
-	err = o.readMetaData()
+	err = o.readMetaData(ctx)
 	return err
 }
 
+// objectLockOption returns a request option which locks the object
+// being uploaded as described by retention.
+//
+// The vendored SDK doesn't know about object locks so the headers are
+// set directly on the requests which create objects.
+func objectLockOption(retention *fs.RetentionOption) request.Option {
+	return func(r *request.Request) {
+		switch r.Operation.Name {
+		case "PutObject", "CreateMultipartUpload":
+			r.HTTPRequest.Header.Set("X-Amz-Object-Lock-Mode", strings.ToUpper(retention.Mode))
+			r.HTTPRequest.Header.Set("X-Amz-Object-Lock-Retain-Until-Date", retention.RetainUntil.UTC().Format(time.RFC3339))
+		}
+	}
+}
+
 // Remove an object
-func (o *Object) Remove() error {
+func (o *Object) Remove(ctx context.Context) error {
+	if fs.Config.Versions {
+		return fs.ErrorNotWithVersions
+	}
 	key := o.fs.root + o.remote
 	req := s3.DeleteObjectInput{
 		Bucket: &o.fs.bucket,
 		Key:    &key,
 	}
-	_, err := o.fs.c.DeleteObject(&req)
+	_, err := o.fs.c.DeleteObjectWithContext(ctx, &req)
 	return err
 }
 
 // MimeType of an Object if known, "" otherwise
 func (o *Object) MimeType() string {
-	err := o.readMetaData()
+	err := o.readMetaData(context.TODO())
 	if err != nil {
 		fs.Logf(o, "Failed to read metadata: %v", err)
 		return ""
@@ -1439,12 +1698,104 @@ func (o *Object) MimeType() string {
 	return o.mimeType
 }
 
+// storageClasses are the storage classes an object can be moved
+// into with SetTier
+//
+// GLACIER can only be reached with a lifecycle rule so isn't here
+var storageClasses = []string{
+	s3.StorageClassStandard,
+	s3.StorageClassReducedRedundancy,
+	s3.StorageClassStandardIa,
+	"ONEZONE_IA", // not in this version of the SDK
+}
+
+// SetTier changes the storage class of the object by copying it to
+// itself
+func (o *Object) SetTier(ctx context.Context, tier string) (err error) {
+	if fs.Config.Versions {
+		return fs.ErrorNotWithVersions
+	}
+	tier = strings.ToUpper(tier)
+	found := false
+	for _, storageClass := range storageClasses {
+		if tier == storageClass {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return errors.Errorf("unknown storage class %q - must be one of %s", tier, strings.Join(storageClasses, ", "))
+	}
+	if o.bytes >= maxSizeForCopy {
+		return errors.Errorf("can't set storage class on objects bigger than %v bytes", fs.SizeSuffix(maxSizeForCopy))
+	}
+	key := o.fs.root + o.remote
+	sourceKey := o.fs.bucket + "/" + key
+	req := s3.CopyObjectInput{
+		Bucket:            &o.fs.bucket,
+		ACL:               &o.fs.acl,
+		Key:               &key,
+		CopySource:        aws.String(pathEscape(sourceKey)),
+		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+		StorageClass:      &tier,
+	}
+	if o.fs.sse != "" {
+		req.ServerSideEncryption = &o.fs.sse
+	}
+	_, err = o.fs.c.CopyObjectWithContext(ctx, &req)
+	if err != nil {
+		return err
+	}
+	o.storageClass = tier
+	return nil
+}
+
+// GetTier returns the storage class of the object
+func (o *Object) GetTier() string {
+	if o.storageClass == "" {
+		// S3 doesn't return the storage class for STANDARD objects
+		return s3.StorageClassStandard
+	}
+	return o.storageClass
+}
+
+// VersionTime returns the time this version of the object was made
+func (o *Object) VersionTime() time.Time {
+	return o.lastModified
+}
+
+// RestoreVersion makes this version of the object the current one by
+// copying it over the latest version
+func (o *Object) RestoreVersion(ctx context.Context) error {
+	if o.versionID == nil {
+		return nil // already the current version
+	}
+	key := o.fs.root + o.baseRemote()
+	source := pathEscape(o.fs.bucket+"/"+key) + "?versionId=" + *o.versionID
+	req := s3.CopyObjectInput{
+		Bucket:            &o.fs.bucket,
+		ACL:               &o.fs.acl,
+		Key:               &key,
+		CopySource:        &source,
+		MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+	}
+	if o.fs.sse != "" {
+		req.ServerSideEncryption = &o.fs.sse
+	}
+	_, err := o.fs.c.CopyObjectWithContext(ctx, &req)
+	return err
+}
+
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs          = &Fs{}
-	_ fs.Copier      = &Fs{}
-	_ fs.PutStreamer = &Fs{}
-	_ fs.ListRer     = &Fs{}
-	_ fs.Object      = &Object{}
-	_ fs.MimeTyper   = &Object{}
+	_ fs.Fs              = &Fs{}
+	_ fs.Copier          = &Fs{}
+	_ fs.PutStreamer     = &Fs{}
+	_ fs.ListRer         = &Fs{}
+	_ fs.CleanUpper      = &Fs{}
+	_ fs.Object          = &Object{}
+	_ fs.MimeTyper       = &Object{}
+	_ fs.SetTierer       = &Object{}
+	_ fs.GetTierer       = &Object{}
+	_ fs.VersionRestorer = &Object{}
 )
@@ -0,0 +1,556 @@
+// Package sidecar provides wrappers for Fs and Object which store
+// modification times in a file in each directory for remotes which
+// can't store them
+package sidecar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/pkg/errors"
+)
+
+// Globals
+var (
+	// Flags
+	batchDelay = flags.DurationP("sidecar-batch-delay", "", 0, "Write changed modtimes files at most this often - 0 writes them on every change")
+)
+
+// sidecarName is the name of the file in each directory holding the
+// modification times of the objects in it
+const sidecarName = ".rclone-modtimes.json"
+
+// Register with Fs
+func init() {
+	fs.Register(&fs.RegInfo{
+		Name:        "sidecar",
+		Description: "Store modification times for a remote which can't",
+		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
+		Options: []fs.Option{{
+			Name: "remote",
+			Help: "Remote to store modification times for.\nNormally should contain a ':' and a path, eg \"myremote:path/to/dir\",\n\"myremote:bucket\" or maybe \"myremote:\".",
+		}},
+	})
+}
+
+// NewFs contstructs an Fs from the path, container:path
+func NewFs(name, rpath string) (fs.Fs, error) {
+	remote := config.FileGet(name, "remote")
+	if remote == "" {
+		return nil, errors.New("sidecar can't point to an empty remote - check the value of the remote setting")
+	}
+	if strings.HasPrefix(remote, name+":") {
+		return nil, errors.New("can't point sidecar remote at itself - check the value of the remote setting")
+	}
+	remotePath := path.Join(remote, rpath)
+	wrappedFs, err := fs.NewFs(remotePath)
+	if err != fs.ErrorIsFile && err != nil {
+		return nil, errors.Wrapf(err, "failed to make remote %q to wrap", remotePath)
+	}
+	f := &Fs{
+		Fs:   wrappedFs,
+		name: name,
+		root: rpath,
+		dirs: make(map[string]*sidecar),
+	}
+	// the features here are ones we could support, and they are
+	// ANDed with the ones from wrappedFs
+	f.features = (&fs.Features{
+		CaseInsensitive:         true,
+		DuplicateFiles:          false,
+		ReadMimeType:            true,
+		WriteMimeType:           true,
+		BucketBased:             true,
+		CanHaveEmptyDirectories: true,
+	}).Fill(f).Mask(wrappedFs).WrapsFs(f, wrappedFs)
+	// Shutdown writes the sidecars whatever wrappedFs supports
+	f.features.Shutdown = f.Shutdown
+	return f, err
+}
+
+// Fs represents a wrapped fs.Fs
+type Fs struct {
+	fs.Fs
+	name     string
+	root     string
+	features *fs.Features // optional features
+
+	mu   sync.Mutex          // protects dirs
+	dirs map[string]*sidecar // sidecars read so far by directory
+}
+
+// sidecar holds the modification times for one directory
+type sidecar struct {
+	mu       sync.Mutex           // protects the fields below
+	loaded   bool                 // set if we have tried to read the file
+	dirty    bool                 // set if modTimes needs writing
+	timer    *time.Timer          // pending write of a batch of changes
+	o        fs.Object            // the sidecar file if it exists
+	modTimes map[string]time.Time // modification time by leaf name
+}
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	return f.root
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// String returns a description of the FS
+func (f *Fs) String() string {
+	return fmt.Sprintf("Sidecar modtimes '%s:%s'", f.name, f.root)
+}
+
+// Precision of the ModTimes in this Fs
+func (f *Fs) Precision() time.Duration {
+	return time.Nanosecond
+}
+
+// isSidecar returns true if remote is the path of a sidecar file
+func isSidecar(remote string) bool {
+	return path.Base(remote) == sidecarName
+}
+
+// splitRemote returns the directory and leaf of remote
+func splitRemote(remote string) (dir, leaf string) {
+	dir, leaf = path.Split(remote)
+	return strings.TrimRight(dir, "/"), leaf
+}
+
+// getSidecar returns the sidecar for dir, reading it if necessary
+//
+// It returns with the sidecar locked - call s.mu.Unlock() when done
+func (f *Fs) getSidecar(ctx context.Context, dir string) (*sidecar, error) {
+	f.mu.Lock()
+	s, ok := f.dirs[dir]
+	if !ok {
+		s = &sidecar{}
+		f.dirs[dir] = s
+	}
+	f.mu.Unlock()
+	s.mu.Lock()
+	if s.loaded {
+		return s, nil
+	}
+	err := f.readSidecar(ctx, dir, s)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	s.loaded = true
+	return s, nil
+}
+
+// readSidecar reads the sidecar file for dir into s
+//
+// Call with s.mu held
+func (f *Fs) readSidecar(ctx context.Context, dir string, s *sidecar) (err error) {
+	s.modTimes = make(map[string]time.Time)
+	o, err := f.Fs.NewObject(ctx, path.Join(dir, sidecarName))
+	if err == fs.ErrorObjectNotFound || err == fs.ErrorDirNotFound {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to find modtimes file")
+	}
+	in, err := o.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open modtimes file")
+	}
+	defer fs.CheckClose(in, &err)
+	err = json.NewDecoder(in).Decode(&s.modTimes)
+	if err != nil {
+		fs.Errorf(o, "Ignoring corrupted modtimes file: %v", err)
+		s.modTimes = make(map[string]time.Time)
+	}
+	s.o = o
+	return nil
+}
+
+// writeSidecar writes s back to the sidecar file for dir, removing
+// it if there are no entries
+//
+// Call with s.mu held
+func (f *Fs) writeSidecar(ctx context.Context, dir string, s *sidecar) error {
+	if len(s.modTimes) == 0 {
+		if s.o == nil {
+			return nil
+		}
+		err := s.o.Remove(ctx)
+		if err != nil && err != fs.ErrorObjectNotFound {
+			return errors.Wrap(err, "failed to remove modtimes file")
+		}
+		s.o = nil
+		return nil
+	}
+	data, err := json.MarshalIndent(s.modTimes, "", "\t")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode modtimes file")
+	}
+	src := object.NewStaticObjectInfo(path.Join(dir, sidecarName), time.Now(), int64(len(data)), true, nil, f.Fs)
+	if s.o != nil {
+		err = s.o.Update(ctx, bytes.NewReader(data), src)
+	} else {
+		s.o, err = f.Fs.Put(ctx, bytes.NewReader(data), src)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write modtimes file")
+	}
+	return nil
+}
+
+// changed writes s back to the sidecar file for dir, or if
+// --sidecar-batch-delay is set arranges for it to be written with any
+// other changes made before the delay is up.
+//
+// Call with s.mu held
+func (f *Fs) changed(ctx context.Context, dir string, s *sidecar) error {
+	if *batchDelay <= 0 {
+		return f.writeSidecar(ctx, dir, s)
+	}
+	s.dirty = true
+	if s.timer == nil {
+		var timer *time.Timer
+		timer = time.AfterFunc(*batchDelay, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if s.timer != timer {
+				return // stopped while waiting for the lock
+			}
+			s.timer = nil
+			_ = f.flushSidecar(context.Background(), dir, s)
+		})
+		s.timer = timer
+	}
+	return nil
+}
+
+// flushSidecar writes s if it has changes which haven't been written
+//
+// Call with s.mu held
+func (f *Fs) flushSidecar(ctx context.Context, dir string, s *sidecar) error {
+	if !s.dirty {
+		return nil
+	}
+	err := f.writeSidecar(ctx, dir, s)
+	if err != nil {
+		fs.CountError(err)
+		fs.Errorf(path.Join(dir, sidecarName), "Failed to write modification times: %v", err)
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// Shutdown writes all the sidecars with changes which haven't been
+// written, returning the first error
+func (f *Fs) Shutdown(ctx context.Context) (err error) {
+	f.mu.Lock()
+	dirs := make(map[string]*sidecar, len(f.dirs))
+	for dir, s := range f.dirs {
+		dirs[dir] = s
+	}
+	f.mu.Unlock()
+	for dir, s := range dirs {
+		s.mu.Lock()
+		if s.timer != nil {
+			s.timer.Stop()
+			s.timer = nil
+		}
+		if flushErr := f.flushSidecar(ctx, dir, s); flushErr != nil && err == nil {
+			err = errors.Wrap(flushErr, "failed to write modification times")
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// modTime returns the modification time recorded for remote if any
+func (f *Fs) modTime(ctx context.Context, remote string) (modTime time.Time, ok bool) {
+	dir, leaf := splitRemote(remote)
+	s, err := f.getSidecar(ctx, dir)
+	if err != nil {
+		fs.Debugf(remote, "Failed to read modification time: %v", err)
+		return modTime, false
+	}
+	defer s.mu.Unlock()
+	modTime, ok = s.modTimes[leaf]
+	return modTime, ok
+}
+
+// setModTime records modTime for remote
+func (f *Fs) setModTime(ctx context.Context, remote string, modTime time.Time) error {
+	dir, leaf := splitRemote(remote)
+	s, err := f.getSidecar(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	if old, ok := s.modTimes[leaf]; ok && old.Equal(modTime) {
+		return nil
+	}
+	s.modTimes[leaf] = modTime
+	return f.changed(ctx, dir, s)
+}
+
+// uploaded records the modification time of src which has just been
+// uploaded to o.  If the remote stored it exactly with the upload
+// then the sidecar file doesn't need it.
+func (f *Fs) uploaded(ctx context.Context, o fs.Object, src fs.ObjectInfo) error {
+	modTime := src.ModTime()
+	if f.Fs.Precision() != fs.ModTimeNotSupported && o.ModTime().Equal(modTime) {
+		return f.removeModTime(ctx, o.Remote())
+	}
+	return f.setModTime(ctx, o.Remote(), modTime)
+}
+
+// removeModTime removes any modification time recorded for remote
+func (f *Fs) removeModTime(ctx context.Context, remote string) error {
+	dir, leaf := splitRemote(remote)
+	s, err := f.getSidecar(ctx, dir)
+	if err != nil {
+		return err
+	}
+	defer s.mu.Unlock()
+	if _, ok := s.modTimes[leaf]; !ok {
+		return nil
+	}
+	delete(s.modTimes, leaf)
+	return f.changed(ctx, dir, s)
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = f.Fs.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	newEntries := entries[:0] // in place filter
+	for _, entry := range entries {
+		switch x := entry.(type) {
+		case fs.Object:
+			if !isSidecar(x.Remote()) {
+				newEntries = append(newEntries, f.newObject(x))
+			}
+		case fs.Directory:
+			newEntries = append(newEntries, x)
+		default:
+			return nil, errors.Errorf("Unknown object type %T", entry)
+		}
+	}
+	return newEntries, nil
+}
+
+// NewObject finds the Object at remote.
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	if isSidecar(remote) {
+		return nil, fs.ErrorObjectNotFound
+	}
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+type putFn func(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error)
+
+// put implements Put or PutStream
+func (f *Fs) put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options []fs.OpenOption, put putFn) (fs.Object, error) {
+	if isSidecar(src.Remote()) {
+		return nil, errors.Errorf("can't upload %q as it is used to store modification times", sidecarName)
+	}
+	o, err := put(ctx, in, src, options...)
+	if err != nil {
+		return nil, err
+	}
+	err = f.uploaded(ctx, o, src)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// Put in to the remote path with the modTime given of the given size
+//
+// May create the object even if it returns an error - if so
+// will return the object and the error, otherwise will return
+// nil and the error
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.put(ctx, in, src, options, f.Fs.Put)
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	return f.put(ctx, in, src, options, f.Fs.Features().PutStream)
+}
+
+// Rmdir removes the directory (container, bucket) if empty
+//
+// Return an error if it doesn't exist or isn't empty
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	// Remove a stale sidecar file so the directory can be empty
+	f.mu.Lock()
+	s := f.dirs[dir]
+	delete(f.dirs, dir)
+	f.mu.Unlock()
+	if s != nil {
+		s.mu.Lock()
+		if s.timer != nil {
+			s.timer.Stop()
+			s.timer = nil
+		}
+		err := f.flushSidecar(ctx, dir, s)
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	entries, err := f.Fs.List(ctx, dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 1 && isSidecar(entries[0].Remote()) {
+		if o, ok := entries[0].(fs.Object); ok {
+			err = o.Remove(ctx)
+			if err != nil {
+				return errors.Wrap(err, "failed to remove modtimes file")
+			}
+		}
+	}
+	return f.Fs.Rmdir(ctx, dir)
+}
+
+// Purge all files in the root and the root directory
+//
+// Implement this if you have a way of deleting all the files
+// quicker than just running Remove() on the result of List()
+//
+// Return an error if it doesn't exist
+func (f *Fs) Purge(ctx context.Context) error {
+	do := f.Fs.Features().Purge
+	if do == nil {
+		return fs.ErrorCantPurge
+	}
+	f.mu.Lock()
+	for _, s := range f.dirs {
+		s.mu.Lock()
+		if s.timer != nil {
+			s.timer.Stop()
+			s.timer = nil
+		}
+		s.mu.Unlock()
+	}
+	f.dirs = make(map[string]*sidecar)
+	f.mu.Unlock()
+	return do(ctx)
+}
+
+// UnWrap returns the Fs that this Fs is wrapping
+func (f *Fs) UnWrap() fs.Fs {
+	return f.Fs
+}
+
+// Object describes a wrapped object with its modification time
+// stored in a sidecar file
+type Object struct {
+	fs.Object
+	f *Fs
+}
+
+func (f *Fs) newObject(o fs.Object) *Object {
+	return &Object{
+		Object: o,
+		f:      f,
+	}
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.Remote()
+}
+
+// ModTime returns the modification time of the object from the
+// sidecar file, falling back to the time the remote stores
+func (o *Object) ModTime() time.Time {
+	if modTime, ok := o.f.modTime(context.TODO(), o.Remote()); ok {
+		return modTime
+	}
+	return o.Object.ModTime()
+}
+
+// SetModTime sets the modification time of the object in the sidecar
+// file
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	return o.f.setModTime(ctx, o.Remote(), modTime)
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	err := o.Object.Update(ctx, in, src, options...)
+	if err != nil {
+		return err
+	}
+	return o.f.uploaded(ctx, o.Object, src)
+}
+
+// Remove an object
+func (o *Object) Remove(ctx context.Context) error {
+	err := o.Object.Remove(ctx)
+	if err != nil {
+		return err
+	}
+	return o.f.removeModTime(ctx, o.Remote())
+}
+
+// UnWrap returns the wrapped Object
+func (o *Object) UnWrap() fs.Object {
+	return o.Object
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs              = (*Fs)(nil)
+	_ fs.Purger          = (*Fs)(nil)
+	_ fs.PutStreamer     = (*Fs)(nil)
+	_ fs.UnWrapper       = (*Fs)(nil)
+	_ fs.Shutdowner      = (*Fs)(nil)
+	_ fs.Object          = (*Object)(nil)
+	_ fs.ObjectUnWrapper = (*Object)(nil)
+)
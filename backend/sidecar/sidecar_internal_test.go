@@ -0,0 +1,83 @@
+package sidecar
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFs makes a sidecar Fs wrapping a temporary local directory
+func newTestFs(t *testing.T) (f *Fs, dir string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "rclone-sidecar-internal")
+	require.NoError(t, err)
+	wrappedFs, err := fs.NewFs(dir)
+	require.NoError(t, err)
+	f = &Fs{
+		Fs:   wrappedFs,
+		dirs: make(map[string]*sidecar),
+	}
+	return f, dir, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func TestBatchDelay(t *testing.T) {
+	ctx := context.Background()
+	f, dir, cleanup := newTestFs(t)
+	defer cleanup()
+	oldBatchDelay := *batchDelay
+	*batchDelay = time.Hour
+	defer func() { *batchDelay = oldBatchDelay }()
+
+	sidecarPath := filepath.Join(dir, "sub", sidecarName)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0777))
+	t1 := time.Date(2001, 2, 3, 4, 5, 6, 7, time.UTC)
+	require.NoError(t, f.setModTime(ctx, "sub/one", t1))
+	require.NoError(t, f.setModTime(ctx, "sub/two", t1))
+
+	// Not written yet but visible
+	_, err := os.Stat(sidecarPath)
+	assert.True(t, os.IsNotExist(err))
+	modTime, ok := f.modTime(ctx, "sub/two")
+	assert.True(t, ok)
+	assert.True(t, t1.Equal(modTime))
+
+	// Written with both changes on shutdown
+	require.NoError(t, f.Shutdown(ctx))
+	data, err := ioutil.ReadFile(sidecarPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"one"`)
+	assert.Contains(t, string(data), `"two"`)
+	s, err := f.getSidecar(ctx, "sub")
+	require.NoError(t, err)
+	assert.False(t, s.dirty)
+	assert.Nil(t, s.timer)
+	s.mu.Unlock()
+}
+
+func TestUploadedModTimeInline(t *testing.T) {
+	ctx := context.Background()
+	f, dir, cleanup := newTestFs(t)
+	defer cleanup()
+
+	// local stores the modification time with the upload so no
+	// sidecar file is needed
+	t1 := time.Date(2001, 2, 3, 4, 5, 6, 7, time.UTC)
+	contents := []byte("hello")
+	src := object.NewStaticObjectInfo("file.txt", t1, int64(len(contents)), true, nil, nil)
+	o, err := f.Put(ctx, bytes.NewReader(contents), src)
+	require.NoError(t, err)
+	assert.True(t, t1.Equal(o.ModTime()))
+	_, err = os.Stat(filepath.Join(dir, sidecarName))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,26 @@
+// Test Sidecar filesystem interface
+package sidecar_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/backend/sidecar"
+	"github.com/ncw/rclone/fstest/fstests"
+)
+
+// TestIntegration runs integration tests against the remote
+func TestIntegration(t *testing.T) {
+	tempdir := filepath.Join(os.TempDir(), "rclone-sidecar-test")
+	name := "TestSidecar"
+	fstests.Run(t, &fstests.Opt{
+		RemoteName: name + ":",
+		NilObject:  (*sidecar.Object)(nil),
+		ExtraConfig: []fstests.ExtraConfigItem{
+			{Name: name, Key: "type", Value: "sidecar"},
+			{Name: name, Key: "remote", Value: tempdir},
+		},
+	})
+}
@@ -0,0 +1,313 @@
+// Package timezone provides wrappers for Fs and Object which
+// translate the modification times of remotes which use local time
+// rather than UTC
+package timezone
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/pkg/errors"
+)
+
+// Register with Fs
+func init() {
+	fs.Register(&fs.RegInfo{
+		Name:        "timezone",
+		Description: "Translate modification times for a remote which uses local time",
+		NewFs:       NewFs,
+		FsType:      (*Fs)(nil),
+		ObjectType:  (*Object)(nil),
+		Options: []fs.Option{{
+			Name: "remote",
+			Help: "Remote to translate modification times for.\nNormally should contain a ':' and a path, eg \"myremote:path/to/dir\",\n\"myremote:bucket\" or maybe \"myremote:\".",
+		}, {
+			Name:     "zone",
+			Help:     "Time zone the remote uses for modification times, eg Europe/London, or Local for the time zone of this computer.",
+			Optional: true,
+		}, {
+			Name:     "offset",
+			Help:     "Fixed offset to remove from the modification times the remote reports, eg 1h or -30m.",
+			Optional: true,
+		}},
+	})
+}
+
+// translator converts between real times and the times a remote
+// stores
+type translator struct {
+	loc    *time.Location // the zone the remote uses, nil for none
+	offset time.Duration  // the offset the remote adds
+}
+
+// newTranslator makes a translator from the zone and offset in the
+// config
+func newTranslator(zone, offset string) (t translator, err error) {
+	if zone != "" {
+		t.loc, err = time.LoadLocation(zone)
+		if err != nil {
+			return t, errors.Wrapf(err, "failed to load time zone %q", zone)
+		}
+	}
+	if offset != "" {
+		t.offset, err = time.ParseDuration(offset)
+		if err != nil {
+			return t, errors.Wrap(err, "failed to parse offset")
+		}
+	}
+	return t, nil
+}
+
+// fromRemote converts a time read from the remote into the real time
+//
+// The remote reports the wall clock time in its zone as if it were
+// UTC, so the wall clock is re-read in that zone.
+func (t translator) fromRemote(in time.Time) time.Time {
+	if in.IsZero() {
+		return in
+	}
+	if t.loc != nil {
+		in = time.Date(in.Year(), in.Month(), in.Day(), in.Hour(), in.Minute(), in.Second(), in.Nanosecond(), t.loc)
+	}
+	return in.Add(-t.offset)
+}
+
+// toRemote converts a real time into the time to give the remote,
+// the inverse of fromRemote
+func (t translator) toRemote(in time.Time) time.Time {
+	if in.IsZero() {
+		return in
+	}
+	in = in.Add(t.offset)
+	if t.loc != nil {
+		in = in.In(t.loc)
+		in = time.Date(in.Year(), in.Month(), in.Day(), in.Hour(), in.Minute(), in.Second(), in.Nanosecond(), time.UTC)
+	}
+	return in
+}
+
+// NewFs contstructs an Fs from the path, container:path
+func NewFs(name, rpath string) (fs.Fs, error) {
+	remote := config.FileGet(name, "remote")
+	if remote == "" {
+		return nil, errors.New("timezone can't point to an empty remote - check the value of the remote setting")
+	}
+	if strings.HasPrefix(remote, name+":") {
+		return nil, errors.New("can't point timezone remote at itself - check the value of the remote setting")
+	}
+	t, err := newTranslator(config.FileGet(name, "zone"), config.FileGet(name, "offset"))
+	if err != nil {
+		return nil, err
+	}
+	remotePath := path.Join(remote, rpath)
+	wrappedFs, err := fs.NewFs(remotePath)
+	if err != fs.ErrorIsFile && err != nil {
+		return nil, errors.Wrapf(err, "failed to make remote %q to wrap", remotePath)
+	}
+	f := &Fs{
+		Fs:   wrappedFs,
+		name: name,
+		root: rpath,
+		t:    t,
+	}
+	// the features here are ones we could support, and they are
+	// ANDed with the ones from wrappedFs
+	f.features = (&fs.Features{
+		CaseInsensitive:         true,
+		DuplicateFiles:          true,
+		ReadMimeType:            true,
+		WriteMimeType:           true,
+		BucketBased:             true,
+		CanHaveEmptyDirectories: true,
+	}).Fill(f).Mask(wrappedFs).WrapsFs(f, wrappedFs)
+	return f, err
+}
+
+// Fs represents a wrapped fs.Fs
+type Fs struct {
+	fs.Fs
+	name     string
+	root     string
+	features *fs.Features // optional features
+	t        translator   // translates the modification times
+}
+
+// Name of the remote (as passed into NewFs)
+func (f *Fs) Name() string {
+	return f.name
+}
+
+// Root of the remote (as passed into NewFs)
+func (f *Fs) Root() string {
+	return f.root
+}
+
+// Features returns the optional features of this Fs
+func (f *Fs) Features() *fs.Features {
+	return f.features
+}
+
+// String returns a description of the FS
+func (f *Fs) String() string {
+	return fmt.Sprintf("Timezone translated '%s:%s'", f.name, f.root)
+}
+
+// List the objects and directories in dir into entries.  The
+// entries can be returned in any order but should be for a
+// complete directory.
+//
+// dir should be "" to list the root, and should not have
+// trailing slashes.
+//
+// This should return ErrDirNotFound if the directory isn't
+// found.
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	entries, err = f.Fs.List(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range entries {
+		switch x := entry.(type) {
+		case fs.Object:
+			entries[i] = f.newObject(x)
+		case fs.Directory:
+			entries[i] = fs.NewDir(x.Remote(), f.t.fromRemote(x.ModTime())).SetID(x.ID()).SetSize(x.Size()).SetItems(x.Items())
+		default:
+			return nil, errors.Errorf("Unknown object type %T", entry)
+		}
+	}
+	return entries, nil
+}
+
+// NewObject finds the Object at remote.
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// Put in to the remote path with the modTime given of the given size
+//
+// May create the object even if it returns an error - if so
+// will return the object and the error, otherwise will return
+// nil and the error
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	o, err := f.Fs.Put(ctx, in, f.newObjectInfo(src), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// PutStream uploads to the remote path with the modTime given of indeterminate size
+func (f *Fs) PutStream(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	o, err := f.Fs.Features().PutStream(ctx, in, f.newObjectInfo(src), options...)
+	if err != nil {
+		return nil, err
+	}
+	return f.newObject(o), nil
+}
+
+// Purge all files in the root and the root directory
+//
+// Implement this if you have a way of deleting all the files
+// quicker than just running Remove() on the result of List()
+//
+// Return an error if it doesn't exist
+func (f *Fs) Purge(ctx context.Context) error {
+	do := f.Fs.Features().Purge
+	if do == nil {
+		return fs.ErrorCantPurge
+	}
+	return do(ctx)
+}
+
+// UnWrap returns the Fs that this Fs is wrapping
+func (f *Fs) UnWrap() fs.Fs {
+	return f.Fs
+}
+
+// ObjectInfo describes a source object with its modification time
+// translated for the wrapped remote
+type ObjectInfo struct {
+	fs.ObjectInfo
+	f *Fs
+}
+
+func (f *Fs) newObjectInfo(src fs.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		ObjectInfo: src,
+		f:          f,
+	}
+}
+
+// ModTime returns the modification time to store on the wrapped remote
+func (o *ObjectInfo) ModTime() time.Time {
+	return o.f.t.toRemote(o.ObjectInfo.ModTime())
+}
+
+// Object describes a wrapped object with its modification time
+// translated
+type Object struct {
+	fs.Object
+	f *Fs
+}
+
+func (f *Fs) newObject(o fs.Object) *Object {
+	return &Object{
+		Object: o,
+		f:      f,
+	}
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *Object) Fs() fs.Info {
+	return o.f
+}
+
+// Return a string version
+func (o *Object) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.Remote()
+}
+
+// ModTime returns the translated modification time of the object
+func (o *Object) ModTime() time.Time {
+	return o.f.t.fromRemote(o.Object.ModTime())
+}
+
+// SetModTime sets the modification time of the object translated
+// for the wrapped remote
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
+	return o.Object.SetModTime(ctx, o.f.t.toRemote(modTime))
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	return o.Object.Update(ctx, in, o.f.newObjectInfo(src), options...)
+}
+
+// UnWrap returns the wrapped Object
+func (o *Object) UnWrap() fs.Object {
+	return o.Object
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Fs              = (*Fs)(nil)
+	_ fs.Purger          = (*Fs)(nil)
+	_ fs.PutStreamer     = (*Fs)(nil)
+	_ fs.UnWrapper       = (*Fs)(nil)
+	_ fs.Object          = (*Object)(nil)
+	_ fs.ObjectUnWrapper = (*Object)(nil)
+)
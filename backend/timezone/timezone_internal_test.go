@@ -0,0 +1,45 @@
+package timezone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslator(t *testing.T) {
+	tr, err := newTranslator("Europe/London", "")
+	require.NoError(t, err)
+
+	// winter - London is UTC
+	winter := time.Date(2018, 1, 15, 12, 0, 0, 0, time.UTC)
+	assert.True(t, winter.Equal(tr.fromRemote(winter)))
+	assert.True(t, winter.Equal(tr.toRemote(winter)))
+
+	// summer - London is UTC+1 so the remote reports 13:00 as UTC
+	summer := time.Date(2018, 7, 15, 12, 0, 0, 0, time.UTC)
+	reported := time.Date(2018, 7, 15, 13, 0, 0, 0, time.UTC)
+	assert.True(t, summer.Equal(tr.fromRemote(reported)), tr.fromRemote(reported).String())
+	assert.True(t, reported.Equal(tr.toRemote(summer)), tr.toRemote(summer).String())
+
+	// zero times aren't translated
+	assert.True(t, tr.fromRemote(time.Time{}).IsZero())
+	assert.True(t, tr.toRemote(time.Time{}).IsZero())
+}
+
+func TestTranslatorOffset(t *testing.T) {
+	tr, err := newTranslator("", "-30m")
+	require.NoError(t, err)
+	now := time.Date(2018, 7, 15, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, now.Add(30*time.Minute), tr.fromRemote(now))
+	assert.Equal(t, now.Add(-30*time.Minute), tr.toRemote(now))
+	assert.Equal(t, now, tr.fromRemote(tr.toRemote(now)))
+}
+
+func TestNewTranslatorErrors(t *testing.T) {
+	_, err := newTranslator("Not/A_Zone", "")
+	assert.Error(t, err)
+	_, err = newTranslator("", "potato")
+	assert.Error(t, err)
+}
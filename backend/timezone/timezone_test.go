@@ -0,0 +1,28 @@
+// Test Timezone filesystem interface
+package timezone_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/backend/timezone"
+	"github.com/ncw/rclone/fstest/fstests"
+)
+
+// TestIntegration runs integration tests against the remote
+func TestIntegration(t *testing.T) {
+	tempdir := filepath.Join(os.TempDir(), "rclone-timezone-test")
+	name := "TestTimezone"
+	fstests.Run(t, &fstests.Opt{
+		RemoteName: name + ":",
+		NilObject:  (*timezone.Object)(nil),
+		ExtraConfig: []fstests.ExtraConfigItem{
+			{Name: name, Key: "type", Value: "timezone"},
+			{Name: name, Key: "remote", Value: tempdir},
+			{Name: name, Key: "zone", Value: "America/New_York"},
+			{Name: name, Key: "offset", Value: "90s"},
+		},
+	})
+}
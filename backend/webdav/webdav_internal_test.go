@@ -0,0 +1,119 @@
+// +build go1.8
+
+package webdav
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/webdav"
+)
+
+const remoteName = "TestWebdavDedupe"
+
+// prepareDedupeServer makes a webdav server which, like Yandex, makes
+// files from the content it has already been sent when given its
+// hashes and returns the Fs for it and a function to tidy up
+func prepareDedupeServer(t *testing.T, vendor string) (f *Fs, puts *int, tidy func()) {
+	memFs := webdav.NewMemFS()
+	handler := &webdav.Handler{
+		FileSystem: memFs,
+		LockSystem: webdav.NewMemLS(),
+	}
+	known := map[string]string{} // content by "md5/sha256"
+	puts = new(int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			*puts++
+			if content, ok := known[r.Header.Get("Etag")+"/"+r.Header.Get("Sha256")]; ok {
+				// Make the file without reading the body
+				fd, err := memFs.OpenFile(r.Context(), r.URL.Path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+				require.NoError(t, err)
+				_, err = fd.Write([]byte(content))
+				require.NoError(t, err)
+				require.NoError(t, fd.Close())
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			data, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			hashes, err := hash.StreamTypes(strings.NewReader(string(data)), hash.NewHashSet(hash.MD5, hash.SHA256))
+			require.NoError(t, err)
+			known[hashes[hash.MD5]+"/"+hashes[hash.SHA256]] = string(data)
+			r.Body = ioutil.NopCloser(strings.NewReader(string(data)))
+		}
+		handler.ServeHTTP(w, r)
+	}))
+
+	config.LoadConfig()
+	config.FileSet(remoteName, "type", "webdav")
+	config.FileSet(remoteName, "url", ts.URL)
+	config.FileSet(remoteName, "vendor", vendor)
+	fsrc, err := NewFs(remoteName, "")
+	require.NoError(t, err)
+	return fsrc.(*Fs), puts, ts.Close
+}
+
+// srcInfo makes an ObjectInfo for content at remote with its hashes
+func srcInfo(t *testing.T, remote, content string) fs.ObjectInfo {
+	hashes, err := hash.StreamTypes(strings.NewReader(content), hash.NewHashSet(hash.MD5, hash.SHA256))
+	require.NoError(t, err)
+	return object.NewStaticObjectInfo(remote, time.Now(), int64(len(content)), true, hashes, nil)
+}
+
+func TestPutByHash(t *testing.T) {
+	ctx := context.Background()
+	f, puts, tidy := prepareDedupeServer(t, "yandex")
+	defer tidy()
+	require.NotNil(t, f.Features().PutByHash)
+
+	// Content the server doesn't have
+	_, err := f.PutByHash(ctx, srcInfo(t, "one.txt", "potato"))
+	assert.Equal(t, fs.ErrorCantPutByHash, err)
+	assert.Equal(t, 1, *puts)
+
+	// Upload it then make another file from it
+	src := srcInfo(t, "one.txt", "potato")
+	_, err = f.Put(ctx, strings.NewReader("potato"), src)
+	require.NoError(t, err)
+	assert.Equal(t, 2, *puts)
+	o, err := f.PutByHash(ctx, srcInfo(t, "dir/two.txt", "potato"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, *puts)
+	assert.Equal(t, "dir/two.txt", o.Remote())
+	assert.Equal(t, int64(6), o.Size())
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, "potato", string(data))
+
+	// Without a SHA-256 the server can't be asked
+	_, err = f.PutByHash(ctx, object.NewStaticObjectInfo("three.txt", time.Now(), 6, true, map[hash.Type]string{
+		hash.MD5: "8ee2027983915ec78acc45027d874316",
+	}, nil))
+	assert.Equal(t, fs.ErrorCantPutByHash, err)
+	assert.Equal(t, 3, *puts)
+}
+
+func TestPutByHashVendor(t *testing.T) {
+	f, _, tidy := prepareDedupeServer(t, "other")
+	defer tidy()
+	assert.Nil(t, f.Features().PutByHash)
+}
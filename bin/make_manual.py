@@ -28,6 +28,7 @@ docs = [
     "b2.md",
     "box.md",
     "cache.md",
+    "combine.md",
     "crypt.md",
     "dropbox.md",
     "ftp.md",
@@ -42,6 +43,8 @@ docs = [
     "swift.md",
     "pcloud.md",
     "sftp.md",
+    "sidecar.md",
+    "timezone.md",
     "webdav.md",
     "yandex.md",
 
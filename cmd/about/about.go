@@ -91,7 +91,7 @@ Use the --json flag for a computer readable output, eg
 			if doAbout == nil {
 				return errors.Errorf("%v doesn't support about", f)
 			}
-			u, err := doAbout()
+			u, err := doAbout(cmd.Context())
 			if err != nil {
 				return errors.Wrap(err, "About call failed")
 			}
@@ -0,0 +1,90 @@
+// Package archive implements the "rclone archive" command which
+// streams an archive of a remote path to another remote
+package archive
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/lib/archive"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	format = ""
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().StringVarP(&format, "format", "", format, "Archive format: zip, tar or tar.gz (default from the file extension)")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "archive source:path dest:path/archive.zip",
+	Short: `Make a zip or tar archive of source:path on another remote.`,
+	Long: `
+rclone archive makes a zip, tar or tar.gz archive of everything in
+source:path and uploads it to dest:path as it is made, so the archive
+is never stored on local disk, eg
+
+    rclone archive drive:photos s3:backup/photos.zip
+
+The format is worked out from the extension of the archive (.zip,
+.tar, .tar.gz or .tgz) unless it is set with --format.  The paths in
+the archive are relative to source:path.
+
+Use the filter flags (eg --include, --exclude) to control what goes
+in the archive.
+
+The archive is uploaded like rclone rcat uploads, so see its docs for
+the effect of --streaming-upload-cutoff.  Use rclone extract to
+extract an archive.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fsrc := cmd.NewFsSrc(args[:1])
+		fdst, dstFileName := cmd.NewFsDstFile(args[1:])
+		cmd.Run(false, false, command, func() error {
+			archiveFormat := format
+			if archiveFormat == "" {
+				archiveFormat = archive.FormatFromName(dstFileName)
+				if archiveFormat == "" {
+					return errors.Errorf("can't work out the archive format of %q - use --format", dstFileName)
+				}
+			}
+			err := archive.CheckFormat(archiveFormat)
+			if err != nil {
+				return err
+			}
+			return Archive(cmd.Context(), fsrc, archiveFormat, fdst, dstFileName)
+		})
+	},
+}
+
+// Archive makes an archive in format of fsrc and uploads it to
+// dstFileName on fdst while it is being made
+func Archive(ctx context.Context, fsrc fs.Fs, format string, fdst fs.Fs, dstFileName string) error {
+	pr, pw := io.Pipe()
+	errs := make(chan error, 1)
+	go func() {
+		err := archive.Create(ctx, fsrc, "", format, pw)
+		_ = pw.CloseWithError(err)
+		errs <- err
+	}()
+	_, err := operations.Rcat(ctx, fdst, dstFileName, pr, time.Now())
+	// stop the archive being made if the upload failed
+	_ = pr.CloseWithError(err)
+	createErr := <-errs
+	if createErr != nil {
+		return errors.Wrap(createErr, "failed to make archive")
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to upload archive")
+	}
+	return nil
+}
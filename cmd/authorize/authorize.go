@@ -1,13 +1,25 @@
 package authorize
 
 import (
+	"log"
+
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/lib/oauthutil"
 	"github.com/spf13/cobra"
 )
 
+var (
+	device        bool
+	deviceAuthURL string
+	oob           bool
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
+	commandDefintion.Flags().BoolVarP(&device, "device", "", false, "Use the device code flow - no browser needed on this machine")
+	commandDefintion.Flags().StringVarP(&deviceAuthURL, "device-auth-url", "", "", "Device authorization endpoint for --device if rclone doesn't know it")
+	commandDefintion.Flags().BoolVarP(&oob, "oob", "", false, "Use the out of band flow - paste the code shown in the browser")
 }
 
 var commandDefintion = &cobra.Command{
@@ -16,9 +28,32 @@ var commandDefintion = &cobra.Command{
 	Long: `
 Remote authorization. Used to authorize a remote or headless
 rclone from a machine with a browser - use as instructed by
-rclone config.`,
+rclone config.
+
+Use --device to authorize on a machine without a browser, eg the
+headless machine itself.  rclone shows a link and a code to enter on
+any device with a browser, such as a phone, and waits until rclone has
+been authorized.  This only works for providers which support the
+device flow, eg Google Drive.  Other providers can be used if their
+device authorization endpoint is given with --device-auth-url.
+
+Use --oob to use the out of band flow.  rclone shows a link to open
+in any browser and you paste the code shown there back into rclone.
+This only works for providers which support it.`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 3, command, args)
-		config.Authorize(args)
+		settings := map[string]string{}
+		switch {
+		case device && oob:
+			log.Fatalf("Can't use --device and --oob together")
+		case device:
+			settings[config.ConfigAuthFlow] = oauthutil.FlowDevice
+			if deviceAuthURL != "" {
+				settings[config.ConfigDeviceAuthURL] = deviceAuthURL
+			}
+		case oob:
+			settings[config.ConfigAuthFlow] = oauthutil.FlowOOB
+		}
+		config.Authorize(args, settings)
 	},
 }
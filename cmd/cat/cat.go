@@ -74,7 +74,7 @@ Note that if offset is negative it will count from the end, so
 			w = ioutil.Discard
 		}
 		cmd.Run(false, false, command, func() error {
-			return operations.Cat(fsrc, w, offset, count)
+			return operations.Cat(cmd.Context(), fsrc, w, offset, count)
 		})
 	},
 }
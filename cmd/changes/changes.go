@@ -0,0 +1,127 @@
+// Package changes implements the "rclone changes" command which
+// prints the paths changed on a remote since a cursor
+package changes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	since      string
+	cursorFile string
+	jsonOutput bool
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().StringVarP(&since, "since", "", "", "Show the changes made since this cursor")
+	commandDefinition.Flags().StringVarP(&cursorFile, "cursor-file", "", "", "Read the cursor from and save the next cursor to this file")
+	commandDefinition.Flags().BoolVarP(&jsonOutput, "json", "", false, "Format output as JSON, one change per line")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "changes remote:path",
+	Short: `Print the paths changed on the remote since a cursor.`,
+	Long: `
+rclone changes reads the change feed of remotes which keep one, such
+as Google Drive, and prints the paths created, modified or deleted
+under remote:path since the cursor given, eg
+
+    $ rclone changes --since 4567:1537268234 drive:
+    created  photos/new.jpg
+    modified notes.txt
+    deleted  old/
+
+Directories are shown with a trailing /.  Use --json to print each
+change as a JSON object on its own line instead, eg
+
+    {"Path":"photos/new.jpg","IsDir":false,"Action":"created"}
+
+The cursor is a position in the change feed and is opaque - only use
+cursors that rclone changes has made.  If no cursor is given then
+rclone changes doesn't print any changes, it just finds the current
+cursor so the changes after it can be read next time.
+
+The next cursor is printed to stderr when it has finished.  Use
+--cursor-file to read the cursor from a file and save the next cursor
+back to it when all the changes have been printed, so running the
+same command repeatedly prints each change once, eg
+
+    rclone changes --cursor-file drive.cursor drive:
+
+Not all remotes support this command.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		f := cmd.NewFsSrc(args)
+		cmd.Run(false, false, command, func() error {
+			listChanges := f.Features().ListChanges
+			if listChanges == nil {
+				return errors.Errorf("%v doesn't support changes", f)
+			}
+			cursor := since
+			if cursor == "" && cursorFile != "" {
+				data, err := ioutil.ReadFile(cursorFile)
+				if err != nil && !os.IsNotExist(err) {
+					return errors.Wrap(err, "failed to read cursor file")
+				}
+				cursor = strings.TrimSpace(string(data))
+			}
+			if cursor == "" {
+				fs.Logf(f, "No cursor given so finding the current cursor without showing any changes")
+			}
+			newCursor, err := Changes(cmd.Context(), listChanges, cursor, jsonOutput, os.Stdout)
+			if err != nil {
+				return err
+			}
+			if cursorFile != "" {
+				err = ioutil.WriteFile(cursorFile, []byte(newCursor+"\n"), 0600)
+				if err != nil {
+					return errors.Wrap(err, "failed to save cursor file")
+				}
+			}
+			fs.Logf(f, "Next cursor: %s", newCursor)
+			return nil
+		})
+	},
+}
+
+// jsonChange is the JSON output of a change
+type jsonChange struct {
+	Path   string
+	IsDir  bool
+	Action string
+}
+
+// Changes writes the changes listed by listChanges since cursor to
+// out, as JSON lines if asJSON is set, and returns the next cursor
+func Changes(ctx context.Context, listChanges func(context.Context, string, func(fs.Change) error) (string, error), cursor string, asJSON bool, out io.Writer) (newCursor string, err error) {
+	enc := json.NewEncoder(out)
+	return listChanges(ctx, cursor, func(change fs.Change) error {
+		isDir := change.EntryType == fs.EntryDirectory
+		if asJSON {
+			return enc.Encode(jsonChange{
+				Path:   change.Path,
+				IsDir:  isDir,
+				Action: change.Action.String(),
+			})
+		}
+		remote := change.Path
+		if isDir {
+			remote += "/"
+		}
+		_, err := fmt.Fprintf(out, "%-8s %s\n", change.Action, remote)
+		return err
+	})
+}
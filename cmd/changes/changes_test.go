@@ -0,0 +1,59 @@
+package changes
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listChanges returns a ListChanges function which lists changes
+// after cursor "1" and returns cursor "2"
+func listChanges(changes []fs.Change) func(context.Context, string, func(fs.Change) error) (string, error) {
+	return func(ctx context.Context, cursor string, fn func(fs.Change) error) (string, error) {
+		if cursor == "" {
+			return "1", nil
+		}
+		for _, change := range changes {
+			err := fn(change)
+			if err != nil {
+				return "", err
+			}
+		}
+		return "2", nil
+	}
+}
+
+func TestChanges(t *testing.T) {
+	list := listChanges([]fs.Change{
+		{Path: "photos/new.jpg", EntryType: fs.EntryObject, Action: fs.ChangeCreated},
+		{Path: "notes.txt", EntryType: fs.EntryObject, Action: fs.ChangeModified},
+		{Path: "old", EntryType: fs.EntryDirectory, Action: fs.ChangeDeleted},
+	})
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	cursor, err := Changes(ctx, list, "", false, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "1", cursor)
+	assert.Equal(t, "", out.String())
+
+	cursor, err = Changes(ctx, list, cursor, false, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "2", cursor)
+	assert.Equal(t, `created  photos/new.jpg
+modified notes.txt
+deleted  old/
+`, out.String())
+
+	out.Reset()
+	_, err = Changes(ctx, list, "1", true, &out)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Path":"photos/new.jpg","IsDir":false,"Action":"created"}
+{"Path":"notes.txt","IsDir":false,"Action":"modified"}
+{"Path":"old","IsDir":true,"Action":"deleted"}
+`, out.String())
+}
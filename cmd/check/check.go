@@ -1,6 +1,8 @@
 package check
 
 import (
+	"os"
+
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/spf13/cobra"
@@ -8,12 +10,16 @@ import (
 
 // Globals
 var (
-	download = false
+	download   = false
+	duplicates = false
+	oneway     = false
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	commandDefintion.Flags().BoolVarP(&download, "download", "", download, "Check by downloading rather than with hash.")
+	commandDefintion.Flags().BoolVarP(&duplicates, "duplicates", "", duplicates, "Report files with the same contents but different paths.")
+	commandDefintion.Flags().BoolVarP(&oneway, "one-way", "", oneway, "Check one way only, source files must exist on remote")
 }
 
 var commandDefintion = &cobra.Command{
@@ -31,15 +37,35 @@ If you supply the --download flag, it will download the data from
 both remotes and check them against each other on the fly.  This can
 be useful for remotes that don't support hashes or if you really want
 to check all the data.
+
+If you supply the --one-way flag, it will only check that the files in
+the source match the files in the destination, not the other way
+around.  Files which are only in the destination aren't reported.
+Use this to verify a backup made with copy which may have extra
+files.
+
+If you supply the --duplicates flag, it will instead report the files
+which are on both the source and the destination with the same size
+and hash but with different paths.  This can be used to find data
+which has been moved or copied before merging two remotes.  Only
+files with a size which is in both are hashed.  The report is written
+to standard output with one line per pair of files, each line being
+the hash, the size, the source path and the destination path
+separated by tabs, eg
+
+    rclone check --duplicates source:path dest:path > duplicates.txt
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(false, false, command, func() error {
+			if duplicates {
+				return operations.CheckDuplicates(cmd.Context(), fdst, fsrc, os.Stdout)
+			}
 			if download {
-				return operations.CheckDownload(fdst, fsrc)
+				return operations.CheckDownload(cmd.Context(), fdst, fsrc, oneway)
 			}
-			return operations.Check(fdst, fsrc)
+			return operations.Check(cmd.Context(), fdst, fsrc, oneway)
 		})
 	},
 }
@@ -14,14 +14,18 @@ var commandDefintion = &cobra.Command{
 	Use:   "cleanup remote:path",
 	Short: `Clean up the remote if possible`,
 	Long: `
-Clean up the remote if possible.  Empty the trash or delete old file
-versions. Not supported by all remotes.
+Clean up the remote if possible.  Empty the trash, delete old file
+versions or abort stale unfinished uploads, depending on what the
+remote supports.  Not supported by all remotes.
+
+Use --dry-run to check whether the remote supports cleaning up
+without changing anything.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(true, false, command, func() error {
-			return operations.CleanUp(fsrc)
+			return operations.CleanUp(cmd.Context(), fsrc)
 		})
 	},
 }
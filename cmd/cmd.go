@@ -7,6 +7,7 @@ package cmd
 // would probably mean bringing all the flags in to here? Or define some flagsets in fs...
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -51,8 +52,17 @@ var (
 	errorNotEnoughArguments = errors.New("not enough arguments")
 	errorTooManyArguents    = errors.New("too many arguments")
 	errorUsageError         = errors.New("usage error")
+	// rootCtx is cancelled when rclone is interrupted
+	rootCtx, cancelRoot = context.WithCancel(context.Background())
 )
 
+// Context returns the context commands should run their operations
+// with.  It is cancelled when Run is interrupted with SIGINT so the
+// operations in progress stop.
+func Context() context.Context {
+	return rootCtx
+}
+
 const (
 	exitCodeSuccess = iota
 	exitCodeUsageError
@@ -154,6 +164,7 @@ func NewFsFile(remote string) (fs.Fs, string) {
 		log.Fatalf("Failed to create file system for %q: %v", remote, err)
 	}
 	f, err := fsInfo.NewFs(configName, fsPath)
+	f = fs.Restrict(configName, f)
 	switch err {
 	case fs.ErrorIsFile:
 		return f, path.Base(fsPath)
@@ -173,6 +184,15 @@ func NewFsFile(remote string) (fs.Fs, string) {
 // This can point to a file
 func newFsSrc(remote string) (fs.Fs, string) {
 	f, fileName := NewFsFile(remote)
+	if jobFs == nil {
+		jobFs = f
+	}
+	if fs.Config.Versions && !f.Features().Versions {
+		fs.Logf(f, "Old versions can't be listed on this remote - ignoring --versions")
+	}
+	if fs.Config.Trash && !f.Features().Trash {
+		fs.Logf(f, "The trash can't be listed on this remote - ignoring --trash")
+	}
 	if fileName != "" {
 		if !filter.Active.InActive() {
 			err := errors.Errorf("Can't limit to single files when using filters: %v", remote)
@@ -198,6 +218,12 @@ func newFsDst(remote string) fs.Fs {
 		fs.CountError(err)
 		log.Fatalf("Failed to create file system for %q: %v", remote, err)
 	}
+	if jobFs == nil {
+		jobFs = f
+	}
+	if fs.Config.Trash && f.Features().Trash {
+		log.Fatalf("Can't use --trash with %q as the destination as only the trash would be seen", remote)
+	}
 	return f
 }
 
@@ -281,7 +307,6 @@ func ShowStats() bool {
 
 // Run the function with stats and retries if required
 func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
-	var err error
 	var stopStats chan struct{}
 	if !showStats && ShowStats() {
 		showStats = true
@@ -289,8 +314,20 @@ func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 	if showStats {
 		stopStats = StartStats()
 	}
-	for try := 1; try <= *retries; try++ {
+	atexit.OnInterrupt(cancelRoot)
+	// Check the source for unexpected changes before transferring
+	// anything
+	job, jobChanges, err := startJob(cmd.Name())
+	tries := *retries
+	if err != nil {
+		tries = 0
+	}
+	for try := 1; try <= tries; try++ {
 		err = f()
+		if rootCtx.Err() != nil {
+			err = rootCtx.Err()
+			break
+		}
 		if !Retry || (err == nil && !accounting.Stats.Errored()) {
 			if try > 1 {
 				fs.Errorf(nil, "Attempt %d/%d succeeded", try, *retries)
@@ -314,9 +351,15 @@ func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 			accounting.Stats.ResetErrors()
 		}
 	}
+	// Write out anything the remotes have buffered
+	if shutdownErr := fs.Shutdown(context.Background()); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
 	if showStats {
 		close(stopStats)
 	}
+	err = job.finish(err)
+	notify(cmd.Name(), err, jobChanges)
 	if err != nil {
 		log.Printf("Failed to %s: %v", cmd.Name(), err)
 		resolveExitCode(err)
@@ -348,6 +391,10 @@ func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 	if accounting.Stats.Errored() {
 		resolveExitCode(accounting.Stats.GetLastError())
 	}
+	if n := accounting.Stats.GetSkippedDirs(); n > 0 {
+		fs.Errorf(nil, "Skipped %d directories which couldn't be listed", n)
+		resolveExitCode(accounting.Stats.GetLastSkipError())
+	}
 }
 
 // CheckArgs checks there are enough arguments and prints a message if not
@@ -397,6 +444,12 @@ func initConfig() {
 
 	// Load filters
 	var err error
+	if filterflags.Profile != "" {
+		err = filterflags.AddProfile(&filterflags.Opt, filterflags.Profile)
+		if err != nil {
+			log.Fatalf("Failed to load filter profile: %v", err)
+		}
+	}
 	filter.Active, err = filter.NewFilter(&filterflags.Opt)
 	if err != nil {
 		log.Fatalf("Failed to load filters: %v", err)
@@ -457,6 +510,7 @@ func initConfig() {
 }
 
 func resolveExitCode(err error) {
+	atexit.Run()
 	if err == nil {
 		os.Exit(exitCodeSuccess)
 	}
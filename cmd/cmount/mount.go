@@ -19,9 +19,9 @@ import (
 	"github.com/billziss-gh/cgofuse/fuse"
 	"github.com/ncw/rclone/cmd/mountlib"
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/lib/service"
 	"github.com/ncw/rclone/vfs"
 	"github.com/ncw/rclone/vfs/vfsflags"
-	"github.com/okzk/sdnotify"
 	"github.com/pkg/errors"
 )
 
@@ -196,7 +196,7 @@ func mount(f fs.Fs, mountpoint string) (*vfs.VFS, <-chan error, func() error, er
 // If noModTime is set then it
 func Mount(f fs.Fs, mountpoint string) error {
 	// Mount it
-	FS, errChan, _, err := mount(f, mountpoint)
+	FS, errChan, unmount, err := mount(f, mountpoint)
 	if err != nil {
 		return errors.Wrap(err, "failed to mount FUSE fs")
 	}
@@ -206,8 +206,8 @@ func Mount(f fs.Fs, mountpoint string) error {
 	sigHup := make(chan os.Signal, 1)
 	signal.Notify(sigHup, syscall.SIGHUP)
 
-	if err := sdnotify.SdNotifyReady(); err != nil && err != sdnotify.SdNotifyNoSocket {
-		return errors.Wrap(err, "failed to notify systemd")
+	if err := service.Ready(); err != nil {
+		return err
 	}
 
 waitloop:
@@ -216,6 +216,10 @@ waitloop:
 		// umount triggered outside the app
 		case err = <-errChan:
 			break waitloop
+		// Service manager asked to stop: umount
+		case <-service.Stop():
+			err = unmount()
+			break waitloop
 		// user sent SIGHUP to clear the cache
 		case <-sigHup:
 			root, err := FS.Root()
@@ -227,7 +231,7 @@ waitloop:
 		}
 	}
 
-	_ = sdnotify.SdNotifyStopping()
+	service.Stopping()
 	if err != nil {
 		return errors.Wrap(err, "failed to umount FUSE fs")
 	}
@@ -1,11 +1,26 @@
 package config
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/fs/config"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+// exportPasswordEnv is the environment variable holding the password
+// for config export and import
+const exportPasswordEnv = "RCLONE_EXPORT_PASS"
+
+var (
+	exportEncrypt   bool
+	importOverwrite bool
+)
+
 func init() {
 	cmd.Root.AddCommand(configCommand)
 	configCommand.AddCommand(configEditCommand)
@@ -17,6 +32,11 @@ func init() {
 	configCommand.AddCommand(configUpdateCommand)
 	configCommand.AddCommand(configDeleteCommand)
 	configCommand.AddCommand(configPasswordCommand)
+	configCommand.AddCommand(configReconnectCommand)
+	configCommand.AddCommand(configExportCommand)
+	configCommand.AddCommand(configImportCommand)
+	configExportCommand.Flags().BoolVarP(&exportEncrypt, "encrypt", "", false, "Encrypt the export with a password")
+	configImportCommand.Flags().BoolVarP(&importOverwrite, "overwrite", "", false, "Replace remotes which already exist")
 }
 
 var configCommand = &cobra.Command{
@@ -107,6 +127,10 @@ in pairs of <key> <value>.
 For example to update the env_auth field of a remote of name myremote you would do:
 
     rclone config update myremote swift env_auth true
+
+This re-runs the authentication part of the remote's config, eg
+refreshing the OAuth token, after the options have been updated. Use
+"rclone config reconnect" to do only that.
 `,
 	RunE: func(command *cobra.Command, args []string) error {
 		cmd.CheckArgs(3, 256, command, args)
@@ -139,3 +163,118 @@ For example to set password of a remote of name myremote you would do:
 		return config.PasswordRemote(args[0], args[1:])
 	},
 }
+
+var configReconnectCommand = &cobra.Command{
+	Use:   "reconnect remote:",
+	Short: `Re-authenticate an existing remote and check it works.`,
+	Long: `
+Re-runs only the authentication part of the config of an existing
+remote, without having to retype all of its options, then checks the
+remote can be listed.
+
+For remotes using OAuth this gets a fresh token.  For remotes with
+stored passwords it offers to change each of them, which is useful
+when a password has been rotated.
+
+For example to get a new token for the remote mydrive you would do:
+
+    rclone config reconnect mydrive:
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(1, 1, command, args)
+		return config.ReconnectRemote(args[0])
+	},
+}
+
+var configExportCommand = &cobra.Command{
+	Use:   "export <name>+",
+	Short: `Export remotes to import on another machine.`,
+	Long: `
+Print the config of the remotes named as a single line of text which
+"rclone config import" can read on another machine.  This makes it
+easy to set up the same remotes on lots of machines.
+
+The passwords of the remotes are revealed in the export so they can
+be obscured again on import.  Anyone with a plain export can read
+them, so use --encrypt to encrypt the export with a password.  This is
+read from the environment variable ` + exportPasswordEnv + ` if set,
+otherwise rclone asks for it.
+
+For example to copy the remotes mydrive and mys3 to another machine
+
+    rclone config export --encrypt mydrive mys3 > remotes.txt
+
+then on the other machine
+
+    rclone config import "$(cat remotes.txt)"
+
+or, as rclone can't ask for the password when the export is read from
+standard input,
+
+    ` + exportPasswordEnv + `=secret rclone config import < remotes.txt
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(1, 256, command, args)
+		password := ""
+		if exportEncrypt {
+			password = os.Getenv(exportPasswordEnv)
+			if password == "" {
+				password = config.ChangePassword("export")
+			}
+		}
+		export, err := config.Export(args, password)
+		if err != nil {
+			return err
+		}
+		fmt.Println(export)
+		return nil
+	},
+}
+
+var configImportCommand = &cobra.Command{
+	Use:   "import [<export>]",
+	Short: `Import remotes exported by "rclone config export".`,
+	Long: `
+Add the remotes in an export made by "rclone config export" to the
+config file.  The export can be given as an argument or read from
+standard input.  Their passwords are obscured again for this machine.
+
+If the export is encrypted the password is read from the environment
+variable ` + exportPasswordEnv + ` if set, otherwise rclone asks for it.
+This needs standard input to be a terminal, so ` + exportPasswordEnv + `
+must be set to read an encrypted export from a file or pipe.
+
+Remotes which already exist are not replaced unless --overwrite is
+given.
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		cmd.CheckArgs(0, 1, command, args)
+		var export string
+		if len(args) == 1 {
+			export = args[0]
+		} else {
+			in, err := ioutil.ReadAll(os.Stdin)
+			if err != nil {
+				return errors.Wrap(err, "failed to read export")
+			}
+			export = string(in)
+		}
+		password := ""
+		if config.ExportEncrypted(export) {
+			password = os.Getenv(exportPasswordEnv)
+			if password == "" {
+				stat, _ := os.Stdin.Stat()
+				if stat == nil || stat.Mode()&os.ModeCharDevice == 0 {
+					return errors.Errorf("can't ask for the password of the encrypted export as standard input isn't a terminal - set %s", exportPasswordEnv)
+				}
+				password = config.GetPassword("Enter export password:")
+			}
+		}
+		names, err := config.Import(export, password, importOverwrite)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported remotes: %s\n", strings.Join(names, ", "))
+		return nil
+	},
+}
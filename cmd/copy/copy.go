@@ -6,8 +6,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Globals
+var (
+	createEmptySrcDirs = false
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
+	commandDefintion.Flags().BoolVarP(&createEmptySrcDirs, "create-empty-src-dirs", "", createEmptySrcDirs, "Create empty source dirs on destination after copy")
 }
 
 var commandDefintion = &cobra.Command{
@@ -49,12 +55,15 @@ If you are familiar with ` + "`rsync`" + `, rclone always works as if you had
 written a trailing / - meaning "copy the contents of this directory".
 This applies to all commands and whether you are talking about the
 source or destination.
+
+Empty directories in the source are not normally copied.  Use the
+--create-empty-src-dirs flag to create them on the destination too.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(true, true, command, func() error {
-			return sync.CopyDir(fdst, fsrc)
+			return sync.CopyDir(cmd.Context(), fdst, fsrc, createEmptySrcDirs)
 		})
 	},
 }
@@ -40,15 +40,31 @@ This will:
 This doesn't transfer unchanged files, testing by size and
 modification time or MD5SUM.  It doesn't delete files from the
 destination.
+
+Either src or dst can be ` + "`-`" + ` for standard input or output, or
+the path of a local named pipe, so copyto can be used in pipelines.
+These streams are always copied as their size isn't known in advance.
+
+    tar cz dir | rclone copyto - remote:backup.tar.gz
+    rclone copyto remote:backup.tar.gz - | tar xz
+
+Copies from a stream work like ` + "`rclone rcat`" + ` and copies to a
+stream like ` + "`rclone cat`" + `.  Neither can be retried.  Nothing
+else can write to standard output when copying to ` + "`-`" + `, so
+` + "`--interactive`" + `, ` + "`--on-success-cmd`" + `, ` + "`--on-failure-cmd`" + ` and
+` + "`--dump`" + ` goroutines or openfiles can't be used with it.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
+		if cmd.RunStream(command, args, false) {
+			return
+		}
 		fsrc, srcFileName, fdst, dstFileName := cmd.NewFsSrcDstFiles(args)
 		cmd.Run(true, true, command, func() error {
 			if srcFileName == "" {
-				return sync.CopyDir(fdst, fsrc)
+				return sync.CopyDir(cmd.Context(), fdst, fsrc, false)
 			}
-			return operations.CopyFile(fdst, fsrc, dstFileName, srcFileName)
+			return operations.CopyFile(cmd.Context(), fdst, fsrc, dstFileName, srcFileName)
 		})
 	},
 }
@@ -54,6 +54,12 @@ After it has run it will log the status of the encryptedremote:.
 func cryptCheck(fdst, fsrc fs.Fs) error {
 	// Check to see fcrypt is a crypt
 	fcrypt, ok := fdst.(*crypt.Fs)
+	if !ok {
+		// it may be wrapped, eg if the remote is read only
+		if unwrap := fdst.Features().UnWrap; unwrap != nil {
+			fcrypt, ok = unwrap().(*crypt.Fs)
+		}
+	}
 	if !ok {
 		return errors.Errorf("%s:%s is not a crypt remote", fdst.Name(), fdst.Root())
 	}
@@ -81,7 +87,7 @@ func cryptCheck(fdst, fsrc fs.Fs) error {
 		if underlyingHash == "" {
 			return false, true
 		}
-		cryptHash, err := fcrypt.ComputeHash(cryptDst, src, hashType)
+		cryptHash, err := fcrypt.ComputeHash(cmd.Context(), cryptDst, src, hashType)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(dst, "Error computing hash: %v", err)
@@ -100,5 +106,5 @@ func cryptCheck(fdst, fsrc fs.Fs) error {
 		return false, false
 	}
 
-	return operations.CheckFn(fcrypt, fsrc, checkIdentical)
+	return operations.CheckFn(cmd.Context(), fcrypt, fsrc, checkIdentical, false)
 }
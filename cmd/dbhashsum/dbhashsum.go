@@ -25,7 +25,7 @@ The output is in the same format as md5sum and sha1sum.
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			return operations.DropboxHashSum(fsrc, os.Stdout)
+			return operations.DropboxHashSum(cmd.Context(), fsrc, os.Stdout)
 		})
 	},
 }
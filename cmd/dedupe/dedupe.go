@@ -112,7 +112,7 @@ Or
 		}
 		fdst := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			return operations.Deduplicate(fdst, dedupeMode)
+			return operations.Deduplicate(cmd.Context(), fdst, dedupeMode)
 		})
 	},
 }
@@ -0,0 +1,304 @@
+// Package diff implements the "rclone diff" command which lists the
+// differences between two remotes
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/march"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fs/walk"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jsonOutput bool
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().BoolVarP(&jsonOutput, "json", "", false, "format output as JSON")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "diff source:path dest:path",
+	Short: `List the differences between the source and destination.`,
+	Long: `
+rclone diff compares the files in the source and destination in the
+same way as rclone check and prints three lists
+
+  * the files missing on the source (only in the destination)
+  * the files missing on the destination (only in the source)
+  * the files in both which differ
+
+with the size of each file.  If one side has a file where the other
+has a directory of the same name then the file and all the files in
+the directory are listed as missing.  Files in both are compared by
+size and then by hash if the remotes have one in common.  The hash
+status of each differing file says why it differs
+
+  * differ - the hashes differ
+  * unchecked - the sizes differ so the hashes weren't read
+  * error - a hash couldn't be read
+
+Use --json to print the lists as JSON instead.  Use --size-only to
+compare files by size only.
+
+rclone diff doesn't change the source or destination.  It exits with
+a non zero status if any differences were found.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fsrc, fdst := cmd.NewFsSrcDst(args)
+		cmd.Run(false, false, command, func() error {
+			results, err := Diff(cmd.Context(), fdst, fsrc)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				err = json.NewEncoder(os.Stdout).Encode(results)
+				if err != nil {
+					return err
+				}
+			} else {
+				results.print()
+			}
+			if n := results.Differences(); n > 0 {
+				return errors.Errorf("%d differences found", n)
+			}
+			return nil
+		})
+	},
+}
+
+// Hash statuses for a Difference
+const (
+	HashDiffer    = "differ"
+	HashUnchecked = "unchecked"
+	HashError     = "error"
+)
+
+// File is a file found on only one of the remotes
+type File struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Difference is a file found on both remotes which differs
+type Difference struct {
+	Path    string `json:"path"`
+	SrcSize int64  `json:"srcSize"`
+	DstSize int64  `json:"dstSize"`
+	Hash    string `json:"hash"`
+}
+
+// Results is the output of the diff command
+type Results struct {
+	MissingOnSrc []File       `json:"missingOnSrc"`
+	MissingOnDst []File       `json:"missingOnDst"`
+	Differ       []Difference `json:"differ"`
+	Same         int64        `json:"same"`
+	NoHash       int64        `json:"noHash"`
+}
+
+// Differences returns the total number of differences found
+func (r *Results) Differences() int {
+	return len(r.MissingOnSrc) + len(r.MissingOnDst) + len(r.Differ)
+}
+
+// print the results as text to stdout
+func (r *Results) print() {
+	printFiles := func(title string, files []File) {
+		fmt.Printf("%s: %d\n", title, len(files))
+		for _, file := range files {
+			fmt.Printf("%14s %s\n", fs.SizeSuffix(file.Size).Unit("Bytes"), file.Path)
+		}
+	}
+	printFiles("Missing on source", r.MissingOnSrc)
+	printFiles("Missing on destination", r.MissingOnDst)
+	fmt.Printf("Differ: %d\n", len(r.Differ))
+	for _, d := range r.Differ {
+		fmt.Printf("%14s %14s %-9s %s\n", fs.SizeSuffix(d.SrcSize).Unit("Bytes"), fs.SizeSuffix(d.DstSize).Unit("Bytes"), d.Hash, d.Path)
+	}
+	fmt.Printf("Same: %d\n", r.Same)
+	if r.NoHash > 0 {
+		fmt.Printf("Same size without a hash to check: %d\n", r.NoHash)
+	}
+}
+
+// filesByPath sorts files by path
+type filesByPath []File
+
+func (s filesByPath) Len() int           { return len(s) }
+func (s filesByPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s filesByPath) Less(i, j int) bool { return s[i].Path < s[j].Path }
+
+// differencesByPath sorts differences by path
+type differencesByPath []Difference
+
+func (s differencesByPath) Len() int           { return len(s) }
+func (s differencesByPath) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s differencesByPath) Less(i, j int) bool { return s[i].Path < s[j].Path }
+
+// diffMarch is used to march over the source and destination
+// collecting the results
+type diffMarch struct {
+	ctx     context.Context
+	fdst    fs.Fs
+	fsrc    fs.Fs
+	mu      sync.Mutex
+	results Results
+	err     error // first error listing a directory
+}
+
+// addFiles adds the object to files, or all the objects under it if
+// it is a directory
+func (d *diffMarch) addFiles(files *[]File, entry fs.DirEntry) (recurse bool) {
+	switch x := entry.(type) {
+	case fs.Object:
+		d.mu.Lock()
+		*files = append(*files, File{Path: x.Remote(), Size: x.Size()})
+		d.mu.Unlock()
+	case fs.Directory:
+		return true
+	default:
+		panic("Bad object in DirEntries")
+	}
+	return false
+}
+
+// addDirFiles adds all the objects under dir in f to files.  It is
+// used when the other remote has a file with the same name as dir so
+// the march doesn't recurse into it.
+func (d *diffMarch) addDirFiles(files *[]File, f fs.Fs, dir fs.DirEntry) {
+	depth := fs.Config.MaxDepth
+	if depth >= 0 {
+		depth -= strings.Count(dir.Remote(), "/") + 1
+		if depth <= 0 {
+			return
+		}
+	}
+	err := walk.Walk(d.ctx, f, dir.Remote(), false, depth, func(dirPath string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			return err
+		}
+		entries.ForObject(func(o fs.Object) {
+			d.addFiles(files, o)
+		})
+		return nil
+	})
+	if err != nil {
+		fs.CountError(err)
+		fs.Errorf(dir, "Failed to list directory: %v", err)
+		d.mu.Lock()
+		if d.err == nil {
+			d.err = err
+		}
+		d.mu.Unlock()
+	}
+}
+
+// DstOnly is called for an entry only in the destination
+func (d *diffMarch) DstOnly(dst fs.DirEntry) (recurse bool) {
+	return d.addFiles(&d.results.MissingOnSrc, dst)
+}
+
+// SrcOnly is called for an entry only in the source
+func (d *diffMarch) SrcOnly(src fs.DirEntry) (recurse bool) {
+	return d.addFiles(&d.results.MissingOnDst, src)
+}
+
+// Match is called for an entry in both the source and destination
+func (d *diffMarch) Match(dst, src fs.DirEntry) (recurse bool) {
+	srcObj, srcIsObj := src.(fs.Object)
+	dstObj, dstIsObj := dst.(fs.Object)
+	switch {
+	case srcIsObj && dstIsObj:
+		d.compare(dstObj, srcObj)
+	case srcIsObj:
+		// a file in the source and a directory in the destination
+		d.addFiles(&d.results.MissingOnDst, src)
+		d.addDirFiles(&d.results.MissingOnSrc, d.fdst, dst)
+	case dstIsObj:
+		// a directory in the source and a file in the destination
+		d.addFiles(&d.results.MissingOnSrc, dst)
+		d.addDirFiles(&d.results.MissingOnDst, d.fsrc, src)
+	default:
+		return true
+	}
+	return false
+}
+
+// compare dst and src recording the result
+func (d *diffMarch) compare(dst, src fs.Object) {
+	accounting.Stats.Checking(src.Remote())
+	defer accounting.Stats.DoneChecking(src.Remote())
+	status := ""
+	noHash := false
+	if src.Size() != dst.Size() {
+		status = HashUnchecked
+	} else if !fs.Config.SizeOnly {
+		same, ht, err := operations.CheckHashes(src, dst)
+		switch {
+		case err != nil:
+			status = HashError
+		case ht == hash.None:
+			noHash = true
+		case !same:
+			status = HashDiffer
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if status == "" {
+		d.results.Same++
+		if noHash {
+			d.results.NoHash++
+		}
+		return
+	}
+	d.results.Differ = append(d.results.Differ, Difference{
+		Path:    src.Remote(),
+		SrcSize: src.Size(),
+		DstSize: dst.Size(),
+		Hash:    status,
+	})
+}
+
+// Diff compares the files in fsrc and fdst by size and hash and
+// returns the files missing on each side and the files which differ.
+func Diff(ctx context.Context, fdst, fsrc fs.Fs) (*Results, error) {
+	d := &diffMarch{
+		ctx:  ctx,
+		fdst: fdst,
+		fsrc: fsrc,
+		results: Results{
+			MissingOnSrc: []File{},
+			MissingOnDst: []File{},
+			Differ:       []Difference{},
+		},
+	}
+	m := march.New(ctx, fdst, fsrc, "", d)
+	m.Run()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	sort.Sort(filesByPath(d.results.MissingOnSrc))
+	sort.Sort(filesByPath(d.results.MissingOnDst))
+	sort.Sort(differencesByPath(d.results.Differ))
+	return &d.results, nil
+}
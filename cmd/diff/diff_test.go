@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+var (
+	t1 = fstest.Time("2017-02-03T04:05:06.499999999Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func TestDiff(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	file1 := r.WriteFile("same", "same contents", t1)
+	file2 := r.WriteFile("dir/src only", "source", t1)
+	file3 := r.WriteFile("size", "short", t1)
+	file4 := r.WriteFile("contents", "AAAA", t1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3, file4)
+	file5 := r.WriteObject("same", "same contents", t1)
+	file6 := r.WriteObject("dst only", "destination", t1)
+	file7 := r.WriteObject("size", "much longer", t1)
+	file8 := r.WriteObject("contents", "BBBB", t1)
+	fstest.CheckItems(t, r.Fremote, file5, file6, file7, file8)
+
+	results, err := Diff(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	assert.Equal(t, &Results{
+		MissingOnSrc: []File{{Path: "dst only", Size: 11}},
+		MissingOnDst: []File{{Path: "dir/src only", Size: 6}},
+		Differ: []Difference{
+			{Path: "contents", SrcSize: 4, DstSize: 4, Hash: HashDiffer},
+			{Path: "size", SrcSize: 5, DstSize: 11, Hash: HashUnchecked},
+		},
+		Same: 1,
+	}, results)
+	assert.Equal(t, 4, results.Differences())
+}
+
+func TestDiffFileAndDirectory(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	file1 := r.WriteFile("a", "file in source", t1)
+	file2 := r.WriteFile("b/one", "one", t1)
+	file3 := r.WriteFile("b/sub/two", "two", t1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+	file4 := r.WriteObject("a/three", "three", t1)
+	file5 := r.WriteObject("b", "file in destination", t1)
+	fstest.CheckItems(t, r.Fremote, file4, file5)
+
+	results, err := Diff(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	assert.Equal(t, []File{{Path: "a/three", Size: 5}, {Path: "b", Size: 19}}, results.MissingOnSrc)
+	assert.Equal(t, []File{{Path: "a", Size: 14}, {Path: "b/one", Size: 3}, {Path: "b/sub/two", Size: 3}}, results.MissingOnDst)
+	assert.Equal(t, 5, results.Differences())
+}
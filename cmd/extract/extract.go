@@ -0,0 +1,68 @@
+// Package extract implements the "rclone extract" command which
+// extracts an archive on a remote to another remote
+package extract
+
+import (
+	"log"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/lib/archive"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	format = ""
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().StringVarP(&format, "format", "", format, "Archive format: zip, tar or tar.gz (default from the file extension)")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "extract source:path/archive.zip dest:path",
+	Short: `Extract a zip or tar archive on a remote into dest:path.`,
+	Long: `
+rclone extract reads a zip, tar or tar.gz archive from a remote and
+uploads the files in it to dest:path without storing the archive or
+the files on local disk, eg
+
+    rclone extract s3:backup/photos.zip drive:photos
+
+The format is worked out from the extension of the archive (.zip,
+.tar, .tar.gz or .tgz) unless it is set with --format.
+
+Tar archives are streamed.  Zip archives keep their index at the end
+so they are read with range requests, which the source remote must
+support.
+
+Use the filter flags (eg --include, --exclude) to control which paths
+in the archive are extracted.  Files in dest:path with the same names
+as files in the archive are overwritten.  Paths in the archive can't
+extract to outside dest:path.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fsrc, srcFileName := cmd.NewFsFile(args[0])
+		if srcFileName == "" {
+			log.Fatalf("%q is not a file", args[0])
+		}
+		fdst := cmd.NewFsDst(args[1:])
+		cmd.Run(false, false, command, func() error {
+			archiveFormat := format
+			if archiveFormat == "" {
+				archiveFormat = archive.FormatFromName(srcFileName)
+				if archiveFormat == "" {
+					return errors.Errorf("can't work out the archive format of %q - use --format", srcFileName)
+				}
+			}
+			ctx := cmd.Context()
+			src, err := fsrc.NewObject(ctx, srcFileName)
+			if err != nil {
+				return errors.Wrap(err, "failed to find archive")
+			}
+			return archive.Extract(ctx, fdst, "", src, archiveFormat)
+		})
+	},
+}
@@ -0,0 +1,153 @@
+// Package filtertest implements the "rclone filtertest" command
+// which shows which filter rule includes or excludes each path
+package filtertest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/list"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pathsFrom string
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().StringVarP(&pathsFrom, "paths-from", "", "", "Read the paths to test from this file, use - for stdin")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "filtertest [remote:path]",
+	Short: `Show which filter rule includes or excludes each path.`,
+	Long: `
+rclone filtertest evaluates the filter flags (--include, --exclude,
+--filter, --files-from, --min-size etc) against each path and prints
+whether it is included (+) or excluded (-) and the rule which decided
+it.  This is useful for debugging complicated sets of filters.
+
+Either give it a remote:path to walk, in which case it lists it the
+way a sync would, not looking inside excluded directories, eg
+
+    $ rclone filtertest --filter "- *.tmp" --filter "+ /photos/**" --filter "- *" remote:
+    - notes.txt: excluded by rule "- *"
+    + photos/: included by rule "+ /photos/**"
+    + photos/a.jpg: included by rule "+ /photos/**"
+    - photos/b.tmp: excluded by rule "- *.tmp"
+
+Or give it a file of paths, one per line, with --paths-from.  Paths
+ending in / are treated as directories.  As the paths don't have sizes
+or modification times the --min-size, --max-size, --min-age and
+--max-age filters aren't checked, eg
+
+    rclone filtertest --filter-from filters.txt --paths-from paths.txt
+`,
+	Run: func(command *cobra.Command, args []string) {
+		if pathsFrom != "" {
+			cmd.CheckArgs(0, 0, command, args)
+			cmd.Run(false, false, command, func() (err error) {
+				in := os.Stdin
+				if pathsFrom != "-" {
+					in, err = os.Open(pathsFrom)
+					if err != nil {
+						return errors.Wrap(err, "failed to open paths file")
+					}
+					defer fs.CheckClose(in, &err)
+				}
+				return Paths(cmd.Context(), filter.Active, in, os.Stdout)
+			})
+			return
+		}
+		cmd.CheckArgs(1, 1, command, args)
+		fsrc := cmd.NewFsSrc(args)
+		cmd.Run(false, false, command, func() error {
+			return Walk(cmd.Context(), filter.Active, fsrc, os.Stdout)
+		})
+	},
+}
+
+// show writes one line of the output
+func show(out io.Writer, include bool, remote, reason string) {
+	c := '-'
+	if include {
+		c = '+'
+	}
+	_, _ = fmt.Fprintf(out, "%c %s: %s\n", c, remote, reason)
+}
+
+// Paths explains the filtering of each path read from in, writing
+// the results to out.  Paths ending in / are directories.
+func Paths(ctx context.Context, fi *filter.Filter, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		remote := strings.TrimSpace(scanner.Text())
+		if remote == "" {
+			continue
+		}
+		if strings.HasSuffix(remote, "/") {
+			include, reason, err := fi.ExplainDirectory(ctx, nil, remote)
+			if err != nil {
+				return err
+			}
+			show(out, include, remote, reason)
+		} else {
+			include, reason := fi.Explain(strings.TrimLeft(remote, "/"), -1, time.Time{})
+			show(out, include, remote, reason)
+		}
+	}
+	return scanner.Err()
+}
+
+// Walk explains the filtering of each entry in f, writing the results
+// to out.  Excluded directories aren't looked in as a sync wouldn't.
+func Walk(ctx context.Context, fi *filter.Filter, f fs.Fs, out io.Writer) error {
+	return walkDir(ctx, fi, f, "", out)
+}
+
+// walkDir explains the filtering of dir and its included subdirectories
+func walkDir(ctx context.Context, fi *filter.Filter, f fs.Fs, dir string, out io.Writer) error {
+	entries, err := list.DirSorted(ctx, f, true, dir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list %q", dir)
+	}
+	for _, entry := range entries {
+		switch x := entry.(type) {
+		case fs.Object:
+			var modTime time.Time
+			if !fi.ModTimeFrom.IsZero() || !fi.ModTimeTo.IsZero() {
+				modTime = x.ModTime()
+			}
+			include, reason := fi.Explain(x.Remote(), x.Size(), modTime)
+			if include {
+				if mimeInclude, mimeReason := fi.ExplainMimeType(x); mimeReason != "" {
+					include, reason = mimeInclude, mimeReason
+				}
+			}
+			show(out, include, x.Remote(), reason)
+		case fs.Directory:
+			include, reason, err := fi.ExplainDirectory(ctx, f, x.Remote())
+			if err != nil {
+				return err
+			}
+			show(out, include, x.Remote()+"/", reason)
+			if include {
+				err = walkDir(ctx, fi, f, x.Remote(), out)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
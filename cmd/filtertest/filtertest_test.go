@@ -0,0 +1,70 @@
+package filtertest
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+// newFilter makes a filter from the rules
+func newFilter(t *testing.T, rules ...string) *filter.Filter {
+	fi, err := filter.NewFilter(nil)
+	require.NoError(t, err)
+	for _, rule := range rules {
+		require.NoError(t, fi.AddRule(rule))
+	}
+	return fi
+}
+
+func TestPaths(t *testing.T) {
+	fi := newFilter(t, "- *.tmp", "+ /photos/**", "- *")
+	in := strings.NewReader("photos/\nphotos/a.jpg\n\nphotos/b.tmp\nnotes.txt\ndocs/\n")
+	out := new(bytes.Buffer)
+	err := Paths(context.Background(), fi, in, out)
+	require.NoError(t, err)
+	assert.Equal(t, `+ photos/: included by rule "+ /photos/**"
++ photos/a.jpg: included by rule "+ /photos/**"
+- photos/b.tmp: excluded by rule "- *.tmp"
+- notes.txt: excluded by rule "- *"
+- docs/: excluded by rule "- *"
+`, out.String())
+}
+
+func TestWalk(t *testing.T) {
+	fstest.Initialise()
+	dir, err := ioutil.TempDir("", "rclone-filtertest")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	for _, name := range []string{"notes.txt", "photos/a.jpg", "photos/b.tmp", "docs/c.txt"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0777))
+		require.NoError(t, ioutil.WriteFile(p, []byte(name), 0666))
+	}
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+
+	fi := newFilter(t, "- *.tmp", "+ /photos/**", "- *")
+	out := new(bytes.Buffer)
+	err = Walk(context.Background(), fi, f, out)
+	require.NoError(t, err)
+	assert.Equal(t, `- docs/: excluded by rule "- *"
+- notes.txt: excluded by rule "- *"
++ photos/: included by rule "+ /photos/**"
++ photos/a.jpg: included by rule "+ /photos/**"
+- photos/b.tmp: excluded by rule "- *.tmp"
+`, out.String())
+}
@@ -31,6 +31,7 @@ Run without a hash to see the list of supported hashes, eg
       * SHA-1
       * DropboxHash
       * QuickXorHash
+      * SHA-256
 
 Then
 
@@ -54,7 +55,7 @@ Then
 		}
 		fsrc := cmd.NewFsSrc(args[1:])
 		cmd.Run(false, false, command, func() error {
-			return operations.HashLister(ht, fsrc, os.Stdout)
+			return operations.HashLister(cmd.Context(), ht, fsrc, os.Stdout)
 		})
 		return nil
 	},
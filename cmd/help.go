@@ -0,0 +1,125 @@
+// The help command and its help topics
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/spf13/cobra"
+)
+
+// helpCommand replaces the default cobra help command so it can have
+// topics of its own
+var helpCommand = &cobra.Command{
+	Use:   "help [command]",
+	Short: "Help about any command",
+	Long: `Help provides help for any command in the application.
+Simply type rclone help [path to command] for full details.`,
+	Run: func(command *cobra.Command, args []string) {
+		found, _, err := Root.Find(args)
+		if found == nil || err != nil {
+			command.Printf("Unknown help topic %#q\n", args)
+			_ = Root.Usage()
+			return
+		}
+		found.InitDefaultHelpFlag() // make possible 'help' flag to be shown
+		_ = found.Help()
+	},
+}
+
+// Flags for help backends
+var helpBackendsFeatures = false
+
+// helpBackends lists the backends
+var helpBackends = &cobra.Command{
+	Use:   "backends",
+	Short: "List the backends available.",
+	Long: `
+List the names and descriptions of the backends compiled into rclone.
+
+With --features it prints a JSON list instead with the optional
+interfaces the Fs and Object of each backend implement, eg
+
+    [
+      {
+        "name": "local",
+        "description": "Local Disk",
+        "fs": ["Purger", "Mover", ...],
+        "object": ["Metadataer", "SetMetadataer"]
+      },
+      ...
+    ]
+
+This is worked out from the backends without making any remotes so
+the features of wrapping backends such as crypt depend on the remote
+they wrap too.  "fs" and "object" are null for backends which use the
+Fs of another backend, eg alias.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		CheckArgs(0, 0, command, args)
+		if helpBackendsFeatures {
+			err := writeBackendFeatures()
+			if err != nil {
+				fs.Errorf(nil, "Failed to write backends: %v", err)
+				resolveExitCode(err)
+			}
+			return
+		}
+		for _, ri := range sortedRegistry() {
+			fmt.Printf("%-20s %s\n", ri.Name, ri.Description)
+		}
+	},
+}
+
+func init() {
+	helpBackends.Flags().BoolVarP(&helpBackendsFeatures, "features", "", helpBackendsFeatures, "Print the optional interfaces of each backend as JSON.")
+	helpCommand.AddCommand(helpBackends)
+	Root.SetHelpCommand(helpCommand)
+}
+
+// sortedRegistry returns the registered backends sorted by name
+func sortedRegistry() []*fs.RegInfo {
+	names := make([]string, 0, len(fs.Registry))
+	byName := make(map[string]*fs.RegInfo, len(fs.Registry))
+	for _, ri := range fs.Registry {
+		names = append(names, ri.Name)
+		byName[ri.Name] = ri
+	}
+	sort.Strings(names)
+	registry := make([]*fs.RegInfo, len(names))
+	for i, name := range names {
+		registry[i] = byName[name]
+	}
+	return registry
+}
+
+// backendFeatures is the output of help backends --features for
+// one backend
+type backendFeatures struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Fs          []string `json:"fs"`
+	Object      []string `json:"object"`
+}
+
+// writeBackendFeatures writes the optional interfaces of each backend
+// to stdout as JSON
+func writeBackendFeatures() error {
+	var out []backendFeatures
+	for _, ri := range sortedRegistry() {
+		fsNames, objectNames, _ := ri.Interfaces()
+		out = append(out, backendFeatures{
+			Name:        ri.Name,
+			Description: ri.Description,
+			Fs:          fsNames,
+			Object:      objectNames,
+		})
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,352 @@
+// Package index implements the "rclone index" command which writes a
+// static HTML and JSON index of a remote into it
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fs/walk"
+	"github.com/ncw/rclone/lib/rest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	indexName = "index.html"
+	jsonName  = "index.json"
+	links     = false
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().StringVarP(&indexName, "index-name", "", indexName, "Name of the HTML index written in each directory.")
+	commandDefinition.Flags().StringVarP(&jsonName, "json-name", "", jsonName, "Name of the JSON index written in the root, \"\" for none.")
+	commandDefinition.Flags().BoolVarP(&links, "links", "", links, "Link to the files with public links made by the remote.")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "index remote:path",
+	Short: `Write a static HTML and JSON index of the remote into it.`,
+	Long: `
+rclone index lists remote:path and uploads an HTML page to each
+directory in it, called ` + "`index.html`" + ` by default, listing the
+files and directories in it with their sizes and modification times.
+The size of a directory is the total size of the files in it and below
+it.  A JSON index of the whole tree is uploaded to the root as
+` + "`index.json`" + `.  This lets others browse a dataset without
+rclone, eg with a bucket served as a static website
+
+    rclone index s3:dataset
+
+The pages link to each other and to the files with relative links.  If
+the remote is one where relative links don't work, eg Drive, use
+` + "`--links`" + ` to link to the files with public links made by the
+remote, as made by rclone link, instead.  These are also put in the
+JSON index.
+
+The JSON index is a tree of directories like this
+
+    {
+      "Name": "",
+      "Path": "",
+      "Size": 6,
+      "Count": 1,
+      "Dirs": [],
+      "Files": [
+        {
+          "Name": "file.txt",
+          "Path": "file.txt",
+          "Size": 6,
+          "ModTime": "2017-05-31T16:15:57.034468261+01:00",
+          "Link": "https://example.com/s/file.txt"
+        }
+      ]
+    }
+
+where Count is the number of files in the directory and below it.
+
+Any files already called the index names are left out of the index and
+replaced.  The filters can be used to choose what is indexed, and
+--dry-run to see what would be uploaded.  Run rclone index again after
+changing the data to bring the index up to date.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		fsrc := cmd.NewFsSrc(args)
+		cmd.Run(true, false, command, func() error {
+			ctx := cmd.Context()
+			root, err := Build(ctx, fsrc)
+			if err != nil {
+				return err
+			}
+			return Upload(ctx, fsrc, root)
+		})
+	},
+}
+
+// Dir is a directory in the index
+type Dir struct {
+	Name  string  // leaf name of the directory, "" for the root
+	Path  string  // path of the directory from the root
+	Size  int64   // total size of the files in the directory and below
+	Count int64   // number of files in the directory and below
+	Dirs  []*Dir  // directories in this directory
+	Files []*File // files in this directory
+}
+
+// File is a file in the index
+type File struct {
+	Name    string    // leaf name of the file
+	Path    string    // path of the file from the root
+	Size    int64     // size of the file
+	ModTime time.Time // modification time of the file
+	Link    string    `json:",omitempty"` // public link to the file if --links
+}
+
+// newDir makes a Dir for dirPath
+func newDir(dirPath string) *Dir {
+	d := &Dir{
+		Path:  dirPath,
+		Dirs:  []*Dir{},
+		Files: []*File{},
+	}
+	if dirPath != "" {
+		d.Name = path.Base(dirPath)
+	}
+	return d
+}
+
+// Build lists f making the index of it
+func Build(ctx context.Context, f fs.Fs) (*Dir, error) {
+	root := newDir("")
+	dirs := map[string]*Dir{"": root}
+	// getDir finds the Dir for dirPath making it and its parents
+	// if necessary as --fast-list may list them in any order
+	var getDir func(dirPath string) *Dir
+	getDir = func(dirPath string) *Dir {
+		if d, ok := dirs[dirPath]; ok {
+			return d
+		}
+		parentPath := path.Dir(dirPath)
+		if parentPath == "." {
+			parentPath = ""
+		}
+		parent := getDir(parentPath)
+		d := newDir(dirPath)
+		parent.Dirs = append(parent.Dirs, d)
+		dirs[dirPath] = d
+		return d
+	}
+	err := walk.Walk(ctx, f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(dirPath, "error listing: %v", err)
+			return nil
+		}
+		for _, entry := range entries {
+			switch x := entry.(type) {
+			case fs.Directory:
+				getDir(x.Remote())
+			case fs.Object:
+				name := path.Base(x.Remote())
+				if name == indexName || (dirPath == "" && name == jsonName) {
+					continue
+				}
+				file := &File{
+					Name:    name,
+					Path:    x.Remote(),
+					Size:    x.Size(),
+					ModTime: x.ModTime(),
+				}
+				if links {
+					file.Link, err = operations.PublicLink(ctx, f, x.Remote(), fs.DurationOff, "")
+					if err != nil {
+						return errors.Wrapf(err, "failed to make public link for %q", x.Remote())
+					}
+				}
+				d := getDir(dirPath)
+				d.Files = append(d.Files, file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list remote")
+	}
+	root.total()
+	return root, nil
+}
+
+// total sorts d and works out the sizes and counts of it and the
+// directories below it
+func (d *Dir) total() {
+	sort.Sort(dirsByName(d.Dirs))
+	sort.Sort(filesByName(d.Files))
+	d.Size, d.Count = 0, 0
+	for _, file := range d.Files {
+		if file.Size > 0 {
+			d.Size += file.Size
+		}
+		d.Count++
+	}
+	for _, subDir := range d.Dirs {
+		subDir.total()
+		d.Size += subDir.Size
+		d.Count += subDir.Count
+	}
+}
+
+// dirsByName sorts Dirs by name
+type dirsByName []*Dir
+
+func (ds dirsByName) Len() int           { return len(ds) }
+func (ds dirsByName) Swap(i, j int)      { ds[i], ds[j] = ds[j], ds[i] }
+func (ds dirsByName) Less(i, j int) bool { return ds[i].Name < ds[j].Name }
+
+// filesByName sorts Files by name
+type filesByName []*File
+
+func (files filesByName) Len() int           { return len(files) }
+func (files filesByName) Swap(i, j int)      { files[i], files[j] = files[j], files[i] }
+func (files filesByName) Less(i, j int) bool { return files[i].Name < files[j].Name }
+
+// indexPage is the template for the HTML index of each directory
+var indexPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>{{ .Size }} in {{ .Count }} files{{ if .JSON }} - <a href="{{ .JSON }}">JSON index</a>{{ end }}</p>
+<table>
+<tr><th>Name</th><th>Size</th><th>Modified</th></tr>
+{{ if .Parent }}<tr><td><a href="{{ .Parent }}">../</a></td><td></td><td></td></tr>
+{{ end }}{{ range .Entries }}<tr><td><a href="{{ .URL }}">{{ .Name }}</a></td><td>{{ .Size }}</td><td>{{ .Modified }}</td></tr>
+{{ end }}</table>
+</body>
+</html>
+`
+
+// indexTemplate is the instantiated indexPage
+var indexTemplate = template.Must(template.New("index").Parse(indexPage))
+
+// indexData is used to fill in the indexTemplate
+type indexData struct {
+	Title   string
+	Size    string
+	Count   int64
+	JSON    string
+	Parent  string
+	Entries []indexEntry
+}
+
+// indexEntry is a row of the indexTemplate
+type indexEntry struct {
+	Name     string
+	URL      string
+	Size     string
+	Modified string
+}
+
+// WriteHTML writes the HTML index of d to out
+func WriteHTML(out io.Writer, d *Dir) error {
+	// relative path from d to the root
+	toRoot := strings.Repeat("../", strings.Count(d.Path, "/")+1)
+	if d.Path == "" {
+		toRoot = ""
+	}
+	data := indexData{
+		Title: "Index of /" + d.Path,
+		Size:  fs.SizeSuffix(d.Size).String(),
+		Count: d.Count,
+	}
+	if jsonName != "" {
+		data.JSON = toRoot + rest.URLPathEscape(jsonName)
+	}
+	if d.Path != "" {
+		data.Parent = "../" + rest.URLPathEscape(indexName)
+	}
+	for _, subDir := range d.Dirs {
+		data.Entries = append(data.Entries, indexEntry{
+			Name: subDir.Name + "/",
+			URL:  rest.URLPathEscape(subDir.Name) + "/" + rest.URLPathEscape(indexName),
+			Size: fs.SizeSuffix(subDir.Size).String(),
+		})
+	}
+	for _, file := range d.Files {
+		URL := file.Link
+		if URL == "" {
+			URL = rest.URLPathEscape(file.Name)
+		}
+		data.Entries = append(data.Entries, indexEntry{
+			Name:     file.Name,
+			URL:      URL,
+			Size:     fs.SizeSuffix(file.Size).String(),
+			Modified: file.ModTime.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return indexTemplate.Execute(out, data)
+}
+
+// Upload uploads the HTML index of each directory in root and the
+// JSON index of all of them to f
+func Upload(ctx context.Context, f fs.Fs, root *Dir) error {
+	if jsonName != "" {
+		data, err := json.MarshalIndent(root, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to make JSON index")
+		}
+		err = upload(ctx, f, jsonName, data)
+		if err != nil {
+			return err
+		}
+	}
+	var uploadDir func(d *Dir) error
+	uploadDir = func(d *Dir) error {
+		var buf bytes.Buffer
+		err := WriteHTML(&buf, d)
+		if err != nil {
+			return errors.Wrapf(err, "failed to make index of %q", d.Path)
+		}
+		err = upload(ctx, f, path.Join(d.Path, indexName), buf.Bytes())
+		if err != nil {
+			return err
+		}
+		for _, subDir := range d.Dirs {
+			err = uploadDir(subDir)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return uploadDir(root)
+}
+
+// upload uploads data to remote in f
+func upload(ctx context.Context, f fs.Fs, remote string, data []byte) error {
+	if fs.Config.DryRun {
+		fs.Logf(remote, "Not uploading index as --dry-run")
+		return nil
+	}
+	_, err := operations.Rcat(ctx, f, remote, ioutil.NopCloser(bytes.NewReader(data)), time.Now())
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload %q", remote)
+	}
+	fs.Infof(remote, "Uploaded index")
+	return nil
+}
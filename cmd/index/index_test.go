@@ -0,0 +1,85 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+var (
+	t1 = fstest.Time("2017-02-03T04:05:06.499999999Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func TestIndex(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	file1 := r.WriteObject("a.txt", "aaaaa", t1)
+	file2 := r.WriteObject("dir/b c.txt", "bbb", t1)
+	file3 := r.WriteObject("dir/sub/c.txt", "cccccccc", t1)
+	oldIndex := r.WriteObject("dir/index.html", "old index", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, oldIndex)
+
+	root, err := Build(ctx, r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(16), root.Size)
+	assert.Equal(t, int64(3), root.Count)
+	require.Len(t, root.Files, 1)
+	assert.Equal(t, "a.txt", root.Files[0].Name)
+	require.Len(t, root.Dirs, 1)
+	dir := root.Dirs[0]
+	assert.Equal(t, "dir", dir.Path)
+	assert.Equal(t, int64(11), dir.Size)
+	assert.Equal(t, int64(2), dir.Count)
+	require.Len(t, dir.Files, 1, "old index should be left out")
+	assert.Equal(t, "dir/b c.txt", dir.Files[0].Path)
+	require.Len(t, dir.Dirs, 1)
+	assert.Equal(t, "dir/sub", dir.Dirs[0].Path)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteHTML(&buf, dir))
+	html := buf.String()
+	assert.Contains(t, html, "<title>Index of /dir</title>")
+	assert.Contains(t, html, `<a href="../index.json">JSON index</a>`)
+	assert.Contains(t, html, `<a href="../index.html">../</a>`)
+	assert.Contains(t, html, `<a href="sub/index.html">sub/</a>`)
+	assert.Contains(t, html, `<a href="b%20c.txt">b c.txt</a>`)
+
+	require.NoError(t, Upload(ctx, r.Fremote, root))
+	for _, remote := range []string{"index.html", "dir/index.html", "dir/sub/index.html"} {
+		o, err := r.Fremote.NewObject(ctx, remote)
+		require.NoError(t, err, remote)
+		assert.NotEqual(t, int64(len("old index")), o.Size())
+	}
+	o, err := r.Fremote.NewObject(ctx, "index.json")
+	require.NoError(t, err)
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	var got Dir
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, root.Count, got.Count)
+	assert.Equal(t, "dir/sub/c.txt", got.Dirs[0].Dirs[0].Files[0].Path)
+
+	// Indexing again leaves the indexes out
+	root2, err := Build(ctx, r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, root.Count, root2.Count)
+	assert.Equal(t, root.Size, root2.Size)
+}
@@ -106,7 +106,7 @@ func (r *results) Print() {
 func (r *results) writeFile(path string) (fs.Object, error) {
 	contents := fstest.RandomString(50)
 	src := object.NewStaticObjectInfo(path, time.Now(), int64(len(contents)), true, nil, r.f)
-	return r.f.Put(bytes.NewBufferString(contents), src)
+	return r.f.Put(cmd.Context(), bytes.NewBufferString(contents), src)
 }
 
 // check whether normalization is enforced and check whether it is
@@ -120,11 +120,11 @@ func (r *results) checkUTF8Normalization() {
 		return
 	}
 	r.canWriteUnnormalized = true
-	_, err = r.f.NewObject(unnormalized)
+	_, err = r.f.NewObject(cmd.Context(), unnormalized)
 	if err == nil {
 		r.canReadUnnormalized = true
 	}
-	_, err = r.f.NewObject(normalized)
+	_, err = r.f.NewObject(cmd.Context(), normalized)
 	if err == nil {
 		r.canReadRenormalized = true
 	}
@@ -216,7 +216,7 @@ func (r *results) checkStreaming() {
 	in := io.TeeReader(buf, hashIn)
 
 	objIn := object.NewStaticObjectInfo("checkStreamingTest", time.Now(), -1, true, nil, r.f)
-	objR, err := putter(in, objIn)
+	objR, err := putter(cmd.Context(), in, objIn)
 	if err != nil {
 		fs.Infof(r.f, "Streamed file failed to upload (%v)", err)
 		r.canStream = false
@@ -247,7 +247,7 @@ func (r *results) checkStreaming() {
 }
 
 func readInfo(f fs.Fs) error {
-	err := f.Mkdir("")
+	err := f.Mkdir(cmd.Context(), "")
 	if err != nil {
 		return errors.Wrap(err, "couldn't mkdir")
 	}
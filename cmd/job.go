@@ -0,0 +1,236 @@
+// Report the changes to the files since the last run of a job
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
+)
+
+// Flags
+var (
+	jobName         = flags.StringP("job-name", "", "", "Save the listing of the source under this name and report the changes since the last run")
+	jobAlertChanges = flags.Float64P("job-alert-changes", "", 0, "Don't run if more than this percentage of the files of --job-name were modified or deleted since the last run")
+)
+
+// jobFs is the Fs listed for --job-name - the source, or the only
+// remote if there is just one
+var jobFs fs.Fs
+
+// jobFile is a file in the listing saved for a job
+type jobFile struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// jobRecord is what is saved at the end of each run of a job
+type jobRecord struct {
+	Command string                 `json:"command"`
+	Remote  string                 `json:"remote"`
+	Time    time.Time              `json:"time"`
+	Stats   map[string]interface{} `json:"stats"`
+	Files   map[string]jobFile     `json:"files"`
+}
+
+// JobChanges describes the changes to the files of a job since its
+// last run
+type JobChanges struct {
+	Name     string    `json:"name"`
+	LastRun  time.Time `json:"lastRun"`
+	Files    int       `json:"files"`
+	New      int       `json:"new"`
+	Modified int       `json:"modified"`
+	Deleted  int       `json:"deleted"`
+}
+
+// jobRecordFile returns the file the record of the job called name is
+// saved in
+func jobRecordFile(name string) (string, error) {
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", errors.Errorf("invalid --job-name %q", name)
+	}
+	return filepath.Join(config.CacheDir, "jobs", name+".json"), nil
+}
+
+// loadJobRecord loads the record saved by the last run of the job or
+// returns nil if there isn't one
+func loadJobRecord(file string) (*jobRecord, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record jobRecord
+	err = json.Unmarshal(data, &record)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %q", file)
+	}
+	return &record, nil
+}
+
+// save writes the record to file
+func (record *jobRecord) save(file string) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(file), 0700)
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// listJobFiles lists the files in f obeying the filters
+func listJobFiles(f fs.Fs) (map[string]jobFile, error) {
+	var mu sync.Mutex
+	files := make(map[string]jobFile)
+	err := operations.ListFn(Context(), f, func(o fs.Object) {
+		file := jobFile{
+			Size:    o.Size(),
+			ModTime: o.ModTime(),
+		}
+		mu.Lock()
+		files[o.Remote()] = file
+		mu.Unlock()
+	})
+	return files, err
+}
+
+// compareJobFiles works out the changes from the old files to the new
+func compareJobFiles(name string, old, new *jobRecord) *JobChanges {
+	changes := &JobChanges{
+		Name:    name,
+		LastRun: old.Time,
+		Files:   len(new.Files),
+	}
+	for remote, file := range new.Files {
+		oldFile, found := old.Files[remote]
+		switch {
+		case !found:
+			changes.New++
+		case file.Size != oldFile.Size || !file.ModTime.Equal(oldFile.ModTime):
+			changes.Modified++
+		}
+	}
+	for remote := range old.Files {
+		if _, found := new.Files[remote]; !found {
+			changes.Deleted++
+		}
+	}
+	return changes
+}
+
+// alert returns an error if more than percent of the files of the last
+// run were modified or deleted
+func (changes *JobChanges) alert(oldFiles int, percent float64) error {
+	if percent <= 0 || oldFiles == 0 {
+		return nil
+	}
+	changed := changes.Modified + changes.Deleted
+	if float64(changed)*100 > percent*float64(oldFiles) {
+		return errors.Errorf("job %q: %d of %d files modified or deleted since the last run - more than --job-alert-changes %g%%", changes.Name, changed, oldFiles, percent)
+	}
+	return nil
+}
+
+// jobRun is a run of the job named with --job-name
+type jobRun struct {
+	file   string     // where the record is saved
+	record *jobRecord // the listing made at the start of the run
+}
+
+// startJob lists the files of the job named with --job-name before
+// the command transfers anything and reports the changes since the
+// last run.
+//
+// It returns the run to finish when the command is done, or nil if
+// there is no job, and the changes, or nil if there was no last run.
+// If the changes are over the --job-alert-changes limit it returns an
+// error and the command shouldn't be run.
+func startJob(command string) (*jobRun, *JobChanges, error) {
+	if *jobName == "" {
+		return nil, nil, nil
+	}
+	if jobFs == nil {
+		fs.Errorf(nil, "Ignoring --job-name as %q doesn't use a remote", command)
+		return nil, nil, nil
+	}
+	file, err := jobRecordFile(*jobName)
+	if err != nil {
+		fs.Errorf(nil, "%v", err)
+		return nil, nil, nil
+	}
+	old, err := loadJobRecord(file)
+	if err != nil {
+		fs.Errorf(nil, "Ignoring the last run of job %q: %v", *jobName, err)
+	}
+	record := &jobRecord{
+		Command: command,
+		Remote:  jobFs.Name() + ":" + jobFs.Root(),
+		Time:    time.Now(),
+	}
+	record.Files, err = listJobFiles(jobFs)
+	if err != nil {
+		fs.Errorf(nil, "Failed to list files for job %q: %v", *jobName, err)
+		return nil, nil, nil
+	}
+	run := &jobRun{
+		file:   file,
+		record: record,
+	}
+	if old == nil {
+		fs.Logf(nil, "Job %q: first run - %d files", *jobName, len(record.Files))
+		return run, nil, nil
+	}
+	changes := compareJobFiles(*jobName, old, record)
+	fs.Logf(nil, "Job %q: %d new, %d modified, %d deleted of %d files since the last run at %v",
+		*jobName, changes.New, changes.Modified, changes.Deleted, changes.Files, old.Time.Format("2006-01-02 15:04:05"))
+	if err = changes.alert(len(old.Files), *jobAlertChanges); err != nil {
+		fs.CountError(err)
+		fs.Errorf(nil, "%v - not running %q", err, command)
+		// don't save the listing so the next run reports the
+		// changes again
+		return nil, changes, err
+	}
+	return run, changes, nil
+}
+
+// finish saves the listing made at the start of the run for next
+// time if the command succeeded.  It returns err.
+func (run *jobRun) finish(err error) error {
+	if run == nil {
+		return err
+	}
+	switch {
+	case err != nil || accounting.Stats.Errored():
+		fs.Logf(nil, "Not saving the listing for job %q as there were errors", *jobName)
+	case fs.Config.DryRun:
+		fs.Logf(nil, "Not saving the listing for job %q as --dry-run is set", *jobName)
+	default:
+		run.record.Stats = accounting.Stats.RemoteStats()
+		if saveErr := run.record.save(run.file); saveErr != nil {
+			fs.Errorf(nil, "Failed to save the listing for job %q: %v", *jobName, saveErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareJobFiles(t *testing.T) {
+	t1 := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	old := &jobRecord{
+		Time: t1,
+		Files: map[string]jobFile{
+			"same":      {Size: 1, ModTime: t1},
+			"size":      {Size: 1, ModTime: t1},
+			"modtime":   {Size: 1, ModTime: t1},
+			"deleted":   {Size: 1, ModTime: t1},
+			"dir/same":  {Size: 2, ModTime: t1},
+			"dir/gone1": {Size: 2, ModTime: t1},
+		},
+	}
+	new := &jobRecord{
+		Files: map[string]jobFile{
+			"same":     {Size: 1, ModTime: t1},
+			"size":     {Size: 2, ModTime: t1},
+			"modtime":  {Size: 1, ModTime: t2},
+			"new":      {Size: 1, ModTime: t1},
+			"dir/same": {Size: 2, ModTime: t1},
+		},
+	}
+	changes := compareJobFiles("job", old, new)
+	assert.Equal(t, &JobChanges{
+		Name:     "job",
+		LastRun:  t1,
+		Files:    5,
+		New:      1,
+		Modified: 2,
+		Deleted:  2,
+	}, changes)
+
+	// 4 of 6 files changed is 66%
+	assert.NoError(t, changes.alert(6, 0))
+	assert.NoError(t, changes.alert(6, 70))
+	assert.Error(t, changes.alert(6, 60))
+	assert.NoError(t, changes.alert(0, 60))
+}
+
+func TestRunJob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-job")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	files := filepath.Join(dir, "files")
+	require.NoError(t, os.Mkdir(files, 0700))
+	write := func(name, contents string) {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(files, name), []byte(contents), 0600))
+	}
+	write("one", "one")
+	write("two", "two")
+
+	oldCacheDir, oldJobName, oldJobAlertChanges, oldJobFs := config.CacheDir, *jobName, *jobAlertChanges, jobFs
+	defer func() {
+		config.CacheDir, *jobName, *jobAlertChanges, jobFs = oldCacheDir, oldJobName, oldJobAlertChanges, oldJobFs
+	}()
+	config.CacheDir = dir
+	*jobName = "test"
+	*jobAlertChanges = 0
+	jobFs, err = fs.NewFs(files)
+	require.NoError(t, err)
+
+	// run the job with err as the result of the command
+	runJob := func(err error) (*JobChanges, error) {
+		run, changes, jobErr := startJob("sync")
+		if jobErr != nil {
+			assert.Nil(t, run)
+			return changes, jobErr
+		}
+		return changes, run.finish(err)
+	}
+
+	// first run has no changes
+	changes, err := runJob(nil)
+	require.NoError(t, err)
+	assert.Nil(t, changes)
+	_, err = os.Stat(filepath.Join(dir, "jobs", "test.json"))
+	require.NoError(t, err)
+
+	write("two", "two modified")
+	write("three", "three")
+	changes, err = runJob(nil)
+	require.NoError(t, err)
+	require.NotNil(t, changes)
+	assert.Equal(t, 3, changes.Files)
+	assert.Equal(t, 1, changes.New)
+	assert.Equal(t, 1, changes.Modified)
+	assert.Equal(t, 0, changes.Deleted)
+
+	// the listing was saved so there are no changes now
+	changes, err = runJob(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changes.New+changes.Modified+changes.Deleted)
+
+	// too many changes is an error before the command runs and the
+	// listing isn't saved
+	*jobAlertChanges = 50
+	require.NoError(t, os.Remove(filepath.Join(files, "one")))
+	require.NoError(t, os.Remove(filepath.Join(files, "two")))
+	run, changes, err := startJob("sync")
+	require.Error(t, err)
+	assert.Nil(t, run)
+	assert.Equal(t, 2, changes.Deleted)
+	changes, err = runJob(nil)
+	require.Error(t, err)
+	assert.Equal(t, 2, changes.Deleted)
+	accounting.Stats.ResetErrors()
+
+	// not saved if the command failed
+	*jobAlertChanges = 0
+	boom := os.ErrInvalid
+	_, err = runJob(boom)
+	assert.Equal(t, boom, err)
+	changes, err = runJob(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changes.Deleted)
+
+	// the listing is made before the command changes the files
+	run, _, err = startJob("sync")
+	require.NoError(t, err)
+	write("four", "four")
+	require.NoError(t, run.finish(nil))
+	changes, err = runJob(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changes.New)
+}
@@ -4,12 +4,20 @@ import (
 	"fmt"
 
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/spf13/cobra"
 )
 
+var (
+	expire   = fs.DurationOff
+	password = ""
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
+	commandDefintion.Flags().VarP(&expire, "expire", "", "Make the link expire after this long in s or suffix ms|s|m|h|d|w|M|y")
+	commandDefintion.Flags().StringVarP(&password, "password", "", password, "Make the link need this password")
 }
 
 var commandDefintion = &cobra.Command{
@@ -24,13 +32,24 @@ rclone link will create or retrieve a public link to the given file or folder.
 If successful, the last line of the output will contain the link. Exact
 capabilities depend on the remote, but the link will always be created with
 the least constraints – e.g. no expiry, no password protection, accessible
-without account.
+without account - unless asked for otherwise.
+
+Use ` + "`--expire`" + ` to make a link which stops working after a time,
+eg ` + "`--expire 1d`" + `, and ` + "`--password`" + ` to make a link which
+needs a password, eg
+
+    rclone link --expire 1w --password secret dropbox:path/to/file
+
+Only some remotes can make these links, and some only with a paid
+account - rclone will give an error if the remote can't make the link
+asked for rather than making a link without the constraints.  If a link
+already exists then its expiry and password are changed if possible.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc, remote := cmd.NewFsFile(args[0])
 		cmd.Run(false, false, command, func() error {
-			link, err := operations.PublicLink(fsrc, remote)
+			link, err := operations.PublicLink(cmd.Context(), fsrc, remote, expire, password)
 			if err != nil {
 				return err
 			}
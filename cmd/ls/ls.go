@@ -33,7 +33,7 @@ Eg
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			return operations.List(fsrc, os.Stdout)
+			return operations.List(cmd.Context(), fsrc, os.Stdout)
 		})
 	},
 }
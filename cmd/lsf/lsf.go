@@ -63,6 +63,8 @@ output:
     s - size
     t - modification time
     h - hash
+    i - ID of directories, if known
+    c - count of items in directories, -1 if unknown
 
 So if you wanted the path, size and modification time, you would use
 --format "pst", or maybe --format "tsp" to put the path last.
@@ -98,6 +100,11 @@ Eg
 
 (Though "rclone md5sum ." is an easier way of typing this.)
 
+Some remotes, eg OneDrive, say how many items are in each directory
+and how big its contents are when listing it, and "c" and "s" show
+these for directories if so.  "i" shows the ID the remote uses for
+each directory, if it has them.  "i" and "c" are blank for files.
+
 By default the separator is ";" this can be changed with the
 --separator flag.  Note that separators aren't escaped in the path so
 putting it last is a good strategy.
@@ -138,12 +145,16 @@ func Lsf(fsrc fs.Fs, out io.Writer) error {
 			list.AddSize()
 		case 'h':
 			list.AddHash(hashType)
+		case 'i':
+			list.AddID()
+		case 'c':
+			list.AddItems()
 		default:
 			return errors.Errorf("Unknown format character %q", char)
 		}
 	}
 
-	return walk.Walk(fsrc, "", false, operations.ConfigMaxDepth(recurse), func(path string, entries fs.DirEntries, err error) error {
+	return walk.Walk(cmd.Context(), fsrc, "", false, operations.ConfigMaxDepth(recurse), func(path string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(path, "error listing: %v", err)
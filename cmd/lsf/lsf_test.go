@@ -2,6 +2,7 @@ package lsf
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/ncw/rclone/fs"
@@ -138,7 +139,7 @@ file3
 	err = Lsf(f, buf)
 	require.NoError(t, err)
 
-	items, _ := list.DirSorted(f, true, "")
+	items, _ := list.DirSorted(context.Background(), f, true, "")
 	var expectedOutput string
 	for _, item := range items {
 		expectedOutput += item.ModTime().Format("2006-01-02 15:04:05") + "\n"
@@ -199,8 +200,8 @@ func TestWholeLsf(t *testing.T) {
 	err = Lsf(f, buf)
 	require.NoError(t, err)
 
-	items, _ := list.DirSorted(f, true, "")
-	itemsInSubdir, _ := list.DirSorted(f, true, "subdir")
+	items, _ := list.DirSorted(context.Background(), f, true, "")
+	itemsInSubdir, _ := list.DirSorted(context.Background(), f, true, "subdir")
 	var expectedOutput []string
 	for _, item := range items {
 		expectedOutput = append(expectedOutput, item.ModTime().Format("2006-01-02 15:04:05"))
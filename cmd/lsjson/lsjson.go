@@ -12,6 +12,7 @@ import (
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/cmd/ls/lshelp"
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fs/walk"
 	"github.com/pkg/errors"
@@ -23,6 +24,7 @@ var (
 	showHash      bool
 	showEncrypted bool
 	noModTime     bool
+	showAPICalls  bool
 )
 
 func init() {
@@ -31,6 +33,7 @@ func init() {
 	commandDefintion.Flags().BoolVarP(&showHash, "hash", "", false, "Include hashes in the output (may take longer).")
 	commandDefintion.Flags().BoolVarP(&noModTime, "no-modtime", "", false, "Don't read the modification time (can speed things up).")
 	commandDefintion.Flags().BoolVarP(&showEncrypted, "encrypted", "M", false, "Show the encrypted names.")
+	commandDefintion.Flags().BoolVarP(&showAPICalls, "api-calls", "", false, "Write the API calls made to stderr as JSON when finished.")
 }
 
 // lsJSON in the struct which gets marshalled for each line
@@ -42,6 +45,8 @@ type lsJSON struct {
 	ModTime   Timestamp //`json:",omitempty"`
 	IsDir     bool
 	Hashes    map[string]string `json:",omitempty"`
+	ID        string            `json:",omitempty"`
+	Items     *int64            `json:",omitempty"`
 }
 
 // Timestamp a time in RFC3339 format with Nanosecond precision secongs
@@ -83,6 +88,11 @@ If --no-modtime is specified then ModTime will be blank.
 
 If --encrypted is not specified the Encrypted won't be emitted.
 
+Directories have an ID if the remote uses IDs for them, eg Drive, and
+Items, the count of items in them, if the remote says when listing,
+eg OneDrive.  The Size of a directory is -1 unless the remote says
+how big its contents are.
+
 The Path field will only show folders below the remote path being listed.
 If "remote:path" contains the file "subfolder/file.txt", the Path for "file.txt"
 will be "subfolder/file.txt", not "remote:path/subfolder/file.txt".
@@ -90,6 +100,13 @@ When used without --recursive the Path will always be the same as Name.
 
 The time is in RFC3339 format with nanosecond precision.
 
+If --api-calls is specified then when the listing is finished a JSON
+object with the number of API calls made to each remote, and their
+estimated cost if an api_cost is set for the remote, is written to
+stderr, eg
+
+    {"apiCalls":{"s3":{"delete":0,"get":0,"list":12,"put":0}},"apiCost":0.00006}
+
 The whole output can be processed as a JSON blob, or alternatively it
 can be processed line by line as each item is written one to a line.
 ` + lshelp.Help,
@@ -113,7 +130,7 @@ can be processed line by line as each item is written one to a line.
 		cmd.Run(false, false, command, func() error {
 			fmt.Println("[")
 			first := true
-			err := walk.Walk(fsrc, "", false, operations.ConfigMaxDepth(recurse), func(dirPath string, entries fs.DirEntries, err error) error {
+			err := walk.Walk(cmd.Context(), fsrc, "", false, operations.ConfigMaxDepth(recurse), func(dirPath string, entries fs.DirEntries, err error) error {
 				if err != nil {
 					fs.CountError(err)
 					fs.Errorf(dirPath, "error listing: %v", err)
@@ -141,6 +158,10 @@ can be processed line by line as each item is written one to a line.
 					switch x := entry.(type) {
 					case fs.Directory:
 						item.IsDir = true
+						item.ID = x.ID()
+						if items := x.Items(); items >= 0 {
+							item.Items = &items
+						}
 					case fs.Object:
 						item.IsDir = false
 						if showHash {
@@ -181,6 +202,13 @@ can be processed line by line as each item is written one to a line.
 				fmt.Println()
 			}
 			fmt.Println("]")
+			if showAPICalls {
+				out, err := json.Marshal(accounting.Stats.APIStats())
+				if err != nil {
+					return errors.Wrap(err, "failed to marshal API calls")
+				}
+				fmt.Fprintln(os.Stderr, string(out))
+			}
 			return nil
 		})
 	},
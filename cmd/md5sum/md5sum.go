@@ -23,7 +23,7 @@ is in the same format as the standard md5sum tool produces.
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			return operations.Md5sum(fsrc, os.Stdout)
+			return operations.Md5sum(cmd.Context(), fsrc, os.Stdout)
 		})
 	},
 }
@@ -22,7 +22,7 @@ var commandDefintion = &cobra.Command{
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			objects, _, err := operations.Count(fsrc)
+			objects, _, err := operations.Count(cmd.Context(), fsrc)
 			if err != nil {
 				return err
 			}
@@ -31,7 +31,7 @@ var commandDefintion = &cobra.Command{
 			runtime.GC()
 			runtime.ReadMemStats(&before)
 			var mu sync.Mutex
-			err = operations.ListFn(fsrc, func(o fs.Object) {
+			err = operations.ListFn(cmd.Context(), fsrc, func(o fs.Object) {
 				mu.Lock()
 				objs = append(objs, o)
 				mu.Unlock()
@@ -1,23 +1,144 @@
 package mkdir
 
 import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"time"
+
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fspath"
 	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var (
+	modTimeString string
+	jsonOutput    bool
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
+	flags := commandDefintion.Flags()
+	flags.StringVarP(&modTimeString, "modtime", "", "", "Set the modification time of the directory in RFC3339 format, eg 2006-01-02T15:04:05Z")
+	flags.BoolVarP(&jsonOutput, "json", "", false, "Print the path and ID of the directory as JSON")
 }
 
 var commandDefintion = &cobra.Command{
 	Use:   "mkdir remote:path",
 	Short: `Make the path if it doesn't already exist.`,
+	Long: `
+Make the directory remote:path if it doesn't already exist.  Any
+parent directories which don't exist are made too, like mkdir -p.
+
+Use --modtime to set the modification time of the directory.  Not all
+remotes can do this - it is an error to use it on one which can't.
+Those which can include local, drive and onedrive.
+
+Use --json to print the directory made as JSON, eg
+
+    {"Path":"a/b","Name":"b","ID":"0B3Vd...","ModTime":"2006-01-02T15:04:05Z"}
+
+The ID is only printed for remotes which identify directories by ID,
+such as drive, and ModTime only if the remote has directory
+modification times.
+`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
+		var modTime time.Time
+		if modTimeString != "" {
+			var err error
+			modTime, err = time.Parse(time.RFC3339Nano, modTimeString)
+			if err != nil {
+				log.Fatalf("Failed to parse --modtime: %v", err)
+			}
+		}
 		fdst := cmd.NewFsDst(args)
 		cmd.Run(true, false, command, func() error {
-			return operations.Mkdir(fdst, "")
+			ctx := cmd.Context()
+			err := Mkdir(ctx, fdst, modTime)
+			if err != nil || !jsonOutput || fs.Config.DryRun {
+				return err
+			}
+			info, err := Info(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return json.NewEncoder(os.Stdout).Encode(info)
 		})
 	},
 }
+
+// Mkdir makes the root of f and any parents needed, setting its
+// modification time if modTime isn't zero
+func Mkdir(ctx context.Context, f fs.Fs, modTime time.Time) error {
+	err := operations.Mkdir(ctx, f, "")
+	if err != nil || modTime.IsZero() || fs.Config.DryRun {
+		return err
+	}
+	do := f.Features().DirSetModTime
+	if do == nil {
+		return errors.Errorf("%v can't set the modification time of directories", f)
+	}
+	err = do(ctx, "", modTime)
+	if err != nil {
+		return errors.Wrap(err, "failed to set modification time")
+	}
+	return nil
+}
+
+// DirInfo describes a directory made by mkdir
+type DirInfo struct {
+	Path    string
+	Name    string
+	ID      string     `json:",omitempty"`
+	ModTime *time.Time `json:",omitempty"`
+}
+
+// Info reads the description of the directory remote:path from its
+// parent directory
+func Info(ctx context.Context, remotePath string) (*DirInfo, error) {
+	if trimmed := strings.TrimRight(remotePath, "/"); trimmed != "" && !strings.HasSuffix(trimmed, ":") {
+		remotePath = trimmed
+	}
+	parent, leaf := fspath.RemoteSplit(remotePath)
+	if parent == "" {
+		parent = "."
+	}
+	_, _, dirPath, err := fs.ParseRemote(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	info := &DirInfo{
+		Path: dirPath,
+		Name: leaf,
+	}
+	if leaf == "" {
+		// the root of the remote has no parent to list
+		return info, nil
+	}
+	fparent, err := fs.NewFs(parent)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := fparent.List(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list parent directory")
+	}
+	for _, entry := range entries {
+		dir, ok := entry.(fs.Directory)
+		if !ok || dir.Remote() != leaf {
+			continue
+		}
+		info.ID = dir.ID()
+		if modTime := dir.ModTime(); !modTime.IsZero() && fparent.Features().DirSetModTime != nil {
+			info.ModTime = &modTime
+		}
+		return info, nil
+	}
+	return nil, errors.Errorf("couldn't find %q after making it", remotePath)
+}
@@ -0,0 +1,51 @@
+package mkdir
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+func TestMkdir(t *testing.T) {
+	fstest.Initialise()
+	dir, err := ioutil.TempDir("", "rclone-mkdir")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	ctx := context.Background()
+	remote := filepath.Join(dir, "a", "b")
+	f, err := fs.NewFs(remote)
+	require.NoError(t, err)
+
+	modTime := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	require.NoError(t, Mkdir(ctx, f, modTime))
+	fi, err := os.Stat(remote)
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+	assert.True(t, modTime.Equal(fi.ModTime()), "want %v got %v", modTime, fi.ModTime())
+
+	// making it again is fine
+	require.NoError(t, Mkdir(ctx, f, time.Time{}))
+
+	info, err := Info(ctx, remote+"/")
+	require.NoError(t, err)
+	assert.Equal(t, remote, info.Path)
+	assert.Equal(t, "b", info.Name)
+	assert.Equal(t, "", info.ID)
+	require.NotNil(t, info.ModTime)
+	assert.True(t, modTime.Equal(*info.ModTime))
+
+	_, err = Info(ctx, filepath.Join(dir, "potato"))
+	assert.Error(t, err)
+}
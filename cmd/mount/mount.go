@@ -13,9 +13,9 @@ import (
 	fusefs "bazil.org/fuse/fs"
 	"github.com/ncw/rclone/cmd/mountlib"
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/lib/service"
 	"github.com/ncw/rclone/vfs"
 	"github.com/ncw/rclone/vfs/vfsflags"
-	"github.com/okzk/sdnotify"
 	"github.com/pkg/errors"
 )
 
@@ -129,8 +129,8 @@ func Mount(f fs.Fs, mountpoint string) error {
 	sigHup := make(chan os.Signal, 1)
 	signal.Notify(sigHup, syscall.SIGHUP)
 
-	if err := sdnotify.SdNotifyReady(); err != nil && err != sdnotify.SdNotifyNoSocket {
-		return errors.Wrap(err, "failed to notify systemd")
+	if err := service.Ready(); err != nil {
+		return err
 	}
 
 waitloop:
@@ -143,6 +143,10 @@ waitloop:
 		case <-sigInt:
 			err = unmount()
 			break waitloop
+		// Service manager asked to stop: umount
+		case <-service.Stop():
+			err = unmount()
+			break waitloop
 		// user sent SIGHUP to clear the cache
 		case <-sigHup:
 			root, err := FS.Root()
@@ -154,7 +158,7 @@ waitloop:
 		}
 	}
 
-	_ = sdnotify.SdNotifyStopping()
+	service.Stopping()
 	if err != nil {
 		return errors.Wrap(err, "failed to umount FUSE fs")
 	}
@@ -189,6 +189,15 @@ to use Type=notify. In this case the service will enter the started state
 after the mountpoint has been successfully set up.
 Units having the rclone ` + commandName + ` service specified as a requirement
 will see all files and folders immediately in this mode.
+
+### Windows service
+
+rclone ` + commandName + ` can be run as a Windows service, eg using
+
+    sc create rclone-mount start= auto binPath= "C:\rclone\rclone.exe mount remote: X: --config C:\rclone\rclone.conf --log-file C:\rclone\mount.log"
+
+It tells the service control manager when the mount is ready and
+unmounts cleanly when the service is stopped.
 ` + vfs.Help,
 		Run: func(command *cobra.Command, args []string) {
 			cmd.CheckArgs(2, 2, command, args)
@@ -9,11 +9,13 @@ import (
 // Globals
 var (
 	deleteEmptySrcDirs = false
+	createEmptySrcDirs = false
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	commandDefintion.Flags().BoolVarP(&deleteEmptySrcDirs, "delete-empty-src-dirs", "", deleteEmptySrcDirs, "Delete empty source dirs after move")
+	commandDefintion.Flags().BoolVarP(&createEmptySrcDirs, "create-empty-src-dirs", "", createEmptySrcDirs, "Create empty source dirs on destination after move")
 }
 
 var commandDefintion = &cobra.Command{
@@ -36,6 +38,9 @@ into ` + "`dest:path`" + ` then delete the original (if no errors on copy) in
 
 If you want to delete empty source directories after move, use the --delete-empty-src-dirs flag.
 
+Empty directories in the source are not normally moved.  Use the
+--create-empty-src-dirs flag to create them on the destination too.
+
 **Important**: Since this can cause data loss, test first with the
 --dry-run flag.
 `,
@@ -44,7 +49,7 @@ If you want to delete empty source directories after move, use the --delete-empt
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(true, true, command, func() error {
 
-			return sync.MoveDir(fdst, fsrc, deleteEmptySrcDirs)
+			return sync.MoveDir(cmd.Context(), fdst, fsrc, deleteEmptySrcDirs, createEmptySrcDirs)
 		})
 	},
 }
@@ -41,18 +41,32 @@ This doesn't transfer unchanged files, testing by size and
 modification time or MD5SUM.  src will be deleted on successful
 transfer.
 
+Either src or dst can be ` + "`-`" + ` for standard input or output, or
+the path of a local named pipe, as with ` + "`rclone copyto`" + `.  A
+file moved to a stream is deleted once it has been copied.
+
+On remotes which are case insensitive, changing just the case of a
+file name, eg
+
+    rclone moveto remote:A.txt remote:a.txt
+
+is done by moving the file to a temporary name first.
+
 **Important**: Since this can cause data loss, test first with the
 --dry-run flag.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
+		if cmd.RunStream(command, args, true) {
+			return
+		}
 		fsrc, srcFileName, fdst, dstFileName := cmd.NewFsSrcDstFiles(args)
 
 		cmd.Run(true, true, command, func() error {
 			if srcFileName == "" {
-				return sync.MoveDir(fdst, fsrc, false)
+				return sync.MoveDir(cmd.Context(), fdst, fsrc, false, false)
 			}
-			return operations.MoveFile(fdst, fsrc, dstFileName, srcFileName)
+			return operations.MoveFile(cmd.Context(), fdst, fsrc, dstFileName, srcFileName)
 		})
 	},
 }
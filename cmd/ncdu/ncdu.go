@@ -36,7 +36,9 @@ all my disk space?".
 To make the user interface it first scans the entire remote given and
 builds an in memory representation.  rclone ncdu can be used during
 this scanning phase and you will see it building up the directory
-structure as it goes along.
+structure as it goes along.  Directories which haven't been read yet
+show the size and count of items the remote gave when listing their
+parent, if it gives them, eg OneDrive.
 
 Here are the keys - press '?' to toggle the help on and off
 
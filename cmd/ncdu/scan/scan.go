@@ -2,6 +2,7 @@
 package scan
 
 import (
+	"context"
 	"path"
 	"sync"
 
@@ -116,7 +117,17 @@ func (d *Dir) AttrI(i int) (size int64, count int64, isDir bool, readable bool)
 		return d.entries[i].Size(), 0, false, true
 	}
 	if subDir == nil {
-		return 0, 0, true, false
+		// Use the size and count the remote gave when listing
+		// if known until the directory is read
+		dir := d.entries[i].(fs.Directory)
+		size, count = dir.Size(), dir.Items()
+		if size < 0 {
+			size = 0
+		}
+		if count < 0 {
+			count = 0
+		}
+		return size, count, true, false
 	}
 	size, count = subDir.Attr()
 	return size, count, true, true
@@ -130,7 +141,7 @@ func Scan(f fs.Fs) (chan *Dir, chan error, chan struct{}) {
 	updated := make(chan struct{}, 1)
 	go func() {
 		parents := map[string]*Dir{}
-		err := walk.Walk(f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+		err := walk.Walk(context.Background(), f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 			if err != nil {
 				return err // FIXME mark directory as errored instead of aborting
 			}
@@ -0,0 +1,121 @@
+// Notify other programs when a command finishes
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/fshttp"
+	"github.com/pkg/errors"
+)
+
+// Flags
+var (
+	onSuccessCmd = flags.StringP("on-success-cmd", "", "", "Run this shell command if the command succeeds")
+	onFailureCmd = flags.StringP("on-failure-cmd", "", "", "Run this shell command if the command fails")
+	notifyURL    = flags.StringP("notify-url", "", "", "POST a JSON summary of the run to this URL when the command finishes")
+)
+
+// Summary describes how a command went - it is sent to the
+// notification hooks
+type Summary struct {
+	Command string                 `json:"command"`
+	Success bool                   `json:"success"`
+	Error   string                 `json:"error,omitempty"`
+	Stats   map[string]interface{} `json:"stats"`
+	Job     *JobChanges            `json:"job,omitempty"`
+}
+
+// newSummary makes the Summary of a command which returned err
+func newSummary(command string, err error) *Summary {
+	if err == nil && accounting.Stats.Errored() {
+		err = accounting.Stats.GetLastError()
+		if err == nil {
+			err = errors.Errorf("%d errors", accounting.Stats.GetErrors())
+		}
+	}
+	s := &Summary{
+		Command: command,
+		Success: err == nil,
+		Stats:   accounting.Stats.RemoteStats(),
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	return s
+}
+
+// runHook runs command with the shell passing the summary as JSON on
+// its standard input and the result in the environment
+func runHook(command string, summary *Summary, body []byte) error {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("sh", "-c", command)
+	}
+	status := "success"
+	if !summary.Success {
+		status = "failure"
+	}
+	c.Env = append(os.Environ(), "RCLONE_STATUS="+status, "RCLONE_ERROR="+summary.Error)
+	c.Stdin = bytes.NewReader(body)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// postSummary POSTs the summary as JSON to url
+func postSummary(url string, body []byte) error {
+	resp, err := fshttp.NewClient(fs.Config).Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("HTTP error %v", resp.Status)
+	}
+	return nil
+}
+
+// notify runs the --on-success-cmd or --on-failure-cmd and POSTs to
+// the --notify-url if set.  jobChanges are the changes found for
+// --job-name if any.  Any errors are logged.
+func notify(command string, err error, jobChanges *JobChanges) {
+	hook := *onSuccessCmd
+	summary := newSummary(command, err)
+	summary.Job = jobChanges
+	if !summary.Success {
+		hook = *onFailureCmd
+	}
+	if hook == "" && *notifyURL == "" {
+		return
+	}
+	body, jsonErr := json.Marshal(summary)
+	if jsonErr != nil {
+		fs.Errorf(nil, "Failed to make notification: %v", jsonErr)
+		return
+	}
+	if hook != "" {
+		fs.Debugf(nil, "Running notification command %q", hook)
+		if hookErr := runHook(hook, summary, body); hookErr != nil {
+			fs.Errorf(nil, "Notification command failed: %v", hookErr)
+		}
+	}
+	if *notifyURL != "" {
+		fs.Debugf(nil, "Sending notification to %q", *notifyURL)
+		if postErr := postSummary(*notifyURL, body); postErr != nil {
+			fs.Errorf(nil, "Failed to send notification: %v", postErr)
+		}
+	}
+}
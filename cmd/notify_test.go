@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotify(t *testing.T) {
+	var got Summary
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		got = Summary{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "rclone-notify")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	out := filepath.Join(dir, "out")
+
+	oldURL, oldSuccess, oldFailure := *notifyURL, *onSuccessCmd, *onFailureCmd
+	defer func() { *notifyURL, *onSuccessCmd, *onFailureCmd = oldURL, oldSuccess, oldFailure }()
+	*notifyURL = ts.URL
+	*onSuccessCmd = "echo success > " + out
+	*onFailureCmd = "echo failure > " + out
+	if runtime.GOOS != "windows" {
+		*onFailureCmd = `echo $RCLONE_STATUS $RCLONE_ERROR > ` + out
+	}
+
+	notify("potato", errors.New("boom"), nil)
+	assert.Equal(t, "potato", got.Command)
+	assert.False(t, got.Success)
+	assert.Equal(t, "boom", got.Error)
+	assert.Contains(t, got.Stats, "bytes")
+	data, err := ioutil.ReadFile(out)
+	require.NoError(t, err)
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, "failure boom\n", string(data))
+	}
+
+	notify("potato", nil, nil)
+	assert.True(t, got.Success)
+	assert.Equal(t, "", got.Error)
+	data, err = ioutil.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "success")
+}
@@ -36,6 +36,10 @@ must fit into RAM. The cutoff needs to be small enough to adhere
 the limits of your remote, please see there. Generally speaking,
 setting this cutoff too high will decrease your performance.
 
+If the remote can't do streaming uploads then files bigger than the
+cutoff are spooled to local disk first, unless ` + "`--no-spool`" + ` is
+set in which case rcat fails instead.
+
 Note that the upload can also not be retried because the data is
 not kept around until the upload succeeds. If you need to transfer
 a lot of data, you're better off caching locally and then
@@ -50,7 +54,7 @@ a lot of data, you're better off caching locally and then
 
 		fdst, dstFileName := cmd.NewFsDstFile(args)
 		cmd.Run(false, false, command, func() error {
-			_, err := operations.Rcat(fdst, dstFileName, os.Stdin, time.Now())
+			_, err := operations.Rcat(cmd.Context(), fdst, dstFileName, os.Stdin, time.Now())
 			return err
 		})
 	},
@@ -0,0 +1,254 @@
+// Package rename implements the "rclone rename" command which
+// renames files by rewriting their names with regular expressions
+package rename
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fs/walk"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rewriteFlags []string
+	fullPath     bool
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().StringArrayVarP(&rewriteFlags, "rewrite", "", nil, "Rewrite names with s/regexp/replacement/ - can be repeated")
+	commandDefinition.Flags().BoolVarP(&fullPath, "full-path", "", false, "Rewrite the path relative to remote:path rather than just the file name")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "rename remote:path",
+	Short: `Rename files under a path by rewriting their names.`,
+	Long: `
+rclone rename renames each file under remote:path by rewriting its
+name with the regular expressions given with --rewrite.  The files are
+moved server side where the remote supports it.
+
+Each --rewrite is written like a sed substitution, s/regexp/replacement/,
+where any character can be used instead of / as long as it is used
+for all three.  The regular expressions use the Go syntax and the
+replacement can refer to submatches with $1 or ${1} and to named
+submatches with ${name}.  Every match in the name is replaced.  If
+--rewrite is repeated the rewrites are applied in order, eg
+
+    rclone rename --rewrite 's/\.JPE?G$/.jpg/' --rewrite 's/^IMG_(\d+)/photo-$1/' remote:photos
+
+By default only the file name is rewritten so files stay in the same
+directory.  With --full-path the path relative to remote:path is
+rewritten instead which means files can be moved into other
+directories, eg
+
+    rclone rename --full-path --rewrite 's:^(\d{4})-(\d\d)-\d\d_:$1/$2/:' remote:photos
+
+The filter flags can be used to choose which files are renamed.
+
+Nothing is renamed if two files would get the same name or if a file
+would be moved outside remote:path.  A file isn't renamed if a file
+with the new name exists already, unless that file is being renamed
+too, so renames like a to b and b to c can be done together.
+
+Use --dry-run to see what would be renamed without changing anything.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		fsrc := cmd.NewFsSrc(args)
+		var rewrites []*Rewrite
+		for _, s := range rewriteFlags {
+			rewrite, err := ParseRewrite(s)
+			if err != nil {
+				log.Fatalf("Failed to parse --rewrite: %v", err)
+			}
+			rewrites = append(rewrites, rewrite)
+		}
+		if len(rewrites) == 0 {
+			log.Fatalf("Need at least one --rewrite")
+		}
+		// Renaming again would rewrite the names of the files
+		// already renamed so don't retry
+		cmd.Run(false, false, command, func() error {
+			return Rename(cmd.Context(), fsrc, rewrites, fullPath)
+		})
+	},
+}
+
+// Rewrite is a regular expression and the replacement for its matches
+type Rewrite struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// ParseRewrite parses a rewrite written as s/regexp/replacement/
+// where / can be any character.
+func ParseRewrite(s string) (*Rewrite, error) {
+	if len(s) < 4 || s[0] != 's' {
+		return nil, errors.Errorf("%q should look like s/regexp/replacement/", s)
+	}
+	delim := s[1:2]
+	parts := strings.Split(s[2:], delim)
+	if len(parts) != 3 || parts[2] != "" {
+		return nil, errors.Errorf("%q should have exactly 3 %q in it", s, delim)
+	}
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad regexp in %q", s)
+	}
+	return &Rewrite{re: re, replacement: parts[1]}, nil
+}
+
+// Apply returns name with the rewrite applied
+func (r *Rewrite) Apply(name string) string {
+	return r.re.ReplaceAllString(name, r.replacement)
+}
+
+// newName returns the remote that remote should be renamed to
+func newName(remote string, rewrites []*Rewrite, fullPath bool) string {
+	dir, name := "", remote
+	if !fullPath {
+		dir, name = path.Split(remote)
+	}
+	for _, rewrite := range rewrites {
+		name = rewrite.Apply(name)
+	}
+	return strings.TrimLeft(path.Join(dir, name), "/")
+}
+
+// rename is a file to rename
+type rename struct {
+	from string // where the file is now
+	to   string // where it is going
+}
+
+// Rename renames every file under f by applying the rewrites to its
+// name, or the whole of its path if fullPath is set.
+//
+// The renames are done in an order which moves files out of the way
+// of the files being renamed to their names, so a->b and b->c work
+// together.  Cycles, like a->b and b->a, are broken by moving one of
+// the files to a temporary name first.
+func Rename(ctx context.Context, f fs.Fs, rewrites []*Rewrite, fullPath bool) error {
+	// Work out the new names first so clashes can be found
+	var (
+		todo     []*rename
+		newNames = make(map[string]string)
+		clash    error
+	)
+	err := walk.Walk(ctx, f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			return err
+		}
+		entries.ForObject(func(o fs.Object) {
+			newRemote := newName(o.Remote(), rewrites, fullPath)
+			if newRemote == "" || newRemote == "." {
+				clash = errors.Errorf("%q would be renamed to an empty name", o.Remote())
+				return
+			}
+			if newRemote == ".." || strings.HasPrefix(newRemote, "../") {
+				clash = errors.Errorf("%q would be renamed to %q outside %v", o.Remote(), newRemote, f)
+				return
+			}
+			if newRemote == o.Remote() {
+				return
+			}
+			if other, ok := newNames[newRemote]; ok {
+				clash = errors.Errorf("%q and %q would both be renamed to %q", other, o.Remote(), newRemote)
+				return
+			}
+			newNames[newRemote] = o.Remote()
+			todo = append(todo, &rename{from: o.Remote(), to: newRemote})
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if clash != nil {
+		return errors.Wrap(clash, "not renaming anything")
+	}
+
+	var (
+		errorCount int
+		pending    = make(map[string]bool, len(todo)) // files still to be renamed
+		vacated    = make(map[string]bool)            // names renamed away from
+	)
+	for _, r := range todo {
+		pending[r.from] = true
+	}
+	move := func(from, to string) error {
+		delete(pending, from)
+		if fs.Config.DryRun {
+			fs.Logf(from, "Not renaming to %q as --dry-run", to)
+		} else {
+			err := operations.MoveFile(ctx, f, f, to, from)
+			if err != nil {
+				return err
+			}
+		}
+		vacated[from] = true
+		return nil
+	}
+	for len(todo) > 0 {
+		var blocked []*rename
+		for _, r := range todo {
+			if pending[r.to] {
+				// wait for the file with the new name to move
+				blocked = append(blocked, r)
+				continue
+			}
+			caseOnly := f.Features().CaseInsensitive && strings.EqualFold(r.to, r.from)
+			if !caseOnly && !vacated[r.to] {
+				_, err := f.NewObject(ctx, r.to)
+				if err == nil {
+					fs.Errorf(r.from, "Not renaming to %q as it exists already", r.to)
+					delete(pending, r.from)
+					errorCount++
+					continue
+				}
+			}
+			err := move(r.from, r.to)
+			if err != nil {
+				fs.Errorf(r.from, "Failed to rename to %q: %v", r.to, err)
+				errorCount++
+				continue
+			}
+			if !fs.Config.DryRun {
+				fs.Infof(r.from, "Renamed to %q", r.to)
+			}
+		}
+		if len(blocked) > 0 && len(blocked) == len(todo) {
+			// Only cycles are left so break one by moving a
+			// file to a temporary name
+			r := blocked[0]
+			tmp := fmt.Sprintf("%s.rclone-rename-%08x", r.from, rand.Uint32())
+			err := move(r.from, tmp)
+			if err != nil {
+				fs.Errorf(r.from, "Failed to rename to temporary name %q: %v", tmp, err)
+				errorCount++
+				blocked = blocked[1:]
+			} else {
+				r.from = tmp
+				pending[tmp] = true
+			}
+		}
+		todo = blocked
+	}
+	if errorCount > 0 {
+		// Retrying would rename the files already renamed again
+		return fserrors.NoRetryError(errors.Errorf("failed to rename %d files", errorCount))
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package rename
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+var (
+	t1 = fstest.Time("2017-02-03T04:05:06.499999999Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func parseRewrites(t *testing.T, in ...string) (rewrites []*Rewrite) {
+	for _, s := range in {
+		rewrite, err := ParseRewrite(s)
+		require.NoError(t, err, s)
+		rewrites = append(rewrites, rewrite)
+	}
+	return rewrites
+}
+
+func TestParseRewrite(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		ok   bool
+		name string
+		want string
+	}{
+		{`s/\.JPG$/.jpg/`, true, "a.JPG", "a.jpg"},
+		{`s:(\d+)-(\d+):$2-$1:`, true, "12-34.txt", "34-12.txt"},
+		{`s/(?P<n>\d+)/[${n}]/`, true, "a1b22", "a[1]b[22]"},
+		{`s/a/b`, false, "", ""},
+		{`s/a/b/c/`, false, "", ""},
+		{`x/a/b/`, false, "", ""},
+		{`s/(/b/`, false, "", ""},
+	} {
+		rewrite, err := ParseRewrite(test.in)
+		if !test.ok {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, rewrite.Apply(test.name), test.in)
+	}
+}
+
+func TestNewName(t *testing.T) {
+	rewrites := parseRewrites(t, `s/^IMG_/photo-/`, `s/\.JPG$/.jpg/`)
+	assert.Equal(t, "dir/photo-1.jpg", newName("dir/IMG_1.JPG", rewrites, false))
+	assert.Equal(t, "dir/IMG_1.jpg", newName("dir/IMG_1.JPG", rewrites, true))
+	rewrites = parseRewrites(t, `s:^(\d{4})-:$1/:`)
+	assert.Equal(t, "2019/a.txt", newName("2019-a.txt", rewrites, true))
+}
+
+func TestRename(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	file1 := r.WriteObject("dir/IMG_1.JPG", "one", t1)
+	file2 := r.WriteObject("IMG_2.JPG", "two", t1)
+	file3 := r.WriteObject("notes.txt", "three", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	rewrites := parseRewrites(t, `s/^IMG_/photo-/`, `s/\.JPG$/.jpg/`)
+
+	// dry run changes nothing
+	fs.Config.DryRun = true
+	err := Rename(ctx, r.Fremote, rewrites, false)
+	fs.Config.DryRun = false
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	err = Rename(ctx, r.Fremote, rewrites, false)
+	require.NoError(t, err)
+	file1.Path = "dir/photo-1.jpg"
+	file2.Path = "photo-2.jpg"
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	// clashing names renames nothing
+	rewrites = parseRewrites(t, `s/^.*$/same/`)
+	err = Rename(ctx, r.Fremote, rewrites, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "would both be renamed")
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	// existing files aren't overwritten
+	rewrites = parseRewrites(t, `s/^photo-2\.jpg$/notes.txt/`)
+	err = Rename(ctx, r.Fremote, rewrites, true)
+	require.Error(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	// files can't be moved outside the root
+	rewrites = parseRewrites(t, `s:^dir/:dir/../../:`)
+	err = Rename(ctx, r.Fremote, rewrites, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "outside")
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	// chained renames are done in order
+	rewrites = parseRewrites(t, `s/^notes\.txt$/photo-3.jpg/`, `s/^photo-2\.jpg$/notes.txt/`)
+	err = Rename(ctx, r.Fremote, rewrites, true)
+	require.NoError(t, err)
+	file2.Path = "notes.txt"
+	file3.Path = "photo-3.jpg"
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	// and so are cycles
+	rewrites = parseRewrites(t, `s/^notes\.txt$/tmp/`, `s/^photo-3\.jpg$/notes.txt/`, `s/^tmp$/photo-3.jpg/`)
+	err = Rename(ctx, r.Fremote, rewrites, true)
+	require.NoError(t, err)
+	file2.Path = "photo-3.jpg"
+	file3.Path = "notes.txt"
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+}
+
+func TestRenamePartialFailure(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	file1 := r.WriteObject("a.txt", "one", t1)
+	file2 := r.WriteObject("b.txt", "two", t1)
+	file3 := r.WriteObject("keep.log", "three", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+
+	// b.txt clashes with keep.log so only a.txt is renamed and
+	// the error says not to retry as that would rename it again
+	rewrites := parseRewrites(t, `s/^b\.txt$/keep.log/`, `s/\.txt$/-1.txt/`)
+	err := Rename(ctx, r.Fremote, rewrites, false)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsNoRetryError(err))
+	file1.Path = "a-1.txt"
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+}
@@ -0,0 +1,147 @@
+package restore
+
+import (
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/lib/version"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Globals
+var (
+	at = ""
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefintion)
+	commandDefintion.Flags().StringVarP(&at, "at", "", at, "Restore the versions current at this time or age, eg 2018-01-02 15:04:05 or 3d")
+}
+
+// timeFormats are the formats accepted by --at
+var timeFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseAt parses s as a time in one of timeFormats or as an age
+// before now
+func parseAt(s string) (time.Time, error) {
+	for _, format := range timeFormats {
+		t, err := time.ParseInLocation(format, s, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	age, err := fs.ParseDuration(s)
+	if err != nil {
+		return time.Time{}, errors.Errorf("couldn't parse %q as a time or an age", s)
+	}
+	return time.Now().Add(-age), nil
+}
+
+var commandDefintion = &cobra.Command{
+	Use:   "restore remote:path",
+	Short: `Restore old versions of objects on remotes which keep them.`,
+	Long: `
+rclone restore makes old versions of objects the current versions on
+remotes which keep old versions, such as B2 and S3 buckets with
+versioning enabled.  The existing versions are left in place, so a
+restore can itself be undone.
+
+To restore a single old version, name it with its version string as
+shown by ` + "`rclone --versions ls`" + `
+
+    rclone restore b2:bucket/path/one-v2016-07-04-141003-000.txt
+
+To restore everything under a path to how it was at a point in time
+use the --at flag with a time or an age, eg
+
+    rclone restore b2:bucket/path --at "2018-01-02 15:04:05"
+    rclone restore s3:bucket/path --at 3d
+
+Objects which didn't exist at that time are left alone.  This obeys
+any filters, so you can restore just some of the objects.
+
+With the --trash flag it moves files out of the trash on remotes with
+a trash, such as Google Drive, back to the directories they were
+deleted from, eg
+
+    rclone restore --trash drive:path/file.txt
+    rclone restore --trash drive:path
+
+Use ` + "`rclone --trash ls`" + ` to see what is in the trash.
+
+Use --dry-run to see what would be restored.  Run with -v to see a
+line for each object restored.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		if fs.Config.Trash {
+			restoreTrash(command, args[0])
+			return
+		}
+		// Old versions need to be listed to find them
+		fs.Config.Versions = true
+		fsrc, fileName := cmd.NewFsFile(args[0])
+		cmd.Run(true, false, command, func() error {
+			if fileName != "" {
+				if !version.Match(fileName) {
+					return errors.Errorf("%q isn't an old version - use --versions to list them", fileName)
+				}
+				o, err := fsrc.NewObject(cmd.Context(), fileName)
+				if err != nil {
+					return err
+				}
+				do, ok := o.(fs.VersionRestorer)
+				if !ok {
+					return errors.Errorf("%v doesn't support restoring old versions", fsrc)
+				}
+				if fs.Config.DryRun {
+					fs.Logf(o, "Not restoring as --dry-run")
+					return nil
+				}
+				return do.RestoreVersion(cmd.Context())
+			}
+			if at == "" {
+				return errors.New("need --at to restore a path")
+			}
+			t, err := parseAt(at)
+			if err != nil {
+				return err
+			}
+			return operations.RestoreVersions(cmd.Context(), fsrc, t)
+		})
+	},
+}
+
+// restoreTrash moves the objects in the trash at remote back to where
+// they were deleted from
+func restoreTrash(command *cobra.Command, remote string) {
+	fsrc, fileName := cmd.NewFsFile(remote)
+	cmd.Run(true, false, command, func() error {
+		if at != "" {
+			return errors.New("can't use --at with --trash")
+		}
+		if fileName == "" {
+			return operations.RestoreTrash(cmd.Context(), fsrc)
+		}
+		o, err := fsrc.NewObject(cmd.Context(), fileName)
+		if err != nil {
+			return err
+		}
+		do, ok := o.(fs.TrashRestorer)
+		if !ok {
+			return errors.Errorf("%v doesn't support restoring from the trash", fsrc)
+		}
+		if fs.Config.DryRun {
+			fs.Logf(o, "Not restoring from trash as --dry-run")
+			return nil
+		}
+		return do.RestoreTrash(cmd.Context())
+	})
+}
@@ -20,7 +20,7 @@ objects in it, use purge for that.`,
 		cmd.CheckArgs(1, 1, command, args)
 		fdst := cmd.NewFsDst(args)
 		cmd.Run(true, false, command, func() error {
-			return operations.Rmdir(fdst, "")
+			return operations.Rmdir(cmd.Context(), fdst, "")
 		})
 	},
 }
@@ -3,6 +3,8 @@ package http
 import (
 	"fmt"
 	"html/template"
+	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path"
@@ -12,17 +14,27 @@ import (
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/cmd/serve/httplib"
 	"github.com/ncw/rclone/cmd/serve/httplib/httpflags"
+	"github.com/ncw/rclone/cmd/serve/readcache"
+	"github.com/ncw/rclone/cmd/serve/readcache/readcacheflags"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/lib/archive"
 	"github.com/ncw/rclone/lib/rest"
 	"github.com/ncw/rclone/vfs"
 	"github.com/ncw/rclone/vfs/vfsflags"
 	"github.com/spf13/cobra"
 )
 
+var (
+	archiveDirs = false
+)
+
 func init() {
 	httpflags.AddFlags(Command.Flags())
 	vfsflags.AddFlags(Command.Flags())
+	readcacheflags.AddFlags(Command.Flags())
+	flags.BoolVarP(Command.Flags(), &archiveDirs, "archive", "", archiveDirs, "Allow directories to be downloaded as zip or tar archives")
 }
 
 // Command definition for cobra
@@ -40,7 +52,15 @@ The server will log errors.  Use -v to see access logs.
 
 --bwlimit will be respected for file transfers.  Use --stats to
 control the stats printing.
-` + httplib.Help + vfs.Help,
+
+Use --archive to allow directories to be downloaded as a single
+archive streamed on the fly.  The directory listings then have links
+to download the directory as a zip, tar or tar.gz archive, or add
+?archive=zip, ?archive=tar or ?archive=tar.gz to the URL of a
+directory, eg
+
+    curl -O -J http://localhost:8080/photos/?archive=zip
+` + httplib.Help + readcache.Help + vfs.Help,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		f := cmd.NewFsSrc(args)
@@ -54,17 +74,26 @@ control the stats printing.
 
 // server contains everything to run the server
 type server struct {
-	f   fs.Fs
-	vfs *vfs.VFS
-	srv *httplib.Server
+	f       fs.Fs
+	vfs     *vfs.VFS
+	srv     *httplib.Server
+	cache   *readcache.Cache // cache of the files served or nil
+	archive bool             // set to allow directories to be downloaded as archives
 }
 
 func newServer(f fs.Fs, opt *httplib.Options) *server {
 	mux := http.NewServeMux()
 	s := &server{
-		f:   f,
-		vfs: vfs.New(f, &vfsflags.Opt),
-		srv: httplib.NewServer(mux, opt),
+		f:       f,
+		vfs:     vfs.New(f, &vfsflags.Opt),
+		srv:     httplib.NewServer(mux, opt),
+		archive: archiveDirs,
+	}
+	cache, err := readcache.New(f, &readcacheflags.Opt)
+	if err != nil {
+		fs.Errorf(nil, "Failed to create read cache - disabling: %v", err)
+	} else {
+		s.cache = cache
 	}
 	mux.HandleFunc("/", s.handler)
 	return s
@@ -134,7 +163,8 @@ var indexPage = `<!DOCTYPE html>
 </head>
 <body>
 <h1>{{ .Title }}</h1>
-{{ range $i := .Entries }}<a href="{{ $i.URL }}">{{ $i.Leaf }}</a><br />
+{{ if .Archives }}<p>Download as {{ range $format := .Archives }}<a href="?archive={{ $format }}">{{ $format }}</a> {{ end }}</p>
+{{ end }}{{ range $i := .Entries }}<a href="{{ $i.URL }}">{{ $i.Leaf }}</a><br />
 {{ end }}</body>
 </html>
 `
@@ -144,8 +174,9 @@ var indexTemplate = template.Must(template.New("index").Parse(indexPage))
 
 // indexData is used to fill in the indexTemplate
 type indexData struct {
-	Title   string
-	Entries entries
+	Title    string
+	Entries  entries
+	Archives []string
 }
 
 // error returns an http.StatusInternalServerError and logs the error
@@ -170,6 +201,10 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 		http.Error(w, "Not a directory", http.StatusNotFound)
 		return
 	}
+	if s.archive && r.URL.Query().Get("archive") != "" {
+		s.serveArchive(w, r, dirRemote, r.URL.Query().Get("archive"))
+		return
+	}
 	dir := node.(*vfs.Dir)
 	dirEntries, err := dir.ReadDirAll()
 	if err != nil {
@@ -187,16 +222,44 @@ func (s *server) serveDir(w http.ResponseWriter, r *http.Request, dirRemote stri
 	defer accounting.Stats.DoneTransferring(dirRemote, true)
 
 	fs.Infof(dirRemote, "%s: Serving directory", r.RemoteAddr)
-	err = indexTemplate.Execute(w, indexData{
+	data := indexData{
 		Entries: out,
 		Title:   fmt.Sprintf("Directory listing of /%s", dirRemote),
-	})
+	}
+	if s.archive {
+		data.Archives = archive.Formats
+	}
+	err = indexTemplate.Execute(w, data)
 	if err != nil {
 		internalError(dirRemote, w, "Failed to render template", err)
 		return
 	}
 }
 
+// serveArchive streams an archive in format of the directory at dirRemote
+func (s *server) serveArchive(w http.ResponseWriter, r *http.Request, dirRemote string, format string) {
+	err := archive.CheckFormat(format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", archive.MimeType(format))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": archive.Name(dirRemote, format)}))
+
+	// If HEAD no need to make the archive since we have set the headers
+	if r.Method == "HEAD" {
+		return
+	}
+
+	fs.Infof(dirRemote, "%s: Serving directory as %s archive", r.RemoteAddr, format)
+	err = archive.Create(r.Context(), s.f, dirRemote, format, w)
+	if err != nil {
+		// The headers have been sent so all we can do is log the error
+		fs.CountError(err)
+		fs.Errorf(dirRemote, "Failed to send archive: %v", err)
+	}
+}
+
 // serveFile serves a file object at remote
 func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string) {
 	node, err := s.vfs.Stat(remote)
@@ -237,7 +300,7 @@ func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string
 	}
 
 	// open the object
-	in, err := file.Open(os.O_RDONLY)
+	in, err := s.open(r, file, obj)
 	if err != nil {
 		internalError(remote, w, "Failed to open file", err)
 		return
@@ -257,3 +320,24 @@ func (s *server) serveFile(w http.ResponseWriter, r *http.Request, remote string
 	// Serve the file
 	http.ServeContent(w, r, remote, node.ModTime(), in)
 }
+
+// readSeekCloser is a file opened for serving
+type readSeekCloser interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// open opens file for reading from the read cache if there is one,
+// otherwise from the remote
+func (s *server) open(r *http.Request, file *vfs.File, obj fs.Object) (readSeekCloser, error) {
+	if s.cache != nil {
+		in, err := s.cache.Open(r.Context(), obj)
+		if err == nil {
+			return in, nil
+		}
+		if err != readcache.ErrNotCached {
+			fs.Errorf(obj, "Reading from remote: %v", err)
+		}
+	}
+	return file.Open(os.O_RDONLY)
+}
@@ -3,6 +3,8 @@
 package http
 
 import (
+	"archive/zip"
+	"bytes"
 	"flag"
 	"io/ioutil"
 	"net"
@@ -232,6 +234,38 @@ func TestAddEntry(t *testing.T) {
 	}, es)
 }
 
+func TestArchive(t *testing.T) {
+	httpServer.archive = true
+	defer func() {
+		httpServer.archive = false
+	}()
+
+	resp, err := http.Get(testURL)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `<a href="?archive=zip">zip</a>`)
+
+	resp, err = http.Get(testURL + "?archive=zip")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/zip", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=archive.zip", resp.Header.Get("Content-Disposition"))
+	body, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	require.NoError(t, err)
+	var names []string
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"one%.txt", "three/", "two.txt", "three/a.txt", "three/b.txt"}, names)
+
+	resp, err = http.Get(testURL + "three/?archive=rar")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
 func TestFinalise(t *testing.T) {
 	httpServer.srv.Close()
 }
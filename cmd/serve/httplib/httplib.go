@@ -13,6 +13,7 @@ import (
 
 	auth "github.com/abbot/go-http-auth"
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/lib/service"
 )
 
 // Globals
@@ -70,6 +71,15 @@ supply --client-ca also.
 of that with the CA certificate.  --key should be the PEM encoded
 private key and --client-ca should be the PEM encoded client
 certificate authority certificate.
+
+#### Running as a service
+
+When run as a systemd service with Type=notify the service enters
+the started state once the server is listening.
+
+When run as a Windows service the service is reported as running
+once the server is listening and stopping the service closes the
+server cleanly.
 `
 
 // Options contains options for the http Server
@@ -222,12 +232,21 @@ func (s *Server) Serve() error {
 			log.Printf("Error on serving HTTP server: %v", err)
 		}
 	}()
+	if err := service.Ready(); err != nil {
+		log.Printf("Error on notifying service manager: %v", err)
+	}
 	return nil
 }
 
-// Wait blocks while the listener is open.
+// Wait blocks while the listener is open.  If the service manager
+// asks rclone to stop it closes the server.
 func (s *Server) Wait() {
-	<-s.waitChan
+	select {
+	case <-s.waitChan:
+	case <-service.Stop():
+		service.Stopping()
+		s.Close()
+	}
 }
 
 // Close shuts the running server down
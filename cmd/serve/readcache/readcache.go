@@ -0,0 +1,252 @@
+// Package readcache implements a cache on local disk of the content
+// of the files served by the serve commands
+package readcache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/lib/evict"
+	"github.com/pkg/errors"
+)
+
+// Help contains text describing the read cache to add to the command
+// help.
+var Help = `
+### Read cache
+
+Use --read-cache-max-size to keep the files served in a cache on local
+disk, eg --read-cache-max-size 10G, so popular files are read from
+the remote once rather than every time they are downloaded.  The least
+recently used files are removed from the cache when it is bigger than
+this.  By default files aren't cached.
+
+A file is downloaded whole into the cache the first time it is asked
+for, then served from there.  It is downloaded again if its size or
+modification time on the remote has changed.  Use
+--read-cache-max-file-size to serve files bigger than this straight
+from the remote without caching them, eg large videos which would
+fill the cache and which clients only read part of.
+
+The cache is stored under ` + "`--cache-dir`" + ` and is emptied when
+the server starts.
+`
+
+// Options contains options for the read cache
+type Options struct {
+	MaxSize     fs.SizeSuffix // max total size of the cache or <= 0 for no cache
+	MaxFileSize fs.SizeSuffix // max size of a file to cache or < 0 for no limit
+}
+
+// DefaultOpt is the default values used for Options
+var DefaultOpt = Options{
+	MaxSize:     -1,
+	MaxFileSize: -1,
+}
+
+// ErrNotCached is returned by Open if the object can't be cached and
+// should be read from the remote instead
+var ErrNotCached = errors.New("object can't be read from the cache")
+
+// Cache keeps the content of objects on local disk, evicting the least
+// recently used when it gets too big
+type Cache struct {
+	f     fs.Fs            // local fs for the cache directory
+	opt   Options          // options for the cache
+	root  string           // root of the cache directory
+	mu    sync.Mutex       // protects items
+	items map[string]*item // objects in the cache by remote
+}
+
+// item is an object in the cache
+type item struct {
+	size     int64         // size of the object
+	modTime  time.Time     // modification time of the object when cached
+	atime    time.Time     // last time the item was read
+	opens    int           // number of times the item is open
+	fetching chan struct{} // closed when the download finishes, nil if not downloading
+}
+
+// New makes a read cache for f, returning nil if opt says there
+// shouldn't be one.
+//
+// The cache is emptied as the index of what is in it is kept in
+// memory.
+func New(f fs.Fs, opt *Options) (*Cache, error) {
+	if opt.MaxSize <= 0 {
+		return nil, nil
+	}
+	fRoot := filepath.FromSlash(f.Root())
+	if runtime.GOOS == "windows" {
+		if strings.HasPrefix(fRoot, `\\?`) {
+			fRoot = fRoot[3:]
+		}
+		fRoot = strings.Replace(fRoot, ":", "", -1)
+	}
+	root := filepath.Join(config.CacheDir, "serve", f.Name(), fRoot)
+	fs.Debugf(nil, "read cache root is %q", root)
+	err := os.RemoveAll(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to empty read cache")
+	}
+	cacheFs, err := fs.NewFs(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create read cache remote")
+	}
+	return &Cache{
+		f:     cacheFs,
+		opt:   *opt,
+		root:  root,
+		items: make(map[string]*item),
+	}, nil
+}
+
+// toOSPath turns a remote into an OS path in the cache
+func (c *Cache) toOSPath(remote string) string {
+	return filepath.Join(c.root, filepath.FromSlash(remote))
+}
+
+// cacheable returns true if an object of size can be cached
+func (c *Cache) cacheable(size int64) bool {
+	if size < 0 || size > int64(c.opt.MaxSize) {
+		return false
+	}
+	return c.opt.MaxFileSize < 0 || size <= int64(c.opt.MaxFileSize)
+}
+
+// Open returns the content of o read from the cache, downloading it
+// into the cache first if it isn't there or has changed.  Close the
+// File when finished with it so it can be evicted.
+//
+// It returns ErrNotCached if o can't be cached, eg it is too big, or
+// if a different version of it is being read from the cache.
+func (c *Cache) Open(ctx context.Context, o fs.Object) (*File, error) {
+	remote := o.Remote()
+	size, modTime := o.Size(), o.ModTime()
+	if !c.cacheable(size) {
+		return nil, ErrNotCached
+	}
+	c.mu.Lock()
+	for {
+		it := c.items[remote]
+		if it == nil {
+			break
+		}
+		if it.fetching != nil {
+			// Wait for the download in progress then look again
+			fetching := it.fetching
+			c.mu.Unlock()
+			select {
+			case <-fetching:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			c.mu.Lock()
+			continue
+		}
+		if it.size == size && it.modTime.Equal(modTime) {
+			file, err := c._open(remote, it)
+			c.mu.Unlock()
+			if err == nil {
+				fs.Debugf(remote, "Reading from read cache")
+			}
+			return file, err
+		}
+		if it.opens > 0 {
+			// The old version can't be replaced while it is being read
+			c.mu.Unlock()
+			return nil, ErrNotCached
+		}
+		break
+	}
+
+	// Reserve the space for the object then download it
+	it := &item{
+		size:     size,
+		modTime:  modTime,
+		atime:    time.Now(),
+		fetching: make(chan struct{}),
+	}
+	c.items[remote] = it
+	c._evict()
+	c.mu.Unlock()
+	newDst, err := operations.Copy(ctx, c.f, nil, remote, o)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	close(it.fetching)
+	it.fetching = nil
+	if err == nil && newDst == nil {
+		// eg --dry-run
+		err = ErrNotCached
+	}
+	if err != nil {
+		delete(c.items, remote)
+		if err == ErrNotCached {
+			return nil, err
+		}
+		return nil, errors.Wrap(err, "failed to download into read cache")
+	}
+	return c._open(remote, it)
+}
+
+// _open opens the cached content of remote
+//
+// call with mu held
+func (c *Cache) _open(remote string, it *item) (*File, error) {
+	fd, err := os.Open(c.toOSPath(remote))
+	if err != nil {
+		delete(c.items, remote)
+		return nil, errors.Wrap(err, "failed to open read cache file")
+	}
+	it.opens++
+	it.atime = time.Now()
+	return &File{File: fd, c: c, it: it}, nil
+}
+
+// _evict removes the least recently used objects which aren't open
+// until the cache is within its maximum size
+//
+// call with mu held
+func (c *Cache) _evict() {
+	policy := evict.Policy{MaxSize: int64(c.opt.MaxSize)}
+	items := make([]evict.Item, 0, len(c.items))
+	for remote, it := range c.items {
+		items = append(items, evict.Item{
+			Name:  remote,
+			Size:  it.size,
+			ATime: it.atime,
+			InUse: it.opens > 0 || it.fetching != nil,
+		})
+	}
+	for _, item := range policy.Choose(items, -1) {
+		fs.Debugf(item.Name, "Evicting from read cache (size %v)", fs.SizeSuffix(item.Size))
+		err := os.Remove(c.toOSPath(item.Name))
+		if err != nil && !os.IsNotExist(err) {
+			fs.Errorf(item.Name, "Failed to evict from read cache: %v", err)
+		}
+		delete(c.items, item.Name)
+	}
+}
+
+// File is an object open for reading from the cache
+type File struct {
+	*os.File
+	c  *Cache
+	it *item
+}
+
+// Close closes the file, letting it be evicted from the cache
+func (file *File) Close() error {
+	file.c.mu.Lock()
+	file.it.opens--
+	file.c.mu.Unlock()
+	return file.File.Close()
+}
@@ -0,0 +1,134 @@
+package readcache
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCache makes a remote with the files given and a cache of it
+func newTestCache(t *testing.T, opt Options, files map[string]string) (c *Cache, f fs.Fs, dir string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "rclone-readcache-test")
+	require.NoError(t, err)
+	oldCacheDir := config.CacheDir
+	config.CacheDir = filepath.Join(dir, "cache")
+	cleanup = func() {
+		config.CacheDir = oldCacheDir
+		require.NoError(t, os.RemoveAll(dir))
+	}
+	remoteDir := filepath.Join(dir, "remote")
+	require.NoError(t, os.Mkdir(remoteDir, 0777))
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(remoteDir, name), []byte(content), 0666))
+	}
+	f, err = fs.NewFs(remoteDir)
+	require.NoError(t, err)
+	c, err = New(f, &opt)
+	require.NoError(t, err)
+	return c, f, remoteDir, cleanup
+}
+
+// readCached reads remote from c checking it was cached
+func readCached(t *testing.T, c *Cache, f fs.Fs, remote string) string {
+	o, err := f.NewObject(context.Background(), remote)
+	require.NoError(t, err)
+	in, err := c.Open(context.Background(), o)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	return string(data)
+}
+
+// isCached returns whether remote is stored in c
+func isCached(c *Cache, remote string) bool {
+	_, err := os.Stat(c.toOSPath(remote))
+	return err == nil
+}
+
+func TestNewDisabled(t *testing.T) {
+	c, _, _, cleanup := newTestCache(t, DefaultOpt, nil)
+	defer cleanup()
+	assert.Nil(t, c)
+}
+
+func TestOpen(t *testing.T) {
+	c, f, remoteDir, cleanup := newTestCache(t, Options{MaxSize: 100, MaxFileSize: 10}, map[string]string{
+		"one.txt": "one",
+		"big.txt": "this is too big",
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	assert.Equal(t, "one", readCached(t, c, f, "one.txt"))
+	assert.True(t, isCached(c, "one.txt"))
+
+	// Read from the cache even though the remote has gone
+	o, err := f.NewObject(ctx, "one.txt")
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(remoteDir, "one.txt")))
+	in, err := c.Open(ctx, o)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(in)
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(data))
+	require.NoError(t, in.Close())
+
+	// Downloaded again when changed
+	require.NoError(t, ioutil.WriteFile(filepath.Join(remoteDir, "one.txt"), []byte("ONE"), 0666))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(remoteDir, "one.txt"), modTime, modTime))
+	assert.Equal(t, "ONE", readCached(t, c, f, "one.txt"))
+
+	// Too big for --read-cache-max-file-size
+	o, err = f.NewObject(ctx, "big.txt")
+	require.NoError(t, err)
+	_, err = c.Open(ctx, o)
+	assert.Equal(t, ErrNotCached, err)
+	assert.False(t, isCached(c, "big.txt"))
+}
+
+func TestEvict(t *testing.T) {
+	c, f, _, cleanup := newTestCache(t, Options{MaxSize: 10, MaxFileSize: -1}, map[string]string{
+		"one.txt":   "11111",
+		"two.txt":   "22222",
+		"three.txt": "33333",
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	assert.Equal(t, "11111", readCached(t, c, f, "one.txt"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, "22222", readCached(t, c, f, "two.txt"))
+	time.Sleep(10 * time.Millisecond)
+
+	// Read one.txt again so two.txt is the least recently used
+	assert.Equal(t, "11111", readCached(t, c, f, "one.txt"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, "33333", readCached(t, c, f, "three.txt"))
+	assert.True(t, isCached(c, "one.txt"))
+	assert.False(t, isCached(c, "two.txt"))
+	assert.True(t, isCached(c, "three.txt"))
+
+	// Open files aren't evicted
+	o, err := f.NewObject(ctx, "one.txt")
+	require.NoError(t, err)
+	in, err := c.Open(ctx, o)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, "33333", readCached(t, c, f, "three.txt"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, "22222", readCached(t, c, f, "two.txt"))
+	assert.True(t, isCached(c, "one.txt"))
+	assert.False(t, isCached(c, "three.txt"))
+	require.NoError(t, in.Close())
+}
@@ -0,0 +1,20 @@
+// Package readcacheflags implements command line flags to set up a
+// read cache for the serve commands
+package readcacheflags
+
+import (
+	"github.com/ncw/rclone/cmd/serve/readcache"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/spf13/pflag"
+)
+
+// Options set by command line flags
+var (
+	Opt = readcache.DefaultOpt
+)
+
+// AddFlags adds flags for the read cache
+func AddFlags(flagSet *pflag.FlagSet) {
+	flags.FVarP(flagSet, &Opt.MaxSize, "read-cache-max-size", "", "Cache the files served on local disk up to this total size.")
+	flags.FVarP(flagSet, &Opt.MaxFileSize, "read-cache-max-file-size", "", "Don't cache files bigger than this.")
+}
@@ -2,6 +2,7 @@
 package restic
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -233,7 +234,7 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 
 // head request the remote
 func (s *server) headObject(w http.ResponseWriter, r *http.Request, remote string) {
-	o, err := s.f.NewObject(remote)
+	o, err := s.f.NewObject(context.Background(), remote)
 	if err != nil {
 		fs.Debugf(remote, "Head request error: %v", err)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -246,7 +247,7 @@ func (s *server) headObject(w http.ResponseWriter, r *http.Request, remote strin
 
 // get the remote
 func (s *server) getObject(w http.ResponseWriter, r *http.Request, remote string) {
-	o, err := s.f.NewObject(remote)
+	o, err := s.f.NewObject(context.Background(), remote)
 	if err != nil {
 		fs.Debugf(remote, "Get request error: %v", err)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -286,14 +287,13 @@ func (s *server) getObject(w http.ResponseWriter, r *http.Request, remote string
 	}
 	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
 
-	file, err := o.Open(options...)
+	in, err := accounting.Open(context.Background(), o, options...) // account the transfer (no buffering)
 	if err != nil {
 		fs.Debugf(remote, "Get request open error: %v", err)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
 	accounting.Stats.Transferring(o.Remote())
-	in := accounting.NewAccount(file, o) // account the transfer (no buffering)
 	defer func() {
 		closeErr := in.Close()
 		if closeErr != nil {
@@ -322,7 +322,7 @@ func (s *server) getObject(w http.ResponseWriter, r *http.Request, remote string
 func (s *server) postObject(w http.ResponseWriter, r *http.Request, remote string) {
 	if appendOnly {
 		// make sure the file does not exist yet
-		_, err := s.f.NewObject(remote)
+		_, err := s.f.NewObject(context.Background(), remote)
 		if err == nil {
 			fs.Errorf(remote, "Post request: file already exists, refusing to overwrite in append-only mode")
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
@@ -352,7 +352,7 @@ func (s *server) postObject(w http.ResponseWriter, r *http.Request, remote strin
 			}
 		}()
 		info := object.NewStaticObjectInfo(remote, time.Now(), r.ContentLength, true, nil, s.f)
-		_, err = s.f.Put(in, info)
+		_, err = s.f.Put(context.Background(), in, info)
 		if err != nil {
 			fs.Errorf(remote, "Post request put error: %v", err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -360,7 +360,7 @@ func (s *server) postObject(w http.ResponseWriter, r *http.Request, remote strin
 		}
 	} else {
 		// Size unknown use Rcat
-		_, err := operations.Rcat(s.f, remote, r.Body, time.Now())
+		_, err := operations.Rcat(context.Background(), s.f, remote, r.Body, time.Now())
 		if err != nil {
 			fs.Errorf(remote, "Post request rcat error: %v", err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -381,14 +381,14 @@ func (s *server) deleteObject(w http.ResponseWriter, r *http.Request, remote str
 		}
 	}
 
-	o, err := s.f.NewObject(remote)
+	o, err := s.f.NewObject(context.Background(), remote)
 	if err != nil {
 		fs.Debugf(remote, "Delete request error: %v", err)
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 		return
 	}
 
-	if err := o.Remove(); err != nil {
+	if err := o.Remove(context.Background()); err != nil {
 		fs.Errorf(remote, "Delete request remove error: %v", err)
 		if err == fs.ErrorObjectNotFound {
 			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -434,14 +434,14 @@ func (s *server) listObjects(w http.ResponseWriter, r *http.Request, remote stri
 	// if remote supports ListR use that directly, otherwise use recursive Walk
 	var err error
 	if ListR := s.f.Features().ListR; ListR != nil {
-		err = ListR(remote, func(entries fs.DirEntries) error {
+		err = ListR(context.Background(), remote, func(entries fs.DirEntries) error {
 			for _, entry := range entries {
 				ls.add(entry)
 			}
 			return nil
 		})
 	} else {
-		err = walk.Walk(s.f, remote, true, -1, func(path string, entries fs.DirEntries, err error) error {
+		err = walk.Walk(context.Background(), s.f, remote, true, -1, func(path string, entries fs.DirEntries, err error) error {
 			if err == nil {
 				for _, entry := range entries {
 					ls.add(entry)
@@ -481,7 +481,7 @@ func (s *server) createRepo(w http.ResponseWriter, r *http.Request, remote strin
 		return
 	}
 
-	err := s.f.Mkdir(remote)
+	err := s.f.Mkdir(context.Background(), remote)
 	if err != nil {
 		fs.Errorf(remote, "Create repo failed to Mkdir: %v", err)
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -490,7 +490,7 @@ func (s *server) createRepo(w http.ResponseWriter, r *http.Request, remote strin
 
 	for _, name := range []string{"data", "index", "keys", "locks", "snapshots"} {
 		dirRemote := path.Join(remote, name)
-		err := s.f.Mkdir(dirRemote)
+		err := s.f.Mkdir(context.Background(), dirRemote)
 		if err != nil {
 			fs.Errorf(dirRemote, "Create repo failed to Mkdir: %v", err)
 			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
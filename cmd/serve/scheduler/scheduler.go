@@ -0,0 +1,252 @@
+// Package scheduler implements the "rclone serve scheduler" command
+// which runs the jobs defined in the config file on cron schedules
+package scheduler
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/lib/cron"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logDir string
+)
+
+func init() {
+	Command.Flags().StringVarP(&logDir, "log-dir", "", "", "Log each job without a log_file to NAME.log in this directory")
+}
+
+// Command definition for cobra
+var Command = &cobra.Command{
+	Use:   "scheduler",
+	Short: `Run the jobs in the config file on their schedules.`,
+	Long: `
+rclone serve scheduler runs until it is stopped, running the jobs
+defined in the config file on cron like schedules.  This can be used
+to automate backups on systems without cron, eg Windows or containers.
+
+Each job is a section in the config file called jobs.NAME, eg
+
+    [jobs.photos]
+    schedule = 30 2 * * *
+    command = sync
+    source = /home/user/photos
+    dest = remote:photos
+    flags = --transfers 8 --exclude *.tmp
+    log_file = /var/log/rclone-photos.log
+
+The keys are
+
+  * schedule - when to run the job (required)
+  * command - sync, copy, move or check (default sync)
+  * source - the source:path of the job (required)
+  * dest - the dest:path of the job (required)
+  * flags - any other flags for the command separated by spaces
+  * log_file - the file to write the log of the job to
+
+The schedule is either the 5 standard cron fields
+
+    minute hour day-of-month month day-of-week
+
+eg "*/15 * * * *" for every 15 minutes or "0 3 * * mon-fri" for 3am
+on weekdays, one of the shortcuts @hourly, @daily, @weekly, @monthly
+or @yearly, or "@every DURATION" eg "@every 6h".  The schedule uses
+local time.
+
+Each run of a job is a separate rclone process using the same config
+file.  A job isn't started if its previous run is still going - a
+message is logged instead.
+
+If a job has no log_file and --log-dir is set then it is logged to
+NAME.log in that directory, otherwise its output goes to the output
+of the scheduler.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(0, 0, command, args)
+		cmd.Run(false, false, command, func() error {
+			jobs, err := LoadJobs()
+			if err != nil {
+				return err
+			}
+			if len(jobs) == 0 {
+				return errors.Errorf("no [%sNAME] sections found in the config file", config.JobPrefix)
+			}
+			NewScheduler(jobs).Run(cmd.Context())
+			return nil
+		})
+	},
+}
+
+// jobCommands are the commands a job can run
+var jobCommands = map[string]bool{
+	"sync":  true,
+	"copy":  true,
+	"move":  true,
+	"check": true,
+}
+
+// Job is a job read from a [jobs.NAME] section of the config file
+type Job struct {
+	Name     string
+	Spec     string
+	Schedule *cron.Schedule
+	Command  string
+	Source   string
+	Dest     string
+	Flags    []string
+	LogFile  string
+}
+
+// LoadJob reads the job called name from the config file
+func LoadJob(name string) (*Job, error) {
+	section := config.JobPrefix + name
+	job := &Job{
+		Name:    name,
+		Spec:    config.FileGet(section, "schedule"),
+		Command: config.FileGet(section, "command", "sync"),
+		Source:  config.FileGet(section, "source"),
+		Dest:    config.FileGet(section, "dest"),
+		Flags:   strings.Fields(config.FileGet(section, "flags")),
+		LogFile: config.FileGet(section, "log_file"),
+	}
+	if job.Spec == "" {
+		return nil, errors.Errorf("job %q: schedule not set", name)
+	}
+	var err error
+	job.Schedule, err = cron.Parse(job.Spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "job %q", name)
+	}
+	if !jobCommands[job.Command] {
+		return nil, errors.Errorf("job %q: unknown command %q", name, job.Command)
+	}
+	if job.Source == "" || job.Dest == "" {
+		return nil, errors.Errorf("job %q: source and dest must be set", name)
+	}
+	if job.LogFile == "" && logDir != "" {
+		job.LogFile = filepath.Join(logDir, name+".log")
+	}
+	return job, nil
+}
+
+// LoadJobs reads all the jobs from the config file
+func LoadJobs() (jobs []*Job, err error) {
+	for _, name := range config.FileSectionsWithPrefix(config.JobPrefix) {
+		job, err := LoadJob(name)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Args returns the arguments for the rclone process which runs job
+func (job *Job) Args() []string {
+	args := []string{job.Command, job.Source, job.Dest}
+	args = append(args, job.Flags...)
+	args = append(args, "--config", config.ConfigPath)
+	if job.LogFile != "" {
+		args = append(args, "--log-file", job.LogFile)
+	}
+	return args
+}
+
+// runJob runs a job in a new rclone process - it is a variable so the
+// tests can replace it
+var runJob = func(ctx context.Context, job *Job) error {
+	c := exec.CommandContext(ctx, os.Args[0], job.Args()...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+// Scheduler runs jobs on their schedules
+type Scheduler struct {
+	jobs    []*Job
+	mu      sync.Mutex
+	running map[string]bool // the jobs which are running
+	wg      sync.WaitGroup  // for the jobs which are running
+}
+
+// NewScheduler makes a Scheduler for the jobs passed in
+func NewScheduler(jobs []*Job) *Scheduler {
+	return &Scheduler{
+		jobs:    jobs,
+		running: make(map[string]bool),
+	}
+}
+
+// start runs job in the background unless it is running already.  It
+// returns false if it was running.
+func (s *Scheduler) start(ctx context.Context, job *Job) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[job.Name] {
+		fs.Logf(nil, "Job %q: not starting as previous run is still going", job.Name)
+		return false
+	}
+	s.running[job.Name] = true
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fs.Logf(nil, "Job %q: starting rclone %s", job.Name, strings.Join(job.Args(), " "))
+		start := time.Now()
+		err := runJob(ctx, job)
+		if err != nil {
+			fs.Errorf(nil, "Job %q: failed after %v: %v", job.Name, time.Since(start), err)
+		} else {
+			fs.Logf(nil, "Job %q: finished after %v", job.Name, time.Since(start))
+		}
+		s.mu.Lock()
+		delete(s.running, job.Name)
+		s.mu.Unlock()
+	}()
+	return true
+}
+
+// schedule starts job each time its schedule says until ctx is done
+func (s *Scheduler) schedule(ctx context.Context, job *Job) {
+	for {
+		next := job.Schedule.Next(time.Now())
+		if next.IsZero() {
+			fs.Errorf(nil, "Job %q: schedule %q never runs", job.Name, job.Spec)
+			return
+		}
+		fs.Infof(nil, "Job %q: next run at %v", job.Name, next.Format(time.RFC3339))
+		timer := time.NewTimer(next.Sub(time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.start(ctx, job)
+		}
+	}
+}
+
+// Run runs the jobs on their schedules until ctx is done then waits
+// for any running jobs to finish.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job *Job) {
+			defer wg.Done()
+			s.schedule(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+	s.wg.Wait()
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ncw/rclone/fs/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadJob(t *testing.T) {
+	section := config.JobPrefix + "scheduler_test"
+	config.FileSet(section, "schedule", "30 2 * * *")
+	config.FileSet(section, "source", "/tmp/src")
+	config.FileSet(section, "dest", "remote:dst")
+	config.FileSet(section, "flags", "--transfers 8  --exclude *.tmp")
+
+	oldLogDir := logDir
+	logDir = "logs"
+	defer func() { logDir = oldLogDir }()
+
+	job, err := LoadJob("scheduler_test")
+	require.NoError(t, err)
+	assert.Equal(t, "sync", job.Command)
+	assert.Equal(t, []string{"--transfers", "8", "--exclude", "*.tmp"}, job.Flags)
+	assert.Equal(t, []string{"sync", "/tmp/src", "remote:dst", "--transfers", "8", "--exclude", "*.tmp", "--config", config.ConfigPath, "--log-file", filepath.Join("logs", "scheduler_test.log")}, job.Args())
+
+	config.FileSet(section, "command", "delete")
+	_, err = LoadJob("scheduler_test")
+	assert.Error(t, err)
+
+	config.FileSet(section, "command", "copy")
+	config.FileSet(section, "schedule", "potato")
+	_, err = LoadJob("scheduler_test")
+	assert.Error(t, err)
+}
+
+func TestSchedulerOverlap(t *testing.T) {
+	oldRunJob := runJob
+	defer func() { runJob = oldRunJob }()
+	release := make(chan struct{})
+	started := make(chan string, 10)
+	runJob = func(ctx context.Context, job *Job) error {
+		started <- job.Name
+		<-release
+		return nil
+	}
+
+	job := &Job{Name: "overlap"}
+	s := NewScheduler([]*Job{job})
+	ctx := context.Background()
+	assert.True(t, s.start(ctx, job))
+	assert.Equal(t, "overlap", <-started)
+	assert.False(t, s.start(ctx, job))
+	close(release)
+	s.wg.Wait()
+	assert.True(t, s.start(ctx, job))
+	assert.Equal(t, "overlap", <-started)
+	s.wg.Wait()
+}
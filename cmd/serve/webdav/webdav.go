@@ -10,6 +10,8 @@ import (
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/cmd/serve/httplib"
 	"github.com/ncw/rclone/cmd/serve/httplib/httpflags"
+	"github.com/ncw/rclone/cmd/serve/readcache"
+	"github.com/ncw/rclone/cmd/serve/readcache/readcacheflags"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/log"
 	"github.com/ncw/rclone/vfs"
@@ -22,6 +24,7 @@ import (
 func init() {
 	httpflags.AddFlags(Command.Flags())
 	vfsflags.AddFlags(Command.Flags())
+	readcacheflags.AddFlags(Command.Flags())
 }
 
 // Command definition for cobra
@@ -36,7 +39,10 @@ write it.
 
 NB at the moment each directory listing reads the start of each file
 which is undesirable: see https://github.com/golang/go/issues/22577
-` + httplib.Help + vfs.Help,
+
+Only files downloaded with GET are read from the read cache, not
+those read to make directory listings.
+` + httplib.Help + readcache.Help + vfs.Help,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		f := cmd.NewFsSrc(args)
@@ -61,9 +67,10 @@ which is undesirable: see https://github.com/golang/go/issues/22577
 // might apply". In particular, whether or not renaming a file or directory
 // overwriting another existing file or directory is an error is OS-dependent.
 type WebDAV struct {
-	f   fs.Fs
-	vfs *vfs.VFS
-	srv *httplib.Server
+	f     fs.Fs
+	vfs   *vfs.VFS
+	srv   *httplib.Server
+	cache *readcache.Cache // cache of the files downloaded or nil
 }
 
 // check interface
@@ -82,10 +89,35 @@ func newWebDAV(f fs.Fs, opt *httplib.Options) *WebDAV {
 		Logger:     w.logRequest, // FIXME
 	}
 
-	w.srv = httplib.NewServer(handler, opt)
+	cache, err := readcache.New(f, &readcacheflags.Opt)
+	if err != nil {
+		fs.Errorf(nil, "Failed to create read cache - disabling: %v", err)
+	} else {
+		w.cache = cache
+	}
+
+	w.srv = httplib.NewServer(w.markDownloads(handler), opt)
 	return w
 }
 
+// downloadKey is the context key set for GET requests
+type downloadKey struct{}
+
+// markDownloads marks the context of GET requests so OpenFile only
+// uses the read cache for files being downloaded, not those opened
+// for directory listings
+func (w *WebDAV) markDownloads(handler http.Handler) http.Handler {
+	if w.cache == nil {
+		return handler
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			r = r.WithContext(context.WithValue(r.Context(), downloadKey{}, true))
+		}
+		handler.ServeHTTP(rw, r)
+	})
+}
+
 // serve runs the http server - doesn't return
 func (w *WebDAV) serve() {
 	err := w.srv.Serve()
@@ -115,9 +147,46 @@ func (w *WebDAV) Mkdir(ctx context.Context, name string, perm os.FileMode) (err
 // OpenFile opens a file or a directory
 func (w *WebDAV) OpenFile(ctx context.Context, name string, flags int, perm os.FileMode) (file webdav.File, err error) {
 	defer log.Trace(name, "flags=%v, perm=%v", flags, perm)("err = %v", &err)
+	if w.cache != nil && flags == os.O_RDONLY && ctx.Value(downloadKey{}) != nil {
+		file, err := w.openCached(ctx, name)
+		if err == nil {
+			return file, nil
+		}
+		if err != readcache.ErrNotCached {
+			fs.Errorf(name, "Reading from remote: %v", err)
+		}
+	}
 	return w.vfs.OpenFile(name, flags, perm)
 }
 
+// cachedFile is a file being read from the read cache
+type cachedFile struct {
+	*readcache.File
+	node vfs.Node
+}
+
+// Stat returns info about the file on the remote
+func (f cachedFile) Stat() (os.FileInfo, error) {
+	return f.node, nil
+}
+
+// openCached opens the file called name from the read cache
+func (w *WebDAV) openCached(ctx context.Context, name string) (webdav.File, error) {
+	node, err := w.vfs.Stat(name)
+	if err != nil || !node.IsFile() {
+		return nil, readcache.ErrNotCached
+	}
+	obj, ok := node.DirEntry().(fs.Object)
+	if !ok {
+		return nil, readcache.ErrNotCached
+	}
+	in, err := w.cache.Open(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	return cachedFile{File: in, node: node}, nil
+}
+
 // RemoveAll removes a file or a directory and its contents
 func (w *WebDAV) RemoveAll(ctx context.Context, name string) (err error) {
 	defer log.Trace(name, "")("err = %v", &err)
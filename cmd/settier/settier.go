@@ -0,0 +1,48 @@
+package settier
+
+import (
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefintion)
+}
+
+var commandDefintion = &cobra.Command{
+	Use:   "settier tier remote:path",
+	Short: `Changes storage class/tier of objects in remote.`,
+	Long: `
+rclone settier changes the storage tier or class of objects in the
+remote if supported. Some cloud storage services provide different
+storage classes on objects, for example S3 has STANDARD,
+REDUCED_REDUNDANCY, STANDARD_IA and ONEZONE_IA.
+
+The content of the objects isn't re-uploaded - the storage class is
+changed in place on the remote.  Objects which are already in the
+requested tier are left alone.
+
+All the objects in the path are changed, obeying any filters, so you
+can change the tier of just some of them, eg
+
+    rclone settier STANDARD_IA s3:bucket/path --min-age 30d
+
+Use --dry-run to see what would be changed.  Run with -v to see a
+line for each object changed.
+
+To change a single object
+
+    rclone settier REDUCED_REDUNDANCY s3:bucket/path/file
+
+Not supported by all remotes.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		tier := args[0]
+		fsrc := cmd.NewFsSrc(args[1:])
+		cmd.Run(true, false, command, func() error {
+			return operations.SetTier(cmd.Context(), fsrc, tier)
+		})
+	},
+}
@@ -23,7 +23,7 @@ is in the same format as the standard sha1sum tool produces.
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			return operations.Sha1sum(fsrc, os.Stdout)
+			return operations.Sha1sum(cmd.Context(), fsrc, os.Stdout)
 		})
 	},
 }
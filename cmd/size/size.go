@@ -1,9 +1,15 @@
 package size
 
 import (
+	"container/heap"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/fs"
@@ -11,27 +17,40 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var jsonOutput bool
+var (
+	jsonOutput  bool
+	top         int
+	byExtension bool
+)
 
 func init() {
 	cmd.Root.AddCommand(commandDefinition)
-	commandDefinition.Flags().BoolVar(&jsonOutput, "json", false, "format output as JSON")
+	flags := commandDefinition.Flags()
+	flags.BoolVar(&jsonOutput, "json", false, "format output as JSON")
+	flags.IntVar(&top, "top", 0, "Show the N largest files")
+	flags.BoolVar(&byExtension, "by-extension", false, "Show the number and size of the files with each extension")
 }
 
 var commandDefinition = &cobra.Command{
 	Use:   "size remote:path",
 	Short: `Prints the total size and number of objects in remote:path.`,
+	Long: `
+Prints the total size and number of objects in remote:path.
+
+Use --top N to show the N largest files and --by-extension to show how
+many files with each extension there are and how much space they use,
+largest first, eg
+
+    rclone size --top 20 --by-extension remote:
+
+These are worked out while listing so don't need any more requests to
+the remote.
+`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			var err error
-			var results struct {
-				Count int64 `json:"count"`
-				Bytes int64 `json:"bytes"`
-			}
-
-			results.Count, results.Bytes, err = operations.Count(fsrc)
+			results, err := Size(cmd.Context(), fsrc, top, byExtension)
 			if err != nil {
 				return err
 			}
@@ -42,8 +61,132 @@ var commandDefinition = &cobra.Command{
 
 			fmt.Printf("Total objects: %d\n", results.Count)
 			fmt.Printf("Total size: %s (%d Bytes)\n", fs.SizeSuffix(results.Bytes).Unit("Bytes"), results.Bytes)
-
+			if len(results.Top) > 0 {
+				fmt.Printf("\nLargest %d files:\n", len(results.Top))
+				for _, file := range results.Top {
+					fmt.Printf("%14s %s\n", fs.SizeSuffix(file.Bytes).Unit("Bytes"), file.Path)
+				}
+			}
+			if len(results.Extensions) > 0 {
+				fmt.Printf("\nBy extension:\n")
+				for _, ext := range results.Extensions {
+					name := ext.Extension
+					if name == "" {
+						name = "(none)"
+					}
+					fmt.Printf("%14s %9d objects %s\n", fs.SizeSuffix(ext.Bytes).Unit("Bytes"), ext.Count, name)
+				}
+			}
 			return nil
 		})
 	},
 }
+
+// File is a file in the --top list
+type File struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Extension is the totals for an extension in the --by-extension list
+type Extension struct {
+	Extension string `json:"extension"`
+	Count     int64  `json:"count"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// Results is the output of the size command
+type Results struct {
+	Count      int64       `json:"count"`
+	Bytes      int64       `json:"bytes"`
+	Top        []File      `json:"top,omitempty"`
+	Extensions []Extension `json:"extensions,omitempty"`
+}
+
+// fileHeap is a min heap of files by size used to keep the largest
+type fileHeap []File
+
+func (h fileHeap) Len() int            { return len(h) }
+func (h fileHeap) Less(i, j int) bool  { return h[i].Bytes < h[j].Bytes }
+func (h fileHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *fileHeap) Push(x interface{}) { *h = append(*h, x.(File)) }
+func (h *fileHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// largestFirst sorts files by size, largest first
+type largestFirst []File
+
+func (s largestFirst) Len() int      { return len(s) }
+func (s largestFirst) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s largestFirst) Less(i, j int) bool {
+	if s[i].Bytes != s[j].Bytes {
+		return s[i].Bytes > s[j].Bytes
+	}
+	return s[i].Path < s[j].Path
+}
+
+// extensionsLargestFirst sorts extensions by size, largest first
+type extensionsLargestFirst []Extension
+
+func (s extensionsLargestFirst) Len() int      { return len(s) }
+func (s extensionsLargestFirst) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s extensionsLargestFirst) Less(i, j int) bool {
+	if s[i].Bytes != s[j].Bytes {
+		return s[i].Bytes > s[j].Bytes
+	}
+	return s[i].Extension < s[j].Extension
+}
+
+// Size counts the objects in f and their total size.  If top > 0 the
+// top largest files are returned and if byExtension is set the totals
+// for each extension.
+func Size(ctx context.Context, f fs.Fs, top int, byExtension bool) (*Results, error) {
+	var (
+		mu      sync.Mutex
+		results Results
+		largest fileHeap
+		exts    = make(map[string]*Extension)
+	)
+	err := operations.ListFn(ctx, f, func(o fs.Object) {
+		size := o.Size()
+		mu.Lock()
+		defer mu.Unlock()
+		results.Count++
+		results.Bytes += size
+		if top > 0 {
+			if len(largest) < top {
+				heap.Push(&largest, File{Path: o.Remote(), Bytes: size})
+			} else if size > largest[0].Bytes {
+				largest[0] = File{Path: o.Remote(), Bytes: size}
+				heap.Fix(&largest, 0)
+			}
+		}
+		if byExtension {
+			ext := strings.ToLower(path.Ext(o.Remote()))
+			total := exts[ext]
+			if total == nil {
+				total = &Extension{Extension: ext}
+				exts[ext] = total
+			}
+			total.Count++
+			total.Bytes += size
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if top > 0 {
+		results.Top = []File(largest)
+		sort.Sort(largestFirst(results.Top))
+	}
+	for _, total := range exts {
+		results.Extensions = append(results.Extensions, *total)
+	}
+	sort.Sort(extensionsLargestFirst(results.Extensions))
+	return &results, nil
+}
@@ -0,0 +1,50 @@
+package size
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+var (
+	t1 = fstest.Time("2017-02-03T04:05:06.499999999Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func TestSize(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	file1 := r.WriteObject("a.jpg", "aaaaa", t1)
+	file2 := r.WriteObject("dir/b.JPG", "bbb", t1)
+	file3 := r.WriteObject("dir/c.txt", "cccccccc", t1)
+	file4 := r.WriteObject("README", "d", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4)
+
+	results, err := Size(context.Background(), r.Fremote, 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, &Results{Count: 4, Bytes: 17}, results)
+
+	results, err = Size(context.Background(), r.Fremote, 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), results.Count)
+	assert.Equal(t, int64(17), results.Bytes)
+	assert.Equal(t, []File{
+		{Path: "dir/c.txt", Bytes: 8},
+		{Path: "a.jpg", Bytes: 5},
+	}, results.Top)
+	assert.Equal(t, []Extension{
+		{Extension: ".jpg", Count: 2, Bytes: 8},
+		{Extension: ".txt", Count: 1, Bytes: 8},
+		{Extension: "", Count: 1, Bytes: 1},
+	}, results.Extensions)
+}
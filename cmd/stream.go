@@ -0,0 +1,127 @@
+// Use "-" and local named pipes as the source or destination of a copy
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/spf13/cobra"
+)
+
+// StreamName is the pseudo remote for standard input or output
+const StreamName = "-"
+
+// IsStream returns whether arg is "-" for standard input or output or
+// the path of a local named pipe.  It doesn't open arg, so checking a
+// named pipe doesn't block waiting for the other end.
+func IsStream(arg string) bool {
+	if arg == StreamName {
+		return true
+	}
+	fi, err := os.Stat(arg)
+	return err == nil && fi.Mode()&os.ModeNamedPipe != 0
+}
+
+// checkStdout exits if anything else would write to standard output
+// while it is the destination of a copy
+func checkStdout() {
+	switch {
+	case fs.Config.Dump&(fs.DumpGoRoutines|fs.DumpOpenFiles) != 0:
+		log.Fatalf("Can't use --dump goroutines or openfiles when copying to %q", StreamName)
+	case fs.Config.Interactive:
+		log.Fatalf("Can't use --interactive when copying to %q as its questions go to standard output", StreamName)
+	case *onSuccessCmd != "" || *onFailureCmd != "":
+		log.Fatalf("Can't use --on-success-cmd or --on-failure-cmd when copying to %q as their output goes to standard output", StreamName)
+	}
+}
+
+// openStream opens the stream arg, for writing if write is set
+func openStream(arg string, write bool) *os.File {
+	if arg == StreamName {
+		if write {
+			return os.Stdout
+		}
+		return os.Stdin
+	}
+	flags := os.O_RDONLY
+	if write {
+		flags = os.O_WRONLY
+	}
+	stream, err := os.OpenFile(arg, flags, 0)
+	if err != nil {
+		log.Fatalf("Failed to open named pipe: %v", err)
+	}
+	return stream
+}
+
+// RunStream runs the copy of command from args[0] to args[1] if either
+// is a stream (see IsStream), deleting the source after a copy to a
+// stream if move is set.  It returns false without doing anything if
+// neither is a stream.
+//
+// Copies from a stream work like rcat and copies to a stream like cat.
+// Neither can be retried.
+func RunStream(command *cobra.Command, args []string, move bool) bool {
+	srcStream, dstStream := IsStream(args[0]), IsStream(args[1])
+	switch {
+	case srcStream && dstStream:
+		log.Fatalf("Can't copy from a stream to a stream")
+	case srcStream:
+		fdst, dstFileName := NewFsDstFile(args[1:])
+		in := openStream(args[0], false)
+		Run(false, false, command, func() error {
+			_, err := operations.Rcat(Context(), fdst, dstFileName, in, time.Now())
+			return err
+		})
+	case dstStream:
+		if args[1] == StreamName {
+			checkStdout()
+		}
+		fsrc, srcFileName := NewFsFile(args[0])
+		if srcFileName == "" {
+			log.Fatalf("%q must be a file to copy it to a stream", args[0])
+		}
+		out := openStream(args[1], true)
+		Run(false, false, command, func() error {
+			return copyToStream(Context(), out, fsrc, srcFileName, move)
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// copyToStream copies the file srcFileName in fsrc to out, deleting it
+// afterwards if move is set
+func copyToStream(ctx context.Context, out io.WriteCloser, fsrc fs.Fs, srcFileName string, move bool) (err error) {
+	defer func() {
+		closeErr := out.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	o, err := fsrc.NewObject(ctx, srcFileName)
+	if err != nil {
+		return err
+	}
+	in, err := accounting.Open(ctx, o)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	closeErr := in.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil || !move {
+		return err
+	}
+	return operations.DeleteFile(ctx, o)
+}
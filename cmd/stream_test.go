@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closeBuffer is a bytes.Buffer which can be closed
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestIsStream(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-stream")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, []byte("potato"), 0600))
+
+	assert.True(t, IsStream("-"))
+	assert.False(t, IsStream(file))
+	assert.False(t, IsStream(dir))
+	assert.False(t, IsStream(filepath.Join(dir, "missing")))
+}
+
+func TestCopyToStream(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "rclone-stream")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(file, []byte("potato"), 0600))
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+
+	var out closeBuffer
+	require.NoError(t, copyToStream(ctx, &out, f, "file", false))
+	assert.Equal(t, "potato", out.String())
+	assert.True(t, out.closed)
+	_, err = os.Stat(file)
+	require.NoError(t, err)
+
+	out = closeBuffer{}
+	require.NoError(t, copyToStream(ctx, &out, f, "file", true))
+	assert.Equal(t, "potato", out.String())
+	_, err = os.Stat(file)
+	assert.True(t, os.IsNotExist(err))
+
+	out = closeBuffer{}
+	assert.Error(t, copyToStream(ctx, &out, f, "file", false))
+	assert.True(t, out.closed)
+}
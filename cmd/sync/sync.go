@@ -6,8 +6,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Globals
+var (
+	createEmptySrcDirs = false
+	stagingDir         = ""
+)
+
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
+	commandDefintion.Flags().BoolVarP(&createEmptySrcDirs, "create-empty-src-dirs", "", createEmptySrcDirs, "Create empty source dirs on destination after sync")
+	commandDefintion.Flags().StringVarP(&stagingDir, "staging-dir", "", stagingDir, "Sync into this directory then swap it with the destination")
 }
 
 var commandDefintion = &cobra.Command{
@@ -32,12 +40,34 @@ extended explanation in the ` + "`" + `copy` + "`" + ` command above if unsure.
 
 If dest:path doesn't exist, it is created and the source:path contents
 go there.
+
+Empty directories in the source are not normally created on the
+destination.  Use the --create-empty-src-dirs flag to create them.
+Directories which only exist on the destination are removed if they
+are empty after the sync.
+
+Use --staging-dir to keep the destination consistent while it is being
+synced.  The source is synced into the staging directory, which must
+be on the same remote as the destination but not inside it, then the
+staging directory
+is swapped with the destination using server side directory moves, eg
+
+    rclone sync --staging-dir remote:site.staging /path/to/site remote:site
+
+If the remote can copy server side the staging directory is filled
+with a copy of the destination first so only changed files are
+uploaded.  The old destination is moved to dest:path.rclone-old while
+the directories are swapped and then removed.  The remote must be able
+to move directories server side and filters can't be used.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(true, true, command, func() error {
-			return sync.Sync(fdst, fsrc)
+			if stagingDir != "" {
+				return sync.SyncStaged(cmd.Context(), fsrc, args[1], stagingDir, createEmptySrcDirs)
+			}
+			return sync.Sync(cmd.Context(), fdst, fsrc, createEmptySrcDirs)
 		})
 	},
 }
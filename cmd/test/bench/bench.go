@@ -0,0 +1,271 @@
+// Package bench implements the "rclone test bench" command which
+// measures the performance of a remote
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Options for the benchmark
+type Options struct {
+	Size       fs.SizeSuffix // size of the large files
+	SmallSize  fs.SizeSuffix // size of the small files
+	SmallFiles int           // number of small files
+	Parallel   int           // number of operations to run at once
+	ListTimes  int           // number of times to list the small files
+}
+
+// Opt is the options set by the flags
+var Opt = Options{
+	Size:       100 * 1024 * 1024,
+	SmallSize:  1024,
+	SmallFiles: 100,
+	Parallel:   4,
+	ListTimes:  3,
+}
+
+func init() {
+	flagSet := Command.Flags()
+	flags.FVarP(flagSet, &Opt.Size, "size", "", "Size of each large file")
+	flags.FVarP(flagSet, &Opt.SmallSize, "small-size", "", "Size of each small file")
+	flags.IntVarP(flagSet, &Opt.SmallFiles, "small-files", "", Opt.SmallFiles, "Number of small files")
+	flags.IntVarP(flagSet, &Opt.Parallel, "parallel", "", Opt.Parallel, "Number of operations to run in parallel")
+	flags.IntVarP(flagSet, &Opt.ListTimes, "list-times", "", Opt.ListTimes, "Number of times to list the small files")
+}
+
+// Command definition for cobra
+var Command = &cobra.Command{
+	Use:   "bench remote:path",
+	Short: `Measure the upload, download, listing and small file performance of a remote.`,
+	Long: `
+rclone test bench runs a series of benchmarks against remote:path
+and prints a report which can be compared between remotes, or between
+runs with different flags such as --transfers or the chunk size flags
+of the backend.
+
+It makes a temporary directory in remote:path and
+
+  * uploads --parallel large files of --size each at the same time
+  * downloads them again at the same time
+  * uploads --small-files files of --small-size each, --parallel at a time
+  * lists the directory of small files --list-times times
+  * deletes the small files, --parallel at a time
+
+The temporary directory is purged when the benchmark finishes.
+
+For example to benchmark with 8 parallel streams of 1GB files
+
+    rclone test bench remote:path --parallel 8 --size 1G
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		fdst := cmd.NewFsDst(args)
+		cmd.Run(false, false, command, func() error {
+			results, err := Bench(cmd.Context(), fdst, Opt)
+			if err != nil {
+				return err
+			}
+			results.Write(os.Stdout)
+			return nil
+		})
+	},
+}
+
+// Result is the result of one benchmark
+type Result struct {
+	Name     string        // name of the benchmark
+	Count    int           // number of operations done
+	Bytes    int64         // number of bytes transferred, 0 if not a transfer
+	Duration time.Duration // time taken
+}
+
+// Results are the results of all the benchmarks
+type Results []Result
+
+// Write the results as a table to out
+func (rs Results) Write(out io.Writer) {
+	for _, r := range rs {
+		seconds := r.Duration.Seconds()
+		if seconds <= 0 {
+			seconds = 1E-9
+		}
+		rate := fmt.Sprintf("%.1f ops/s", float64(r.Count)/seconds)
+		if r.Bytes > 0 {
+			rate = fmt.Sprintf("%vBytes/s", fs.SizeSuffix(float64(r.Bytes)/seconds))
+		}
+		average := r.Duration
+		if r.Count > 0 {
+			average /= time.Duration(r.Count)
+		}
+		_, _ = fmt.Fprintf(out, "%-10s %6d ops %12s %16s %12s/op\n", r.Name, r.Count, r.Duration.Round(time.Millisecond), rate, average.Round(time.Microsecond))
+	}
+}
+
+// parallel runs fn(i) for i in 0..n-1 with at most p running at once
+// returning the first error
+func parallel(n, p int, fn func(i int) error) error {
+	if p < 1 {
+		p = 1
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		tokens   = make(chan struct{}, p)
+	)
+	for i := 0; i < n; i++ {
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := fn(i)
+			<-tokens
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// upload makes a file of size random bytes at remote
+func upload(ctx context.Context, f fs.Fs, remote string, size int64, seed int64) (fs.Object, error) {
+	in := io.LimitReader(rand.New(rand.NewSource(seed)), size)
+	src := object.NewStaticObjectInfo(remote, time.Now(), size, true, nil, f)
+	return f.Put(ctx, in, src)
+}
+
+// download reads all of o
+func download(ctx context.Context, o fs.Object) (err error) {
+	in, err := accounting.Open(ctx, o)
+	if err != nil {
+		return err
+	}
+	defer fs.CheckClose(in, &err)
+	n, err := io.Copy(ioutil.Discard, in)
+	if err != nil {
+		return err
+	}
+	if n != o.Size() {
+		return errors.Errorf("%v: downloaded %d bytes but expected %d", o, n, o.Size())
+	}
+	return nil
+}
+
+// Bench runs the benchmarks on f with opt returning the results
+//
+// It runs in a temporary directory of f which is purged afterwards
+func Bench(ctx context.Context, f fs.Fs, opt Options) (results Results, err error) {
+	dir := fmt.Sprintf("rclone-bench-%08x", rand.Uint32())
+	fs.Infof(f, "Running benchmarks in %q", dir)
+	err = f.Mkdir(ctx, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make benchmark directory")
+	}
+	defer func() {
+		purgeErr := operations.Purge(ctx, f, dir)
+		if purgeErr != nil {
+			fs.Errorf(f, "Failed to remove benchmark directory %q: %v", dir, purgeErr)
+			if err == nil {
+				err = purgeErr
+			}
+		}
+	}()
+
+	// time fn and add the result
+	run := func(name string, count int, bytes int64, fn func() error) error {
+		fs.Infof(f, "Running %s benchmark", name)
+		start := time.Now()
+		err := fn()
+		if err != nil {
+			return errors.Wrapf(err, "%s benchmark failed", name)
+		}
+		results = append(results, Result{
+			Name:     name,
+			Count:    count,
+			Bytes:    bytes,
+			Duration: time.Since(start),
+		})
+		return nil
+	}
+
+	// Large files
+	large := make([]fs.Object, opt.Parallel)
+	largeBytes := int64(opt.Size) * int64(opt.Parallel)
+	err = run("upload", opt.Parallel, largeBytes, func() error {
+		return parallel(opt.Parallel, opt.Parallel, func(i int) (err error) {
+			large[i], err = upload(ctx, f, path.Join(dir, fmt.Sprintf("large-%d.bin", i)), int64(opt.Size), int64(i))
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = run("download", opt.Parallel, largeBytes, func() error {
+		return parallel(opt.Parallel, opt.Parallel, func(i int) error {
+			return download(ctx, large[i])
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Small files
+	smallDir := path.Join(dir, "small")
+	small := make([]fs.Object, opt.SmallFiles)
+	err = run("create", opt.SmallFiles, 0, func() error {
+		return parallel(opt.SmallFiles, opt.Parallel, func(i int) (err error) {
+			small[i], err = upload(ctx, f, path.Join(smallDir, fmt.Sprintf("small-%d.bin", i)), int64(opt.SmallSize), int64(i))
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = run("list", opt.ListTimes, 0, func() error {
+		for i := 0; i < opt.ListTimes; i++ {
+			entries, err := f.List(ctx, smallDir)
+			if err != nil {
+				return err
+			}
+			if len(entries) != opt.SmallFiles {
+				fs.Logf(f, "Listing returned %d entries but expected %d", len(entries), opt.SmallFiles)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = run("delete", opt.SmallFiles, 0, func() error {
+		return parallel(opt.SmallFiles, opt.Parallel, func(i int) error {
+			return small[i].Remove(ctx)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
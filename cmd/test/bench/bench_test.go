@@ -0,0 +1,61 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+func TestBench(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	results, err := Bench(context.Background(), r.Fremote, Options{
+		Size:       4096,
+		SmallSize:  16,
+		SmallFiles: 5,
+		Parallel:   2,
+		ListTimes:  2,
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, result := range results {
+		names = append(names, result.Name)
+	}
+	assert.Equal(t, []string{"upload", "download", "create", "list", "delete"}, names)
+	assert.Equal(t, int64(2*4096), results[0].Bytes)
+	assert.Equal(t, 5, results[2].Count)
+
+	var out bytes.Buffer
+	results.Write(&out)
+	assert.Equal(t, 5, strings.Count(out.String(), "\n"))
+
+	// check the benchmark directory was removed
+	fstest.CheckListingWithPrecision(t, r.Fremote, nil, []string{}, fs.Config.ModifyWindow)
+}
+
+func TestParallel(t *testing.T) {
+	seen := make([]bool, 10)
+	err := parallel(10, 3, func(i int) error {
+		seen[i] = true
+		return nil
+	})
+	require.NoError(t, err)
+	for i := range seen {
+		assert.True(t, seen[i], i)
+	}
+}
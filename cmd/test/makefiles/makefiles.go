@@ -0,0 +1,261 @@
+// Package makefiles implements the "rclone test makefiles" command
+// which makes a reproducible tree of files with random contents
+package makefiles
+
+import (
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Options for making the files
+type Options struct {
+	Files         int           // number of files to make
+	FilesPerDir   int           // number of files in each directory
+	MinSize       fs.SizeSuffix // minimum size of each file
+	MaxSize       fs.SizeSuffix // maximum size of each file
+	Distribution  string        // how the sizes are distributed - "uniform" or "log"
+	MinNameLength int           // minimum length of the names
+	MaxNameLength int           // maximum length of the names
+	Seed          int64         // seed for the random numbers
+}
+
+// Opt is the options set by the flags
+var Opt = Options{
+	Files:         1000,
+	FilesPerDir:   10,
+	MinSize:       0,
+	MaxSize:       100,
+	Distribution:  "uniform",
+	MinNameLength: 4,
+	MaxNameLength: 12,
+	Seed:          1,
+}
+
+func init() {
+	flagSet := Command.Flags()
+	flags.IntVarP(flagSet, &Opt.Files, "files", "", Opt.Files, "Number of files to make")
+	flags.IntVarP(flagSet, &Opt.FilesPerDir, "files-per-directory", "", Opt.FilesPerDir, "Number of files in each directory")
+	flags.FVarP(flagSet, &Opt.MinSize, "min-file-size", "", "Minimum size of each file")
+	flags.FVarP(flagSet, &Opt.MaxSize, "max-file-size", "", "Maximum size of each file")
+	flags.StringVarP(flagSet, &Opt.Distribution, "distribution", "", Opt.Distribution, "How the file sizes are distributed: uniform or log")
+	flags.IntVarP(flagSet, &Opt.MinNameLength, "min-name-length", "", Opt.MinNameLength, "Minimum length of the file and directory names")
+	flags.IntVarP(flagSet, &Opt.MaxNameLength, "max-name-length", "", Opt.MaxNameLength, "Maximum length of the file and directory names")
+	flags.IntVar64P(flagSet, &Opt.Seed, "seed", "", Opt.Seed, "Seed for the random number generator")
+}
+
+// Command definition for cobra
+var Command = &cobra.Command{
+	Use:   "makefiles remote:path",
+	Short: `Make a reproducible tree of files with random contents.`,
+	Long: `
+rclone test makefiles makes a tree of --files files in remote:path
+with random names, sizes and contents.  The directory to make them in
+may be on the local disk or any remote.
+
+The files are put in directories of --files-per-directory files each
+which are nested at random.  The sizes of the files are between
+--min-file-size and --max-file-size, either spread evenly with
+--distribution uniform or with --distribution log so that there are
+as many files of each order of magnitude, which is closer to real
+data.
+
+The same --seed and other options always make exactly the same tree,
+with the same names, sizes, modification times and contents, so it
+can be used to benchmark transfers or to make a bug report which
+anyone can reproduce, eg
+
+    rclone test makefiles --files 10000 --max-file-size 10M --distribution log --seed 42 remote:test
+
+The files are uploaded --transfers at a time.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		fdst := cmd.NewFsDst(args)
+		cmd.Run(false, false, command, func() error {
+			return MakeFiles(cmd.Context(), fdst, Opt)
+		})
+	},
+}
+
+// File describes one of the files to make
+type File struct {
+	Remote  string    // path of the file
+	Size    int64     // size of the file
+	ModTime time.Time // modification time of the file
+	Seed    int64     // seed for the contents of the file
+}
+
+// baseTime is the earliest modification time of the files
+var baseTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// nameChars are the characters used for the random names
+const nameChars = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// maxNameSpace caps the number of names nameSpace counts
+const maxNameSpace = 1 << 30
+
+// nameSpace returns how many different names of nameChars between
+// minLength and maxLength long there are, up to maxNameSpace
+func nameSpace(minLength, maxLength int) int64 {
+	var space int64
+	for length := minLength; length <= maxLength && space < maxNameSpace; length++ {
+		n := int64(1)
+		for i := 0; i < length && n < maxNameSpace; i++ {
+			n *= int64(len(nameChars))
+		}
+		space += n
+	}
+	return space
+}
+
+// Plan works out the files to make from opt.  It returns the same
+// files every time for the same opt.
+func Plan(opt Options) ([]File, error) {
+	switch {
+	case opt.Files < 0:
+		return nil, errors.New("--files can't be negative")
+	case opt.FilesPerDir < 1:
+		return nil, errors.New("--files-per-directory must be at least 1")
+	case opt.MinSize < 0 || opt.MaxSize < opt.MinSize:
+		return nil, errors.New("--max-file-size must be at least --min-file-size")
+	case opt.MinNameLength < 1 || opt.MaxNameLength < opt.MinNameLength:
+		return nil, errors.New("--max-name-length must be at least --min-name-length which must be at least 1")
+	case opt.Distribution != "uniform" && opt.Distribution != "log":
+		return nil, errors.Errorf("unknown --distribution %q - use uniform or log", opt.Distribution)
+	}
+	space := nameSpace(opt.MinNameLength, opt.MaxNameLength)
+	if int64(opt.FilesPerDir) > space {
+		return nil, errors.Errorf("--files-per-directory %d is more than the %d names --min-name-length and --max-name-length allow", opt.FilesPerDir, space)
+	}
+	rng := rand.New(rand.NewSource(opt.Seed))
+
+	// used tracks the names in use in each directory
+	used := map[string]map[string]struct{}{}
+	randomName := func(dir string) (string, error) {
+		if used[dir] == nil {
+			used[dir] = map[string]struct{}{}
+		}
+		if int64(len(used[dir])) >= space {
+			return "", errors.Errorf("no names left in directory %q - increase --max-name-length or --files-per-directory", dir)
+		}
+		for {
+			name := make([]byte, opt.MinNameLength+rng.Intn(opt.MaxNameLength-opt.MinNameLength+1))
+			for i := range name {
+				name[i] = nameChars[rng.Intn(len(nameChars))]
+			}
+			if _, found := used[dir][string(name)]; !found {
+				used[dir][string(name)] = struct{}{}
+				return string(name), nil
+			}
+		}
+	}
+	randomSize := func() int64 {
+		min, max := int64(opt.MinSize), int64(opt.MaxSize)
+		if opt.Distribution == "log" {
+			// uniform in log(1+size)
+			logMin, logMax := math.Log1p(float64(min)), math.Log1p(float64(max))
+			size := int64(math.Expm1(logMin + rng.Float64()*(logMax-logMin)))
+			if size < min {
+				size = min
+			} else if size > max {
+				size = max
+			}
+			return size
+		}
+		return min + rng.Int63n(max-min+1)
+	}
+
+	files := make([]File, 0, opt.Files)
+	dirs := []string{""}
+	dir := ""
+	for i := 0; i < opt.Files; i++ {
+		if i > 0 && i%opt.FilesPerDir == 0 {
+			// start a new directory inside a random existing one
+			parent := dirs[rng.Intn(len(dirs))]
+			name, err := randomName(parent)
+			if err != nil {
+				return nil, err
+			}
+			dir = path.Join(parent, name)
+			dirs = append(dirs, dir)
+		}
+		name, err := randomName(dir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, File{
+			Remote:  path.Join(dir, name),
+			Size:    randomSize(),
+			ModTime: baseTime.Add(time.Duration(rng.Int63n(int64(365 * 24 * time.Hour)))),
+			Seed:    rng.Int63(),
+		})
+	}
+	return files, nil
+}
+
+// makeFile uploads file to f
+func makeFile(ctx context.Context, f fs.Fs, file File) error {
+	in := io.LimitReader(rand.New(rand.NewSource(file.Seed)), file.Size)
+	src := object.NewStaticObjectInfo(file.Remote, file.ModTime, file.Size, true, nil, f)
+	_, err := f.Put(ctx, in, src)
+	return err
+}
+
+// MakeFiles makes the files described by opt in f, uploading
+// --transfers at once
+func MakeFiles(ctx context.Context, f fs.Fs, opt Options) error {
+	files, err := Plan(opt)
+	if err != nil {
+		return err
+	}
+	err = operations.Mkdir(ctx, f, "")
+	if err != nil {
+		return err
+	}
+	if fs.Config.DryRun {
+		fs.Logf(f, "Not making %d files as --dry-run", len(files))
+		return nil
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		tokens   = make(chan struct{}, fs.Config.Transfers)
+	)
+	for _, file := range files {
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(file File) {
+			defer wg.Done()
+			err := makeFile(ctx, f, file)
+			<-tokens
+			if err != nil {
+				fs.CountError(err)
+				fs.Errorf(file.Remote, "Failed to make file: %v", err)
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(file)
+	}
+	wg.Wait()
+	if firstErr == nil {
+		fs.Infof(f, "Made %d files", len(files))
+	}
+	return firstErr
+}
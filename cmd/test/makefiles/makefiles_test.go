@@ -0,0 +1,102 @@
+package makefiles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/ncw/rclone/backend/local"
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+var testOpt = Options{
+	Files:         25,
+	FilesPerDir:   4,
+	MinSize:       10,
+	MaxSize:       1000,
+	Distribution:  "log",
+	MinNameLength: 2,
+	MaxNameLength: 6,
+	Seed:          42,
+}
+
+func TestPlan(t *testing.T) {
+	files, err := Plan(testOpt)
+	require.NoError(t, err)
+	require.Len(t, files, 25)
+	seen := map[string]bool{}
+	for _, file := range files {
+		assert.False(t, seen[file.Remote], file.Remote)
+		seen[file.Remote] = true
+		assert.True(t, file.Size >= 10 && file.Size <= 1000, file.Size)
+	}
+
+	// the same options make the same files
+	again, err := Plan(testOpt)
+	require.NoError(t, err)
+	assert.Equal(t, files, again)
+
+	// a different seed doesn't
+	opt := testOpt
+	opt.Seed++
+	different, err := Plan(opt)
+	require.NoError(t, err)
+	assert.NotEqual(t, files, different)
+
+	opt = testOpt
+	opt.Distribution = "potato"
+	_, err = Plan(opt)
+	assert.Error(t, err)
+
+	// running out of names is an error rather than looping forever
+	opt = testOpt
+	opt.MinNameLength, opt.MaxNameLength = 1, 1
+	opt.FilesPerDir = 37
+	_, err = Plan(opt)
+	assert.Error(t, err)
+	opt.Files, opt.FilesPerDir = 2000, 36
+	_, err = Plan(opt)
+	assert.Error(t, err)
+}
+
+func TestNameSpace(t *testing.T) {
+	assert.Equal(t, int64(36), nameSpace(1, 1))
+	assert.Equal(t, int64(36+36*36), nameSpace(1, 2))
+	assert.True(t, nameSpace(1, 100) >= maxNameSpace)
+}
+
+func TestMakeFiles(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	require.NoError(t, MakeFiles(ctx, r.Flocal, testOpt))
+	require.NoError(t, MakeFiles(ctx, r.Fremote, testOpt))
+
+	count, size, err := operations.Count(ctx, r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(25), count)
+	files, err := Plan(testOpt)
+	require.NoError(t, err)
+	var wantSize int64
+	for _, file := range files {
+		wantSize += file.Size
+	}
+	assert.Equal(t, wantSize, size)
+
+	// both trees are the same
+	require.NoError(t, operations.Check(ctx, r.Fremote, r.Flocal, false))
+	o, err := r.Fremote.NewObject(ctx, files[0].Remote)
+	require.NoError(t, err)
+	_, ok := fstest.CheckTimeEqualWithPrecision(files[0].ModTime, o.ModTime(), fs.Config.ModifyWindow)
+	assert.True(t, ok)
+}
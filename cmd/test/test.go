@@ -0,0 +1,35 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/cmd/test/bench"
+	"github.com/ncw/rclone/cmd/test/makefiles"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	Command.AddCommand(bench.Command)
+	Command.AddCommand(makefiles.Command)
+	cmd.Root.AddCommand(Command)
+}
+
+// Command definition for cobra
+var Command = &cobra.Command{
+	Use:   "test <subcommand> [opts] <remote>",
+	Short: `Run a test command against a remote.`,
+	Long: `rclone test is used to run test commands against a remote.  This
+command requires the use of a subcommand, eg
+
+    rclone test bench remote:
+
+Each subcommand has its own options which you can see in their help.
+`,
+	RunE: func(command *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("test requires a subcommand, eg 'rclone test bench remote:'")
+		}
+		return errors.New("unknown subcommand")
+	},
+}
@@ -0,0 +1,146 @@
+// Package tier implements the "rclone tier" command which moves old
+// files from one remote to another
+package tier
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/sync"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	olderThan = fs.DurationOff
+	manifest  = ""
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefinition)
+	commandDefinition.Flags().VarP(&olderThan, "older-than", "", "Move files older than this in s or suffix ms|s|m|h|d|w|M|y (required)")
+	commandDefinition.Flags().StringVarP(&manifest, "manifest", "", manifest, "Append a JSON line for each file moved to this local file")
+}
+
+var commandDefinition = &cobra.Command{
+	Use:   "tier source:path dest:path --older-than AGE",
+	Short: `Move files older than an age from source to dest.`,
+	Long: `
+rclone tier moves the files in the source which were last modified
+longer ago than ` + "`--older-than`" + ` to the destination, keeping
+their paths, so old files can be moved to cheaper storage, eg
+
+    rclone tier opendrive:files b2:archive/files --older-than 1y --manifest tiered.json
+
+This works like rclone move with ` + "`--min-age`" + ` but the files are
+moved safely: each is copied to the destination and nothing is deleted
+from the source until every file copied has been read back from the
+destination and checked against the source by size, and by hash if
+the source and destination have a hash in common.  If any file fails
+the check or there were any errors then no source files are deleted.
+
+If they don't have a hash in common, as in the example above where
+OpenDrive has MD5 and B2 has SHA-1, only the sizes are checked and
+rclone logs a warning saying so.
+
+If ` + "`--manifest`" + ` is given then a line of JSON is appended to
+this local file for each file moved, before the sources are deleted,
+like this
+
+    {"Path":"dir/file.txt","Size":6,"ModTime":"2017-01-02T15:04:05Z","Hashes":{"MD5":"b1946ac92492d2347c6235b4d2611184"},"Source":"opendrive:files","Destination":"b2:archive/files","Moved":"2019-03-01T10:00:00Z"}
+
+Hashes has the hash the source and destination have in common, if
+any, of the file in the destination.  DestPath is added if the file
+has a different path in the destination, eg with ` + "`--dest-template`" + `.  If the manifest can't be written
+then no source files are deleted.
+
+Other filters can be used as well to choose the files, eg
+` + "`--include \"*.log\"`" + `.  Use --dry-run to see what would be
+moved.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		if !olderThan.IsSet() {
+			log.Fatalf("--older-than must be set")
+		}
+		fsrc, fdst := cmd.NewFsSrcDst(args)
+		if fsrc.Hashes().Overlap(fdst.Hashes()).Count() == 0 {
+			fs.Logf(fdst, "No hash in common with %v so the files moved will only be checked by size", fsrc)
+		}
+		modTimeTo := time.Now().Add(-time.Duration(olderThan))
+		if filter.Active.ModTimeTo.IsZero() || modTimeTo.Before(filter.Active.ModTimeTo) {
+			filter.Active.ModTimeTo = modTimeTo
+			filter.Active.Opt.MinAge = olderThan
+		}
+		cmd.Run(true, true, command, func() (err error) {
+			var moved sync.MovedFn
+			if manifest != "" && !fs.Config.DryRun {
+				var out *os.File
+				out, err = os.OpenFile(manifest, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+				if err != nil {
+					return errors.Wrap(err, "failed to open manifest")
+				}
+				defer fs.CheckClose(out, &err)
+				moved = newManifest(out, fsrc, fdst).add
+			}
+			return sync.MoveDirVerified(cmd.Context(), fdst, fsrc, moved)
+		})
+	},
+}
+
+// Entry is a line of the manifest
+type Entry struct {
+	Path        string            // path of the file in Source
+	DestPath    string            `json:",omitempty"` // path of the file in Destination if different
+	Size        int64             // size of the file
+	ModTime     time.Time         // modification time of the file
+	Hashes      map[string]string `json:",omitempty"` // hash of the file in Destination
+	Source      string            // the source remote
+	Destination string            // the destination remote
+	Moved       time.Time         // when the file was moved
+}
+
+// manifestWriter writes the manifest entries
+type manifestWriter struct {
+	enc        *json.Encoder
+	ht         hash.Type
+	fsrc, fdst string
+}
+
+// newManifest makes a manifestWriter writing to out
+func newManifest(out *os.File, fsrc, fdst fs.Fs) *manifestWriter {
+	return &manifestWriter{
+		enc:  json.NewEncoder(out),
+		ht:   fsrc.Hashes().Overlap(fdst.Hashes()).GetOne(),
+		fsrc: fsrc.Name() + ":" + fsrc.Root(),
+		fdst: fdst.Name() + ":" + fdst.Root(),
+	}
+}
+
+// add writes the entry for src which was moved to dst
+func (m *manifestWriter) add(src, dst fs.Object) error {
+	entry := Entry{
+		Path:        src.Remote(),
+		Size:        dst.Size(),
+		ModTime:     dst.ModTime(),
+		Source:      m.fsrc,
+		Destination: m.fdst,
+		Moved:       time.Now(),
+	}
+	if dst.Remote() != src.Remote() {
+		entry.DestPath = dst.Remote()
+	}
+	if m.ht != hash.None {
+		sum, err := dst.Hash(m.ht)
+		if err == nil && sum != "" {
+			entry.Hashes = map[string]string{m.ht.String(): sum}
+		}
+	}
+	return m.enc.Encode(&entry)
+}
@@ -2,11 +2,15 @@ package touch
 
 import (
 	"bytes"
+	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/object"
+	"github.com/ncw/rclone/fs/walk"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -14,23 +18,49 @@ import (
 var (
 	notCreateNewFile bool
 	timeAsArgument   string
+	recursive        bool
 )
 
 const defaultLayout string = "060102"
 const layoutDateWithTime = "2006-01-02T15:04:05"
+const layoutDate = "2006-01-02"
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	flags := commandDefintion.Flags()
 	flags.BoolVarP(&notCreateNewFile, "no-create", "C", false, "Do not create the file if it does not exist.")
-	flags.StringVarP(&timeAsArgument, "timestamp", "t", "", "Change the modification times to the specified time instead of the current time of day. The argument is of the form 'YYMMDD' (ex. 17.10.30) or 'YYYY-MM-DDTHH:MM:SS' (ex. 2006-01-02T15:04:05)")
+	flags.StringVarP(&timeAsArgument, "timestamp", "t", "", "Change the modification times to the specified time instead of the current time of day. The argument is of the form 'YYMMDD' (ex. 17.10.30), 'YYYY-MM-DD', 'YYYY-MM-DDTHH:MM:SS' (ex. 2006-01-02T15:04:05), RFC3339 (ex. 2006-01-02T15:04:05+07:00) or '@' and seconds since the epoch (ex. @1136214245)")
+	flags.BoolVarP(&recursive, "recursive", "R", false, "Touch recursively all the files in the directory, obeying the filters.")
 }
 
 var commandDefintion = &cobra.Command{
 	Use:   "touch remote:path",
 	Short: `Create new file or change file modification time.`,
+	Long: `
+Set the modification time of remote:path to the current time, or the
+time given with --timestamp, creating an empty file if it doesn't
+exist unless --no-create is given.
+
+With --recursive remote:path can be a directory and the modification
+time of every file in it is set instead.  The filter flags can be used
+to choose which files are touched.  No files are created in this mode.
+This can be used to fix up trees whose modification times were lost,
+eg
+
+    rclone touch -R --include "*.jpg" -t 2019-01-01 remote:photos
+
+Times without a time zone are in UTC.  Use --dry-run to see which
+files would be touched.
+`,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(1, 1, command, args)
+		if recursive {
+			fsrc := cmd.NewFsSrc(args)
+			cmd.Run(true, false, command, func() error {
+				return TouchRecursive(cmd.Context(), fsrc)
+			})
+			return
+		}
 		fsrc, srcFileName := cmd.NewFsDstFile(args)
 		cmd.Run(true, false, command, func() error {
 			return Touch(fsrc, srcFileName)
@@ -38,33 +68,93 @@ var commandDefintion = &cobra.Command{
 	},
 }
 
-//Touch create new file or change file modification time.
-func Touch(fsrc fs.Fs, srcFileName string) error {
-	timeAtr := time.Now()
-	if timeAsArgument != "" {
-		layout := defaultLayout
-		if len(timeAsArgument) == len(layoutDateWithTime) {
-			layout = layoutDateWithTime
+// timeLayouts are the layouts accepted by --timestamp
+var timeLayouts = []string{
+	defaultLayout,
+	layoutDate,
+	layoutDateWithTime,
+	time.RFC3339Nano,
+}
+
+// parseTimeArgument parses the --timestamp flag returning the current
+// time if it isn't set
+func parseTimeArgument() (time.Time, error) {
+	if timeAsArgument == "" {
+		return time.Now(), nil
+	}
+	if strings.HasPrefix(timeAsArgument, "@") {
+		seconds, err := strconv.ParseInt(timeAsArgument[1:], 10, 64)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, "failed to parse epoch time argument")
+		}
+		return time.Unix(seconds, 0), nil
+	}
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, timeAsArgument)
+		if err == nil {
+			return t, nil
 		}
-		timeAtrFromFlags, err := time.Parse(layout, timeAsArgument)
+	}
+	return time.Time{}, errors.Wrap(err, "failed to parse date/time argument")
+}
+
+// TouchRecursive sets the modification time of every file in f
+// which isn't excluded by the filters.
+func TouchRecursive(ctx context.Context, f fs.Fs) error {
+	timeAtr, err := parseTimeArgument()
+	if err != nil {
+		return err
+	}
+	var errorCount int
+	err = walk.Walk(ctx, f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
-			return errors.Wrap(err, "failed to parse date/time argument")
+			return err
 		}
-		timeAtr = timeAtrFromFlags
+		entries.ForObject(func(o fs.Object) {
+			if fs.Config.DryRun {
+				fs.Logf(o, "Not touching as --dry-run")
+				return
+			}
+			err := o.SetModTime(ctx, timeAtr)
+			if err != nil {
+				fs.Errorf(o, "touch: couldn't set mod time: %v", err)
+				errorCount++
+				return
+			}
+			fs.Debugf(o, "Touched")
+		})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return errors.Errorf("failed to set the mod time of %d files", errorCount)
+	}
+	return nil
+}
+
+//Touch create new file or change file modification time.
+func Touch(fsrc fs.Fs, srcFileName string) error {
+	timeAtr, err := parseTimeArgument()
+	if err != nil {
+		return err
 	}
-	file, err := fsrc.NewObject(srcFileName)
+	file, err := fsrc.NewObject(cmd.Context(), srcFileName)
 	if err != nil {
 		if !notCreateNewFile {
 			var buffer []byte
 			src := object.NewStaticObjectInfo(srcFileName, timeAtr, int64(len(buffer)), true, nil, fsrc)
-			_, err = fsrc.Put(bytes.NewBuffer(buffer), src)
+			_, err = fsrc.Put(cmd.Context(), bytes.NewBuffer(buffer), src)
 			if err != nil {
 				return err
 			}
 		}
 		return nil
 	}
-	err = file.SetModTime(timeAtr)
+	err = file.SetModTime(cmd.Context(), timeAtr)
 	if err != nil {
 		return errors.Wrap(err, "touch: couldn't set mod time")
 	}
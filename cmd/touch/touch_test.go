@@ -1,11 +1,13 @@
 package touch
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	_ "github.com/ncw/rclone/backend/local"
@@ -37,7 +39,7 @@ func TestTouchOneFile(t *testing.T) {
 
 	err := Touch(r.Fremote, "newFile")
 	require.NoError(t, err)
-	_, err = r.Fremote.NewObject("newFile")
+	_, err = r.Fremote.NewObject(context.Background(), "newFile")
 	require.NoError(t, err)
 }
 
@@ -48,7 +50,7 @@ func TestTouchWithNoCreateFlag(t *testing.T) {
 	notCreateNewFile = true
 	err := Touch(r.Fremote, "newFile")
 	require.NoError(t, err)
-	_, err = r.Fremote.NewObject("newFile")
+	_, err = r.Fremote.NewObject(context.Background(), "newFile")
 	require.Error(t, err)
 	notCreateNewFile = false
 }
@@ -117,3 +119,57 @@ func TestTouchCreateMultipleDirAndFile(t *testing.T) {
 	file1 := fstest.NewItem("a/b/c.txt", "", t1)
 	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1}, []string{"a", "a/b"}, fs.ModTimeNotSupported)
 }
+
+func TestTouchTimestampFormats(t *testing.T) {
+	defer func() {
+		timeAsArgument = ""
+	}()
+	for _, test := range []struct {
+		in   string
+		want time.Time
+	}{
+		{"121212", time.Date(2012, 12, 12, 0, 0, 0, 0, time.UTC)},
+		{"2019-03-04", time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{"2019-03-04T05:06:07", time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{"2019-03-04T05:06:07+01:00", time.Date(2019, 3, 4, 4, 6, 7, 0, time.UTC)},
+		{"2019-03-04T05:06:07.5Z", time.Date(2019, 3, 4, 5, 6, 7, 500000000, time.UTC)},
+		{"@1136214245", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+	} {
+		timeAsArgument = test.in
+		got, err := parseTimeArgument()
+		require.NoError(t, err, test.in)
+		assert.True(t, test.want.Equal(got), "%s: want %v got %v", test.in, test.want, got)
+	}
+	for _, in := range []string{"potato", "@potato", "2019-13-01"} {
+		timeAsArgument = in
+		_, err := parseTimeArgument()
+		assert.Error(t, err, in)
+	}
+}
+
+func TestTouchRecursive(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	defer func() {
+		timeAsArgument = ""
+	}()
+
+	file1 := r.WriteObject("a.txt", "aaa", t1)
+	file2 := r.WriteObject("dir/b.txt", "bbb", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	timeAsArgument = "2019-03-04T05:06:07"
+	t2 := fstest.Time("2019-03-04T05:06:07Z")
+
+	fs.Config.DryRun = true
+	err := TouchRecursive(context.Background(), r.Fremote)
+	fs.Config.DryRun = false
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	err = TouchRecursive(context.Background(), r.Fremote)
+	require.NoError(t, err)
+	file1.ModTime = t2
+	file2.ModTime = t2
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+}
@@ -58,7 +58,7 @@ func init() {
 	flags.BoolVarP(&opts.DirSort, "dirsfirst", "", false, "List directories before files (-U disables).")
 	flags.StringVarP(&sort, "sort", "", "", "Select sort: name,version,size,mtime,ctime.")
 	// Graphics
-	flags.BoolVarP(&opts.NoIndent, "noindent", "i", false, "Don't print indentation lines.")
+	flags.BoolVarP(&opts.NoIndent, "noindent", "", false, "Don't print indentation lines.")
 	flags.BoolVarP(&opts.Colorize, "color", "C", false, "Turn colorization on always.")
 }
 
@@ -117,7 +117,7 @@ short options as they conflict with rclone's short options.
 
 // Tree lists fsrc to outFile using the Options passed in
 func Tree(fsrc fs.Fs, outFile io.Writer, opts *tree.Options) error {
-	dirs, err := walk.NewDirTree(fsrc, "", false, opts.DeepLevel)
+	dirs, err := walk.NewDirTree(cmd.Context(), fsrc, "", false, opts.DeepLevel)
 	if err != nil {
 		return err
 	}
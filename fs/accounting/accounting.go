@@ -2,6 +2,7 @@
 package accounting
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"sync"
@@ -25,15 +26,18 @@ type Account struct {
 	close   io.Closer
 	size    int64
 	name    string
-	statmu  sync.Mutex         // Separate mutex for stat values.
-	bytes   int64              // Total number of bytes read
-	start   time.Time          // Start time of first read
-	lpTime  time.Time          // Time of last average measurement
-	lpBytes int                // Number of bytes read since last measurement
-	avg     ewma.MovingAverage // Moving average of last few measurements
-	closed  bool               // set if the file is closed
-	exit    chan struct{}      // channel that will be closed when transfer is finished
-	withBuf bool               // is using a buffered in
+	statmu  sync.Mutex               // Separate mutex for stat values.
+	bytes   int64                    // Total number of bytes read
+	start   time.Time                // Start time of first read
+	lpTime  time.Time                // Time of last average measurement
+	lpBytes int                      // Number of bytes read since last measurement
+	avg     ewma.MovingAverage       // Moving average of last few measurements
+	closed  bool                     // set if the file is closed
+	exit    chan struct{}            // channel that will be closed when transfer is finished
+	withBuf bool                     // is using a buffered in
+	buf     *asyncreader.AsyncReader // current buffer if any - under statmu
+	peakMem int64                    // most memory used by previous buffers - under statmu
+	stream  *Stream                  // share of the bandwidth limit
 }
 
 // NewAccountSizeName makes a Account reader for an io.ReadCloser of
@@ -48,6 +52,10 @@ func NewAccountSizeName(in io.ReadCloser, size int64, name string) *Account {
 		exit:   make(chan struct{}),
 		avg:    ewma.NewMovingAverage(),
 		lpTime: time.Now(),
+		stream: NewStream(),
+	}
+	if size >= 0 && size < int64(fs.Config.BwLimitSmallFiles) {
+		acc.stream.SetWeight(smallFileWeight)
 	}
 	go acc.averageLoop()
 	Stats.inProgress.set(acc.name, acc)
@@ -59,6 +67,48 @@ func NewAccount(in io.ReadCloser, obj fs.Object) *Account {
 	return NewAccountSizeName(in, obj.Size(), obj.Remote())
 }
 
+// Open opens obj for reading with the options given and returns a
+// reader which accounts the transfer, so the stats, progress and
+// --bwlimit apply to the download whichever backend obj comes from.
+//
+// The size accounted is the part of obj the options ask for.  If
+// --download-check is set and all of obj is read then its hash is
+// checked at the end.
+func Open(ctx context.Context, obj fs.Object, options ...fs.OpenOption) (*Account, error) {
+	in, err := obj.Open(ctx, options...)
+	if err != nil {
+		return nil, err
+	}
+	size := obj.Size()
+	whole := true
+	for _, option := range options {
+		switch x := option.(type) {
+		case *fs.RangeOption:
+			whole = false
+			if size >= 0 {
+				offset, limit := x.Decode(size)
+				if limit >= 0 && offset+limit <= size {
+					size = limit
+				} else {
+					size -= offset
+				}
+			}
+		case *fs.SeekOption:
+			whole = false
+			if size >= 0 {
+				size -= x.Offset
+			}
+		}
+	}
+	if size < 0 && obj.Size() >= 0 {
+		size = 0
+	}
+	if fs.Config.DownloadCheck && whole {
+		in = newCheckReader(in, obj)
+	}
+	return NewAccountSizeName(in, size, obj.Remote()), nil
+}
+
 // WithBuffer - If the file is above a certain size it adds an Async reader
 func (acc *Account) WithBuffer() *Account {
 	acc.withBuf = true
@@ -76,6 +126,12 @@ func (acc *Account) WithBuffer() *Account {
 		} else {
 			acc.in = rc
 			acc.close = rc
+			acc.statmu.Lock()
+			if acc.buf != nil && acc.buf.Peak() > acc.peakMem {
+				acc.peakMem = acc.buf.Peak()
+			}
+			acc.buf = rc
+			acc.statmu.Unlock()
 		}
 	}
 	return acc
@@ -138,6 +194,7 @@ func (acc *Account) read(in io.Reader, p []byte) (n int, err error) {
 	}
 	acc.statmu.Unlock()
 
+	waitIfPaused()
 	n, err = in.Read(p)
 
 	// Update Stats
@@ -148,7 +205,7 @@ func (acc *Account) read(in io.Reader, p []byte) (n int, err error) {
 
 	Stats.Bytes(int64(n))
 
-	limitBandwidth(n)
+	limitBandwidth(acc.stream, n)
 	return
 }
 
@@ -169,9 +226,27 @@ func (acc *Account) Close() error {
 	acc.closed = true
 	close(acc.exit)
 	Stats.inProgress.clear(acc.name)
+	if peak := acc.PeakMemory(); peak > 0 {
+		Stats.TransferMemory(acc.name, peak)
+		if fs.Config.NoSpool {
+			fs.Debugf(acc.name, "Peak buffer memory %v", fs.SizeSuffix(peak))
+		}
+	}
 	return acc.close.Close()
 }
 
+// PeakMemory returns the most memory the buffers of this transfer
+// have used at once
+func (acc *Account) PeakMemory() int64 {
+	acc.statmu.Lock()
+	defer acc.statmu.Unlock()
+	peak := acc.peakMem
+	if acc.buf != nil && acc.buf.Peak() > peak {
+		peak = acc.buf.Peak()
+	}
+	return peak
+}
+
 // progress returns bytes read as well as the size.
 // Size can be <= 0 if the size is unknown.
 func (acc *Account) progress() (bytes, size int64) {
@@ -260,12 +335,16 @@ func (acc *Account) String() string {
 
 	done := fmt.Sprintf("%2d%% /%s", percentageDone, fs.SizeSuffix(b))
 
-	return fmt.Sprintf("%45s: %s, %s/s, %s",
+	out := fmt.Sprintf("%45s: %s, %s/s, %s",
 		string(name),
 		done,
 		fs.SizeSuffix(cur),
 		etas,
 	)
+	if fs.Config.NoSpool {
+		out += fmt.Sprintf(", buffer %s", fs.SizeSuffix(acc.PeakMemory()))
+	}
+	return out
 }
 
 // OldStream returns the top io.Reader
@@ -2,11 +2,14 @@ package accounting
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
 
+	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/asyncreader"
 	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
@@ -42,6 +45,49 @@ func TestNewAccount(t *testing.T) {
 	assert.Nil(t, Stats.inProgress.get("test"))
 }
 
+// openObject is a mock object of size 100 which can be opened
+type openObject struct {
+	mockobject.Object
+	err error
+}
+
+func (o openObject) Size() int64 { return 100 }
+
+func (o openObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	return ioutil.NopCloser(bytes.NewBuffer(make([]byte, 100))), nil
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		options []fs.OpenOption
+		want    int64
+	}{
+		{nil, 100},
+		{[]fs.OpenOption{&fs.SeekOption{Offset: 10}}, 90},
+		{[]fs.OpenOption{&fs.RangeOption{Start: 10, End: 19}}, 10},
+		{[]fs.OpenOption{&fs.RangeOption{Start: 90, End: 199}}, 10},
+		{[]fs.OpenOption{&fs.RangeOption{Start: 10, End: -1}}, 90},
+		{[]fs.OpenOption{&fs.RangeOption{Start: -1, End: 30}}, 30},
+		{[]fs.OpenOption{&fs.SeekOption{Offset: 200}}, 0},
+	} {
+		acc, err := Open(ctx, openObject{Object: "test"}, test.options...)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, acc.size, fs.OpenOptionHeaders(test.options))
+		assert.Equal(t, acc, Stats.inProgress.get("test"))
+		require.NoError(t, acc.Close())
+		assert.Nil(t, Stats.inProgress.get("test"))
+	}
+
+	openErr := errors.New("open failed")
+	_, err := Open(ctx, openObject{Object: "test", err: openErr})
+	assert.Equal(t, openErr, err)
+	assert.Nil(t, Stats.inProgress.get("test"))
+}
+
 func TestAccountWithBuffer(t *testing.T) {
 	in := ioutil.NopCloser(bytes.NewBuffer([]byte{1}))
 
@@ -60,6 +106,29 @@ func TestAccountWithBuffer(t *testing.T) {
 	assert.NoError(t, acc.Close())
 }
 
+func TestAccountPeakMemory(t *testing.T) {
+	Stats.ResetCounters()
+	defer Stats.ResetCounters()
+
+	in := ioutil.NopCloser(bytes.NewBuffer(make([]byte, 100)))
+	acc := NewAccountSizeName(in, -1, "test")
+	assert.Equal(t, int64(0), acc.PeakMemory())
+	acc.WithBuffer()
+	_, err := ioutil.ReadAll(acc)
+	require.NoError(t, err)
+	peak := acc.PeakMemory()
+	assert.True(t, peak >= asyncreader.BufferSize, peak)
+	assert.NoError(t, acc.Close())
+	assert.Equal(t, peak, Stats.RemoteStats()["peakMemory"])
+	assert.Equal(t, "test", Stats.RemoteStats()["peakMemoryName"])
+
+	// only the biggest is kept
+	Stats.TransferMemory("small", 1)
+	assert.Equal(t, "test", Stats.RemoteStats()["peakMemoryName"])
+	Stats.TransferMemory("big", peak+1)
+	assert.Equal(t, "big", Stats.RemoteStats()["peakMemoryName"])
+}
+
 func TestAccountGetUpdateReader(t *testing.T) {
 	in := ioutil.NopCloser(bytes.NewBuffer([]byte{1}))
 	acc := NewAccountSizeName(in, 1, "test")
@@ -34,3 +34,20 @@ func startSignalHandler() {
 		}
 	}()
 }
+
+// startPauseSignalHandler sets a signal handler to pause the transfers
+// on SIGTSTP and resume them on SIGCONT.
+func startPauseSignalHandler() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTSTP, syscall.SIGCONT)
+
+	go func() {
+		for sig := range signals {
+			if sig == syscall.SIGTSTP {
+				Pause()
+			} else {
+				Resume()
+			}
+		}
+	}()
+}
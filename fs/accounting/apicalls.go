@@ -0,0 +1,203 @@
+// Count the API transactions made to each remote
+
+package accounting
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ncw/rclone/fs/rc"
+	"github.com/pkg/errors"
+)
+
+// Classes of API calls
+const (
+	APIList   = "list"
+	APIGet    = "get"
+	APIPut    = "put"
+	APIDelete = "delete"
+)
+
+// apiClasses are the classes of API calls in the order they are shown
+var apiClasses = []string{APIList, APIGet, APIPut, APIDelete}
+
+// apiClassKey is the context key for the API call class
+type apiClassKey struct{}
+
+// WithAPIClass returns a copy of ctx which marks any API calls made
+// with it as being of class, eg APIList
+func WithAPIClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, apiClassKey{}, class)
+}
+
+// APIClass returns the class of the API call req.
+//
+// This is the class set with WithAPIClass on the context of the
+// request if any, otherwise it is worked out from the HTTP method.
+func APIClass(req *http.Request) string {
+	if class, ok := req.Context().Value(apiClassKey{}).(string); ok {
+		return class
+	}
+	switch req.Method {
+	case "PUT", "POST", "PATCH":
+		return APIPut
+	case "DELETE":
+		return APIDelete
+	}
+	return APIGet
+}
+
+// APICalls is the number of API calls made of each class
+type APICalls map[string]int64
+
+// String returns the calls in a human readable form
+func (calls APICalls) String() string {
+	var out []string
+	for _, class := range apiClasses {
+		out = append(out, fmt.Sprintf("%s %d", class, calls[class]))
+	}
+	return strings.Join(out, ", ")
+}
+
+// APICost is the cost per 1000 API calls of each class
+type APICost map[string]float64
+
+// ParseAPICost parses a cost model in the form used by the api_cost
+// config option, eg "list=0.005,get=0.0004,put=0.005,delete=0"
+//
+// Classes which aren't mentioned cost nothing.
+func ParseAPICost(s string) (APICost, error) {
+	cost := APICost{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		equals := strings.IndexRune(part, '=')
+		if equals < 0 {
+			return nil, errors.Errorf("bad API cost %q: expecting class=cost", part)
+		}
+		class := strings.TrimSpace(part[:equals])
+		found := false
+		for _, c := range apiClasses {
+			if c == class {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("bad API cost %q: unknown class %q, expecting one of %s", part, class, strings.Join(apiClasses, ", "))
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(part[equals+1:]), 64)
+		if err != nil || value < 0 {
+			return nil, errors.Errorf("bad API cost %q: cost must be a number >= 0", part)
+		}
+		cost[class] = value
+	}
+	return cost, nil
+}
+
+// Cost returns the cost of calls
+func (cost APICost) Cost(calls APICalls) (total float64) {
+	for class, n := range calls {
+		total += cost[class] * float64(n) / 1000
+	}
+	return total
+}
+
+// APICall counts a single API call of class to remote
+func (s *StatsInfo) APICall(remote string, class string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.apiCalls == nil {
+		s.apiCalls = make(map[string]APICalls)
+	}
+	calls := s.apiCalls[remote]
+	if calls == nil {
+		calls = make(APICalls, len(apiClasses))
+		s.apiCalls[remote] = calls
+	}
+	calls[class]++
+}
+
+// SetAPICost sets the cost model used to estimate the cost of the
+// API calls to remote
+func (s *StatsInfo) SetAPICost(remote string, cost APICost) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.apiCosts == nil {
+		s.apiCosts = make(map[string]APICost)
+	}
+	s.apiCosts[remote] = cost
+}
+
+// GetAPICalls returns a copy of the API calls made to each remote
+func (s *StatsInfo) GetAPICalls() map[string]APICalls {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	out := make(map[string]APICalls, len(s.apiCalls))
+	for remote, calls := range s.apiCalls {
+		c := make(APICalls, len(calls))
+		for class, n := range calls {
+			c[class] = n
+		}
+		out[remote] = c
+	}
+	return out
+}
+
+// GetAPICost returns the estimated cost of the API calls made so far
+// and whether any of the remotes used have a cost model
+func (s *StatsInfo) GetAPICost() (total float64, ok bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.apiCost()
+}
+
+// apiCost returns the estimated cost of the API calls - call with
+// the lock held
+func (s *StatsInfo) apiCost() (total float64, ok bool) {
+	for remote, calls := range s.apiCalls {
+		if cost, found := s.apiCosts[remote]; found {
+			total += cost.Cost(calls)
+			ok = true
+		}
+	}
+	return total, ok
+}
+
+// apiCallsString returns the API calls made to each remote for the
+// stats - call with the lock held
+func (s *StatsInfo) apiCallsString() string {
+	remotes := make([]string, 0, len(s.apiCalls))
+	for remote := range s.apiCalls {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	var out []string
+	for _, remote := range remotes {
+		calls := s.apiCalls[remote]
+		line := fmt.Sprintf(" * %s: %v", remote, calls)
+		if cost, ok := s.apiCosts[remote]; ok {
+			line += fmt.Sprintf(" (cost %.4f)", cost.Cost(calls))
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
+
+// APIStats returns the API calls made to each remote and the
+// estimated cost, if known, for reporting as JSON
+func (s *StatsInfo) APIStats() rc.Params {
+	out := rc.Params{
+		"apiCalls": s.GetAPICalls(),
+	}
+	if cost, ok := s.GetAPICost(); ok {
+		out["apiCost"] = cost
+	}
+	return out
+}
@@ -0,0 +1,85 @@
+package accounting
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIClass(t *testing.T) {
+	for _, test := range []struct {
+		method string
+		class  string
+		want   string
+	}{
+		{"GET", "", APIGet},
+		{"HEAD", "", APIGet},
+		{"PUT", "", APIPut},
+		{"POST", "", APIPut},
+		{"PATCH", "", APIPut},
+		{"DELETE", "", APIDelete},
+		{"GET", APIList, APIList},
+		{"POST", APIDelete, APIDelete},
+	} {
+		req, err := http.NewRequest(test.method, "http://example.com/", nil)
+		require.NoError(t, err)
+		if test.class != "" {
+			req = req.WithContext(WithAPIClass(context.Background(), test.class))
+		}
+		assert.Equal(t, test.want, APIClass(req), test.method+" "+test.class)
+	}
+}
+
+func TestParseAPICost(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    APICost
+		wantErr bool
+	}{
+		{"", APICost{}, false},
+		{"list=0.005", APICost{APIList: 0.005}, false},
+		{" list = 0.005 , get=0.0004,put=5,delete=0 ", APICost{APIList: 0.005, APIGet: 0.0004, APIPut: 5, APIDelete: 0}, false},
+		{"list", nil, true},
+		{"copy=1", nil, true},
+		{"get=potato", nil, true},
+		{"get=-1", nil, true},
+	} {
+		got, err := ParseAPICost(test.in)
+		if test.wantErr {
+			assert.Error(t, err, test.in)
+		} else {
+			assert.NoError(t, err, test.in)
+		}
+		assert.Equal(t, test.want, got, test.in)
+	}
+}
+
+func TestStatsAPICalls(t *testing.T) {
+	s := NewStats()
+	_, ok := s.GetAPICost()
+	assert.False(t, ok)
+
+	for i := 0; i < 2000; i++ {
+		s.APICall("a", APIList)
+	}
+	s.APICall("a", APIPut)
+	s.APICall("b", APIGet)
+	assert.Equal(t, map[string]APICalls{
+		"a": {APIList: 2000, APIPut: 1},
+		"b": {APIGet: 1},
+	}, s.GetAPICalls())
+	assert.Contains(t, s.String(), " * a: list 2000, get 0, put 1, delete 0\n * b: list 0, get 1, put 0, delete 0\n")
+
+	s.SetAPICost("a", APICost{APIList: 0.005, APIPut: 1})
+	cost, ok := s.GetAPICost()
+	assert.True(t, ok)
+	assert.InDelta(t, 0.011, cost, 1e-9)
+	assert.Contains(t, s.String(), "(cost 0.0110)")
+	assert.Equal(t, cost, s.APIStats()["apiCost"])
+
+	s.ResetCounters()
+	assert.Equal(t, map[string]APICalls{}, s.GetAPICalls())
+}
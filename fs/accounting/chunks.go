@@ -0,0 +1,209 @@
+// Time the chunks of chunked uploads to each remote
+
+package accounting
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// maxChunkSamples is the number of chunk times kept for each remote
+// to work out the median from, so the memory used doesn't grow with
+// the number of chunks
+const maxChunkSamples = 1000
+
+// chunkTimes are the timings of the chunks uploaded to one remote
+type chunkTimes struct {
+	chunks  int64           // number of chunks uploaded
+	samples []time.Duration // random sample of at most maxChunkSamples chunk times
+	retries int64           // total retries of all the chunks
+	min     time.Duration   // time of the fastest chunk
+	max     time.Duration   // time of the slowest chunk
+	slowest string          // description of the slowest chunk
+}
+
+// durations is a sortable slice of time.Duration
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// ChunkStats summarises the chunks uploaded to a remote
+type ChunkStats struct {
+	Chunks  int64         // number of chunks uploaded
+	Retries int64         // number of retries of those chunks
+	Min     time.Duration // time of the fastest chunk
+	Median  time.Duration // median chunk time, estimated if there are lots of chunks
+	Max     time.Duration // time of the slowest chunk
+	Slowest string        // description of the slowest chunk
+}
+
+// String returns the stats in a human readable form
+func (cs ChunkStats) String() string {
+	return fmt.Sprintf("%d chunks, %d retries, min %v, median %v, max %v (%s)",
+		cs.Chunks, cs.Retries, cs.Min, cs.Median, cs.Max, cs.Slowest)
+}
+
+// add records a chunk described by what which took elapsed
+func (ct *chunkTimes) add(what string, elapsed time.Duration, retries int) {
+	if ct.chunks == 0 || elapsed < ct.min {
+		ct.min = elapsed
+	}
+	if ct.chunks == 0 || elapsed > ct.max {
+		ct.max = elapsed
+		ct.slowest = what
+	}
+	ct.chunks++
+	ct.retries += int64(retries)
+	// Reservoir sample the times so each chunk is equally likely
+	// to be in the sample
+	if len(ct.samples) < maxChunkSamples {
+		ct.samples = append(ct.samples, elapsed)
+	} else if i := rand.Int63n(ct.chunks); i < maxChunkSamples {
+		ct.samples[i] = elapsed
+	}
+}
+
+// summary works out the ChunkStats for ct
+func (ct *chunkTimes) summary() (cs ChunkStats) {
+	cs.Chunks = ct.chunks
+	cs.Retries = ct.retries
+	cs.Slowest = ct.slowest
+	n := len(ct.samples)
+	if n == 0 {
+		return cs
+	}
+	sorted := make(durations, n)
+	copy(sorted, ct.samples)
+	sort.Sort(sorted)
+	cs.Min = ct.min
+	cs.Max = ct.max
+	if n%2 == 1 {
+		cs.Median = sorted[n/2]
+	} else {
+		cs.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return cs
+}
+
+// Chunk records that a chunk described by what took elapsed to
+// upload to remote with retries retries
+func (s *StatsInfo) Chunk(remote string, what string, elapsed time.Duration, retries int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.chunks == nil {
+		s.chunks = make(map[string]*chunkTimes)
+	}
+	ct := s.chunks[remote]
+	if ct == nil {
+		ct = &chunkTimes{}
+		s.chunks[remote] = ct
+	}
+	ct.add(what, elapsed, retries)
+}
+
+// GetChunkStats returns a summary of the chunks uploaded to each
+// remote
+func (s *StatsInfo) GetChunkStats() map[string]ChunkStats {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	out := make(map[string]ChunkStats, len(s.chunks))
+	for remote, ct := range s.chunks {
+		out[remote] = ct.summary()
+	}
+	return out
+}
+
+// chunksString returns the chunk stats of each remote for the stats
+//
+// call with the lock held
+func (s *StatsInfo) chunksString() string {
+	remotes := make([]string, 0, len(s.chunks))
+	for remote := range s.chunks {
+		remotes = append(remotes, remote)
+	}
+	sort.Strings(remotes)
+	var out []string
+	for _, remote := range remotes {
+		out = append(out, fmt.Sprintf(" * %s: %v", remote, s.chunks[remote].summary()))
+	}
+	return strings.Join(out, "\n")
+}
+
+// chunkStatsJSON is the form of ChunkStats returned by core/stats
+type chunkStatsJSON struct {
+	Chunks  int64   `json:"chunks"`
+	Retries int64   `json:"retries"`
+	Min     float64 `json:"min"`
+	Median  float64 `json:"median"`
+	Max     float64 `json:"max"`
+	Slowest string  `json:"slowest"`
+}
+
+// chunkStats returns the chunk stats of each remote for reporting as
+// JSON, with the times in seconds
+//
+// call with the lock held
+func (s *StatsInfo) chunkStats() map[string]chunkStatsJSON {
+	out := make(map[string]chunkStatsJSON, len(s.chunks))
+	for remote, ct := range s.chunks {
+		cs := ct.summary()
+		out[remote] = chunkStatsJSON{
+			Chunks:  cs.Chunks,
+			Retries: cs.Retries,
+			Min:     cs.Min.Seconds(),
+			Median:  cs.Median.Seconds(),
+			Max:     cs.Max.Seconds(),
+			Slowest: cs.Slowest,
+		}
+	}
+	return out
+}
+
+// ChunkTimer times the upload of one chunk including its retries
+type ChunkTimer struct {
+	remote string      // name of the remote being uploaded to
+	o      interface{} // object the chunk is part of
+	part   int64       // number of the chunk
+	start  time.Time   // when the first try started
+	tries  int         // number of tries so far
+}
+
+// NewChunkTimer starts timing the upload of chunk part of o to the
+// remote called remote
+func NewChunkTimer(remote string, o interface{}, part int64) *ChunkTimer {
+	return &ChunkTimer{
+		remote: remote,
+		o:      o,
+		part:   part,
+		start:  time.Now(),
+	}
+}
+
+// Try should be called at the start of each try at uploading the
+// chunk
+func (t *ChunkTimer) Try() {
+	t.tries++
+}
+
+// Done records the time the chunk took in the stats, if it was
+// uploaded successfully, and logs it at debug level
+func (t *ChunkTimer) Done(err error) {
+	elapsed := time.Since(t.start)
+	retries := t.tries - 1
+	if retries < 0 {
+		retries = 0
+	}
+	if err != nil {
+		fs.Debugf(t.o, "Chunk %d failed after %v with %d retries: %v", t.part, elapsed, retries, err)
+		return
+	}
+	fs.Debugf(t.o, "Chunk %d uploaded in %v with %d retries", t.part, elapsed, retries)
+	Stats.Chunk(t.remote, fmt.Sprintf("%v chunk %d", t.o, t.part), elapsed, retries)
+}
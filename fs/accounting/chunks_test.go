@@ -0,0 +1,101 @@
+package accounting
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkStats(t *testing.T) {
+	s := NewStats()
+	assert.Equal(t, map[string]ChunkStats{}, s.GetChunkStats())
+	assert.NotContains(t, s.String(), "Chunks:")
+
+	s.Chunk("remote", "file chunk 1", 2*time.Second, 0)
+	s.Chunk("remote", "file chunk 2", 9*time.Second, 2)
+	s.Chunk("remote", "file chunk 3", 1*time.Second, 0)
+	s.Chunk("remote", "file chunk 4", 4*time.Second, 1)
+	s.Chunk("other", "other chunk 1", 3*time.Second, 0)
+
+	assert.Equal(t, map[string]ChunkStats{
+		"remote": {
+			Chunks:  4,
+			Retries: 3,
+			Min:     1 * time.Second,
+			Median:  3 * time.Second,
+			Max:     9 * time.Second,
+			Slowest: "file chunk 2",
+		},
+		"other": {
+			Chunks:  1,
+			Min:     3 * time.Second,
+			Median:  3 * time.Second,
+			Max:     3 * time.Second,
+			Slowest: "other chunk 1",
+		},
+	}, s.GetChunkStats())
+
+	out := s.String()
+	assert.Contains(t, out, "Chunks:\n * other: 1 chunks")
+	assert.Contains(t, out, " * remote: 4 chunks, 3 retries, min 1s, median 3s, max 9s (file chunk 2)")
+	assert.True(t, strings.Index(out, "* other") < strings.Index(out, "* remote"))
+
+	stats := s.RemoteStats()["chunks"].(map[string]chunkStatsJSON)
+	assert.Equal(t, 9.0, stats["remote"].Max)
+	assert.Equal(t, int64(3), stats["remote"].Retries)
+}
+
+func TestChunkTimer(t *testing.T) {
+	oldStats := Stats
+	Stats = NewStats()
+	defer func() { Stats = oldStats }()
+
+	timer := NewChunkTimer("remote", "file", 3)
+	timer.Try()
+	timer.Try()
+	timer.Done(nil)
+
+	timer = NewChunkTimer("remote", "file", 4)
+	timer.Try()
+	timer.Done(errors.New("failed"))
+
+	cs := Stats.GetChunkStats()["remote"]
+	assert.Equal(t, int64(1), cs.Chunks)
+	assert.Equal(t, int64(1), cs.Retries)
+	assert.Equal(t, "file chunk 3", cs.Slowest)
+}
+
+func TestChunkStatsSampled(t *testing.T) {
+	s := NewStats()
+	n := 10 * maxChunkSamples
+	for i := 1; i <= n; i++ {
+		s.Chunk("remote", "chunk", time.Duration(i)*time.Millisecond, 0)
+	}
+	assert.Len(t, s.chunks["remote"].samples, maxChunkSamples)
+	cs := s.GetChunkStats()["remote"]
+	assert.Equal(t, int64(n), cs.Chunks)
+	assert.Equal(t, time.Millisecond, cs.Min)
+	assert.Equal(t, time.Duration(n)*time.Millisecond, cs.Max)
+	// The median is estimated from the sample
+	assert.InDelta(t, float64(n/2), float64(cs.Median/time.Millisecond), float64(n/10))
+}
+
+func TestRemoteStatsChunksWhileWriting(t *testing.T) {
+	s := NewStats()
+	s.Chunk("remote", "chunk", time.Second, 0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			s.Chunk("remote", "chunk", time.Second, 0)
+			s.Bytes(1)
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		_ = s.RemoteStats()
+	}
+	<-done
+}
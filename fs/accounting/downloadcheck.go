@@ -0,0 +1,74 @@
+// Check the hash of downloads as they are read
+
+package accounting
+
+import (
+	"io"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/pkg/errors"
+)
+
+// checkReader checks the hash of the data read through it against
+// the hash the remote has when it gets to the end
+type checkReader struct {
+	io.ReadCloser
+	o      fs.Object         // object being downloaded
+	ht     hash.Type         // type of hash being checked
+	want   string            // hash the remote has
+	hasher *hash.MultiHasher // hash of the data read so far
+	done   bool              // set when the hash has been checked
+}
+
+// newCheckReader returns in wrapped with a checkReader if the remote
+// has a hash for o, otherwise in.
+//
+// Remotes which work out hashes by reading the object aren't checked
+// as that would read it twice.
+func newCheckReader(in io.ReadCloser, o fs.Object) io.ReadCloser {
+	info := o.Fs()
+	if info == nil {
+		return in
+	}
+	if info.Features().SlowHash {
+		return in
+	}
+	ht := info.Hashes().GetOne()
+	if ht == hash.None {
+		return in
+	}
+	want, err := o.Hash(ht)
+	if err != nil || want == "" {
+		fs.Debugf(o, "Not checking download as no %v hash: %v", ht, err)
+		return in
+	}
+	hasher, err := hash.NewMultiHasherTypes(hash.NewHashSet(ht))
+	if err != nil {
+		return in
+	}
+	return &checkReader{
+		ReadCloser: in,
+		o:          o,
+		ht:         ht,
+		want:       want,
+		hasher:     hasher,
+	}
+}
+
+// Read bytes checking the hash at the end
+func (cr *checkReader) Read(p []byte) (n int, err error) {
+	n, err = cr.ReadCloser.Read(p)
+	_, _ = cr.hasher.Write(p[:n])
+	if err == io.EOF && !cr.done {
+		cr.done = true
+		got := cr.hasher.Sums()[cr.ht]
+		if !hash.Equals(cr.want, got) {
+			Stats.Corrupted()
+			err = fserrors.RetryError(errors.Errorf("corrupted on download: %v hash differ %q vs %q", cr.ht, cr.want, got))
+			fs.Errorf(cr.o, "%v", err)
+		}
+	}
+	return n, err
+}
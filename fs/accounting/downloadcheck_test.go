@@ -0,0 +1,107 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// md5Info is an fs.Info which supports MD5
+type md5Info struct{}
+
+func (md5Info) Name() string             { return "md5" }
+func (md5Info) Root() string             { return "" }
+func (md5Info) String() string           { return "md5" }
+func (md5Info) Precision() time.Duration { return time.Second }
+func (md5Info) Hashes() hash.Set         { return hash.Set(hash.MD5) }
+func (md5Info) Features() *fs.Features   { return &fs.Features{} }
+
+// hashObject is a mock object with contents and an MD5 hash
+type hashObject struct {
+	mockobject.Object
+	contents []byte
+	md5      string
+}
+
+func (o hashObject) Fs() fs.Info { return md5Info{} }
+
+func (o hashObject) Size() int64 { return int64(len(o.contents)) }
+
+func (o hashObject) Hash(ht hash.Type) (string, error) { return o.md5, nil }
+
+func (o hashObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewBuffer(o.contents)), nil
+}
+
+// slowHashInfo is an md5Info which reads objects to work out hashes
+type slowHashInfo struct{ md5Info }
+
+func (slowHashInfo) Features() *fs.Features { return &fs.Features{SlowHash: true} }
+
+// slowHashObject is a hashObject on a slowHashInfo
+type slowHashObject struct{ hashObject }
+
+func (o slowHashObject) Fs() fs.Info { return slowHashInfo{} }
+
+func (o slowHashObject) Hash(ht hash.Type) (string, error) {
+	panic("hash shouldn't be read")
+}
+
+func TestOpenDownloadCheck(t *testing.T) {
+	ctx := context.Background()
+	oldStats := Stats
+	Stats = NewStats()
+	oldDownloadCheck := fs.Config.DownloadCheck
+	defer func() {
+		Stats = oldStats
+		fs.Config.DownloadCheck = oldDownloadCheck
+	}()
+
+	contents := []byte("hello world")
+	sum := md5.Sum(contents)
+	good := hashObject{Object: "good", contents: contents, md5: hex.EncodeToString(sum[:])}
+	bad := hashObject{Object: "bad", contents: contents, md5: "0123456789abcdef0123456789abcdef"}
+
+	read := func(o fs.Object, options ...fs.OpenOption) error {
+		in, err := Open(ctx, o, options...)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, in.Close()) }()
+		_, err = ioutil.ReadAll(in)
+		return err
+	}
+
+	// Not checked unless asked for
+	fs.Config.DownloadCheck = false
+	assert.NoError(t, read(bad))
+
+	fs.Config.DownloadCheck = true
+	assert.NoError(t, read(good))
+	assert.Equal(t, int64(0), Stats.GetCorrupted())
+
+	err := read(bad)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "corrupted on download")
+	assert.True(t, fserrors.IsRetryError(err))
+	assert.Equal(t, int64(1), Stats.GetCorrupted())
+	assert.Contains(t, Stats.String(), "Corrupted:")
+
+	// Partial reads can't be checked
+	assert.NoError(t, read(bad, &fs.SeekOption{Offset: 1}))
+	assert.Equal(t, int64(1), Stats.GetCorrupted())
+
+	// Remotes which read the object to hash it aren't checked
+	assert.NoError(t, read(slowHashObject{bad}))
+	assert.Equal(t, int64(1), Stats.GetCorrupted())
+}
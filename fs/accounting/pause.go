@@ -0,0 +1,94 @@
+package accounting
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/rc"
+)
+
+// Globals
+var (
+	pauseMu sync.Mutex    // protects paused
+	paused  chan struct{} // closed when the transfers are resumed, nil if not paused
+)
+
+// Pause stops all the transfers reading or writing any more data
+// and stops any new transfers or checks starting until Resume is
+// called.  The transfers stay open so they carry on from where they
+// were when resumed.
+func Pause() {
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+	if paused != nil {
+		return
+	}
+	paused = make(chan struct{})
+	fs.Logf(nil, "Transfers paused")
+}
+
+// Resume carries on with the transfers stopped by Pause
+func Resume() {
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+	if paused == nil {
+		return
+	}
+	close(paused)
+	paused = nil
+	fs.Logf(nil, "Transfers resumed")
+}
+
+// IsPaused returns whether the transfers are paused
+func IsPaused() bool {
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+	return paused != nil
+}
+
+// waitIfPaused blocks while the transfers are paused
+func waitIfPaused() {
+	pauseMu.Lock()
+	ch := paused
+	pauseMu.Unlock()
+	if ch != nil {
+		<-ch
+	}
+}
+
+// StartPauseHandler starts the signal handler which pauses the
+// transfers on SIGTSTP and resumes them on SIGCONT if --pause-signals
+// is set.
+func StartPauseHandler() {
+	if fs.Config.PauseSignals {
+		startPauseSignalHandler()
+	}
+}
+
+// Remote control for pausing the transfers
+func init() {
+	rc.Add(rc.Call{
+		Path: "core/pause",
+		Fn: func(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+			Pause()
+			return rc.Params{"paused": true}, nil
+		},
+		Title: "Pause all the transfers.",
+		Help: `
+This stops all the transfers reading or writing any more data, and
+any new transfers or checks starting, until core/resume is called.  The transfers are kept open so they carry on
+from where they were when resumed.  Note that some remotes may time
+out a transfer which is paused for a long time, in which case it will
+be retried.
+`,
+	})
+	rc.Add(rc.Call{
+		Path: "core/resume",
+		Fn: func(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+			Resume()
+			return rc.Params{"paused": false}, nil
+		},
+		Title: "Resume the transfers paused by core/pause.",
+	})
+}
@@ -0,0 +1,70 @@
+package accounting
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseResume(t *testing.T) {
+	assert.False(t, IsPaused())
+	Pause()
+	Pause()
+	assert.True(t, IsPaused())
+
+	acc := NewAccountSizeName(ioutil.NopCloser(bytes.NewBufferString("potato")), 6, "test")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buf := make([]byte, 6)
+		n, err := acc.Read(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "potato", string(buf[:n]))
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("read while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	Resume()
+	Resume()
+	assert.False(t, IsPaused())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("read not resumed")
+	}
+}
+
+func TestPauseTransferringChecking(t *testing.T) {
+	s := NewStats()
+	Pause()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Transferring("one")
+		s.Checking("two")
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("transfer started while paused")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	Resume()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("transfer not resumed")
+	}
+	s.DoneTransferring("one", true)
+	s.DoneChecking("two")
+	assert.Equal(t, int64(1), s.GetTransfers())
+}
@@ -2,12 +2,14 @@ package accounting
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/rc"
 )
 
 var (
@@ -18,6 +20,71 @@ var (
 func init() {
 	// Set the function pointer up in fs
 	fs.CountError = Stats.Error
+
+	rc.Add(rc.Call{
+		Path:  "core/stats",
+		Fn:    rcStats,
+		Title: "Returns stats about current transfers.",
+		Help: `
+This returns the stats of the transfers so far, including the number
+of API calls made to each remote and their estimated cost if an
+api_cost is configured for the remote, eg
+
+    {
+        "bytes": 12345,
+        "errors": 0,
+        "checks": 10,
+        "transfers": 2,
+        "deletes": 0,
+        "corrupted": 0,
+        "skippedDirs": 0,
+        "elapsedTime": 12.3,
+        "apiCalls": {
+            "s3": { "list": 3, "get": 10, "put": 2, "delete": 0 }
+        },
+        "apiCost": 0.0001,
+        "chunks": {
+            "b2": { "chunks": 12, "retries": 1, "min": 1.2, "median": 1.9, "max": 8.5, "slowest": "file.bin chunk 7" }
+        },
+        "peakMemory": 16777216,
+        "peakMemoryName": "file.bin"
+    }
+
+apiCost is only returned if one of the remotes used has an api_cost.
+chunks is only returned if chunked uploads were done, with the times
+in seconds.  peakMemory is the most memory in bytes the read ahead
+buffer of a single transfer used, and peakMemoryName that transfer.
+`,
+	})
+}
+
+// rcStats returns the stats as rc.Params
+func rcStats(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	return Stats.RemoteStats(), nil
+}
+
+// RemoteStats returns the stats in the format returned by the
+// core/stats remote control command
+func (s *StatsInfo) RemoteStats() (out rc.Params) {
+	out = s.APIStats()
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	out["bytes"] = s.bytes
+	out["errors"] = s.errors
+	out["checks"] = s.checks
+	out["transfers"] = s.transfers
+	out["deletes"] = s.deletes
+	out["corrupted"] = s.corrupted
+	out["skippedDirs"] = s.skippedDirs
+	out["elapsedTime"] = time.Since(s.start).Seconds()
+	if len(s.chunks) > 0 {
+		out["chunks"] = s.chunkStats()
+	}
+	if s.peakMemory > 0 {
+		out["peakMemory"] = s.peakMemory
+		out["peakMemoryName"] = s.peakName
+	}
+	return out
 }
 
 // StatsInfo accounts all transfers
@@ -31,8 +98,17 @@ type StatsInfo struct {
 	transfers    int64
 	transferring stringSet
 	deletes      int64
+	corrupted    int64
+	skippedDirs  int64
+	lastSkipErr  error
 	start        time.Time
 	inProgress   *inProgress
+	apiCalls     map[string]APICalls    // API calls made to each remote
+	apiCosts     map[string]APICost     // cost model for each remote, if set
+	chunks       map[string]*chunkTimes // times of chunks uploaded to each remote
+	peakMemory   int64                  // most buffer memory used by a transfer
+	peakName     string                 // the transfer which used it
+	queued       int64                  // transfers queued for --max-files
 }
 
 // NewStats cretates an initialised StatsInfo
@@ -74,6 +150,24 @@ Elapsed time:  %10v
 		s.checks,
 		s.transfers,
 		dtRounded)
+	if s.corrupted > 0 {
+		fmt.Fprintf(buf, "Corrupted:     %10d\n", s.corrupted)
+	}
+	if s.skippedDirs > 0 {
+		fmt.Fprintf(buf, "Skipped dirs:  %10d\n", s.skippedDirs)
+	}
+	if fs.Config.NoSpool && s.peakMemory > 0 {
+		fmt.Fprintf(buf, "Peak buffer:   %10s (%s)\n", fs.SizeSuffix(s.peakMemory), s.peakName)
+	}
+	if cost, ok := s.apiCost(); ok {
+		fmt.Fprintf(buf, "API cost:      %10.4f\n", cost)
+	}
+	if len(s.apiCalls) > 0 {
+		fmt.Fprintf(buf, "API calls:\n%s\n", s.apiCallsString())
+	}
+	if len(s.chunks) > 0 {
+		fmt.Fprintf(buf, "Chunks:\n%s\n", s.chunksString())
+	}
 	if len(s.checking) > 0 {
 		fmt.Fprintf(buf, "Checking:\n%s\n", s.checking)
 	}
@@ -124,15 +218,89 @@ func (s *StatsInfo) Deletes(deletes int64) int64 {
 	return s.deletes
 }
 
-// ResetCounters sets the counters (bytes, checks, errors, transfers) to 0
-func (s *StatsInfo) ResetCounters() {
+// Corrupted records a download which failed the --download-check
+func (s *StatsInfo) Corrupted() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.corrupted++
+}
+
+// GetCorrupted returns the number of downloads which failed the
+// --download-check
+func (s *StatsInfo) GetCorrupted() int64 {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
+	return s.corrupted
+}
+
+// ResetCounters sets the counters (bytes, checks, errors, transfers,
+// API calls, transfers queued) to 0
+func (s *StatsInfo) ResetCounters() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	s.bytes = 0
 	s.errors = 0
 	s.checks = 0
 	s.transfers = 0
 	s.deletes = 0
+	s.corrupted = 0
+	s.skippedDirs = 0
+	s.peakMemory = 0
+	s.peakName = ""
+	s.apiCalls = nil
+	s.queued = 0
+}
+
+// QueueTransfer counts a transfer being queued and returns whether it
+// is within --max-files.  The count isn't reset by ResetErrors so
+// --max-files limits all the retries of a command together.
+func (s *StatsInfo) QueueTransfer() bool {
+	if fs.Config.MaxFiles < 0 {
+		return true
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.queued >= fs.Config.MaxFiles {
+		return false
+	}
+	s.queued++
+	return true
+}
+
+// TransferMemory records the most buffer memory the transfer of
+// remote used at once, keeping the biggest
+func (s *StatsInfo) TransferMemory(remote string, peak int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if peak > s.peakMemory {
+		s.peakMemory = peak
+		s.peakName = remote
+	}
+}
+
+// SkipDir records a directory which was skipped because it couldn't
+// be listed with --ignore-listing-errors
+func (s *StatsInfo) SkipDir(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.skippedDirs++
+	s.lastSkipErr = err
+}
+
+// GetSkippedDirs returns the number of directories skipped because
+// they couldn't be listed
+func (s *StatsInfo) GetSkippedDirs() int64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.skippedDirs
+}
+
+// GetLastSkipError returns the error listing the last directory
+// which was skipped
+func (s *StatsInfo) GetLastSkipError() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.lastSkipErr
 }
 
 // ResetErrors sets the errors count to 0
@@ -158,7 +326,10 @@ func (s *StatsInfo) Error(err error) {
 }
 
 // Checking adds a check into the stats
+//
+// It waits while the transfers are paused so no new checks start.
 func (s *StatsInfo) Checking(remote string) {
+	waitIfPaused()
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.checking[remote] = struct{}{}
@@ -180,7 +351,11 @@ func (s *StatsInfo) GetTransfers() int64 {
 }
 
 // Transferring adds a transfer into the stats
+//
+// It waits while the transfers are paused so no new transfers start,
+// including server side copies and moves which don't read any data.
 func (s *StatsInfo) Transferring(remote string) {
+	waitIfPaused()
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.transferring[remote] = struct{}{}
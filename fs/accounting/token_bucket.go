@@ -1,6 +1,7 @@
 package accounting
 
 import (
+	"container/heap"
 	"context"
 	"sync"
 	"time"
@@ -99,20 +100,162 @@ func StartTokenTicker() {
 	}()
 }
 
-// limitBandwith sleeps for the correct amount of time for the passage
-// of n bytes according to the current bandwidth limit
-func limitBandwidth(n int) {
-	tokenBucketMu.Lock()
+// tokenBucketQuantum is the most bytes a stream may take from the
+// token bucket in one go before the other streams get a turn
+const tokenBucketQuantum = 16 * 1024
 
-	// Limit the transfer speed if required
-	if tokenBucket != nil {
-		err := tokenBucket.WaitN(context.Background(), n)
-		if err != nil {
-			fs.Errorf(nil, "Token bucket error: %v", err)
-		}
+// smallFileWeight is the weight of the streams of the files smaller
+// than --bwlimit-small-files
+const smallFileWeight = 4
+
+// Stream is a user of the token bucket which gets a share of the
+// bandwidth in proportion to its weight.
+type Stream struct {
+	weight  float64       // share of the bandwidth relative to other streams
+	finish  float64       // virtual time the last request of this stream finishes
+	limiter *rate.Limiter // limits this stream on its own if set
+}
+
+// NewStream makes a Stream with weight 1
+//
+// If --max-upload-rate-per-file is set the stream is shaped to that
+// rate in tokenBucketQuantum sized steps so it never sends a big
+// burst.
+func NewStream() *Stream {
+	s := &Stream{weight: 1}
+	if fs.Config.MaxUploadRatePerFile > 0 {
+		s.limiter = rate.NewLimiter(rate.Limit(fs.Config.MaxUploadRatePerFile), tokenBucketQuantum)
+	}
+	return s
+}
+
+// SetWeight sets the share of the bandwidth this stream gets
+// relative to the other streams.  A stream with weight 2 gets twice
+// the bandwidth of a stream of weight 1 when both are busy.
+func (s *Stream) SetWeight(weight float64) {
+	if weight <= 0 {
+		weight = 1
+	}
+	scheduler.mu.Lock()
+	s.weight = weight
+	scheduler.mu.Unlock()
+}
+
+// waiter is a request queued for the token bucket
+type waiter struct {
+	start float64       // virtual start time of the request
+	seq   uint64        // order of arrival to break ties
+	ready chan struct{} // closed when it is this request's turn
+}
+
+// waiters is a heap of waiters ordered by start time
+type waiters []*waiter
+
+func (h waiters) Len() int { return len(h) }
+func (h waiters) Less(i, j int) bool {
+	if h[i].start != h[j].start {
+		return h[i].start < h[j].start
+	}
+	return h[i].seq < h[j].seq
+}
+func (h waiters) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *waiters) Push(x interface{}) { *h = append(*h, x.(*waiter)) }
+func (h *waiters) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
+
+// fairScheduler hands out turns at the token bucket using start time
+// fair queueing so that concurrent streams share it in proportion to
+// their weights.  A new stream starts at the current virtual time so
+// it is served promptly rather than queueing behind the data already
+// requested by a big transfer.
+type fairScheduler struct {
+	mu      sync.Mutex
+	vtime   float64 // virtual time of the request being served
+	busy    bool    // set if a request is being served
+	seq     uint64  // arrival counter
+	waiting waiters
+}
+
+var scheduler fairScheduler
+
+// acquire waits until it is the turn of stream s to take n tokens
+func (sch *fairScheduler) acquire(s *Stream, n int) {
+	sch.mu.Lock()
+	start := s.finish
+	if start < sch.vtime {
+		start = sch.vtime
+	}
+	s.finish = start + float64(n)/s.weight
+	if !sch.busy && len(sch.waiting) == 0 {
+		sch.busy = true
+		sch.vtime = start
+		sch.mu.Unlock()
+		return
+	}
+	sch.seq++
+	w := &waiter{start: start, seq: sch.seq, ready: make(chan struct{})}
+	heap.Push(&sch.waiting, w)
+	sch.mu.Unlock()
+	<-w.ready
+}
+
+// release passes the turn on to the next waiting request
+func (sch *fairScheduler) release() {
+	sch.mu.Lock()
+	if len(sch.waiting) > 0 {
+		w := heap.Pop(&sch.waiting).(*waiter)
+		sch.vtime = w.start
+		close(w.ready)
+	} else {
+		sch.busy = false
 	}
+	sch.mu.Unlock()
+}
 
-	tokenBucketMu.Unlock()
+// limitBandwith sleeps for the correct amount of time for the passage
+// of n bytes by stream s according to the current bandwidth limit
+// and the limit of the stream
+func limitBandwidth(s *Stream, n int) {
+	for n > 0 {
+		chunk := n
+		if chunk > tokenBucketQuantum {
+			chunk = tokenBucketQuantum
+		}
+		if s.limiter != nil {
+			err := s.limiter.WaitN(context.Background(), chunk)
+			if err != nil {
+				fs.Errorf(nil, "Stream rate limit error: %v", err)
+			}
+		}
+		tokenBucketMu.Lock()
+		tb := tokenBucket
+		tokenBucketMu.Unlock()
+		if tb == nil {
+			if s.limiter == nil {
+				return
+			}
+			n -= chunk
+			continue
+		}
+		scheduler.acquire(s, chunk)
+		// Re-read the bucket as it may have changed while waiting
+		tokenBucketMu.Lock()
+		tb = tokenBucket
+		tokenBucketMu.Unlock()
+		if tb != nil {
+			err := tb.WaitN(context.Background(), chunk)
+			if err != nil {
+				fs.Errorf(nil, "Token bucket error: %v", err)
+			}
+		}
+		scheduler.release()
+		n -= chunk
+	}
 }
 
 // SetBwLimit sets the current bandwidth limit
@@ -132,7 +275,7 @@ func SetBwLimit(bandwidth fs.SizeSuffix) {
 func init() {
 	rc.Add(rc.Call{
 		Path: "core/bwlimit",
-		Fn: func(in rc.Params) (out rc.Params, err error) {
+		Fn: func(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 			ibwlimit, ok := in["rate"]
 			if !ok {
 				return out, errors.Errorf("parameter rate not found")
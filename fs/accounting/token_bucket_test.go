@@ -0,0 +1,125 @@
+package accounting
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitQueued waits until n requests are queued on sch
+func waitQueued(t *testing.T, sch *fairScheduler, n int) {
+	for i := 0; i < 1000; i++ {
+		sch.mu.Lock()
+		queued := len(sch.waiting)
+		sch.mu.Unlock()
+		if queued >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d queued requests", n)
+}
+
+// runScheduler queues the requests in order while the scheduler is
+// busy then returns the order they were served in
+func runScheduler(t *testing.T, sch *fairScheduler, requests []*Stream, names []string) (order []string) {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	busy := NewStream()
+	sch.acquire(busy, tokenBucketQuantum)
+	for i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sch.acquire(requests[i], tokenBucketQuantum)
+			mu.Lock()
+			order = append(order, names[i])
+			mu.Unlock()
+			sch.release()
+		}(i)
+		// queue the requests in a known order
+		waitQueued(t, sch, i+1)
+	}
+	sch.release()
+	wg.Wait()
+	return order
+}
+
+func TestFairSchedulerNewStreamNotStarved(t *testing.T) {
+	var sch fairScheduler
+	big, small := NewStream(), NewStream()
+	// big has already queued lots of data
+	requests := []*Stream{big, big, big, small}
+	names := []string{"big1", "big2", "big3", "small"}
+	order := runScheduler(t, &sch, requests, names)
+	assert.Equal(t, []string{"big1", "small", "big2", "big3"}, order)
+}
+
+func TestFairSchedulerWeights(t *testing.T) {
+	var sch fairScheduler
+	heavy, light := NewStream(), NewStream()
+	heavy.weight = 2
+	requests := []*Stream{light, light, heavy, heavy, heavy, heavy}
+	names := []string{"light1", "light2", "heavy1", "heavy2", "heavy3", "heavy4"}
+	order := runScheduler(t, &sch, requests, names)
+	assert.Equal(t, []string{"light1", "heavy1", "heavy2", "light2", "heavy3", "heavy4"}, order)
+}
+
+func TestFairSchedulerNotBusy(t *testing.T) {
+	var sch fairScheduler
+	s := NewStream()
+	sch.acquire(s, 10)
+	assert.True(t, sch.busy)
+	sch.release()
+	assert.False(t, sch.busy)
+}
+
+func TestAccountSmallFileWeight(t *testing.T) {
+	oldSmall := fs.Config.BwLimitSmallFiles
+	defer func() { fs.Config.BwLimitSmallFiles = oldSmall }()
+	in := ioutil.NopCloser(bytes.NewReader(nil))
+
+	fs.Config.BwLimitSmallFiles = 0
+	acc := NewAccountSizeName(in, 10, "off")
+	assert.Equal(t, 1.0, acc.stream.weight)
+	require.NoError(t, acc.Close())
+
+	fs.Config.BwLimitSmallFiles = 100
+	for _, test := range []struct {
+		size   int64
+		weight float64
+	}{
+		{10, smallFileWeight},
+		{100, 1},
+		{-1, 1},
+	} {
+		acc := NewAccountSizeName(in, test.size, "file")
+		assert.Equal(t, test.weight, acc.stream.weight, test.size)
+		require.NoError(t, acc.Close())
+	}
+}
+
+func TestStreamMaxUploadRatePerFile(t *testing.T) {
+	oldRate := fs.Config.MaxUploadRatePerFile
+	defer func() { fs.Config.MaxUploadRatePerFile = oldRate }()
+
+	fs.Config.MaxUploadRatePerFile = 0
+	assert.Nil(t, NewStream().limiter)
+
+	fs.Config.MaxUploadRatePerFile = fs.SizeSuffix(10 * tokenBucketQuantum)
+	s := NewStream()
+	assert.NotNil(t, s.limiter)
+
+	// the first quantum is the burst, the next two take 200ms
+	start := time.Now()
+	limitBandwidth(s, 3*tokenBucketQuantum)
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "too fast: %v", time.Since(start))
+}
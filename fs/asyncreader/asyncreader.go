@@ -5,6 +5,7 @@ package asyncreader
 import (
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ncw/rclone/lib/readers"
 	"github.com/pkg/errors"
@@ -38,6 +39,7 @@ type AsyncReader struct {
 	size    int           // size of buffer to use
 	closed  bool          // whether we have closed the underlying stream
 	mu      sync.Mutex    // lock for Read/WriteTo/Abandon/Close
+	peak    int64         // most buffers in use at once - read with atomic
 }
 
 // New returns a reader that will asynchronously read from
@@ -81,6 +83,9 @@ func (a *AsyncReader) init(rd io.ReadCloser, buffers int) {
 		for {
 			select {
 			case <-a.token:
+				if inUse := int64(a.buffers - len(a.token)); inUse > atomic.LoadInt64(&a.peak) {
+					atomic.StoreInt64(&a.peak, inUse)
+				}
 				b := a.getBuffer()
 				if a.size < BufferSize {
 					b.buf = b.buf[:a.size]
@@ -180,6 +185,12 @@ func (a *AsyncReader) WriteTo(w io.Writer) (n int64, err error) {
 	}
 }
 
+// Peak returns the most memory the buffers have used at once.  It is
+// safe to call at any time.
+func (a *AsyncReader) Peak() int64 {
+	return atomic.LoadInt64(&a.peak) * BufferSize
+}
+
 // Abandon will ensure that the underlying async reader is shut down.
 // It will NOT close the input supplied on New.
 func (a *AsyncReader) Abandon() {
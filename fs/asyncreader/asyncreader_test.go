@@ -49,6 +49,24 @@ func TestAsyncReader(t *testing.T) {
 
 }
 
+func TestAsyncReaderPeak(t *testing.T) {
+	buf := ioutil.NopCloser(bytes.NewBuffer(make([]byte, 10*BufferSize)))
+	ar, err := New(buf, 4)
+	require.NoError(t, err)
+
+	// without any reading the read ahead fills all the buffers
+	for i := 0; i < 100 && ar.Peak() < 4*BufferSize; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, int64(4*BufferSize), ar.Peak())
+
+	// reading everything doesn't use any more
+	_, err = ioutil.ReadAll(ar)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4*BufferSize), ar.Peak())
+	require.NoError(t, ar.Close())
+}
+
 func TestAsyncWriteTo(t *testing.T) {
 	buf := ioutil.NopCloser(bytes.NewBufferString("Testbuffer"))
 	ar, err := New(buf, 4)
@@ -1,6 +1,7 @@
 package chunkedreader
 
 import (
+	"context"
 	"io"
 	"sync"
 
@@ -12,6 +13,7 @@ import (
 //
 // A initialChunkSize of 0 will disable chunked reading.
 type ChunkedReader struct {
+	ctx              context.Context
 	mu               sync.Mutex
 	o                fs.Object
 	rc               io.ReadCloser
@@ -28,11 +30,12 @@ type ChunkedReader struct {
 // A initialChunkSize of 0 will disable chunked reading.
 // If chunkGrowth is true, the chunk size will be doubled after each chunk read.
 // A Seek or RangeSeek will reset the chunk size to it's initial value
-func New(o fs.Object, initialChunkSize int64, chunkGrowth bool) *ChunkedReader {
+func New(ctx context.Context, o fs.Object, initialChunkSize int64, chunkGrowth bool) *ChunkedReader {
 	if initialChunkSize < 0 {
 		initialChunkSize = 0
 	}
 	return &ChunkedReader{
+		ctx:              ctx,
 		o:                o,
 		offset:           -1,
 		chunkSize:        initialChunkSize,
@@ -141,12 +144,12 @@ func (cr *ChunkedReader) openRange() error {
 	var err error
 	if length <= 0 {
 		if offset == 0 {
-			rc, err = cr.o.Open()
+			rc, err = cr.o.Open(cr.ctx)
 		} else {
-			rc, err = cr.o.Open(&fs.RangeOption{Start: offset, End: -1})
+			rc, err = cr.o.Open(cr.ctx, &fs.RangeOption{Start: offset, End: -1})
 		}
 	} else {
-		rc, err = cr.o.Open(&fs.RangeOption{Start: offset, End: offset + length - 1})
+		rc, err = cr.o.Open(cr.ctx, &fs.RangeOption{Start: offset, End: offset + length - 1})
 	}
 	if err != nil {
 		return err
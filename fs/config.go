@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"context"
 	"net"
 	"time"
 )
@@ -31,26 +32,51 @@ var (
 type ConfigInfo struct {
 	LogLevel              LogLevel
 	StatsLogLevel         LogLevel
+	LogFilterRemote       string // Only log about the remote with this name if set
 	DryRun                bool
+	Interactive           bool // Ask before destructive operations
+	Metadata              bool // Copy the metadata of objects where the remotes support it
 	CheckSum              bool
+	DownloadCheck         bool // Check the hash of downloads as they are read
 	SizeOnly              bool
 	IgnoreTimes           bool
 	IgnoreExisting        bool
 	IgnoreErrors          bool
+	IgnoreListingErrors   bool   // Skip directories which can't be listed rather than stop deletions
+	UploadLockDir         string // Directory of locks so only one rclone uploads each object at once
+	NoSpool               bool   // Fail rather than spool transfers to local disk
+	DestTemplate          string // Template for the destination path of each file in copy and move
+	Sorted                bool   // Walk directories in a stable sorted order
 	ModifyWindow          time.Duration
+	ModifyWindowAuto      bool // Work out ModifyWindow from the Fs precisions
 	Checkers              int
 	Transfers             int
 	ConnectTimeout        time.Duration // Connect timeout
 	Timeout               time.Duration // Data channel timeout
 	Dump                  DumpFlags
-	InsecureSkipVerify    bool // Skip server certificate verification
+	DumpFile              string // Write HTTP transactions to this file if set
+	InsecureSkipVerify    bool   // Skip server certificate verification
+	CaCert                string // Client Side CA
+	ClientCert            string // Client Side Cert
+	ClientKey             string // Client Side Key
+	Proxy                 string // Proxy for http connections - "" to read from the environment
+	UploadHeaders         []*HTTPOption
+	DownloadHeaders       []*HTTPOption
 	DeleteMode            DeleteMode
 	MaxDelete             int64
-	TrackRenames          bool // Track file renames.
+	MaxFiles              int64      // Stop queuing transfers after this many files if >= 0
+	MaxDeletePercent      float64    // Limit the deletes to this percentage of the destination files if >= 0
+	MaxSizeDelete         SizeSuffix // Limit the total size of the deletes if >= 0
+	TrackRenames          bool       // Track file renames.
+	DeleteAfterVerify     bool       // Only delete the sources of a move once the whole run is verified
+	NoTraverse            bool       // Look up the --files-from files directly rather than listing
 	LowLevelRetries       int
+	TransferFailureLimit  int  // Try failed files again at the end until they have failed this many times
 	UpdateOlder           bool // Skip files that are newer on the destination
 	NoGzip                bool // Disable compression
 	MaxDepth              int
+	VerifyDelay           time.Duration // Wait this long before rechecking an upload which failed verification
+	VerifyRetries         int           // Recheck a failed upload this many times if VerifyDelay is set
 	IgnoreSize            bool
 	IgnoreChecksum        bool
 	NoUpdateModTime       bool
@@ -68,9 +94,24 @@ type ConfigInfo struct {
 	Immutable             bool
 	AutoConfirm           bool
 	StreamingUploadCutoff SizeSuffix
+	MaxUploadRatePerFile  SizeSuffix // Shape each transfer to this many bytes/s if set
+	BwLimitSmallFiles     SizeSuffix // Give transfers of files smaller than this a bigger share of the bandwidth if set
 	StatsFileNameLength   int
 	AskPassword           bool
 	UseServerModTime      bool
+	BackendEncoding       string        // default encoding of reserved characters in file names
+	Versions              bool          // Include old versions of objects in listings
+	Trash                 bool          // List the trash instead of the normal files
+	RetentionPeriod       time.Duration // Lock uploaded objects for this long if set
+	RetentionMode         string        // RetentionGovernance or RetentionCompliance
+	PauseSignals          bool          // Pause transfers on SIGTSTP and resume them on SIGCONT
+	UseMmap               bool          // Allocate upload buffers with mmap
+	Pacer                 string        // Pacer type or preset to use instead of the backend's
+	PacerMinSleep         time.Duration // Override the minimum sleep between API calls if set
+	PacerBurst            int           // Override the number of API calls allowed at once if set
+	DirCacheWorkers       int           // Number of directory lookups to do at once
+	DirCacheNotFoundTime  time.Duration // How long to remember directories which weren't found
+	DirCachePersist       bool          // Save directory IDs between runs
 }
 
 // NewConfig creates a new config with everything set to the default
@@ -83,21 +124,42 @@ func NewConfig() *ConfigInfo {
 	c.LogLevel = LogLevelNotice
 	c.StatsLogLevel = LogLevelInfo
 	c.ModifyWindow = time.Nanosecond
+	c.ModifyWindowAuto = true
 	c.Checkers = 8
 	c.Transfers = 4
 	c.ConnectTimeout = 60 * time.Second
 	c.Timeout = 5 * 60 * time.Second
 	c.DeleteMode = DeleteModeDefault
 	c.MaxDelete = -1
+	c.MaxFiles = -1
+	c.MaxDeletePercent = -1
+	c.MaxSizeDelete = -1
 	c.LowLevelRetries = 10
+	c.TransferFailureLimit = 2
 	c.MaxDepth = -1
 	c.DataRateUnit = "bytes"
 	c.BufferSize = SizeSuffix(16 << 20)
 	c.UserAgent = "rclone/" + Version
+	c.RetentionMode = RetentionCompliance
+	c.VerifyRetries = 3
 	c.StreamingUploadCutoff = SizeSuffix(100 * 1024)
 	c.StatsFileNameLength = 40
 	c.AskPassword = true
 	c.TPSLimitBurst = 1
+	c.DirCacheWorkers = 4
+	c.DirCacheNotFoundTime = 10 * time.Second
 
 	return c
 }
+
+// CallContext returns a context for a single call to a remote which
+// transfers no file data, eg reading a listing or the metadata of an
+// object.  It is cancelled when ctx is or if the call takes longer
+// than --contimeout plus --timeout, so a stuck call fails and can be
+// retried.  Call the CancelFunc when the call has finished.
+func CallContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if Config.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, Config.ConnectTimeout+Config.Timeout)
+}
@@ -4,6 +4,7 @@ package config
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
@@ -30,6 +31,7 @@ import (
 	"github.com/ncw/rclone/fs/config/obscure"
 	"github.com/ncw/rclone/fs/driveletter"
 	"github.com/ncw/rclone/fs/fshttp"
+	"github.com/ncw/rclone/lib/filelock"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/nacl/secretbox"
 	"golang.org/x/text/unicode/norm"
@@ -56,6 +58,14 @@ const (
 
 	// ConfigAutomatic indicates that we want non-interactive configuration
 	ConfigAutomatic = "config_automatic"
+
+	// ConfigAuthFlow is the config key used to choose a different
+	// OAuth flow, eg "device"
+	ConfigAuthFlow = "config_auth_flow"
+
+	// ConfigDeviceAuthURL is the config key used to store the device
+	// authorization endpoint for the OAuth device flow
+	ConfigDeviceAuthURL = "device_auth_url"
 )
 
 // Global
@@ -176,6 +186,9 @@ func LoadConfig() {
 	// Start the bandwidth update ticker
 	accounting.StartTokenTicker()
 
+	// Start the SIGTSTP/SIGCONT handler to pause the transfers
+	accounting.StartPauseHandler()
+
 	// Start the transactions per second limiter
 	fshttp.StartHTTPTokenBucket()
 }
@@ -486,9 +499,73 @@ func SetValueAndSave(name, key, value string) (err error) {
 	return nil
 }
 
+// FileGetFromDisk reloads the config file from disk and returns the
+// key in section, so that values saved by other rclone processes
+// are seen.  The value is updated in the config in memory too.
+//
+// If the config file can't be reloaded the value in memory is
+// returned.
+func FileGetFromDisk(section, key string) string {
+	reloadedConfigFile, err := loadConfigFile()
+	if err == nil {
+		if value, err := reloadedConfigFile.GetValue(section, key); err == nil {
+			getConfigData().SetValue(section, key, value)
+		}
+	} else if err != errorConfigFileNotFound {
+		fs.Debugf(nil, "Failed to reload config file: %v", err)
+	}
+	return FileGet(section, key)
+}
+
+// configLockTimeout is how long LockConfig waits for the lock
+const configLockTimeout = 2 * time.Minute
+
+// LockConfig takes a lock on the config file which other rclone
+// processes using the same config file wait for.  It returns a
+// function to release the lock.
+//
+// The lock is a file next to the config file held with
+// lib/filelock, so it is taken over if the process holding it died.
+func LockConfig() (unlock func(), err error) {
+	lockPath := ConfigPath + ".lock"
+	ctx, cancel := context.WithTimeout(context.Background(), configLockTimeout)
+	defer cancel()
+	lock, _, err := filelock.AcquirePath(ctx, lockPath, ConfigPath)
+	if err == context.DeadlineExceeded {
+		return nil, errors.Errorf("timed out waiting for config lock file %q", lockPath)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to lock config file")
+	}
+	return func() {
+		if err := lock.Release(); err != nil {
+			fs.Errorf(nil, "Failed to remove config lock file: %v", err)
+		}
+	}, nil
+}
+
+// FilterProfilePrefix starts the name of config file sections which
+// hold filter profiles rather than remotes, eg [filters.photos]
+const FilterProfilePrefix = "filters."
+
+// JobPrefix starts the name of config file sections which hold jobs
+// for the scheduler rather than remotes, eg [jobs.backup]
+const JobPrefix = "jobs."
+
+// remoteSections returns the sections in the config file which are
+// remotes
+func remoteSections() (remotes []string) {
+	for _, section := range getConfigData().GetSectionList() {
+		if !strings.HasPrefix(section, FilterProfilePrefix) && !strings.HasPrefix(section, JobPrefix) {
+			remotes = append(remotes, section)
+		}
+	}
+	return remotes
+}
+
 // ShowRemotes shows an overview of the config file
 func ShowRemotes() {
-	remotes := getConfigData().GetSectionList()
+	remotes := remoteSections()
 	if len(remotes) == 0 {
 		return
 	}
@@ -502,7 +579,7 @@ func ShowRemotes() {
 
 // ChooseRemote chooses a remote name
 func ChooseRemote() string {
-	remotes := getConfigData().GetSectionList()
+	remotes := remoteSections()
 	sort.Strings(remotes)
 	return Choose("remote", remotes, nil, false)
 }
@@ -818,6 +895,40 @@ func PasswordRemote(name string, keyValues []string) error {
 	return nil
 }
 
+// ReconnectRemote re-runs the authentication part of the config of
+// the remote name, offering to change any passwords stored for it,
+// then checks the remote can be listed.
+func ReconnectRemote(name string) error {
+	name = strings.TrimSuffix(name, ":")
+	if _, err := getConfigData().GetSection(name); err != nil {
+		return errors.Errorf("remote %q not found in config", name)
+	}
+	ri := MustFindByName(name)
+	for _, option := range ri.Options {
+		if !option.IsPassword || FileGet(name, option.Name) == "" {
+			continue
+		}
+		fmt.Printf("Change the %q password?\n", option.Name)
+		if Confirm() {
+			password := ChangePassword("the new")
+			getConfigData().SetValue(name, option.Name, obscure.MustObscure(password))
+		}
+	}
+	SaveConfig()
+	RemoteConfig(name)
+	fmt.Printf("Testing remote %q\n", name)
+	f, err := fs.NewFs(name + ":")
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to remote %q", name)
+	}
+	_, err = f.List(context.Background(), "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to list remote %q", name)
+	}
+	fmt.Printf("Remote %q is working\n", name)
+	return nil
+}
+
 // JSONListProviders prints all the providers and options in JSON format
 func JSONListProviders() error {
 	b, err := json.MarshalIndent(fs.Registry, "", "    ")
@@ -978,7 +1089,7 @@ func ShowConfig() {
 // EditConfig edits the config file interactively
 func EditConfig() {
 	for {
-		haveRemotes := len(getConfigData().GetSectionList()) != 0
+		haveRemotes := len(remoteSections()) != 0
 		what := []string{"eEdit existing remote", "nNew remote", "dDelete remote", "rRename remote", "cCopy remote", "sSet configuration password", "qQuit config"}
 		if haveRemotes {
 			fmt.Printf("Current remotes:\n\n")
@@ -1056,7 +1167,10 @@ func SetPassword() {
 //
 //   rclone authorize "fs name"
 //   rclone authorize "fs name" "client id" "client secret"
-func Authorize(args []string) {
+//
+// Any settings passed in are set in the config of the remote, eg to
+// select a different OAuth flow with ConfigAuthFlow
+func Authorize(args []string, settings map[string]string) {
 	switch len(args) {
 	case 1, 3:
 	default:
@@ -1079,6 +1193,9 @@ func Authorize(args []string) {
 		getConfigData().SetValue(name, ConfigClientID, args[1])
 		getConfigData().SetValue(name, ConfigClientSecret, args[2])
 	}
+	for key, value := range settings {
+		getConfigData().SetValue(name, key, value)
+	}
 	fs.Config(name)
 }
 
@@ -1151,12 +1268,29 @@ func FileDeleteKey(section, key string) bool {
 	return getConfigData().DeleteKey(section, key)
 }
 
+// FileKeys returns the keys in section of the config file in the
+// order they appear.
+func FileKeys(section string) []string {
+	return getConfigData().GetKeyList(section)
+}
+
+// FileSectionsWithPrefix returns the names of the sections in the
+// config file starting with prefix with the prefix removed
+func FileSectionsWithPrefix(prefix string) (names []string) {
+	for _, section := range getConfigData().GetSectionList() {
+		if strings.HasPrefix(section, prefix) {
+			names = append(names, section[len(prefix):])
+		}
+	}
+	return names
+}
+
 var matchEnv = regexp.MustCompile(`^RCLONE_CONFIG_(.*?)_TYPE=.*$`)
 
-// FileSections returns the sections in the config file
-// including any defined by environment variables.
+// FileSections returns the sections in the config file which are
+// remotes including any defined by environment variables.
 func FileSections() []string {
-	sections := getConfigData().GetSectionList()
+	sections := remoteSections()
 	for _, item := range os.Environ() {
 		matches := matchEnv.FindStringSubmatch(item)
 		if len(matches) == 2 {
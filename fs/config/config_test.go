@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/config/obscure"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -81,6 +84,99 @@ func TestCRUD(t *testing.T) {
 	assert.Equal(t, []string{}, configFile.GetSectionList())
 }
 
+func TestReconnectRemote(t *testing.T) {
+	configKey = nil // reset password
+	tempFile, err := ioutil.TempFile("", "reconnect.conf")
+	require.NoError(t, err)
+	path := tempFile.Name()
+	defer func() {
+		assert.NoError(t, os.Remove(path))
+	}()
+	assert.NoError(t, tempFile.Close())
+
+	oldOsStdout := os.Stdout
+	oldConfigPath := ConfigPath
+	oldConfig := fs.Config
+	oldConfigFile := configFile
+	os.Stdout = nil
+	ConfigPath = path
+	fs.Config = &fs.ConfigInfo{}
+	configFile = nil
+	defer func() {
+		os.Stdout = oldOsStdout
+		ConfigPath = oldConfigPath
+		fs.Config = oldConfig
+		configFile = oldConfigFile
+	}()
+	LoadConfig()
+
+	// Fake a remote which can't connect
+	configured := 0
+	fs.Register(&fs.RegInfo{
+		Name: "config_test_reconnect",
+		NewFs: func(name, root string) (fs.Fs, error) {
+			return nil, errors.New("can't connect")
+		},
+		Config: func(name string) {
+			configured++
+		},
+	})
+	getConfigData().SetValue("reconnect", "type", "config_test_reconnect")
+
+	err = ReconnectRemote("missing:")
+	assert.EqualError(t, err, `remote "missing" not found in config`)
+	assert.Equal(t, 0, configured)
+
+	err = ReconnectRemote("reconnect:")
+	assert.EqualError(t, err, `failed to connect to remote "reconnect": can't connect`)
+	assert.Equal(t, 1, configured)
+}
+
+func TestLockConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lockconfig")
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, os.RemoveAll(dir))
+	}()
+	oldConfigPath := ConfigPath
+	ConfigPath = filepath.Join(dir, "rclone.conf")
+	defer func() {
+		ConfigPath = oldConfigPath
+	}()
+	lockPath := ConfigPath + ".lock"
+
+	unlock, err := LockConfig()
+	require.NoError(t, err)
+	assert.FileExists(t, lockPath)
+
+	// A second lock waits for the first to be released
+	locked := make(chan struct{})
+	go func() {
+		unlock, err := LockConfig()
+		assert.NoError(t, err)
+		unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		t.Fatal("lock taken twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	<-locked
+
+	// A stale lock is removed
+	require.NoError(t, ioutil.WriteFile(lockPath, []byte("1\n"), 0600))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(lockPath, old, old))
+	unlock, err = LockConfig()
+	require.NoError(t, err)
+	unlock()
+	_, err = os.Stat(lockPath)
+	assert.True(t, os.IsNotExist(err))
+
+}
+
 // Test some error cases
 func TestReveal(t *testing.T) {
 	for _, test := range []struct {
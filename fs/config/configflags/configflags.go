@@ -7,11 +7,14 @@ import (
 	"log"
 	"net"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/lib/pacer"
 	"github.com/spf13/pflag"
 )
 
@@ -26,7 +29,11 @@ var (
 	deleteAfter     bool
 	bindAddr        string
 	disableFeatures string
-	noTraverse      bool
+	modifyWindow    = "auto"
+	maxDelete       = "-1"
+	logFilter       string
+	uploadHeaders   []string
+	downloadHeaders []string
 )
 
 // AddFlags adds the non filing system specific flags to the command
@@ -34,36 +41,56 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	// NB defaults which aren't the zero for the type should be set in fs/config.go NewConfig
 	flags.CountVarP(flagSet, &verbose, "verbose", "v", "Print lots more stuff (repeat for more)")
 	flags.BoolVarP(flagSet, &quiet, "quiet", "q", false, "Print as little stuff as possible")
-	flags.DurationVarP(flagSet, &fs.Config.ModifyWindow, "modify-window", "", fs.Config.ModifyWindow, "Max time diff to be considered the same")
+	flags.StringVarP(flagSet, &modifyWindow, "modify-window", "", modifyWindow, "Max time diff to be considered the same, or \"auto\" to use the precision of the remotes")
 	flags.IntVarP(flagSet, &fs.Config.Checkers, "checkers", "", fs.Config.Checkers, "Number of checkers to run in parallel.")
 	flags.IntVarP(flagSet, &fs.Config.Transfers, "transfers", "", fs.Config.Transfers, "Number of file transfers to run in parallel.")
 	flags.StringVarP(flagSet, &config.ConfigPath, "config", "", config.ConfigPath, "Config file.")
 	flags.StringVarP(flagSet, &config.CacheDir, "cache-dir", "", config.CacheDir, "Directory rclone will use for caching.")
 	flags.BoolVarP(flagSet, &fs.Config.CheckSum, "checksum", "c", fs.Config.CheckSum, "Skip based on checksum & size, not mod-time & size")
+	flags.BoolVarP(flagSet, &fs.Config.DownloadCheck, "download-check", "", fs.Config.DownloadCheck, "Check the hash of each file downloaded against the one the remote has")
 	flags.BoolVarP(flagSet, &fs.Config.SizeOnly, "size-only", "", fs.Config.SizeOnly, "Skip based on size only, not mod-time or checksum")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreTimes, "ignore-times", "I", fs.Config.IgnoreTimes, "Don't skip files that match size and time - transfer all files")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreExisting, "ignore-existing", "", fs.Config.IgnoreExisting, "Skip all files that exist on destination")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreErrors, "ignore-errors", "", fs.Config.IgnoreErrors, "delete even if there are I/O errors")
+	flags.BoolVarP(flagSet, &fs.Config.IgnoreListingErrors, "ignore-listing-errors", "", fs.Config.IgnoreListingErrors, "Skip directories which can't be listed, but delete files elsewhere")
+	flags.StringVarP(flagSet, &fs.Config.DestTemplate, "dest-template", "", fs.Config.DestTemplate, "Go template for the destination path of each file in copy and move, eg {{.ModTime.Format \"2006/01\"}}/{{.Name}}")
+	flags.BoolVarP(flagSet, &fs.Config.Sorted, "sorted", "", fs.Config.Sorted, "List directories in a stable sorted order so listings can be compared")
+	flags.BoolVarP(flagSet, &fs.Config.NoSpool, "no-spool", "", fs.Config.NoSpool, "Fail rather than spool transfers to local disk, and show the transfer buffer memory in the stats")
 	flags.BoolVarP(flagSet, &fs.Config.DryRun, "dry-run", "n", fs.Config.DryRun, "Do a trial run with no permanent changes")
+	flags.BoolVarP(flagSet, &fs.Config.Interactive, "interactive", "i", fs.Config.Interactive, "Ask before deleting or overwriting each file")
+	flags.BoolVarP(flagSet, &fs.Config.Metadata, "metadata", "", fs.Config.Metadata, "Copy metadata, eg access and creation times, where the remotes support it")
+	flags.BoolVarP(flagSet, &fs.Config.Versions, "versions", "", fs.Config.Versions, "Include old versions of objects in listings on remotes which support it")
+	flags.BoolVarP(flagSet, &fs.Config.Trash, "trash", "", fs.Config.Trash, "List the files in the trash instead of the normal files on remotes which support it")
 	flags.DurationVarP(flagSet, &fs.Config.ConnectTimeout, "contimeout", "", fs.Config.ConnectTimeout, "Connect timeout")
 	flags.DurationVarP(flagSet, &fs.Config.Timeout, "timeout", "", fs.Config.Timeout, "IO idle timeout")
 	flags.BoolVarP(flagSet, &dumpHeaders, "dump-headers", "", false, "Dump HTTP bodies - may contain sensitive info")
 	flags.BoolVarP(flagSet, &dumpBodies, "dump-bodies", "", false, "Dump HTTP headers and bodies - may contain sensitive info")
 	flags.BoolVarP(flagSet, &fs.Config.InsecureSkipVerify, "no-check-certificate", "", fs.Config.InsecureSkipVerify, "Do not verify the server SSL certificate. Insecure.")
+	flags.StringVarP(flagSet, &fs.Config.CaCert, "ca-cert", "", fs.Config.CaCert, "CA certificate used to verify servers")
+	flags.StringVarP(flagSet, &fs.Config.ClientCert, "client-cert", "", fs.Config.ClientCert, "Client SSL certificate (PEM) for mutual TLS auth")
+	flags.StringVarP(flagSet, &fs.Config.ClientKey, "client-key", "", fs.Config.ClientKey, "Client SSL private key (PEM) for mutual TLS auth")
+	flags.StringVarP(flagSet, &fs.Config.Proxy, "proxy", "", fs.Config.Proxy, "Proxy for http connections, eg socks5://host:1080 or \"direct\" - overrides the environment")
+	flags.StringArrayVarP(flagSet, &uploadHeaders, "header-upload", "", nil, "Set HTTP header for upload transactions, eg \"X-Audit: tag\"")
+	flags.StringArrayVarP(flagSet, &downloadHeaders, "header-download", "", nil, "Set HTTP header for download transactions, eg \"X-Audit: tag\"")
 	flags.BoolVarP(flagSet, &fs.Config.AskPassword, "ask-password", "", fs.Config.AskPassword, "Allow prompt for password for encrypted configuration.")
 	flags.BoolVarP(flagSet, &deleteBefore, "delete-before", "", false, "When synchronizing, delete files on destination before transfering")
 	flags.BoolVarP(flagSet, &deleteDuring, "delete-during", "", false, "When synchronizing, delete files during transfer (default)")
 	flags.BoolVarP(flagSet, &deleteAfter, "delete-after", "", false, "When synchronizing, delete files on destination after transfering")
-	flags.IntVar64P(flagSet, &fs.Config.MaxDelete, "max-delete", "", -1, "When synchronizing, limit the number of deletes")
+	flags.IntVar64P(flagSet, &fs.Config.MaxFiles, "max-files", "", fs.Config.MaxFiles, "Stop queuing new transfers after this many files, leaving the rest for the next run")
+	flags.StringVarP(flagSet, &maxDelete, "max-delete", "", maxDelete, "When synchronizing, limit the number of deletes, or the percentage of the destination files with a % suffix")
+	flags.FVarP(flagSet, &fs.Config.MaxSizeDelete, "max-size-delete", "", "When synchronizing, limit the total size of the deletes in k or suffix b|k|M|G")
 	flags.BoolVarP(flagSet, &fs.Config.TrackRenames, "track-renames", "", fs.Config.TrackRenames, "When synchronizing, track file renames and do a server side move if possible")
 	flags.IntVarP(flagSet, &fs.Config.LowLevelRetries, "low-level-retries", "", fs.Config.LowLevelRetries, "Number of low level retries to do.")
+	flags.IntVarP(flagSet, &fs.Config.TransferFailureLimit, "transfer-failure-limit", "", fs.Config.TransferFailureLimit, "Try failed files again after all the others until they have failed this many times. 0 to disable.")
 	flags.BoolVarP(flagSet, &fs.Config.UpdateOlder, "update", "u", fs.Config.UpdateOlder, "Skip files that are newer on the destination.")
+	flags.StringVarP(flagSet, &fs.Config.UploadLockDir, "upload-lock-dir", "", fs.Config.UploadLockDir, "Lock uploads with files in this directory so only one rclone uploads each object at once")
 	flags.BoolVarP(flagSet, &fs.Config.UseServerModTime, "use-server-modtime", "", fs.Config.UseServerModTime, "Use server modified time instead of object metadata")
+	flags.BoolVarP(flagSet, &fs.Config.UseMmap, "use-mmap", "", fs.Config.UseMmap, "Use mmap allocator for upload buffers")
 	flags.BoolVarP(flagSet, &fs.Config.NoGzip, "no-gzip-encoding", "", fs.Config.NoGzip, "Don't set Accept-Encoding: gzip.")
 	flags.IntVarP(flagSet, &fs.Config.MaxDepth, "max-depth", "", fs.Config.MaxDepth, "If set limits the recursion depth to this.")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreSize, "ignore-size", "", false, "Ignore size when skipping use mod-time or checksum.")
 	flags.BoolVarP(flagSet, &fs.Config.IgnoreChecksum, "ignore-checksum", "", fs.Config.IgnoreChecksum, "Skip post copy check of checksums.")
-	flags.BoolVarP(flagSet, &noTraverse, "no-traverse", "", noTraverse, "Obsolete - does nothing.")
+	flags.BoolVarP(flagSet, &fs.Config.NoTraverse, "no-traverse", "", fs.Config.NoTraverse, "With --files-from look up the files directly instead of listing the directories.")
 	flags.BoolVarP(flagSet, &fs.Config.NoUpdateModTime, "no-update-modtime", "", fs.Config.NoUpdateModTime, "Don't update destination mod-time if files identical.")
 	flags.StringVarP(flagSet, &fs.Config.BackupDir, "backup-dir", "", fs.Config.BackupDir, "Make backups into hierarchy based in DIR.")
 	flags.StringVarP(flagSet, &fs.Config.Suffix, "suffix", "", fs.Config.Suffix, "Suffix for use with --backup-dir.")
@@ -74,17 +101,46 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.StringVarP(flagSet, &disableFeatures, "disable", "", "", "Disable a comma separated list of features.  Use help to see a list.")
 	flags.StringVarP(flagSet, &fs.Config.UserAgent, "user-agent", "", fs.Config.UserAgent, "Set the user-agent to a specified string. The default is rclone/ version")
 	flags.BoolVarP(flagSet, &fs.Config.Immutable, "immutable", "", fs.Config.Immutable, "Do not modify files. Fail if existing files have been modified.")
+	flags.BoolVarP(flagSet, &fs.Config.DeleteAfterVerify, "delete-after-verify", "", fs.Config.DeleteAfterVerify, "When moving, only delete the sources once every file has been transferred and verified.")
+	flags.DurationVarP(flagSet, &fs.Config.VerifyDelay, "verify-delay", "", fs.Config.VerifyDelay, "If an upload fails verification, wait this long and recheck it before failing.")
+	flags.IntVarP(flagSet, &fs.Config.VerifyRetries, "verify-retries", "", fs.Config.VerifyRetries, "Number of times to recheck an upload with --verify-delay.")
+	flags.DurationVarP(flagSet, &fs.Config.RetentionPeriod, "retention-period", "", fs.Config.RetentionPeriod, "Lock uploaded objects against deletion or overwriting for this long on remotes which support it.")
+	flags.StringVarP(flagSet, &fs.Config.RetentionMode, "retention-mode", "", fs.Config.RetentionMode, "Retention mode for --retention-period: governance or compliance.")
 	flags.BoolVarP(flagSet, &fs.Config.AutoConfirm, "auto-confirm", "", fs.Config.AutoConfirm, "If enabled, do not request console confirmation.")
 	flags.IntVarP(flagSet, &fs.Config.StatsFileNameLength, "stats-file-name-length", "", fs.Config.StatsFileNameLength, "Max file name length in stats. 0 for no limit")
 	flags.FVarP(flagSet, &fs.Config.LogLevel, "log-level", "", "Log level DEBUG|INFO|NOTICE|ERROR")
+	flags.StringVarP(flagSet, &logFilter, "log-filter", "", "", "Only log about one remote, eg remote=NAME - errors are always logged")
 	flags.FVarP(flagSet, &fs.Config.StatsLogLevel, "stats-log-level", "", "Log level to show --stats output DEBUG|INFO|NOTICE|ERROR")
 	flags.FVarP(flagSet, &fs.Config.BwLimit, "bwlimit", "", "Bandwidth limit in kBytes/s, or use suffix b|k|M|G or a full timetable.")
 	flags.FVarP(flagSet, &fs.Config.BufferSize, "buffer-size", "", "Buffer size when copying files.")
+	flags.FVarP(flagSet, &fs.Config.BwLimitSmallFiles, "bwlimit-small-files", "", "Give transfers of files smaller than this a bigger share of --bwlimit so they aren't held up by big ones.")
+	flags.FVarP(flagSet, &fs.Config.MaxUploadRatePerFile, "max-upload-rate-per-file", "", "Smoothly limit each file transfer to this rate in kBytes/s, or use suffix b|k|M|G.")
 	flags.FVarP(flagSet, &fs.Config.StreamingUploadCutoff, "streaming-upload-cutoff", "", "Cutoff for switching to chunked upload if file size is unknown. Upload starts after reaching cutoff or when file ends.")
+	flags.StringVarP(flagSet, &fs.Config.BackendEncoding, "backend-encoding", "", fs.Config.BackendEncoding, "Default encoding of reserved characters in file names for backends which support it, eg '\\,:=%'.")
 	flags.FVarP(flagSet, &fs.Config.Dump, "dump", "", "List of items to dump from: "+fs.DumpFlagsList)
+	flags.BoolVarP(flagSet, &fs.Config.PauseSignals, "pause-signals", "", fs.Config.PauseSignals, "Pause transfers on SIGTSTP and resume them on SIGCONT")
+	flags.StringVarP(flagSet, &fs.Config.Pacer, "pacer", "", fs.Config.Pacer, "Pacer type or provider preset to use for API calls instead of the backend's. Use help to see a list.")
+	flags.DurationVarP(flagSet, &fs.Config.PacerMinSleep, "pacer-min-sleep", "", fs.Config.PacerMinSleep, "Override the minimum sleep between API calls if set.")
+	flags.IntVarP(flagSet, &fs.Config.PacerBurst, "pacer-burst", "", fs.Config.PacerBurst, "Override the number of API calls which can be made at once if set.")
+	flags.IntVarP(flagSet, &fs.Config.DirCacheWorkers, "dircache-workers", "", fs.Config.DirCacheWorkers, "Number of directory lookups to do at once on remotes which find directories by ID.")
+	flags.DurationVarP(flagSet, &fs.Config.DirCacheNotFoundTime, "dircache-not-found-time", "", fs.Config.DirCacheNotFoundTime, "How long to remember directories which weren't found on remotes which find directories by ID. 0 to disable.")
+	flags.BoolVarP(flagSet, &fs.Config.DirCachePersist, "dircache-persist", "", fs.Config.DirCachePersist, "Save the IDs of directories between runs on remotes which find directories by ID.")
+	flags.StringVarP(flagSet, &fs.Config.DumpFile, "dump-file", "", fs.Config.DumpFile, "Write all HTTP transactions to this file as JSON lines")
 
 }
 
+// parseHeaders parses the headers from flag or exits with a fatal error
+func parseHeaders(flag string, headers []string) (options []*fs.HTTPOption) {
+	for _, header := range headers {
+		option, err := fs.ParseHTTPOption(header)
+		if err != nil {
+			log.Fatalf("%s: %v", flag, err)
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
 // SetFlags converts any flags into config which weren't straight foward
 func SetFlags() {
 	if verbose >= 2 {
@@ -108,8 +164,12 @@ func SetFlags() {
 		}
 	}
 
-	if noTraverse {
-		fs.Logf(nil, "--no-traverse is obsolete and no longer needed - please remove")
+	if logFilter != "" {
+		const prefix = "remote="
+		if !strings.HasPrefix(logFilter, prefix) || len(logFilter) == len(prefix) {
+			log.Fatalf("--log-filter: Failed to parse %q - must be remote=NAME", logFilter)
+		}
+		fs.Config.LogFilterRemote = logFilter[len(prefix):]
 	}
 
 	if dumpHeaders {
@@ -139,10 +199,50 @@ func SetFlags() {
 		log.Fatalf(`Can't use --size-only and --ignore-size together.`)
 	}
 
+	if modifyWindow == "auto" {
+		fs.Config.ModifyWindowAuto = true
+	} else {
+		window, err := time.ParseDuration(modifyWindow)
+		if err != nil {
+			log.Fatalf("--modify-window: Failed to parse %q as duration or \"auto\": %v", modifyWindow, err)
+		}
+		fs.Config.ModifyWindow = window
+		fs.Config.ModifyWindowAuto = false
+	}
+
+	if strings.HasSuffix(maxDelete, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(maxDelete, "%"), 64)
+		if err != nil || percent < 0 || percent > 100 {
+			log.Fatalf("--max-delete: Failed to parse %q as a percentage between 0%% and 100%%", maxDelete)
+		}
+		fs.Config.MaxDelete = -1
+		fs.Config.MaxDeletePercent = percent
+	} else {
+		n, err := strconv.ParseInt(maxDelete, 10, 64)
+		if err != nil {
+			log.Fatalf("--max-delete: Failed to parse %q as a number of files or a percentage: %v", maxDelete, err)
+		}
+		fs.Config.MaxDelete = n
+		fs.Config.MaxDeletePercent = -1
+	}
+
 	if fs.Config.Suffix != "" && fs.Config.BackupDir == "" {
 		log.Fatalf(`Can only use --suffix with --backup-dir.`)
 	}
 
+	switch fs.Config.RetentionMode {
+	case fs.RetentionGovernance, fs.RetentionCompliance:
+	default:
+		log.Fatalf(`--retention-mode must be %q or %q.`, fs.RetentionGovernance, fs.RetentionCompliance)
+	}
+
+	if (fs.Config.ClientCert == "") != (fs.Config.ClientKey == "") {
+		log.Fatalf(`Can only use --client-cert with --client-key and vice versa.`)
+	}
+
+	fs.Config.UploadHeaders = parseHeaders("--header-upload", uploadHeaders)
+	fs.Config.DownloadHeaders = parseHeaders("--header-download", downloadHeaders)
+
 	if bindAddr != "" {
 		addrs, err := net.LookupIP(bindAddr)
 		if err != nil {
@@ -161,6 +261,15 @@ func SetFlags() {
 		fs.Config.DisableFeatures = strings.Split(disableFeatures, ",")
 	}
 
+	if fs.Config.Pacer != "" {
+		if fs.Config.Pacer == "help" {
+			log.Fatalf("Possible pacers are: %s\n", strings.Join(pacer.Names(), ", "))
+		}
+		if err := pacer.CheckName(fs.Config.Pacer); err != nil {
+			log.Fatalf("--pacer: %v", err)
+		}
+	}
+
 	// Make the config file absolute
 	configPath, err := filepath.Abs(config.ConfigPath)
 	if err == nil {
@@ -0,0 +1,214 @@
+// Export and import remotes to copy them between machines
+
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/obscure"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// exportPrefix starts a plain export
+	exportPrefix = "RCLONE_REMOTES_V1:"
+
+	// exportEncryptedPrefix starts an export encrypted with a password
+	exportEncryptedPrefix = "RCLONE_REMOTES_ENC_V1:"
+
+	// exportSaltSize is the size of the random salt which starts an
+	// encrypted export
+	exportSaltSize = 16
+
+	// exportNonceSize is the size of the nonce which follows it
+	exportNonceSize = 24
+)
+
+// exportKey makes the key to encrypt an export from the password and
+// salt using scrypt so that the password is expensive to guess
+func exportKey(password string, salt []byte) (*[32]byte, error) {
+	password, err := checkPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	keyBytes, err := scrypt.Key([]byte(password), salt, 16384, 8, 1, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make export key")
+	}
+	var key [32]byte
+	copy(key[:], keyBytes)
+	return &key, nil
+}
+
+// passwordOptions returns the names of the options of the remote
+// name which are stored obscured
+func passwordOptions(name string) (map[string]bool, error) {
+	fsType := FileGet(name, "type")
+	if fsType == "" {
+		return nil, errors.Errorf("remote %q not found in config", name)
+	}
+	ri, err := fs.Find(fsType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "remote %q", name)
+	}
+	passwords := make(map[string]bool)
+	for _, option := range ri.Options {
+		if option.IsPassword {
+			passwords[option.Name] = true
+		}
+	}
+	return passwords, nil
+}
+
+// Export returns the config of the remotes names as a single line of
+// text which Import can read on another machine.
+//
+// Passwords are revealed so that Import can obscure them again.  If
+// password is set the export is encrypted with it, otherwise anyone
+// with the export can read the passwords.
+func Export(names []string, password string) (string, error) {
+	remotes := make(map[string]map[string]string, len(names))
+	for _, name := range names {
+		name = strings.TrimSuffix(name, ":")
+		passwords, err := passwordOptions(name)
+		if err != nil {
+			return "", err
+		}
+		params := make(map[string]string)
+		for _, key := range getConfigData().GetKeyList(name) {
+			value := FileGet(name, key)
+			if passwords[key] && value != "" {
+				value, err = obscure.Reveal(value)
+				if err != nil {
+					return "", errors.Wrapf(err, "remote %q: failed to reveal %q", name, key)
+				}
+			}
+			params[key] = value
+		}
+		remotes[name] = params
+	}
+	data, err := json.Marshal(remotes)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal remotes")
+	}
+	if password == "" {
+		return exportPrefix + base64.RawURLEncoding.EncodeToString(data), nil
+	}
+	// The export is the salt, then the nonce, then the sealed data
+	header := make([]byte, exportSaltSize+exportNonceSize)
+	_, err = io.ReadFull(rand.Reader, header)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to make salt and nonce")
+	}
+	key, err := exportKey(password, header[:exportSaltSize])
+	if err != nil {
+		return "", err
+	}
+	var nonce [exportNonceSize]byte
+	copy(nonce[:], header[exportSaltSize:])
+	box := secretbox.Seal(header, data, &nonce, key)
+	return exportEncryptedPrefix + base64.RawURLEncoding.EncodeToString(box), nil
+}
+
+// ExportEncrypted returns whether the export needs a password to
+// import it
+func ExportEncrypted(export string) bool {
+	return strings.HasPrefix(strings.TrimSpace(export), exportEncryptedPrefix)
+}
+
+// decodeExport decodes the remotes from an export made by Export
+func decodeExport(export, password string) (remotes map[string]map[string]string, err error) {
+	export = strings.TrimSpace(export)
+	var data []byte
+	switch {
+	case strings.HasPrefix(export, exportPrefix):
+		data, err = base64.RawURLEncoding.DecodeString(export[len(exportPrefix):])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode export")
+		}
+	case strings.HasPrefix(export, exportEncryptedPrefix):
+		box, err := base64.RawURLEncoding.DecodeString(export[len(exportEncryptedPrefix):])
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode export")
+		}
+		if len(box) < exportSaltSize+exportNonceSize+secretbox.Overhead {
+			return nil, errors.New("export too short")
+		}
+		key, err := exportKey(password, box[:exportSaltSize])
+		if err != nil {
+			return nil, err
+		}
+		var nonce [exportNonceSize]byte
+		copy(nonce[:], box[exportSaltSize:exportSaltSize+exportNonceSize])
+		var ok bool
+		data, ok = secretbox.Open(nil, box[exportSaltSize+exportNonceSize:], &nonce, key)
+		if !ok {
+			return nil, errors.New("failed to decrypt export - wrong password?")
+		}
+	default:
+		return nil, errors.New("not an rclone export")
+	}
+	err = json.Unmarshal(data, &remotes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal export")
+	}
+	return remotes, nil
+}
+
+// Import adds the remotes in an export made by Export to the config
+// file, obscuring their passwords again, and returns their names.
+//
+// password is needed if the export is encrypted.  Remotes which
+// already exist are an error unless overwrite is set.
+func Import(export, password string, overwrite bool) (names []string, err error) {
+	remotes, err := decodeExport(export, password)
+	if err != nil {
+		return nil, err
+	}
+	for name := range remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		params := remotes[name]
+		if params["type"] == "" {
+			return nil, errors.Errorf("remote %q has no type", name)
+		}
+		if _, err := fs.Find(params["type"]); err != nil {
+			return nil, errors.Wrapf(err, "remote %q", name)
+		}
+		if !overwrite {
+			if _, err := getConfigData().GetSection(name); err == nil {
+				return nil, errors.Errorf("remote %q already exists", name)
+			}
+		}
+	}
+	for _, name := range names {
+		params := remotes[name]
+		getConfigData().DeleteSection(name)
+		getConfigData().SetValue(name, "type", params["type"])
+		passwords, err := passwordOptions(name)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range params {
+			if passwords[key] && value != "" {
+				value, err = obscure.Obscure(value)
+				if err != nil {
+					return nil, errors.Wrapf(err, "remote %q: failed to obscure %q", name, key)
+				}
+			}
+			getConfigData().SetValue(name, key, value)
+		}
+	}
+	SaveConfig()
+	return names, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/obscure"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	configKey = nil // reset password
+	tempFile, err := ioutil.TempFile("", "export.conf")
+	require.NoError(t, err)
+	path := tempFile.Name()
+	defer func() {
+		assert.NoError(t, os.Remove(path))
+	}()
+	assert.NoError(t, tempFile.Close())
+
+	oldConfigPath := ConfigPath
+	oldConfig := fs.Config
+	oldConfigFile := configFile
+	ConfigPath = path
+	fs.Config = &fs.ConfigInfo{}
+	configFile = nil
+	defer func() {
+		ConfigPath = oldConfigPath
+		fs.Config = oldConfig
+		configFile = oldConfigFile
+	}()
+	LoadConfig()
+
+	fs.Register(&fs.RegInfo{
+		Name: "config_test_export",
+		Options: []fs.Option{
+			{Name: "user"},
+			{Name: "pass", IsPassword: true},
+		},
+	})
+	getConfigData().SetValue("one", "type", "config_test_export")
+	getConfigData().SetValue("one", "user", "alice")
+	getConfigData().SetValue("one", "pass", obscure.MustObscure("secret"))
+	getConfigData().SetValue("two", "type", "config_test_export")
+
+	_, err = Export([]string{"missing"}, "")
+	assert.EqualError(t, err, `remote "missing" not found in config`)
+
+	for _, password := range []string{"", "export password"} {
+		export, err := Export([]string{"one:", "two"}, password)
+		require.NoError(t, err)
+		assert.Equal(t, password != "", ExportEncrypted(export))
+		if password != "" {
+			assert.NotContains(t, export, exportPrefix)
+			again, err := Export([]string{"one:", "two"}, password)
+			require.NoError(t, err)
+			assert.NotEqual(t, export, again, "salt and nonce should be random")
+			_, err = Import(export, "wrong password", true)
+			assert.EqualError(t, err, "failed to decrypt export - wrong password?")
+		}
+
+		_, err = Import(export, password, false)
+		assert.EqualError(t, err, `remote "one" already exists`)
+
+		getConfigData().DeleteSection("one")
+		getConfigData().DeleteSection("two")
+		names, err := Import(export, password, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"one", "two"}, names)
+		assert.Equal(t, "alice", FileGet("one", "user"))
+		assert.Equal(t, "config_test_export", FileGet("two", "type"))
+		pass := FileGet("one", "pass")
+		assert.NotEqual(t, "secret", pass)
+		assert.Equal(t, "secret", obscure.MustReveal(pass))
+	}
+
+	_, err = Import("potato", "", true)
+	assert.EqualError(t, err, "not an rclone export")
+}
@@ -28,6 +28,7 @@ func NewDirCopy(d Directory) *Dir {
 		modTime: d.ModTime(),
 		size:    d.Size(),
 		items:   d.Items(),
+		id:      d.ID(),
 	}
 }
 
@@ -67,7 +68,8 @@ func (d *Dir) ModTime() time.Time {
 	return time.Now()
 }
 
-// Size returns the size of the file
+// Size returns the size of the directory and its contents if known,
+// -1 for unknown
 func (d *Dir) Size() int64 {
 	return d.size
 }
@@ -3,7 +3,9 @@ package filter
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"os"
 	"path"
@@ -21,6 +23,7 @@ var Active = mustNewFilter(nil)
 // rule is one filter rule
 type rule struct {
 	Include bool
+	Glob    string // the glob the rule was made from, for explanations
 	Regexp  *regexp.Regexp
 }
 
@@ -38,6 +41,15 @@ func (r *rule) String() string {
 	return fmt.Sprintf("%s %s", c, r.Regexp.String())
 }
 
+// describe the rule as the glob it was made from
+func (r *rule) describe() string {
+	c := "-"
+	if r.Include {
+		c = "+"
+	}
+	return fmt.Sprintf("%s %s", c, r.Glob)
+}
+
 // rules is a slice of rules
 type rules struct {
 	rules    []rule
@@ -45,12 +57,13 @@ type rules struct {
 }
 
 // add adds a rule if it doesn't exist already
-func (rs *rules) add(Include bool, re *regexp.Regexp) {
+func (rs *rules) add(Include bool, glob string, re *regexp.Regexp) {
 	if rs.existing == nil {
 		rs.existing = make(map[string]struct{})
 	}
 	newRule := rule{
 		Include: Include,
+		Glob:    glob,
 		Regexp:  re,
 	}
 	newRuleString := newRule.String()
@@ -90,6 +103,9 @@ type Opt struct {
 	MaxAge         fs.Duration
 	MinSize        fs.SizeSuffix
 	MaxSize        fs.SizeSuffix
+	IncludeMime    []string
+	ExcludeMime    []string
+	Partition      string
 }
 
 // DefaultOpt is the default config for the filter
@@ -109,6 +125,8 @@ type Filter struct {
 	dirRules    rules
 	files       FilesMap // files if filesFrom
 	dirs        FilesMap // dirs from filesFrom
+	partIndex   uint32   // this partition 0..partCount-1 if --partition
+	partCount   uint32   // number of partitions if --partition or 0
 }
 
 // NewFilter parses the command line options and creates a Filter
@@ -136,6 +154,23 @@ func NewFilter(opt *Opt) (f *Filter, err error) {
 		fs.Debugf(nil, "--max-age %v to %v", f.Opt.MaxAge, f.ModTimeFrom)
 	}
 
+	for _, pattern := range append(f.Opt.IncludeMime, f.Opt.ExcludeMime...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "bad MIME type pattern %q", pattern)
+		}
+	}
+
+	if f.Opt.Partition != "" {
+		err = f.parsePartition(f.Opt.Partition)
+		if err != nil {
+			return nil, err
+		}
+		if f.Opt.DeleteExcluded {
+			return nil, errors.New("can't use --delete-excluded with --partition as it would delete the other partitions")
+		}
+		fs.Debugf(nil, "--partition %d/%d", f.partIndex+1, f.partCount)
+	}
+
 	addImplicitExclude := false
 	foundExcludeRule := false
 
@@ -230,7 +265,7 @@ func (f *Filter) addDirGlobs(Include bool, glob string) error {
 		if err != nil {
 			return err
 		}
-		f.dirRules.add(Include, dirRe)
+		f.dirRules.add(Include, glob, dirRe)
 	}
 	return nil
 }
@@ -247,7 +282,7 @@ func (f *Filter) Add(Include bool, glob string) error {
 		return err
 	}
 	if isFileRule {
-		f.fileRules.add(Include, re)
+		f.fileRules.add(Include, glob, re)
 		// If include rule work out what directories are needed to scan
 		// if exclude rule, we can't rule anything out
 		// Unless it is `*` which matches everything
@@ -260,7 +295,7 @@ func (f *Filter) Add(Include bool, glob string) error {
 		}
 	}
 	if isDirRule {
-		f.dirRules.add(Include, re)
+		f.dirRules.add(Include, glob, re)
 	}
 	return nil
 }
@@ -329,6 +364,37 @@ func (f *Filter) Clear() {
 	f.dirRules.clear()
 }
 
+// parsePartition parses a --partition i/n
+func (f *Filter) parsePartition(partition string) error {
+	var i, n uint32
+	_, err := fmt.Sscanf(partition, "%d/%d", &i, &n)
+	if err != nil || n == 0 || i < 1 || i > n || fmt.Sprintf("%d/%d", i, n) != partition {
+		return errors.Errorf("bad --partition %q - must be i/n with 1 <= i <= n, eg 1/4", partition)
+	}
+	f.partIndex = i - 1
+	f.partCount = n
+	return nil
+}
+
+// inPartition returns whether remote is in this --partition, which is
+// decided by its top level directory, or its name if it is in the
+// root.
+func (f *Filter) inPartition(remote string) bool {
+	if f.partCount == 0 {
+		return true
+	}
+	remote = strings.TrimLeft(remote, "/")
+	if i := strings.IndexRune(remote, '/'); i >= 0 {
+		remote = remote[:i]
+	}
+	if remote == "" {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(remote))
+	return h.Sum32()%f.partCount == f.partIndex
+}
+
 // InActive returns false if any filters are active
 func (f *Filter) InActive() bool {
 	return (f.files == nil &&
@@ -338,7 +404,10 @@ func (f *Filter) InActive() bool {
 		f.Opt.MaxSize < 0 &&
 		f.fileRules.len() == 0 &&
 		f.dirRules.len() == 0 &&
-		len(f.Opt.ExcludeFile) == 0)
+		len(f.Opt.ExcludeFile) == 0 &&
+		len(f.Opt.IncludeMime) == 0 &&
+		len(f.Opt.ExcludeMime) == 0 &&
+		f.partCount == 0)
 }
 
 // includeRemote returns whether this remote passes the filter rules.
@@ -370,12 +439,12 @@ func (f *Filter) ListContainsExcludeFile(entries fs.DirEntries) bool {
 
 // IncludeDirectory returns a function which checks whether this
 // directory should be included in the sync or not.
-func (f *Filter) IncludeDirectory(fs fs.Fs) func(string) (bool, error) {
+func (f *Filter) IncludeDirectory(ctx context.Context, fs fs.Fs) func(string) (bool, error) {
 	return func(remote string) (bool, error) {
 		remote = strings.Trim(remote, "/")
 		// first check if we need to remove directory based on
 		// the exclude file
-		excl, err := f.DirContainsExcludeFile(fs, remote)
+		excl, err := f.DirContainsExcludeFile(ctx, fs, remote)
 		if err != nil {
 			return false, err
 		}
@@ -383,6 +452,10 @@ func (f *Filter) IncludeDirectory(fs fs.Fs) func(string) (bool, error) {
 			return false, nil
 		}
 
+		if !f.inPartition(remote) {
+			return false, nil
+		}
+
 		// filesFrom takes precedence
 		if f.files != nil {
 			_, include := f.dirs[remote]
@@ -402,9 +475,9 @@ func (f *Filter) IncludeDirectory(fs fs.Fs) func(string) (bool, error) {
 // DirContainsExcludeFile checks if exclude file is present in a
 // directroy. If fs is nil, it works properly if ExcludeFile is an
 // empty string (for testing).
-func (f *Filter) DirContainsExcludeFile(fremote fs.Fs, remote string) (bool, error) {
+func (f *Filter) DirContainsExcludeFile(ctx context.Context, fremote fs.Fs, remote string) (bool, error) {
 	if len(f.Opt.ExcludeFile) > 0 {
-		exists, err := fs.FileExists(fremote, path.Join(remote, f.Opt.ExcludeFile))
+		exists, err := fs.FileExists(ctx, fremote, path.Join(remote, f.Opt.ExcludeFile))
 		if err != nil {
 			return false, err
 		}
@@ -418,6 +491,9 @@ func (f *Filter) DirContainsExcludeFile(fremote fs.Fs, remote string) (bool, err
 // Include returns whether this object should be included into the
 // sync or not
 func (f *Filter) Include(remote string, size int64, modTime time.Time) bool {
+	if !f.inPartition(remote) {
+		return false
+	}
 	// filesFrom takes precedence
 	if f.files != nil {
 		_, include := f.files[remote]
@@ -450,7 +526,127 @@ func (f *Filter) IncludeObject(o fs.Object) bool {
 		modTime = time.Unix(0, 0)
 	}
 
-	return f.Include(o.Remote(), o.Size(), modTime)
+	if !f.Include(o.Remote(), o.Size(), modTime) {
+		return false
+	}
+	include, _ := f.ExplainMimeType(o)
+	return include
+}
+
+// matchMime returns the first of patterns which matches mimeType or ""
+func matchMime(patterns []string, mimeType string) string {
+	for _, pattern := range patterns {
+		if match, _ := path.Match(strings.ToLower(pattern), mimeType); match {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// ExplainMimeType returns whether o passes the --include-mimetype and
+// --exclude-mimetype filters and a description of the one which
+// decided it, or "" if they aren't in use.
+//
+// The MIME type is read from the remote if it can, otherwise it is
+// guessed from the file extension.
+func (f *Filter) ExplainMimeType(o fs.ObjectInfo) (include bool, reason string) {
+	if len(f.Opt.IncludeMime) == 0 && len(f.Opt.ExcludeMime) == 0 {
+		return true, ""
+	}
+	mimeType := fs.MimeType(o)
+	if i := strings.IndexRune(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	if pattern := matchMime(f.Opt.ExcludeMime, mimeType); pattern != "" {
+		return false, fmt.Sprintf("excluded by --exclude-mimetype %s as %s", pattern, mimeType)
+	}
+	if len(f.Opt.IncludeMime) == 0 {
+		return true, ""
+	}
+	if pattern := matchMime(f.Opt.IncludeMime, mimeType); pattern != "" {
+		return true, fmt.Sprintf("included by --include-mimetype %s as %s", pattern, mimeType)
+	}
+	return false, fmt.Sprintf("excluded as %s doesn't match --include-mimetype", mimeType)
+}
+
+// explainRules returns whether remote is included by rules and why
+func explainRules(rs *rules, remote string) (include bool, reason string) {
+	for _, rule := range rs.rules {
+		if rule.Match(remote) {
+			action := "excluded"
+			if rule.Include {
+				action = "included"
+			}
+			return rule.Include, fmt.Sprintf("%s by rule %q", action, rule.describe())
+		}
+	}
+	return true, "included as no rule matched"
+}
+
+// Explain returns whether the object would be included into the sync
+// like Include, and a description of the filter which decided it.
+//
+// A size < 0 or a zero modTime are treated as unknown and aren't
+// checked against the size and age filters.
+func (f *Filter) Explain(remote string, size int64, modTime time.Time) (include bool, reason string) {
+	if !f.inPartition(remote) {
+		return false, fmt.Sprintf("excluded as not in --partition %s", f.Opt.Partition)
+	}
+	// filesFrom takes precedence
+	if f.files != nil {
+		if _, include = f.files[remote]; include {
+			return true, "included by --files-from"
+		}
+		return false, "excluded as not in --files-from"
+	}
+	if !modTime.IsZero() {
+		if !f.ModTimeFrom.IsZero() && modTime.Before(f.ModTimeFrom) {
+			return false, fmt.Sprintf("excluded by --max-age %v", f.Opt.MaxAge)
+		}
+		if !f.ModTimeTo.IsZero() && modTime.After(f.ModTimeTo) {
+			return false, fmt.Sprintf("excluded by --min-age %v", f.Opt.MinAge)
+		}
+	}
+	if size >= 0 {
+		if f.Opt.MinSize >= 0 && size < int64(f.Opt.MinSize) {
+			return false, fmt.Sprintf("excluded by --min-size %v", f.Opt.MinSize)
+		}
+		if f.Opt.MaxSize >= 0 && size > int64(f.Opt.MaxSize) {
+			return false, fmt.Sprintf("excluded by --max-size %v", f.Opt.MaxSize)
+		}
+	}
+	return explainRules(&f.fileRules, remote)
+}
+
+// ExplainDirectory returns whether the directory would be included
+// into the sync like IncludeDirectory, and a description of the
+// filter which decided it.
+//
+// If fremote is nil then --exclude-if-present isn't checked.
+func (f *Filter) ExplainDirectory(ctx context.Context, fremote fs.Fs, remote string) (include bool, reason string, err error) {
+	remote = strings.Trim(remote, "/")
+	if fremote != nil {
+		excl, err := f.DirContainsExcludeFile(ctx, fremote, remote)
+		if err != nil {
+			return false, "", err
+		}
+		if excl {
+			return false, fmt.Sprintf("excluded by --exclude-if-present %s", f.Opt.ExcludeFile), nil
+		}
+	}
+	if !f.inPartition(remote) {
+		return false, fmt.Sprintf("excluded as not in --partition %s", f.Opt.Partition), nil
+	}
+	// filesFrom takes precedence
+	if f.files != nil {
+		if _, include = f.dirs[remote]; include {
+			return true, "included as it contains files in --files-from", nil
+		}
+		return false, "excluded as it contains no files in --files-from", nil
+	}
+	include, reason = explainRules(&f.dirRules, remote+"/")
+	return include, reason, nil
 }
 
 // forEachLine calls fn on every line in the file pointed to by path
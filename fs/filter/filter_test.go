@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -114,6 +116,8 @@ func testInclude(t *testing.T, f *Filter, tests []includeTest) {
 	for _, test := range tests {
 		got := f.Include(test.in, test.size, time.Unix(test.modTime, 0))
 		assert.Equal(t, test.want, got, fmt.Sprintf("in=%q, size=%v, modTime=%v", test.in, test.size, time.Unix(test.modTime, 0)))
+		got, reason := f.Explain(test.in, test.size, time.Unix(test.modTime, 0))
+		assert.Equal(t, test.want, got, fmt.Sprintf("Explain in=%q reason=%q", test.in, reason))
 	}
 }
 
@@ -124,9 +128,12 @@ type includeDirTest struct {
 
 func testDirInclude(t *testing.T, f *Filter, tests []includeDirTest) {
 	for _, test := range tests {
-		got, err := f.IncludeDirectory(nil)(test.in)
+		got, err := f.IncludeDirectory(context.Background(), nil)(test.in)
 		require.NoError(t, err)
 		assert.Equal(t, test.want, got, test.in)
+		got, reason, err := f.ExplainDirectory(context.Background(), nil, test.in)
+		require.NoError(t, err)
+		assert.Equal(t, test.want, got, fmt.Sprintf("ExplainDirectory in=%q reason=%q", test.in, reason))
 	}
 }
 
@@ -207,6 +214,89 @@ func TestNewFilterMaxSize(t *testing.T) {
 	assert.False(t, f.InActive())
 }
 
+// mimeObject is an object with a MIME type read from the remote
+type mimeObject struct {
+	mockobject.Object
+	mimeType string
+}
+
+func (o mimeObject) MimeType() string { return o.mimeType }
+
+func TestNewFilterMimeType(t *testing.T) {
+	opt := DefaultOpt
+	opt.IncludeMime = []string{"video/*", "audio/mpeg"}
+	opt.ExcludeMime = []string{"video/x-msvideo"}
+	f, err := NewFilter(&opt)
+	require.NoError(t, err)
+	assert.False(t, f.InActive())
+	for _, test := range []struct {
+		o      fs.Object
+		want   bool
+		reason string
+	}{
+		{mimeObject{mockobject.Object("film.mp4"), "video/mp4"}, true, "included by --include-mimetype video/* as video/mp4"},
+		{mimeObject{mockobject.Object("film.avi"), "video/x-msvideo"}, false, "excluded by --exclude-mimetype video/x-msvideo as video/x-msvideo"},
+		{mimeObject{mockobject.Object("song.mp3"), "audio/mpeg"}, true, "included by --include-mimetype audio/mpeg as audio/mpeg"},
+		{mimeObject{mockobject.Object("no-extension"), "Video/MP4; codecs=avc1"}, true, "included by --include-mimetype video/* as video/mp4"},
+		{mimeObject{mockobject.Object("film.mp4"), "text/plain"}, false, "excluded as text/plain doesn't match --include-mimetype"},
+		// guessed from the extension if the remote can't read it
+		{mockobject.Object("photo.jpg"), false, "excluded as image/jpeg doesn't match --include-mimetype"},
+	} {
+		assert.Equal(t, test.want, f.IncludeObject(test.o), test.o.Remote())
+		include, reason := f.ExplainMimeType(test.o)
+		assert.Equal(t, test.want, include, test.o.Remote())
+		assert.Equal(t, test.reason, reason, test.o.Remote())
+	}
+
+	opt.IncludeMime = []string{"video/["}
+	_, err = NewFilter(&opt)
+	assert.Error(t, err)
+}
+
+func TestNewFilterPartition(t *testing.T) {
+	const n = 3
+	// every top level name ends up in exactly one partition with
+	// everything below it
+	for _, remote := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		count := 0
+		for i := 1; i <= n; i++ {
+			opt := DefaultOpt
+			opt.Partition = fmt.Sprintf("%d/%d", i, n)
+			f, err := NewFilter(&opt)
+			require.NoError(t, err)
+			assert.False(t, f.InActive())
+			include := f.Include(remote, 0, time.Now())
+			assert.Equal(t, include, f.Include(remote+"/sub/file.txt", 0, time.Now()), remote)
+			includeDir, err := f.IncludeDirectory(context.Background(), nil)(remote + "/sub")
+			require.NoError(t, err)
+			assert.Equal(t, include, includeDir, remote)
+			if include {
+				count++
+			} else {
+				_, reason := f.Explain(remote, 0, time.Now())
+				assert.Equal(t, "excluded as not in --partition "+opt.Partition, reason)
+			}
+			includeDir, err = f.IncludeDirectory(context.Background(), nil)("")
+			require.NoError(t, err)
+			assert.True(t, includeDir, "root always included")
+		}
+		assert.Equal(t, 1, count, remote)
+	}
+
+	for _, bad := range []string{"0/3", "4/3", "1/0", "1", "a/b", "1/3x"} {
+		opt := DefaultOpt
+		opt.Partition = bad
+		_, err := NewFilter(&opt)
+		assert.Error(t, err, bad)
+	}
+
+	opt := DefaultOpt
+	opt.Partition = "1/2"
+	opt.DeleteExcluded = true
+	_, err := NewFilter(&opt)
+	assert.Error(t, err)
+}
+
 func TestNewFilterMinAndMaxAge(t *testing.T) {
 	f, err := NewFilter(nil)
 	require.NoError(t, err)
@@ -436,3 +526,47 @@ func TestFilterMatchesFromDocs(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterExplain(t *testing.T) {
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	require.NoError(t, f.AddRule("- *.tmp"))
+	require.NoError(t, f.AddRule("+ /photos/**"))
+	require.NoError(t, f.AddRule("- *"))
+	f.Opt.MaxSize = 100
+
+	for _, test := range []struct {
+		in         string
+		size       int64
+		wantInc    bool
+		wantReason string
+	}{
+		{"photos/a.jpg", 10, true, `included by rule "+ /photos/**"`},
+		{"photos/a.tmp", 10, false, `excluded by rule "- *.tmp"`},
+		{"b.jpg", 10, false, `excluded by rule "- *"`},
+		{"photos/big.jpg", 101, false, "excluded by --max-size 100"},
+		{"photos/unknown.jpg", -1, true, `included by rule "+ /photos/**"`},
+	} {
+		include, reason := f.Explain(test.in, test.size, time.Time{})
+		assert.Equal(t, test.wantInc, include, test.in)
+		assert.Equal(t, test.wantReason, reason, test.in)
+	}
+
+	include, reason, err := f.ExplainDirectory(context.Background(), nil, "photos/")
+	require.NoError(t, err)
+	assert.True(t, include)
+	assert.Equal(t, `included by rule "+ /photos/**"`, reason)
+
+	f, err = NewFilter(nil)
+	require.NoError(t, err)
+	_, reason = f.Explain("file", 0, time.Time{})
+	assert.Equal(t, "included as no rule matched", reason)
+	require.NoError(t, f.AddFile("dir/file"))
+	include, reason = f.Explain("other", 0, time.Time{})
+	assert.False(t, include)
+	assert.Equal(t, "excluded as not in --files-from", reason)
+	include, reason, err = f.ExplainDirectory(context.Background(), nil, "dir")
+	require.NoError(t, err)
+	assert.True(t, include)
+	assert.Equal(t, "included as it contains files in --files-from", reason)
+}
@@ -2,14 +2,20 @@
 package filterflags
 
 import (
+	"strconv"
+	"strings"
+
+	"github.com/ncw/rclone/fs/config"
 	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/fs/filter"
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 )
 
 // Options set by command line flags
 var (
-	Opt = filter.DefaultOpt
+	Opt     = filter.DefaultOpt
+	Profile string // name of the filter profile to use
 )
 
 // AddFlags adds the non filing system specific flags to the command
@@ -27,5 +33,70 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.FVarP(flagSet, &Opt.MaxAge, "max-age", "", "Only transfer files younger than this in s or suffix ms|s|m|h|d|w|M|y")
 	flags.FVarP(flagSet, &Opt.MinSize, "min-size", "", "Only transfer files bigger than this in k or suffix b|k|M|G")
 	flags.FVarP(flagSet, &Opt.MaxSize, "max-size", "", "Only transfer files smaller than this in k or suffix b|k|M|G")
+	flags.StringArrayVarP(flagSet, &Opt.IncludeMime, "include-mimetype", "", nil, "Include only files with a MIME type matching this pattern, eg video/*")
+	flags.StringArrayVarP(flagSet, &Opt.ExcludeMime, "exclude-mimetype", "", nil, "Exclude files with a MIME type matching this pattern, eg image/*")
+	flags.StringVarP(flagSet, &Opt.Partition, "partition", "", "", "Only transfer the top level directories in partition i/n of n, eg 1/4")
+	flags.StringVarP(flagSet, &Profile, "filter-profile", "", "", "Add the filters in the [filters.NAME] section of the config file")
 	//cvsExclude     = BoolP("cvs-exclude", "C", false, "Exclude files in the same way CVS does")
 }
+
+// AddProfile adds the filters from the [filters.name] section of the
+// config file to opt.
+//
+// The keys in the section are the names of the filter flags with _
+// for -, eg include or min_size.  Flags which can be repeated can
+// have a number on the end of the key to make it unique, eg filter1,
+// filter2.  The rules are added in the order they appear in the
+// section after any already in opt.
+func AddProfile(opt *filter.Opt, name string) error {
+	section := config.FilterProfilePrefix + name
+	keys := config.FileKeys(section)
+	if keys == nil {
+		return errors.Errorf("filter profile %q not found - add a [%s] section to the config file", name, section)
+	}
+	for _, key := range keys {
+		value := config.FileGet(section, key)
+		flag := strings.Replace(strings.TrimRight(key, "0123456789"), "-", "_", -1)
+		var err error
+		switch flag {
+		case "filter":
+			opt.FilterRule = append(opt.FilterRule, value)
+		case "filter_from":
+			opt.FilterFrom = append(opt.FilterFrom, value)
+		case "exclude":
+			opt.ExcludeRule = append(opt.ExcludeRule, value)
+		case "exclude_from":
+			opt.ExcludeFrom = append(opt.ExcludeFrom, value)
+		case "exclude_if_present":
+			opt.ExcludeFile = value
+		case "include":
+			opt.IncludeRule = append(opt.IncludeRule, value)
+		case "include_from":
+			opt.IncludeFrom = append(opt.IncludeFrom, value)
+		case "files_from":
+			opt.FilesFrom = append(opt.FilesFrom, value)
+		case "delete_excluded":
+			opt.DeleteExcluded, err = strconv.ParseBool(value)
+		case "min_age":
+			err = opt.MinAge.Set(value)
+		case "max_age":
+			err = opt.MaxAge.Set(value)
+		case "min_size":
+			err = opt.MinSize.Set(value)
+		case "max_size":
+			err = opt.MaxSize.Set(value)
+		case "include_mimetype":
+			opt.IncludeMime = append(opt.IncludeMime, value)
+		case "exclude_mimetype":
+			opt.ExcludeMime = append(opt.ExcludeMime, value)
+		case "partition":
+			opt.Partition = value
+		default:
+			return errors.Errorf("unknown key %q in filter profile %q", key, name)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "bad %s in filter profile %q", key, name)
+		}
+	}
+	return nil
+}
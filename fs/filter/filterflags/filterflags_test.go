@@ -0,0 +1,39 @@
+package filterflags
+
+import (
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddProfile(t *testing.T) {
+	section := config.FilterProfilePrefix + "filterflags_test"
+	config.FileSet(section, "filter1", "- *.tmp")
+	config.FileSet(section, "filter2", "+ *.{jpg,png}")
+	config.FileSet(section, "include", "*.raw")
+	config.FileSet(section, "min_size", "10k")
+	config.FileSet(section, "max-age", "1d")
+	config.FileSet(section, "delete_excluded", "true")
+
+	opt := filter.DefaultOpt
+	opt.FilterRule = []string{"- .git/**"}
+	require.NoError(t, AddProfile(&opt, "filterflags_test"))
+	assert.Equal(t, []string{"- .git/**", "- *.tmp", "+ *.{jpg,png}"}, opt.FilterRule)
+	assert.Equal(t, []string{"*.raw"}, opt.IncludeRule)
+	assert.Equal(t, fs.SizeSuffix(10*1024), opt.MinSize)
+	assert.Equal(t, fs.SizeSuffix(-1), opt.MaxSize)
+	assert.True(t, opt.MaxAge.IsSet())
+	assert.True(t, opt.DeleteExcluded)
+
+	err := AddProfile(&opt, "filterflags_test_missing")
+	assert.Error(t, err)
+
+	config.FileSet(section, "potato", "true")
+	err = AddProfile(&opt, "filterflags_test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown key")
+}
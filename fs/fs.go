@@ -2,6 +2,8 @@
 package fs
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
@@ -12,6 +14,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ncw/rclone/fs/driveletter"
@@ -53,6 +56,7 @@ var (
 	ErrorLevelNotSupported           = errors.New("level value not supported")
 	ErrorListAborted                 = errors.New("list aborted")
 	ErrorListBucketRequired          = errors.New("bucket or container name is needed in remote")
+	ErrorListTruncated               = errors.New("directory listing truncated")
 	ErrorIsFile                      = errors.New("is a file not a directory")
 	ErrorNotAFile                    = errors.New("is a not a regular file")
 	ErrorNotDeleting                 = errors.New("not deleting files as there were IO errors")
@@ -61,6 +65,14 @@ var (
 	ErrorDirectoryNotEmpty           = errors.New("directory not empty")
 	ErrorImmutableModified           = errors.New("immutable file modified")
 	ErrorPermissionDenied            = errors.New("permission denied")
+	ErrorNotWithVersions             = errors.New("can't modify or delete objects in --versions mode")
+	ErrorReadOnly                    = errors.New("remote is read only")
+	ErrorWriteOnly                   = errors.New("remote is write only - files can't be read")
+	ErrorDestinationChanged          = errors.New("destination changed since it was checked - not overwriting it")
+	ErrorSpoolingNotAllowed          = errors.New("can't spool to local disk with --no-spool")
+	ErrorCantPutByHash               = errors.New("can't put object by hash - content not on remote")
+	ErrorLinkExpiryUnsupported       = errors.New("this remote can't make links which expire")
+	ErrorLinkPasswordUnsupported     = errors.New("this remote can't make password protected links")
 )
 
 // RegInfo provides information about a filesystem
@@ -77,6 +89,11 @@ type RegInfo struct {
 	Config func(string) `json:"-"`
 	// Options for the Fs configuration
 	Options []Option
+	// FsType and ObjectType are nil pointers of the types of the
+	// Fs and Object so the optional interfaces they implement can
+	// be found without making an Fs.  They may be left nil.
+	FsType     Fs     `json:"-"`
+	ObjectType Object `json:"-"`
 }
 
 // Option is describes an option for the config wizard
@@ -131,28 +148,28 @@ type Fs interface {
 	//
 	// This should return ErrDirNotFound if the directory isn't
 	// found.
-	List(dir string) (entries DirEntries, err error)
+	List(ctx context.Context, dir string) (entries DirEntries, err error)
 
 	// NewObject finds the Object at remote.  If it can't be found
 	// it returns the error ErrorObjectNotFound.
-	NewObject(remote string) (Object, error)
+	NewObject(ctx context.Context, remote string) (Object, error)
 
 	// Put in to the remote path with the modTime given of the given size
 	//
 	// May create the object even if it returns an error - if so
 	// will return the object and the error, otherwise will return
 	// nil and the error
-	Put(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	Put(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 
 	// Mkdir makes the directory (container, bucket)
 	//
 	// Shouldn't return an error if it already exists
-	Mkdir(dir string) error
+	Mkdir(ctx context.Context, dir string) error
 
 	// Rmdir removes the directory (container, bucket) if empty
 	//
 	// Return an error if it doesn't exist or isn't empty
-	Rmdir(dir string) error
+	Rmdir(ctx context.Context, dir string) error
 }
 
 // Info provides a read only interface to information about a filesystem.
@@ -181,16 +198,16 @@ type Object interface {
 	ObjectInfo
 
 	// SetModTime sets the metadata on the object to set the modification date
-	SetModTime(time.Time) error
+	SetModTime(ctx context.Context, t time.Time) error
 
 	// Open opens the file for read.  Call Close() on the returned io.ReadCloser
-	Open(options ...OpenOption) (io.ReadCloser, error)
+	Open(ctx context.Context, options ...OpenOption) (io.ReadCloser, error)
 
 	// Update in to the object with the modTime given of the given size
-	Update(in io.Reader, src ObjectInfo, options ...OpenOption) error
+	Update(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) error
 
 	// Removes this object
-	Remove() error
+	Remove(ctx context.Context) error
 }
 
 // ObjectInfo provides read only information about an object.
@@ -227,6 +244,10 @@ type DirEntry interface {
 }
 
 // Directory is a filesystem like directory provided by an Fs
+//
+// Backends which are told the ID, item count or total size of a
+// directory when listing should return them so they can be shown, eg
+// by lsjson.  Size returns -1 if the size isn't known.
 type Directory interface {
 	DirEntry
 
@@ -246,6 +267,46 @@ type MimeTyper interface {
 	MimeType() string
 }
 
+// VersionIDer is an optional interface for Object
+type VersionIDer interface {
+	// VersionID returns an ID of the version of the Object, eg
+	// an ETag or generation number, which changes whenever it is
+	// modified, or "" if not known
+	VersionID() string
+}
+
+// SetTierer is an optional interface for Object
+type SetTierer interface {
+	// SetTier changes the storage tier (or class) of the Object
+	// without re-uploading its content
+	SetTier(ctx context.Context, tier string) error
+}
+
+// GetTierer is an optional interface for Object
+type GetTierer interface {
+	// GetTier returns the storage tier (or class) of the Object
+	// or "" if not known
+	GetTier() string
+}
+
+// VersionRestorer is an optional interface for Object
+type VersionRestorer interface {
+	// VersionTime returns the time this version of the Object was
+	// made on the remote
+	VersionTime() time.Time
+
+	// RestoreVersion makes this version of the Object the current
+	// one, leaving the existing versions in place
+	RestoreVersion(ctx context.Context) error
+}
+
+// TrashRestorer is an optional interface for Object
+type TrashRestorer interface {
+	// RestoreTrash moves the Object out of the trash back to the
+	// directory it was deleted from
+	RestoreTrash(ctx context.Context) error
+}
+
 // ObjectUnWrapper is an optional interface for Object
 type ObjectUnWrapper interface {
 	// UnWrap returns the Object that this Object is wrapping or
@@ -260,7 +321,7 @@ type ObjectUnWrapper interface {
 type ListRCallback func(entries DirEntries) error
 
 // ListRFn is defines the call used to recursively list a directory
-type ListRFn func(dir string, callback ListRCallback) error
+type ListRFn func(ctx context.Context, dir string, callback ListRCallback) error
 
 // NewUsageValue makes a valid value
 func NewUsageValue(value int64) *int64 {
@@ -281,6 +342,36 @@ type Usage struct {
 	Objects *int64 `json:"objects,omitempty"` // objects in the storage system
 }
 
+// ChangeAction is what happened to a path found by ListChanges
+type ChangeAction int
+
+// Types of change found by ListChanges
+const (
+	ChangeCreated ChangeAction = iota
+	ChangeModified
+	ChangeDeleted
+)
+
+// String turns a ChangeAction into a string
+func (a ChangeAction) String() string {
+	switch a {
+	case ChangeCreated:
+		return "created"
+	case ChangeModified:
+		return "modified"
+	case ChangeDeleted:
+		return "deleted"
+	}
+	return fmt.Sprintf("ChangeAction(%d)", int(a))
+}
+
+// Change is a change to a path found by ListChanges
+type Change struct {
+	Path      string       // path relative to the root of the Fs
+	EntryType EntryType    // whether the path is a directory or an object
+	Action    ChangeAction // what happened to the path
+}
+
 // Features describe the optional features of the Fs
 type Features struct {
 	// Feature flags, whether Fs
@@ -290,6 +381,13 @@ type Features struct {
 	WriteMimeType           bool // can set the mime type of objects
 	CanHaveEmptyDirectories bool // can have empty directories
 	BucketBased             bool // is bucket based (like s3, swift etc)
+	SetTier                 bool // allows the storage tier of objects to be changed
+	GetTier                 bool // allows the storage tier of objects to be read
+	Versions                bool // can list old versions of objects with --versions
+	Trash                   bool // can list the files in the trash with --trash
+	ObjectLock              bool // can lock objects with a RetentionOption when uploading
+	ConditionalUpdate       bool // can make Update fail if the object has changed with an IfMatchOption
+	SlowHash                bool // works out hashes by reading the whole object
 
 	// Purge all files in the root and the root directory
 	//
@@ -297,7 +395,7 @@ type Features struct {
 	// quicker than just running Remove() on the result of List()
 	//
 	// Return an error if it doesn't exist
-	Purge func() error
+	Purge func(ctx context.Context) error
 
 	// Copy src to this remote using server side copy operations.
 	//
@@ -308,7 +406,7 @@ type Features struct {
 	// Will only be called if src.Fs().Name() == f.Name()
 	//
 	// If it isn't possible then return fs.ErrorCantCopy
-	Copy func(src Object, remote string) (Object, error)
+	Copy func(ctx context.Context, src Object, remote string) (Object, error)
 
 	// Move src to this remote using server side move operations.
 	//
@@ -319,7 +417,7 @@ type Features struct {
 	// Will only be called if src.Fs().Name() == f.Name()
 	//
 	// If it isn't possible then return fs.ErrorCantMove
-	Move func(src Object, remote string) (Object, error)
+	Move func(ctx context.Context, src Object, remote string) (Object, error)
 
 	// DirMove moves src, srcRemote to this remote at dstRemote
 	// using server side move operations.
@@ -329,13 +427,25 @@ type Features struct {
 	// If it isn't possible then return fs.ErrorCantDirMove
 	//
 	// If destination exists then return fs.ErrorDirExists
-	DirMove func(src Fs, srcRemote, dstRemote string) error
+	DirMove func(ctx context.Context, src Fs, srcRemote, dstRemote string) error
+
+	// DirSetModTime sets the modification time of the directory
+	// dir which must exist
+	DirSetModTime func(ctx context.Context, dir string, modTime time.Time) error
 
 	// ChangeNotify calls the passed function with a path
 	// that has had changes. If the implementation
 	// uses polling, it should adhere to the given interval.
 	ChangeNotify func(func(string, EntryType), time.Duration) chan bool
 
+	// ListChanges calls fn for each change made to the Fs since
+	// cursor and returns the cursor to read the changes after
+	// those from.
+	//
+	// If cursor is "" it returns the current cursor without
+	// calling fn.
+	ListChanges func(ctx context.Context, cursor string, fn func(Change) error) (newCursor string, err error)
+
 	// UnWrap returns the Fs that this Fs is wrapping
 	UnWrap func() Fs
 
@@ -350,7 +460,12 @@ type Features struct {
 	DirCacheFlush func()
 
 	// PublicLink generates a public link to the remote path (usually readable by anyone)
-	PublicLink func(remote string) (string, error)
+	//
+	// If expire is set the link should stop working after that
+	// long, and if password isn't "" the link should need it.  If
+	// the remote can't do these it should return
+	// fs.ErrorLinkExpiryUnsupported or fs.ErrorLinkPasswordUnsupported.
+	PublicLink func(ctx context.Context, remote string, expire Duration, password string) (string, error)
 
 	// Put in to the remote path with the modTime given of the given size
 	//
@@ -360,24 +475,33 @@ type Features struct {
 	//
 	// May create duplicates or return errors if src already
 	// exists.
-	PutUnchecked func(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutUnchecked func(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 
 	// PutStream uploads to the remote path with the modTime given of indeterminate size
 	//
 	// May create the object even if it returns an error - if so
 	// will return the object and the error, otherwise will return
 	// nil and the error
-	PutStream func(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutStream func(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+
+	// PutByHash creates the object at src.Remote() from content
+	// the remote already has with the same hash as src, without
+	// uploading the data, replacing any object already there.
+	//
+	// It reads the hash with src.Hash.  If the remote doesn't have
+	// the content it should return fs.ErrorCantPutByHash and the
+	// data will be uploaded as normal.
+	PutByHash func(ctx context.Context, src ObjectInfo, options ...OpenOption) (Object, error)
 
 	// MergeDirs merges the contents of all the directories passed
 	// in into the first one and rmdirs the other directories.
-	MergeDirs func([]Directory) error
+	MergeDirs func(ctx context.Context, dirs []Directory) error
 
 	// CleanUp the trash in the Fs
 	//
 	// Implement this if you have a way of emptying the trash or
 	// otherwise cleaning up old versions of files.
-	CleanUp func() error
+	CleanUp func(ctx context.Context) error
 
 	// ListR lists the objects and directories of the Fs starting
 	// from dir recursively into out.
@@ -398,7 +522,11 @@ type Features struct {
 	ListR ListRFn
 
 	// About gets quota information from the Fs
-	About func() (*Usage, error)
+	About func(ctx context.Context) (*Usage, error)
+
+	// Shutdown writes out anything the Fs has buffered.  It is
+	// called when the command using the Fs has finished.
+	Shutdown func(ctx context.Context) error
 }
 
 // Disable nil's out the named feature.  If it isn't found then it
@@ -457,9 +585,15 @@ func (ft *Features) Fill(f Fs) *Features {
 	if do, ok := f.(DirMover); ok {
 		ft.DirMove = do.DirMove
 	}
+	if do, ok := f.(DirSetModTimer); ok {
+		ft.DirSetModTime = do.DirSetModTime
+	}
 	if do, ok := f.(ChangeNotifier); ok {
 		ft.ChangeNotify = do.ChangeNotify
 	}
+	if do, ok := f.(ChangeLister); ok {
+		ft.ListChanges = do.ListChanges
+	}
 	if do, ok := f.(UnWrapper); ok {
 		ft.UnWrap = do.UnWrap
 	}
@@ -479,6 +613,9 @@ func (ft *Features) Fill(f Fs) *Features {
 	if do, ok := f.(PutStreamer); ok {
 		ft.PutStream = do.PutStream
 	}
+	if do, ok := f.(PutByHasher); ok {
+		ft.PutByHash = do.PutByHash
+	}
 	if do, ok := f.(MergeDirser); ok {
 		ft.MergeDirs = do.MergeDirs
 	}
@@ -491,6 +628,9 @@ func (ft *Features) Fill(f Fs) *Features {
 	if do, ok := f.(Abouter); ok {
 		ft.About = do.About
 	}
+	if do, ok := f.(Shutdowner); ok {
+		ft.Shutdown = do.Shutdown
+	}
 	return ft.DisableList(Config.DisableFeatures)
 }
 
@@ -508,6 +648,13 @@ func (ft *Features) Mask(f Fs) *Features {
 	ft.WriteMimeType = ft.WriteMimeType && mask.WriteMimeType
 	ft.CanHaveEmptyDirectories = ft.CanHaveEmptyDirectories && mask.CanHaveEmptyDirectories
 	ft.BucketBased = ft.BucketBased && mask.BucketBased
+	ft.SetTier = ft.SetTier && mask.SetTier
+	ft.GetTier = ft.GetTier && mask.GetTier
+	ft.Versions = ft.Versions && mask.Versions
+	ft.Trash = ft.Trash && mask.Trash
+	ft.ObjectLock = ft.ObjectLock && mask.ObjectLock
+	ft.ConditionalUpdate = ft.ConditionalUpdate && mask.ConditionalUpdate
+	ft.SlowHash = ft.SlowHash && mask.SlowHash
 	if mask.Purge == nil {
 		ft.Purge = nil
 	}
@@ -520,9 +667,15 @@ func (ft *Features) Mask(f Fs) *Features {
 	if mask.DirMove == nil {
 		ft.DirMove = nil
 	}
+	if mask.DirSetModTime == nil {
+		ft.DirSetModTime = nil
+	}
 	if mask.ChangeNotify == nil {
 		ft.ChangeNotify = nil
 	}
+	if mask.ListChanges == nil {
+		ft.ListChanges = nil
+	}
 	// if mask.UnWrap == nil {
 	// 	ft.UnWrap = nil
 	// }
@@ -535,6 +688,9 @@ func (ft *Features) Mask(f Fs) *Features {
 	if mask.PutStream == nil {
 		ft.PutStream = nil
 	}
+	if mask.PutByHash == nil {
+		ft.PutByHash = nil
+	}
 	if mask.MergeDirs == nil {
 		ft.MergeDirs = nil
 	}
@@ -547,6 +703,9 @@ func (ft *Features) Mask(f Fs) *Features {
 	if mask.About == nil {
 		ft.About = nil
 	}
+	if mask.Shutdown == nil {
+		ft.Shutdown = nil
+	}
 	return ft.DisableList(Config.DisableFeatures)
 }
 
@@ -582,7 +741,7 @@ type Purger interface {
 	// quicker than just running Remove() on the result of List()
 	//
 	// Return an error if it doesn't exist
-	Purge() error
+	Purge(ctx context.Context) error
 }
 
 // Copier is an optional interface for Fs
@@ -596,7 +755,7 @@ type Copier interface {
 	// Will only be called if src.Fs().Name() == f.Name()
 	//
 	// If it isn't possible then return fs.ErrorCantCopy
-	Copy(src Object, remote string) (Object, error)
+	Copy(ctx context.Context, src Object, remote string) (Object, error)
 }
 
 // Mover is an optional interface for Fs
@@ -610,7 +769,7 @@ type Mover interface {
 	// Will only be called if src.Fs().Name() == f.Name()
 	//
 	// If it isn't possible then return fs.ErrorCantMove
-	Move(src Object, remote string) (Object, error)
+	Move(ctx context.Context, src Object, remote string) (Object, error)
 }
 
 // DirMover is an optional interface for Fs
@@ -623,7 +782,7 @@ type DirMover interface {
 	// If it isn't possible then return fs.ErrorCantDirMove
 	//
 	// If destination exists then return fs.ErrorDirExists
-	DirMove(src Fs, srcRemote, dstRemote string) error
+	DirMove(ctx context.Context, src Fs, srcRemote, dstRemote string) error
 }
 
 // ChangeNotifier is an optional interface for Fs
@@ -634,6 +793,17 @@ type ChangeNotifier interface {
 	ChangeNotify(func(string, EntryType), time.Duration) chan bool
 }
 
+// ChangeLister is an optional interface for Fs
+type ChangeLister interface {
+	// ListChanges calls fn for each change made to the Fs since
+	// cursor and returns the cursor to read the changes after
+	// those from.
+	//
+	// If cursor is "" it returns the current cursor without
+	// calling fn.
+	ListChanges(ctx context.Context, cursor string, fn func(Change) error) (newCursor string, err error)
+}
+
 // UnWrapper is an optional interfaces for Fs
 type UnWrapper interface {
 	// UnWrap returns the Fs that this Fs is wrapping
@@ -665,7 +835,7 @@ type PutUncheckeder interface {
 	//
 	// May create duplicates or return errors if src already
 	// exists.
-	PutUnchecked(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutUnchecked(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 }
 
 // PutStreamer is an optional interface for Fs
@@ -675,20 +845,44 @@ type PutStreamer interface {
 	// May create the object even if it returns an error - if so
 	// will return the object and the error, otherwise will return
 	// nil and the error
-	PutStream(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutStream(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+}
+
+// PutByHasher is an optional interface for Fs
+type PutByHasher interface {
+	// PutByHash creates the object at src.Remote() from content
+	// the remote already has with the same hash as src, without
+	// uploading the data, replacing any object already there.
+	//
+	// It reads the hash with src.Hash.  If the remote doesn't have
+	// the content it should return fs.ErrorCantPutByHash and the
+	// data will be uploaded as normal.
+	PutByHash(ctx context.Context, src ObjectInfo, options ...OpenOption) (Object, error)
 }
 
 // PublicLinker is an optional interface for Fs
 type PublicLinker interface {
 	// PublicLink generates a public link to the remote path (usually readable by anyone)
-	PublicLink(remote string) (string, error)
+	//
+	// If expire is set the link should stop working after that
+	// long, and if password isn't "" the link should need it.  If
+	// the remote can't do these it should return
+	// fs.ErrorLinkExpiryUnsupported or fs.ErrorLinkPasswordUnsupported.
+	PublicLink(ctx context.Context, remote string, expire Duration, password string) (string, error)
+}
+
+// DirSetModTimer is an optional interface for Fs
+type DirSetModTimer interface {
+	// DirSetModTime sets the modification time of the directory
+	// dir which must exist
+	DirSetModTime(ctx context.Context, dir string, modTime time.Time) error
 }
 
 // MergeDirser is an option interface for Fs
 type MergeDirser interface {
 	// MergeDirs merges the contents of all the directories passed
 	// in into the first one and rmdirs the other directories.
-	MergeDirs([]Directory) error
+	MergeDirs(ctx context.Context, dirs []Directory) error
 }
 
 // CleanUpper is an optional interfaces for Fs
@@ -697,7 +891,7 @@ type CleanUpper interface {
 	//
 	// Implement this if you have a way of emptying the trash or
 	// otherwise cleaning up old versions of files.
-	CleanUp() error
+	CleanUp(ctx context.Context) error
 }
 
 // ListRer is an optional interfaces for Fs
@@ -718,7 +912,7 @@ type ListRer interface {
 	//
 	// Don't implement this unless you have a more efficient way
 	// of listing recursively that doing a directory traversal.
-	ListR(dir string, callback ListRCallback) error
+	ListR(ctx context.Context, dir string, callback ListRCallback) error
 }
 
 // RangeSeeker is the interface that wraps the RangeSeek method.
@@ -737,7 +931,14 @@ type RangeSeeker interface {
 // Abouter is an optional interface for Fs
 type Abouter interface {
 	// About gets quota information from the Fs
-	About() (*Usage, error)
+	About(ctx context.Context) (*Usage, error)
+}
+
+// Shutdowner is an optional interface for Fs
+type Shutdowner interface {
+	// Shutdown writes out anything the Fs has buffered.  It is
+	// called when the command using the Fs has finished.
+	Shutdown(ctx context.Context) error
 }
 
 // ObjectsChan is a channel of Objects
@@ -816,13 +1017,48 @@ func NewFs(path string) (Fs, error) {
 	if err != nil {
 		return nil, err
 	}
-	return fsInfo.NewFs(configName, fsPath)
+	f, err := fsInfo.NewFs(configName, fsPath)
+	f = Restrict(configName, f)
+	if f != nil && f.Features().Shutdown != nil {
+		shutdownMu.Lock()
+		shutdowns = append(shutdowns, f.Features().Shutdown)
+		shutdownMu.Unlock()
+	}
+	return f, err
+}
+
+var (
+	shutdownMu sync.Mutex                        // protects shutdowns
+	shutdowns  []func(ctx context.Context) error // Shutdown of each Fs made by NewFs
+)
+
+// Shutdown calls the Shutdown feature of each Fs made by NewFs which
+// has one, so they write out anything they have buffered.  It returns
+// the first error.
+//
+// The commands call this when they have finished with their remotes.
+func Shutdown(ctx context.Context) (err error) {
+	shutdownMu.Lock()
+	fns := shutdowns
+	shutdowns = nil
+	shutdownMu.Unlock()
+	for _, fn := range fns {
+		if fnErr := fn(ctx); fnErr != nil && err == nil {
+			err = fnErr
+		}
+	}
+	return err
 }
 
 // TemporaryLocalFs creates a local FS in the OS's temporary directory.
 //
 // No cleanup is performed, the caller must call Purge on the Fs themselves.
+//
+// It returns ErrorSpoolingNotAllowed if --no-spool is set.
 func TemporaryLocalFs() (Fs, error) {
+	if Config.NoSpool {
+		return nil, ErrorSpoolingNotAllowed
+	}
 	path, err := ioutil.TempDir("", "rclone-spool")
 	if err == nil {
 		err = os.Remove(path)
@@ -845,8 +1081,8 @@ func CheckClose(c io.Closer, err *error) {
 
 // FileExists returns true if a file remote exists.
 // If remote is a directory, FileExists returns false.
-func FileExists(fs Fs, remote string) (bool, error) {
-	_, err := fs.NewObject(remote)
+func FileExists(ctx context.Context, fs Fs, remote string) (bool, error) {
+	_, err := fs.NewObject(ctx, remote)
 	if err != nil {
 		if err == ErrorObjectNotFound || err == ErrorNotAFile || err == ErrorPermissionDenied {
 			return false, nil
@@ -859,20 +1095,27 @@ func FileExists(fs Fs, remote string) (bool, error) {
 // CalculateModifyWindow works out modify window for Fses passed in -
 // sets Config.ModifyWindow
 //
-// This is the largest modify window of all the fses in use, and the
-// user configured value
+// If Config.ModifyWindowAuto is set this is the largest modify window
+// of all the fses in use, otherwise it is the user configured value.
+// If any of the fses doesn't support modification times at all then
+// it is always ModTimeNotSupported.
 func CalculateModifyWindow(fss ...Fs) {
 	for _, f := range fss {
 		if f != nil {
 			precision := f.Precision()
-			if precision > Config.ModifyWindow {
-				Config.ModifyWindow = precision
-			}
 			if precision == ModTimeNotSupported {
+				Config.ModifyWindow = precision
 				Infof(f, "Modify window not supported")
 				return
 			}
+			if Config.ModifyWindowAuto && precision > Config.ModifyWindow {
+				Config.ModifyWindow = precision
+			}
 		}
 	}
-	Infof(fss[0], "Modify window is %s", Config.ModifyWindow)
+	if Config.ModifyWindowAuto {
+		Infof(fss[0], "Modify window is %s", Config.ModifyWindow)
+	} else {
+		Infof(fss[0], "Modify window is %s (set by --modify-window)", Config.ModifyWindow)
+	}
 }
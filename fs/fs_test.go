@@ -1,15 +1,18 @@
 package fs
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestFeaturesDisable(t *testing.T) {
 	ft := new(Features)
-	ft.Copy = func(src Object, remote string) (Object, error) {
+	ft.Copy = func(ctx context.Context, src Object, remote string) (Object, error) {
 		return nil, nil
 	}
 	ft.CaseInsensitive = true
@@ -35,7 +38,7 @@ func TestFeaturesList(t *testing.T) {
 
 func TestFeaturesDisableList(t *testing.T) {
 	ft := new(Features)
-	ft.Copy = func(src Object, remote string) (Object, error) {
+	ft.Copy = func(ctx context.Context, src Object, remote string) (Object, error) {
 		return nil, nil
 	}
 	ft.CaseInsensitive = true
@@ -52,3 +55,51 @@ func TestFeaturesDisableList(t *testing.T) {
 	assert.False(t, ft.CaseInsensitive)
 	assert.False(t, ft.DuplicateFiles)
 }
+
+func TestShutdown(t *testing.T) {
+	ctx := context.Background()
+	var calls []int
+	shutdowns = []func(ctx context.Context) error{
+		func(ctx context.Context) error { calls = append(calls, 1); return nil },
+		func(ctx context.Context) error { calls = append(calls, 2); return errors.New("two") },
+		func(ctx context.Context) error { calls = append(calls, 3); return errors.New("three") },
+	}
+	err := Shutdown(ctx)
+	assert.EqualError(t, err, "two")
+	assert.Equal(t, []int{1, 2, 3}, calls)
+
+	// each is only called once
+	assert.NoError(t, Shutdown(ctx))
+	assert.Equal(t, []int{1, 2, 3}, calls)
+}
+
+// hashObjectInfo is an ObjectInfo which returns a fixed MD5
+type hashObjectInfo struct {
+	ObjectInfo
+	md5 string
+	err error
+}
+
+func (o hashObjectInfo) String() string { return "hashObjectInfo" }
+
+func (o hashObjectInfo) Hash(ht hash.Type) (string, error) { return o.md5, o.err }
+
+func TestUploadHash(t *testing.T) {
+	const md5 = "d41d8cd98f00b204e9800998ecf8427e"
+	for _, test := range []struct {
+		in   hashObjectInfo
+		want string
+	}{
+		{hashObjectInfo{md5: md5}, md5},
+		{hashObjectInfo{md5: ""}, ""},
+		{hashObjectInfo{md5: "potato"}, ""},
+		{hashObjectInfo{md5: md5[:30]}, ""},
+		{hashObjectInfo{md5: md5, err: errors.New("boom")}, ""},
+	} {
+		assert.Equal(t, test.want, UploadHash(test.in, hash.MD5), test.in.md5)
+	}
+	oldIgnoreChecksum := Config.IgnoreChecksum
+	Config.IgnoreChecksum = true
+	defer func() { Config.IgnoreChecksum = oldIgnoreChecksum }()
+	assert.Equal(t, "", UploadHash(hashObjectInfo{md5: md5}, hash.MD5))
+}
@@ -0,0 +1,127 @@
+package fshttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// captureAuthHeaders are the headers which are redacted in the
+// capture file unless --dump auth is set
+var captureAuthHeaders = []string{
+	"Authorization",
+	"X-Auth-Token",
+}
+
+// captureRequest is the request part of a captureEntry
+type captureRequest struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Headers  http.Header `json:"headers"`
+	BodySize int64       `json:"bodySize"`
+}
+
+// captureResponse is the response part of a captureEntry
+type captureResponse struct {
+	Status     int         `json:"status"`
+	StatusText string      `json:"statusText"`
+	Headers    http.Header `json:"headers"`
+	BodySize   int64       `json:"bodySize"`
+}
+
+// captureEntry is one HTTP transaction as written to the capture
+// file.  The field names follow the entries in the HAR format.
+type captureEntry struct {
+	Started  time.Time        `json:"startedDateTime"`
+	Time     float64          `json:"time"` // in milliseconds
+	Request  captureRequest   `json:"request"`
+	Response *captureResponse `json:"response,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// captureFile writes HTTP transactions to a file one JSON object per
+// line
+type captureFile struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	dumpAll bool // set to leave auth headers in
+}
+
+var (
+	capture     *captureFile
+	captureErr  error
+	captureOnce sync.Once
+)
+
+// openCapture opens the capture file in ci if set, returning nil if
+// it isn't.  The file is shared by all the transports.
+func openCapture(ci *fs.ConfigInfo) (*captureFile, error) {
+	if ci.DumpFile == "" {
+		return nil, nil
+	}
+	captureOnce.Do(func() {
+		out, err := os.OpenFile(ci.DumpFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			captureErr = errors.Wrap(err, "failed to open --dump-file")
+			return
+		}
+		capture = &captureFile{
+			enc:     json.NewEncoder(out),
+			dumpAll: ci.Dump&fs.DumpAuth != 0,
+		}
+	})
+	return capture, captureErr
+}
+
+// redact returns a copy of headers with the auth headers removed
+// unless --dump auth is set
+func (c *captureFile) redact(headers http.Header) http.Header {
+	out := make(http.Header, len(headers))
+	for key, values := range headers {
+		out[key] = values
+	}
+	if c.dumpAll {
+		return out
+	}
+	for _, key := range captureAuthHeaders {
+		if out.Get(key) != "" {
+			out.Set(key, "XXXX")
+		}
+	}
+	return out
+}
+
+// write a transaction to the capture file
+func (c *captureFile) write(started time.Time, req *http.Request, resp *http.Response, err error) {
+	entry := captureEntry{
+		Started: started,
+		Time:    float64(time.Since(started)) / float64(time.Millisecond),
+		Request: captureRequest{
+			Method:   req.Method,
+			URL:      req.URL.String(),
+			Headers:  c.redact(req.Header),
+			BodySize: req.ContentLength,
+		},
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Response = &captureResponse{
+			Status:     resp.StatusCode,
+			StatusText: http.StatusText(resp.StatusCode),
+			Headers:    c.redact(resp.Header),
+			BodySize:   resp.ContentLength,
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	encErr := c.enc.Encode(&entry)
+	if encErr != nil {
+		fs.Errorf(nil, "Failed to write to --dump-file: %v", encErr)
+	}
+}
@@ -0,0 +1,64 @@
+package fshttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureWrite(t *testing.T) {
+	var buf bytes.Buffer
+	c := &captureFile{enc: json.NewEncoder(&buf)}
+
+	req, err := http.NewRequest("PUT", "http://example.com/file?x=1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Other", "visible")
+	req.ContentLength = 42
+	resp := &http.Response{
+		StatusCode:    http.StatusCreated,
+		Header:        http.Header{"X-Auth-Token": {"secret"}},
+		ContentLength: 7,
+	}
+	started := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.write(started, req, resp, nil)
+	c.write(started, req, nil, errors.New("connection reset"))
+
+	dec := json.NewDecoder(&buf)
+	var entry captureEntry
+	require.NoError(t, dec.Decode(&entry))
+	assert.True(t, started.Equal(entry.Started))
+	assert.Equal(t, "PUT", entry.Request.Method)
+	assert.Equal(t, "http://example.com/file?x=1", entry.Request.URL)
+	assert.Equal(t, "XXXX", entry.Request.Headers.Get("Authorization"))
+	assert.Equal(t, "visible", entry.Request.Headers.Get("X-Other"))
+	assert.Equal(t, int64(42), entry.Request.BodySize)
+	require.NotNil(t, entry.Response)
+	assert.Equal(t, http.StatusCreated, entry.Response.Status)
+	assert.Equal(t, "Created", entry.Response.StatusText)
+	assert.Equal(t, "XXXX", entry.Response.Headers.Get("X-Auth-Token"))
+	assert.Equal(t, int64(7), entry.Response.BodySize)
+	assert.Equal(t, "", entry.Error)
+
+	// The request headers must not have been modified
+	assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+
+	entry = captureEntry{}
+	require.NoError(t, dec.Decode(&entry))
+	assert.Nil(t, entry.Response)
+	assert.Equal(t, "connection reset", entry.Error)
+
+	// With --dump auth the headers are left alone
+	buf.Reset()
+	c.dumpAll = true
+	c.write(started, req, resp, nil)
+	entry = captureEntry{}
+	require.NoError(t, json.NewDecoder(&buf).Decode(&entry))
+	assert.Equal(t, "Bearer secret", entry.Request.Headers.Get("Authorization"))
+}
@@ -5,14 +5,22 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/csv"
+	"io/ioutil"
+	"log"
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/pkg/errors"
 	"golang.org/x/time/rate"
 )
 
@@ -22,11 +30,23 @@ const (
 )
 
 var (
-	transport   http.RoundTripper
-	noTransport sync.Once
-	tpsBucket   *rate.Limiter // for limiting number of http transactions per second
+	transport        http.RoundTripper
+	noTransport      sync.Once
+	tpsBucket        *rate.Limiter // for limiting number of http transactions per second
+	remoteMu         sync.Mutex
+	remoteTransports = map[remoteKey]http.RoundTripper{} // transports for each remote
 )
 
+// remoteKey identifies a transport made by NewRemoteTransport
+type remoteKey struct {
+	ci   *fs.ConfigInfo
+	name string
+}
+
+// ProxyDirect is the proxy setting to connect directly, ignoring
+// any proxy set in the environment
+const ProxyDirect = "direct"
+
 // StartHTTPTokenBucket starts the token bucket if necessary
 func StartHTTPTokenBucket() {
 	if fs.Config.TPSLimit > 0 {
@@ -118,31 +138,185 @@ func dialContextTimeout(ctx context.Context, network, address string, ci *fs.Con
 	return newTimeoutConn(c, ci.Timeout)
 }
 
+// parseProxy returns the function to find the proxy for each request
+// from proxy.  This is one of
+//
+//   - "" to read the proxy from the environment
+//   - "direct" to not use a proxy
+//   - a URL with scheme http, https or socks5
+func parseProxy(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case ProxyDirect:
+		return nil, nil
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse proxy %q", proxy)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, errors.Errorf("proxy %q must have scheme http, https or socks5", proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
 // NewTransport returns an http.RoundTripper with the correct timeouts
 func NewTransport(ci *fs.ConfigInfo) http.RoundTripper {
 	noTransport.Do(func() {
-		// Start with a sensible set of defaults then override.
-		// This also means we get new stuff when it gets added to go
-		t := new(http.Transport)
-		setDefaults(t, http.DefaultTransport.(*http.Transport))
-		t.Proxy = http.ProxyFromEnvironment
-		t.MaxIdleConnsPerHost = 2 * (ci.Checkers + ci.Transfers + 1)
-		t.MaxIdleConns = 2 * t.MaxIdleConnsPerHost
-		t.TLSHandshakeTimeout = ci.ConnectTimeout
-		t.ResponseHeaderTimeout = ci.Timeout
-		t.TLSClientConfig = &tls.Config{InsecureSkipVerify: ci.InsecureSkipVerify}
-		t.DisableCompression = ci.NoGzip
-		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return dialContextTimeout(ctx, network, addr, ci)
-		}
-		t.IdleConnTimeout = 60 * time.Second
-		t.ExpectContinueTimeout = ci.ConnectTimeout
-		// Wrap that http.Transport in our own transport
-		transport = newTransport(ci, t)
+		transport = newHTTPTransport(ci, ci.Proxy)
 	})
 	return transport
 }
 
+// NewRemoteTransport returns an http.RoundTripper with the correct
+// timeouts which uses the proxy and headers configured for the remote
+// name, if any, otherwise the same as NewTransport.
+//
+// The API calls made with it are counted against name in the stats.
+// It is made once for each ci and name.
+func NewRemoteTransport(ci *fs.ConfigInfo, name string) http.RoundTripper {
+	remoteMu.Lock()
+	defer remoteMu.Unlock()
+	key := remoteKey{ci: ci, name: name}
+	t, ok := remoteTransports[key]
+	if ok {
+		return t
+	}
+	var base http.RoundTripper
+	proxy := fs.ConfigFileGet(name, "proxy")
+	headers := fs.ConfigFileGet(name, "headers")
+	if proxy == "" && headers == "" {
+		base = NewTransport(ci)
+	} else {
+		if proxy == "" {
+			proxy = ci.Proxy
+		}
+		transport := newHTTPTransport(ci, proxy)
+		var err error
+		transport.headers, err = parseHeaders(headers)
+		if err != nil {
+			log.Fatalf("Failed to read headers for remote %q: %v", name, err)
+		}
+		base = transport
+	}
+	if apiCost := fs.ConfigFileGet(name, "api_cost"); apiCost != "" {
+		cost, err := accounting.ParseAPICost(apiCost)
+		if err != nil {
+			log.Fatalf("Failed to read api_cost for remote %q: %v", name, err)
+		}
+		accounting.Stats.SetAPICost(name, cost)
+	}
+	t = &apiTransport{RoundTripper: base, name: name}
+	remoteTransports[key] = t
+	return t
+}
+
+// apiTransport counts the API calls made to the remote name
+type apiTransport struct {
+	http.RoundTripper
+	name string
+}
+
+// SetRequestFilter sets a filter to be used on each request if the
+// underlying transport supports it
+func (t *apiTransport) SetRequestFilter(f func(req *http.Request)) {
+	if do, ok := t.RoundTripper.(interface {
+		SetRequestFilter(f func(req *http.Request))
+	}); ok {
+		do.SetRequestFilter(f)
+	}
+}
+
+// RoundTrip implements the RoundTripper interface counting the call
+func (t *apiTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	accounting.Stats.APICall(t.name, accounting.APIClass(req))
+	return t.RoundTripper.RoundTrip(req)
+}
+
+// parseHeaders parses a comma separated list of "Key: Value" headers
+// as used in the headers config option.  Headers containing commas
+// can be quoted as in CSV, eg
+//
+//	X-Audit: tag,"X-List: a,b"
+func parseHeaders(s string) (http.Header, error) {
+	if s == "" {
+		return nil, nil
+	}
+	r := csv.NewReader(strings.NewReader(s))
+	r.TrimLeadingSpace = true
+	fields, err := r.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse headers")
+	}
+	headers := http.Header{}
+	for _, field := range fields {
+		option, err := fs.ParseHTTPOption(field)
+		if err != nil {
+			return nil, err
+		}
+		headers.Add(option.Key, option.Value)
+	}
+	return headers, nil
+}
+
+// newHTTPTransport makes a new http.RoundTripper with the correct
+// timeouts which connects using proxy
+func newHTTPTransport(ci *fs.ConfigInfo, proxy string) *Transport {
+	// Start with a sensible set of defaults then override.
+	// This also means we get new stuff when it gets added to go
+	t := new(http.Transport)
+	setDefaults(t, http.DefaultTransport.(*http.Transport))
+	var err error
+	t.Proxy, err = parseProxy(proxy)
+	if err != nil {
+		log.Fatalf("Failed to set proxy: %v", err)
+	}
+	t.MaxIdleConnsPerHost = 2 * (ci.Checkers + ci.Transfers + 1)
+	t.MaxIdleConns = 2 * t.MaxIdleConnsPerHost
+	t.TLSHandshakeTimeout = ci.ConnectTimeout
+	t.ResponseHeaderTimeout = ci.Timeout
+	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: ci.InsecureSkipVerify}
+	err = loadCerts(t.TLSClientConfig, ci)
+	if err != nil {
+		log.Fatalf("Failed to load certificates: %v", err)
+	}
+	t.DisableCompression = ci.NoGzip
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return dialContextTimeout(ctx, network, addr, ci)
+	}
+	t.IdleConnTimeout = 60 * time.Second
+	t.ExpectContinueTimeout = ci.ConnectTimeout
+	// Wrap that http.Transport in our own transport
+	return newTransport(ci, t)
+}
+
+// loadCerts loads the client certificate and the CA certificate from
+// the config into tlsConfig if they are set
+func loadCerts(tlsConfig *tls.Config, ci *fs.ConfigInfo) error {
+	if ci.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(ci.ClientCert, ci.ClientKey)
+		if err != nil {
+			return errors.Wrap(err, "--client-cert/--client-key")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if ci.CaCert != "" {
+		caCert, err := ioutil.ReadFile(ci.CaCert)
+		if err != nil {
+			return errors.Wrap(err, "--ca-cert")
+		}
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return errors.Errorf("--ca-cert: no certificates found in %q", ci.CaCert)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	return nil
+}
+
 // NewClient returns an http.Client with the correct timeouts
 func NewClient(ci *fs.ConfigInfo) *http.Client {
 	return &http.Client{
@@ -150,6 +324,14 @@ func NewClient(ci *fs.ConfigInfo) *http.Client {
 	}
 }
 
+// NewRemoteClient returns an http.Client with the correct timeouts
+// which uses the proxy configured for the remote name
+func NewRemoteClient(ci *fs.ConfigInfo, name string) *http.Client {
+	return &http.Client{
+		Transport: NewRemoteTransport(ci, name),
+	}
+}
+
 // Transport is a our http Transport which wraps an http.Transport
 // * Sets the User Agent
 // * Does logging
@@ -158,15 +340,22 @@ type Transport struct {
 	dump          fs.DumpFlags
 	filterRequest func(req *http.Request)
 	userAgent     string
+	headers       http.Header  // extra headers to set on every request
+	capture       *captureFile // write transactions here if set
 }
 
 // newTransport wraps the http.Transport passed in and logs all
 // roundtrips including the body if logBody is set.
 func newTransport(ci *fs.ConfigInfo, transport *http.Transport) *Transport {
+	capture, err := openCapture(ci)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
 	return &Transport{
 		Transport: transport,
 		dump:      ci.Dump,
 		userAgent: ci.UserAgent,
+		capture:   capture,
 	}
 }
 
@@ -266,6 +455,10 @@ func (t *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error
 	}
 	// Force user agent
 	req.Header.Set("User-Agent", t.userAgent)
+	// Set any extra headers
+	for key, values := range t.headers {
+		req.Header[key] = values
+	}
 	// Filter the request if required
 	if t.filterRequest != nil {
 		t.filterRequest(req)
@@ -282,7 +475,11 @@ func (t *Transport) RoundTrip(req *http.Request) (resp *http.Response, err error
 		fs.Debugf(nil, "%s", separatorReq)
 	}
 	// Do round trip
+	started := time.Now()
 	resp, err = t.Transport.RoundTrip(req)
+	if t.capture != nil {
+		t.capture.write(started, req, resp, err)
+	}
 	// Logf response
 	if t.dump&(fs.DumpHeaders|fs.DumpBodies|fs.DumpAuth|fs.DumpRequests|fs.DumpResponses) != 0 {
 		fs.Debugf(nil, "%s", separatorResp)
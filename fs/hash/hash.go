@@ -3,6 +3,7 @@ package hash
 import (
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"hash"
@@ -36,13 +37,21 @@ const (
 	// https://docs.microsoft.com/en-us/onedrive/developer/code-snippets/quickxorhash
 	QuickXorHash
 
+	// SHA256 indicates SHA-256 support
+	SHA256
+
 	// None indicates no hashes are supported
 	None Type = 0
 )
 
 // Supported returns a set of all the supported hashes by
 // HashStream and MultiHasher.
-var Supported = NewHashSet(MD5, SHA1, Dropbox, QuickXorHash)
+var Supported = NewHashSet(MD5, SHA1, Dropbox, QuickXorHash, SHA256)
+
+// Default is the set of hashes computed when an object is read
+// without asking for particular hashes.  SHA-256 is left out as it is
+// slow to compute and only a few remotes use it.
+var Default = NewHashSet(MD5, SHA1, Dropbox, QuickXorHash)
 
 // Width returns the width in characters for any HashType
 var Width = map[Type]int{
@@ -50,6 +59,7 @@ var Width = map[Type]int{
 	SHA1:         40,
 	Dropbox:      64,
 	QuickXorHash: 40,
+	SHA256:       64,
 }
 
 // Stream will calculate hashes of all supported hash types.
@@ -89,6 +99,8 @@ func (h Type) String() string {
 		return "DropboxHash"
 	case QuickXorHash:
 		return "QuickXorHash"
+	case SHA256:
+		return "SHA-256"
 	default:
 		err := fmt.Sprintf("internal error: unknown hash type: 0x%x", int(h))
 		panic(err)
@@ -108,6 +120,8 @@ func (h *Type) Set(s string) error {
 		*h = Dropbox
 	case "QuickXorHash":
 		*h = QuickXorHash
+	case "SHA-256":
+		*h = SHA256
 	default:
 		return errors.Errorf("Unknown hash type %q", s)
 	}
@@ -138,6 +152,8 @@ func fromTypes(set Set) (map[Type]hash.Hash, error) {
 			hashers[t] = dbhash.New()
 		case QuickXorHash:
 			hashers[t] = quickxorhash.New()
+		case SHA256:
+			hashers[t] = sha256.New()
 		default:
 			err := fmt.Sprintf("internal error: Unsupported hash type %v", t)
 			panic(err)
@@ -73,6 +73,7 @@ var hashTestSet = []hashTest{
 			hash.SHA1:         "3ab6543c08a75f292a5ecedac87ec41642d12166",
 			hash.Dropbox:      "214d2fcf3566e94c99ad2f59bd993daca46d8521a0c447adf4b324f53fddc0c7",
 			hash.QuickXorHash: "0110c000085000031c0001095ec00218d0000700",
+			hash.SHA256:       "c839e57675862af5c21bd0a15413c3ec579e0d5522dab600bc6c3489b05b8f54",
 		},
 	},
 	// Empty data set
@@ -83,6 +84,7 @@ var hashTestSet = []hashTest{
 			hash.SHA1:         "da39a3ee5e6b4b0d3255bfef95601890afd80709",
 			hash.Dropbox:      "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
 			hash.QuickXorHash: "0000000000000000000000000000000000000000",
+			hash.SHA256:       "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
 		},
 	},
 }
@@ -153,8 +155,8 @@ func TestHashStreamTypes(t *testing.T) {
 }
 
 func TestHashSetStringer(t *testing.T) {
-	h := hash.NewHashSet(hash.SHA1, hash.MD5, hash.Dropbox, hash.QuickXorHash)
-	assert.Equal(t, h.String(), "[MD5, SHA-1, DropboxHash, QuickXorHash]")
+	h := hash.NewHashSet(hash.SHA1, hash.MD5, hash.Dropbox, hash.QuickXorHash, hash.SHA256)
+	assert.Equal(t, h.String(), "[MD5, SHA-1, DropboxHash, QuickXorHash, SHA-256]")
 	h = hash.NewHashSet(hash.SHA1)
 	assert.Equal(t, h.String(), "[SHA-1]")
 	h = hash.NewHashSet()
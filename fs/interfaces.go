@@ -0,0 +1,68 @@
+// Find the optional interfaces a backend implements
+
+package fs
+
+// optionalInterface describes an optional interface of Fs or Object
+type optionalInterface struct {
+	name       string
+	implements func(x interface{}) bool
+}
+
+// fsInterfaces are the optional interfaces of Fs
+var fsInterfaces = []optionalInterface{
+	{"Purger", func(x interface{}) bool { _, ok := x.(Purger); return ok }},
+	{"Copier", func(x interface{}) bool { _, ok := x.(Copier); return ok }},
+	{"Mover", func(x interface{}) bool { _, ok := x.(Mover); return ok }},
+	{"DirMover", func(x interface{}) bool { _, ok := x.(DirMover); return ok }},
+	{"ChangeNotifier", func(x interface{}) bool { _, ok := x.(ChangeNotifier); return ok }},
+	{"ChangeLister", func(x interface{}) bool { _, ok := x.(ChangeLister); return ok }},
+	{"UnWrapper", func(x interface{}) bool { _, ok := x.(UnWrapper); return ok }},
+	{"Wrapper", func(x interface{}) bool { _, ok := x.(Wrapper); return ok }},
+	{"DirCacheFlusher", func(x interface{}) bool { _, ok := x.(DirCacheFlusher); return ok }},
+	{"PutUncheckeder", func(x interface{}) bool { _, ok := x.(PutUncheckeder); return ok }},
+	{"PutStreamer", func(x interface{}) bool { _, ok := x.(PutStreamer); return ok }},
+	{"PutByHasher", func(x interface{}) bool { _, ok := x.(PutByHasher); return ok }},
+	{"PublicLinker", func(x interface{}) bool { _, ok := x.(PublicLinker); return ok }},
+	{"DirSetModTimer", func(x interface{}) bool { _, ok := x.(DirSetModTimer); return ok }},
+	{"MergeDirser", func(x interface{}) bool { _, ok := x.(MergeDirser); return ok }},
+	{"CleanUpper", func(x interface{}) bool { _, ok := x.(CleanUpper); return ok }},
+	{"ListRer", func(x interface{}) bool { _, ok := x.(ListRer); return ok }},
+	{"Abouter", func(x interface{}) bool { _, ok := x.(Abouter); return ok }},
+	{"Shutdowner", func(x interface{}) bool { _, ok := x.(Shutdowner); return ok }},
+}
+
+// objectInterfaces are the optional interfaces of Object
+var objectInterfaces = []optionalInterface{
+	{"MimeTyper", func(x interface{}) bool { _, ok := x.(MimeTyper); return ok }},
+	{"VersionIDer", func(x interface{}) bool { _, ok := x.(VersionIDer); return ok }},
+	{"SetTierer", func(x interface{}) bool { _, ok := x.(SetTierer); return ok }},
+	{"GetTierer", func(x interface{}) bool { _, ok := x.(GetTierer); return ok }},
+	{"VersionRestorer", func(x interface{}) bool { _, ok := x.(VersionRestorer); return ok }},
+	{"TrashRestorer", func(x interface{}) bool { _, ok := x.(TrashRestorer); return ok }},
+	{"ObjectUnWrapper", func(x interface{}) bool { _, ok := x.(ObjectUnWrapper); return ok }},
+	{"Metadataer", func(x interface{}) bool { _, ok := x.(Metadataer); return ok }},
+	{"SetMetadataer", func(x interface{}) bool { _, ok := x.(SetMetadataer); return ok }},
+}
+
+// implemented returns the names of the interfaces x implements
+func implemented(x interface{}, interfaces []optionalInterface) (names []string) {
+	names = []string{}
+	for _, i := range interfaces {
+		if i.implements(x) {
+			names = append(names, i.name)
+		}
+	}
+	return names
+}
+
+// Interfaces returns the names of the optional interfaces the Fs and
+// Object of the backend implement.
+//
+// ok is false if the backend didn't register its types, eg if it
+// uses the Fs of another backend.
+func (ri *RegInfo) Interfaces() (fsNames, objectNames []string, ok bool) {
+	if ri.FsType == nil || ri.ObjectType == nil {
+		return nil, nil, false
+	}
+	return implemented(ri.FsType, fsInterfaces), implemented(ri.ObjectType, objectInterfaces), true
+}
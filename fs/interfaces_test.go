@@ -0,0 +1,39 @@
+package fs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// interfacesTestFs implements a couple of the optional interfaces
+type interfacesTestFs struct {
+	Fs
+}
+
+func (f *interfacesTestFs) Purge(ctx context.Context) error { return nil }
+
+func (f *interfacesTestFs) CleanUp(ctx context.Context) error { return nil }
+
+// interfacesTestObject implements one optional interface
+type interfacesTestObject struct {
+	Object
+}
+
+func (o *interfacesTestObject) MimeType() string { return "" }
+
+func TestRegInfoInterfaces(t *testing.T) {
+	ri := &RegInfo{Name: "test"}
+	fsNames, objectNames, ok := ri.Interfaces()
+	assert.False(t, ok)
+	assert.Nil(t, fsNames)
+	assert.Nil(t, objectNames)
+
+	ri.FsType = (*interfacesTestFs)(nil)
+	ri.ObjectType = (*interfacesTestObject)(nil)
+	fsNames, objectNames, ok = ri.Interfaces()
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Purger", "CleanUpper"}, fsNames)
+	assert.Equal(t, []string{"MimeTyper"}, objectNames)
+}
@@ -2,11 +2,14 @@
 package list
 
 import (
+	"context"
 	"sort"
 	"strings"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/pkg/errors"
 )
 
@@ -18,9 +21,9 @@ import (
 // files and directories passing the filter will be added.
 //
 // Files will be returned in sorted order
-func DirSorted(f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error) {
+func DirSorted(ctx context.Context, f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error) {
 	// Get unfiltered entries from the fs
-	entries, err = f.List(dir)
+	entries, err = f.List(accounting.WithAPIClass(ctx, accounting.APIList), dir)
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +34,7 @@ func DirSorted(f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err
 		fs.Debugf(dir, "Excluded from sync (and deletion)")
 		return nil, nil
 	}
-	return filterAndSortDir(entries, includeAll, dir, filter.Active.IncludeObject, filter.Active.IncludeDirectory(f))
+	return filterAndSortDir(entries, includeAll, dir, filter.Active.IncludeObject, filter.Active.IncludeDirectory(ctx, f))
 }
 
 // filter (if required) and check the entries, then sort them
@@ -100,3 +103,19 @@ func filterAndSortDir(entries fs.DirEntries, includeAll bool, dir string,
 	sort.Stable(entries)
 	return entries, nil
 }
+
+// CheckCount checks that all the entries of a directory were
+// received, for backends whose listing API reports the total number
+// of entries in a directory.  o is the directory being listed and is
+// used in the error as it would be in a log.
+//
+// If fewer than total were received it returns fs.ErrorListTruncated
+// marked for retry at a high level.  The error stops sync deleting
+// files on the other side which were missing from the listing.
+func CheckCount(o interface{}, total, received int64) error {
+	if received >= total {
+		return nil
+	}
+	err := errors.Wrapf(fs.ErrorListTruncated, "%v: received %d of %d entries", o, received, total)
+	return fserrors.RetryError(err)
+}
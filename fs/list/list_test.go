@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fstest/mockdir"
 	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
@@ -102,3 +103,12 @@ func TestFilterAndSortUnknown(t *testing.T) {
 	assert.Error(t, err, "error")
 	assert.Nil(t, newEntries)
 }
+
+func TestCheckCount(t *testing.T) {
+	assert.NoError(t, CheckCount("dir", 3, 3))
+	assert.NoError(t, CheckCount("dir", 3, 4))
+	err := CheckCount("dir", 3, 2)
+	require.Error(t, err)
+	assert.Equal(t, "dir: received 2 of 3 entries: directory listing truncated", err.Error())
+	assert.True(t, fserrors.IsRetryError(err))
+}
@@ -73,8 +73,34 @@ var LogPrint = func(level LogLevel, text string) {
 	log.Print(text)
 }
 
+// logRemote returns the name of the remote o is about, or "" if it
+// isn't about a remote
+func logRemote(o interface{}) string {
+	switch x := o.(type) {
+	case Info:
+		return x.Name()
+	case ObjectInfo:
+		if f := x.Fs(); f != nil {
+			return f.Name()
+		}
+	}
+	return ""
+}
+
 // LogPrintf produces a log string from the arguments passed in
+//
+// If --log-filter remote=NAME is in use, only errors and the logs
+// about the remote NAME are produced.
 func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{}) {
+	if Config.LogFilterRemote != "" && level > LogLevelError && logRemote(o) != Config.LogFilterRemote {
+		return
+	}
+	logPrintf(level, o, text, args...)
+}
+
+// logPrintf produces a log string from the arguments passed in
+// without filtering it
+func logPrintf(level LogLevel, o interface{}, text string, args ...interface{}) {
 	out := fmt.Sprintf(text, args...)
 	if o != nil {
 		out = fmt.Sprintf("%v: %s", o, out)
@@ -82,10 +108,11 @@ func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{})
 	LogPrint(level, out)
 }
 
-// LogLevelPrintf writes logs at the given level
+// LogLevelPrintf writes logs at the given level.  These aren't
+// removed by --log-filter so use it for reports such as the stats.
 func LogLevelPrintf(level LogLevel, o interface{}, text string, args ...interface{}) {
 	if Config.LogLevel >= level {
-		LogPrintf(level, o, text, args...)
+		logPrintf(level, o, text, args...)
 	}
 }
 
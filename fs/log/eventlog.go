@@ -0,0 +1,16 @@
+// Windows Event Log interface for non-Windows variants only
+
+// +build !windows
+
+package log
+
+import (
+	"log"
+	"runtime"
+)
+
+// Starts logging to the Windows Event Log
+func startEventLog() bool {
+	log.Fatalf("--windows-event-log not supported on %s platform", runtime.GOOS)
+	return false
+}
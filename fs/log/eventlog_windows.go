@@ -0,0 +1,58 @@
+// Windows Event Log interface for Windows only
+
+// +build windows
+
+package log
+
+import (
+	"log"
+
+	"github.com/ncw/rclone/fs"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventID is the ID rclone's messages are logged with
+const eventID = 1
+
+// Starts logging to the Windows Event Log
+func startEventLog() bool {
+	source := logTag()
+	// Register the source so the messages display properly - this
+	// needs administrator rights and fails if it exists already
+	err := eventlog.InstallAsEventCreate(source, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil {
+		fs.Debugf(nil, "Couldn't register event log source %q: %v", source, err)
+	}
+	w, err := eventlog.Open(source)
+	if err != nil {
+		log.Fatalf("Failed to open event log: %v", err)
+	}
+	log.SetFlags(0)
+	log.SetOutput(eventLogWriter{w})
+	fs.LogPrint = func(level fs.LogLevel, text string) {
+		switch {
+		case level <= fs.LogLevelError:
+			_ = w.Error(eventID, text)
+		case level <= fs.LogLevelWarning:
+			_ = w.Warning(eventID, text)
+		default:
+			_ = w.Info(eventID, text)
+		}
+	}
+	return true
+}
+
+// eventLogWriter writes the output of the standard logger to the
+// event log as errors
+type eventLogWriter struct {
+	w *eventlog.Log
+}
+
+// Write writes p as an event
+func (e eventLogWriter) Write(p []byte) (int, error) {
+	err := e.w.Error(eventID, string(p))
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
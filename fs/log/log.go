@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"path"
 	"reflect"
 	"runtime"
 	"strings"
@@ -18,6 +19,9 @@ var (
 	logFile        = flags.StringP("log-file", "", "", "Log everything to this file")
 	useSyslog      = flags.BoolP("syslog", "", false, "Use Syslog for logging")
 	syslogFacility = flags.StringP("syslog-facility", "", "DAEMON", "Facility for syslog, eg KERN,USER,...")
+	syslogTag      = flags.StringP("syslog-tag", "", "", "Tag for syslog messages (default the program name)")
+	syslogServer   = flags.StringP("syslog-server", "", "", "Send syslog messages to this server, eg udp://host:514, instead of the local syslog")
+	useEventLog    = flags.BoolP("windows-event-log", "", false, "Use the Windows Event Log for logging")
 )
 
 // fnName returns the name of the calling +2 function
@@ -87,4 +91,20 @@ func InitLogging() {
 		}
 		startSysLog()
 	}
+
+	// Windows Event Log output
+	if *useEventLog {
+		if *logFile != "" || *useSyslog {
+			log.Fatalf("Can't use --windows-event-log with --syslog or --log-file")
+		}
+		startEventLog()
+	}
+}
+
+// logTag returns the name rclone logs under in syslog or the event log
+func logTag() string {
+	if *syslogTag != "" {
+		return *syslogTag
+	}
+	return path.Base(os.Args[0])
 }
@@ -7,8 +7,7 @@ package log
 import (
 	"log"
 	"log/syslog"
-	"os"
-	"path"
+	"strings"
 
 	"github.com/ncw/rclone/fs"
 )
@@ -36,8 +35,19 @@ func startSysLog() bool {
 	if !ok {
 		log.Fatalf("Unknown syslog facility %q - man syslog for list", *syslogFacility)
 	}
-	Me := path.Base(os.Args[0])
-	w, err := syslog.New(syslog.LOG_NOTICE|facility, Me)
+	var (
+		w   *syslog.Writer
+		err error
+	)
+	if *syslogServer != "" {
+		network, addr := "udp", *syslogServer
+		if i := strings.Index(addr, "://"); i >= 0 {
+			network, addr = addr[:i], addr[i+3:]
+		}
+		w, err = syslog.Dial(network, addr, syslog.LOG_NOTICE|facility, logTag())
+	} else {
+		w, err = syslog.New(syslog.LOG_NOTICE|facility, logTag())
+	}
 	if err != nil {
 		log.Fatalf("Failed to start syslog: %v", err)
 	}
@@ -1,6 +1,56 @@
 package fs
 
-import "github.com/spf13/pflag"
+import (
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
 
 // Check it satisfies the interface
 var _ pflag.Value = (*LogLevel)(nil)
+
+// logTestFs is an Info with a name for testing the log filter
+type logTestFs string
+
+func (f logTestFs) Name() string             { return string(f) }
+func (f logTestFs) Root() string             { return "" }
+func (f logTestFs) String() string           { return string(f) + ":" }
+func (f logTestFs) Precision() time.Duration { return time.Second }
+func (f logTestFs) Hashes() hash.Set         { return hash.Set(hash.None) }
+func (f logTestFs) Features() *Features      { return &Features{} }
+
+func TestLogFilterRemote(t *testing.T) {
+	oldLogPrint, oldFilter, oldLevel := LogPrint, Config.LogFilterRemote, Config.LogLevel
+	defer func() {
+		LogPrint, Config.LogFilterRemote, Config.LogLevel = oldLogPrint, oldFilter, oldLevel
+	}()
+	var logged []string
+	LogPrint = func(level LogLevel, text string) {
+		logged = append(logged, text)
+	}
+	Config.LogLevel = LogLevelDebug
+
+	logAll := func() {
+		logged = nil
+		Debugf(logTestFs("one"), "debug")
+		Infof(logTestFs("two"), "info")
+		Debugf(nil, "no remote")
+		Errorf(logTestFs("two"), "error")
+		LogLevelPrintf(LogLevelInfo, nil, "stats")
+	}
+
+	Config.LogFilterRemote = ""
+	logAll()
+	assert.Equal(t, []string{"one:: debug", "two:: info", "no remote", "two:: error", "stats"}, logged)
+
+	Config.LogFilterRemote = "one"
+	logAll()
+	assert.Equal(t, []string{"one:: debug", "two:: error", "stats"}, logged)
+
+	Config.LogFilterRemote = "two"
+	logAll()
+	assert.Equal(t, []string{"two:: info", "two:: error", "stats"}, logged)
+}
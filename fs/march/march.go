@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/filter"
 	"github.com/ncw/rclone/fs/list"
 	"github.com/ncw/rclone/fs/walk"
@@ -49,8 +50,8 @@ func New(ctx context.Context, fdst, fsrc fs.Fs, dir string, callback Marcher) *M
 		dir:      dir,
 		callback: callback,
 	}
-	m.srcListDir = m.makeListDir(fsrc, false)
-	m.dstListDir = m.makeListDir(fdst, filter.Active.Opt.DeleteExcluded)
+	m.srcListDir = m.makeListDir(ctx, fsrc, false)
+	m.dstListDir = m.makeListDir(ctx, fdst, filter.Active.Opt.DeleteExcluded)
 	// Now create the matching transform
 	// ..normalise the UTF8 first
 	m.transforms = append(m.transforms, norm.NFC.String)
@@ -70,10 +71,10 @@ func New(ctx context.Context, fdst, fsrc fs.Fs, dir string, callback Marcher) *M
 type listDirFn func(dir string) (entries fs.DirEntries, err error)
 
 // makeListDir makes a listing function for the given fs and includeAll flags
-func (m *March) makeListDir(f fs.Fs, includeAll bool) listDirFn {
+func (m *March) makeListDir(ctx context.Context, f fs.Fs, includeAll bool) listDirFn {
 	if !fs.Config.UseListR || f.Features().ListR == nil {
 		return func(dir string) (entries fs.DirEntries, err error) {
-			return list.DirSorted(f, includeAll, dir)
+			return list.DirSorted(ctx, f, includeAll, dir)
 		}
 	}
 	var (
@@ -86,7 +87,7 @@ func (m *March) makeListDir(f fs.Fs, includeAll bool) listDirFn {
 		mu.Lock()
 		defer mu.Unlock()
 		if !started {
-			dirs, dirsErr = walk.NewDirTree(f, m.dir, includeAll, fs.Config.MaxDepth)
+			dirs, dirsErr = walk.NewDirTree(ctx, f, m.dir, includeAll, fs.Config.MaxDepth)
 			started = true
 		}
 		if dirsErr != nil {
@@ -316,6 +317,17 @@ func matchListings(srcListEntries, dstListEntries fs.DirEntries, transforms []ma
 	return
 }
 
+// countListError counts an error listing a directory.  With
+// --ignore-listing-errors the directory is only counted as skipped so
+// sync carries on deleting files in the directories it could list.
+func countListError(err error) {
+	if fs.Config.IgnoreListingErrors {
+		accounting.Stats.SkipDir(err)
+		return
+	}
+	fs.CountError(err)
+}
+
 // processJob processes a listDirJob listing the source and
 // destination directories, comparing them and returning a slice of
 // more jobs
@@ -348,14 +360,14 @@ func (m *March) processJob(job listDirJob) (jobs []listDirJob) {
 	wg.Wait()
 	if srcListErr != nil {
 		fs.Errorf(job.srcRemote, "error reading source directory: %v", srcListErr)
-		fs.CountError(srcListErr)
+		countListError(srcListErr)
 		return nil
 	}
 	if dstListErr == fs.ErrorDirNotFound {
 		// Copy the stuff anyway
 	} else if dstListErr != nil {
 		fs.Errorf(job.dstRemote, "error reading destination directory: %v", dstListErr)
-		fs.CountError(dstListErr)
+		countListError(dstListErr)
 		return nil
 	}
 
@@ -3,10 +3,12 @@
 package march
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
 )
@@ -169,3 +171,28 @@ func TestMatchListings(t *testing.T) {
 		assert.Equal(t, test.matches, matches, test.what)
 	}
 }
+
+func TestCountListError(t *testing.T) {
+	oldCountError := fs.CountError
+	defer func() {
+		fs.CountError = oldCountError
+		fs.Config.IgnoreListingErrors = false
+		accounting.Stats.ResetCounters()
+	}()
+	var counted []error
+	fs.CountError = func(err error) {
+		counted = append(counted, err)
+	}
+	accounting.Stats.ResetCounters()
+	err := errors.New("list failed")
+
+	countListError(err)
+	assert.Equal(t, []error{err}, counted)
+	assert.Equal(t, int64(0), accounting.Stats.GetSkippedDirs())
+
+	fs.Config.IgnoreListingErrors = true
+	countListError(err)
+	assert.Equal(t, []error{err}, counted)
+	assert.Equal(t, int64(1), accounting.Stats.GetSkippedDirs())
+	assert.Equal(t, err, accounting.Stats.GetLastSkipError())
+}
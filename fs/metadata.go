@@ -0,0 +1,81 @@
+// Metadata of objects beyond the size and modification time
+
+package fs
+
+import (
+	"context"
+	"time"
+)
+
+// Metadata is the extra metadata of an Object as key value pairs.
+//
+// Times are stored in RFC 3339 format with nanoseconds under the keys
+// "mtime" for the modification time, "atime" for the last access time
+// and "btime" for the creation (birth) time, where the remote supports
+// them.
+type Metadata map[string]string
+
+// Time returns the time stored under key and whether it was there
+// and could be parsed.
+func (m Metadata) Time(key string) (t time.Time, ok bool) {
+	value, ok := m[key]
+	if !ok {
+		return t, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		Debugf(nil, "Ignoring metadata %s: %v", key, err)
+		return t, false
+	}
+	return t, true
+}
+
+// SetTime stores t under key
+func (m Metadata) SetTime(key string, t time.Time) {
+	m[key] = t.Format(time.RFC3339Nano)
+}
+
+// Metadataer is an optional interface for Object
+type Metadataer interface {
+	// Metadata returns the metadata of the Object
+	Metadata(ctx context.Context) (Metadata, error)
+}
+
+// SetMetadataer is an optional interface for Object
+type SetMetadataer interface {
+	// SetMetadata applies as much of the metadata to the Object
+	// as the remote supports, ignoring the rest
+	SetMetadata(ctx context.Context, metadata Metadata) error
+}
+
+// GetMetadata returns the metadata of o, looking through any objects
+// wrapping it, or nil if it doesn't have any.
+func GetMetadata(ctx context.Context, o ObjectInfo) (Metadata, error) {
+	for o != nil {
+		if do, ok := o.(Metadataer); ok {
+			return do.Metadata(ctx)
+		}
+		unWrapper, ok := o.(ObjectUnWrapper)
+		if !ok {
+			break
+		}
+		o = unWrapper.UnWrap()
+	}
+	return nil, nil
+}
+
+// SetMetadata applies the metadata to o, looking through any objects
+// wrapping it.  It does nothing if o can't have metadata set.
+func SetMetadata(ctx context.Context, o Object, metadata Metadata) error {
+	for o != nil {
+		if do, ok := o.(SetMetadataer); ok {
+			return do.SetMetadata(ctx, metadata)
+		}
+		unWrapper, ok := o.(ObjectUnWrapper)
+		if !ok {
+			break
+		}
+		o = unWrapper.UnWrap()
+	}
+	return nil
+}
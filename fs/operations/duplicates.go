@@ -0,0 +1,163 @@
+// Find files which are on two remotes with different paths
+
+package operations
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/pkg/errors"
+)
+
+// listBySize lists all the objects in f grouped by their size
+func listBySize(ctx context.Context, f fs.Fs) (map[int64][]fs.Object, error) {
+	var mu sync.Mutex
+	bySize := make(map[int64][]fs.Object)
+	err := ListFn(ctx, f, func(o fs.Object) {
+		mu.Lock()
+		bySize[o.Size()] = append(bySize[o.Size()], o)
+		mu.Unlock()
+	})
+	return bySize, err
+}
+
+// hashObjects reads the ht hash of all the objects passed in using
+// --checkers goroutines, leaving out any which can't be hashed
+func hashObjects(ctx context.Context, objects []fs.Object, ht hash.Type) map[fs.Object]string {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		hashes = make(map[fs.Object]string, len(objects))
+		in     = make(chan fs.Object, fs.Config.Checkers)
+	)
+	for i := 0; i < fs.Config.Checkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for o := range in {
+				accounting.Stats.Checking(o.Remote())
+				sum, err := o.Hash(ht)
+				accounting.Stats.DoneChecking(o.Remote())
+				if err != nil {
+					fs.Errorf(o, "Failed to read %v: %v", ht, err)
+					fs.CountError(err)
+					continue
+				}
+				if sum == "" {
+					continue
+				}
+				mu.Lock()
+				hashes[o] = sum
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, o := range objects {
+		in <- o
+	}
+	close(in)
+	wg.Wait()
+	return hashes
+}
+
+// Duplicate is a file on the source with the same contents as a file
+// on the destination with a different path
+type Duplicate struct {
+	Hash    string
+	Size    int64
+	SrcPath string
+	DstPath string
+}
+
+// byPath sorts duplicates by source then destination path
+type byPath []Duplicate
+
+func (s byPath) Len() int      { return len(s) }
+func (s byPath) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byPath) Less(i, j int) bool {
+	if s[i].SrcPath != s[j].SrcPath {
+		return s[i].SrcPath < s[j].SrcPath
+	}
+	return s[i].DstPath < s[j].DstPath
+}
+
+// FindDuplicates finds the files in fsrc which have the same size and
+// hash as files in fdst with different paths.
+//
+// Only files whose size appears in both fsrc and fdst are hashed.
+func FindDuplicates(ctx context.Context, fdst, fsrc fs.Fs) (ht hash.Type, duplicates []Duplicate, err error) {
+	ht = fdst.Hashes().Overlap(fsrc.Hashes()).GetOne()
+	if ht == hash.None {
+		return ht, nil, errors.Errorf("%v and %v have no hash in common", fsrc, fdst)
+	}
+	srcBySize, err := listBySize(ctx, fsrc)
+	if err != nil {
+		return ht, nil, errors.Wrap(err, "failed to list source")
+	}
+	dstBySize, err := listBySize(ctx, fdst)
+	if err != nil {
+		return ht, nil, errors.Wrap(err, "failed to list destination")
+	}
+
+	// Only hash files which could have duplicates
+	var toHash []fs.Object
+	for size, srcObjs := range srcBySize {
+		if dstObjs, ok := dstBySize[size]; ok {
+			toHash = append(toHash, srcObjs...)
+			toHash = append(toHash, dstObjs...)
+		}
+	}
+	hashes := hashObjects(ctx, toHash, ht)
+
+	for size, srcObjs := range srcBySize {
+		dstByHash := make(map[string][]fs.Object)
+		for _, dst := range dstBySize[size] {
+			if sum, ok := hashes[dst]; ok {
+				dstByHash[sum] = append(dstByHash[sum], dst)
+			}
+		}
+		if len(dstByHash) == 0 {
+			continue
+		}
+		for _, src := range srcObjs {
+			sum, ok := hashes[src]
+			if !ok {
+				continue
+			}
+			for _, dst := range dstByHash[sum] {
+				if dst.Remote() != src.Remote() {
+					duplicates = append(duplicates, Duplicate{
+						Hash:    sum,
+						Size:    size,
+						SrcPath: src.Remote(),
+						DstPath: dst.Remote(),
+					})
+				}
+			}
+		}
+	}
+	sort.Sort(byPath(duplicates))
+	return ht, duplicates, nil
+}
+
+// CheckDuplicates writes a report to w of the files in fsrc which
+// have the same contents as files in fdst with different paths.
+//
+// Each line of the report is the hash, the size, the source path and
+// the destination path separated by tabs.
+func CheckDuplicates(ctx context.Context, fdst, fsrc fs.Fs, w io.Writer) error {
+	ht, duplicates, err := FindDuplicates(ctx, fdst, fsrc)
+	if err != nil {
+		return err
+	}
+	for _, d := range duplicates {
+		syncFprintf(w, "%s\t%d\t%s\t%s\n", d.Hash, d.Size, d.SrcPath, d.DstPath)
+	}
+	fs.Logf(fdst, "%d duplicates with different paths found using %v", len(duplicates), ht)
+	return nil
+}
@@ -0,0 +1,73 @@
+// interactive - confirm destructive operations with the user
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/lib/atexit"
+)
+
+var (
+	interactiveMu sync.Mutex // only ask one question at once
+	skipAll       bool       // the user chose to skip all the operations
+	confirmAll    bool       // the user chose to do all the operations
+)
+
+// confirmedKey is the context key set when the user has already
+// confirmed the operation in progress
+type confirmedKey struct{}
+
+// withConfirmed returns a context which stops the operations done
+// with it asking again, eg for the copy and delete a move is made of.
+func withConfirmed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confirmedKey{}, true)
+}
+
+// SkipDestructive asks the user whether to do the destructive
+// operation action, eg "delete", on subject if --interactive is set.
+//
+// It returns true if the operation should be skipped.  The callers
+// deal with --dry-run themselves.
+func SkipDestructive(ctx context.Context, subject interface{}, action string) bool {
+	if !fs.Config.Interactive || ctx.Value(confirmedKey{}) != nil {
+		return false
+	}
+	interactiveMu.Lock()
+	defer interactiveMu.Unlock()
+	switch {
+	case confirmAll:
+		return false
+	case skipAll:
+		fs.Logf(subject, "Skipped %s as skip all was chosen", action)
+		return true
+	}
+	fmt.Printf("rclone: %s %q?\n", action, fmt.Sprint(subject))
+	switch config.Command([]string{
+		"yYes, this is OK",
+		"nNo, skip this",
+		"aAll, do this and all the following operations with no more questions",
+		"sSkip all, skip this and all the following operations with no more questions",
+		"qQuit rclone now",
+	}) {
+	case 'n':
+		fs.Logf(subject, "Skipped %s", action)
+		return true
+	case 'a':
+		confirmAll = true
+	case 's':
+		skipAll = true
+		fs.Logf(subject, "Skipped %s", action)
+		return true
+	case 'q':
+		fs.Logf(nil, "Quitting rclone as asked")
+		atexit.Run()
+		os.Exit(0)
+	}
+	return false
+}
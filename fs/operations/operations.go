@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"path"
 	"sort"
 	"strconv"
@@ -22,7 +23,9 @@ import (
 	"github.com/ncw/rclone/fs/march"
 	"github.com/ncw/rclone/fs/object"
 	"github.com/ncw/rclone/fs/walk"
+	"github.com/ncw/rclone/lib/filelock"
 	"github.com/ncw/rclone/lib/readers"
+	"github.com/ncw/rclone/lib/version"
 	"github.com/pkg/errors"
 )
 
@@ -71,6 +74,58 @@ func CheckHashes(src fs.ObjectInfo, dst fs.Object) (equal bool, ht hash.Type, er
 	return srcHash == dstHash, ht, nil
 }
 
+// CompareStrategy is the method Equal uses to decide whether a src
+// and dst object are the same
+type CompareStrategy int
+
+// Comparison strategies
+const (
+	CompareModTime  CompareStrategy = iota // size, then mod time, then hash if mod times differ
+	CompareSizeOnly                        // size only
+	CompareCheckSum                        // size then hash
+)
+
+var compareStrategyNames = []string{
+	CompareModTime:  "size and modification time",
+	CompareSizeOnly: "size only",
+	CompareCheckSum: "size and checksum",
+}
+
+// String turns a CompareStrategy into a string
+func (c CompareStrategy) String() string {
+	if c < 0 || int(c) >= len(compareStrategyNames) {
+		return fmt.Sprintf("CompareStrategy(%d)", c)
+	}
+	return compareStrategyNames[c]
+}
+
+// chooseCompareStrategy works out which CompareStrategy to use from
+// the flags requested and what the remotes support.
+//
+// sizeOnly always wins.  checkSum falls back to comparing mod times
+// if there isn't a common hash, and to size only if mod times can't
+// be compared either.  The default of comparing mod times falls back
+// to size only if mod times can't be compared.
+func chooseCompareStrategy(sizeOnly, checkSum bool, common hash.Set, modifyWindow time.Duration) CompareStrategy {
+	if sizeOnly {
+		return CompareSizeOnly
+	}
+	if checkSum && common.Count() > 0 {
+		return CompareCheckSum
+	}
+	if modifyWindow == fs.ModTimeNotSupported {
+		return CompareSizeOnly
+	}
+	return CompareModTime
+}
+
+// CompareStrategyFor returns the CompareStrategy that Equal will use
+// for objects being transferred from src to dst with the current
+// config.
+func CompareStrategyFor(src, dst fs.Info) CompareStrategy {
+	return chooseCompareStrategy(fs.Config.SizeOnly, fs.Config.CheckSum, src.Hashes().Overlap(dst.Hashes()), fs.Config.ModifyWindow)
+}
+
 // Equal checks to see if the src and dst objects are equal by looking at
 // size, mtime and hash
 //
@@ -87,10 +142,14 @@ func CheckHashes(src fs.ObjectInfo, dst fs.Object) (equal bool, ht hash.Type, er
 // considered to be equal.  In this case the mtime on the dst is
 // updated if --checksum is not set.
 //
+// If --checksum is set but src and dst don't have a common hash then
+// the mtime is checked instead.  If the mtime can't be checked either
+// then only the size is checked.  See chooseCompareStrategy.
+//
 // Otherwise the file is considered to be not equal including if there
 // were errors reading info.
-func Equal(src fs.ObjectInfo, dst fs.Object) bool {
-	return equal(src, dst, fs.Config.SizeOnly, fs.Config.CheckSum)
+func Equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object) bool {
+	return equal(ctx, src, dst, fs.Config.SizeOnly, fs.Config.CheckSum)
 }
 
 // sizeDiffers compare the size of src and dst taking into account the
@@ -102,12 +161,14 @@ func sizeDiffers(src, dst fs.ObjectInfo) bool {
 	return src.Size() != dst.Size()
 }
 
-func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
+func equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
+	strategy := chooseCompareStrategy(sizeOnly, checkSum, src.Fs().Hashes().Overlap(dst.Fs().Hashes()), fs.Config.ModifyWindow)
+	fs.Debugf(src, "Comparing using %v", strategy)
 	if sizeDiffers(src, dst) {
 		fs.Debugf(src, "Sizes differ (src %d vs dst %d)", src.Size(), dst.Size())
 		return false
 	}
-	if sizeOnly {
+	if strategy == CompareSizeOnly {
 		fs.Debugf(src, "Sizes identical")
 		return true
 	}
@@ -115,7 +176,7 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 	// Assert: Size is equal or being ignored
 
 	// If checking checksum and not modtime
-	if checkSum {
+	if strategy == CompareCheckSum {
 		// Check the hash
 		same, ht, _ := CheckHashes(src, dst)
 		if !same {
@@ -131,10 +192,6 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 	}
 
 	// Sizes the same so check the mtime
-	if fs.Config.ModifyWindow == fs.ModTimeNotSupported {
-		fs.Debugf(src, "Sizes identical")
-		return true
-	}
 	srcModTime := src.ModTime()
 	dstModTime := dst.ModTime()
 	dt := dstModTime.Sub(srcModTime)
@@ -169,7 +226,7 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 				return false
 			}
 			// Update the mtime of the dst object here
-			err := dst.SetModTime(srcModTime)
+			err := dst.SetModTime(ctx, srcModTime)
 			if err == fs.ErrorCantSetModTime {
 				fs.Debugf(dst, "src and dst identical but can't set mod time without re-uploading")
 				return false
@@ -178,7 +235,7 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 				// Remove the file if BackupDir isn't set.  If BackupDir is set we would rather have the old file
 				// put in the BackupDir than deleted which is what will happen if we don't delete it.
 				if fs.Config.BackupDir == "" {
-					err = dst.Remove()
+					err = dst.Remove(ctx)
 					if err != nil {
 						fs.Errorf(dst, "failed to delete before re-upload: %v", err)
 					}
@@ -198,12 +255,12 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 // Used to remove a failed copy
 //
 // Returns whether the file was succesfully removed or not
-func removeFailedCopy(dst fs.Object) bool {
+func removeFailedCopy(ctx context.Context, dst fs.Object) bool {
 	if dst == nil {
 		return false
 	}
 	fs.Infof(dst, "Removing failed copy")
-	removeErr := dst.Remove()
+	removeErr := dst.Remove(ctx)
 	if removeErr != nil {
 		fs.Infof(dst, "Failed to remove failed copy: %s", removeErr)
 		return false
@@ -234,17 +291,110 @@ func (o *overrideRemoteObject) MimeType() string {
 // Check interface is satisfied
 var _ fs.MimeTyper = (*overrideRemoteObject)(nil)
 
+// retentionOptions returns the options needed to lock objects
+// uploaded to f if --retention-period is set.
+//
+// It returns an error if f can't lock objects.
+func retentionOptions(f fs.Fs) ([]fs.OpenOption, error) {
+	if fs.Config.RetentionPeriod <= 0 {
+		return nil, nil
+	}
+	if !f.Features().ObjectLock {
+		return nil, errors.Errorf("%v can't lock objects so can't use --retention-period", f)
+	}
+	return []fs.OpenOption{&fs.RetentionOption{
+		Mode:        fs.Config.RetentionMode,
+		RetainUntil: time.Now().Add(fs.Config.RetentionPeriod),
+	}}, nil
+}
+
+// acquireUploadLock takes the lock on remote in f in --upload-lock-dir
+// returning whether it had to wait for another process and the
+// function to release it.
+func acquireUploadLock(ctx context.Context, f fs.Fs, remote string) (unlock func(), waited bool, err error) {
+	key := path.Join(f.Name()+":"+f.Root(), remote)
+	lock, waited, err := filelock.Acquire(ctx, fs.Config.UploadLockDir, key)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "upload lock")
+	}
+	unlock = func() {
+		err := lock.Release()
+		if err != nil {
+			fs.Errorf(key, "%v", err)
+		}
+	}
+	return unlock, waited, nil
+}
+
+// lockUpload takes the lock on remote in f in --upload-lock-dir so
+// only one rclone process uploads it at once.
+//
+// If it had to wait for another process it reads dst again as that
+// process may have uploaded it.  If the upload is no longer needed it
+// returns the new dst and a nil unlock, otherwise unlock should be
+// called when the upload is finished.
+func lockUpload(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, unlock func(), err error) {
+	unlock, waited, err := acquireUploadLock(ctx, f, remote)
+	if err != nil {
+		return dst, nil, err
+	}
+	if !waited {
+		return dst, unlock, nil
+	}
+	newDst, err = f.NewObject(ctx, remote)
+	if err == fs.ErrorObjectNotFound {
+		return nil, unlock, nil
+	} else if err != nil {
+		unlock()
+		return dst, nil, errors.Wrap(err, "upload lock: failed to read destination")
+	}
+	if Equal(ctx, src, newDst) {
+		unlock()
+		fs.Infof(src, "Not copying as it was uploaded by another process")
+		return newDst, nil, nil
+	}
+	return newDst, unlock, nil
+}
+
 // Copy src object to dst or f if nil.  If dst is nil then it uses
 // remote as the name of the new object.
 //
 // It returns the destination object if possible.  Note that this may
 // be nil.
-func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
 	newDst = dst
 	if fs.Config.DryRun {
 		fs.Logf(src, "Not copying as --dry-run")
 		return newDst, nil
 	}
+	if dst != nil && SkipDestructive(ctx, dst, "overwrite") {
+		return newDst, nil
+	}
+	if fs.Config.UploadLockDir != "" {
+		var unlock func()
+		dst, unlock, err = lockUpload(ctx, f, dst, remote, src)
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(src, "Failed to copy: %v", err)
+			return newDst, err
+		}
+		if unlock == nil {
+			return dst, nil
+		}
+		defer unlock()
+		newDst = dst
+	}
+	// read the metadata before the transfer as reading the source
+	// may change its access time
+	var metadata fs.Metadata
+	if fs.Config.Metadata {
+		metadata, err = fs.GetMetadata(ctx, src)
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(src, "Failed to read metadata: %v", err)
+			return newDst, err
+		}
+	}
 	maxTries := fs.Config.LowLevelRetries
 	tries := 0
 	doUpdate := dst != nil
@@ -259,38 +409,78 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		}
 	}
 	hashOption := &fs.HashesOption{Hashes: common}
+	downloadOptions := []fs.OpenOption{hashOption}
+	for _, option := range fs.Config.DownloadHeaders {
+		downloadOptions = append(downloadOptions, option)
+	}
+	uploadOptions := []fs.OpenOption{hashOption}
+	for _, option := range fs.Config.UploadHeaders {
+		uploadOptions = append(uploadOptions, option)
+	}
+	retention, err := retentionOptions(f)
+	if err != nil {
+		fs.CountError(err)
+		fs.Errorf(src, "Failed to copy: %v", err)
+		return newDst, err
+	}
+	uploadOptions = append(uploadOptions, retention...)
+	// Make the upload fail if dst has changed since it was read if
+	// the remote can
+	if doUpdate && f.Features().ConditionalUpdate {
+		if do, ok := dst.(fs.VersionIDer); ok {
+			if versionID := do.VersionID(); versionID != "" {
+				uploadOptions = append(uploadOptions, &fs.IfMatchOption{VersionID: versionID})
+			}
+		}
+	}
+	var wrappedSrc fs.ObjectInfo = src
+	// We try to pass the original object if possible
+	if src.Remote() != remote {
+		wrappedSrc = &overrideRemoteObject{Object: src, remote: remote}
+	}
+	// Only ask the remote for the content by hash once
+	doPutByHash := f.Features().PutByHash
 	var actionTaken string
 	for {
 		// Try server side copy first - if has optional interface and
-		// is same underlying remote
+		// is same underlying remote.  Server side copies can't lock
+		// the new object so upload instead if --retention-period is set.
 		actionTaken = "Copied (server side copy)"
-		if doCopy := f.Features().Copy; doCopy != nil && SameConfig(src.Fs(), f) {
-			newDst, err = doCopy(src, remote)
+		if doCopy := f.Features().Copy; doCopy != nil && SameConfig(src.Fs(), f) && retention == nil {
+			newDst, err = doCopy(ctx, src, remote)
 			if err == nil {
 				dst = newDst
 			}
 		} else {
 			err = fs.ErrorCantCopy
 		}
+		// Next see if the remote has the content already so it
+		// needn't be uploaded
+		if err == fs.ErrorCantCopy && doPutByHash != nil && retention == nil {
+			actionTaken = "Copied (by hash)"
+			newDst, err = doPutByHash(ctx, wrappedSrc, uploadOptions...)
+			if err == nil {
+				dst = newDst
+			} else if err == fs.ErrorCantPutByHash {
+				fs.Debugf(src, "Uploading as the remote doesn't have the content")
+				doPutByHash = nil
+				err = fs.ErrorCantCopy
+			}
+		}
 		// If can't server side copy, do it manually
 		if err == fs.ErrorCantCopy {
-			var in0 io.ReadCloser
-			in0, err = src.Open(hashOption)
+			var in *accounting.Account
+			in, err = accounting.Open(ctx, src, downloadOptions...)
 			if err != nil {
 				err = errors.Wrap(err, "failed to open source object")
 			} else {
-				in := accounting.NewAccount(in0, src).WithBuffer() // account and buffer the transfer
-				var wrappedSrc fs.ObjectInfo = src
-				// We try to pass the original object if possible
-				if src.Remote() != remote {
-					wrappedSrc = &overrideRemoteObject{Object: src, remote: remote}
-				}
+				in = in.WithBuffer() // buffer the transfer
 				if doUpdate {
 					actionTaken = "Copied (replaced existing)"
-					err = dst.Update(in, wrappedSrc, hashOption)
+					err = dst.Update(ctx, in, wrappedSrc, uploadOptions...)
 				} else {
 					actionTaken = "Copied (new)"
-					dst, err = f.Put(in, wrappedSrc, hashOption)
+					dst, err = f.Put(ctx, in, wrappedSrc, uploadOptions...)
 				}
 				closeErr := in.Close()
 				if err == nil {
@@ -311,19 +501,52 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		// otherwise finish
 		break
 	}
+	if err == fs.ErrorDestinationChanged {
+		// Retrying would read the changed destination again and
+		// overwrite it
+		err = fserrors.NoRetryError(err)
+	}
 	if err != nil {
 		fs.CountError(err)
 		fs.Errorf(src, "Failed to copy: %v", err)
 		return newDst, err
 	}
 
+	// Verify the transfer, rechecking if the remote is slow to
+	// show the new object
+	var corrupted error
+	dst, corrupted, err = verifyTransfer(ctx, src, dst, hashType, f.NewObject)
+	if dst != nil {
+		newDst = dst
+	}
+	if corrupted != nil {
+		fs.Errorf(dst, "%v", corrupted)
+		fs.CountError(corrupted)
+		removeFailedCopy(ctx, dst)
+		return newDst, corrupted
+	}
+
+	if len(metadata) != 0 && newDst != nil {
+		if metadataErr := fs.SetMetadata(ctx, newDst, metadata); metadataErr != nil {
+			fs.CountError(metadataErr)
+			fs.Errorf(newDst, "Failed to set metadata: %v", metadataErr)
+			return newDst, metadataErr
+		}
+	}
+
+	fs.Infof(src, actionTaken)
+	return newDst, err
+}
+
+// checkTransfer checks the size and hash of dst are the same as src
+// after a transfer.
+//
+// It returns a non nil corrupted error if they differ.  Errors
+// reading the hashes are logged and counted and returned in err.
+func checkTransfer(src fs.ObjectInfo, dst fs.Object, hashType hash.Type) (corrupted error, err error) {
 	// Verify sizes are the same after transfer
 	if sizeDiffers(src, dst) {
-		err = errors.Errorf("corrupted on transfer: sizes differ %d vs %d", src.Size(), dst.Size())
-		fs.Errorf(dst, "%v", err)
-		fs.CountError(err)
-		removeFailedCopy(dst)
-		return newDst, err
+		return errors.Errorf("corrupted on transfer: sizes differ %d vs %d", src.Size(), dst.Size()), nil
 	}
 
 	// Verify hashes are the same after transfer - ignoring blank hashes
@@ -342,17 +565,36 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 				fs.CountError(err)
 				fs.Errorf(dst, "Failed to read hash: %v", err)
 			} else if !fs.Config.IgnoreChecksum && !hash.Equals(srcSum, dstSum) {
-				err = errors.Errorf("corrupted on transfer: %v hash differ %q vs %q", hashType, srcSum, dstSum)
-				fs.Errorf(dst, "%v", err)
-				fs.CountError(err)
-				removeFailedCopy(dst)
-				return newDst, err
+				return errors.Errorf("corrupted on transfer: %v hash differ %q vs %q", hashType, srcSum, dstSum), nil
 			}
 		}
 	}
+	return nil, err
+}
 
-	fs.Infof(src, actionTaken)
-	return newDst, err
+// verifyTransfer checks dst is the same as src after a transfer.
+//
+// If --verify-delay is set and the check fails, the object is looked
+// up again with newObject after the delay and rechecked, up to
+// --verify-retries times, as remotes which are eventually consistent
+// may show an old version of the object for a while after it was
+// uploaded.
+//
+// It returns the last version of dst checked.
+func verifyTransfer(ctx context.Context, src fs.ObjectInfo, dst fs.Object, hashType hash.Type, newObject func(ctx context.Context, remote string) (fs.Object, error)) (newDst fs.Object, corrupted error, err error) {
+	corrupted, err = checkTransfer(src, dst, hashType)
+	for tries := 1; corrupted != nil && fs.Config.VerifyDelay > 0 && tries <= fs.Config.VerifyRetries; tries++ {
+		fs.Debugf(dst, "%v - rechecking in %v (%d/%d)", corrupted, fs.Config.VerifyDelay, tries, fs.Config.VerifyRetries)
+		time.Sleep(fs.Config.VerifyDelay)
+		recheckDst, findErr := newObject(ctx, dst.Remote())
+		if findErr != nil {
+			fs.Debugf(dst, "Failed to find object to recheck: %v", findErr)
+			continue
+		}
+		dst = recheckDst
+		corrupted, err = checkTransfer(src, dst, hashType)
+	}
+	return dst, corrupted, err
 }
 
 // Move src object to dst or fdst if nil.  If dst is nil then it uses
@@ -360,23 +602,29 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 //
 // It returns the destination object if possible.  Note that this may
 // be nil.
-func Move(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
 	newDst = dst
 	if fs.Config.DryRun {
 		fs.Logf(src, "Not moving as --dry-run")
 		return newDst, nil
 	}
-	// See if we have Move available
-	if doMove := fdst.Features().Move; doMove != nil && SameConfig(src.Fs(), fdst) {
+	if dst != nil && SkipDestructive(ctx, dst, "overwrite") {
+		return newDst, nil
+	}
+	// don't ask again for the copy and delete the move may be done with
+	ctx = withConfirmed(ctx)
+	// See if we have Move available - not if --retention-period is
+	// set as the moved object wouldn't be locked
+	if doMove := fdst.Features().Move; doMove != nil && SameConfig(src.Fs(), fdst) && fs.Config.RetentionPeriod <= 0 {
 		// Delete destination if it exists
 		if dst != nil {
-			err = DeleteFile(dst)
+			err = DeleteFile(ctx, dst)
 			if err != nil {
 				return newDst, err
 			}
 		}
 		// Move dst <- src
-		newDst, err = doMove(src, remote)
+		newDst, err = doMove(ctx, src, remote)
 		switch err {
 		case nil:
 			fs.Infof(src, "Moved (server side)")
@@ -390,13 +638,13 @@ func Move(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Ob
 		}
 	}
 	// Move not found or didn't work so copy dst <- src
-	newDst, err = Copy(fdst, dst, remote, src)
+	newDst, err = Copy(ctx, fdst, dst, remote, src)
 	if err != nil {
 		fs.Errorf(src, "Not deleting source as copy failed: %v", err)
 		return newDst, err
 	}
 	// Delete src if no error on copy
-	return newDst, DeleteFile(src)
+	return newDst, DeleteFile(ctx, src)
 }
 
 // CanServerSideMove returns true if fdst support server side moves or
@@ -410,21 +658,46 @@ func CanServerSideMove(fdst fs.Fs) bool {
 	return canMove || canCopy
 }
 
+// CheckDeleteLimits returns a fatal error if deleting count files
+// totalling size bytes out of the total files in the destination
+// would break --max-delete or --max-size-delete.
+//
+// This is called before deleting anything so that an accidentally
+// empty source doesn't wipe out part of the destination before the
+// limit is reached.
+func CheckDeleteLimits(count, size, total int64) error {
+	if fs.Config.MaxDelete >= 0 && count > fs.Config.MaxDelete {
+		return fserrors.FatalError(errors.Errorf("not deleting %d files as it is more than --max-delete %d", count, fs.Config.MaxDelete))
+	}
+	if fs.Config.MaxDeletePercent >= 0 && total > 0 && float64(count)*100 > fs.Config.MaxDeletePercent*float64(total) {
+		return fserrors.FatalError(errors.Errorf("not deleting %d of %d files as it is more than --max-delete %g%%", count, total, fs.Config.MaxDeletePercent))
+	}
+	if fs.Config.MaxSizeDelete >= 0 && size > int64(fs.Config.MaxSizeDelete) {
+		return fserrors.FatalError(errors.Errorf("not deleting %v as it is more than --max-size-delete %v", fs.SizeSuffix(size), fs.Config.MaxSizeDelete))
+	}
+	return nil
+}
+
 // DeleteFileWithBackupDir deletes a single file respecting --dry-run
 // and accumulating stats and errors.
 //
 // If backupDir is set then it moves the file to there instead of
 // deleting
-func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
+func DeleteFileWithBackupDir(ctx context.Context, dst fs.Object, backupDir fs.Fs) (err error) {
 	accounting.Stats.Checking(dst.Remote())
-	numDeletes := accounting.Stats.Deletes(1)
-	if fs.Config.MaxDelete != -1 && numDeletes > fs.Config.MaxDelete {
-		return fserrors.FatalError(errors.New("--max-delete threshold reached"))
-	}
+	defer accounting.Stats.DoneChecking(dst.Remote())
 	action, actioned, actioning := "delete", "Deleted", "deleting"
 	if backupDir != nil {
 		action, actioned, actioning = "move into backup dir", "Moved into backup dir", "moving into backup dir"
 	}
+	// ask first so skipped deletes aren't counted
+	if !fs.Config.DryRun && SkipDestructive(ctx, dst, action) {
+		return nil
+	}
+	numDeletes := accounting.Stats.Deletes(1)
+	if fs.Config.MaxDelete != -1 && numDeletes > fs.Config.MaxDelete {
+		return fserrors.FatalError(errors.New("--max-delete threshold reached"))
+	}
 	if fs.Config.DryRun {
 		fs.Logf(dst, "Not %s as --dry-run", actioning)
 	} else if backupDir != nil {
@@ -432,11 +705,11 @@ func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
 			err = errors.New("parameter to --backup-dir has to be on the same remote as destination")
 		} else {
 			remoteWithSuffix := dst.Remote() + fs.Config.Suffix
-			overwritten, _ := backupDir.NewObject(remoteWithSuffix)
-			_, err = Move(backupDir, overwritten, remoteWithSuffix, dst)
+			overwritten, _ := backupDir.NewObject(ctx, remoteWithSuffix)
+			_, err = Move(withConfirmed(ctx), backupDir, overwritten, remoteWithSuffix, dst)
 		}
 	} else {
-		err = dst.Remove()
+		err = dst.Remove(accounting.WithAPIClass(ctx, accounting.APIDelete))
 	}
 	if err != nil {
 		fs.CountError(err)
@@ -444,7 +717,6 @@ func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
 	} else if !fs.Config.DryRun {
 		fs.Infof(dst, actioned)
 	}
-	accounting.Stats.DoneChecking(dst.Remote())
 	return err
 }
 
@@ -452,8 +724,8 @@ func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
 //
 // If useBackupDir is set and --backup-dir is in effect then it moves
 // the file to there instead of deleting
-func DeleteFile(dst fs.Object) (err error) {
-	return DeleteFileWithBackupDir(dst, nil)
+func DeleteFile(ctx context.Context, dst fs.Object) (err error) {
+	return DeleteFileWithBackupDir(ctx, dst, nil)
 }
 
 // DeleteFilesWithBackupDir removes all the files passed in the
@@ -461,7 +733,7 @@ func DeleteFile(dst fs.Object) (err error) {
 //
 // If backupDir is set the files will be placed into that directory
 // instead of being deleted.
-func DeleteFilesWithBackupDir(toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error {
+func DeleteFilesWithBackupDir(ctx context.Context, toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error {
 	var wg sync.WaitGroup
 	wg.Add(fs.Config.Transfers)
 	var errorCount int32
@@ -471,7 +743,7 @@ func DeleteFilesWithBackupDir(toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error
 		go func() {
 			defer wg.Done()
 			for dst := range toBeDeleted {
-				err := DeleteFileWithBackupDir(dst, backupDir)
+				err := DeleteFileWithBackupDir(ctx, dst, backupDir)
 				if err != nil {
 					atomic.AddInt32(&errorCount, 1)
 					if fserrors.IsFatalError(err) {
@@ -496,8 +768,8 @@ func DeleteFilesWithBackupDir(toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error
 }
 
 // DeleteFiles removes all the files passed in the channel
-func DeleteFiles(toBeDeleted fs.ObjectsChan) error {
-	return DeleteFilesWithBackupDir(toBeDeleted, nil)
+func DeleteFiles(ctx context.Context, toBeDeleted fs.ObjectsChan) error {
+	return DeleteFilesWithBackupDir(ctx, toBeDeleted, nil)
 }
 
 // Read a Objects into add() for the given Fs.
@@ -507,8 +779,8 @@ func DeleteFiles(toBeDeleted fs.ObjectsChan) error {
 //
 // Each object is passed ito the function provided.  If that returns
 // an error then the listing will be aborted and that error returned.
-func readFilesFn(f fs.Fs, includeAll bool, dir string, add func(fs.Object) error) (err error) {
-	return walk.Walk(f, "", includeAll, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+func readFilesFn(ctx context.Context, f fs.Fs, includeAll bool, dir string, add func(fs.Object) error) (err error) {
+	return walk.Walk(ctx, f, "", includeAll, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			return err
 		}
@@ -576,6 +848,7 @@ type checkFn func(a, b fs.Object) (differ bool, noHash bool)
 type checkMarch struct {
 	fdst, fsrc      fs.Fs
 	check           checkFn
+	oneway          bool
 	differences     int32
 	noHashes        int32
 	srcFilesMissing int32
@@ -586,6 +859,9 @@ type checkMarch struct {
 func (c *checkMarch) DstOnly(dst fs.DirEntry) (recurse bool) {
 	switch dst.(type) {
 	case fs.Object:
+		if c.oneway {
+			return false
+		}
 		err := errors.Errorf("File not in %v", c.fsrc)
 		fs.Errorf(dst, "%v", err)
 		fs.CountError(err)
@@ -593,6 +869,9 @@ func (c *checkMarch) DstOnly(dst fs.DirEntry) (recurse bool) {
 		atomic.AddInt32(&c.srcFilesMissing, 1)
 	case fs.Directory:
 		// Do the same thing to the entire contents of the directory
+		if c.oneway {
+			return false
+		}
 		return true
 	default:
 		panic("Bad object in DirEntries")
@@ -681,15 +960,19 @@ func (c *checkMarch) Match(dst, src fs.DirEntry) (recurse bool) {
 //
 // it returns true if differences were found
 // it also returns whether it couldn't be hashed
-func CheckFn(fdst, fsrc fs.Fs, check checkFn) error {
+//
+// If oneway is set then files which are only in fdst aren't reported,
+// so it only checks that every file in fsrc is in fdst.
+func CheckFn(ctx context.Context, fdst, fsrc fs.Fs, check checkFn, oneway bool) error {
 	c := &checkMarch{
-		fdst:  fdst,
-		fsrc:  fsrc,
-		check: check,
+		fdst:   fdst,
+		fsrc:   fsrc,
+		check:  check,
+		oneway: oneway,
 	}
 
 	// set up a march over fdst and fsrc
-	m := march.New(context.Background(), fdst, fsrc, "", c)
+	m := march.New(ctx, fdst, fsrc, "", c)
 	fs.Infof(fdst, "Waiting for checks to finish")
 	m.Run()
 
@@ -711,8 +994,8 @@ func CheckFn(fdst, fsrc fs.Fs, check checkFn) error {
 }
 
 // Check the files in fsrc and fdst according to Size and hash
-func Check(fdst, fsrc fs.Fs) error {
-	return CheckFn(fdst, fsrc, checkIdentical)
+func Check(ctx context.Context, fdst, fsrc fs.Fs, oneway bool) error {
+	return CheckFn(ctx, fdst, fsrc, checkIdentical, oneway)
 }
 
 // CheckEqualReaders checks to see if in1 and in2 have the same
@@ -749,19 +1032,19 @@ func CheckEqualReaders(in1, in2 io.Reader) (differ bool, err error) {
 // reading all their bytes if necessary.
 //
 // it returns true if differences were found
-func CheckIdentical(dst, src fs.Object) (differ bool, err error) {
-	in1, err := dst.Open()
+func CheckIdentical(ctx context.Context, dst, src fs.Object) (differ bool, err error) {
+	in1, err := accounting.Open(ctx, dst)
 	if err != nil {
 		return true, errors.Wrapf(err, "failed to open %q", dst)
 	}
-	in1 = accounting.NewAccount(in1, dst).WithBuffer() // account and buffer the transfer
+	in1 = in1.WithBuffer() // buffer the transfer
 	defer fs.CheckClose(in1, &err)
 
-	in2, err := src.Open()
+	in2, err := accounting.Open(ctx, src)
 	if err != nil {
 		return true, errors.Wrapf(err, "failed to open %q", src)
 	}
-	in2 = accounting.NewAccount(in2, src).WithBuffer() // account and buffer the transfer
+	in2 = in2.WithBuffer() // buffer the transfer
 	defer fs.CheckClose(in2, &err)
 
 	return CheckEqualReaders(in1, in2)
@@ -769,9 +1052,9 @@ func CheckIdentical(dst, src fs.Object) (differ bool, err error) {
 
 // CheckDownload checks the files in fsrc and fdst according to Size
 // and the actual contents of the files.
-func CheckDownload(fdst, fsrc fs.Fs) error {
+func CheckDownload(ctx context.Context, fdst, fsrc fs.Fs, oneway bool) error {
 	check := func(a, b fs.Object) (differ bool, noHash bool) {
-		differ, err := CheckIdentical(a, b)
+		differ, err := CheckIdentical(ctx, a, b)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(a, "Failed to download: %v", err)
@@ -779,14 +1062,14 @@ func CheckDownload(fdst, fsrc fs.Fs) error {
 		}
 		return differ, false
 	}
-	return CheckFn(fdst, fsrc, check)
+	return CheckFn(ctx, fdst, fsrc, check, oneway)
 }
 
 // ListFn lists the Fs to the supplied function
 //
 // Lists in parallel which may get them out of order
-func ListFn(f fs.Fs, fn func(fs.Object)) error {
-	return walk.Walk(f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+func ListFn(ctx context.Context, f fs.Fs, fn func(fs.Object)) error {
+	return walk.Walk(ctx, f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			// FIXME count errors and carry on for listing
 			return err
@@ -813,8 +1096,8 @@ func syncFprintf(w io.Writer, format string, a ...interface{}) {
 // Shows size and path - obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func List(f fs.Fs, w io.Writer) error {
-	return ListFn(f, func(o fs.Object) {
+func List(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
 		syncFprintf(w, "%9d %s\n", o.Size(), o.Remote())
 	})
 }
@@ -824,8 +1107,8 @@ func List(f fs.Fs, w io.Writer) error {
 // Shows size, mod time and path - obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func ListLong(f fs.Fs, w io.Writer) error {
-	return ListFn(f, func(o fs.Object) {
+func ListLong(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
 		accounting.Stats.Checking(o.Remote())
 		modTime := o.ModTime()
 		accounting.Stats.DoneChecking(o.Remote())
@@ -839,8 +1122,8 @@ func ListLong(f fs.Fs, w io.Writer) error {
 // excludes
 //
 // Lists in parallel which may get them out of order
-func Md5sum(f fs.Fs, w io.Writer) error {
-	return HashLister(hash.MD5, f, w)
+func Md5sum(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return HashLister(ctx, hash.MD5, f, w)
 }
 
 // Sha1sum list the Fs to the supplied writer
@@ -848,8 +1131,8 @@ func Md5sum(f fs.Fs, w io.Writer) error {
 // Obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func Sha1sum(f fs.Fs, w io.Writer) error {
-	return HashLister(hash.SHA1, f, w)
+func Sha1sum(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return HashLister(ctx, hash.SHA1, f, w)
 }
 
 // DropboxHashSum list the Fs to the supplied writer
@@ -857,8 +1140,8 @@ func Sha1sum(f fs.Fs, w io.Writer) error {
 // Obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func DropboxHashSum(f fs.Fs, w io.Writer) error {
-	return HashLister(hash.Dropbox, f, w)
+func DropboxHashSum(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return HashLister(ctx, hash.Dropbox, f, w)
 }
 
 // hashSum returns the human readable hash for ht passed in.  This may
@@ -877,8 +1160,8 @@ func hashSum(ht hash.Type, o fs.Object) string {
 }
 
 // HashLister does a md5sum equivalent for the hash type passed in
-func HashLister(ht hash.Type, f fs.Fs, w io.Writer) error {
-	return ListFn(f, func(o fs.Object) {
+func HashLister(ctx context.Context, ht hash.Type, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
 		sum := hashSum(ht, o)
 		syncFprintf(w, "%*s  %s\n", hash.Width[ht], sum, o.Remote())
 	})
@@ -887,8 +1170,8 @@ func HashLister(ht hash.Type, f fs.Fs, w io.Writer) error {
 // Count counts the objects and their sizes in the Fs
 //
 // Obeys includes and excludes
-func Count(f fs.Fs) (objects int64, size int64, err error) {
-	err = ListFn(f, func(o fs.Object) {
+func Count(ctx context.Context, f fs.Fs) (objects int64, size int64, err error) {
+	err = ListFn(ctx, f, func(o fs.Object) {
 		atomic.AddInt64(&objects, 1)
 		atomic.AddInt64(&size, o.Size())
 	})
@@ -905,8 +1188,8 @@ func ConfigMaxDepth(recursive bool) int {
 }
 
 // ListDir lists the directories/buckets/containers in the Fs to the supplied writer
-func ListDir(f fs.Fs, w io.Writer) error {
-	return walk.Walk(f, "", false, ConfigMaxDepth(false), func(dirPath string, entries fs.DirEntries, err error) error {
+func ListDir(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return walk.Walk(ctx, f, "", false, ConfigMaxDepth(false), func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			// FIXME count errors and carry on for listing
 			return err
@@ -921,13 +1204,13 @@ func ListDir(f fs.Fs, w io.Writer) error {
 }
 
 // Mkdir makes a destination directory or container
-func Mkdir(f fs.Fs, dir string) error {
+func Mkdir(ctx context.Context, f fs.Fs, dir string) error {
 	if fs.Config.DryRun {
 		fs.Logf(fs.LogDirName(f, dir), "Not making directory as dry run is set")
 		return nil
 	}
 	fs.Debugf(fs.LogDirName(f, dir), "Making directory")
-	err := f.Mkdir(dir)
+	err := f.Mkdir(ctx, dir)
 	if err != nil {
 		fs.CountError(err)
 		return err
@@ -937,18 +1220,18 @@ func Mkdir(f fs.Fs, dir string) error {
 
 // TryRmdir removes a container but not if not empty.  It doesn't
 // count errors but may return one.
-func TryRmdir(f fs.Fs, dir string) error {
+func TryRmdir(ctx context.Context, f fs.Fs, dir string) error {
 	if fs.Config.DryRun {
 		fs.Logf(fs.LogDirName(f, dir), "Not deleting as dry run is set")
 		return nil
 	}
 	fs.Debugf(fs.LogDirName(f, dir), "Removing directory")
-	return f.Rmdir(dir)
+	return f.Rmdir(accounting.WithAPIClass(ctx, accounting.APIDelete), dir)
 }
 
 // Rmdir removes a container but not if not empty
-func Rmdir(f fs.Fs, dir string) error {
-	err := TryRmdir(f, dir)
+func Rmdir(ctx context.Context, f fs.Fs, dir string) error {
+	err := TryRmdir(ctx, f, dir)
 	if err != nil {
 		fs.CountError(err)
 		return err
@@ -957,7 +1240,7 @@ func Rmdir(f fs.Fs, dir string) error {
 }
 
 // Purge removes a directory and all of its contents
-func Purge(f fs.Fs, dir string) error {
+func Purge(ctx context.Context, f fs.Fs, dir string) error {
 	doFallbackPurge := true
 	var err error
 	if dir == "" {
@@ -966,8 +1249,8 @@ func Purge(f fs.Fs, dir string) error {
 			doFallbackPurge = false
 			if fs.Config.DryRun {
 				fs.Logf(f, "Not purging as --dry-run set")
-			} else {
-				err = doPurge()
+			} else if !SkipDestructive(ctx, f, "purge") {
+				err = doPurge(accounting.WithAPIClass(ctx, accounting.APIDelete))
 				if err == fs.ErrorCantPurge {
 					doFallbackPurge = true
 				}
@@ -976,11 +1259,11 @@ func Purge(f fs.Fs, dir string) error {
 	}
 	if doFallbackPurge {
 		// DeleteFiles and Rmdir observe --dry-run
-		err = DeleteFiles(listToChan(f, dir))
+		err = DeleteFiles(ctx, listToChan(ctx, f, dir))
 		if err != nil {
 			return err
 		}
-		err = Rmdirs(f, "", false)
+		err = Rmdirs(ctx, f, "", false)
 	}
 	if err != nil {
 		fs.CountError(err)
@@ -991,13 +1274,13 @@ func Purge(f fs.Fs, dir string) error {
 
 // Delete removes all the contents of a container.  Unlike Purge, it
 // obeys includes and excludes.
-func Delete(f fs.Fs) error {
+func Delete(ctx context.Context, f fs.Fs) error {
 	delete := make(fs.ObjectsChan, fs.Config.Transfers)
 	delErr := make(chan error, 1)
 	go func() {
-		delErr <- DeleteFiles(delete)
+		delErr <- DeleteFiles(ctx, delete)
 	}()
-	err := ListFn(f, func(o fs.Object) {
+	err := ListFn(ctx, f, func(o fs.Object) {
 		delete <- o
 	})
 	close(delete)
@@ -1014,11 +1297,11 @@ func Delete(f fs.Fs) error {
 // channel.
 //
 // If the error was ErrorDirNotFound then it will be ignored
-func listToChan(f fs.Fs, dir string) fs.ObjectsChan {
+func listToChan(ctx context.Context, f fs.Fs, dir string) fs.ObjectsChan {
 	o := make(fs.ObjectsChan, fs.Config.Checkers)
 	go func() {
 		defer close(o)
-		_ = walk.Walk(f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+		_ = walk.Walk(ctx, f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 			if err != nil {
 				if err == fs.ErrorDirNotFound {
 					return nil
@@ -1038,7 +1321,7 @@ func listToChan(f fs.Fs, dir string) fs.ObjectsChan {
 }
 
 // CleanUp removes the trash for the Fs
-func CleanUp(f fs.Fs) error {
+func CleanUp(ctx context.Context, f fs.Fs) error {
 	doCleanUp := f.Features().CleanUp
 	if doCleanUp == nil {
 		return errors.Errorf("%v doesn't support cleanup", f)
@@ -1047,7 +1330,173 @@ func CleanUp(f fs.Fs) error {
 		fs.Logf(f, "Not running cleanup as --dry-run set")
 		return nil
 	}
-	return doCleanUp()
+	return doCleanUp(ctx)
+}
+
+// SetTier changes the storage tier of all the objects in f to tier
+// without re-uploading them - obeys includes and excludes
+//
+// Objects which are already in tier are left alone.
+func SetTier(ctx context.Context, f fs.Fs, tier string) error {
+	if !f.Features().SetTier {
+		return errors.Errorf("%v doesn't support setting the storage tier", f)
+	}
+	var errorCount int32
+	err := ListFn(ctx, f, func(o fs.Object) {
+		do, ok := o.(fs.SetTierer)
+		if !ok {
+			atomic.AddInt32(&errorCount, 1)
+			err := errors.New("object doesn't support setting the storage tier")
+			fs.CountError(err)
+			fs.Errorf(o, "Failed to set tier: %v", err)
+			return
+		}
+		if getTier, ok := o.(fs.GetTierer); ok && strings.EqualFold(getTier.GetTier(), tier) {
+			fs.Debugf(o, "Already in tier %q", tier)
+			return
+		}
+		if fs.Config.DryRun {
+			fs.Logf(o, "Not setting tier to %q as --dry-run", tier)
+			return
+		}
+		err := do.SetTier(ctx, tier)
+		if err != nil {
+			atomic.AddInt32(&errorCount, 1)
+			fs.CountError(err)
+			fs.Errorf(o, "Failed to set tier: %v", err)
+			return
+		}
+		fs.Infof(o, "Set tier to %q", tier)
+	})
+	if err != nil {
+		return err
+	}
+	if errorCount > 0 {
+		return errors.Errorf("failed to set tier on %d objects", errorCount)
+	}
+	return nil
+}
+
+// RestoreVersions restores all the objects in f to the versions which
+// were current at time at - obeys includes and excludes
+//
+// f should have been made with --versions so the old versions are
+// listed.  Objects which didn't exist at time at are left alone.
+func RestoreVersions(ctx context.Context, f fs.Fs, at time.Time) error {
+	if !f.Features().Versions {
+		return errors.Errorf("%v doesn't support old versions", f)
+	}
+	type versions struct {
+		current fs.VersionRestorer   // the current version - may be nil
+		old     []fs.VersionRestorer // the old versions
+	}
+	var mu sync.Mutex
+	objects := map[string]*versions{}
+	err := ListFn(ctx, f, func(o fs.Object) {
+		do, ok := o.(fs.VersionRestorer)
+		if !ok {
+			return
+		}
+		t, remote := version.Remove(o.Remote())
+		mu.Lock()
+		defer mu.Unlock()
+		vs := objects[remote]
+		if vs == nil {
+			vs = &versions{}
+			objects[remote] = vs
+		}
+		if t.IsZero() {
+			vs.current = do
+		} else {
+			vs.old = append(vs.old, do)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	var errorCount int
+	for remote, vs := range objects {
+		if vs.current != nil && !vs.current.VersionTime().After(at) {
+			fs.Debugf(vs.current, "Current version is older than %v", at)
+			continue
+		}
+		var best fs.VersionRestorer
+		for _, o := range vs.old {
+			t := o.VersionTime()
+			if t.After(at) {
+				continue
+			}
+			if best == nil || t.After(best.VersionTime()) {
+				best = o
+			}
+		}
+		if best == nil {
+			fs.Logf(remote, "No version found before %v - leaving alone", at)
+			continue
+		}
+		if fs.Config.DryRun {
+			fs.Logf(best, "Not restoring as --dry-run")
+			continue
+		}
+		err := best.RestoreVersion(ctx)
+		if err != nil {
+			errorCount++
+			fs.CountError(err)
+			fs.Errorf(best, "Failed to restore: %v", err)
+			continue
+		}
+		fs.Infof(best, "Restored")
+	}
+	if errorCount > 0 {
+		return errors.Errorf("failed to restore %d objects", errorCount)
+	}
+	return nil
+}
+
+// RestoreTrash moves all the objects in the trash of f back to where
+// they were deleted from - obeys includes and excludes
+//
+// f should have been made with --trash so the trash is listed.
+func RestoreTrash(ctx context.Context, f fs.Fs) error {
+	if !f.Features().Trash {
+		return errors.Errorf("%v doesn't support listing the trash", f)
+	}
+	// Find the objects first so restoring them doesn't upset the
+	// listing
+	var mu sync.Mutex
+	var trashed []fs.TrashRestorer
+	err := ListFn(ctx, f, func(o fs.Object) {
+		do, ok := o.(fs.TrashRestorer)
+		if !ok {
+			fs.Debugf(o, "Can't restore from trash")
+			return
+		}
+		mu.Lock()
+		trashed = append(trashed, do)
+		mu.Unlock()
+	})
+	if err != nil {
+		return err
+	}
+	var errorCount int
+	for _, do := range trashed {
+		if fs.Config.DryRun {
+			fs.Logf(do, "Not restoring from trash as --dry-run")
+			continue
+		}
+		err := do.RestoreTrash(ctx)
+		if err != nil {
+			errorCount++
+			fs.CountError(err)
+			fs.Errorf(do, "Failed to restore from trash: %v", err)
+			continue
+		}
+		fs.Infof(do, "Restored from trash")
+	}
+	if errorCount > 0 {
+		return errors.Errorf("failed to restore %d objects from trash", errorCount)
+	}
+	return nil
 }
 
 // wrap a Reader and a Closer together into a ReadCloser
@@ -1064,9 +1513,9 @@ type readCloser struct {
 //
 // if count < 0 then it will be ignored
 // if count >= 0 then only that many characters will be output
-func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
+func Cat(ctx context.Context, f fs.Fs, w io.Writer, offset, count int64) error {
 	var mu sync.Mutex
-	return ListFn(f, func(o fs.Object) {
+	return ListFn(ctx, f, func(o fs.Object) {
 		var err error
 		accounting.Stats.Transferring(o.Remote())
 		defer func() {
@@ -1084,20 +1533,16 @@ func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
 		if opt.Start > 0 || opt.End >= 0 {
 			options = append(options, &opt)
 		}
-		in, err := o.Open(options...)
+		acc, err := accounting.Open(ctx, o, options...)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(o, "Failed to open: %v", err)
 			return
 		}
+		var in io.ReadCloser = acc.WithBuffer() // buffer the transfer
 		if count >= 0 {
 			in = &readCloser{Reader: &io.LimitedReader{R: in, N: count}, Closer: in}
-			// reduce remaining size to count
-			if size > count {
-				size = count
-			}
 		}
-		in = accounting.NewAccountSizeName(in, size, o.Remote()).WithBuffer() // account and buffer the transfer
 		defer func() {
 			err = in.Close()
 			if err != nil {
@@ -1117,7 +1562,7 @@ func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
 }
 
 // Rcat reads data from the Reader until EOF and uploads it to a file on remote
-func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (dst fs.Object, err error) {
+func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (dst fs.Object, err error) {
 	accounting.Stats.Transferring(dstFileName)
 	in = accounting.NewAccountSizeName(in, -1, dstFileName).WithBuffer()
 	defer func() {
@@ -1128,6 +1573,10 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	}()
 
 	hashOption := &fs.HashesOption{Hashes: fdst.Hashes()}
+	retention, err := retentionOptions(fdst)
+	if err != nil {
+		return nil, err
+	}
 	hash, err := hash.NewMultiHasherTypes(fdst.Hashes())
 	if err != nil {
 		return nil, err
@@ -1137,7 +1586,7 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 
 	compare := func(dst fs.Object) error {
 		src := object.NewStaticObjectInfo(dstFileName, modTime, int64(readCounter.BytesRead()), false, hash.Sums(), fdst)
-		if !Equal(src, dst) {
+		if !Equal(ctx, src, dst) {
 			err = errors.Errorf("corrupted on transfer")
 			fs.CountError(err)
 			fs.Errorf(dst, "%v", err)
@@ -1151,7 +1600,7 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	if n, err := io.ReadFull(trackingIn, buf); err == io.EOF || err == io.ErrUnexpectedEOF {
 		fs.Debugf(fdst, "File to upload is small (%d bytes), uploading instead of streaming", n)
 		src := object.NewMemoryObject(dstFileName, modTime, buf[:n])
-		return Copy(fdst, nil, dstFileName, src)
+		return Copy(ctx, fdst, nil, dstFileName, src)
 	}
 
 	// Make a new ReadCloser with the bits we've already read
@@ -1165,11 +1614,14 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	if !canStream {
 		fs.Debugf(fdst, "Target remote doesn't support streaming uploads, creating temporary local FS to spool file")
 		tmpLocalFs, err := fs.TemporaryLocalFs()
+		if err == fs.ErrorSpoolingNotAllowed {
+			return nil, fserrors.NoRetryError(errors.Wrapf(err, "%v doesn't support streaming uploads of files bigger than --streaming-upload-cutoff", fdst))
+		}
 		if err != nil {
 			return nil, errors.Wrap(err, "Failed to create temporary local FS to spool file")
 		}
 		defer func() {
-			err := Purge(tmpLocalFs, "")
+			err := Purge(ctx, tmpLocalFs, "")
 			if err != nil {
 				fs.Infof(tmpLocalFs, "Failed to cleanup temporary FS: %v", err)
 			}
@@ -1184,8 +1636,23 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 		return nil, err
 	}
 
+	options := []fs.OpenOption{hashOption}
+	if canStream {
+		// if spooling, the Copy below locks the object
+		options = append(options, retention...)
+		if fs.Config.UploadLockDir != "" {
+			// the stream can't be compared with what another
+			// process uploaded so it is always uploaded
+			var unlock func()
+			unlock, _, err = acquireUploadLock(ctx, fdst, dstFileName)
+			if err != nil {
+				return nil, err
+			}
+			defer unlock()
+		}
+	}
 	objInfo := object.NewStaticObjectInfo(dstFileName, modTime, -1, false, nil, nil)
-	if dst, err = fStreamTo.Features().PutStream(in, objInfo, hashOption); err != nil {
+	if dst, err = fStreamTo.Features().PutStream(ctx, in, objInfo, options...); err != nil {
 		return dst, err
 	}
 	if err = compare(dst); err != nil {
@@ -1193,26 +1660,26 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	}
 	if !canStream {
 		// copy dst (which is the local object we have just streamed to) to the remote
-		return Copy(fdst, nil, dstFileName, dst)
+		return Copy(ctx, fdst, nil, dstFileName, dst)
 	}
 	return dst, nil
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
-func PublicLink(f fs.Fs, remote string) (string, error) {
+func PublicLink(ctx context.Context, f fs.Fs, remote string, expire fs.Duration, password string) (string, error) {
 	doPublicLink := f.Features().PublicLink
 	if doPublicLink == nil {
 		return "", errors.Errorf("%v doesn't support public links", f)
 	}
-	return doPublicLink(remote)
+	return doPublicLink(ctx, remote, expire, password)
 }
 
 // Rmdirs removes any empty directories (or directories only
 // containing empty directories) under f, including f.
-func Rmdirs(f fs.Fs, dir string, leaveRoot bool) error {
+func Rmdirs(ctx context.Context, f fs.Fs, dir string, leaveRoot bool) error {
 	dirEmpty := make(map[string]bool)
 	dirEmpty[""] = !leaveRoot
-	err := walk.Walk(f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+	err := walk.Walk(ctx, f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(f, "Failed to list %q: %v", dirPath, err)
@@ -1259,7 +1726,7 @@ func Rmdirs(f fs.Fs, dir string, leaveRoot bool) error {
 	sort.Strings(toDelete)
 	for i := len(toDelete) - 1; i >= 0; i-- {
 		dir := toDelete[i]
-		err := TryRmdir(f, dir)
+		err := TryRmdir(ctx, f, dir)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(dir, "Failed to rmdir: %v", err)
@@ -1274,7 +1741,7 @@ func Rmdirs(f fs.Fs, dir string, leaveRoot bool) error {
 //
 // Returns a flag which indicates whether the file needs to be
 // transferred or not.
-func NeedTransfer(dst, src fs.Object) bool {
+func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
 	if dst == nil {
 		fs.Debugf(src, "Couldn't find file - need to transfer")
 		return true
@@ -1317,7 +1784,7 @@ func NeedTransfer(dst, src fs.Object) bool {
 		}
 	} else {
 		// Check to see if changed or not
-		if Equal(src, dst) {
+		if Equal(ctx, src, dst) {
 			fs.Debugf(src, "Unchanged skipping")
 			return false
 		}
@@ -1326,7 +1793,7 @@ func NeedTransfer(dst, src fs.Object) bool {
 }
 
 // moveOrCopyFile moves or copies a single file possibly to a new name
-func moveOrCopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string, cp bool) (err error) {
+func moveOrCopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string, cp bool) (err error) {
 	dstFilePath := path.Join(fdst.Root(), dstFileName)
 	srcFilePath := path.Join(fsrc.Root(), srcFileName)
 	if fdst.Name() == fsrc.Name() && dstFilePath == srcFilePath {
@@ -1341,41 +1808,92 @@ func moveOrCopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName stri
 	}
 
 	// Find src object
-	srcObj, err := fsrc.NewObject(srcFileName)
+	srcObj, err := fsrc.NewObject(ctx, srcFileName)
 	if err != nil {
 		return err
 	}
 
+	// Changing just the case of a file name on a case insensitive
+	// remote needs a rename via a temporary name, otherwise the
+	// destination is found as the source itself.
+	if !cp && fdst.Name() == fsrc.Name() && fdst.Features().CaseInsensitive && strings.ToLower(dstFilePath) == strings.ToLower(srcFilePath) {
+		return caseRename(ctx, fdst, dstFileName, srcObj)
+	}
+
 	// Find dst object if it exists
-	dstObj, err := fdst.NewObject(dstFileName)
+	dstObj, err := fdst.NewObject(ctx, dstFileName)
 	if err == fs.ErrorObjectNotFound {
 		dstObj = nil
 	} else if err != nil {
 		return err
 	}
 
-	if NeedTransfer(dstObj, srcObj) {
+	if NeedTransfer(ctx, dstObj, srcObj) {
 		accounting.Stats.Transferring(srcFileName)
-		_, err = Op(fdst, dstObj, dstFileName, srcObj)
+		_, err = Op(ctx, fdst, dstObj, dstFileName, srcObj)
 		accounting.Stats.DoneTransferring(srcFileName, err == nil)
 	} else {
 		accounting.Stats.Checking(srcFileName)
 		if !cp {
-			err = DeleteFile(srcObj)
+			err = DeleteFile(ctx, srcObj)
 		}
 		defer accounting.Stats.DoneChecking(srcFileName)
 	}
 	return err
 }
 
+// caseRename moves srcObj to dstFileName on fdst where the names
+// differ only in case by moving it to a temporary name first
+func caseRename(ctx context.Context, fdst fs.Fs, dstFileName string, srcObj fs.Object) (err error) {
+	tmpFileName := fmt.Sprintf("%s.rclone-case-%08x", dstFileName, rand.Uint32())
+	_, err = fdst.NewObject(ctx, tmpFileName)
+	if err == nil {
+		return errors.Errorf("temporary file %q for case rename already exists - try again", tmpFileName)
+	} else if err != fs.ErrorObjectNotFound {
+		return errors.Wrap(err, "failed to check temporary file for case rename")
+	}
+	srcFileName := srcObj.Remote()
+	if fs.Config.DryRun {
+		fs.Logf(srcObj, "Not changing case of name to %q as --dry-run", dstFileName)
+		return nil
+	}
+	accounting.Stats.Transferring(srcFileName)
+	defer func() {
+		accounting.Stats.DoneTransferring(srcFileName, err == nil)
+	}()
+	fs.Debugf(srcObj, "Changing case of name via %q", tmpFileName)
+	tmpObj, err := Move(ctx, fdst, nil, tmpFileName, srcObj)
+	if err != nil {
+		return errors.Wrap(err, "failed to move to temporary file for case rename")
+	}
+	if tmpObj == nil {
+		// the move worked but didn't return the object
+		tmpObj, err = fdst.NewObject(ctx, tmpFileName)
+		if err != nil {
+			return errors.Wrap(err, "failed to find temporary file for case rename")
+		}
+	}
+	_, err = Move(ctx, fdst, nil, dstFileName, tmpObj)
+	if err != nil {
+		// put the file back rather than leave it under the
+		// temporary name
+		_, backErr := Move(ctx, fdst, nil, srcFileName, tmpObj)
+		if backErr != nil {
+			fs.Errorf(tmpObj, "Failed to move back to %q after failed case rename: %v", srcFileName, backErr)
+		}
+		return err
+	}
+	return nil
+}
+
 // MoveFile moves a single file possibly to a new name
-func MoveFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
-	return moveOrCopyFile(fdst, fsrc, dstFileName, srcFileName, false)
+func MoveFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
+	return moveOrCopyFile(ctx, fdst, fsrc, dstFileName, srcFileName, false)
 }
 
 // CopyFile moves a single file possibly to a new name
-func CopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
-	return moveOrCopyFile(fdst, fsrc, dstFileName, srcFileName, true)
+func CopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
+	return moveOrCopyFile(ctx, fdst, fsrc, dstFileName, srcFileName, true)
 }
 
 // ListFormat defines files information print format
@@ -1426,6 +1944,27 @@ func (l *ListFormat) AddPath() {
 	})
 }
 
+// AddID adds the ID of directories to the output, "" if unknown
+func (l *ListFormat) AddID() {
+	l.AppendOutput(func() string {
+		if d, ok := l.entry.(fs.Directory); ok {
+			return d.ID()
+		}
+		return ""
+	})
+}
+
+// AddItems adds the count of items in directories to the output, -1
+// if unknown
+func (l *ListFormat) AddItems() {
+	l.AppendOutput(func() string {
+		if d, ok := l.entry.(fs.Directory); ok {
+			return strconv.FormatInt(d.Items(), 10)
+		}
+		return ""
+	})
+}
+
 // AddHash adds the hash of the type given to the output
 func (l *ListFormat) AddHash(ht hash.Type) {
 	l.AppendOutput(func() string {
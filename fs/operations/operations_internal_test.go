@@ -3,12 +3,17 @@
 package operations
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/object"
+	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,3 +43,153 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+func TestChooseCompareStrategy(t *testing.T) {
+	none := hash.Set(hash.None)
+	md5 := hash.Set(hash.MD5)
+	for _, test := range []struct {
+		sizeOnly     bool
+		checkSum     bool
+		common       hash.Set
+		modifyWindow time.Duration
+		want         CompareStrategy
+	}{
+		{false, false, md5, time.Second, CompareModTime},
+		{false, false, none, time.Second, CompareModTime},
+		{false, false, md5, fs.ModTimeNotSupported, CompareSizeOnly},
+		{true, false, md5, time.Second, CompareSizeOnly},
+		{true, true, md5, time.Second, CompareSizeOnly},
+		{false, true, md5, time.Second, CompareCheckSum},
+		{false, true, md5, fs.ModTimeNotSupported, CompareCheckSum},
+		{false, true, none, time.Second, CompareModTime},
+		{false, true, none, fs.ModTimeNotSupported, CompareSizeOnly},
+	} {
+		got := chooseCompareStrategy(test.sizeOnly, test.checkSum, test.common, test.modifyWindow)
+		assert.Equal(t, test.want, got, fmt.Sprintf("%+v", test))
+	}
+}
+
+func TestCompareStrategyString(t *testing.T) {
+	assert.Equal(t, "size only", CompareSizeOnly.String())
+	assert.Equal(t, "CompareStrategy(99)", CompareStrategy(99).String())
+}
+
+func TestVerifyTransfer(t *testing.T) {
+	ctx := context.Background()
+	oldDelay, oldRetries := fs.Config.VerifyDelay, fs.Config.VerifyRetries
+	defer func() {
+		fs.Config.VerifyDelay, fs.Config.VerifyRetries = oldDelay, oldRetries
+	}()
+	when := time.Now()
+	src := object.NewMemoryObject("file", when, []byte("potato"))
+	stale := object.NewMemoryObject("file", when, []byte("old"))
+	good := object.NewMemoryObject("file", when, []byte("potato"))
+
+	// newObject returns the objects passed in one by one
+	lookups := 0
+	newObjects := func(objs ...fs.Object) func(ctx context.Context, remote string) (fs.Object, error) {
+		lookups = 0
+		return func(ctx context.Context, remote string) (fs.Object, error) {
+			assert.Equal(t, "file", remote)
+			if lookups >= len(objs) {
+				return nil, fs.ErrorObjectNotFound
+			}
+			o := objs[lookups]
+			lookups++
+			if o == nil {
+				return nil, fs.ErrorObjectNotFound
+			}
+			return o, nil
+		}
+	}
+
+	// no recheck without --verify-delay
+	fs.Config.VerifyDelay, fs.Config.VerifyRetries = 0, 3
+	dst, corrupted, err := verifyTransfer(ctx, src, stale, hash.MD5, newObjects(good))
+	assert.NoError(t, err)
+	assert.Error(t, corrupted)
+	assert.Equal(t, stale, dst)
+	assert.Equal(t, 0, lookups)
+
+	// the new object shows up on the second recheck
+	fs.Config.VerifyDelay = time.Millisecond
+	dst, corrupted, err = verifyTransfer(ctx, src, stale, hash.MD5, newObjects(nil, stale, good))
+	assert.NoError(t, err)
+	assert.NoError(t, corrupted)
+	assert.Equal(t, good, dst)
+	assert.Equal(t, 3, lookups)
+
+	// gives up after --verify-retries
+	fs.Config.VerifyRetries = 2
+	_, corrupted, err = verifyTransfer(ctx, src, stale, hash.MD5, newObjects(stale, stale, good))
+	assert.NoError(t, err)
+	assert.Error(t, corrupted)
+	assert.Equal(t, 2, lookups)
+
+	// a good transfer isn't rechecked
+	dst, corrupted, err = verifyTransfer(ctx, src, good, hash.MD5, newObjects(stale))
+	assert.NoError(t, err)
+	assert.NoError(t, corrupted)
+	assert.Equal(t, good, dst)
+	assert.Equal(t, 0, lookups)
+}
+
+func TestSkipDestructive(t *testing.T) {
+	ctx := context.Background()
+	var answers []string
+	oldReadLine, oldInteractive := config.ReadLine, fs.Config.Interactive
+	defer func() {
+		config.ReadLine, fs.Config.Interactive = oldReadLine, oldInteractive
+		skipAll, confirmAll = false, false
+	}()
+	config.ReadLine = func() string {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer
+	}
+
+	// doesn't ask unless --interactive
+	fs.Config.Interactive = false
+	assert.False(t, SkipDestructive(ctx, "file", "delete"))
+
+	fs.Config.Interactive = true
+	answers = []string{"y", "n", "x", "N"}
+	assert.False(t, SkipDestructive(ctx, "file", "delete"))
+	assert.True(t, SkipDestructive(ctx, "file", "delete"))
+	assert.True(t, SkipDestructive(ctx, "file", "delete"))
+	assert.Len(t, answers, 0)
+
+	// doesn't ask again once confirmed
+	assert.False(t, SkipDestructive(withConfirmed(ctx), "file", "delete"))
+
+	// skip all
+	answers = []string{"s"}
+	assert.True(t, SkipDestructive(ctx, "file", "delete"))
+	assert.True(t, SkipDestructive(ctx, "file2", "overwrite"))
+	assert.Len(t, answers, 0)
+
+	// all
+	skipAll = false
+	answers = []string{"a"}
+	assert.False(t, SkipDestructive(ctx, "file", "delete"))
+	assert.False(t, SkipDestructive(ctx, "file2", "overwrite"))
+	assert.Len(t, answers, 0)
+}
+
+func TestDeleteFileSkippedNotCounted(t *testing.T) {
+	ctx := context.Background()
+	oldReadLine, oldInteractive, oldMaxDelete := config.ReadLine, fs.Config.Interactive, fs.Config.MaxDelete
+	defer func() {
+		config.ReadLine, fs.Config.Interactive, fs.Config.MaxDelete = oldReadLine, oldInteractive, oldMaxDelete
+		skipAll, confirmAll = false, false
+	}()
+	config.ReadLine = func() string { return "n" }
+	fs.Config.Interactive = true
+	fs.Config.MaxDelete = 0
+	accounting.Stats.ResetCounters()
+
+	// a skipped delete doesn't count towards --max-delete
+	err := DeleteFile(ctx, mockobject.Object("file"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), accounting.Stats.Deletes(0))
+}
@@ -21,12 +21,16 @@ package operations_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
+	"path"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,10 +38,12 @@ import (
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/list"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fstest"
+	"github.com/ncw/rclone/lib/filelock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -58,11 +64,11 @@ func TestMkdir(t *testing.T) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
 
-	err := operations.Mkdir(r.Fremote, "")
+	err := operations.Mkdir(context.Background(), r.Fremote, "")
 	require.NoError(t, err)
 	fstest.CheckListing(t, r.Fremote, []fstest.Item{})
 
-	err = operations.Mkdir(r.Fremote, "")
+	err = operations.Mkdir(context.Background(), r.Fremote, "")
 	require.NoError(t, err)
 }
 
@@ -74,7 +80,7 @@ func TestLsd(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 
 	var buf bytes.Buffer
-	err := operations.ListDir(r.Fremote, &buf)
+	err := operations.ListDir(context.Background(), r.Fremote, &buf)
 	require.NoError(t, err)
 	res := buf.String()
 	assert.Contains(t, res, "sub dir\n")
@@ -89,7 +95,7 @@ func TestLs(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1, file2)
 
 	var buf bytes.Buffer
-	err := operations.List(r.Fremote, &buf)
+	err := operations.List(context.Background(), r.Fremote, &buf)
 	require.NoError(t, err)
 	res := buf.String()
 	assert.Contains(t, res, "        0 empty space\n")
@@ -105,7 +111,7 @@ func TestLsLong(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1, file2)
 
 	var buf bytes.Buffer
-	err := operations.ListLong(r.Fremote, &buf)
+	err := operations.ListLong(context.Background(), r.Fremote, &buf)
 	require.NoError(t, err)
 	res := buf.String()
 	lines := strings.Split(strings.Trim(res, "\n"), "\n")
@@ -152,7 +158,7 @@ func TestHashSums(t *testing.T) {
 	// MD5 Sum
 
 	var buf bytes.Buffer
-	err := operations.Md5sum(r.Fremote, &buf)
+	err := operations.Md5sum(context.Background(), r.Fremote, &buf)
 	require.NoError(t, err)
 	res := buf.String()
 	if !strings.Contains(res, "d41d8cd98f00b204e9800998ecf8427e  empty space\n") &&
@@ -169,7 +175,7 @@ func TestHashSums(t *testing.T) {
 	// SHA1 Sum
 
 	buf.Reset()
-	err = operations.Sha1sum(r.Fremote, &buf)
+	err = operations.Sha1sum(context.Background(), r.Fremote, &buf)
 	require.NoError(t, err)
 	res = buf.String()
 	if !strings.Contains(res, "da39a3ee5e6b4b0d3255bfef95601890afd80709  empty space\n") &&
@@ -186,7 +192,7 @@ func TestHashSums(t *testing.T) {
 	// Dropbox Hash Sum
 
 	buf.Reset()
-	err = operations.DropboxHashSum(r.Fremote, &buf)
+	err = operations.DropboxHashSum(context.Background(), r.Fremote, &buf)
 	require.NoError(t, err)
 	res = buf.String()
 	if !strings.Contains(res, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  empty space\n") &&
@@ -214,7 +220,7 @@ func TestCount(t *testing.T) {
 	fs.Config.MaxDepth = 1
 	defer func() { fs.Config.MaxDepth = -1 }()
 
-	objects, size, err := operations.Count(r.Fremote)
+	objects, size, err := operations.Count(context.Background(), r.Fremote)
 	require.NoError(t, err)
 	assert.Equal(t, int64(2), objects)
 	assert.Equal(t, int64(60), size)
@@ -233,19 +239,19 @@ func TestDelete(t *testing.T) {
 		filter.Active.Opt.MaxSize = -1
 	}()
 
-	err := operations.Delete(r.Fremote)
+	err := operations.Delete(context.Background(), r.Fremote)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Fremote, file3)
 }
 
-func testCheck(t *testing.T, checkFunction func(fdst, fsrc fs.Fs) error) {
+func testCheck(t *testing.T, checkFunction func(ctx context.Context, fdst, fsrc fs.Fs, oneway bool) error, oneway bool) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
 
 	check := func(i int, wantErrors int64) {
 		fs.Debugf(r.Fremote, "%d: Starting check test", i)
 		oldErrors := accounting.Stats.GetErrors()
-		err := checkFunction(r.Flocal, r.Fremote)
+		err := checkFunction(context.Background(), r.Flocal, r.Fremote, oneway)
 		gotErrors := accounting.Stats.GetErrors() - oldErrors
 		if wantErrors == 0 && err != nil {
 			t.Errorf("%d: Got error when not expecting one: %v", i, err)
@@ -266,11 +272,20 @@ func testCheck(t *testing.T, checkFunction func(fdst, fsrc fs.Fs) error) {
 
 	file2 := r.WriteFile("potato2", "------------------------------------------------------------", t1)
 	fstest.CheckItems(t, r.Flocal, file1, file2)
-	check(2, 1)
+	// file2 is only in the destination
+	if oneway {
+		check(2, 0)
+	} else {
+		check(2, 1)
+	}
 
 	file3 := r.WriteObject("empty space", "", t2)
 	fstest.CheckItems(t, r.Fremote, file1, file3)
-	check(3, 2)
+	if oneway {
+		check(3, 1)
+	} else {
+		check(3, 2)
+	}
 
 	file2r := file2
 	if fs.Config.SizeOnly {
@@ -287,11 +302,15 @@ func testCheck(t *testing.T, checkFunction func(fdst, fsrc fs.Fs) error) {
 }
 
 func TestCheck(t *testing.T) {
-	testCheck(t, operations.Check)
+	testCheck(t, operations.Check, false)
+}
+
+func TestCheckOneWay(t *testing.T) {
+	testCheck(t, operations.Check, true)
 }
 
 func TestCheckDownload(t *testing.T) {
-	testCheck(t, operations.CheckDownload)
+	testCheck(t, operations.CheckDownload, false)
 }
 
 func TestCheckSizeOnly(t *testing.T) {
@@ -300,6 +319,37 @@ func TestCheckSizeOnly(t *testing.T) {
 	TestCheck(t)
 }
 
+func TestCheckDuplicates(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("a/potato", "same contents", t1)
+	file2 := r.WriteFile("same path", "same path contents", t1)
+	file3 := r.WriteFile("unique", "only on the source", t1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+	file4 := r.WriteObject("b/potato", "same contents", t2)
+	file5 := r.WriteObject("c/potato copy", "same contents", t2)
+	file6 := r.WriteObject("same path", "same path contents", t2)
+	file7 := r.WriteObject("other", "different contents", t2)
+	fstest.CheckItems(t, r.Fremote, file4, file5, file6, file7)
+
+	ht, duplicates, err := operations.FindDuplicates(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	sum, err := hash.NewMultiHasherTypes(hash.NewHashSet(ht))
+	require.NoError(t, err)
+	_, err = sum.Write([]byte("same contents"))
+	require.NoError(t, err)
+	wantHash := sum.Sums()[ht]
+	assert.Equal(t, []operations.Duplicate{
+		{Hash: wantHash, Size: 13, SrcPath: "a/potato", DstPath: "b/potato"},
+		{Hash: wantHash, Size: 13, SrcPath: "a/potato", DstPath: "c/potato copy"},
+	}, duplicates)
+
+	buf := new(bytes.Buffer)
+	err = operations.CheckDuplicates(context.Background(), r.Fremote, r.Flocal, buf)
+	require.NoError(t, err)
+	assert.Equal(t, wantHash+"\t13\ta/potato\tb/potato\n"+wantHash+"\t13\ta/potato\tc/potato copy\n", buf.String())
+}
+
 func TestCat(t *testing.T) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
@@ -320,7 +370,7 @@ func TestCat(t *testing.T) {
 		{1, 3, "BCD", "123"},
 	} {
 		var buf bytes.Buffer
-		err := operations.Cat(r.Fremote, &buf, test.offset, test.count)
+		err := operations.Cat(context.Background(), r.Fremote, &buf, test.offset, test.count)
 		require.NoError(t, err)
 		res := buf.String()
 
@@ -353,11 +403,11 @@ func TestRcat(t *testing.T) {
 		path2 := prefix + "big_file_from_pipe"
 
 		in := ioutil.NopCloser(strings.NewReader(data1))
-		_, err := operations.Rcat(r.Fremote, path1, in, t1)
+		_, err := operations.Rcat(context.Background(), r.Fremote, path1, in, t1)
 		require.NoError(t, err)
 
 		in = ioutil.NopCloser(strings.NewReader(data2))
-		_, err = operations.Rcat(r.Fremote, path2, in, t2)
+		_, err = operations.Rcat(context.Background(), r.Fremote, path2, in, t2)
 		require.NoError(t, err)
 
 		file1 := fstest.NewItem(path1, data1, t1)
@@ -378,13 +428,13 @@ func TestRmdirsNoLeaveRoot(t *testing.T) {
 	r.ForceMkdir(r.Fremote)
 	file1 := r.WriteObject("A1/B1/C1/one", "aaa", t1)
 	//..and dirs we expect to delete
-	require.NoError(t, operations.Mkdir(r.Fremote, "A2"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A1/B2"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A1/B2/C2"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A1/B1/C3"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A3"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A3/B3"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A3/B3/C4"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A2"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A1/B2"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A1/B2/C2"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A1/B1/C3"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A3"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A3/B3"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A3/B3/C4"))
 	//..and one more file at the end
 	file2 := r.WriteObject("A1/two", "bbb", t2)
 
@@ -409,7 +459,7 @@ func TestRmdirsNoLeaveRoot(t *testing.T) {
 		fs.Config.ModifyWindow,
 	)
 
-	require.NoError(t, operations.Rmdirs(r.Fremote, "", false))
+	require.NoError(t, operations.Rmdirs(context.Background(), r.Fremote, "", false))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -434,9 +484,9 @@ func TestRmdirsLeaveRoot(t *testing.T) {
 
 	r.ForceMkdir(r.Fremote)
 
-	require.NoError(t, operations.Mkdir(r.Fremote, "A1"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A1/B1"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "A1/B1/C1"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A1"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A1/B1"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "A1/B1/C1"))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -450,7 +500,7 @@ func TestRmdirsLeaveRoot(t *testing.T) {
 		fs.Config.ModifyWindow,
 	)
 
-	require.NoError(t, operations.Rmdirs(r.Fremote, "A1", true))
+	require.NoError(t, operations.Rmdirs(context.Background(), r.Fremote, "A1", true))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -473,7 +523,7 @@ func TestMoveFile(t *testing.T) {
 	file2 := file1
 	file2.Path = "sub/file2"
 
-	err := operations.MoveFile(r.Fremote, r.Flocal, file2.Path, file1.Path)
+	err := operations.MoveFile(context.Background(), r.Fremote, r.Flocal, file2.Path, file1.Path)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal)
 	fstest.CheckItems(t, r.Fremote, file2)
@@ -481,17 +531,77 @@ func TestMoveFile(t *testing.T) {
 	r.WriteFile("file1", "file1 contents", t1)
 	fstest.CheckItems(t, r.Flocal, file1)
 
-	err = operations.MoveFile(r.Fremote, r.Flocal, file2.Path, file1.Path)
+	err = operations.MoveFile(context.Background(), r.Fremote, r.Flocal, file2.Path, file1.Path)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal)
 	fstest.CheckItems(t, r.Fremote, file2)
 
-	err = operations.MoveFile(r.Fremote, r.Fremote, file2.Path, file2.Path)
+	err = operations.MoveFile(context.Background(), r.Fremote, r.Fremote, file2.Path, file2.Path)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal)
 	fstest.CheckItems(t, r.Fremote, file2)
 }
 
+// caseInsensitiveFs makes an Fs look case insensitive
+type caseInsensitiveFs struct {
+	fs.Fs
+	features *fs.Features
+}
+
+func (f *caseInsensitiveFs) Features() *fs.Features {
+	return f.features
+}
+
+func TestMoveFileCaseRename(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	file1 := r.WriteObject("file1.txt", "file1 contents", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	f := &caseInsensitiveFs{Fs: r.Fremote}
+	f.features = (&fs.Features{CaseInsensitive: true}).Fill(f)
+
+	file2 := file1
+	file2.Path = "FILE1.txt"
+
+	// dry run changes nothing
+	fs.Config.DryRun = true
+	err := operations.MoveFile(context.Background(), f, f, file2.Path, file1.Path)
+	fs.Config.DryRun = false
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	// a failed rename leaves the file where it was
+	failing := &failingPutFs{Fs: r.Fremote, fail: file2.Path}
+	failing.features = (&fs.Features{CaseInsensitive: true}).Fill(failing)
+	err = operations.MoveFile(context.Background(), failing, failing, file2.Path, file1.Path)
+	require.Error(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	err = operations.MoveFile(context.Background(), f, f, file2.Path, file1.Path)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file2)
+}
+
+// failingPutFs is a case insensitive Fs which can't upload to fail
+type failingPutFs struct {
+	fs.Fs
+	features *fs.Features
+	fail     string
+}
+
+func (f *failingPutFs) Features() *fs.Features {
+	return f.features
+}
+
+func (f *failingPutFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	if src.Remote() == f.fail {
+		return nil, errors.New("upload failed")
+	}
+	return f.Fs.Put(ctx, in, src, options...)
+}
+
 func TestCopyFile(t *testing.T) {
 	r := fstest.NewRun(t)
 	defer r.Finalise()
@@ -502,22 +612,292 @@ func TestCopyFile(t *testing.T) {
 	file2 := file1
 	file2.Path = "sub/file2"
 
-	err := operations.CopyFile(r.Fremote, r.Flocal, file2.Path, file1.Path)
+	err := operations.CopyFile(context.Background(), r.Fremote, r.Flocal, file2.Path, file1.Path)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file2)
 
-	err = operations.CopyFile(r.Fremote, r.Flocal, file2.Path, file1.Path)
+	err = operations.CopyFile(context.Background(), r.Fremote, r.Flocal, file2.Path, file1.Path)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file2)
 
-	err = operations.CopyFile(r.Fremote, r.Fremote, file2.Path, file2.Path)
+	err = operations.CopyFile(context.Background(), r.Fremote, r.Fremote, file2.Path, file2.Path)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file2)
 }
 
+func TestCopyUploadLock(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	lockDir, err := ioutil.TempDir("", "rclone-upload-lock-test")
+	require.NoError(t, err)
+	fs.Config.UploadLockDir = lockDir
+	defer func() {
+		fs.Config.UploadLockDir = ""
+		require.NoError(t, os.RemoveAll(lockDir))
+	}()
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	src, err := r.Flocal.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+
+	// another process holds the lock and uploads the file
+	key := path.Join(r.Fremote.Name()+":"+r.Fremote.Root(), file1.Path)
+	lock, _, err := filelock.Acquire(ctx, lockDir, key)
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		r.WriteObject(file1.Path, "file1 contents", t1)
+		assert.NoError(t, lock.Release())
+	}()
+
+	accounting.Stats.ResetCounters()
+	dst, err := operations.Copy(ctx, r.Fremote, nil, file1.Path, src)
+	require.NoError(t, err)
+	require.NotNil(t, dst)
+	assert.Equal(t, int64(0), accounting.Stats.RemoteStats()["bytes"], "shouldn't upload again")
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+func TestRcatUploadLock(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	lockDir, err := ioutil.TempDir("", "rclone-upload-lock-test")
+	require.NoError(t, err)
+	oldCutoff := fs.Config.StreamingUploadCutoff
+	fs.Config.UploadLockDir = lockDir
+	fs.Config.StreamingUploadCutoff = 1
+	defer func() {
+		fs.Config.UploadLockDir = ""
+		fs.Config.StreamingUploadCutoff = oldCutoff
+		require.NoError(t, os.RemoveAll(lockDir))
+	}()
+
+	// another process holds the lock so the stream waits for it
+	key := path.Join(r.Fremote.Name()+":"+r.Fremote.Root(), "file1")
+	lock, _, err := filelock.Acquire(ctx, lockDir, key)
+	require.NoError(t, err)
+	var released int32
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		atomic.StoreInt32(&released, 1)
+		assert.NoError(t, lock.Release())
+	}()
+
+	in := ioutil.NopCloser(strings.NewReader("file1 contents"))
+	_, err = operations.Rcat(ctx, r.Fremote, "file1", in, t1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&released), "should wait for the lock")
+	file1 := fstest.NewItem("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+// putByHashFs makes an Fs which has the content with the md5sums in
+// contents already and counts the uploads
+type putByHashFs struct {
+	fs.Fs
+	features *fs.Features
+	contents map[string]string
+	puts     int
+}
+
+func (f *putByHashFs) Features() *fs.Features {
+	return f.features
+}
+
+func (f *putByHashFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	f.puts++
+	return f.Fs.Put(ctx, in, src, options...)
+}
+
+func (f *putByHashFs) PutByHash(ctx context.Context, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	md5sum, err := src.Hash(hash.MD5)
+	if err != nil {
+		return nil, err
+	}
+	contents, ok := f.contents[md5sum]
+	if !ok {
+		return nil, fs.ErrorCantPutByHash
+	}
+	return f.Fs.Put(ctx, strings.NewReader(contents), src, options...)
+}
+
+func TestCopyPutByHash(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	if !r.Fremote.Hashes().Contains(hash.MD5) {
+		t.Skip("remote doesn't support MD5")
+	}
+	f := &putByHashFs{
+		Fs: r.Fremote,
+		contents: map[string]string{
+			"0ef726ce9b1a7692357ff70dd321d595": "file1 contents",
+		},
+	}
+	f.features = (&fs.Features{}).Fill(f)
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	file2 := r.WriteFile("file2", "file2 contents", t1)
+	for _, file := range []fstest.Item{file1, file2} {
+		src, err := r.Flocal.NewObject(ctx, file.Path)
+		require.NoError(t, err)
+		_, err = operations.Copy(ctx, f, nil, file.Path, src)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, f.puts, "only file2 should be uploaded")
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+}
+
+func TestCopyFileRetentionUnsupported(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().ObjectLock {
+		t.Skip("remote supports ObjectLock")
+	}
+	retentionPeriodBefore := fs.Config.RetentionPeriod
+	fs.Config.RetentionPeriod = time.Hour
+	defer func() { fs.Config.RetentionPeriod = retentionPeriodBefore }()
+
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Flocal, file1)
+
+	err := operations.CopyFile(context.Background(), r.Fremote, r.Flocal, file1.Path, file1.Path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "can't lock objects")
+	fstest.CheckItems(t, r.Fremote)
+}
+
+// conditionalFs makes an Fs which can do conditional updates, using
+// the size and modification time of the objects as their version
+type conditionalFs struct {
+	fs.Fs
+	features *fs.Features
+}
+
+func (f *conditionalFs) Features() *fs.Features {
+	return f.features
+}
+
+func (f *conditionalFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	o, err := f.Fs.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalObject{Object: o, f: f}, nil
+}
+
+// conditionalObject is an object in a conditionalFs
+type conditionalObject struct {
+	fs.Object
+	f *conditionalFs
+}
+
+func (o *conditionalObject) Fs() fs.Info {
+	return o.f
+}
+
+func (o *conditionalObject) VersionID() string {
+	return fmt.Sprintf("%d-%d", o.Size(), o.ModTime().UnixNano())
+}
+
+func (o *conditionalObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	var passOn []fs.OpenOption
+	for _, option := range options {
+		ifMatch, ok := option.(*fs.IfMatchOption)
+		if !ok {
+			passOn = append(passOn, option)
+			continue
+		}
+		current, err := o.f.NewObject(ctx, o.Remote())
+		if err != nil {
+			return err
+		}
+		if current.(*conditionalObject).VersionID() != ifMatch.VersionID {
+			return fs.ErrorDestinationChanged
+		}
+	}
+	return o.Object.Update(ctx, in, src, passOn...)
+}
+
+func TestCopyConditionalUpdate(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	f := r.Fremote
+	if !f.Features().ConditionalUpdate {
+		// Test with a remote which can do conditional updates
+		cf := &conditionalFs{Fs: r.Fremote}
+		cf.features = (&fs.Features{}).Fill(cf)
+		cf.features.ConditionalUpdate = true
+		f = cf
+	}
+	file1 := r.WriteObject("file1", "original contents", t1)
+	dst, err := f.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+
+	// the object is changed by someone else after it was checked
+	time.Sleep(time.Second)
+	file1b := r.WriteObject("file1", "newer contents", t3)
+
+	file2 := r.WriteFile("file1", "local contents", t2)
+	src, err := r.Flocal.NewObject(ctx, file2.Path)
+	require.NoError(t, err)
+	_, err = operations.Copy(ctx, f, dst, file2.Path, src)
+	require.Error(t, err)
+	assert.Equal(t, fs.ErrorDestinationChanged.Error(), err.Error())
+	assert.True(t, fserrors.IsNoRetryError(err), "shouldn't be retried")
+	fstest.CheckItems(t, r.Fremote, file1b)
+}
+
+func TestSetTierUnsupported(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().SetTier {
+		t.Skip("remote supports SetTier")
+	}
+	file1 := r.WriteObject("file1", "file1 contents", t1)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	err := operations.SetTier(context.Background(), r.Fremote, "COLD")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't support setting the storage tier")
+}
+
+func TestRestoreVersionsUnsupported(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().Versions {
+		t.Skip("remote supports old versions")
+	}
+	err := operations.RestoreVersions(context.Background(), r.Fremote, time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't support old versions")
+}
+
+func TestRestoreTrashUnsupported(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().Trash {
+		t.Skip("remote supports listing the trash")
+	}
+	err := operations.RestoreTrash(context.Background(), r.Fremote)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "doesn't support listing the trash")
+}
+
 // testFsInfo is for unit testing fs.Info
 type testFsInfo struct {
 	name      string
@@ -690,7 +1070,7 @@ func TestListFormat(t *testing.T) {
 
 	fstest.CheckItems(t, r.Fremote, file1, file2)
 
-	items, _ := list.DirSorted(r.Fremote, true, "")
+	items, _ := list.DirSorted(context.Background(), r.Fremote, true, "")
 	var list operations.ListFormat
 	list.AddPath()
 	list.SetDirSlash(false)
@@ -738,4 +1118,12 @@ func TestListFormat(t *testing.T) {
 			assert.Equal(t, test.want, got)
 		}
 	}
+
+	dir := fs.DirEntry(fs.NewDir("dir", t1).SetID("dirID").SetItems(3))
+	list.SetOutput(nil)
+	list.SetSeparator(";")
+	list.AddID()
+	list.AddItems()
+	assert.Equal(t, "dirID;3", operations.ListFormatted(&dir, &list))
+	assert.Equal(t, ";", operations.ListFormatted(&items[0], &list))
 }
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/pkg/errors"
@@ -194,6 +195,23 @@ func (o *HTTPOption) Mandatory() bool {
 	return false
 }
 
+// ParseHTTPOption parses a header in the form "Key: Value" into an
+// HTTPOption
+func ParseHTTPOption(s string) (*HTTPOption, error) {
+	i := strings.IndexRune(s, ':')
+	if i < 0 {
+		return nil, errors.Errorf("header %q must be in the form \"Key: Value\"", s)
+	}
+	key := strings.TrimSpace(s[:i])
+	if key == "" {
+		return nil, errors.Errorf("header %q has an empty key", s)
+	}
+	return &HTTPOption{
+		Key:   key,
+		Value: strings.TrimSpace(s[i+1:]),
+	}, nil
+}
+
 // HashesOption defines an option used to tell the local fs to limit
 // the number of hashes it calculates.
 type HashesOption struct {
@@ -215,6 +233,62 @@ func (o *HashesOption) Mandatory() bool {
 	return false
 }
 
+// Retention modes for RetentionOption
+const (
+	// RetentionGovernance objects can have their retention
+	// removed by users with special permissions
+	RetentionGovernance = "governance"
+	// RetentionCompliance objects can't be deleted or overwritten
+	// by anyone until the retention expires
+	RetentionCompliance = "compliance"
+)
+
+// RetentionOption defines an option used to lock an object against
+// being deleted or overwritten until RetainUntil when it is
+// uploaded.  Only remotes with the ObjectLock feature understand it.
+type RetentionOption struct {
+	Mode        string    // RetentionGovernance or RetentionCompliance
+	RetainUntil time.Time // the object can't be deleted before this
+}
+
+// Header formats the option as an http header
+func (o *RetentionOption) Header() (key string, value string) {
+	return "", ""
+}
+
+// String formats the option into human readable form
+func (o *RetentionOption) String() string {
+	return fmt.Sprintf("RetentionOption(%s,%s)", o.Mode, o.RetainUntil.Format(time.RFC3339))
+}
+
+// Mandatory returns whether the option must be parsed or can be ignored
+func (o *RetentionOption) Mandatory() bool {
+	return true
+}
+
+// IfMatchOption defines an option used to make an Update fail with
+// ErrorDestinationChanged unless the object on the remote is still
+// the version VersionID.  Only remotes with the ConditionalUpdate
+// feature understand it.
+type IfMatchOption struct {
+	VersionID string // from the VersionID of the object being replaced
+}
+
+// Header formats the option as an http header
+func (o *IfMatchOption) Header() (key string, value string) {
+	return "", ""
+}
+
+// String formats the option into human readable form
+func (o *IfMatchOption) String() string {
+	return fmt.Sprintf("IfMatchOption(%s)", o.VersionID)
+}
+
+// Mandatory returns whether the option must be parsed or can be ignored
+func (o *IfMatchOption) Mandatory() bool {
+	return true
+}
+
 // OpenOptionAddHeaders adds each header found in options to the
 // headers map provided the key was non empty.
 func OpenOptionAddHeaders(options []OpenOption, headers map[string]string) {
@@ -58,3 +58,29 @@ func TestRangeOptionDecode(t *testing.T) {
 		assert.Equal(t, test.wantLimit, gotLimit, "limit "+what)
 	}
 }
+
+func TestParseHTTPOption(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want HTTPOption
+		err  string
+	}{
+		{in: "", err: "must be in the form"},
+		{in: "X-Audit", err: "must be in the form"},
+		{in: " : value", err: "empty key"},
+		{in: "X-Audit: tag", want: HTTPOption{Key: "X-Audit", Value: "tag"}},
+		{in: "  X-Audit  :  tag  ", want: HTTPOption{Key: "X-Audit", Value: "tag"}},
+		{in: "X-Time: 12:34", want: HTTPOption{Key: "X-Time", Value: "12:34"}},
+		{in: "X-Empty:", want: HTTPOption{Key: "X-Empty", Value: ""}},
+	} {
+		got, err := ParseHTTPOption(test.in)
+		what := fmt.Sprintf("parsing %q", test.in)
+		if test.err != "" {
+			require.Contains(t, err.Error(), test.err)
+			require.Nil(t, got, what)
+		} else {
+			require.NoError(t, err, what)
+			assert.Equal(t, test.want, *got, what)
+		}
+	}
+}
@@ -129,7 +129,7 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fs.Debugf(nil, "rc: %q: with parameters %+v", path, in)
-	out, err := call.Fn(in)
+	out, err := call.Fn(r.Context(), in)
 	if err != nil {
 		writeError(errors.Wrap(err, "remote control command failed"), http.StatusInternalServerError)
 		return
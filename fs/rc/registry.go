@@ -3,6 +3,7 @@
 package rc
 
 import (
+	"context"
 	"sort"
 	"strings"
 	"sync"
@@ -14,7 +15,7 @@ import (
 type Params map[string]interface{}
 
 // Func defines a type for a remote control function
-type Func func(in Params) (out Params, err error)
+type Func func(ctx context.Context, in Params) (out Params, err error)
 
 // Call defines info about a remote control function and is used in
 // the Add function to create new entry points.
@@ -0,0 +1,481 @@
+// Enforce the read_only and write_only settings of remotes
+
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ConfigReadOnly is the config key to stop a remote being
+	// modified
+	ConfigReadOnly = "read_only"
+
+	// ConfigWriteOnly is the config key to stop the files on a remote
+	// being read
+	ConfigWriteOnly = "write_only"
+)
+
+// configBool reads the boolean config key for the remote called name.
+//
+// A value which can't be parsed is treated as true so that a typo
+// doesn't leave a remote unprotected.
+func configBool(name, key string) bool {
+	value := ConfigFileGet(name, key)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		Errorf(nil, "Treating %s = %q for remote %q as true: %v", key, value, name, err)
+		return true
+	}
+	return b
+}
+
+// Restrict returns f wrapped so that the read_only and write_only
+// settings of the remote called name are enforced, or f unchanged if
+// neither is set.
+//
+// NewFs does this, so it only needs calling by backends which make
+// the Fs for another remote without using NewFs.
+func Restrict(name string, f Fs) Fs {
+	if f == nil {
+		return nil
+	}
+	readOnly, writeOnly := configBool(name, ConfigReadOnly), configBool(name, ConfigWriteOnly)
+	if !readOnly && !writeOnly {
+		return f
+	}
+	r := &restrictedFs{
+		Fs:        f,
+		readOnly:  readOnly,
+		writeOnly: writeOnly,
+	}
+	features := *f.Features()
+	r.features = &features
+	if readOnly {
+		features.SetTier = false
+		features.Purge = nil
+		features.Copy = nil
+		features.Move = nil
+		features.DirMove = nil
+		features.DirSetModTime = nil
+		features.PutUnchecked = nil
+		features.PutStream = nil
+		features.PutByHash = nil
+		features.MergeDirs = nil
+		features.CleanUp = nil
+	}
+	// replace the features which return objects so they can be
+	// wrapped
+	if features.Copy != nil {
+		features.Copy = r.Copy
+	}
+	if features.Move != nil {
+		features.Move = r.Move
+	}
+	if features.DirMove != nil {
+		features.DirMove = r.DirMove
+	}
+	if features.PutUnchecked != nil {
+		features.PutUnchecked = r.PutUnchecked
+	}
+	if features.PutStream != nil {
+		features.PutStream = r.PutStream
+	}
+	if features.PutByHash != nil {
+		features.PutByHash = r.PutByHash
+	}
+	if features.ListR != nil {
+		features.ListR = r.ListR
+	}
+	features.UnWrap = r.UnWrap
+	return r
+}
+
+// restrictedFs is an Fs which can't be modified if readOnly is set
+// and whose files can't be read if writeOnly is set
+type restrictedFs struct {
+	Fs
+	features  *Features
+	readOnly  bool
+	writeOnly bool
+}
+
+// String returns a description of the FS
+func (r *restrictedFs) String() string {
+	switch {
+	case r.readOnly && r.writeOnly:
+		return fmt.Sprintf("%v (read and write only)", r.Fs)
+	case r.readOnly:
+		return fmt.Sprintf("%v (read only)", r.Fs)
+	}
+	return fmt.Sprintf("%v (write only)", r.Fs)
+}
+
+// Features returns the optional features of this Fs
+func (r *restrictedFs) Features() *Features {
+	return r.features
+}
+
+// UnWrap returns the Fs that this Fs is wrapping
+func (r *restrictedFs) UnWrap() Fs {
+	return r.Fs
+}
+
+// errorReadOnly returns ErrorReadOnly if the remote is read only
+func (r *restrictedFs) errorReadOnly() error {
+	if r.readOnly {
+		return ErrorReadOnly
+	}
+	return nil
+}
+
+// wrapEntries wraps the objects in entries
+func (r *restrictedFs) wrapEntries(entries DirEntries) {
+	for i, entry := range entries {
+		if o, ok := entry.(Object); ok {
+			entries[i] = r.newObject(o)
+		}
+	}
+}
+
+// List the objects and directories in dir into entries
+func (r *restrictedFs) List(ctx context.Context, dir string) (entries DirEntries, err error) {
+	entries, err = r.Fs.List(ctx, dir)
+	r.wrapEntries(entries)
+	return entries, err
+}
+
+// ListR lists the objects and directories of the Fs starting
+// from dir recursively into out
+func (r *restrictedFs) ListR(ctx context.Context, dir string, callback ListRCallback) error {
+	return r.Fs.Features().ListR(ctx, dir, func(entries DirEntries) error {
+		r.wrapEntries(entries)
+		return callback(entries)
+	})
+}
+
+// NewObject finds the Object at remote
+func (r *restrictedFs) NewObject(ctx context.Context, remote string) (Object, error) {
+	o, err := r.Fs.NewObject(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	return r.newObject(o), nil
+}
+
+// Put in to the remote path with the modTime given of the given size
+func (r *restrictedFs) Put(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error) {
+	if err := r.errorReadOnly(); err != nil {
+		return nil, err
+	}
+	o, err := r.Fs.Put(ctx, in, src, options...)
+	if o != nil {
+		o = r.newObject(o)
+	}
+	return o, err
+}
+
+// PutUnchecked uploads the object without checking for an existing
+// one
+func (r *restrictedFs) PutUnchecked(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error) {
+	o, err := r.Fs.Features().PutUnchecked(ctx, in, src, options...)
+	if o != nil {
+		o = r.newObject(o)
+	}
+	return o, err
+}
+
+// PutStream uploads to the remote path with the modTime given of
+// indeterminate size
+func (r *restrictedFs) PutStream(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error) {
+	o, err := r.Fs.Features().PutStream(ctx, in, src, options...)
+	if o != nil {
+		o = r.newObject(o)
+	}
+	return o, err
+}
+
+// PutByHash creates the object from content the remote already has
+func (r *restrictedFs) PutByHash(ctx context.Context, src ObjectInfo, options ...OpenOption) (Object, error) {
+	o, err := r.Fs.Features().PutByHash(ctx, src, options...)
+	if o != nil {
+		o = r.newObject(o)
+	}
+	return o, err
+}
+
+// Mkdir makes the directory (container, bucket)
+func (r *restrictedFs) Mkdir(ctx context.Context, dir string) error {
+	if err := r.errorReadOnly(); err != nil {
+		return err
+	}
+	return r.Fs.Mkdir(ctx, dir)
+}
+
+// Rmdir removes the directory (container, bucket) if empty
+func (r *restrictedFs) Rmdir(ctx context.Context, dir string) error {
+	if err := r.errorReadOnly(); err != nil {
+		return err
+	}
+	return r.Fs.Rmdir(ctx, dir)
+}
+
+// restricter is satisfied by all the restricted object types
+type restricter interface {
+	restricted() *restrictedObject
+}
+
+// unwrapObject returns the object restrictedFs wrapped to make o
+func unwrapObject(o Object) Object {
+	if ro, ok := o.(restricter); ok {
+		return ro.restricted().Object
+	}
+	return o
+}
+
+// Copy src to this remote using server side copy operations
+func (r *restrictedFs) Copy(ctx context.Context, src Object, remote string) (Object, error) {
+	o, err := r.Fs.Features().Copy(ctx, unwrapObject(src), remote)
+	if err != nil {
+		return nil, err
+	}
+	return r.newObject(o), nil
+}
+
+// Move src to this remote using server side move operations
+func (r *restrictedFs) Move(ctx context.Context, src Object, remote string) (Object, error) {
+	if ro, ok := src.(restricter); ok && ro.restricted().f.readOnly {
+		return nil, ErrorReadOnly
+	}
+	o, err := r.Fs.Features().Move(ctx, unwrapObject(src), remote)
+	if err != nil {
+		return nil, err
+	}
+	return r.newObject(o), nil
+}
+
+// DirMove moves src, srcRemote to this remote at dstRemote using
+// server side move operations
+func (r *restrictedFs) DirMove(ctx context.Context, src Fs, srcRemote, dstRemote string) error {
+	if srcFs, ok := src.(*restrictedFs); ok {
+		if srcFs.readOnly {
+			return ErrorReadOnly
+		}
+		src = srcFs.Fs
+	}
+	return r.Fs.Features().DirMove(ctx, src, srcRemote, dstRemote)
+}
+
+// restrictedObject is an Object on a restrictedFs
+//
+// It has the optional methods which return "" if they aren't
+// supported.  The ones callers check for support with a type
+// assertion are added by wrapping it in the types below.
+type restrictedObject struct {
+	Object
+	f *restrictedFs
+}
+
+// restrictedVersionObject is a restrictedObject with a
+// VersionRestorer
+type restrictedVersionObject struct {
+	*restrictedObject
+}
+
+// restrictedTrashObject is a restrictedObject with a TrashRestorer
+type restrictedTrashObject struct {
+	*restrictedObject
+}
+
+// restrictedVersionTrashObject is a restrictedObject with a
+// VersionRestorer and a TrashRestorer
+type restrictedVersionTrashObject struct {
+	*restrictedObject
+}
+
+// newObject wraps o with the optional interfaces it has
+func (r *restrictedFs) newObject(o Object) Object {
+	ro := &restrictedObject{
+		Object: o,
+		f:      r,
+	}
+	_, isVersion := o.(VersionRestorer)
+	_, isTrash := o.(TrashRestorer)
+	switch {
+	case isVersion && isTrash:
+		return restrictedVersionTrashObject{ro}
+	case isVersion:
+		return restrictedVersionObject{ro}
+	case isTrash:
+		return restrictedTrashObject{ro}
+	}
+	return ro
+}
+
+// restricted returns the restrictedObject
+func (o *restrictedObject) restricted() *restrictedObject {
+	return o
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *restrictedObject) Fs() Info {
+	return o.f
+}
+
+// Open opens the file for read unless the remote is write only
+func (o *restrictedObject) Open(ctx context.Context, options ...OpenOption) (io.ReadCloser, error) {
+	if o.f.writeOnly {
+		return nil, ErrorWriteOnly
+	}
+	return o.Object.Open(ctx, options...)
+}
+
+// SetModTime sets the modification time of the object
+func (o *restrictedObject) SetModTime(ctx context.Context, modTime time.Time) error {
+	if err := o.f.errorReadOnly(); err != nil {
+		return err
+	}
+	return o.Object.SetModTime(ctx, modTime)
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *restrictedObject) Update(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) error {
+	if err := o.f.errorReadOnly(); err != nil {
+		return err
+	}
+	return o.Object.Update(ctx, in, src, options...)
+}
+
+// SetMetadata applies the metadata to the object
+func (o *restrictedObject) SetMetadata(ctx context.Context, metadata Metadata) error {
+	if err := o.f.errorReadOnly(); err != nil {
+		return err
+	}
+	return SetMetadata(ctx, o.Object, metadata)
+}
+
+// Remove this object
+func (o *restrictedObject) Remove(ctx context.Context) error {
+	if err := o.f.errorReadOnly(); err != nil {
+		return err
+	}
+	return o.Object.Remove(ctx)
+}
+
+// UnWrap returns the wrapped Object
+func (o *restrictedObject) UnWrap() Object {
+	return o.Object
+}
+
+// MimeType returns the content type of the Object if known
+func (o *restrictedObject) MimeType() string {
+	if do, ok := o.Object.(MimeTyper); ok {
+		return do.MimeType()
+	}
+	return ""
+}
+
+// VersionID returns an ID of the version of the Object if known
+func (o *restrictedObject) VersionID() string {
+	if do, ok := o.Object.(VersionIDer); ok {
+		return do.VersionID()
+	}
+	return ""
+}
+
+// GetTier returns the storage tier of the Object if known
+func (o *restrictedObject) GetTier() string {
+	if do, ok := o.Object.(GetTierer); ok {
+		return do.GetTier()
+	}
+	return ""
+}
+
+// SetTier changes the storage tier of the Object
+func (o *restrictedObject) SetTier(ctx context.Context, tier string) error {
+	if err := o.f.errorReadOnly(); err != nil {
+		return err
+	}
+	do, ok := o.Object.(SetTierer)
+	if !ok {
+		return errors.New("object doesn't support setting the storage tier")
+	}
+	return do.SetTier(ctx, tier)
+}
+
+// versionTime returns the time the version of the Object was made
+func (o *restrictedObject) versionTime() time.Time {
+	return o.Object.(VersionRestorer).VersionTime()
+}
+
+// restoreVersion makes this version of the Object the current one
+func (o *restrictedObject) restoreVersion(ctx context.Context) error {
+	if err := o.f.errorReadOnly(); err != nil {
+		return err
+	}
+	return o.Object.(VersionRestorer).RestoreVersion(ctx)
+}
+
+// restoreTrash moves the Object out of the trash
+func (o *restrictedObject) restoreTrash(ctx context.Context) error {
+	if err := o.f.errorReadOnly(); err != nil {
+		return err
+	}
+	return o.Object.(TrashRestorer).RestoreTrash(ctx)
+}
+
+// VersionTime returns the time this version of the Object was made
+func (o restrictedVersionObject) VersionTime() time.Time {
+	return o.versionTime()
+}
+
+// RestoreVersion makes this version of the Object the current one
+func (o restrictedVersionObject) RestoreVersion(ctx context.Context) error {
+	return o.restoreVersion(ctx)
+}
+
+// RestoreTrash moves the Object out of the trash
+func (o restrictedTrashObject) RestoreTrash(ctx context.Context) error {
+	return o.restoreTrash(ctx)
+}
+
+// VersionTime returns the time this version of the Object was made
+func (o restrictedVersionTrashObject) VersionTime() time.Time {
+	return o.versionTime()
+}
+
+// RestoreVersion makes this version of the Object the current one
+func (o restrictedVersionTrashObject) RestoreVersion(ctx context.Context) error {
+	return o.restoreVersion(ctx)
+}
+
+// RestoreTrash moves the Object out of the trash
+func (o restrictedVersionTrashObject) RestoreTrash(ctx context.Context) error {
+	return o.restoreTrash(ctx)
+}
+
+// Check the interfaces are satisfied
+var (
+	_ Fs              = (*restrictedFs)(nil)
+	_ UnWrapper       = (*restrictedFs)(nil)
+	_ Object          = (*restrictedObject)(nil)
+	_ ObjectUnWrapper = (*restrictedObject)(nil)
+	_ SetMetadataer   = (*restrictedObject)(nil)
+	_ MimeTyper       = (*restrictedObject)(nil)
+	_ VersionIDer     = (*restrictedObject)(nil)
+	_ GetTierer       = (*restrictedObject)(nil)
+	_ SetTierer       = (*restrictedObject)(nil)
+	_ VersionRestorer = restrictedVersionObject{}
+	_ TrashRestorer   = restrictedTrashObject{}
+	_ VersionRestorer = restrictedVersionTrashObject{}
+	_ TrashRestorer   = restrictedVersionTrashObject{}
+)
@@ -0,0 +1,146 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restrictTestFs is an Fs with a single object which records what
+// is done to it - the methods not needed by the tests are left nil
+type restrictTestFs struct {
+	Fs
+	features Features
+	puts     int
+}
+
+func (f *restrictTestFs) Features() *Features {
+	return &f.features
+}
+
+func (f *restrictTestFs) NewObject(ctx context.Context, remote string) (Object, error) {
+	return &restrictTestObject{remote: remote}, nil
+}
+
+func (f *restrictTestFs) Put(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error) {
+	f.puts++
+	return &restrictTestObject{remote: src.Remote()}, nil
+}
+
+func (f *restrictTestFs) PutByHash(ctx context.Context, src ObjectInfo, options ...OpenOption) (Object, error) {
+	f.puts++
+	return &restrictTestObject{remote: src.Remote()}, nil
+}
+
+func (f *restrictTestFs) Purge(ctx context.Context) error {
+	return nil
+}
+
+type restrictTestObject struct {
+	Object
+	remote   string
+	removed  bool
+	restored bool
+}
+
+func (o *restrictTestObject) MimeType() string {
+	return "text/potato"
+}
+
+func (o *restrictTestObject) RestoreTrash(ctx context.Context) error {
+	o.restored = true
+	return nil
+}
+
+func (o *restrictTestObject) Remote() string {
+	return o.remote
+}
+
+func (o *restrictTestObject) Open(ctx context.Context, options ...OpenOption) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewBufferString("potato")), nil
+}
+
+func (o *restrictTestObject) Remove(ctx context.Context) error {
+	o.removed = true
+	return nil
+}
+
+func TestRestrict(t *testing.T) {
+	ctx := context.Background()
+	config := map[string]string{}
+	oldConfigFileGet := ConfigFileGet
+	defer func() {
+		ConfigFileGet = oldConfigFileGet
+	}()
+	ConfigFileGet = func(section, key string, defaultVal ...string) string {
+		return config[section+"."+key]
+	}
+	newFs := func() (*restrictTestFs, Fs) {
+		f := &restrictTestFs{}
+		f.features.Purge = f.Purge
+		f.features.PutByHash = f.PutByHash
+		return f, Restrict("remote", f)
+	}
+
+	// unchanged if not restricted
+	f, r := newFs()
+	assert.Equal(t, Fs(f), r)
+
+	// read only
+	config["remote.read_only"] = "true"
+	f, r = newFs()
+	_, err := r.Put(ctx, nil, &restrictTestObject{remote: "file"})
+	assert.Equal(t, ErrorReadOnly, err)
+	assert.Equal(t, 0, f.puts)
+	assert.Equal(t, ErrorReadOnly, r.Mkdir(ctx, "dir"))
+	assert.Equal(t, ErrorReadOnly, r.Rmdir(ctx, "dir"))
+	assert.Nil(t, r.Features().Purge)
+	assert.Nil(t, r.Features().PutByHash)
+	o, err := r.NewObject(ctx, "file")
+	require.NoError(t, err)
+	assert.Equal(t, r, o.Fs())
+	assert.Equal(t, ErrorReadOnly, o.Remove(ctx))
+	assert.False(t, o.(ObjectUnWrapper).UnWrap().(*restrictTestObject).removed)
+	in, err := o.Open(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, in.Close())
+	assert.Equal(t, Fs(f), r.Features().UnWrap())
+	assert.Equal(t, "text/potato", MimeType(o))
+	_, ok := o.(VersionRestorer)
+	assert.False(t, ok)
+	assert.Equal(t, ErrorReadOnly, o.(TrashRestorer).RestoreTrash(ctx))
+	assert.False(t, o.(ObjectUnWrapper).UnWrap().(*restrictTestObject).restored)
+
+	// write only
+	delete(config, "remote.read_only")
+	config["remote.write_only"] = "1"
+	f, r = newFs()
+	o, err = r.Put(ctx, nil, &restrictTestObject{remote: "file"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.puts)
+	_, err = o.Open(ctx)
+	assert.Equal(t, ErrorWriteOnly, err)
+	assert.NoError(t, o.Remove(ctx))
+	assert.NotNil(t, r.Features().Purge)
+	assert.NoError(t, o.(TrashRestorer).RestoreTrash(ctx))
+	assert.True(t, o.(ObjectUnWrapper).UnWrap().(*restrictTestObject).restored)
+	o, err = r.Features().PutByHash(ctx, &restrictTestObject{remote: "file2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.puts)
+	assert.Equal(t, r, o.Fs())
+	_, err = o.Open(ctx)
+	assert.Equal(t, ErrorWriteOnly, err)
+
+	// an unparsable value is treated as true
+	config["remote.write_only"] = "potato"
+	_, r = newFs()
+	o, err = r.NewObject(ctx, "file")
+	require.NoError(t, err)
+	_, err = o.Open(ctx)
+	assert.Equal(t, ErrorWriteOnly, err)
+}
@@ -0,0 +1,53 @@
+package sync
+
+import (
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// circuitBreaker counts the transfer failures of each path in a sync
+// so that objects which fail are retried after everything else has
+// been transferred, until they have failed too often.
+type circuitBreaker struct {
+	mu    sync.Mutex
+	count map[string]int
+}
+
+// newCircuitBreaker makes a new empty circuitBreaker
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		count: make(map[string]int),
+	}
+}
+
+// Fail records a failed transfer of remote returning the number of
+// failures so far
+func (c *circuitBreaker) Fail(remote string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count[remote]++
+	return c.count[remote]
+}
+
+// Succeed records a successful transfer of remote which closes the
+// circuit again, returning the number of times it failed before
+func (c *circuitBreaker) Succeed(remote string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	failures := c.count[remote]
+	delete(c.count, remote)
+	return failures
+}
+
+// Tripped returns true if remote has failed often enough not to be
+// tried again according to --transfer-failure-limit
+func (c *circuitBreaker) Tripped(remote string) bool {
+	limit := fs.Config.TransferFailureLimit
+	if limit <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count[remote] >= limit
+}
@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fstest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	oldLimit := fs.Config.TransferFailureLimit
+	defer func() {
+		fs.Config.TransferFailureLimit = oldLimit
+	}()
+	fs.Config.TransferFailureLimit = 2
+
+	c := newCircuitBreaker()
+	assert.False(t, c.Tripped("a"))
+	assert.Equal(t, 1, c.Fail("a"))
+	assert.False(t, c.Tripped("a"))
+	assert.Equal(t, 2, c.Fail("a"))
+	assert.True(t, c.Tripped("a"))
+	assert.False(t, c.Tripped("b"))
+
+	fs.Config.TransferFailureLimit = 0
+	assert.False(t, c.Tripped("a"))
+	fs.Config.TransferFailureLimit = 2
+
+	c.Succeed("a")
+	assert.False(t, c.Tripped("a"))
+}
+
+// rejectingFs is an Fs which rejects the uploads of some files a
+// number of times before accepting them
+type rejectingFs struct {
+	fs.Fs
+	features *fs.Features
+	mu       sync.Mutex
+	rejects  map[string]int // number of times left to reject each file
+	puts     []string       // the files uploaded in order
+}
+
+func newRejectingFs(f fs.Fs, rejects map[string]int) *rejectingFs {
+	features := *f.Features()
+	features.Copy = nil
+	features.Move = nil
+	features.PutByHash = nil
+	return &rejectingFs{
+		Fs:       f,
+		features: &features,
+		rejects:  rejects,
+	}
+}
+
+func (f *rejectingFs) Features() *fs.Features {
+	return f.features
+}
+
+func (f *rejectingFs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+	f.mu.Lock()
+	f.puts = append(f.puts, src.Remote())
+	reject := f.rejects[src.Remote()] > 0
+	if reject {
+		f.rejects[src.Remote()]--
+	}
+	f.mu.Unlock()
+	if reject {
+		return nil, errors.New("rejected")
+	}
+	return f.Fs.Put(ctx, in, src, options...)
+}
+
+func TestCopyCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	oldLimit, oldTransfers := fs.Config.TransferFailureLimit, fs.Config.Transfers
+	defer func() {
+		fs.Config.TransferFailureLimit, fs.Config.Transfers = oldLimit, oldTransfers
+	}()
+	fs.Config.TransferFailureLimit = 2
+	fs.Config.Transfers = 1
+	bad := r.WriteFile("bad", "bad", t1)
+	good1 := r.WriteFile("good1", "good1", t1)
+	good2 := r.WriteFile("good2", "good2", t1)
+	r.Mkdir(r.Fremote)
+
+	// A file which fails once is tried again after the others and
+	// the failure isn't left counted as an error
+	accounting.Stats.ResetCounters()
+	fdst := newRejectingFs(r.Fremote, map[string]int{"bad": 1})
+	require.NoError(t, CopyDir(ctx, fdst, r.Flocal, false))
+	assert.Equal(t, 4, len(fdst.puts))
+	assert.Equal(t, "bad", fdst.puts[3])
+	assert.False(t, accounting.Stats.Errored())
+	fstest.CheckItems(t, r.Fremote, bad, good1, good2)
+
+	// so a sync still deletes the extra files
+	extra := r.WriteObject("extra", "extra", t1)
+	fstest.CheckItems(t, r.Fremote, bad, extra, good1, good2)
+	o, err := r.Fremote.NewObject(ctx, "bad")
+	require.NoError(t, err)
+	require.NoError(t, operations.DeleteFile(ctx, o))
+	accounting.Stats.ResetCounters()
+	fdst = newRejectingFs(r.Fremote, map[string]int{"bad": 1})
+	require.NoError(t, Sync(ctx, fdst, r.Flocal, false))
+	assert.False(t, accounting.Stats.Errored())
+	fstest.CheckItems(t, r.Fremote, bad, good1, good2)
+
+	// A file which keeps failing trips the breaker and isn't tried
+	// again, but the others are copied
+	require.NoError(t, operations.Purge(ctx, r.Fremote, ""))
+	r.Mkdir(r.Fremote)
+	fdst = newRejectingFs(r.Fremote, map[string]int{"bad": 100})
+	err = CopyDir(ctx, fdst, r.Flocal, false)
+	require.Error(t, err)
+	assert.Equal(t, "rejected", err.Error())
+	assert.Equal(t, 4, len(fdst.puts))
+	fstest.CheckItems(t, r.Fremote, good1, good2)
+}
@@ -0,0 +1,155 @@
+package sync
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/fspath"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
+)
+
+// oldSuffix is added to the destination to make the name it is moved
+// to while the staging directory is moved into its place
+const oldSuffix = ".rclone-old"
+
+// SyncStaged makes the directory dst identical to fsrc like Sync but
+// syncs into the directory staging first, then swaps it with dst using
+// server side directory moves.  This means dst is only inconsistent
+// for the moment it takes to swap the directories.
+//
+// dst and staging are remote:path strings which must be on the same
+// remote, which must be able to move directories server side.  If the
+// remote can copy server side, staging is filled with a copy of dst
+// first so only the changed files are uploaded.
+//
+// The old contents of dst are moved to dst+".rclone-old" during the
+// swap and purged afterwards.
+func SyncStaged(ctx context.Context, fsrc fs.Fs, dst, staging string, copyEmptySrcDirs bool) error {
+	if !filter.Active.InActive() {
+		return errors.New("can't use filters with a staging directory as excluded files would be lost from the destination")
+	}
+	dst = strings.TrimRight(dst, "/")
+	if _, leaf := fspath.RemoteSplit(dst); leaf == "" {
+		return errors.Errorf("can't swap %q as it is the root of a remote", dst)
+	}
+	old := dst + oldSuffix
+	fdst, err := fs.NewFs(dst)
+	if err != nil {
+		return errors.Wrap(err, "failed to make destination")
+	}
+	fstaging, err := fs.NewFs(staging)
+	if err != nil {
+		return errors.Wrap(err, "failed to make staging directory")
+	}
+	if fdst.Features().DirMove == nil {
+		return errors.Errorf("%v can't move directories server side so can't use a staging directory", fdst)
+	}
+	if !operations.SameConfig(fdst, fstaging) {
+		return errors.New("the staging directory must be on the same remote as the destination")
+	}
+	if operations.Overlapping(fdst, fstaging) {
+		return errors.New("the staging directory can't overlap the destination")
+	}
+	if fs.Config.DryRun {
+		fs.Logf(fdst, "Not using staging directory as --dry-run")
+		return Sync(ctx, fdst, fsrc, copyEmptySrcDirs)
+	}
+	fold, err := fs.NewFs(old)
+	if err != nil {
+		return errors.Wrap(err, "failed to make old destination")
+	}
+	if operations.Overlapping(fold, fstaging) {
+		return errors.Errorf("the staging directory can't overlap %q", old)
+	}
+	oldExists, err := dirExists(ctx, fold)
+	if err != nil {
+		return err
+	}
+	if oldExists {
+		return errors.Errorf("%q exists, probably from a failed swap - check and remove it", old)
+	}
+	dstExists, err := dirExists(ctx, fdst)
+	if err != nil {
+		return err
+	}
+
+	// Seed the staging directory so only changed files are uploaded
+	if dstExists && fdst.Features().Copy != nil {
+		fs.Infof(fstaging, "Copying destination into staging directory server side")
+		err = CopyDir(ctx, fstaging, fdst, copyEmptySrcDirs)
+		if err != nil {
+			return errors.Wrap(err, "failed to copy destination into staging directory")
+		}
+	}
+
+	err = Sync(ctx, fstaging, fsrc, copyEmptySrcDirs)
+	if err != nil {
+		return errors.Wrap(err, "failed to sync to staging directory")
+	}
+	return swapDirs(ctx, dst, staging, old, dstExists)
+}
+
+// dirExists returns whether the root of f exists
+func dirExists(ctx context.Context, f fs.Fs) (bool, error) {
+	_, err := f.List(ctx, "")
+	if err == fs.ErrorDirNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to list %v", f)
+	}
+	return true, nil
+}
+
+// swapDirs moves the directory staging to dst, moving dst out of the
+// way to old first if it exists and purging it afterwards.
+func swapDirs(ctx context.Context, dst, staging, old string, dstExists bool) error {
+	if dstExists {
+		err := dirMove(ctx, dst, old)
+		if err != nil {
+			return errors.Wrap(err, "failed to move destination out of the way")
+		}
+	}
+	err := dirMove(ctx, staging, dst)
+	if err != nil {
+		if dstExists {
+			undoErr := dirMove(ctx, old, dst)
+			if undoErr != nil {
+				fs.Errorf(nil, "Failed to move %q back to %q: %v", old, dst, undoErr)
+			}
+		}
+		return errors.Wrap(err, "failed to move staging directory into place")
+	}
+	fs.Infof(nil, "Swapped %q into %q", staging, dst)
+	if !dstExists {
+		return nil
+	}
+	fold, err := fs.NewFs(old)
+	if err != nil {
+		return errors.Wrap(err, "failed to make old destination")
+	}
+	err = operations.Purge(ctx, fold, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to remove old destination %q", old)
+	}
+	return nil
+}
+
+// dirMove moves the directory src to dst server side
+//
+// New Fs are made for each move as backends may cache the IDs of
+// their roots.
+func dirMove(ctx context.Context, src, dst string) error {
+	fsrc, err := fs.NewFs(src)
+	if err != nil {
+		return err
+	}
+	fdst, err := fs.NewFs(dst)
+	if err != nil {
+		return err
+	}
+	return fdst.Features().DirMove(ctx, fsrc, "", "")
+}
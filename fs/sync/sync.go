@@ -4,8 +4,10 @@ package sync
 import (
 	"context"
 	"fmt"
+	"path"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
@@ -24,6 +26,7 @@ type syncCopyMove struct {
 	deleteMode         fs.DeleteMode // how we are doing deletions
 	DoMove             bool
 	deleteEmptySrcDirs bool
+	copyEmptySrcDirs   bool
 	dir                string
 	// internal state
 	ctx            context.Context        // internal context for controlling go-routines
@@ -38,13 +41,16 @@ type syncCopyMove struct {
 	srcFilesResult chan error             // error result of src listing
 	dstFilesResult chan error             // error result of dst listing
 	dstEmptyDirsMu sync.Mutex             // protect dstEmptyDirs
-	dstEmptyDirs   []fs.DirEntry          // potentially empty directories
+	dstEmptyDirs   map[string]fs.DirEntry // potentially empty directories
 	srcEmptyDirsMu sync.Mutex             // protect srcEmptyDirs
-	srcEmptyDirs   []fs.DirEntry          // potentially empty directories
+	srcEmptyDirs   map[string]fs.DirEntry // potentially empty directories
 	checkerWg      sync.WaitGroup         // wait for checkers
 	toBeChecked    fs.ObjectPairChan      // checkers channel
 	transfersWg    sync.WaitGroup         // wait for transfers
 	toBeUploaded   fs.ObjectPairChan      // copiers channel
+	failures       *circuitBreaker        // counts the failed transfers of each file
+	parkedMu       sync.Mutex             // protect parked
+	parked         []fs.ObjectPair        // failed transfers to try again at the end
 	errorMu        sync.Mutex             // Mutex covering the errors variables
 	err            error                  // normal error from copy process
 	noRetryErr     error                  // error with NoRetry set
@@ -59,19 +65,30 @@ type syncCopyMove struct {
 	renameCheck    []fs.Object            // accumulate files to check for rename here
 	backupDir      fs.Fs                  // place to store overwrites/deletes
 	suffix         string                 // suffix to add to files placed in backupDir
+	deferDeletes   bool                   // defer deleting moved sources until the run is verified
+	toBeVerifiedMu sync.Mutex             // protect toBeVerified
+	toBeVerified   []fs.Object            // moved sources to verify and delete at the end
+	limitDeletes   bool                   // count the deletes before doing any for --max-delete etc
+	dstObjects     int64                  // number of objects seen in fdst - use atomic
+	notQueued      int64                  // number of transfers not queued because of --max-files - use atomic
+	destTemplate   *destTemplate          // makes the destination paths if --dest-template
+	moved          MovedFn                // called with each verified move if set
 }
 
-func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool) (*syncCopyMove, error) {
+func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) (*syncCopyMove, error) {
 	s := &syncCopyMove{
 		fdst:               fdst,
 		fsrc:               fsrc,
 		deleteMode:         deleteMode,
 		DoMove:             DoMove,
 		deleteEmptySrcDirs: deleteEmptySrcDirs,
+		copyEmptySrcDirs:   copyEmptySrcDirs,
 		dir:                "",
 		srcFilesChan:       make(chan fs.Object, fs.Config.Checkers+fs.Config.Transfers),
 		srcFilesResult:     make(chan error, 1),
 		dstFilesResult:     make(chan error, 1),
+		dstEmptyDirs:       make(map[string]fs.DirEntry),
+		srcEmptyDirs:       make(map[string]fs.DirEntry),
 		toBeChecked:        make(fs.ObjectPairChan, fs.Config.Transfers),
 		toBeUploaded:       make(fs.ObjectPairChan, fs.Config.Transfers),
 		deleteFilesCh:      make(chan fs.Object, fs.Config.Checkers),
@@ -79,8 +96,11 @@ func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 		commonHash:         fsrc.Hashes().Overlap(fdst.Hashes()).GetOne(),
 		toBeRenamed:        make(fs.ObjectPairChan, fs.Config.Transfers),
 		trackRenamesCh:     make(chan fs.Object, fs.Config.Checkers),
+		deferDeletes:       DoMove && fs.Config.DeleteAfterVerify,
+		limitDeletes:       fs.Config.MaxDelete >= 0 || fs.Config.MaxDeletePercent >= 0 || fs.Config.MaxSizeDelete >= 0,
+		failures:           newCircuitBreaker(),
 	}
-	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.ctx, s.cancel = context.WithCancel(ctx)
 	if s.trackRenames {
 		// Don't track renames for remotes without server-side move support.
 		if !operations.CanServerSideMove(fdst) {
@@ -96,12 +116,45 @@ func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 			s.trackRenames = false
 		}
 	}
+	if strategy := operations.CompareStrategyFor(fsrc, fdst); fs.Config.CheckSum && strategy != operations.CompareCheckSum {
+		fs.Logf(fdst, "Ignoring --checksum as the source and destination do not have a common hash - comparing using %v", strategy)
+	} else {
+		fs.Debugf(fdst, "Comparing files using %v", strategy)
+	}
+	if fs.Config.NoTraverse && filter.Active.Files() == nil {
+		fs.Logf(fdst, "Ignoring --no-traverse as it only works with --files-from")
+	}
+	if fs.Config.DestTemplate != "" {
+		if s.deleteMode != fs.DeleteModeOff {
+			return nil, fserrors.FatalError(errors.New("can't use --dest-template with sync, only copy or move"))
+		}
+		var err error
+		s.destTemplate, err = newDestTemplate(fs.Config.DestTemplate)
+		if err != nil {
+			return nil, fserrors.FatalError(err)
+		}
+		if s.copyEmptySrcDirs {
+			fs.Logf(fdst, "Ignoring --create-empty-src-dirs as it doesn't work with --dest-template")
+			s.copyEmptySrcDirs = false
+		}
+	}
+	if s.copyEmptySrcDirs && !fdst.Features().CanHaveEmptyDirectories {
+		fs.Debugf(fdst, "Ignoring --create-empty-src-dirs as the destination can't have empty directories")
+		s.copyEmptySrcDirs = false
+	}
 	if s.trackRenames {
 		// track renames needs delete after
 		if s.deleteMode != fs.DeleteModeOff {
 			s.deleteMode = fs.DeleteModeAfter
 		}
 	}
+	if s.limitDeletes {
+		// the deletes need counting before any are done so
+		// collect them rather than deleting during
+		if s.deleteMode == fs.DeleteModeDuring {
+			s.deleteMode = fs.DeleteModeAfter
+		}
+	}
 	// Make Fs for --backup-dir if required
 	if fs.Config.BackupDir != "" {
 		var err error
@@ -208,17 +261,19 @@ func (s *syncCopyMove) pairChecker(in fs.ObjectPairChan, out fs.ObjectPairChan,
 			accounting.Stats.Checking(src.Remote())
 			// Check to see if can store this
 			if src.Storable() {
-				if operations.NeedTransfer(pair.Dst, pair.Src) {
+				if operations.NeedTransfer(s.ctx, pair.Dst, pair.Src) {
 					// If files are treated as immutable, fail if destination exists and does not match
 					if fs.Config.Immutable && pair.Dst != nil {
 						fs.Errorf(pair.Dst, "Source and destination exist but do not match: immutable file modified")
 						s.processError(fs.ErrorImmutableModified)
+					} else if !s.reserveTransfer(src) {
+						// leave it for the next run
 					} else {
 						// If destination already exists, then we must move it into --backup-dir if required
 						if pair.Dst != nil && s.backupDir != nil {
 							remoteWithSuffix := pair.Dst.Remote() + s.suffix
-							overwritten, _ := s.backupDir.NewObject(remoteWithSuffix)
-							_, err := operations.Move(s.backupDir, overwritten, remoteWithSuffix, pair.Dst)
+							overwritten, _ := s.backupDir.NewObject(s.ctx, remoteWithSuffix)
+							_, err := operations.Move(s.ctx, s.backupDir, overwritten, remoteWithSuffix, pair.Dst)
 							if err != nil {
 								s.processError(err)
 							} else {
@@ -232,9 +287,11 @@ func (s *syncCopyMove) pairChecker(in fs.ObjectPairChan, out fs.ObjectPairChan,
 					}
 				} else {
 					// If moving need to delete the files we don't need to copy
-					if s.DoMove {
+					if s.deferDeletes {
+						s.deferDelete(src)
+					} else if s.DoMove {
 						// Delete src if no error on copy
-						s.processError(operations.DeleteFile(src))
+						s.processError(operations.DeleteFile(s.ctx, src))
 					}
 				}
 			}
@@ -245,6 +302,121 @@ func (s *syncCopyMove) pairChecker(in fs.ObjectPairChan, out fs.ObjectPairChan,
 	}
 }
 
+// reserveTransfer returns whether src can be queued for transfer,
+// or false if --max-files transfers have been queued already.  The
+// files not queued are counted to report at the end.
+func (s *syncCopyMove) reserveTransfer(src fs.Object) bool {
+	if accounting.Stats.QueueTransfer() {
+		return true
+	}
+	atomic.AddInt64(&s.notQueued, 1)
+	fs.Debugf(src, "Not transferring as --max-files %d reached", fs.Config.MaxFiles)
+	return false
+}
+
+// deferDelete records src to be deleted by verifyAndDeleteSources
+// at the end of the run
+func (s *syncCopyMove) deferDelete(src fs.Object) {
+	s.toBeVerifiedMu.Lock()
+	s.toBeVerified = append(s.toBeVerified, src)
+	s.toBeVerifiedMu.Unlock()
+}
+
+// verifyMoved checks src has been transferred to the destination
+// intact by reading the destination object again and comparing its
+// size and hash with src.  It returns the destination object.
+func (s *syncCopyMove) verifyMoved(src fs.Object) (fs.Object, error) {
+	remote, err := s.dstRemote(src)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := s.fdst.NewObject(s.ctx, remote)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to find destination")
+	}
+	if !fs.Config.IgnoreSize && src.Size() >= 0 && dst.Size() >= 0 && src.Size() != dst.Size() {
+		return nil, errors.Errorf("sizes differ %d vs %d", src.Size(), dst.Size())
+	}
+	if !fs.Config.IgnoreChecksum {
+		equal, ht, err := operations.CheckHashes(src, dst)
+		if err != nil {
+			return nil, err
+		}
+		if !equal {
+			return nil, errors.Errorf("%v hashes differ", ht)
+		}
+	}
+	return dst, nil
+}
+
+// verifyAndDeleteSources checks that every source deferred with
+// --delete-after-verify is on the destination and only if they all
+// are deletes them.
+//
+// Nothing is deleted if there were any errors during the run.  If
+// s.moved is set it is called with each file once they are all
+// verified, before any are deleted.
+func (s *syncCopyMove) verifyAndDeleteSources() error {
+	if len(s.toBeVerified) == 0 {
+		return nil
+	}
+	if s.currentError() != nil && !fs.Config.IgnoreErrors {
+		fs.Errorf(s.fsrc, "Not deleting sources as there were errors")
+		return nil
+	}
+	if !fs.Config.DryRun {
+		fs.Infof(s.fsrc, "Verifying %d files before deleting sources", len(s.toBeVerified))
+		var (
+			wg       sync.WaitGroup
+			failed   int32
+			toVerify = make(chan int, fs.Config.Checkers)
+			dsts     = make([]fs.Object, len(s.toBeVerified))
+		)
+		wg.Add(fs.Config.Checkers)
+		for i := 0; i < fs.Config.Checkers; i++ {
+			go func() {
+				defer wg.Done()
+				for i := range toVerify {
+					src := s.toBeVerified[i]
+					accounting.Stats.Checking(src.Remote())
+					dst, err := s.verifyMoved(src)
+					if err != nil {
+						atomic.AddInt32(&failed, 1)
+						fs.CountError(err)
+						fs.Errorf(src, "Failed to verify: %v", err)
+					}
+					dsts[i] = dst
+					accounting.Stats.DoneChecking(src.Remote())
+				}
+			}()
+		}
+		for i := range s.toBeVerified {
+			toVerify <- i
+		}
+		close(toVerify)
+		wg.Wait()
+		if failed > 0 {
+			return errors.Errorf("not deleting any sources as %d files failed verification", failed)
+		}
+		if s.moved != nil {
+			for i, src := range s.toBeVerified {
+				err := s.moved(src, dsts[i])
+				if err != nil {
+					return errors.Wrap(err, "not deleting any sources")
+				}
+			}
+		}
+	}
+	toBeDeleted := make(fs.ObjectsChan, fs.Config.Transfers)
+	go func() {
+		for _, src := range s.toBeVerified {
+			toBeDeleted <- src
+		}
+		close(toBeDeleted)
+	}()
+	return operations.DeleteFiles(s.ctx, toBeDeleted)
+}
+
 // pairRenamer reads Objects~s on in and attempts to rename them,
 // otherwise it sends them out if they need transferring.
 func (s *syncCopyMove) pairRenamer(in fs.ObjectPairChan, out fs.ObjectPairChan, wg *sync.WaitGroup) {
@@ -259,7 +431,7 @@ func (s *syncCopyMove) pairRenamer(in fs.ObjectPairChan, out fs.ObjectPairChan,
 				return
 			}
 			src := pair.Src
-			if !s.tryRename(src) {
+			if !s.tryRename(src) && s.reserveTransfer(src) {
 				// pass on if not renamed
 				out <- pair
 			}
@@ -272,7 +444,6 @@ func (s *syncCopyMove) pairRenamer(in fs.ObjectPairChan, out fs.ObjectPairChan,
 // pairCopyOrMove reads Objects on in and moves or copies them.
 func (s *syncCopyMove) pairCopyOrMove(in fs.ObjectPairChan, fdst fs.Fs, wg *sync.WaitGroup) {
 	defer wg.Done()
-	var err error
 	for {
 		if s.aborting() {
 			return
@@ -282,21 +453,85 @@ func (s *syncCopyMove) pairCopyOrMove(in fs.ObjectPairChan, fdst fs.Fs, wg *sync
 			if !ok {
 				return
 			}
-			src := pair.Src
-			accounting.Stats.Transferring(src.Remote())
-			if s.DoMove {
-				_, err = operations.Move(fdst, pair.Dst, src.Remote(), src)
-			} else {
-				_, err = operations.Copy(fdst, pair.Dst, src.Remote(), src)
-			}
-			s.processError(err)
-			accounting.Stats.DoneTransferring(src.Remote(), err == nil)
+			s.copyOrMove(pair, fdst)
 		case <-s.ctx.Done():
 			return
 		}
 	}
 }
 
+// copyOrMove moves or copies a single pair recording the result in
+// the circuit breaker.
+//
+// If the transfer fails and the circuit breaker for the file hasn't
+// tripped then the pair is parked to be tried again by
+// transferParked once everything else is done.
+func (s *syncCopyMove) copyOrMove(pair fs.ObjectPair, fdst fs.Fs) {
+	src := pair.Src
+	accounting.Stats.Transferring(src.Remote())
+	remote, err := s.dstRemote(src)
+	switch {
+	case err != nil:
+		// made when it was queued so this shouldn't happen
+	case s.DoMove && !s.deferDeletes:
+		_, err = operations.Move(s.ctx, fdst, pair.Dst, remote, src)
+	default:
+		_, err = operations.Copy(s.ctx, fdst, pair.Dst, remote, src)
+		if err == nil && s.deferDeletes {
+			s.deferDelete(src)
+		}
+	}
+	accounting.Stats.DoneTransferring(src.Remote(), err == nil)
+	if err != nil {
+		s.failures.Fail(src.Remote())
+		if s.park(pair, err) {
+			return
+		}
+	} else if failures := s.failures.Succeed(src.Remote()); failures > 0 {
+		// The failed transfers were all parked and counted as
+		// errors, so uncount them now the file is transferred
+		accounting.Stats.Errors(-int64(failures))
+	}
+	s.processError(err)
+}
+
+// park parks pair to be transferred again at the end after it failed
+// with err, returning false if it shouldn't be tried again.
+func (s *syncCopyMove) park(pair fs.ObjectPair, err error) bool {
+	if fs.Config.TransferFailureLimit <= 0 || s.failures.Tripped(pair.Src.Remote()) {
+		return false
+	}
+	if fserrors.IsFatalError(err) || fserrors.IsNoRetryError(err) {
+		return false
+	}
+	fs.Infof(pair.Src, "Will try the transfer again once the other files are done")
+	s.parkedMu.Lock()
+	s.parked = append(s.parked, pair)
+	s.parkedMu.Unlock()
+	return true
+}
+
+// transferParked transfers the objects parked after failing one at
+// a time until they succeed or their circuit breakers trip.
+func (s *syncCopyMove) transferParked() {
+	for {
+		s.parkedMu.Lock()
+		parked := s.parked
+		s.parked = nil
+		s.parkedMu.Unlock()
+		if len(parked) == 0 {
+			return
+		}
+		fs.Infof(s.fdst, "Trying %d failed transfers again", len(parked))
+		for _, pair := range parked {
+			if s.aborting() {
+				return
+			}
+			s.copyOrMove(pair, s.fdst)
+		}
+	}
+}
+
 // This starts the background checkers.
 func (s *syncCopyMove) startCheckers() {
 	s.checkerWg.Add(fs.Config.Checkers)
@@ -371,6 +606,12 @@ func (s *syncCopyMove) stopTrackRenames() {
 	s.trackRenamesWg.Wait()
 }
 
+// deletesCollected returns true if the files to delete are collected
+// in dstFiles and deleted at the end rather than as they are found
+func (s *syncCopyMove) deletesCollected() bool {
+	return s.deleteMode == fs.DeleteModeAfter || (s.deleteMode == fs.DeleteModeOnly && s.limitDeletes)
+}
+
 // This starts the background deletion of files for --delete-during
 func (s *syncCopyMove) startDeleters() {
 	if s.deleteMode != fs.DeleteModeDuring && s.deleteMode != fs.DeleteModeOnly {
@@ -379,7 +620,7 @@ func (s *syncCopyMove) startDeleters() {
 	s.deletersWg.Add(1)
 	go func() {
 		defer s.deletersWg.Done()
-		err := operations.DeleteFilesWithBackupDir(s.deleteFilesCh, s.backupDir)
+		err := operations.DeleteFilesWithBackupDir(s.ctx, s.deleteFilesCh, s.backupDir)
 		s.processError(err)
 	}()
 }
@@ -404,6 +645,14 @@ func (s *syncCopyMove) deleteFiles(checkSrcMap bool) error {
 		return fs.ErrorNotDeleting
 	}
 
+	if s.limitDeletes {
+		err := s.checkDeleteLimits(checkSrcMap)
+		if err != nil {
+			fs.Errorf(s.fdst, "%v", err)
+			return err
+		}
+	}
+
 	// Delete the spare files
 	toDelete := make(fs.ObjectsChan, fs.Config.Transfers)
 	go func() {
@@ -421,13 +670,31 @@ func (s *syncCopyMove) deleteFiles(checkSrcMap bool) error {
 		}
 		close(toDelete)
 	}()
-	return operations.DeleteFilesWithBackupDir(toDelete, s.backupDir)
+	return operations.DeleteFilesWithBackupDir(s.ctx, toDelete, s.backupDir)
+}
+
+// checkDeleteLimits checks the files deleteFiles would delete against
+// --max-delete and --max-size-delete before any are deleted
+func (s *syncCopyMove) checkDeleteLimits(checkSrcMap bool) error {
+	var count, size int64
+	for remote, o := range s.dstFiles {
+		if checkSrcMap {
+			if _, exists := s.srcFiles[remote]; exists {
+				continue
+			}
+		}
+		count++
+		if o.Size() > 0 {
+			size += o.Size()
+		}
+	}
+	return operations.CheckDeleteLimits(count, size, atomic.LoadInt64(&s.dstObjects))
 }
 
 // This deletes the empty directories in the slice passed in.  It
 // ignores any errors deleting directories
-func deleteEmptyDirectories(f fs.Fs, entries fs.DirEntries) error {
-	if len(entries) == 0 {
+func deleteEmptyDirectories(ctx context.Context, f fs.Fs, entriesMap map[string]fs.DirEntry) error {
+	if len(entriesMap) == 0 {
 		return nil
 	}
 	if accounting.Stats.Errored() && !fs.Config.IgnoreErrors {
@@ -435,6 +702,10 @@ func deleteEmptyDirectories(f fs.Fs, entries fs.DirEntries) error {
 		return fs.ErrorNotDeletingDirs
 	}
 
+	var entries fs.DirEntries
+	for _, entry := range entriesMap {
+		entries = append(entries, entry)
+	}
 	// Now delete the empty directories starting from the longest path
 	sort.Sort(entries)
 	var errorCount int
@@ -444,7 +715,7 @@ func deleteEmptyDirectories(f fs.Fs, entries fs.DirEntries) error {
 		dir, ok := entry.(fs.Directory)
 		if ok {
 			// TryRmdir only deletes empty directories
-			err := operations.TryRmdir(f, dir.Remote())
+			err := operations.TryRmdir(ctx, f, dir.Remote())
 			if err != nil {
 				fs.Debugf(fs.LogDirName(f, dir.Remote()), "Failed to Rmdir: %v", err)
 				errorCount++
@@ -464,6 +735,56 @@ func deleteEmptyDirectories(f fs.Fs, entries fs.DirEntries) error {
 	return nil
 }
 
+// This creates the empty directories in the map passed in on f.  It
+// logs any errors creating the directories but doesn't return them.
+func copyEmptyDirectories(ctx context.Context, f fs.Fs, entries map[string]fs.DirEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var errorCount int
+	var okCount int
+	for _, entry := range entries {
+		dir, ok := entry.(fs.Directory)
+		if ok {
+			err := operations.Mkdir(ctx, f, dir.Remote())
+			if err != nil {
+				fs.Errorf(fs.LogDirName(f, dir.Remote()), "Failed to Mkdir: %v", err)
+				errorCount++
+			} else {
+				okCount++
+			}
+		} else {
+			fs.Errorf(f, "Not a directory: %v", entry)
+		}
+	}
+	if errorCount > 0 {
+		fs.Debugf(f, "failed to copy %d directories", errorCount)
+	}
+	if okCount > 0 {
+		fs.Debugf(f, "copied %d directories", okCount)
+	}
+	return nil
+}
+
+// srcParentDirCheck removes the parent directory of entry from
+// srcEmptyDirs as it can't be empty.
+//
+// Call with srcEmptyDirsMu held
+func (s *syncCopyMove) srcParentDirCheck(entry fs.DirEntry) {
+	// If we are moving files then we don't want to remove
+	// directories with files in them from srcEmptyDirs as we are
+	// about to move the files making the directory empty.
+	if s.DoMove {
+		return
+	}
+	parentDir := path.Dir(entry.Remote())
+	if parentDir == "." {
+		parentDir = ""
+	}
+	delete(s.srcEmptyDirs, parentDir)
+}
+
 // renameHash makes a string with the size and the hash for rename detection
 //
 // it may return an empty string in which case no hash could be made
@@ -562,10 +883,10 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 	}
 
 	// Find dst object we are about to overwrite if it exists
-	dstOverwritten, _ := s.fdst.NewObject(src.Remote())
+	dstOverwritten, _ := s.fdst.NewObject(s.ctx, src.Remote())
 
 	// Rename dst to have name src.Remote()
-	_, err := operations.Move(s.fdst, dstOverwritten, src.Remote(), dst)
+	_, err := operations.Move(s.ctx, s.fdst, dstOverwritten, src.Remote(), dst)
 	if err != nil {
 		fs.Debugf(src, "Failed to rename to %q: %v", dst.Remote(), err)
 		return false
@@ -602,9 +923,17 @@ func (s *syncCopyMove) run() error {
 
 	s.startTrackRenames()
 
-	// set up a march over fdst and fsrc
-	m := march.New(s.ctx, s.fdst, s.fsrc, s.dir, s)
-	m.Run()
+	if s.destTemplate != nil {
+		// look up the destination of each source file
+		s.runDestTemplate()
+	} else if fs.Config.NoTraverse && filter.Active.Files() != nil {
+		// look up the --files-from files without listing
+		s.runFilesFrom()
+	} else {
+		// set up a march over fdst and fsrc
+		m := march.New(s.ctx, s.fdst, s.fsrc, s.dir, s)
+		m.Run()
+	}
 
 	s.stopTrackRenames()
 	if s.trackRenames {
@@ -621,9 +950,24 @@ func (s *syncCopyMove) run() error {
 	s.stopRenamers()
 	s.stopTransfers()
 	s.stopDeleters()
+	s.transferParked()
+
+	if notQueued := atomic.LoadInt64(&s.notQueued); notQueued > 0 {
+		fs.Logf(s.fdst, "Stopped queuing transfers after --max-files %d - %d more files need transferring so run again to carry on", fs.Config.MaxFiles, notQueued)
+	}
+
+	// Create the empty source directories on the destination
+	if s.copyEmptySrcDirs {
+		s.processError(copyEmptyDirectories(s.ctx, s.fdst, s.srcEmptyDirs))
+	}
+
+	// Delete the moved sources now everything has been transferred
+	if s.deferDeletes {
+		s.processError(s.verifyAndDeleteSources())
+	}
 
 	// Delete files after
-	if s.deleteMode == fs.DeleteModeAfter {
+	if s.deletesCollected() {
 		if s.currentError() != nil && !fs.Config.IgnoreErrors {
 			fs.Errorf(s.fdst, "%v", fs.ErrorNotDeleting)
 		} else {
@@ -636,7 +980,7 @@ func (s *syncCopyMove) run() error {
 		if s.currentError() != nil && !fs.Config.IgnoreErrors {
 			fs.Errorf(s.fdst, "%v", fs.ErrorNotDeletingDirs)
 		} else {
-			s.processError(deleteEmptyDirectories(s.fdst, s.dstEmptyDirs))
+			s.processError(deleteEmptyDirectories(s.ctx, s.fdst, s.dstEmptyDirs))
 		}
 	}
 
@@ -644,11 +988,75 @@ func (s *syncCopyMove) run() error {
 	// if DoMove and --delete-empty-src-dirs flag is set
 	if s.DoMove && s.deleteEmptySrcDirs {
 		//delete empty subdirectories that were part of the move
-		s.processError(deleteEmptyDirectories(s.fsrc, s.srcEmptyDirs))
+		s.processError(deleteEmptyDirectories(s.ctx, s.fsrc, s.srcEmptyDirs))
 	}
 	return s.currentError()
 }
 
+// runFilesFrom looks up each of the --files-from files in fsrc and
+// fdst directly with NewObject, rather than listing the directories,
+// and passes them on to SrcOnly, DstOnly or Match as march would.
+func (s *syncCopyMove) runFilesFrom() {
+	remotes := make(chan string, fs.Config.Checkers)
+	var wg sync.WaitGroup
+	wg.Add(fs.Config.Checkers)
+	for i := 0; i < fs.Config.Checkers; i++ {
+		go func() {
+			defer wg.Done()
+			for remote := range remotes {
+				s.lookupFilesFrom(remote)
+			}
+		}()
+	}
+	for remote := range filter.Active.Files() {
+		if s.aborting() {
+			break
+		}
+		remotes <- remote
+	}
+	close(remotes)
+	wg.Wait()
+}
+
+// lookupFilesFrom looks up the --files-from file remote in fsrc and
+// fdst and passes it on to SrcOnly, DstOnly or Match
+func (s *syncCopyMove) lookupFilesFrom(remote string) {
+	src, err := lookupObject(s.ctx, s.fsrc, remote)
+	if err != nil {
+		fs.Errorf(remote, "Failed to find source file: %v", err)
+		s.processError(err)
+		return
+	}
+	dst, err := lookupObject(s.ctx, s.fdst, remote)
+	if err != nil {
+		fs.Errorf(remote, "Failed to find destination file: %v", err)
+		s.processError(err)
+		return
+	}
+	switch {
+	case src != nil && dst != nil:
+		s.Match(dst, src)
+	case src != nil:
+		s.SrcOnly(src)
+	case dst != nil:
+		s.DstOnly(dst)
+	default:
+		fs.Debugf(remote, "Not found in source or destination")
+	}
+}
+
+// lookupObject finds remote in f returning nil if it isn't a file there
+func lookupObject(ctx context.Context, f fs.Fs, remote string) (fs.DirEntry, error) {
+	o, err := f.NewObject(ctx, remote)
+	switch err {
+	case nil:
+		return o, nil
+	case fs.ErrorObjectNotFound, fs.ErrorNotAFile:
+		return nil, nil
+	}
+	return nil, err
+}
+
 // DstOnly have an object which is in the destination only
 func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 	if s.deleteMode == fs.DeleteModeOff {
@@ -656,13 +1064,14 @@ func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 	}
 	switch x := dst.(type) {
 	case fs.Object:
-		switch s.deleteMode {
-		case fs.DeleteModeAfter:
+		atomic.AddInt64(&s.dstObjects, 1)
+		switch {
+		case s.deletesCollected():
 			// record object as needs deleting
 			s.dstFilesMu.Lock()
 			s.dstFiles[x.Remote()] = x
 			s.dstFilesMu.Unlock()
-		case fs.DeleteModeDuring, fs.DeleteModeOnly:
+		case s.deleteMode == fs.DeleteModeDuring, s.deleteMode == fs.DeleteModeOnly:
 			s.deleteFilesCh <- x
 		default:
 			panic(fmt.Sprintf("unexpected delete mode %d", s.deleteMode))
@@ -672,7 +1081,7 @@ func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 		// Record directory as it is potentially empty and needs deleting
 		if s.fdst.Features().CanHaveEmptyDirectories {
 			s.dstEmptyDirsMu.Lock()
-			s.dstEmptyDirs = append(s.dstEmptyDirs, dst)
+			s.dstEmptyDirs[dst.Remote()] = dst
 			s.dstEmptyDirsMu.Unlock()
 		}
 		return true
@@ -690,10 +1099,13 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 	}
 	switch x := src.(type) {
 	case fs.Object:
+		s.srcEmptyDirsMu.Lock()
+		s.srcParentDirCheck(src)
+		s.srcEmptyDirsMu.Unlock()
 		if s.trackRenames {
 			// Save object to check for a rename later
 			s.trackRenamesCh <- x
-		} else {
+		} else if s.reserveTransfer(x) {
 			// No need to check since doesn't exist
 			s.toBeUploaded <- fs.ObjectPair{Src: x, Dst: nil}
 		}
@@ -701,7 +1113,8 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 		// Do the same thing to the entire contents of the directory
 		// Record the directory for deletion
 		s.srcEmptyDirsMu.Lock()
-		s.srcEmptyDirs = append(s.srcEmptyDirs, src)
+		s.srcParentDirCheck(src)
+		s.srcEmptyDirs[src.Remote()] = src
 		s.srcEmptyDirsMu.Unlock()
 		return true
 	default:
@@ -712,8 +1125,14 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 
 // Match is called when src and dst are present, so sync src to dst
 func (s *syncCopyMove) Match(dst, src fs.DirEntry) (recurse bool) {
+	if _, ok := dst.(fs.Object); ok {
+		atomic.AddInt64(&s.dstObjects, 1)
+	}
 	switch srcX := src.(type) {
 	case fs.Object:
+		s.srcEmptyDirsMu.Lock()
+		s.srcParentDirCheck(src)
+		s.srcEmptyDirsMu.Unlock()
 		if s.deleteMode == fs.DeleteModeOnly {
 			return false
 		}
@@ -732,7 +1151,8 @@ func (s *syncCopyMove) Match(dst, src fs.DirEntry) (recurse bool) {
 		if ok {
 			// Record the src directory for deletion
 			s.srcEmptyDirsMu.Lock()
-			s.srcEmptyDirs = append(s.srcEmptyDirs, src)
+			s.srcParentDirCheck(src)
+			s.srcEmptyDirs[src.Remote()] = src
 			s.srcEmptyDirsMu.Unlock()
 			return true
 		}
@@ -753,17 +1173,20 @@ func (s *syncCopyMove) Match(dst, src fs.DirEntry) (recurse bool) {
 // If DoMove is true then files will be moved instead of copied
 //
 // dir is the start directory, "" for root
-func runSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool) error {
+func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
 	if deleteMode != fs.DeleteModeOff && DoMove {
 		return fserrors.FatalError(errors.New("can't delete and move at the same time"))
 	}
+	if fs.Config.NoTraverse && filter.Active.Files() != nil && filter.Active.Opt.DeleteExcluded {
+		return fserrors.FatalError(errors.New("can't use --delete-excluded with --no-traverse as the excluded files aren't listed"))
+	}
 	// Run an extra pass to delete only
 	if deleteMode == fs.DeleteModeBefore {
 		if fs.Config.TrackRenames {
 			return fserrors.FatalError(errors.New("can't use --delete-before with --track-renames"))
 		}
 		// only delete stuff during in this pass
-		do, err := newSyncCopyMove(fdst, fsrc, fs.DeleteModeOnly, false, deleteEmptySrcDirs)
+		do, err := newSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOnly, false, deleteEmptySrcDirs, copyEmptySrcDirs)
 		if err != nil {
 			return err
 		}
@@ -774,7 +1197,7 @@ func runSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 		// Next pass does a copy only
 		deleteMode = fs.DeleteModeOff
 	}
-	do, err := newSyncCopyMove(fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs)
+	do, err := newSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, copyEmptySrcDirs)
 	if err != nil {
 		return err
 	}
@@ -782,35 +1205,70 @@ func runSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 }
 
 // Sync fsrc into fdst
-func Sync(fdst, fsrc fs.Fs) error {
-	return runSyncCopyMove(fdst, fsrc, fs.Config.DeleteMode, false, false)
+//
+// If copyEmptySrcDirs is set then empty directories in fsrc are
+// created in fdst
+func Sync(ctx context.Context, fdst, fsrc fs.Fs, copyEmptySrcDirs bool) error {
+	return runSyncCopyMove(ctx, fdst, fsrc, fs.Config.DeleteMode, false, false, copyEmptySrcDirs)
 }
 
 // CopyDir copies fsrc into fdst
-func CopyDir(fdst, fsrc fs.Fs) error {
-	return runSyncCopyMove(fdst, fsrc, fs.DeleteModeOff, false, false)
+//
+// If copyEmptySrcDirs is set then empty directories in fsrc are
+// created in fdst
+func CopyDir(ctx context.Context, fdst, fsrc fs.Fs, copyEmptySrcDirs bool) error {
+	return runSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOff, false, false, copyEmptySrcDirs)
 }
 
 // moveDir moves fsrc into fdst
-func moveDir(fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
-	return runSyncCopyMove(fdst, fsrc, fs.DeleteModeOff, true, deleteEmptySrcDirs)
+func moveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
+	return runSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOff, true, deleteEmptySrcDirs, copyEmptySrcDirs)
+}
+
+// MovedFn is called by MoveDirVerified with each source file and the
+// file it was moved to once they have been verified
+type MovedFn func(src, dst fs.Object) error
+
+// MoveDirVerified moves the files in fsrc into fdst like MoveDir with
+// --delete-after-verify, so the sources are only deleted once all the
+// files have been copied and checked in fdst.
+//
+// moved is called with each file once they have all been verified and
+// before any sources are deleted.  If it returns an error no sources
+// are deleted.  It isn't called with --dry-run.
+func MoveDirVerified(ctx context.Context, fdst, fsrc fs.Fs, moved MovedFn) error {
+	if operations.Overlapping(fdst, fsrc) {
+		err := fs.ErrorCantMoveOverlapping
+		fs.Errorf(fdst, "%v", err)
+		return err
+	}
+	do, err := newSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOff, true, false, false)
+	if err != nil {
+		return err
+	}
+	do.deferDeletes = true
+	do.moved = moved
+	return do.run()
 }
 
 // MoveDir moves fsrc into fdst
-func MoveDir(fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
+//
+// If copyEmptySrcDirs is set then empty directories in fsrc are
+// created in fdst
+func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool, copyEmptySrcDirs bool) error {
 	if operations.Same(fdst, fsrc) {
 		fs.Errorf(fdst, "Nothing to do as source and destination are the same")
 		return nil
 	}
 
 	// First attempt to use DirMover if exists, same Fs and no filters are active
-	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && filter.Active.InActive() {
+	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && filter.Active.InActive() && fs.Config.DestTemplate == "" {
 		if fs.Config.DryRun {
 			fs.Logf(fdst, "Not doing server side directory move as --dry-run")
 			return nil
 		}
 		fs.Debugf(fdst, "Using server side directory move")
-		err := fdstDirMove(fsrc, "", "")
+		err := fdstDirMove(ctx, fsrc, "", "")
 		switch err {
 		case fs.ErrorCantDirMove, fs.ErrorDirExists:
 			fs.Infof(fdst, "Server side directory move failed - fallback to file moves: %v", err)
@@ -832,5 +1290,5 @@ func MoveDir(fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
 	}
 
 	// Otherwise move the files one by one
-	return moveDir(fdst, fsrc, deleteEmptySrcDirs)
+	return moveDir(ctx, fdst, fsrc, deleteEmptySrcDirs, copyEmptySrcDirs)
 }
@@ -3,7 +3,9 @@
 package sync
 
 import (
+	"context"
 	"runtime"
+	"sort"
 	"testing"
 	"time"
 
@@ -11,9 +13,11 @@ import (
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fstest"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/unicode/norm"
@@ -39,7 +43,7 @@ func TestCopyWithDryRun(t *testing.T) {
 	r.Mkdir(r.Fremote)
 
 	fs.Config.DryRun = true
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal, false)
 	fs.Config.DryRun = false
 	require.NoError(t, err)
 
@@ -54,13 +58,67 @@ func TestCopy(t *testing.T) {
 	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
 	r.Mkdir(r.Fremote)
 
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file1)
 }
 
+// Test copy with empty directories
+func TestCopyEmptyDirectories(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
+	err := operations.Mkdir(context.Background(), r.Flocal, "sub dir2")
+	require.NoError(t, err)
+	err = operations.Mkdir(context.Background(), r.Flocal, "sub dir2/sub sub dir")
+	require.NoError(t, err)
+	r.Mkdir(r.Fremote)
+
+	err = CopyDir(context.Background(), r.Fremote, r.Flocal, true)
+	require.NoError(t, err)
+
+	fstest.CheckListingWithPrecision(
+		t,
+		r.Fremote,
+		[]fstest.Item{
+			file1,
+		},
+		[]string{
+			"sub dir",
+			"sub dir2",
+			"sub dir2/sub sub dir",
+		},
+		fs.Config.ModifyWindow,
+	)
+}
+
+// Test copy without empty directories
+func TestCopyNoEmptyDirectories(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
+	err := operations.Mkdir(context.Background(), r.Flocal, "sub dir2")
+	require.NoError(t, err)
+	r.Mkdir(r.Fremote)
+
+	err = CopyDir(context.Background(), r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+
+	fstest.CheckListingWithPrecision(
+		t,
+		r.Fremote,
+		[]fstest.Item{
+			file1,
+		},
+		[]string{
+			"sub dir",
+		},
+		fs.Config.ModifyWindow,
+	)
+}
+
 // Test copy with depth
 func TestCopyWithDepth(t *testing.T) {
 	r := fstest.NewRun(t)
@@ -72,7 +130,7 @@ func TestCopyWithDepth(t *testing.T) {
 	fs.Config.MaxDepth = 1
 	defer func() { fs.Config.MaxDepth = -1 }()
 
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1, file2)
@@ -91,7 +149,7 @@ func TestServerSideCopy(t *testing.T) {
 	defer finaliseCopy()
 	t.Logf("Server side copy (if possible) %v -> %v", r.Fremote, FremoteCopy)
 
-	err = CopyDir(FremoteCopy, r.Fremote)
+	err = CopyDir(context.Background(), FremoteCopy, r.Fremote, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, FremoteCopy, file1)
@@ -106,10 +164,10 @@ func TestCopyAfterDelete(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal)
 	fstest.CheckItems(t, r.Fremote, file1)
 
-	err := operations.Mkdir(r.Flocal, "")
+	err := operations.Mkdir(context.Background(), r.Flocal, "")
 	require.NoError(t, err)
 
-	err = CopyDir(r.Fremote, r.Flocal)
+	err = CopyDir(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal)
@@ -123,7 +181,7 @@ func TestCopyRedownload(t *testing.T) {
 	file1 := r.WriteObject("sub dir/hello world", "hello world", t1)
 	fstest.CheckItems(t, r.Fremote, file1)
 
-	err := CopyDir(r.Flocal, r.Fremote)
+	err := CopyDir(context.Background(), r.Flocal, r.Fremote, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -142,7 +200,7 @@ func TestSyncBasedOnCheckSum(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file.
@@ -154,7 +212,7 @@ func TestSyncBasedOnCheckSum(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred no files
@@ -176,7 +234,7 @@ func TestSyncSizeOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file.
@@ -188,7 +246,7 @@ func TestSyncSizeOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred no files
@@ -210,7 +268,7 @@ func TestSyncIgnoreSize(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file.
@@ -222,7 +280,7 @@ func TestSyncIgnoreSize(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred no files
@@ -238,7 +296,7 @@ func TestSyncIgnoreTimes(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred exactly 0 files because the
@@ -249,7 +307,7 @@ func TestSyncIgnoreTimes(t *testing.T) {
 	defer func() { fs.Config.IgnoreTimes = false }()
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file even though the
@@ -269,7 +327,7 @@ func TestSyncIgnoreExisting(t *testing.T) {
 	defer func() { fs.Config.IgnoreExisting = false }()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file1)
@@ -277,7 +335,7 @@ func TestSyncIgnoreExisting(t *testing.T) {
 	// Change everything
 	r.WriteFile("existing", "newpotatoes", t2)
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	// Items should not change
 	fstest.CheckItems(t, r.Fremote, file1)
@@ -293,7 +351,7 @@ func TestSyncIgnoreErrors(t *testing.T) {
 	file1 := r.WriteFile("a/potato2", "------------------------------------------------------------", t1)
 	file2 := r.WriteObject("b/potato", "SMALLER BUT SAME DATE", t2)
 	file3 := r.WriteBoth("c/non empty space", "AhHa!", t2)
-	require.NoError(t, operations.Mkdir(r.Fremote, "d"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d"))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -325,7 +383,7 @@ func TestSyncIgnoreErrors(t *testing.T) {
 
 	accounting.Stats.ResetCounters()
 	fs.CountError(nil)
-	assert.NoError(t, Sync(r.Fremote, r.Flocal))
+	assert.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal, false))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -368,7 +426,7 @@ func TestSyncAfterChangingModtimeOnly(t *testing.T) {
 	defer func() { fs.Config.DryRun = false }()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -377,7 +435,7 @@ func TestSyncAfterChangingModtimeOnly(t *testing.T) {
 	fs.Config.DryRun = false
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -405,7 +463,7 @@ func TestSyncAfterChangingModtimeOnlyWithNoUpdateModTime(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -426,7 +484,7 @@ func TestSyncDoesntUpdateModtime(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -446,7 +504,7 @@ func TestSyncAfterAddingAFile(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1, file2)
 	fstest.CheckItems(t, r.Fremote, file1, file2)
@@ -461,7 +519,7 @@ func TestSyncAfterChangingFilesSizeOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2)
 	fstest.CheckItems(t, r.Fremote, file2)
@@ -484,7 +542,7 @@ func TestSyncAfterChangingContentsOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2)
 	fstest.CheckItems(t, r.Fremote, file2)
@@ -500,7 +558,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileDryRun(t *testing.T) {
 
 	fs.Config.DryRun = true
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	fs.Config.DryRun = false
 	require.NoError(t, err)
 
@@ -519,7 +577,7 @@ func TestSyncAfterRemovingAFileAndAddingAFile(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1, file3)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1, file3)
 	fstest.CheckItems(t, r.Fremote, file1, file3)
@@ -532,8 +590,8 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDir(t *testing.T) {
 	file1 := r.WriteFile("a/potato2", "------------------------------------------------------------", t1)
 	file2 := r.WriteObject("b/potato", "SMALLER BUT SAME DATE", t2)
 	file3 := r.WriteBoth("c/non empty space", "AhHa!", t2)
-	require.NoError(t, operations.Mkdir(r.Fremote, "d"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "d/e"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d/e"))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -565,7 +623,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDir(t *testing.T) {
 	)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckListingWithPrecision(
@@ -603,7 +661,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDirWithErrors(t *testing.T) {
 	file1 := r.WriteFile("a/potato2", "------------------------------------------------------------", t1)
 	file2 := r.WriteObject("b/potato", "SMALLER BUT SAME DATE", t2)
 	file3 := r.WriteBoth("c/non empty space", "AhHa!", t2)
-	require.NoError(t, operations.Mkdir(r.Fremote, "d"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d"))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -635,7 +693,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDirWithErrors(t *testing.T) {
 
 	accounting.Stats.ResetCounters()
 	fs.CountError(nil)
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	assert.Equal(t, fs.ErrorNotDeleting, err)
 
 	fstest.CheckListingWithPrecision(
@@ -712,13 +770,175 @@ func TestCopyDeleteBefore(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Fremote, file1, file2)
 	fstest.CheckItems(t, r.Flocal, file2)
 }
 
+// Test the delete limits are checked before anything is deleted
+func TestSyncWithMaxDelete(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteBoth("empty space", "", t2)
+	file2 := r.WriteObject("potato", "SMALLER BUT SAME DATE", t2)
+	file3 := r.WriteObject("potato2", "------------------------------------------------------------", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+	fstest.CheckItems(t, r.Flocal, file1)
+
+	defer func() {
+		fs.Config.MaxDelete = -1
+		fs.Config.MaxDeletePercent = -1
+		fs.Config.MaxSizeDelete = -1
+		fs.Config.DeleteMode = fs.DeleteModeDefault
+	}()
+	for _, test := range []struct {
+		name          string
+		maxDelete     int64
+		maxPercent    float64
+		maxSizeDelete fs.SizeSuffix
+		deleteMode    fs.DeleteMode
+	}{
+		{"count", 1, -1, -1, fs.DeleteModeDefault},
+		{"percent", -1, 50, -1, fs.DeleteModeDefault},
+		{"size", -1, -1, 50, fs.DeleteModeDefault},
+		{"before", 1, -1, -1, fs.DeleteModeBefore},
+	} {
+		fs.Config.MaxDelete = test.maxDelete
+		fs.Config.MaxDeletePercent = test.maxPercent
+		fs.Config.MaxSizeDelete = test.maxSizeDelete
+		fs.Config.DeleteMode = test.deleteMode
+		accounting.Stats.ResetCounters()
+		err := Sync(context.Background(), r.Fremote, r.Flocal, false)
+		require.Error(t, err, test.name)
+		assert.True(t, fserrors.IsFatalError(err), test.name)
+		accounting.Stats.ResetCounters()
+		fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+	}
+
+	// within the limits everything is deleted
+	fs.Config.MaxDelete = 2
+	fs.Config.MaxDeletePercent = 70
+	fs.Config.MaxSizeDelete = 100
+	fs.Config.DeleteMode = fs.DeleteModeDefault
+	accounting.Stats.ResetCounters()
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+func TestCopyWithMaxFiles(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteBoth("existing", "already there", t1)
+	file2 := r.WriteFile("one", "one", t1)
+	file3 := r.WriteFile("two", "two", t1)
+	file4 := r.WriteFile("three", "three", t1)
+
+	fs.Config.MaxFiles = 2
+	defer func() {
+		fs.Config.MaxFiles = -1
+	}()
+
+	// only 2 new files are transferred on each run
+	accounting.Stats.ResetCounters()
+	require.NoError(t, CopyDir(context.Background(), r.Fremote, r.Flocal, false))
+	objects, _, err := operations.Count(context.Background(), r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), objects)
+
+	// a retry in the same run doesn't transfer any more
+	require.NoError(t, CopyDir(context.Background(), r.Fremote, r.Flocal, false))
+	objects, _, err = operations.Count(context.Background(), r.Fremote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), objects)
+
+	accounting.Stats.ResetCounters()
+	require.NoError(t, CopyDir(context.Background(), r.Fremote, r.Flocal, false))
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4)
+}
+
+// Test copy and move with --dest-template
+func TestCopyMoveWithDestTemplate(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("photo.jpg", "photo", t1)
+	file2 := r.WriteFile("sub/log.txt", "log", t2)
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+
+	fs.Config.DestTemplate = `{{.ModTime.UTC.Format "2006/01"}}/{{.Base}}-{{.Size}}{{.Ext}}`
+	defer func() {
+		fs.Config.DestTemplate = ""
+	}()
+
+	accounting.Stats.ResetCounters()
+	require.NoError(t, CopyDir(context.Background(), r.Fremote, r.Flocal, false))
+	dst1 := fstest.NewItem("2001/02/photo-5.jpg", "photo", t1)
+	dst2 := fstest.NewItem("2011/12/log-3.txt", "log", t2)
+	fstest.CheckItems(t, r.Fremote, dst1, dst2)
+	assert.Equal(t, int64(2), accounting.Stats.GetTransfers())
+
+	// the files already there aren't transferred again
+	accounting.Stats.ResetCounters()
+	require.NoError(t, CopyDir(context.Background(), r.Fremote, r.Flocal, false))
+	assert.Equal(t, int64(0), accounting.Stats.GetTransfers())
+
+	// move transfers nothing but removes the source files once
+	// they are verified at their new paths
+	fs.Config.DeleteAfterVerify = true
+	defer func() {
+		fs.Config.DeleteAfterVerify = false
+	}()
+	accounting.Stats.ResetCounters()
+	require.NoError(t, MoveDir(context.Background(), r.Fremote, r.Flocal, false, false))
+	fstest.CheckItems(t, r.Flocal)
+	fstest.CheckItems(t, r.Fremote, dst1, dst2)
+
+	// sync isn't allowed as it would delete the files
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
+	assert.Error(t, err)
+
+	// two files with the same destination
+	r.WriteFile("a/same", "same", t1)
+	r.WriteFile("b/same", "same", t1)
+	fs.Config.DestTemplate = `{{.Name}}`
+	accounting.Stats.ResetCounters()
+	err = CopyDir(context.Background(), r.Fremote, r.Flocal, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "--dest-template made \"same\"")
+}
+
+// Test MoveDirVerified calls moved before deleting the sources
+func TestMoveDirVerified(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("one", "one", t1)
+	file2 := r.WriteFile("sub/two", "two", t2)
+
+	// nothing is deleted if moved fails
+	accounting.Stats.ResetCounters()
+	err := MoveDirVerified(context.Background(), r.Fremote, r.Flocal, func(src, dst fs.Object) error {
+		return errors.New("manifest full")
+	})
+	assert.Error(t, err)
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	var moved []string
+	accounting.Stats.ResetCounters()
+	err = MoveDirVerified(context.Background(), r.Fremote, r.Flocal, func(src, dst fs.Object) error {
+		assert.Equal(t, src.Remote(), dst.Remote())
+		moved = append(moved, src.Remote())
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(moved)
+	assert.Equal(t, []string{"one", "sub/two"}, moved)
+	fstest.CheckItems(t, r.Flocal)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+}
+
 // Test with exclude
 func TestSyncWithExclude(t *testing.T) {
 	r := fstest.NewRun(t)
@@ -735,18 +955,55 @@ func TestSyncWithExclude(t *testing.T) {
 	}()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Fremote, file2, file1)
 
 	// Now sync the other way round and check enormous doesn't get
 	// deleted as it is excluded from the sync
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Flocal, r.Fremote)
+	err = Sync(context.Background(), r.Flocal, r.Fremote, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2, file1, file3)
 }
 
+// Test --files-from with --no-traverse looking up the files directly
+func TestSyncFilesFromNoTraverse(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("new", "new file", t1)
+	file2 := r.WriteBoth("dir/both", "both", t1)
+	file3 := r.WriteObject("gone", "deleted from the source", t1)
+	file4 := r.WriteObject("unlisted", "not in --files-from", t1)
+	file5 := r.WriteFile("local", "not in --files-from", t1)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file5)
+	fstest.CheckItems(t, r.Fremote, file2, file3, file4)
+
+	oldFilter := filter.Active
+	oldNoTraverse := fs.Config.NoTraverse
+	defer func() {
+		filter.Active = oldFilter
+		fs.Config.NoTraverse = oldNoTraverse
+	}()
+	var err error
+	filter.Active, err = filter.NewFilter(nil)
+	require.NoError(t, err)
+	for _, file := range []string{"new", "dir/both", "gone", "missing"} {
+		require.NoError(t, filter.Active.AddFile(file))
+	}
+	fs.Config.NoTraverse = true
+
+	accounting.Stats.ResetCounters()
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Flocal, file1, file2, file5)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file4)
+
+	filter.Active.Opt.DeleteExcluded = true
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
+	assert.Error(t, err)
+}
+
 // Test with exclude and delete excluded
 func TestSyncWithExcludeAndDeleteExcluded(t *testing.T) {
 	r := fstest.NewRun(t)
@@ -765,14 +1022,14 @@ func TestSyncWithExcludeAndDeleteExcluded(t *testing.T) {
 	}()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	// Check sync the other way round to make sure enormous gets
 	// deleted even though it is excluded
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Flocal, r.Fremote)
+	err = Sync(context.Background(), r.Flocal, r.Fremote, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2)
 }
@@ -807,7 +1064,7 @@ func TestSyncWithUpdateOlder(t *testing.T) {
 	}()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Fremote, oneO, twoF, threeO, fourF, fiveF)
 }
@@ -831,7 +1088,7 @@ func TestSyncWithTrackRenames(t *testing.T) {
 	f2 := r.WriteFile("yam", "Yam Content", t2)
 
 	accounting.Stats.ResetCounters()
-	require.NoError(t, Sync(r.Fremote, r.Flocal))
+	require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal, false))
 
 	fstest.CheckItems(t, r.Fremote, f1, f2)
 	fstest.CheckItems(t, r.Flocal, f1, f2)
@@ -840,7 +1097,7 @@ func TestSyncWithTrackRenames(t *testing.T) {
 	f2 = r.RenameFile(f2, "yaml")
 
 	accounting.Stats.ResetCounters()
-	require.NoError(t, Sync(r.Fremote, r.Flocal))
+	require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal, false))
 
 	fstest.CheckItems(t, r.Fremote, f1, f2)
 
@@ -862,7 +1119,7 @@ func testServerSideMove(t *testing.T, r *fstest.Run, withFilter, testDeleteEmpty
 	file3u := r.WriteBoth("potato3", "------------------------------------------------------------ UPDATED", t2)
 
 	if testDeleteEmptyDirs {
-		err := operations.Mkdir(r.Fremote, "tomatoDir")
+		err := operations.Mkdir(context.Background(), r.Fremote, "tomatoDir")
 		require.NoError(t, err)
 	}
 
@@ -877,7 +1134,7 @@ func testServerSideMove(t *testing.T, r *fstest.Run, withFilter, testDeleteEmpty
 
 	// Do server side move
 	accounting.Stats.ResetCounters()
-	err = MoveDir(FremoteMove, r.Fremote, testDeleteEmptyDirs)
+	err = MoveDir(context.Background(), FremoteMove, r.Fremote, testDeleteEmptyDirs, false)
 	require.NoError(t, err)
 
 	if withFilter {
@@ -898,13 +1155,13 @@ func testServerSideMove(t *testing.T, r *fstest.Run, withFilter, testDeleteEmpty
 	defer finaliseMove2()
 
 	if testDeleteEmptyDirs {
-		err := operations.Mkdir(FremoteMove, "tomatoDir")
+		err := operations.Mkdir(context.Background(), FremoteMove, "tomatoDir")
 		require.NoError(t, err)
 	}
 
 	// Move it back to a new empty remote, dst does not exist this time
 	accounting.Stats.ResetCounters()
-	err = MoveDir(FremoteMove2, FremoteMove, testDeleteEmptyDirs)
+	err = MoveDir(context.Background(), FremoteMove2, FremoteMove, testDeleteEmptyDirs, false)
 	require.NoError(t, err)
 
 	if withFilter {
@@ -964,7 +1221,7 @@ func TestServerSideMoveOverlap(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 
 	// Subdir move with no filters should return ErrorCantMoveOverlapping
-	err = MoveDir(FremoteMove, r.Fremote, false)
+	err = MoveDir(context.Background(), FremoteMove, r.Fremote, false, false)
 	assert.EqualError(t, err, fs.ErrorCantMoveOverlapping.Error())
 
 	// Now try with a filter which should also fail with ErrorCantMoveOverlapping
@@ -972,7 +1229,7 @@ func TestServerSideMoveOverlap(t *testing.T) {
 	defer func() {
 		filter.Active.Opt.MinSize = -1
 	}()
-	err = MoveDir(FremoteMove, r.Fremote, false)
+	err = MoveDir(context.Background(), FremoteMove, r.Fremote, false, false)
 	assert.EqualError(t, err, fs.ErrorCantMoveOverlapping.Error())
 }
 
@@ -1008,7 +1265,7 @@ func testSyncBackupDir(t *testing.T, suffix string) {
 	require.NoError(t, err)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(fdst, r.Flocal)
+	err = Sync(context.Background(), fdst, r.Flocal, false)
 	require.NoError(t, err)
 
 	// one should be moved to the backup dir and the new one installed
@@ -1029,7 +1286,7 @@ func testSyncBackupDir(t *testing.T, suffix string) {
 	// This should delete three and overwrite one again, checking
 	// the files got overwritten correctly in backup-dir
 	accounting.Stats.ResetCounters()
-	err = Sync(fdst, r.Flocal)
+	err = Sync(context.Background(), fdst, r.Flocal, false)
 	require.NoError(t, err)
 
 	// one should be moved to the backup dir and the new one installed
@@ -1066,7 +1323,7 @@ func TestSyncUTFNorm(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file, but kept the
@@ -1092,7 +1349,7 @@ func TestSyncImmutable(t *testing.T) {
 
 	// Should succeed
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal, false)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file1)
@@ -1104,8 +1361,77 @@ func TestSyncImmutable(t *testing.T) {
 
 	// Should fail with ErrorImmutableModified and not modify local or remote files
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal, false)
 	assert.EqualError(t, err, fs.ErrorImmutableModified.Error())
 	fstest.CheckItems(t, r.Flocal, file2)
 	fstest.CheckItems(t, r.Fremote, file1)
 }
+
+// Test syncing via a staging directory
+func TestSyncStaged(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Fremote.Features().DirMove == nil {
+		t.Skip("remote can't move directories")
+	}
+	ctx := context.Background()
+	dst := r.FremoteName + "/live"
+	staging := r.FremoteName + "/staging"
+
+	old1 := r.WriteObject("live/old", "old content", t1)
+	same := r.WriteObject("live/same", "same content", t2)
+	fstest.CheckItems(t, r.Fremote, old1, same)
+	r.WriteFile("same", "same content", t2)
+	r.WriteFile("new", "new content", t1)
+
+	accounting.Stats.ResetCounters()
+	require.NoError(t, SyncStaged(ctx, r.Flocal, dst, staging, false))
+	same.Path = "live/same"
+	new1 := fstest.NewItem("live/new", "new content", t1)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{same, new1}, []string{"live"}, fs.Config.ModifyWindow)
+
+	// staging directories which overlap the destination are refused
+	for _, staging := range []string{dst, dst + "/.next", r.FremoteName, dst + oldSuffix} {
+		err := SyncStaged(ctx, r.Flocal, dst, staging, false)
+		require.Error(t, err, staging)
+		assert.Contains(t, err.Error(), "can't overlap", staging)
+	}
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{same, new1}, []string{"live"}, fs.Config.ModifyWindow)
+}
+
+// Test a move with --delete-after-verify
+func TestMoveDeleteAfterVerify(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	fs.Config.DeleteAfterVerify = true
+	defer func() { fs.Config.DeleteAfterVerify = false }()
+
+	file1 := r.WriteFile("potato", "potato content", t1)
+	file2 := r.WriteFile("sub/yam", "yam content", t2)
+	file3 := r.WriteObject("potato", "potato content", t1)
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	fstest.CheckItems(t, r.Fremote, file3)
+
+	accounting.Stats.ResetCounters()
+	require.NoError(t, moveDir(context.Background(), r.Fremote, r.Flocal, false, false))
+	fstest.CheckItems(t, r.Flocal)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	// nothing is deleted if there are errors
+	file4 := r.WriteFile("sausage", "sausage content", t1)
+	src4, err := r.Flocal.NewObject(context.Background(), file4.Path)
+	require.NoError(t, err)
+	s, err := newSyncCopyMove(context.Background(), r.Fremote, r.Flocal, fs.DeleteModeOff, true, false, false)
+	require.NoError(t, err)
+	s.deferDelete(src4)
+	s.processError(errors.New("boom"))
+	require.NoError(t, s.verifyAndDeleteSources())
+	fstest.CheckItems(t, r.Flocal, file4)
+
+	// nothing is deleted if a file fails verification
+	s, err = newSyncCopyMove(context.Background(), r.Fremote, r.Flocal, fs.DeleteModeOff, true, false, false)
+	require.NoError(t, err)
+	s.deferDelete(src4)
+	assert.Error(t, s.verifyAndDeleteSources())
+	fstest.CheckItems(t, r.Flocal, file4)
+}
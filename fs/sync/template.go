@@ -0,0 +1,165 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/walk"
+	"github.com/pkg/errors"
+)
+
+// TemplateData is what a --dest-template is executed with to make the
+// destination path of each file
+type TemplateData struct {
+	Path    string    // path of the file relative to the source
+	Dir     string    // directory of the file, "" if in the root
+	Name    string    // leaf name of the file
+	Base    string    // Name without Ext
+	Ext     string    // extension of Name including the ".", eg ".jpg"
+	Size    int64     // size of the file
+	ModTime time.Time // modification time of the file
+}
+
+// destTemplate makes the destination paths for --dest-template
+type destTemplate struct {
+	tmpl   *template.Template
+	seenMu sync.Mutex
+	seen   map[string]string // source path of each destination path made
+}
+
+// newDestTemplate parses text as a --dest-template
+func newDestTemplate(text string) (*destTemplate, error) {
+	tmpl, err := template.New("dest-template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "bad --dest-template")
+	}
+	return &destTemplate{
+		tmpl: tmpl,
+		seen: make(map[string]string),
+	}, nil
+}
+
+// remote executes the template for src returning the path it should
+// have in the destination
+func (d *destTemplate) remote(src fs.Object) (string, error) {
+	srcRemote := src.Remote()
+	dir, name := path.Split(srcRemote)
+	ext := path.Ext(name)
+	data := TemplateData{
+		Path:    srcRemote,
+		Dir:     strings.TrimSuffix(dir, "/"),
+		Name:    name,
+		Base:    strings.TrimSuffix(name, ext),
+		Ext:     ext,
+		Size:    src.Size(),
+		ModTime: src.ModTime(),
+	}
+	var buf bytes.Buffer
+	err := d.tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", fserrors.NoRetryError(errors.Wrap(err, "failed to execute --dest-template"))
+	}
+	remote := path.Clean(strings.TrimLeft(buf.String(), "/"))
+	if remote == "." || remote == ".." || strings.HasPrefix(remote, "../") || strings.HasSuffix(buf.String(), "/") {
+		return "", fserrors.NoRetryError(errors.Errorf("--dest-template made bad path %q", buf.String()))
+	}
+	return remote, nil
+}
+
+// claim executes the template for src and records the path made,
+// returning an error if another file was given the same path
+func (d *destTemplate) claim(src fs.Object) (string, error) {
+	remote, err := d.remote(src)
+	if err != nil {
+		return "", err
+	}
+	d.seenMu.Lock()
+	defer d.seenMu.Unlock()
+	if other, found := d.seen[remote]; found && other != src.Remote() {
+		return "", fserrors.NoRetryError(errors.Errorf("--dest-template made %q for %q too", remote, other))
+	}
+	d.seen[remote] = src.Remote()
+	return remote, nil
+}
+
+// runDestTemplate walks the source and looks up the destination of
+// each file the --dest-template makes, passing them on to SrcOnly or
+// Match as march would.  Nothing in the destination is deleted.
+func (s *syncCopyMove) runDestTemplate() {
+	objects := make(chan fs.Object, fs.Config.Checkers)
+	var wg sync.WaitGroup
+	wg.Add(fs.Config.Checkers)
+	for i := 0; i < fs.Config.Checkers; i++ {
+		go func() {
+			defer wg.Done()
+			for src := range objects {
+				s.lookupDestTemplate(src)
+			}
+		}()
+	}
+	err := walk.Walk(s.ctx, s.fsrc, s.dir, false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if s.aborting() {
+				return s.ctx.Err()
+			}
+			switch x := entry.(type) {
+			case fs.Object:
+				objects <- x
+			case fs.Directory:
+				// Record the directory for --delete-empty-src-dirs
+				s.srcEmptyDirsMu.Lock()
+				s.srcParentDirCheck(x)
+				s.srcEmptyDirs[x.Remote()] = x
+				s.srcEmptyDirsMu.Unlock()
+			}
+		}
+		return nil
+	})
+	close(objects)
+	wg.Wait()
+	if err != nil && err != context.Canceled {
+		fs.Errorf(s.fsrc, "error reading source directory: %v", err)
+		fs.CountError(err)
+		s.processError(err)
+	}
+}
+
+// lookupDestTemplate looks up the destination the --dest-template
+// makes for src and passes it on to SrcOnly or Match
+func (s *syncCopyMove) lookupDestTemplate(src fs.Object) {
+	remote, err := s.destTemplate.claim(src)
+	if err != nil {
+		fs.Errorf(src, "%v", err)
+		s.processError(err)
+		return
+	}
+	dst, err := lookupObject(s.ctx, s.fdst, remote)
+	if err != nil {
+		fs.Errorf(remote, "Failed to find destination file: %v", err)
+		s.processError(err)
+		return
+	}
+	if dst != nil {
+		s.Match(dst, src)
+	} else {
+		s.SrcOnly(src)
+	}
+}
+
+// dstRemote returns the path src should have in the destination
+func (s *syncCopyMove) dstRemote(src fs.Object) (string, error) {
+	if s.destTemplate == nil {
+		return src.Remote(), nil
+	}
+	return s.destTemplate.remote(src)
+}
@@ -0,0 +1,32 @@
+package fs
+
+import (
+	"encoding/hex"
+
+	"github.com/ncw/rclone/fs/hash"
+)
+
+// UploadHash returns the hash of type ht of src for a backend to send
+// with an upload, so the remote can check the data it receives and
+// reject a corrupted upload.  It returns "" if the hash isn't known.
+//
+// The hash isn't returned if --ignore-checksum is set or if it isn't
+// a valid hash of type ht.
+func UploadHash(src ObjectInfo, ht hash.Type) string {
+	if Config.IgnoreChecksum {
+		return ""
+	}
+	sum, err := src.Hash(ht)
+	if err != nil {
+		Debugf(src, "Failed to read %v to send with upload: %v", ht, err)
+		return ""
+	}
+	if sum == "" {
+		return ""
+	}
+	if _, err := hex.DecodeString(sum); err != nil || len(sum) != hash.Width[ht] {
+		Debugf(src, "Not sending invalid %v %q with upload", ht, sum)
+		return ""
+	}
+	return sum
+}
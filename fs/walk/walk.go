@@ -3,6 +3,7 @@ package walk
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"path"
 	"sort"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/filter"
 	"github.com/ncw/rclone/fs/list"
 	"github.com/pkg/errors"
@@ -54,36 +56,43 @@ type Func func(path string, entries fs.DirEntries, err error) error
 // This is implemented by WalkR if Config.UseRecursiveListing is true
 // and f supports it and level > 1, or WalkN otherwise.
 //
+// If Config.Sorted is set then fn is called in a stable order - see
+// walkSorted.
+//
 // NB (f, path) to be replaced by fs.Dir at some point
-func Walk(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func) error {
+func Walk(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int, fn Func) error {
+	if fs.Config.Sorted {
+		return walkSorted(ctx, f, path, includeAll, maxLevel, fn, list.DirSorted)
+	}
 	if (maxLevel < 0 || maxLevel > 1) && fs.Config.UseListR && f.Features().ListR != nil {
-		return walkListR(f, path, includeAll, maxLevel, fn)
+		return walkListR(ctx, f, path, includeAll, maxLevel, fn)
 	}
-	return walkListDirSorted(f, path, includeAll, maxLevel, fn)
+	return walkListDirSorted(ctx, f, path, includeAll, maxLevel, fn)
 }
 
 // walkListDirSorted lists the directory.
 //
 // It implements Walk using non recursive directory listing.
-func walkListDirSorted(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func) error {
-	return walk(f, path, includeAll, maxLevel, fn, list.DirSorted)
+func walkListDirSorted(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int, fn Func) error {
+	return walk(ctx, f, path, includeAll, maxLevel, fn, list.DirSorted)
 }
 
 // walkListR lists the directory.
 //
 // It implements Walk using recursive directory listing if
 // available, or returns ErrorCantListR if not.
-func walkListR(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func) error {
+func walkListR(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int, fn Func) error {
 	listR := f.Features().ListR
 	if listR == nil {
 		return ErrorCantListR
 	}
-	return walkR(f, path, includeAll, maxLevel, fn, listR)
+	ctx = accounting.WithAPIClass(ctx, accounting.APIList)
+	return walkR(ctx, f, path, includeAll, maxLevel, fn, listR)
 }
 
-type listDirFunc func(fs fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error)
+type listDirFunc func(ctx context.Context, fs fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error)
 
-func walk(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listDir listDirFunc) error {
+func walk(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listDir listDirFunc) error {
 	var (
 		wg         sync.WaitGroup // sync closing of go routines
 		traversing sync.WaitGroup // running directory traversals
@@ -119,7 +128,7 @@ func walk(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listDir
 					if !ok {
 						return
 					}
-					entries, err := listDir(f, includeAll, job.remote)
+					entries, err := listDir(ctx, f, includeAll, job.remote)
 					var jobs []listJob
 					if err == nil && job.depth != 0 {
 						entries.ForDir(func(dir fs.Directory) {
@@ -177,6 +186,102 @@ func walk(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listDir
 	return <-errs
 }
 
+// sortedDir is a directory being listed in the background by
+// walkSorted
+type sortedDir struct {
+	remote  string
+	depth   int
+	done    chan struct{} // closed when the listing is done
+	entries fs.DirEntries
+	err     error
+}
+
+// walkSorted implements Walk calling fn in a stable order which is
+// the same whichever remote is listed and however long each listing
+// takes.
+//
+// The entries of each directory are passed to fn in sorted order
+// with the contents of each subdirectory passed straight after the
+// subdirectory itself, so fn is called with a tranche of entries up
+// to and including each subdirectory.  The listings of the
+// subdirectories are done in the background while the directory is
+// being passed to fn.
+func walkSorted(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listDir listDirFunc) error {
+	tokens := make(chan struct{}, fs.Config.Checkers)
+	start := func(remote string, depth int) *sortedDir {
+		d := &sortedDir{
+			remote: remote,
+			depth:  depth,
+			done:   make(chan struct{}),
+		}
+		go func() {
+			defer close(d.done)
+			tokens <- struct{}{}
+			d.entries, d.err = listDir(ctx, f, includeAll, remote)
+			<-tokens
+		}()
+		return d
+	}
+	// call calls fn returning whether to skip the subdirectories
+	call := func(remote string, entries fs.DirEntries, err error) (skip bool, _ error) {
+		err = fn(remote, entries, err)
+		if err == ErrorSkipDir {
+			return true, nil
+		}
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(remote, "error listing: %v", err)
+		}
+		return false, err
+	}
+	var visit func(d *sortedDir) error
+	visit = func(d *sortedDir) error {
+		<-d.done
+		if d.err != nil {
+			_, err := call(d.remote, nil, d.err)
+			return err
+		}
+		// start listing the subdirectories
+		subDirs := map[string]*sortedDir{}
+		if d.depth != 0 {
+			d.entries.ForDir(func(dir fs.Directory) {
+				subDirs[dir.Remote()] = start(dir.Remote(), d.depth-1)
+			})
+		}
+		skip := false
+		flush := func(tranche fs.DirEntries) (err error) {
+			var skipped bool
+			skipped, err = call(d.remote, tranche, nil)
+			skip = skip || skipped
+			return err
+		}
+		var tranche fs.DirEntries
+		for _, entry := range d.entries {
+			tranche = append(tranche, entry)
+			subDir := subDirs[entry.Remote()]
+			if subDir == nil {
+				continue
+			}
+			err := flush(tranche)
+			if err != nil {
+				return err
+			}
+			tranche = nil
+			if !skip {
+				err = visit(subDir)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if len(tranche) > 0 || len(d.entries) == 0 {
+			return flush(tranche)
+		}
+		return nil
+	}
+	return visit(start(path, maxLevel-1))
+}
+
 // DirTree is a map of directories to entries
 type DirTree map[string]fs.DirEntries
 
@@ -349,14 +454,14 @@ func (dt DirTree) String() string {
 	return out.String()
 }
 
-func walkRDirTree(f fs.Fs, startPath string, includeAll bool, maxLevel int, listR fs.ListRFn) (DirTree, error) {
+func walkRDirTree(ctx context.Context, f fs.Fs, startPath string, includeAll bool, maxLevel int, listR fs.ListRFn) (DirTree, error) {
 	dirs := make(DirTree)
 	// Entries can come in arbitrary order. We use toPrune to keep
 	// all directories to exclude later.
 	toPrune := make(map[string]bool)
-	includeDirectory := filter.Active.IncludeDirectory(f)
+	includeDirectory := filter.Active.IncludeDirectory(ctx, f)
 	var mu sync.Mutex
-	err := listR(startPath, func(entries fs.DirEntries) error {
+	err := listR(ctx, startPath, func(entries fs.DirEntries) error {
 		mu.Lock()
 		defer mu.Unlock()
 		for _, entry := range entries {
@@ -426,7 +531,7 @@ func walkRDirTree(f fs.Fs, startPath string, includeAll bool, maxLevel int, list
 }
 
 // Create a DirTree using List
-func walkNDirTree(f fs.Fs, path string, includeAll bool, maxLevel int, listDir listDirFunc) (DirTree, error) {
+func walkNDirTree(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int, listDir listDirFunc) (DirTree, error) {
 	dirs := make(DirTree)
 	fn := func(dirPath string, entries fs.DirEntries, err error) error {
 		if err == nil {
@@ -434,7 +539,7 @@ func walkNDirTree(f fs.Fs, path string, includeAll bool, maxLevel int, listDir l
 		}
 		return err
 	}
-	err := walk(f, path, includeAll, maxLevel, fn, listDir)
+	err := walk(ctx, f, path, includeAll, maxLevel, fn, listDir)
 	if err != nil {
 		return nil, err
 	}
@@ -453,15 +558,15 @@ func walkNDirTree(f fs.Fs, path string, includeAll bool, maxLevel int, listDir l
 // and f supports it and level > 1, or WalkN otherwise.
 //
 // NB (f, path) to be replaced by fs.Dir at some point
-func NewDirTree(f fs.Fs, path string, includeAll bool, maxLevel int) (DirTree, error) {
+func NewDirTree(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int) (DirTree, error) {
 	if ListR := f.Features().ListR; (maxLevel < 0 || maxLevel > 1) && fs.Config.UseListR && ListR != nil {
-		return walkRDirTree(f, path, includeAll, maxLevel, ListR)
+		return walkRDirTree(ctx, f, path, includeAll, maxLevel, ListR)
 	}
-	return walkNDirTree(f, path, includeAll, maxLevel, list.DirSorted)
+	return walkNDirTree(ctx, f, path, includeAll, maxLevel, list.DirSorted)
 }
 
-func walkR(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listR fs.ListRFn) error {
-	dirs, err := walkRDirTree(f, path, includeAll, maxLevel, listR)
+func walkR(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listR fs.ListRFn) error {
+	dirs, err := walkRDirTree(ctx, f, path, includeAll, maxLevel, listR)
 	if err != nil {
 		return err
 	}
@@ -495,8 +600,8 @@ func walkR(f fs.Fs, path string, includeAll bool, maxLevel int, fn Func, listR f
 }
 
 // GetAll runs Walk getting all the results
-func GetAll(f fs.Fs, path string, includeAll bool, maxLevel int) (objs []fs.Object, dirs []fs.Directory, err error) {
-	err = Walk(f, path, includeAll, maxLevel, func(dirPath string, entries fs.DirEntries, err error) error {
+func GetAll(ctx context.Context, f fs.Fs, path string, includeAll bool, maxLevel int) (objs []fs.Object, dirs []fs.Directory, err error) {
+	err = Walk(ctx, f, path, includeAll, maxLevel, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			return err
 		}
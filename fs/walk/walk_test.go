@@ -1,9 +1,11 @@
 package walk
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/filter"
@@ -65,7 +67,7 @@ func (ls *listDirs) SetLevel(maxLevel int) *listDirs {
 }
 
 // ListDir returns the expected listing for the directory
-func (ls *listDirs) ListDir(f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error) {
+func (ls *listDirs) ListDir(ctx context.Context, f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 	assert.Equal(ls.t, ls.fs, f)
@@ -86,7 +88,7 @@ func (ls *listDirs) ListDir(f fs.Fs, includeAll bool, dir string) (entries fs.Di
 }
 
 // ListR returns the expected listing for the directory using ListR
-func (ls *listDirs) ListR(dir string, callback fs.ListRCallback) (err error) {
+func (ls *listDirs) ListR(ctx context.Context, dir string, callback fs.ListRCallback) (err error) {
 	ls.mu.Lock()
 	defer ls.mu.Unlock()
 
@@ -147,14 +149,14 @@ func (ls *listDirs) WalkFn(dir string, entries fs.DirEntries, err error) error {
 
 // Walk does the walk and tests the expectations
 func (ls *listDirs) Walk() {
-	err := walk(nil, "", ls.includeAll, ls.maxLevel, ls.WalkFn, ls.ListDir)
+	err := walk(context.Background(), nil, "", ls.includeAll, ls.maxLevel, ls.WalkFn, ls.ListDir)
 	assert.Equal(ls.t, ls.finalError, err)
 	ls.IsFinished()
 }
 
 // WalkR does the walkR and tests the expectations
 func (ls *listDirs) WalkR() {
-	err := walkR(nil, "", ls.includeAll, ls.maxLevel, ls.WalkFn, ls.ListR)
+	err := walkR(context.Background(), nil, "", ls.includeAll, ls.maxLevel, ls.WalkFn, ls.ListR)
 	assert.Equal(ls.t, ls.finalError, err)
 	if ls.finalError == nil {
 		ls.IsFinished()
@@ -263,7 +265,7 @@ func TestWalkRLevelsNoRecursive10(t *testing.T) { testWalkLevels(t, 10).WalkR()
 
 func TestWalkNDirTree(t *testing.T) {
 	ls := testWalkLevels(t, -1)
-	entries, err := walkNDirTree(nil, "", ls.includeAll, ls.maxLevel, ls.ListDir)
+	entries, err := walkNDirTree(context.Background(), nil, "", ls.includeAll, ls.maxLevel, ls.ListDir)
 	require.NoError(t, err)
 	assert.Equal(t, `/
   A
@@ -361,6 +363,83 @@ func TestWalkRErrors(t *testing.T) { testWalkErrors(t).WalkR() }
 
 var errorBoom = errors.New("boom")
 
+func TestWalkSorted(t *testing.T) {
+	entries := map[string]fs.DirEntries{
+		"":    {mockobject.Object("a"), mockdir.New("b"), mockobject.Object("c"), mockdir.New("d")},
+		"b":   {mockdir.New("b/x"), mockobject.Object("b/y")},
+		"b/x": {},
+		"d":   {mockobject.Object("d/z")},
+	}
+	walkSorted := func(maxLevel int, skip string) (calls []string, err error) {
+		// copy entries as listings which are skipped may still be running
+		listings := map[string]fs.DirEntries{}
+		for dir, dirEntries := range entries {
+			listings[dir] = dirEntries
+		}
+		// list the directories given first slowest
+		listDir := func(ctx context.Context, f fs.Fs, includeAll bool, dir string) (fs.DirEntries, error) {
+			time.Sleep(time.Duration(10-len(dir)) * time.Millisecond)
+			result, ok := listings[dir]
+			if !ok {
+				return nil, fs.ErrorDirNotFound
+			}
+			return result, nil
+		}
+		err = walkSorted(context.Background(), nil, "", false, maxLevel, func(dir string, entries fs.DirEntries, err error) error {
+			call := fmt.Sprintf("%q:", dir)
+			for _, entry := range entries {
+				call += " " + entry.Remote()
+			}
+			calls = append(calls, call)
+			if dir == skip {
+				return ErrorSkipDir
+			}
+			return err
+		}, listDir)
+		return calls, err
+	}
+
+	calls, err := walkSorted(-1, "-")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`"": a b`,
+		`"b": b/x`,
+		`"b/x":`,
+		`"b": b/y`,
+		`"": c d`,
+		`"d": d/z`,
+	}, calls)
+
+	// without recursion the directory is passed in one go
+	calls, err = walkSorted(1, "-")
+	require.NoError(t, err)
+	assert.Equal(t, []string{`"": a b c d`}, calls)
+
+	// skipping b skips its subdirectories
+	calls, err = walkSorted(-1, "b")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`"": a b`,
+		`"b": b/x`,
+		`"b": b/y`,
+		`"": c d`,
+		`"d": d/z`,
+	}, calls)
+
+	// errors listing are passed to fn
+	delete(entries, "d")
+	calls, err = walkSorted(-1, "-")
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	assert.Equal(t, []string{
+		`"": a b`,
+		`"b": b/x`,
+		`"b/x":`,
+		`"b": b/y`,
+		`"": c d`,
+		`"d":`,
+	}, calls)
+}
+
 func makeTree(level int, terminalErrors bool) (listResults, errorMap) {
 	lr := listResults{}
 	em := errorMap{}
@@ -411,7 +490,7 @@ func TestWalkRMultiErrors(t *testing.T) { testWalkMultiErrors(t).Walk() }
 
 // a very simple listRcallback function
 func makeListRCallback(entries fs.DirEntries, err error) fs.ListRFn {
-	return func(dir string, callback fs.ListRCallback) error {
+	return func(ctx context.Context, dir string, callback fs.ListRCallback) error {
 		if err == nil {
 			err = callback(entries)
 		}
@@ -556,7 +635,7 @@ a/
   b/
 `, nil, "", 2},
 	} {
-		r, err := walkRDirTree(nil, test.root, true, test.level, makeListRCallback(test.entries, test.err))
+		r, err := walkRDirTree(context.Background(), nil, test.root, true, test.level, makeListRCallback(test.entries, test.err))
 		assert.Equal(t, test.err, err, fmt.Sprintf("%+v", test))
 		assert.Equal(t, test.want, r.String(), fmt.Sprintf("%+v", test))
 	}
@@ -627,7 +706,7 @@ b/c/d/
 `, nil, "", -1, "ign", true},
 	} {
 		filter.Active.Opt.ExcludeFile = test.excludeFile
-		r, err := walkRDirTree(nil, test.root, test.includeAll, test.level, makeListRCallback(test.entries, test.err))
+		r, err := walkRDirTree(context.Background(), nil, test.root, test.includeAll, test.level, makeListRCallback(test.entries, test.err))
 		assert.Equal(t, test.err, err, fmt.Sprintf("%+v", test))
 		assert.Equal(t, test.want, r.String(), fmt.Sprintf("%+v", test))
 	}
@@ -4,6 +4,7 @@ package fstests
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -60,7 +61,7 @@ func findObject(t *testing.T, f fs.Fs, Name string) fs.Object {
 	var obj fs.Object
 	var err error
 	for i := 1; i <= *fstest.ListRetries; i++ {
-		obj, err = f.NewObject(Name)
+		obj, err = f.NewObject(context.Background(), Name)
 		if err == nil {
 			break
 		}
@@ -83,7 +84,7 @@ again:
 
 	file.Size = int64(buf.Len())
 	obji := object.NewStaticObjectInfo(file.Path, file.ModTime, file.Size, true, nil, nil)
-	obj, err := f.Put(in, obji)
+	obj, err := f.Put(context.Background(), in, obji)
 	if err != nil {
 		// Retry if err returned a retry error
 		if fserrors.IsRetryError(err) && tries < maxTries {
@@ -116,7 +117,7 @@ func (er errorReader) Read(p []byte) (n int, err error) {
 // read the contents of an object as a string
 func readObject(t *testing.T, obj fs.Object, limit int64, options ...fs.OpenOption) string {
 	what := fmt.Sprintf("readObject(%q) limit=%d, options=%+v", obj, limit, options)
-	in, err := obj.Open(options...)
+	in, err := obj.Open(context.Background(), options...)
 	require.NoError(t, err, what)
 	var r io.Reader = in
 	if limit >= 0 {
@@ -229,7 +230,7 @@ func Run(t *testing.T, opt *Opt) {
 
 		skipIfNotOk(t)
 
-		err = remote.Mkdir("")
+		err = remote.Mkdir(context.Background(), "")
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{})
 	})
@@ -245,7 +246,8 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestFsName", func(t *testing.T) {
 		skipIfNotOk(t)
 		got := remote.Name()
-		want := remoteName
+		// the remote name may have a path after the ":"
+		want := remoteName[:strings.LastIndex(remoteName, ":")+1]
 		if isLocalRemote {
 			want = "local:"
 		}
@@ -268,14 +270,14 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsRmdirEmpty tests deleting an empty directory
 	t.Run("TestFsRmdirEmpty", func(t *testing.T) {
 		skipIfNotOk(t)
-		err := remote.Rmdir("")
+		err := remote.Rmdir(context.Background(), "")
 		require.NoError(t, err)
 	})
 
 	// TestFsRmdirNotFound tests deleting a non existent directory
 	t.Run("TestFsRmdirNotFound", func(t *testing.T) {
 		skipIfNotOk(t)
-		err := remote.Rmdir("")
+		err := remote.Rmdir(context.Background(), "")
 		assert.Error(t, err, "Expecting error on Rmdir non existent")
 	})
 
@@ -288,11 +290,11 @@ func Run(t *testing.T, opt *Opt) {
 		// (eg azure blob)
 		newFs(t)
 
-		err := remote.Mkdir("")
+		err := remote.Mkdir(context.Background(), "")
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{})
 
-		err = remote.Mkdir("")
+		err = remote.Mkdir(context.Background(), "")
 		require.NoError(t, err)
 	})
 
@@ -300,15 +302,15 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestFsMkdirRmdirSubdir", func(t *testing.T) {
 		skipIfNotOk(t)
 		dir := "dir/subdir"
-		err := operations.Mkdir(remote, dir)
+		err := operations.Mkdir(context.Background(), remote, dir)
 		require.NoError(t, err)
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{}, []string{"dir", "dir/subdir"}, fs.Config.ModifyWindow)
 
-		err = operations.Rmdir(remote, dir)
+		err = operations.Rmdir(context.Background(), remote, dir)
 		require.NoError(t, err)
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{}, []string{"dir"}, fs.Config.ModifyWindow)
 
-		err = operations.Rmdir(remote, "dir")
+		err = operations.Rmdir(context.Background(), remote, "dir")
 		require.NoError(t, err)
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{}, []string{}, fs.Config.ModifyWindow)
 	})
@@ -322,7 +324,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsListDirEmpty tests listing the directories from an empty directory
 	TestFsListDirEmpty := func(t *testing.T) {
 		skipIfNotOk(t)
-		objs, dirs, err := walk.GetAll(remote, "", true, 1)
+		objs, dirs, err := walk.GetAll(context.Background(), remote, "", true, 1)
 		require.NoError(t, err)
 		assert.Equal(t, []string{}, objsToNames(objs))
 		assert.Equal(t, []string{}, dirsToNames(dirs))
@@ -338,7 +340,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsListDirNotFound tests listing the directories from an empty directory
 	TestFsListDirNotFound := func(t *testing.T) {
 		skipIfNotOk(t)
-		objs, dirs, err := walk.GetAll(remote, "does not exist", true, 1)
+		objs, dirs, err := walk.GetAll(context.Background(), remote, "does not exist", true, 1)
 		if !remote.Features().CanHaveEmptyDirectories {
 			if err != fs.ErrorDirNotFound {
 				assert.NoError(t, err)
@@ -360,11 +362,11 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestFsNewObjectNotFound", func(t *testing.T) {
 		skipIfNotOk(t)
 		// Object in an existing directory
-		o, err := remote.NewObject("potato")
+		o, err := remote.NewObject(context.Background(), "potato")
 		assert.Nil(t, o)
 		assert.Equal(t, fs.ErrorObjectNotFound, err)
 		// Now try an object in a non existing directory
-		o, err = remote.NewObject("directory/not/found/potato")
+		o, err = remote.NewObject(context.Background(), "directory/not/found/potato")
 		assert.Nil(t, o)
 		assert.Equal(t, fs.ErrorObjectNotFound, err)
 	})
@@ -389,11 +391,11 @@ func Run(t *testing.T, opt *Opt) {
 		in := io.MultiReader(buf, er)
 
 		obji := object.NewStaticObjectInfo(file2.Path, file2.ModTime, 100, true, nil, nil)
-		_, err := remote.Put(in, obji)
+		_, err := remote.Put(context.Background(), in, obji)
 		// assert.Nil(t, obj) - FIXME some remotes return the object even on nil
 		assert.NotNil(t, err)
 
-		obj, err := remote.NewObject(file2.Path)
+		obj, err := remote.NewObject(context.Background(), file2.Path)
 		assert.Nil(t, obj)
 		assert.Equal(t, fs.ErrorObjectNotFound, err)
 	})
@@ -418,9 +420,9 @@ func Run(t *testing.T, opt *Opt) {
 		list := func(dir string, expectedDirNames, expectedObjNames []string) {
 			var objNames, dirNames []string
 			for i := 1; i <= *fstest.ListRetries; i++ {
-				objs, dirs, err := walk.GetAll(remote, dir, true, 1)
+				objs, dirs, err := walk.GetAll(context.Background(), remote, dir, true, 1)
 				if errors.Cause(err) == fs.ErrorDirNotFound {
-					objs, dirs, err = walk.GetAll(remote, fstest.WinPath(dir), true, 1)
+					objs, dirs, err = walk.GetAll(context.Background(), remote, fstest.WinPath(dir), true, 1)
 				}
 				require.NoError(t, err)
 				objNames = objsToNames(objs)
@@ -468,7 +470,7 @@ func Run(t *testing.T, opt *Opt) {
 		skipIfNotOk(t)
 		rootRemote, err := fs.NewFs(remoteName)
 		require.NoError(t, err)
-		_, dirs, err := walk.GetAll(rootRemote, "", true, 1)
+		_, dirs, err := walk.GetAll(context.Background(), rootRemote, "", true, 1)
 		require.NoError(t, err)
 		assert.Contains(t, dirsToNames(dirs), subRemoteLeaf, "Remote leaf not found")
 	}
@@ -490,7 +492,7 @@ func Run(t *testing.T, opt *Opt) {
 		for i := 0; i < 2; i++ {
 			dir, _ := path.Split(fileName)
 			dir = dir[:len(dir)-1]
-			objs, dirs, err = walk.GetAll(remote, dir, true, -1)
+			objs, dirs, err = walk.GetAll(context.Background(), remote, dir, true, -1)
 			if err != fs.ErrorDirNotFound {
 				break
 			}
@@ -512,7 +514,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsListLevel2 tests List works for 2 levels
 	TestFsListLevel2 := func(t *testing.T) {
 		skipIfNotOk(t)
-		objs, dirs, err := walk.GetAll(remote, "", true, 2)
+		objs, dirs, err := walk.GetAll(context.Background(), remote, "", true, 2)
 		if err == fs.ErrorLevelNotSupported {
 			return
 		}
@@ -551,7 +553,7 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestFsNewObjectDir", func(t *testing.T) {
 		skipIfNotOk(t)
 		dir := path.Dir(file2.Path)
-		obj, err := remote.NewObject(dir)
+		obj, err := remote.NewObject(context.Background(), dir)
 		assert.Nil(t, obj)
 		assert.NotNil(t, err)
 	})
@@ -572,7 +574,7 @@ func Run(t *testing.T, opt *Opt) {
 
 		// do the copy
 		src := findObject(t, remote, file2.Path)
-		dst, err := doCopy(src, file2Copy.Path)
+		dst, err := doCopy(context.Background(), src, file2Copy.Path)
 		if err == fs.ErrorCantCopy {
 			t.Skip("FS can't copy")
 		}
@@ -585,7 +587,7 @@ func Run(t *testing.T, opt *Opt) {
 		assert.Equal(t, file2Copy.Path, dst.Remote())
 
 		// Delete copy
-		err = dst.Remove()
+		err = dst.Remove(context.Background())
 		require.NoError(t, err)
 
 	})
@@ -612,7 +614,7 @@ func Run(t *testing.T, opt *Opt) {
 		file2Move.Path = "other.txt"
 		file2Move.WinPath = ""
 		src := findObject(t, remote, file2.Path)
-		dst, err := doMove(src, file2Move.Path)
+		dst, err := doMove(context.Background(), src, file2Move.Path)
 		if err == fs.ErrorCantMove {
 			t.Skip("FS can't move")
 		}
@@ -627,7 +629,7 @@ func Run(t *testing.T, opt *Opt) {
 		// Check conflict on "rename, then move"
 		file1Move.Path = "moveTest/other.txt"
 		src = findObject(t, remote, file1.Path)
-		_, err = doMove(src, file1Move.Path)
+		_, err = doMove(context.Background(), src, file1Move.Path)
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{file1Move, file2Move})
 		// 1: moveTest/other.txt
@@ -635,21 +637,21 @@ func Run(t *testing.T, opt *Opt) {
 
 		// Check conflict on "move, then rename"
 		src = findObject(t, remote, file1Move.Path)
-		_, err = doMove(src, file1.Path)
+		_, err = doMove(context.Background(), src, file1.Path)
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{file1, file2Move})
 		// 1: file name.txt
 		// 2: other.txt
 
 		src = findObject(t, remote, file2Move.Path)
-		_, err = doMove(src, file2.Path)
+		_, err = doMove(context.Background(), src, file2.Path)
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{file1, file2})
 		// 1: file name.txt
 		// 2: hello sausage?/../z.txt
 
 		// Tidy up moveTest directory
-		require.NoError(t, remote.Rmdir("moveTest"))
+		require.NoError(t, remote.Rmdir(context.Background(), "moveTest"))
 	})
 
 	// Move src to this remote using server side move operations.
@@ -673,7 +675,7 @@ func Run(t *testing.T, opt *Opt) {
 		}
 
 		// Check it can't move onto itself
-		err := doDirMove(remote, "", "")
+		err := doDirMove(context.Background(), remote, "", "")
 		require.Equal(t, fs.ErrorDirExists, err)
 
 		// new remote
@@ -683,12 +685,12 @@ func Run(t *testing.T, opt *Opt) {
 
 		const newName = "new_name/sub_new_name"
 		// try the move
-		err = newRemote.Features().DirMove(remote, "", newName)
+		err = newRemote.Features().DirMove(context.Background(), remote, "", newName)
 		require.NoError(t, err)
 
 		// check remotes
 		// remote should not exist here
-		_, err = remote.List("")
+		_, err = remote.List(context.Background(), "")
 		assert.Equal(t, fs.ErrorDirNotFound, errors.Cause(err))
 		//fstest.CheckListingWithPrecision(t, remote, []fstest.Item{}, []string{}, remote.Precision())
 		file1Copy := file1
@@ -706,7 +708,7 @@ func Run(t *testing.T, opt *Opt) {
 		}, newRemote.Precision())
 
 		// move it back
-		err = doDirMove(newRemote, newName, "")
+		err = doDirMove(context.Background(), newRemote, newName, "")
 		require.NoError(t, err)
 
 		// check remotes
@@ -724,7 +726,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsRmdirFull tests removing a non empty directory
 	t.Run("TestFsRmdirFull", func(t *testing.T) {
 		skipIfNotOk(t)
-		err := remote.Rmdir("")
+		err := remote.Rmdir(context.Background(), "")
 		require.Error(t, err, "Expecting error on RMdir on non empty remote")
 	})
 
@@ -754,7 +756,7 @@ func Run(t *testing.T, opt *Opt) {
 			t.Skip("FS has no ChangeNotify interface")
 		}
 
-		err := operations.Mkdir(remote, "dir")
+		err := operations.Mkdir(context.Background(), remote, "dir")
 		require.NoError(t, err)
 
 		dirChanges := []string{}
@@ -776,7 +778,7 @@ func Run(t *testing.T, opt *Opt) {
 		var dirs []string
 		for _, idx := range []int{1, 3, 2} {
 			dir := fmt.Sprintf("dir/subdir%d", idx)
-			err = operations.Mkdir(remote, dir)
+			err = operations.Mkdir(context.Background(), remote, dir)
 			require.NoError(t, err)
 			dirs = append(dirs, dir)
 		}
@@ -787,7 +789,7 @@ func Run(t *testing.T, opt *Opt) {
 		var objs []fs.Object
 		for _, idx := range []int{2, 4, 3} {
 			obji := object.NewStaticObjectInfo(fmt.Sprintf("dir/file%d", idx), time.Now(), int64(buf.Len()), true, nil, nil)
-			o, err := remote.Put(buf, obji)
+			o, err := remote.Put(context.Background(), buf, obji)
 			require.NoError(t, err)
 			objs = append(objs, o)
 		}
@@ -799,11 +801,11 @@ func Run(t *testing.T, opt *Opt) {
 
 		// tidy up afterwards
 		for _, o := range objs {
-			assert.NoError(t, o.Remove())
+			assert.NoError(t, o.Remove(context.Background()))
 		}
 		dirs = append(dirs, "dir")
 		for _, dir := range dirs {
-			assert.NoError(t, remote.Rmdir(dir))
+			assert.NoError(t, remote.Rmdir(context.Background(), dir))
 		}
 	})
 
@@ -872,7 +874,7 @@ func Run(t *testing.T, opt *Opt) {
 		skipIfNotOk(t)
 		newModTime := fstest.Time("2011-12-13T14:15:16.999999999Z")
 		obj := findObject(t, remote, file1.Path)
-		err := obj.SetModTime(newModTime)
+		err := obj.SetModTime(context.Background(), newModTime)
 		if err == fs.ErrorCantSetModTime || err == fs.ErrorCantSetModTimeWithoutDelete {
 			t.Log(err)
 			return
@@ -944,7 +946,7 @@ func Run(t *testing.T, opt *Opt) {
 		file1.Size = int64(buf.Len())
 		obj := findObject(t, remote, file1.Path)
 		obji := object.NewStaticObjectInfo(file1.Path, file1.ModTime, int64(len(contents)), true, nil, obj.Fs())
-		err := obj.Update(in, obji)
+		err := obj.Update(context.Background(), in, obji)
 		require.NoError(t, err)
 		file1.Hashes = hash.Sums()
 
@@ -999,34 +1001,34 @@ func Run(t *testing.T, opt *Opt) {
 		}
 
 		// if object not found
-		link, err := doPublicLink(file1.Path + "_does_not_exist")
+		link, err := doPublicLink(context.Background(), file1.Path+"_does_not_exist", fs.DurationOff, "")
 		require.Error(t, err, "Expected to get error when file doesn't exist")
 		require.Equal(t, "", link, "Expected link to be empty on error")
 
 		// sharing file for the first time
-		link1, err := doPublicLink(file1.Path)
+		link1, err := doPublicLink(context.Background(), file1.Path, fs.DurationOff, "")
 		require.NoError(t, err)
 		require.NotEqual(t, "", link1, "Link should not be empty")
 
-		link2, err := doPublicLink(file2.Path)
+		link2, err := doPublicLink(context.Background(), file2.Path, fs.DurationOff, "")
 		require.NoError(t, err)
 		require.NotEqual(t, "", link2, "Link should not be empty")
 
 		require.NotEqual(t, link1, link2, "Links to different files should differ")
 
 		// sharing file for the 2nd time
-		link1, err = doPublicLink(file1.Path)
+		link1, err = doPublicLink(context.Background(), file1.Path, fs.DurationOff, "")
 		require.NoError(t, err)
 		require.NotEqual(t, "", link1, "Link should not be empty")
 
 		// sharing directory for the first time
 		path := path.Dir(file2.Path)
-		link3, err := doPublicLink(path)
+		link3, err := doPublicLink(context.Background(), path, fs.DurationOff, "")
 		require.NoError(t, err)
 		require.NotEqual(t, "", link3, "Link should not be empty")
 
 		// sharing directory for the second time
-		link3, err = doPublicLink(path)
+		link3, err = doPublicLink(context.Background(), path, fs.DurationOff, "")
 		require.NoError(t, err)
 		require.NotEqual(t, "", link3, "Link should not be empty")
 
@@ -1037,10 +1039,10 @@ func Run(t *testing.T, opt *Opt) {
 		// ensure sub remote isn't empty
 		buf := bytes.NewBufferString("somecontent")
 		obji := object.NewStaticObjectInfo("somefile", time.Now(), int64(buf.Len()), true, nil, nil)
-		_, err = subRemote.Put(buf, obji)
+		_, err = subRemote.Put(context.Background(), buf, obji)
 		require.NoError(t, err)
 
-		link4, err := subRemote.Features().PublicLink("")
+		link4, err := subRemote.Features().PublicLink(context.Background(), "", fs.DurationOff, "")
 		require.NoError(t, err, "Sharing root in a sub-remote should work")
 		require.NotEqual(t, "", link4, "Link should not be empty")
 	})
@@ -1049,7 +1051,7 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestObjectRemove", func(t *testing.T) {
 		skipIfNotOk(t)
 		obj := findObject(t, remote, file1.Path)
-		err := obj.Remove()
+		err := obj.Remove(context.Background())
 		require.NoError(t, err)
 		// check listing without modtime as TestPublicLink may change the modtime
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{file2}, nil, fs.ModTimeNotSupported)
@@ -1079,7 +1081,7 @@ func Run(t *testing.T, opt *Opt) {
 
 		file.Size = -1
 		obji := object.NewStaticObjectInfo(file.Path, file.ModTime, file.Size, true, nil, nil)
-		obj, err := remote.Features().PutStream(in, obji)
+		obj, err := remote.Features().PutStream(context.Background(), in, obji)
 		if err != nil {
 			// Retry if err returned a retry error
 			if fserrors.IsRetryError(err) && tries < maxTries {
@@ -1110,7 +1112,7 @@ func Run(t *testing.T, opt *Opt) {
 		}
 
 		// Can't really check the output much!
-		usage, err := doAbout()
+		usage, err := doAbout(context.Background())
 		require.NoError(t, err)
 		require.NotNil(t, usage)
 		assert.NotEqual(t, int64(0), usage.Total)
@@ -1120,11 +1122,11 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestObjectPurge", func(t *testing.T) {
 		skipIfNotOk(t)
 
-		err := operations.Purge(remote, "")
+		err := operations.Purge(context.Background(), remote, "")
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{})
 
-		err = operations.Purge(remote, "")
+		err = operations.Purge(context.Background(), remote, "")
 		assert.Error(t, err, "Expecting error after on second purge")
 	})
 
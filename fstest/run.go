@@ -26,6 +26,7 @@ package fstest
 
 import (
 	"bytes"
+	"context"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -139,7 +140,7 @@ func NewRun(t *testing.T) *Run {
 		*r = *oneRun
 		r.cleanRemote = func() {
 			var toDelete []string
-			err := walk.Walk(r.Fremote, "", true, -1, func(dirPath string, entries fs.DirEntries, err error) error {
+			err := walk.Walk(context.Background(), r.Fremote, "", true, -1, func(dirPath string, entries fs.DirEntries, err error) error {
 				if err != nil {
 					if err == fs.ErrorDirNotFound {
 						return nil
@@ -149,7 +150,7 @@ func NewRun(t *testing.T) *Run {
 				for _, entry := range entries {
 					switch x := entry.(type) {
 					case fs.Object:
-						retry(t, fmt.Sprintf("removing file %q", x.Remote()), x.Remove)
+						retry(t, fmt.Sprintf("removing file %q", x.Remote()), func() error { return x.Remove(context.Background()) })
 					case fs.Directory:
 						toDelete = append(toDelete, x.Remote())
 					}
@@ -164,7 +165,7 @@ func NewRun(t *testing.T) *Run {
 			for i := len(toDelete) - 1; i >= 0; i-- {
 				dir := toDelete[i]
 				retry(t, fmt.Sprintf("removing dir %q", dir), func() error {
-					return r.Fremote.Rmdir(dir)
+					return r.Fremote.Rmdir(context.Background(), dir)
 				})
 			}
 			// Check remote is empty
@@ -213,7 +214,7 @@ func (r *Run) WriteFile(filePath, content string, t time.Time) Item {
 
 // ForceMkdir creates the remote
 func (r *Run) ForceMkdir(f fs.Fs) {
-	err := f.Mkdir("")
+	err := f.Mkdir(context.Background(), "")
 	if err != nil {
 		r.Fatalf("Failed to mkdir %q: %v", f, err)
 	}
@@ -241,7 +242,7 @@ func (r *Run) WriteObjectTo(f fs.Fs, remote, content string, modTime time.Time,
 	for tries := 1; ; tries++ {
 		in := bytes.NewBufferString(content)
 		objinfo := object.NewStaticObjectInfo(remote, modTime, int64(len(content)), true, nil, nil)
-		_, err := put(in, objinfo)
+		_, err := put(context.Background(), in, objinfo)
 		if err == nil {
 			break
 		}
@@ -283,7 +284,7 @@ func (r *Run) CheckWithDuplicates(t *testing.T, items ...Item) {
 	sort.Strings(want)
 
 	// do the listing
-	objs, _, err := walk.GetAll(r.Fremote, "", true, -1)
+	objs, _, err := walk.GetAll(context.Background(), r.Fremote, "", true, -1)
 	if err != nil && err != fs.ErrorDirNotFound {
 		t.Fatalf("Error listing: %v", err)
 	}
@@ -0,0 +1,220 @@
+// Package archive streams zip and tar archives of the contents of
+// remotes without staging them on local disk
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/walk"
+	"github.com/pkg/errors"
+)
+
+// Archive formats
+const (
+	Zip   = "zip"
+	Tar   = "tar"
+	TarGz = "tar.gz"
+)
+
+// Formats is the list of supported archive formats
+var Formats = []string{Zip, Tar, TarGz}
+
+// CheckFormat returns an error if format isn't supported
+func CheckFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return errors.Errorf("unknown archive format %q, expecting one of %s", format, strings.Join(Formats, ", "))
+}
+
+// MimeType returns the mime type of an archive in format
+func MimeType(format string) string {
+	switch format {
+	case Zip:
+		return "application/zip"
+	case Tar:
+		return "application/x-tar"
+	case TarGz:
+		return "application/gzip"
+	}
+	return "application/octet-stream"
+}
+
+// writer writes the entries of an archive
+type writer interface {
+	// addDir adds a directory to the archive
+	addDir(name string, modTime time.Time) error
+	// addFile adds a file of size to the archive reading the contents from in
+	addFile(name string, size int64, modTime time.Time, in io.Reader) error
+	// Close finishes the archive - it doesn't close the output
+	Close() error
+}
+
+// zipWriter writes a zip archive
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) addDir(name string, modTime time.Time) error {
+	header := &zip.FileHeader{
+		Name: name + "/",
+	}
+	header.SetModTime(modTime)
+	header.SetMode(0755 | os.ModeDir)
+	_, err := w.zw.CreateHeader(header)
+	return err
+}
+
+func (w *zipWriter) addFile(name string, size int64, modTime time.Time, in io.Reader) error {
+	header := &zip.FileHeader{
+		Name:   name,
+		Method: zip.Deflate,
+	}
+	header.SetModTime(modTime)
+	header.SetMode(0644)
+	out, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
+
+// tarWriter writes a tar archive, optionally gzipped
+type tarWriter struct {
+	tw *tar.Writer
+	gz *gzip.Writer // nil if not compressing
+}
+
+func (w *tarWriter) addDir(name string, modTime time.Time) error {
+	return w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     0755,
+		ModTime:  modTime,
+	})
+}
+
+func (w *tarWriter) addFile(name string, size int64, modTime time.Time, in io.Reader) error {
+	if size < 0 {
+		return errors.New("can't add a file of unknown size to a tar archive")
+	}
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     size,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w.tw, in)
+	return err
+}
+
+func (w *tarWriter) Close() error {
+	err := w.tw.Close()
+	if w.gz != nil {
+		gzErr := w.gz.Close()
+		if err == nil {
+			err = gzErr
+		}
+	}
+	return err
+}
+
+// newWriter makes a writer for format which writes to out
+func newWriter(format string, out io.Writer) (writer, error) {
+	switch format {
+	case Zip:
+		return &zipWriter{zw: zip.NewWriter(out)}, nil
+	case Tar:
+		return &tarWriter{tw: tar.NewWriter(out)}, nil
+	case TarGz:
+		gz := gzip.NewWriter(out)
+		return &tarWriter{tw: tar.NewWriter(gz), gz: gz}, nil
+	}
+	return nil, CheckFormat(format)
+}
+
+// addObject adds the contents of o to the archive as name
+func addObject(ctx context.Context, w writer, name string, o fs.Object) (err error) {
+	accounting.Stats.Transferring(o.Remote())
+	defer func() {
+		accounting.Stats.DoneTransferring(o.Remote(), err == nil)
+	}()
+	in, err := accounting.Open(ctx, o)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %q", o.Remote())
+	}
+	in = in.WithBuffer() // buffer the transfer
+	defer fs.CheckClose(in, &err)
+	err = w.addFile(name, o.Size(), o.ModTime(), in)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add %q to archive", o.Remote())
+	}
+	return nil
+}
+
+// Create writes an archive in format of everything in dir on f to
+// out.  The paths in the archive are relative to dir.
+//
+// The filters are applied to the files and directories as they are
+// listed.
+func Create(ctx context.Context, f fs.Fs, dir string, format string, out io.Writer) (err error) {
+	w, err := newWriter(format, out)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := w.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+	return walk.Walk(ctx, f, dir, false, -1, func(dirPath string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			name := strings.TrimPrefix(entry.Remote(), dir)
+			name = strings.TrimPrefix(name, "/")
+			switch x := entry.(type) {
+			case fs.Directory:
+				err = w.addDir(name, x.ModTime())
+			case fs.Object:
+				err = addObject(ctx, w, name, x)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Name returns the file name for an archive of dir in format
+func Name(dir string, format string) string {
+	leaf := path.Base(dir)
+	if dir == "" || leaf == "/" || leaf == "." {
+		leaf = "archive"
+	}
+	return leaf + "." + format
+}
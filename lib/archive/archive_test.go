@@ -0,0 +1,103 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/ncw/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeFs makes a local Fs with some files in for testing
+func makeFs(t *testing.T) (f fs.Fs, cleanup func()) {
+	dir, err := ioutil.TempDir("", "rclone-archive-test")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "top", "sub"), 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "top", "a.txt"), []byte("aaa"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "top", "sub", "b.txt"), []byte("bbbbb"), 0666))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0666))
+	f, err = fs.NewFs(dir)
+	require.NoError(t, err)
+	return f, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+var wantFiles = map[string]string{
+	"a.txt":     "aaa",
+	"sub/":      "",
+	"sub/b.txt": "bbbbb",
+}
+
+func TestCreateZip(t *testing.T) {
+	f, cleanup := makeFs(t)
+	defer cleanup()
+
+	var buf bytes.Buffer
+	require.NoError(t, Create(context.Background(), f, "top", Zip, &buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	got := map[string]string{}
+	for _, file := range zr.File {
+		in, err := file.Open()
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(in)
+		require.NoError(t, err)
+		require.NoError(t, in.Close())
+		got[file.Name] = string(data)
+	}
+	assert.Equal(t, wantFiles, got)
+}
+
+func TestCreateTar(t *testing.T) {
+	f, cleanup := makeFs(t)
+	defer cleanup()
+
+	for _, format := range []string{Tar, TarGz} {
+		var buf bytes.Buffer
+		require.NoError(t, Create(context.Background(), f, "top", format, &buf))
+
+		var in io.Reader = &buf
+		if format == TarGz {
+			gz, err := gzip.NewReader(in)
+			require.NoError(t, err)
+			in = gz
+		}
+		tr := tar.NewReader(in)
+		got := map[string]string{}
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			data, err := ioutil.ReadAll(tr)
+			require.NoError(t, err)
+			got[header.Name] = string(data)
+		}
+		assert.Equal(t, wantFiles, got, format)
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	assert.NoError(t, CheckFormat(Zip))
+	assert.NoError(t, CheckFormat(TarGz))
+	assert.Error(t, CheckFormat("rar"))
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "archive.zip", Name("", Zip))
+	assert.Equal(t, "top.tar.gz", Name("top", TarGz))
+	assert.Equal(t, "sub.tar", Name("top/sub", Tar))
+}
@@ -0,0 +1,236 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/object"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
+)
+
+// FormatFromName returns the archive format of the file name or ""
+// if it isn't recognised
+func FormatFromName(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return Zip
+	case strings.HasSuffix(name, ".tar"):
+		return Tar
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return TarGz
+	}
+	return ""
+}
+
+// readAtChunk is the size of the range requests used to read zip
+// archives
+const readAtChunk = 1024 * 1024
+
+// objectReaderAt reads an object at random offsets using range
+// requests of readAtChunk bytes, keeping the last chunk read to serve
+// the small reads the zip reader makes from it.
+type objectReaderAt struct {
+	ctx       context.Context
+	o         fs.Object
+	mu        sync.Mutex
+	buf       []byte // the last chunk read
+	bufOffset int64  // the offset of buf in the object
+}
+
+// ReadAt reads len(p) bytes from the object at off
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for len(p) > 0 {
+		if off >= r.o.Size() {
+			return n, io.EOF
+		}
+		if off < r.bufOffset || off >= r.bufOffset+int64(len(r.buf)) {
+			err = r.fill(off)
+			if err != nil {
+				return n, err
+			}
+		}
+		copied := copy(p, r.buf[off-r.bufOffset:])
+		p = p[copied:]
+		n += copied
+		off += int64(copied)
+	}
+	return n, nil
+}
+
+// fill reads the chunk starting at off into the buffer
+func (r *objectReaderAt) fill(off int64) (err error) {
+	end := off + readAtChunk
+	if end > r.o.Size() {
+		end = r.o.Size()
+	}
+	in, err := accounting.Open(r.ctx, r.o, &fs.RangeOption{Start: off, End: end - 1})
+	if err != nil {
+		return errors.Wrap(err, "failed to open archive")
+	}
+	defer fs.CheckClose(in, &err)
+	buf := make([]byte, end-off)
+	_, err = io.ReadFull(in, buf)
+	if err != nil {
+		return errors.Wrap(err, "failed to read archive")
+	}
+	r.buf, r.bufOffset = buf, off
+	return nil
+}
+
+// cleanName returns the name of an archive entry as a path which
+// can't escape the directory it is extracted to, or "" if it is the
+// directory itself
+func cleanName(name string) string {
+	name = path.Clean("/" + strings.Replace(name, "\\", "/", -1))
+	return strings.TrimPrefix(name, "/")
+}
+
+// extractor extracts archive entries to a directory of an Fs
+type extractor struct {
+	ctx  context.Context
+	fdst fs.Fs
+	dir  string
+}
+
+// addDir makes the directory name
+//
+// If filters are in use directories are only made by extracting
+// files into them.
+func (e *extractor) addDir(name string) error {
+	name = cleanName(name)
+	if name == "" || !filter.Active.InActive() {
+		return nil
+	}
+	return operations.Mkdir(e.ctx, e.fdst, path.Join(e.dir, name))
+}
+
+// addFile uploads the file name of size read from in
+func (e *extractor) addFile(name string, size int64, modTime time.Time, in io.Reader) (err error) {
+	name = cleanName(name)
+	if name == "" || !filter.Active.Include(name, size, modTime) {
+		return nil
+	}
+	remote := path.Join(e.dir, name)
+	if fs.Config.DryRun {
+		fs.Logf(remote, "Not extracting as --dry-run")
+		return nil
+	}
+	accounting.Stats.Transferring(remote)
+	defer func() {
+		accounting.Stats.DoneTransferring(remote, err == nil)
+	}()
+	acc := accounting.NewAccountSizeName(ioutil.NopCloser(in), size, remote).WithBuffer() // account and buffer the transfer
+	defer fs.CheckClose(acc, &err)
+	src := object.NewStaticObjectInfo(remote, modTime, size, true, nil, e.fdst)
+	_, err = e.fdst.Put(e.ctx, acc, src)
+	if err != nil {
+		return errors.Wrapf(err, "failed to extract %q", name)
+	}
+	return nil
+}
+
+// extractZip extracts the zip archive in src
+func (e *extractor) extractZip(src fs.Object) error {
+	zr, err := zip.NewReader(&objectReaderAt{ctx: e.ctx, o: src}, src.Size())
+	if err != nil {
+		return errors.Wrap(err, "failed to read zip archive")
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			err = e.addDir(file.Name)
+		} else {
+			err = e.extractZipFile(file)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractZipFile extracts a single file from a zip archive
+func (e *extractor) extractZipFile(file *zip.File) (err error) {
+	in, err := file.Open()
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %q in zip archive", file.Name)
+	}
+	defer fs.CheckClose(in, &err)
+	return e.addFile(file.Name, int64(file.UncompressedSize64), file.ModTime(), in)
+}
+
+// extractTar extracts the tar archive read from in
+func (e *extractor) extractTar(in io.Reader) error {
+	tr := tar.NewReader(in)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read tar archive")
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = e.addDir(header.Name)
+		case tar.TypeReg, tar.TypeRegA:
+			err = e.addFile(header.Name, header.Size, header.ModTime, tr)
+		default:
+			fs.Logf(header.Name, "Not extracting as it isn't a file or a directory")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Extract extracts the archive src, which is in format, into dir on
+// fdst without storing it on local disk.
+//
+// Zip archives are read with range requests as the index is at the
+// end, tar archives are streamed.  Reading the archive is accounted
+// so it shows in the stats and obeys --bwlimit.  The filters are
+// applied to the paths in the archive.
+func Extract(ctx context.Context, fdst fs.Fs, dir string, src fs.Object, format string) (err error) {
+	e := &extractor{
+		ctx:  ctx,
+		fdst: fdst,
+		dir:  dir,
+	}
+	if format == Zip {
+		return e.extractZip(src)
+	}
+	err = CheckFormat(format)
+	if err != nil {
+		return err
+	}
+	in, err := accounting.Open(ctx, src)
+	if err != nil {
+		return errors.Wrap(err, "failed to open archive")
+	}
+	defer fs.CheckClose(in, &err)
+	if format == TarGz {
+		var gz *gzip.Reader
+		gz, err = gzip.NewReader(in)
+		if err != nil {
+			return errors.Wrap(err, "failed to read gzip archive")
+		}
+		defer fs.CheckClose(gz, &err)
+		return e.extractTar(gz)
+	}
+	return e.extractTar(in)
+}
@@ -0,0 +1,175 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFromName(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"a.zip", Zip},
+		{"a.ZIP", Zip},
+		{"a.tar", Tar},
+		{"a.tar.gz", TarGz},
+		{"a.tgz", TarGz},
+		{"a.gz", ""},
+		{"a", ""},
+	} {
+		assert.Equal(t, test.want, FormatFromName(test.name), test.name)
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"a/b.txt", "a/b.txt"},
+		{"a/", "a"},
+		{"/a/b.txt", "a/b.txt"},
+		{"../../a.txt", "a.txt"},
+		{"a/../../b.txt", "b.txt"},
+		{`a\b.txt`, "a/b.txt"},
+		{".", ""},
+		{"/", ""},
+	} {
+		assert.Equal(t, test.want, cleanName(test.name), test.name)
+	}
+}
+
+// localObject writes data to name in a temporary directory and
+// returns it as an Object
+func localObject(t *testing.T, name string, data []byte) (o fs.Object, cleanup func()) {
+	dir, err := ioutil.TempDir("", "rclone-archive-test")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0666))
+	f, err := fs.NewFs(dir)
+	require.NoError(t, err)
+	o, err = f.NewObject(context.Background(), name)
+	require.NoError(t, err)
+	return o, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func TestObjectReaderAt(t *testing.T) {
+	data := make([]byte, 3*readAtChunk+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	o, cleanup := localObject(t, "data.bin", data)
+	defer cleanup()
+	r := &objectReaderAt{
+		ctx: context.Background(),
+		o:   o,
+	}
+	for _, test := range []struct {
+		off int64
+		n   int
+	}{
+		{0, 10},
+		{5, 10},
+		{readAtChunk - 5, 10},
+		{0, 2*readAtChunk + 7},
+		{int64(len(data)) - 10, 10},
+	} {
+		p := make([]byte, test.n)
+		n, err := r.ReadAt(p, test.off)
+		require.NoError(t, err)
+		assert.Equal(t, test.n, n)
+		assert.Equal(t, data[test.off:test.off+int64(test.n)], p)
+	}
+	p := make([]byte, 20)
+	n, err := r.ReadAt(p, int64(len(data))-10)
+	assert.Equal(t, 10, n)
+	assert.Error(t, err)
+}
+
+// readDir returns the files found in dir and their contents
+func readDir(t *testing.T, dir string) map[string]string {
+	got := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		rel, err := filepath.Rel(dir, path)
+		require.NoError(t, err)
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if rel != "." {
+				got[rel+"/"] = ""
+			}
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		got[rel] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+	return got
+}
+
+func TestExtract(t *testing.T) {
+	ctx := context.Background()
+	fsrc, cleanup := makeFs(t)
+	defer cleanup()
+
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		require.NoError(t, Create(ctx, fsrc, "top", format, &buf))
+		src, cleanupSrc := localObject(t, "archive."+format, buf.Bytes())
+
+		dir, err := ioutil.TempDir("", "rclone-extract-test")
+		require.NoError(t, err)
+		fdst, err := fs.NewFs(dir)
+		require.NoError(t, err)
+		require.NoError(t, Extract(ctx, fdst, "out", src, format))
+		assert.Equal(t, map[string]string{
+			"out/":          "",
+			"out/a.txt":     "aaa",
+			"out/sub/":      "",
+			"out/sub/b.txt": "bbbbb",
+		}, readDir(t, dir), format)
+		require.NoError(t, os.RemoveAll(dir))
+		cleanupSrc()
+	}
+}
+
+func TestExtractUnsafePaths(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"../escape.txt", "/abs.txt"} {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: 2}))
+		_, err := tw.Write([]byte("hi"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	src, cleanupSrc := localObject(t, "archive.tar", buf.Bytes())
+	defer cleanupSrc()
+
+	dir, err := ioutil.TempDir("", "rclone-extract-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	fdst, err := fs.NewFs(filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	require.NoError(t, Extract(ctx, fdst, "", src, Tar))
+	assert.Equal(t, map[string]string{
+		"out/":           "",
+		"out/abs.txt":    "hi",
+		"out/escape.txt": "hi",
+	}, readDir(t, dir))
+}
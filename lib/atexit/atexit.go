@@ -16,19 +16,44 @@ var (
 	fns          []func()
 	exitOnce     sync.Once
 	registerOnce sync.Once
+	interruptMu  sync.Mutex
+	interrupt    func() // called on the first signal instead of exiting if set
 )
 
 // Register a function to be called on exit
 func Register(fn func()) {
 	fns = append(fns, fn)
-	// Run AtExit handlers on SIGINT or SIGTERM so everything gets
-	// tidied up properly
+	startSignalHandler()
+}
+
+// OnInterrupt sets fn to be called on the first SIGINT instead of
+// exiting, so the program can stop what it is doing and exit
+// normally.  A second SIGINT runs the exit functions and exits.
+func OnInterrupt(fn func()) {
+	interruptMu.Lock()
+	interrupt = fn
+	interruptMu.Unlock()
+	startSignalHandler()
+}
+
+// startSignalHandler runs the AtExit handlers on SIGINT or SIGTERM so
+// everything gets tidied up properly
+func startSignalHandler() {
 	registerOnce.Do(func() {
 		go func() {
 			ch := make(chan os.Signal, 1)
 			signal.Notify(ch, os.Interrupt) // syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT
 			sig := <-ch
 			fs.Infof(nil, "Signal received: %s", sig)
+			interruptMu.Lock()
+			fn := interrupt
+			interruptMu.Unlock()
+			if fn != nil {
+				fs.Logf(nil, "Stopping - interrupt again to exit immediately")
+				fn()
+				sig = <-ch
+				fs.Infof(nil, "Signal received: %s", sig)
+			}
 			Run()
 			fs.Infof(nil, "Exiting...")
 			os.Exit(0)
@@ -0,0 +1,192 @@
+// Package cron parses cron style schedules and works out when they
+// next run
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed cron schedule
+type Schedule struct {
+	minute, hour, dom, month, dow uint64 // bit set of the allowed values of each field
+	domAny, dowAny                bool   // set if the day fields were *
+	every                         time.Duration
+}
+
+// field describes one of the fields of a cron schedule
+type field struct {
+	name     string
+	min, max int
+	names    []string // names for the values starting at min, if any
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// shortcuts are the @ names for common schedules
+var shortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// value parses a single value of f which may be a number or a name
+func (f *field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, errors.Errorf("bad %s %q", f.name, s)
+	}
+	return n, nil
+}
+
+// parse parses a field which is a comma separated list of *, values
+// or ranges each optionally followed by /step
+func (f *field) parse(s string) (bits uint64, err error) {
+	for _, part := range strings.Split(s, ",") {
+		start, end, step := f.min, f.max, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return 0, errors.Errorf("bad step in %s %q", f.name, part)
+			}
+			part = part[:i]
+		}
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			start, err = f.value(bounds[0])
+			if err != nil {
+				return 0, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = f.value(bounds[1])
+				if err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				end = f.max
+			}
+			if end < start {
+				return 0, errors.Errorf("bad range in %s %q", f.name, part)
+			}
+		}
+		for i := start; i <= end; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// Parse parses a schedule.  This is either the 5 standard cron fields
+//
+//     minute hour day-of-month month day-of-week
+//
+// one of the shortcuts @yearly, @monthly, @weekly, @daily, @hourly or
+// "@every DURATION" to run at fixed intervals, eg "@every 1h30m".
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad schedule %q", spec)
+		}
+		if every < time.Second {
+			return nil, errors.Errorf("bad schedule %q: interval must be at least 1s", spec)
+		}
+		return &Schedule{every: every}, nil
+	}
+	if expanded, ok := shortcuts[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, errors.Errorf("bad schedule %q: need %d fields", spec, len(fields))
+	}
+	var bits [5]uint64
+	for i := range fields {
+		var err error
+		bits[i], err = fields[i].parse(parts[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad schedule %q", spec)
+		}
+	}
+	// Sunday can be 0 or 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return &Schedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// has returns whether bit n is set in bits
+func has(bits uint64, n int) bool {
+	return bits&(1<<uint(n)) != 0
+}
+
+// dayMatches returns whether the schedule runs on the day of t.  As
+// in cron if both the day of month and day of week are restricted a
+// day matching either will do.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domOK := has(s.dom, t.Day())
+	dowOK := has(s.dow, int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// Next returns the first time the schedule runs after t, or the zero
+// time if it never does.
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Add(s.every)
+	}
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	// Give up after 5 years so impossible dates like 31 Feb end
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
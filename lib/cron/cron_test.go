@@ -0,0 +1,58 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * potato *",
+		"@every potato",
+		"@every 1ms",
+	} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNext(t *testing.T) {
+	// a Wednesday
+	now := time.Date(2019, 1, 2, 10, 30, 15, 0, time.UTC)
+	for _, test := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2019, 1, 2, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 1, 2, 10, 45, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2019, 1, 2, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2019, 1, 2, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2019, 1, 3, 2, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2019, 1, 2, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * sat,sun", time.Date(2019, 1, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 5", time.Date(2019, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jun *", time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+		{"@every 90m", now.Add(90 * time.Minute)},
+	} {
+		s, err := Parse(test.spec)
+		require.NoError(t, err, test.spec)
+		assert.Equal(t, test.want, s.Next(now), test.spec)
+	}
+}
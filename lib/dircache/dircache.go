@@ -4,9 +4,11 @@ package dircache
 // _methods are called without the lock
 
 import (
+	"context"
 	"log"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/pkg/errors"
@@ -17,35 +19,74 @@ type DirCache struct {
 	cacheMu      sync.RWMutex
 	cache        map[string]string
 	invCache     map[string]string
-	mu           sync.Mutex
-	fs           DirCacher // Interface to find and make stuff
-	trueRootID   string    // ID of the absolute root
-	root         string    // the path we are working on
-	rootID       string    // ID of the root directory
-	rootParentID string    // ID of the root's parent directory
-	foundRoot    bool      // Whether we have found the root or not
+	notFound     map[string]time.Time // paths not found and when to forget them
+	notFoundTime time.Duration        // how long to remember paths not found
+	lookupMu     sync.Mutex
+	lookups      map[string]*lookup // lookups in progress by path
+	workers      chan struct{}      // tokens for calling FindLeaf and CreateDir
+	cacheRoot    string             // path of the root of the cache keys from the true root
+	store        *store             // directory IDs saved between runs if set
+	name         string             // name of the remote, set by Persist
+	forgotten    int32              // how many of forgets have been checked
+	mu           sync.RWMutex       // held for writing when changing the root
+	fs           DirCacher          // Interface to find and make stuff
+	trueRootID   string             // ID of the absolute root
+	root         string             // the path we are working on
+	rootID       string             // ID of the root directory
+	rootParentID string             // ID of the root's parent directory
+	foundRoot    bool               // Whether we have found the root or not
 }
 
 // DirCacher describes an interface for doing the low level directory work
 type DirCacher interface {
-	FindLeaf(pathID, leaf string) (pathIDOut string, found bool, err error)
-	CreateDir(pathID, leaf string) (newID string, err error)
+	FindLeaf(ctx context.Context, pathID, leaf string) (pathIDOut string, found bool, err error)
+	CreateDir(ctx context.Context, pathID, leaf string) (newID string, err error)
+}
+
+// lookup is a directory lookup in progress which other lookups of
+// the same path wait for
+type lookup struct {
+	done   chan struct{} // closed when the lookup is finished
+	create bool          // whether the lookup creates the directory
+	pathID string
+	err    error
 }
 
 // New makes a DirCache
 //
-// The cache is safe for concurrent use
-func New(root string, trueRootID string, fs DirCacher) *DirCache {
+// The cache is safe for concurrent use.  Up to --dircache-workers
+// calls to FindLeaf and CreateDir are made at once.
+func New(root string, trueRootID string, dirCacher DirCacher) *DirCache {
 	d := &DirCache{
 		trueRootID: trueRootID,
 		root:       root,
-		fs:         fs,
+		fs:         dirCacher,
+		lookups:    make(map[string]*lookup),
 	}
+	d.SetWorkers(fs.Config.DirCacheWorkers)
+	d.SetNotFoundTime(fs.Config.DirCacheNotFoundTime)
 	d.Flush()
 	d.ResetRoot()
 	return d
 }
 
+// SetWorkers sets the maximum number of calls to FindLeaf and
+// CreateDir which are made at once.  It should be called before the
+// DirCache is used.
+func (dc *DirCache) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	dc.workers = make(chan struct{}, n)
+}
+
+// SetNotFoundTime sets how long a directory which wasn't found is
+// remembered as not existing.  0 disables remembering them.  It
+// should be called before the DirCache is used.
+func (dc *DirCache) SetNotFoundTime(t time.Duration) {
+	dc.notFoundTime = t
+}
+
 // Get an ID given a path
 func (dc *DirCache) Get(path string) (id string, ok bool) {
 	dc.cacheMu.RLock()
@@ -63,18 +104,45 @@ func (dc *DirCache) GetInv(id string) (path string, ok bool) {
 }
 
 // Put a path, id into the map
+//
+// This forgets that path or any of its parents weren't found
 func (dc *DirCache) Put(path, id string) {
 	dc.cacheMu.Lock()
 	dc.cache[path] = id
 	dc.invCache[id] = path
+	for p := path; p != ""; p, _ = SplitPath(p) {
+		delete(dc.notFound, p)
+	}
+	if dc.store != nil {
+		dc.store.put(dc.absPath(path), id)
+	}
 	dc.cacheMu.Unlock()
 }
 
+// putNotFound remembers that path wasn't found
+func (dc *DirCache) putNotFound(path string) {
+	if dc.notFoundTime <= 0 {
+		return
+	}
+	dc.cacheMu.Lock()
+	dc.notFound[path] = time.Now().Add(dc.notFoundTime)
+	dc.cacheMu.Unlock()
+}
+
+// isNotFound returns true if path was recently not found
+func (dc *DirCache) isNotFound(path string) bool {
+	dc.cacheMu.RLock()
+	expires, ok := dc.notFound[path]
+	dc.cacheMu.RUnlock()
+	return ok && time.Now().Before(expires)
+}
+
 // Flush the map of all data
 func (dc *DirCache) Flush() {
 	dc.cacheMu.Lock()
 	dc.cache = make(map[string]string)
 	dc.invCache = make(map[string]string)
+	dc.notFound = make(map[string]time.Time)
 	dc.cacheMu.Unlock()
 }
 
@@ -95,6 +163,8 @@ func (dc *DirCache) FlushDir(dir string) {
 		delete(dc.invCache, ID)
 	}
 
+	delete(dc.notFound, dir)
+
 	// And any sub directories
 	dir += "/"
 	for key, ID := range dc.cache {
@@ -103,6 +173,15 @@ func (dc *DirCache) FlushDir(dir string) {
 			delete(dc.invCache, ID)
 		}
 	}
+	for key := range dc.notFound {
+		if strings.HasPrefix(key, dir) {
+			delete(dc.notFound, key)
+		}
+	}
+
+	if dc.store != nil {
+		dc.store.forget(dc.absPath(strings.TrimSuffix(dir, "/")))
+	}
 
 	dc.cacheMu.Unlock()
 }
@@ -135,10 +214,16 @@ func SplitPath(path string) (directory, leaf string) {
 //  Look in the cache for the path, if found return the pathID
 //  If not found strip the last path off the path and recurse
 //  Now have a parent directory id, so look in the parent for self and return it
-func (dc *DirCache) FindDir(path string, create bool) (pathID string, err error) {
-	dc.mu.Lock()
-	defer dc.mu.Unlock()
-	return dc._findDir(path, create)
+//
+// Lookups of different paths run in parallel but concurrent lookups
+// of the same path wait for the first one so directories are only
+// created once.  Once the root is found, paths which weren't found
+// are remembered for a short while so they aren't looked up again.
+func (dc *DirCache) FindDir(ctx context.Context, path string, create bool) (pathID string, err error) {
+	dc.checkForgotten()
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc._findDir(ctx, path, create)
 }
 
 // Look for the root and in the cache - safe to call without the mu
@@ -161,24 +246,77 @@ func (dc *DirCache) _findDirInCache(path string) string {
 }
 
 // Unlocked findDir - must have mu
-func (dc *DirCache) _findDir(path string, create bool) (pathID string, err error) {
+//
+// This waits for any lookup of path in progress or starts one
+func (dc *DirCache) _findDir(ctx context.Context, path string, create bool) (pathID string, err error) {
+	pathID = dc._findDirInCache(path)
+	if pathID != "" {
+		return pathID, nil
+	}
+	if !create && dc.isNotFound(path) {
+		return "", fs.ErrorDirNotFound
+	}
+
+	dc.lookupMu.Lock()
+	// Check the cache again as a lookup may have finished
 	pathID = dc._findDirInCache(path)
 	if pathID != "" {
+		dc.lookupMu.Unlock()
 		return pathID, nil
 	}
+	if l, ok := dc.lookups[path]; ok {
+		dc.lookupMu.Unlock()
+		<-l.done
+		if create && !l.create && l.err == fs.ErrorDirNotFound {
+			// The lookup didn't create the directory so try again
+			return dc._findDir(ctx, path, create)
+		}
+		return l.pathID, l.err
+	}
+	l := &lookup{
+		done:   make(chan struct{}),
+		create: create,
+	}
+	dc.lookups[path] = l
+	dc.lookupMu.Unlock()
+
+	l.pathID, l.err = dc._lookup(ctx, path, create)
+
+	dc.lookupMu.Lock()
+	delete(dc.lookups, path)
+	dc.lookupMu.Unlock()
+	close(l.done)
+	return l.pathID, l.err
+}
+
+// _lookup finds the directory ID of path which isn't in the cache
+// using FindLeaf and CreateDir - must have mu
+func (dc *DirCache) _lookup(ctx context.Context, path string, create bool) (pathID string, err error) {
+	// Try the directory ID saved by a previous run first
+	if dc.store != nil {
+		dc.workers <- struct{}{}
+		pathID, ok := dc._findPersisted(ctx, path)
+		<-dc.workers
+		if ok {
+			return pathID, nil
+		}
+	}
 
 	// Split the path into directory, leaf
 	directory, leaf := SplitPath(path)
 
 	// Recurse and find pathID for parent directory
-	parentPathID, err := dc._findDir(directory, create)
+	parentPathID, err := dc._findDir(ctx, directory, create)
 	if err != nil {
 		return "", err
 
 	}
 
+	dc.workers <- struct{}{}
+	defer func() { <-dc.workers }()
+
 	// Find the leaf in parentPathID
-	pathID, found, err := dc.fs.FindLeaf(parentPathID, leaf)
+	pathID, found, err := dc.fs.FindLeaf(ctx, parentPathID, leaf)
 	if err != nil {
 		return "", err
 	}
@@ -186,11 +324,16 @@ func (dc *DirCache) _findDir(path string, create bool) (pathID string, err error
 	// If not found create the directory if required or return an error
 	if !found {
 		if create {
-			pathID, err = dc.fs.CreateDir(parentPathID, leaf)
+			pathID, err = dc.fs.CreateDir(ctx, parentPathID, leaf)
 			if err != nil {
 				return "", errors.Wrap(err, "failed to make directory")
 			}
 		} else {
+			// Until the root is found the paths are from the
+			// true root so don't remember them
+			if dc.foundRoot {
+				dc.putNotFound(path)
+			}
 			return "", fs.ErrorDirNotFound
 		}
 	}
@@ -207,15 +350,16 @@ func (dc *DirCache) _findDir(path string, create bool) (pathID string, err error
 // Do not call FindPath with the root directory - it will return an error
 //
 // If create is set parent directories will be created if they don't exist
-func (dc *DirCache) FindPath(path string, create bool) (leaf, directoryID string, err error) {
+func (dc *DirCache) FindPath(ctx context.Context, path string, create bool) (leaf, directoryID string, err error) {
 	if path == "" {
 		err = errors.New("internal error: can't call FindPath with root directory")
 		return
 	}
-	dc.mu.Lock()
-	defer dc.mu.Unlock()
+	dc.checkForgotten()
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
 	directory, leaf := SplitPath(path)
-	directoryID, err = dc._findDir(directory, create)
+	directoryID, err = dc._findDir(ctx, directory, create)
 	return
 }
 
@@ -224,13 +368,14 @@ func (dc *DirCache) FindPath(path string, create bool) (leaf, directoryID string
 // Resets the root directory
 //
 // If create is set it will make the directory if not found
-func (dc *DirCache) FindRoot(create bool) error {
+func (dc *DirCache) FindRoot(ctx context.Context, create bool) error {
+	dc.checkForgotten()
 	dc.mu.Lock()
 	defer dc.mu.Unlock()
 	if dc.foundRoot {
 		return nil
 	}
-	rootID, err := dc._findDir(dc.root, create)
+	rootID, err := dc._findDir(ctx, dc.root, create)
 	if err != nil {
 		return err
 	}
@@ -244,6 +389,9 @@ func (dc *DirCache) FindRoot(create bool) error {
 
 	// Reset the tree based on dc.root
 	dc.Flush()
+	dc.cacheMu.Lock()
+	dc.cacheRoot = dc.root
+	dc.cacheMu.Unlock()
 	// Put the root directory in
 	dc.Put("", dc.rootID)
 	return nil
@@ -252,12 +400,12 @@ func (dc *DirCache) FindRoot(create bool) error {
 // FindRootAndPath finds the root first if not found then finds leaf and directoryID from a path
 //
 // If create is set parent directories will be created if they don't exist
-func (dc *DirCache) FindRootAndPath(path string, create bool) (leaf, directoryID string, err error) {
-	err = dc.FindRoot(create)
+func (dc *DirCache) FindRootAndPath(ctx context.Context, path string, create bool) (leaf, directoryID string, err error) {
+	err = dc.FindRoot(ctx, create)
 	if err != nil {
 		return
 	}
-	return dc.FindPath(path, create)
+	return dc.FindPath(ctx, path, create)
 }
 
 // FoundRoot returns whether the root directory has been found yet
@@ -271,8 +419,8 @@ func (dc *DirCache) FoundRoot() bool {
 //
 // This should be called after FindRoot
 func (dc *DirCache) RootID() string {
-	dc.mu.Lock()
-	defer dc.mu.Unlock()
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
 	if !dc.foundRoot {
 		log.Fatalf("Internal Error: RootID() called before FindRoot")
 	}
@@ -283,8 +431,8 @@ func (dc *DirCache) RootID() string {
 //
 // This should be called after FindRoot
 func (dc *DirCache) RootParentID() (string, error) {
-	dc.mu.Lock()
-	defer dc.mu.Unlock()
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
 	if !dc.foundRoot {
 		return "", errors.New("internal error: RootID() called before FindRoot")
 	}
@@ -304,6 +452,9 @@ func (dc *DirCache) ResetRoot() {
 	defer dc.mu.Unlock()
 	dc.foundRoot = false
 	dc.Flush()
+	dc.cacheMu.Lock()
+	dc.cacheRoot = ""
+	dc.cacheMu.Unlock()
 
 	// Put the true root in
 	dc.rootID = dc.trueRootID
@@ -0,0 +1,349 @@
+package dircache
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDirCacher is a DirCacher which keeps the directories in memory
+// and counts the calls made
+type testDirCacher struct {
+	mu          sync.Mutex
+	dirs        map[string]string // parentID/leaf -> ID
+	findLeafs   int
+	createDirs  int
+	running     int // calls in progress
+	maxRunning  int // maximum calls in progress seen
+	callLatency time.Duration
+}
+
+func newTestDirCacher() *testDirCacher {
+	return &testDirCacher{
+		dirs:        make(map[string]string),
+		callLatency: 10 * time.Millisecond,
+	}
+}
+
+// call records a call in progress - returns a function to finish it
+func (t *testDirCacher) call() func() {
+	t.mu.Lock()
+	t.running++
+	if t.running > t.maxRunning {
+		t.maxRunning = t.running
+	}
+	t.mu.Unlock()
+	time.Sleep(t.callLatency)
+	return func() {
+		t.mu.Lock()
+		t.running--
+		t.mu.Unlock()
+	}
+}
+
+func (t *testDirCacher) FindLeaf(ctx context.Context, pathID, leaf string) (pathIDOut string, found bool, err error) {
+	defer t.call()()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.findLeafs++
+	pathIDOut, found = t.dirs[pathID+"/"+leaf]
+	return pathIDOut, found, nil
+}
+
+func (t *testDirCacher) CreateDir(ctx context.Context, pathID, leaf string) (newID string, err error) {
+	defer t.call()()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.createDirs++
+	newID = fmt.Sprintf("id%d", t.createDirs)
+	t.dirs[pathID+"/"+leaf] = newID
+	return newID, nil
+}
+
+func TestFindDirCreateOnce(t *testing.T) {
+	ctx := context.Background()
+	tdc := newTestDirCacher()
+	dc := New("", "root", tdc)
+	dc.SetWorkers(4)
+
+	var wg sync.WaitGroup
+	ids := make([]string, 10)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id, err := dc.FindDir(ctx, "a/b/c", true)
+			assert.NoError(t, err)
+			ids[i] = id
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 3, tdc.createDirs)
+	for _, id := range ids {
+		assert.Equal(t, ids[0], id)
+	}
+	id, ok := dc.Get("a/b")
+	assert.True(t, ok)
+	assert.Equal(t, "id2", id)
+}
+
+func TestFindDirWorkers(t *testing.T) {
+	ctx := context.Background()
+	tdc := newTestDirCacher()
+	dc := New("", "root", tdc)
+	dc.SetWorkers(3)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := dc.FindDir(ctx, fmt.Sprintf("dir%d", i), true)
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, tdc.createDirs)
+	assert.True(t, tdc.maxRunning > 1, "expecting parallel calls got %d", tdc.maxRunning)
+	assert.True(t, tdc.maxRunning <= 3, "expecting at most 3 calls at once got %d", tdc.maxRunning)
+}
+
+func TestFindDirNotFound(t *testing.T) {
+	ctx := context.Background()
+	tdc := newTestDirCacher()
+	tdc.callLatency = 0
+	dc := New("", "root", tdc)
+	require.NoError(t, dc.FindRoot(ctx, false))
+
+	_, err := dc.FindDir(ctx, "a/b", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	assert.Equal(t, 1, tdc.findLeafs)
+
+	// Both the directory and its parent are remembered
+	_, err = dc.FindDir(ctx, "a/b", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	_, err = dc.FindDir(ctx, "a", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	assert.Equal(t, 1, tdc.findLeafs)
+
+	// Creating the directory forgets it wasn't found
+	_, err = dc.FindDir(ctx, "a/b", true)
+	require.NoError(t, err)
+	_, err = dc.FindDir(ctx, "a", false)
+	assert.NoError(t, err)
+
+	// FlushDir forgets it wasn't found
+	_, err = dc.FindDir(ctx, "a/c", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	tdc.dirs[tdc.dirs["root/a"]+"/c"] = "made elsewhere"
+	dc.FlushDir("a")
+	id, err := dc.FindDir(ctx, "a/c", false)
+	require.NoError(t, err)
+	assert.Equal(t, "made elsewhere", id)
+}
+
+func TestFindRootNotFoundNotRemembered(t *testing.T) {
+	ctx := context.Background()
+	tdc := newTestDirCacher()
+	tdc.callLatency = 0
+	dc := New("a", "root", tdc)
+
+	assert.Equal(t, fs.ErrorDirNotFound, dc.FindRoot(ctx, false))
+	tdc.dirs["root/a"] = "a"
+	require.NoError(t, dc.FindRoot(ctx, false))
+	assert.Equal(t, "a", dc.RootID())
+}
+
+func TestFindDirNotFoundTime(t *testing.T) {
+	ctx := context.Background()
+	tdc := newTestDirCacher()
+	tdc.callLatency = 0
+	dc := New("", "root", tdc)
+	dc.SetNotFoundTime(50 * time.Millisecond)
+	require.NoError(t, dc.FindRoot(ctx, false))
+
+	_, err := dc.FindDir(ctx, "a", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	tdc.dirs["root/a"] = "made elsewhere"
+	_, err = dc.FindDir(ctx, "a", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	assert.Equal(t, 1, tdc.findLeafs)
+
+	// Forgotten after the time is up
+	time.Sleep(100 * time.Millisecond)
+	id, err := dc.FindDir(ctx, "a", false)
+	require.NoError(t, err)
+	assert.Equal(t, "made elsewhere", id)
+	assert.Equal(t, 2, tdc.findLeafs)
+
+	// Not remembered at all if disabled
+	dc.SetNotFoundTime(0)
+	for i := 0; i < 2; i++ {
+		_, err = dc.FindDir(ctx, "b", false)
+		assert.Equal(t, fs.ErrorDirNotFound, err)
+	}
+	assert.Equal(t, 4, tdc.findLeafs)
+}
+
+func TestPersist(t *testing.T) {
+	ctx := context.Background()
+	oldCacheDir, oldPersist := config.CacheDir, fs.Config.DirCachePersist
+	defer func() {
+		config.CacheDir, fs.Config.DirCachePersist = oldCacheDir, oldPersist
+	}()
+	var err error
+	config.CacheDir, err = ioutil.TempDir("", "rclone-dircache-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(config.CacheDir))
+	}()
+	fs.Config.DirCachePersist = true
+
+	tdc := newTestDirCacher()
+	tdc.callLatency = 0
+	dc := New("root", "trueRoot", tdc)
+	dc.Persist("test")
+	_, err = dc.FindDir(ctx, "root/a/b/c", true)
+	require.NoError(t, err)
+	require.NoError(t, dc.FindRoot(ctx, false))
+	_, err = dc.FindDir(ctx, "d", true)
+	require.NoError(t, err)
+	require.NoError(t, dc.store.save())
+
+	// Pretend to be a new run of rclone
+	storesMu.Lock()
+	stores = make(map[string]*store)
+	storesMu.Unlock()
+
+	tdc.findLeafs = 0
+	dc = New("root", "trueRoot", tdc)
+	dc.Persist("test")
+	require.NoError(t, dc.FindRoot(ctx, false))
+	assert.Equal(t, 1, tdc.findLeafs)
+	id, err := dc.FindDir(ctx, "a/b/c", false)
+	require.NoError(t, err)
+	assert.Equal(t, tdc.dirs[tdc.dirs[tdc.dirs["id1/a"]+"/b"]+"/c"], id)
+	assert.Equal(t, 4, tdc.findLeafs)
+	_, err = dc.FindDir(ctx, "d", false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, tdc.findLeafs)
+
+	// A saved ID which is wrong is forgotten and looked up again
+	dc.FlushDir("a")
+	dc.store.put("root/a/x", "wrong")
+	dc.store.put("root/a", "wrong")
+	_, err = dc.FindDir(ctx, "a/b", false)
+	require.NoError(t, err)
+	id, _ = dc.store.get("root/a")
+	assert.Equal(t, tdc.dirs["id1/a"], id)
+	_, ok := dc.store.get("root/a/x")
+	assert.False(t, ok)
+
+	// A parent moved by something else isn't used even though the
+	// leaf is still in it
+	dc.Flush()
+	idA := tdc.dirs["id1/a"]
+	tdc.mu.Lock()
+	tdc.dirs[idA+"/moved"] = tdc.dirs[idA+"/b"]
+	delete(tdc.dirs, idA+"/b")
+	tdc.mu.Unlock()
+	_, err = dc.FindDir(ctx, "a/b/c", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	_, ok = dc.store.get("root/a/b")
+	assert.False(t, ok)
+	_, ok = dc.store.get("root/a/b/c")
+	assert.False(t, ok)
+	_, ok = dc.store.get("root/a")
+	assert.True(t, ok)
+}
+
+func TestForget(t *testing.T) {
+	ctx := context.Background()
+	tdc := newTestDirCacher()
+	tdc.callLatency = 0
+	dc := New("root", "trueRoot", tdc)
+	dc.Persist("forget-test")
+	other := New("", "trueRoot", tdc)
+	other.Persist("forget-other")
+	require.NoError(t, dc.FindRoot(ctx, true))
+	_, err := dc.FindDir(ctx, "a/b", true)
+	require.NoError(t, err)
+	_, err = dc.FindDir(ctx, "c", true)
+	require.NoError(t, err)
+	_, err = other.FindDir(ctx, "root/a", false)
+	require.NoError(t, err)
+
+	// Another client renames root/a
+	tdc.mu.Lock()
+	delete(tdc.dirs, tdc.dirs["id1/a"]+"/b")
+	tdc.mu.Unlock()
+
+	Forget("forget-test", "/root/a/")
+	tdc.findLeafs = 0
+	_, err = dc.FindDir(ctx, "a/b", false)
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+	assert.Equal(t, 2, tdc.findLeafs)
+	_, err = dc.FindDir(ctx, "c", false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, tdc.findLeafs)
+	_, ok := other.Get("root/a")
+	assert.True(t, ok, "other remote forgotten")
+
+	// Forgetting a parent of the root keeps the root
+	Forget("forget-test", "")
+	id, err := dc.FindDir(ctx, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, "id1", id)
+	_, err = dc.FindDir(ctx, "c", false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, tdc.findLeafs)
+}
+
+func TestUseDir(t *testing.T) {
+	ctx := context.Background()
+	tdc := newTestDirCacher()
+	tdc.callLatency = 0
+	dc := New("", "trueRoot", tdc)
+	require.NoError(t, dc.FindRoot(ctx, true))
+	oldID, err := dc.FindDir(ctx, "a", true)
+	require.NoError(t, err)
+
+	// Another client replaces a
+	tdc.mu.Lock()
+	tdc.dirs["trueRoot/a"] = "new"
+	tdc.mu.Unlock()
+
+	errStale := errors.New("stale")
+	stale := func(err error) bool { return err == errStale }
+	var used []string
+	err = dc.UseDir(ctx, "a", stale, func(pathID string) error {
+		used = append(used, pathID)
+		if pathID == oldID {
+			return errStale
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{oldID, "new"}, used)
+
+	// Other errors aren't retried
+	used = nil
+	err = dc.UseDir(ctx, "a", stale, func(pathID string) error {
+		used = append(used, pathID)
+		return errors.New("other")
+	})
+	assert.EqualError(t, err, "other")
+	assert.Equal(t, []string{"new"}, used)
+}
@@ -0,0 +1,166 @@
+// Forget directory IDs which have gone stale
+
+package dircache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/rc"
+	"github.com/pkg/errors"
+)
+
+// forgetting is a directory whose IDs should be forgotten
+type forgetting struct {
+	name string // name of the remote
+	dir  string // path from the true root of the remote
+}
+
+var (
+	forgetMu   sync.Mutex
+	forgets    []forgetting // every directory forgotten in order
+	forgetsLen int32        // len(forgets) read atomically
+)
+
+// Forget forgets the directory IDs of dir and everything below it in
+// all the DirCaches for the remote called name and in the IDs saved
+// for it with --dircache-persist.  dir is the path from the root of
+// the remote, "" for all of it.
+//
+// This is for use when the directories have been changed by something
+// other than this rclone, eg renamed with the web interface, so the
+// IDs cached are stale.  The directories are looked up again when
+// next used.  The root directory of a DirCache which has already been
+// found isn't looked up again.
+func Forget(name, dir string) {
+	dir = strings.Trim(dir, "/")
+	forgetMu.Lock()
+	forgets = append(forgets, forgetting{name: name, dir: dir})
+	atomic.StoreInt32(&forgetsLen, int32(len(forgets)))
+	forgetMu.Unlock()
+
+	storesMu.Lock()
+	for key, s := range stores {
+		if strings.HasPrefix(key, name+"\x00") {
+			s.forget(dir)
+		}
+	}
+	storesMu.Unlock()
+}
+
+// checkForgotten flushes the directories passed to Forget since it
+// was last called - call without mu held
+func (dc *DirCache) checkForgotten() {
+	if dc.name == "" || atomic.LoadInt32(&dc.forgotten) == atomic.LoadInt32(&forgetsLen) {
+		return
+	}
+	forgetMu.Lock()
+	todo := append([]forgetting(nil), forgets[atomic.LoadInt32(&dc.forgotten):]...)
+	atomic.StoreInt32(&dc.forgotten, int32(len(forgets)))
+	forgetMu.Unlock()
+	for _, item := range todo {
+		if item.name != dc.name {
+			continue
+		}
+		dc.cacheMu.RLock()
+		cacheRoot := dc.cacheRoot
+		dc.cacheMu.RUnlock()
+		switch {
+		case cacheRoot == "" && item.dir != "":
+			dc.FlushDir(item.dir)
+		case strings.HasPrefix(item.dir, cacheRoot+"/"):
+			dc.FlushDir(item.dir[len(cacheRoot)+1:])
+		case item.dir == "" || item.dir == cacheRoot || strings.HasPrefix(cacheRoot, item.dir+"/"):
+			// Flush everything apart from the root
+			dc.mu.RLock()
+			rootID := dc.rootID
+			dc.mu.RUnlock()
+			dc.Flush()
+			dc.Put("", rootID)
+		default:
+			continue
+		}
+		fs.Debugf(dc.name+":", "Forgot directory IDs for %q", item.dir)
+	}
+}
+
+// UseDir calls fn with the ID of the directory at path found with
+// FindDir.
+//
+// If fn returns an error which stale says means the directory with
+// that ID doesn't exist then the ID cached was probably made stale by
+// something other than this rclone renaming or deleting the
+// directory.  In that case path is flushed from the cache and fn is
+// called once more with the ID looked up again, so a stale ID costs
+// one extra call rather than failing until rclone is restarted.  Note
+// that fn may be called twice.
+func (dc *DirCache) UseDir(ctx context.Context, path string, stale func(error) bool, fn func(pathID string) error) error {
+	pathID, err := dc.FindDir(ctx, path, false)
+	if err != nil {
+		return err
+	}
+	err = fn(pathID)
+	if err == nil || path == "" || !stale(err) {
+		return err
+	}
+	fs.Debugf(nil, "Looking up directory %q again as ID %q is stale: %v", path, pathID, err)
+	dc.FlushDir(path)
+	pathID, err = dc.FindDir(ctx, path, false)
+	if err != nil {
+		return err
+	}
+	return fn(pathID)
+}
+
+// Remote control for forgetting directory IDs
+func init() {
+	rc.Add(rc.Call{
+		Path: "dircache/forget",
+		Fn: func(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+			forgotten := []string{}
+			for k, v := range in {
+				remote, ok := v.(string)
+				if !ok {
+					return out, errors.Errorf("value must be string %q=%v", k, v)
+				}
+				if !strings.HasPrefix(k, "fs") {
+					return out, errors.Errorf("unknown key %q", k)
+				}
+				parts := fs.Matcher.FindStringSubmatch(remote)
+				if parts == nil {
+					return out, errors.Errorf("%q must be remote:path", remote)
+				}
+				Forget(parts[1], parts[2])
+				forgotten = append(forgotten, remote)
+			}
+			if len(forgotten) == 0 {
+				return out, errors.New("need at least one fs=remote:path")
+			}
+			out = rc.Params{
+				"forgotten": forgotten,
+			}
+			return out, nil
+		},
+		Title: "Forget the directory IDs cached for remotes.",
+		Help: `
+Remotes which use IDs for directories, eg drive, box, onedrive and
+pcloud, cache the ID of each directory they look up.  If a directory
+is renamed or deleted by something other than this rclone then its
+cached ID is stale.  This forgets the IDs of the directories passed in
+and everything below them, including those saved with
+--dircache-persist, so they are looked up again when next used.
+
+Pass the directories in as fs=remote:path.  Any parameter key starting
+with fs will forget that directory, eg
+
+    rclone rc dircache/forget fs=drive:photos fs2=box:
+
+Unlike vfs/forget this works for all operations, not just mounts.
+When a mount is running call this before vfs/forget so the directories
+are read again with fresh IDs.
+`,
+	})
+}
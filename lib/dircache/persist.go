@@ -0,0 +1,246 @@
+// Persist the directory IDs between runs of rclone
+
+package dircache
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/lib/atexit"
+	"github.com/pkg/errors"
+)
+
+// store holds the directory IDs of a remote saved between runs.  It
+// is shared by all the DirCaches for that remote.
+//
+// The paths are from the true root of the remote.  The IDs aren't
+// trusted until they have been checked with FindLeaf.
+type store struct {
+	mu         sync.Mutex
+	file       string
+	dirty      bool
+	TrueRootID string            `json:"trueRootID"`
+	Dirs       map[string]string `json:"dirs"`
+}
+
+var (
+	storesMu sync.Mutex
+	stores   = make(map[string]*store) // by remote name and true root ID
+)
+
+// storeFile returns the file the directory IDs for the remote called
+// name are stored in
+func storeFile(name string) string {
+	name = strings.Replace(name, string(filepath.Separator), "_", -1)
+	return filepath.Join(config.CacheDir, "dircache", name+".json")
+}
+
+// getStore returns the store for the remote called name, loading it
+// from disk and arranging for it to be saved on exit if necessary
+func getStore(name, trueRootID string) *store {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	key := name + "\x00" + trueRootID
+	if s, ok := stores[key]; ok {
+		return s
+	}
+	s := &store{
+		file: storeFile(name),
+	}
+	data, err := ioutil.ReadFile(s.file)
+	if err == nil {
+		err = json.Unmarshal(data, s)
+		if err != nil {
+			fs.Errorf(name, "Ignoring corrupt directory cache %q: %v", s.file, err)
+		}
+	} else if !os.IsNotExist(err) {
+		fs.Errorf(name, "Failed to read directory cache: %v", err)
+	}
+	if err != nil || s.TrueRootID != trueRootID {
+		s.Dirs = nil
+	}
+	if s.Dirs == nil {
+		s.Dirs = make(map[string]string)
+	}
+	s.TrueRootID = trueRootID
+	fs.Debugf(name, "Loaded %d directory IDs from %q", len(s.Dirs), s.file)
+	atexit.Register(func() {
+		err := s.save()
+		if err != nil {
+			fs.Errorf(name, "Failed to save directory cache: %v", err)
+		}
+	})
+	stores[key] = s
+	return s
+}
+
+// save writes the store to disk if it has changed
+func (s *store) save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(s.file), 0700)
+	if err != nil {
+		return err
+	}
+	tmp := s.file + ".tmp"
+	err = ioutil.WriteFile(tmp, data, 0600)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, s.file)
+	if err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// get returns the ID saved for path
+func (s *store) get(path string) (id string, ok bool) {
+	s.mu.Lock()
+	id, ok = s.Dirs[path]
+	s.mu.Unlock()
+	return id, ok
+}
+
+// put saves the ID of path
+func (s *store) put(path, id string) {
+	s.mu.Lock()
+	if s.Dirs[path] != id {
+		s.Dirs[path] = id
+		s.dirty = true
+	}
+	s.mu.Unlock()
+}
+
+// forget removes path and any paths below it, or all paths if path
+// is ""
+func (s *store) forget(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := dir + "/"
+	for key := range s.Dirs {
+		if dir == "" || key == dir || strings.HasPrefix(key, prefix) {
+			delete(s.Dirs, key)
+			s.dirty = true
+		}
+	}
+}
+
+// Persist loads the directory IDs for the remote called name saved by
+// previous runs of rclone and saves them again when rclone exits, if
+// --dircache-persist is set.
+//
+// The saved IDs are checked with FindLeaf before they are used, with
+// the levels of a deep directory checked in parallel, so it can be
+// found in the time of one call rather than one per level.
+//
+// It also records name so Forget can find the DirCache, so it should
+// be called even if --dircache-persist isn't set, before the DirCache
+// is used.
+func (dc *DirCache) Persist(name string) {
+	dc.name = name
+	if !fs.Config.DirCachePersist {
+		return
+	}
+	dc.store = getStore(name, dc.trueRootID)
+}
+
+// absPath returns path from the true root - call with cacheMu held
+func (dc *DirCache) absPath(relPath string) string {
+	if dc.cacheRoot == "" {
+		return relPath
+	}
+	if relPath == "" {
+		return dc.cacheRoot
+	}
+	return path.Join(dc.cacheRoot, relPath)
+}
+
+// persistedLevel is a level of a path being looked up with saved IDs
+type persistedLevel struct {
+	path     string // path relative to the root of the DirCache
+	absPath  string // path from the true root
+	leaf     string // leaf name of path
+	parentID string // ID of the parent directory
+	id       string // saved ID of path
+}
+
+// _findPersisted looks up path using the IDs saved by a previous run.
+//
+// The saved IDs are used for path and each of its parents up to the
+// deepest one in the cache.  Each level is checked with FindLeaf to be
+// in the level above, with the calls made in parallel, so a directory
+// moved by something other than rclone isn't used.  If they are all
+// correct they are put in the cache, otherwise the saved IDs of the
+// first incorrect level and everything below it are forgotten.
+//
+// Call with mu held and a worker token
+func (dc *DirCache) _findPersisted(ctx context.Context, path string) (pathID string, ok bool) {
+	var levels []persistedLevel // from path up to the deepest parent in the cache
+	for p := path; ; {
+		if p == "" {
+			return "", false
+		}
+		directory, leaf := SplitPath(p)
+		dc.cacheMu.RLock()
+		absPath := dc.absPath(p)
+		dc.cacheMu.RUnlock()
+		savedID, ok := dc.store.get(absPath)
+		if !ok {
+			return "", false
+		}
+		levels = append(levels, persistedLevel{path: p, absPath: absPath, leaf: leaf, id: savedID})
+		if parentID := dc._findDirInCache(directory); parentID != "" {
+			levels[len(levels)-1].parentID = parentID
+			break
+		}
+		p = directory
+	}
+	for i := 0; i < len(levels)-1; i++ {
+		levels[i].parentID = levels[i+1].id
+	}
+
+	// Check each level is still in its parent
+	errs := make([]error, len(levels))
+	var wg sync.WaitGroup
+	for i := range levels {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			l := &levels[i]
+			pathID, found, err := dc.fs.FindLeaf(ctx, l.parentID, l.leaf)
+			if err == nil && (!found || pathID != l.id) {
+				err = errors.New("directory ID has changed")
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	for i := len(levels) - 1; i >= 0; i-- {
+		if errs[i] != nil {
+			fs.Debugf(nil, "Forgetting saved directory IDs for %q: %v", levels[i].absPath, errs[i])
+			dc.store.forget(levels[i].absPath)
+			return "", false
+		}
+	}
+	for i := len(levels) - 1; i >= 0; i-- {
+		dc.Put(levels[i].path, levels[i].id)
+	}
+	return levels[0].id, true
+}
@@ -0,0 +1,224 @@
+// Package encoder maps characters in file names which are reserved on
+// a remote to replacements and back again.
+//
+// An encoding is described by a comma separated list of items, eg
+//
+//	\,*,?=_,:=%
+//
+// Each item is one of
+//
+//	X     - replace X with its FULLWIDTH unicode equivalent
+//	X=Y   - replace X with the character Y
+//	X=%   - replace X with its percent encoding, eg %3A
+//	none  - no replacements
+//
+// The "/" character can't be mapped as it is the path separator and
+// "," can't be mapped as it is the list separator.
+//
+// Names which already contain a replacement character, eg "_" above,
+// have it quoted with QuoteRune so it isn't changed when decoded.
+package encoder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/pkg/errors"
+)
+
+// ConfigEncoding is the config key for the encoding of a remote
+const ConfigEncoding = "encoding"
+
+// QuoteRune is put before replacement characters which are in a name
+// already so they decode to themselves rather than the character they
+// replace
+const QuoteRune = '‛' // SINGLE HIGH-REVERSED-9 QUOTATION MARK
+
+// Encoder maps reserved characters in file names to replacements
+type Encoder struct {
+	spec    string
+	replace map[rune]rune // characters to replace with another character
+	restore map[rune]rune // inverse of replace
+	percent map[rune]bool // characters to percent encode
+}
+
+// fullWidth returns the FULLWIDTH equivalent of c
+func fullWidth(c rune) (rune, error) {
+	switch {
+	case c == ' ':
+		return '␠', nil // SYMBOL FOR SPACE
+	case c > ' ' && c <= '~':
+		return c - '!' + '！', nil
+	}
+	return 0, errors.Errorf("no FULLWIDTH equivalent for %q - use %c=Y", c, c)
+}
+
+// Parse makes an Encoder from the spec passed in
+func Parse(spec string) (*Encoder, error) {
+	e := &Encoder{
+		spec:    spec,
+		replace: make(map[rune]rune),
+		restore: make(map[rune]rune),
+		percent: make(map[rune]bool),
+	}
+	if spec == "" || spec == "none" {
+		return e, nil
+	}
+	for _, item := range strings.Split(spec, ",") {
+		runes := []rune(item)
+		if len(runes) == 0 {
+			return nil, errors.Errorf("empty item in encoding %q", spec)
+		}
+		c := runes[0]
+		if c == '/' || c == QuoteRune {
+			return nil, errors.Errorf("can't encode %q in encoding %q", c, spec)
+		}
+		if _, found := e.replace[c]; found || e.percent[c] {
+			return nil, errors.Errorf("%q is encoded twice in encoding %q", c, spec)
+		}
+		var replacement rune
+		switch {
+		case len(runes) == 1:
+			var err error
+			replacement, err = fullWidth(c)
+			if err != nil {
+				return nil, err
+			}
+		case len(runes) == 3 && runes[1] == '=' && runes[2] == '%':
+			e.percent[c] = true
+			continue
+		case len(runes) == 3 && runes[1] == '=':
+			replacement = runes[2]
+		default:
+			return nil, errors.Errorf("bad item %q in encoding %q", item, spec)
+		}
+		if replacement == '/' || replacement == c || replacement == QuoteRune {
+			return nil, errors.Errorf("bad replacement %q for %q in encoding %q", replacement, c, spec)
+		}
+		if _, found := e.restore[replacement]; found {
+			return nil, errors.Errorf("%q is used as a replacement twice in encoding %q", replacement, spec)
+		}
+		e.replace[c] = replacement
+		e.restore[replacement] = c
+	}
+	if len(e.percent) > 0 {
+		// % needs escaping so it can be decoded unambiguously
+		if _, found := e.replace['%']; found {
+			return nil, errors.Errorf("can't replace %% and percent encode in encoding %q", spec)
+		}
+		e.percent['%'] = true
+	}
+	return e, nil
+}
+
+// MustParse parses spec and panics on error - use for static encodings
+func MustParse(spec string) *Encoder {
+	e, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// ForRemote returns the Encoder for the remote called name.
+//
+// This is read from the "encoding" key in the config for the remote
+// if set, otherwise from --backend-encoding if set, otherwise
+// defaultSpec is used.
+func ForRemote(name, defaultSpec string) (*Encoder, error) {
+	spec := fs.Config.BackendEncoding
+	if spec == "" {
+		spec = defaultSpec
+	}
+	spec = config.FileGet(name, ConfigEncoding, spec)
+	e, err := Parse(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: bad %s", name, ConfigEncoding)
+	}
+	return e, nil
+}
+
+// String returns the spec the Encoder was made from
+func (e *Encoder) String() string {
+	return e.spec
+}
+
+// Encode substitutes any reserved characters in the path in
+func (e *Encoder) Encode(in string) string {
+	if len(e.replace) == 0 && len(e.percent) == 0 {
+		return in
+	}
+	var out bytes.Buffer
+	for _, c := range in {
+		if replacement, ok := e.replace[c]; ok {
+			out.WriteRune(replacement)
+		} else if _, ok := e.restore[c]; ok || (c == QuoteRune && len(e.restore) > 0) {
+			// quote characters which would be decoded
+			out.WriteRune(QuoteRune)
+			out.WriteRune(c)
+		} else if e.percent[c] {
+			var buf [utf8.UTFMax]byte
+			n := utf8.EncodeRune(buf[:], c)
+			for _, b := range buf[:n] {
+				fmt.Fprintf(&out, "%%%02X", b)
+			}
+		} else {
+			out.WriteRune(c)
+		}
+	}
+	return out.String()
+}
+
+// unhex converts a hex digit into its value returning false if not
+// valid
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// Decode undoes any substitutions made by Encode
+func (e *Encoder) Decode(in string) string {
+	if len(e.restore) == 0 && len(e.percent) == 0 {
+		return in
+	}
+	var out bytes.Buffer
+	for i := 0; i < len(in); {
+		if in[i] == '%' && len(e.percent) > 0 && i+2 < len(in) {
+			hi, ok1 := unhex(in[i+1])
+			lo, ok2 := unhex(in[i+2])
+			if ok1 && ok2 {
+				out.WriteByte(hi<<4 | lo)
+				i += 3
+				continue
+			}
+		}
+		c, n := utf8.DecodeRuneInString(in[i:])
+		i += n
+		if len(e.restore) == 0 {
+			out.WriteString(in[i-n : i])
+			continue
+		}
+		if c == QuoteRune && i < len(in) {
+			// a quoted character stands for itself
+			_, n = utf8.DecodeRuneInString(in[i:])
+			out.WriteString(in[i : i+n])
+			i += n
+		} else if original, ok := e.restore[c]; ok {
+			out.WriteRune(original)
+		} else {
+			out.WriteString(in[i-n : i])
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,70 @@
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		spec    string
+		wantErr bool
+	}{
+		{"", false},
+		{"none", false},
+		{`\`, false},
+		{`\,*,?=_,:=%`, false},
+		{" ", false},
+		{"/", true},
+		{"a=/", true},
+		{"a=a", true},
+		{"a,,b", true},
+		{"a,a", true},
+		{"a=%,a", true},
+		{"a=x,b=x", true},
+		{"ab", true},
+		{"a=bc", true},
+		{"é", true},
+		{"%=x,a=%", true},
+		{"‛", true},
+		{"a=‛", true},
+	} {
+		_, err := Parse(test.spec)
+		assert.Equal(t, test.wantErr, err != nil, test.spec)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, test := range []struct {
+		spec string
+		in   string
+		want string
+	}{
+		{"", `a\b:c`, `a\b:c`},
+		{"none", `a\b:c`, `a\b:c`},
+		{`\`, `dir/a\b`, `dir/a＼b`},
+		{`*,?`, `what?*`, `what？＊`},
+		{" ", ` a b`, `␠a␠b`},
+		{`?=_`, `what?`, `what_`},
+		{`:=%`, `a:b%c`, `a%3Ab%25c`},
+		{`é=%`, `café`, `caf%C3%A9`},
+		{`\,:=%`, `a\b:c`, `a＼b%3Ac`},
+		{`?=_`, `a_b?`, `a‛_b_`},
+		{`?=_`, `‛_`, `‛‛‛_`},
+		{`?`, `？?`, `‛？？`},
+		{`?=_,:=%`, `_:%3A‛`, `‛_%3A%253A‛‛`},
+		{`:=%`, `‛`, `‛`},
+	} {
+		e, err := Parse(test.spec)
+		require.NoError(t, err, test.spec)
+		got := e.Encode(test.in)
+		assert.Equal(t, test.want, got, test.spec)
+		assert.Equal(t, test.in, e.Decode(got), test.spec)
+	}
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, `\,:=%`, MustParse(`\,:=%`).String())
+}
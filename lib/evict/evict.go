@@ -0,0 +1,82 @@
+// Package evict chooses which items to remove from a local cache.
+//
+// It is shared by the cache backend and the VFS cache so they both
+// evict in the same way: least recently used first, never touching
+// pinned items or items in use, until the cache is under its maximum
+// size and the disk it is on has the minimum free space.
+package evict
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Item is an entry in a cache which could be evicted
+type Item struct {
+	Name  string    // path of the item relative to the cache root
+	Size  int64     // size of the item in bytes
+	ATime time.Time // last time the item was accessed
+	InUse bool      // set if the item is in use and can't be evicted
+}
+
+// byATime sorts items by access time, oldest first
+type byATime []Item
+
+func (s byATime) Len() int           { return len(s) }
+func (s byATime) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byATime) Less(i, j int) bool { return s[i].ATime.Before(s[j].ATime) }
+
+// Policy describes when items should be evicted from a cache
+type Policy struct {
+	MaxSize      int64    // max total size of the cache or <= 0 for no limit
+	MinFreeSpace int64    // evict until the disk has this much free or <= 0 for no limit
+	Pin          []string // glob patterns of items which are never evicted
+}
+
+// IsPinned returns true if name or any of its parent directories
+// matches one of the Pin patterns
+func (p *Policy) IsPinned(name string) bool {
+	name = strings.Trim(name, "/")
+	for _, pattern := range p.Pin {
+		pattern = strings.Trim(pattern, "/")
+		for dir := name; dir != "." && dir != ""; dir = path.Dir(dir) {
+			if ok, _ := path.Match(pattern, dir); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Choose returns the items which should be evicted, least recently
+// accessed first.
+//
+// free is the space available on the disk holding the cache, or < 0
+// if it isn't known in which case MinFreeSpace is ignored.
+func (p *Policy) Choose(items []Item, free int64) (evict []Item) {
+	var total int64
+	candidates := make([]Item, 0, len(items))
+	for _, item := range items {
+		total += item.Size
+		if item.InUse || p.IsPinned(item.Name) {
+			continue
+		}
+		candidates = append(candidates, item)
+	}
+	sort.Stable(byATime(candidates))
+	for _, item := range candidates {
+		overSize := p.MaxSize > 0 && total > p.MaxSize
+		lowSpace := p.MinFreeSpace > 0 && free >= 0 && free < p.MinFreeSpace
+		if !overSize && !lowSpace {
+			break
+		}
+		evict = append(evict, item)
+		total -= item.Size
+		if free >= 0 {
+			free += item.Size
+		}
+	}
+	return evict
+}
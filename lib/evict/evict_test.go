@@ -0,0 +1,65 @@
+package evict
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func names(items []Item) (out []string) {
+	for _, item := range items {
+		out = append(out, item.Name)
+	}
+	return out
+}
+
+func TestIsPinned(t *testing.T) {
+	p := Policy{Pin: []string{"keep/*", "*.iso", "/dir"}}
+	for _, test := range []struct {
+		name string
+		want bool
+	}{
+		{"file.txt", false},
+		{"keep", false},
+		{"keep/file.txt", true},
+		{"keep/sub/file.txt", true},
+		{"image.iso", true},
+		{"sub/image.iso", false},
+		{"dir", true},
+		{"dir/file.txt", true},
+		{"/dir/file.txt", true},
+		{"dir2/file.txt", false},
+	} {
+		assert.Equal(t, test.want, p.IsPinned(test.name), test.name)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	t0 := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []Item{
+		{Name: "c", Size: 100, ATime: t0.Add(3 * time.Second)},
+		{Name: "a", Size: 100, ATime: t0.Add(1 * time.Second)},
+		{Name: "pinned", Size: 100, ATime: t0},
+		{Name: "open", Size: 100, ATime: t0, InUse: true},
+		{Name: "b", Size: 100, ATime: t0.Add(2 * time.Second)},
+	}
+	for _, test := range []struct {
+		what   string
+		policy Policy
+		free   int64
+		want   []string
+	}{
+		{"no limits", Policy{}, 0, nil},
+		{"under max size", Policy{MaxSize: 500}, -1, nil},
+		{"over max size", Policy{MaxSize: 350}, -1, []string{"pinned", "a"}},
+		{"max size pinned", Policy{MaxSize: 250, Pin: []string{"pinned"}}, -1, []string{"a", "b", "c"}},
+		{"enough free", Policy{MinFreeSpace: 1000}, 1000, nil},
+		{"low free", Policy{MinFreeSpace: 1000}, 850, []string{"pinned", "a"}},
+		{"free unknown", Policy{MinFreeSpace: 1000}, -1, nil},
+		{"both", Policy{MaxSize: 400, MinFreeSpace: 1000}, 750, []string{"pinned", "a", "b"}},
+	} {
+		got := names(test.policy.Choose(items, test.free))
+		assert.Equal(t, test.want, got, test.what)
+	}
+}
@@ -0,0 +1,13 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!windows
+
+package evict
+
+import "github.com/pkg/errors"
+
+// FreeSpace returns the number of bytes available to this user on
+// the disk holding path
+//
+// It isn't supported on this platform so always returns an error.
+func FreeSpace(path string) (int64, error) {
+	return -1, errors.New("reading free disk space not supported on this platform")
+}
@@ -0,0 +1,20 @@
+// +build darwin dragonfly freebsd linux
+
+package evict
+
+import (
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// FreeSpace returns the number of bytes available to this user on
+// the disk holding path
+func FreeSpace(path string) (int64, error) {
+	var s syscall.Statfs_t
+	err := syscall.Statfs(path, &s)
+	if err != nil {
+		return -1, errors.Wrap(err, "failed to read disk usage")
+	}
+	return int64(s.Bsize) * int64(s.Bavail), nil
+}
@@ -0,0 +1,205 @@
+// Package filelock implements locks shared between processes using
+// lock files in a directory.
+//
+// A lock is a file created exclusively in the directory.  The holder
+// of the lock touches the file regularly so a lock left behind by a
+// process which was killed goes stale and is taken over.
+//
+// The directory can be on a network file system to share the locks
+// between machines, provided their clocks are roughly in sync.
+package filelock
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// Timings of the locks - variables for the tests
+var (
+	pollInterval    = time.Second      // how often to look to see if a lock has been released
+	refreshInterval = 10 * time.Second // how often the holder touches the lock file
+	staleAge        = time.Minute      // lock files older than this are taken over
+)
+
+// Lock is a lock held on a key
+type Lock struct {
+	path  string        // path of the lock file
+	token string        // content of the lock file which shows we own it
+	stop  chan struct{} // close to stop refreshing the lock
+	done  chan struct{} // closed when the refresh has stopped
+}
+
+// lockPath returns the path of the lock file for key in dir
+func lockPath(dir, key string) string {
+	hash := sha1.Sum([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(hash[:])+".lock")
+}
+
+// create tries to create the lock file at path containing token,
+// returning true if it was created
+func create(path, token string) (bool, error) {
+	fd, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = fd.WriteString(token)
+	closeErr := fd.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return false, err
+	}
+	return true, nil
+}
+
+// removeStale removes the lock file at path if it hasn't been touched
+// for staleAge
+func removeStale(path string) error {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if time.Since(fi.ModTime()) < staleAge {
+		return nil
+	}
+	fs.Logf(nil, "Removing stale lock %q", path)
+	return removeIfSame(path, fi)
+}
+
+// removeIfSame removes the lock file at path if it is still the one
+// described by fi.
+//
+// Another process may have removed the stale lock and made a new one
+// since fi was read, so the lock is first moved out of the way, which
+// only one process can do, then removed only if it is the same file
+// with the same modification time.  Otherwise it is put back.
+func removeIfSame(path string, fi os.FileInfo) error {
+	stalePath := fmt.Sprintf("%s.stale.%d", path, os.Getpid())
+	err := os.Rename(path, stalePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	staleFi, err := os.Stat(stalePath)
+	if err == nil && os.SameFile(fi, staleFi) && fi.ModTime().Equal(staleFi.ModTime()) {
+		return os.Remove(stalePath)
+	}
+	// It was a new lock - put it back unless yet another has been made
+	err = os.Link(stalePath, path)
+	removeErr := os.Remove(stalePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to restore lock")
+	}
+	return removeErr
+}
+
+// Acquire takes the lock on key in dir, waiting for any other process
+// holding it to release it first.
+//
+// It returns waited true if it had to wait for the lock.  It returns
+// an error if the lock couldn't be created or ctx was cancelled while
+// waiting.
+func Acquire(ctx context.Context, dir, key string) (lock *Lock, waited bool, err error) {
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to make lock directory")
+	}
+	return AcquirePath(ctx, lockPath(dir, key), key)
+}
+
+// AcquirePath takes the lock on key using the lock file at path, in
+// the same way as Acquire.
+func AcquirePath(ctx context.Context, path, key string) (lock *Lock, waited bool, err error) {
+	host, _ := os.Hostname()
+	token := fmt.Sprintf("%s\npid %d on %s at %d\n", key, os.Getpid(), host, time.Now().UnixNano())
+	for {
+		created, err := create(path, token)
+		if err != nil {
+			return nil, waited, errors.Wrap(err, "failed to create lock")
+		}
+		if created {
+			break
+		}
+		if !waited {
+			fs.Debugf(nil, "Waiting for lock on %q", key)
+			waited = true
+		}
+		err = removeStale(path)
+		if err != nil {
+			return nil, waited, errors.Wrap(err, "failed to remove stale lock")
+		}
+		select {
+		case <-ctx.Done():
+			return nil, waited, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+	lock = &Lock{
+		path:  path,
+		token: token,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go lock.refresh()
+	return lock, waited, nil
+}
+
+// refresh touches the lock file until the lock is released
+func (l *Lock) refresh() {
+	defer close(l.done)
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			err := os.Chtimes(l.path, now, now)
+			if err != nil {
+				fs.Errorf(nil, "Failed to refresh lock %q: %v", l.path, err)
+			}
+		}
+	}
+}
+
+// Release releases the lock.
+//
+// The lock file is only removed if it is still ours, so a lock which
+// was taken over as stale by another process isn't removed from under
+// it.
+func (l *Lock) Release() error {
+	close(l.stop)
+	<-l.done
+	data, err := ioutil.ReadFile(l.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to release lock")
+	}
+	if string(data) != l.token {
+		return errors.Errorf("failed to release lock: %q was taken over by another process", l.path)
+	}
+	err = os.Remove(l.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to release lock")
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package filelock
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setTimings() func() {
+	oldPoll, oldRefresh, oldStale := pollInterval, refreshInterval, staleAge
+	pollInterval = time.Millisecond
+	refreshInterval = 10 * time.Millisecond
+	staleAge = time.Hour
+	return func() {
+		pollInterval, refreshInterval, staleAge = oldPoll, oldRefresh, oldStale
+	}
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "rclone-filelock-test")
+	require.NoError(t, err)
+	return dir, func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func TestAcquireRelease(t *testing.T) {
+	defer setTimings()()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	lock, waited, err := Acquire(ctx, dir, "remote:file")
+	require.NoError(t, err)
+	assert.False(t, waited)
+	_, err = os.Stat(lockPath(dir, "remote:file"))
+	require.NoError(t, err)
+
+	// a different key isn't blocked
+	other, waited, err := Acquire(ctx, dir, "remote:other")
+	require.NoError(t, err)
+	assert.False(t, waited)
+	require.NoError(t, other.Release())
+
+	// the same key waits for the release
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(released)
+		assert.NoError(t, lock.Release())
+	}()
+	lock2, waited, err := Acquire(ctx, dir, "remote:file")
+	require.NoError(t, err)
+	assert.True(t, waited)
+	select {
+	case <-released:
+	default:
+		t.Fatal("lock acquired before it was released")
+	}
+	require.NoError(t, lock2.Release())
+
+	_, err = os.Stat(lockPath(dir, "remote:file"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAcquireCancel(t *testing.T) {
+	defer setTimings()()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	lock, _, err := Acquire(context.Background(), dir, "key")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, lock.Release())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, waited, err := Acquire(ctx, dir, "key")
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, waited)
+}
+
+func TestAcquireStale(t *testing.T) {
+	defer setTimings()()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// a lock left behind by a process which was killed
+	path := lockPath(dir, "key")
+	require.NoError(t, ioutil.WriteFile(path, []byte("key\n"), 0666))
+	old := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(path, old, old))
+
+	lock, waited, err := Acquire(context.Background(), dir, "key")
+	require.NoError(t, err)
+	assert.True(t, waited)
+
+	// the holder keeps the lock fresh
+	time.Sleep(50 * time.Millisecond)
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, time.Since(fi.ModTime()) < time.Minute)
+	require.NoError(t, lock.Release())
+}
+
+func TestRemoveIfSame(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	path := lockPath(dir, "key")
+	old := time.Now().Add(-2 * time.Hour)
+
+	// a stale lock is removed
+	require.NoError(t, ioutil.WriteFile(path, []byte("1\n"), 0666))
+	require.NoError(t, os.Chtimes(path, old, old))
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, removeIfSame(path, fi))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// but not if another process has replaced it with a new one
+	require.NoError(t, ioutil.WriteFile(path, []byte("1\n"), 0666))
+	require.NoError(t, os.Chtimes(path, old, old))
+	fi, err = os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, ioutil.WriteFile(path, []byte("2\n"), 0666))
+	require.NoError(t, removeIfSame(path, fi))
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2\n", string(data))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 1, len(files))
+}
+
+func TestReleaseTakenOver(t *testing.T) {
+	defer setTimings()()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	lock, _, err := Acquire(context.Background(), dir, "key")
+	require.NoError(t, err)
+
+	// another process took the lock over
+	path := lockPath(dir, "key")
+	require.NoError(t, ioutil.WriteFile(path, []byte("key\nsomeone else\n"), 0666))
+	err = lock.Release()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "taken over by another process")
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "key\nsomeone else\n", string(data))
+}
@@ -0,0 +1,7 @@
+// Package mmap implements a large block memory allocator using
+// anonymous memory maps.
+//
+// Memory allocated this way isn't managed by the Go garbage collector
+// so it must be freed explicitly with Free, but it is returned to the
+// OS as soon as it is freed.
+package mmap
@@ -0,0 +1,18 @@
+// Memory allocation for platforms without memory maps
+
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package mmap
+
+// Alloc allocates size bytes and returns a slice containing them.
+// Memory maps aren't supported on this platform so this uses the Go
+// allocator.
+func Alloc(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+// Free frees buffers allocated by Alloc.  The Go allocator frees the
+// memory when it is no longer referenced so this does nothing.
+func Free(mem []byte) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+package mmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocFree(t *testing.T) {
+	const size = 4096
+	b, err := Alloc(size)
+	require.NoError(t, err)
+	assert.Equal(t, size, len(b))
+	assert.Equal(t, size, cap(b))
+
+	// check we can write to all the memory
+	for i := range b {
+		b[i] = byte(i)
+	}
+	for i := range b {
+		assert.Equal(t, byte(i), b[i])
+	}
+
+	require.NoError(t, Free(b))
+}
@@ -0,0 +1,184 @@
+// OAuth2 device authorization flow (RFC 8628)
+
+package oauthutil
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/fshttp"
+	"github.com/ncw/rclone/lib/rest"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// FlowDevice is the value of config.ConfigAuthFlow to use the
+	// device authorization flow
+	FlowDevice = "device"
+
+	// FlowOOB is the value of config.ConfigAuthFlow to use the out
+	// of band flow where the code is shown in the browser
+	FlowOOB = "oob"
+
+	// deviceGrantType is the grant_type for polling the token URL
+	deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// deviceAuthURLs are the device authorization endpoints of the
+// providers which support the device flow indexed by their token URL
+var deviceAuthURLs = map[string]string{
+	"https://accounts.google.com/o/oauth2/token":                 "https://oauth2.googleapis.com/device/code",
+	"https://oauth2.googleapis.com/token":                        "https://oauth2.googleapis.com/device/code",
+	"https://login.microsoftonline.com/common/oauth2/v2.0/token": "https://login.microsoftonline.com/common/oauth2/v2.0/devicecode",
+}
+
+// sleep is used to wait between polls - it is a variable so the tests
+// can replace it
+var sleep = time.Sleep
+
+// DeviceCode is the response from the device authorization endpoint
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURL         string `json:"verification_url"` // Google's name for verification_uri
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// deviceToken is the response from polling the token URL
+type deviceToken struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// deviceAuthURL returns the device authorization endpoint for the
+// remote called name or "" if it doesn't have one
+func deviceAuthURL(name string, oauthConfig *oauth2.Config) string {
+	if deviceURL := config.FileGet(name, config.ConfigDeviceAuthURL); deviceURL != "" {
+		return deviceURL
+	}
+	return deviceAuthURLs[oauthConfig.Endpoint.TokenURL]
+}
+
+// postForm posts params to postURL decoding the JSON response into
+// result.  Error responses with a JSON body are decoded too.
+func postForm(client *http.Client, postURL string, params url.Values, result interface{}) (resp *http.Response, err error) {
+	resp, err = client.PostForm(postURL, params)
+	if err != nil {
+		return nil, err
+	}
+	err = rest.DecodeJSON(resp, result)
+	if err != nil {
+		return resp, errors.Wrapf(err, "failed to decode response from %s (HTTP status %s)", postURL, resp.Status)
+	}
+	return resp, nil
+}
+
+// DeviceFlow gets a token using the device authorization flow.
+//
+// It gets a code from deviceURL, calls prompt with it so the user can
+// enter it on another device, then polls the token URL until the user
+// has authorized rclone.
+func DeviceFlow(client *http.Client, oauthConfig *oauth2.Config, deviceURL string, prompt func(*DeviceCode)) (*oauth2.Token, error) {
+	params := url.Values{
+		"client_id": {oauthConfig.ClientID},
+	}
+	if len(oauthConfig.Scopes) > 0 {
+		params.Set("scope", strings.Join(oauthConfig.Scopes, " "))
+	}
+	var code DeviceCode
+	resp, err := postForm(client, deviceURL, params, &code)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get device code")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || code.DeviceCode == "" {
+		return nil, errors.Errorf("failed to get device code: HTTP status %s", resp.Status)
+	}
+	if code.VerificationURI == "" {
+		code.VerificationURI = code.VerificationURL
+	}
+	prompt(&code)
+
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expires := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	params = url.Values{
+		"grant_type":  {deviceGrantType},
+		"device_code": {code.DeviceCode},
+		"client_id":   {oauthConfig.ClientID},
+	}
+	if oauthConfig.ClientSecret != "" {
+		params.Set("client_secret", oauthConfig.ClientSecret)
+	}
+	for {
+		sleep(interval)
+		if code.ExpiresIn > 0 && time.Now().After(expires) {
+			return nil, errors.New("device code expired before rclone was authorized")
+		}
+		var result deviceToken
+		_, err = postForm(client, oauthConfig.Endpoint.TokenURL, params, &result)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get token")
+		}
+		switch result.Error {
+		case "":
+			if result.AccessToken == "" {
+				return nil, errors.New("failed to get token: no access token returned")
+			}
+			token := &oauth2.Token{
+				AccessToken:  result.AccessToken,
+				TokenType:    result.TokenType,
+				RefreshToken: result.RefreshToken,
+			}
+			if result.ExpiresIn > 0 {
+				token.Expiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+			}
+			return token, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, errors.Errorf("failed to get token: %s: %s", result.Error, result.ErrorDescription)
+		}
+		fs.Debugf(nil, "Waiting %v for authorization: %s", interval, result.Error)
+	}
+}
+
+// configDevice does the initial creation of the token for the remote
+// called name using the device authorization flow
+func configDevice(name string, oauthConfig *oauth2.Config, automatic bool) error {
+	deviceURL := deviceAuthURL(name, oauthConfig)
+	if deviceURL == "" {
+		return errors.Errorf("this remote doesn't support the device flow - use --device-auth-url or set %q in the config if its provider does", config.ConfigDeviceAuthURL)
+	}
+	token, err := DeviceFlow(fshttp.NewClient(fs.Config), oauthConfig, deviceURL, func(code *DeviceCode) {
+		if code.VerificationURIComplete != "" {
+			fmt.Printf("On any device go to the following link: %s\n", code.VerificationURIComplete)
+			fmt.Printf("Check the code shown is %s\n", code.UserCode)
+		} else {
+			fmt.Printf("On any device go to the following link: %s\n", code.VerificationURI)
+			fmt.Printf("Enter the code %s\n", code.UserCode)
+		}
+		fmt.Printf("Log in and authorize rclone for access\n")
+		fmt.Printf("Waiting for authorization...\n")
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Got token\n")
+	return saveToken(name, token, automatic)
+}
@@ -0,0 +1,110 @@
+package oauthutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	oldSleep := sleep
+	defer func() { sleep = oldSleep }()
+	var sleeps []time.Duration
+	sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "id", r.FormValue("client_id"))
+		assert.Equal(t, "a b", r.FormValue("scope"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":      "devicecode",
+			"user_code":        "USER-CODE",
+			"verification_url": "https://example.com/device",
+			"expires_in":       1800,
+			"interval":         2,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, deviceGrantType, r.FormValue("grant_type"))
+		assert.Equal(t, "devicecode", r.FormValue("device_code"))
+		assert.Equal(t, "secret", r.FormValue("client_secret"))
+		polls++
+		reply := map[string]interface{}{}
+		switch polls {
+		case 1:
+			reply["error"] = "authorization_pending"
+		case 2:
+			reply["error"] = "slow_down"
+		default:
+			reply["access_token"] = "access"
+			reply["refresh_token"] = "refresh"
+			reply["token_type"] = "Bearer"
+			reply["expires_in"] = 3600
+		}
+		if reply["error"] != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		_ = json.NewEncoder(w).Encode(reply)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	oauthConfig := &oauth2.Config{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Scopes:       []string{"a", "b"},
+		Endpoint:     oauth2.Endpoint{TokenURL: ts.URL + "/token"},
+	}
+	var gotCode *DeviceCode
+	token, err := DeviceFlow(http.DefaultClient, oauthConfig, ts.URL+"/device", func(code *DeviceCode) {
+		gotCode = code
+	})
+	require.NoError(t, err)
+	require.NotNil(t, gotCode)
+	assert.Equal(t, "USER-CODE", gotCode.UserCode)
+	assert.Equal(t, "https://example.com/device", gotCode.VerificationURI)
+	assert.Equal(t, "access", token.AccessToken)
+	assert.Equal(t, "refresh", token.RefreshToken)
+	assert.True(t, token.Expiry.After(time.Now()))
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, []time.Duration{2 * time.Second, 2 * time.Second, 7 * time.Second}, sleeps)
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	oldSleep := sleep
+	defer func() { sleep = oldSleep }()
+	sleep = func(time.Duration) {}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"device_code":      "devicecode",
+			"user_code":        "USER-CODE",
+			"verification_uri": "https://example.com/device",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":             "access_denied",
+			"error_description": "user said no",
+		})
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	oauthConfig := &oauth2.Config{
+		ClientID: "id",
+		Endpoint: oauth2.Endpoint{TokenURL: ts.URL + "/token"},
+	}
+	_, err := DeviceFlow(http.DefaultClient, oauthConfig, ts.URL+"/device", func(*DeviceCode) {})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access_denied: user said no")
+}
@@ -56,7 +56,11 @@ type oldToken struct {
 // GetToken returns the token saved in the config file under
 // section name.
 func GetToken(name string) (*oauth2.Token, error) {
-	tokenString := config.FileGet(name, config.ConfigToken)
+	return parseToken(name, config.FileGet(name, config.ConfigToken))
+}
+
+// parseToken parses the tokenString stored for the remote name
+func parseToken(name, tokenString string) (*oauth2.Token, error) {
 	if tokenString == "" {
 		return nil, errors.New("empty token found - please run rclone config again")
 	}
@@ -110,7 +114,7 @@ func PutToken(name string, token *oauth2.Token, newSection bool) error {
 	return nil
 }
 
-// TokenSource stores updated tokens in the config file
+// TokenSource stores updated tokens in the Store
 type TokenSource struct {
 	mu          sync.Mutex
 	name        string
@@ -119,17 +123,39 @@ type TokenSource struct {
 	config      *oauth2.Config
 	ctx         context.Context
 	expiryTimer *time.Timer // signals whenever the token expires
+	invalidated string      // access token which was invalidated
 }
 
 // Token returns a token or an error.
 // Token must be safe for concurrent use by multiple goroutines.
 // The returned Token must not be modified.
 //
-// This saves the token in the config file if it has changed
+// This saves the token in the Store if it has changed.
+//
+// If the token needs refreshing the Store is locked while it is done
+// so that other rclone processes using the same remote wait for the
+// new token rather than refreshing it too.
 func (ts *TokenSource) Token() (*oauth2.Token, error) {
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
+	// If the token needs refreshing see if another process has
+	// refreshed it already
+	if !ts.token.Valid() {
+		unlock, err := Store.Lock(ts.name)
+		if err != nil {
+			fs.Debugf(ts.name, "Refreshing token without lock: %v", err)
+		} else {
+			defer unlock()
+		}
+		token, err := Store.Get(ts.name)
+		if err == nil && token.Valid() && token.AccessToken != ts.invalidated {
+			fs.Debugf(ts.name, "Using token refreshed by another process")
+			ts.token = token
+			ts.tokenSource = nil
+		}
+	}
+
 	// Make a new token source if required
 	if ts.tokenSource == nil {
 		ts.tokenSource = ts.config.TokenSource(ts.ctx, ts.token)
@@ -146,7 +172,7 @@ func (ts *TokenSource) Token() (*oauth2.Token, error) {
 		if ts.expiryTimer != nil {
 			ts.expiryTimer.Reset(ts.timeToExpiry())
 		}
-		err = PutToken(ts.name, token, false)
+		err = Store.Put(ts.name, token)
 		if err != nil {
 			return nil, err
 		}
@@ -157,6 +183,7 @@ func (ts *TokenSource) Token() (*oauth2.Token, error) {
 // Invalidate invalidates the token
 func (ts *TokenSource) Invalidate() {
 	ts.mu.Lock()
+	ts.invalidated = ts.token.AccessToken
 	ts.token.AccessToken = ""
 	ts.mu.Unlock()
 }
@@ -232,7 +259,7 @@ func overrideCredentials(name string, origConfig *oauth2.Config) (newConfig *oau
 // httpClient passed in as the base client.
 func NewClientWithBaseClient(name string, config *oauth2.Config, baseClient *http.Client) (*http.Client, *TokenSource, error) {
 	config, _ = overrideCredentials(name, config)
-	token, err := GetToken(name)
+	token, err := Store.Get(name)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -241,7 +268,7 @@ func NewClientWithBaseClient(name string, config *oauth2.Config, baseClient *htt
 	ctx := Context(baseClient)
 
 	// Wrap the TokenSource in our TokenSource which saves changed
-	// tokens in the config file
+	// tokens in the Store
 	ts := &TokenSource{
 		name:   name,
 		token:  token,
@@ -255,7 +282,7 @@ func NewClientWithBaseClient(name string, config *oauth2.Config, baseClient *htt
 // NewClient gets a token from the config file and configures a Client
 // with it.  It returns the client and a TokenSource which Invalidate may need to be called on
 func NewClient(name string, oauthConfig *oauth2.Config) (*http.Client, *TokenSource, error) {
-	return NewClientWithBaseClient(name, oauthConfig, fshttp.NewClient(fs.Config))
+	return NewClientWithBaseClient(name, oauthConfig, fshttp.NewRemoteClient(fs.Config, name))
 }
 
 // Config does the initial creation of the token
@@ -288,6 +315,11 @@ func doConfig(id, name string, oauthConfig *oauth2.Config, offline bool, opts []
 		}
 	}
 
+	flow := config.FileGet(name, config.ConfigAuthFlow)
+	if flow == FlowDevice {
+		return configDevice(name, oauthConfig, automatic)
+	}
+
 	// Detect whether we should use internal web server
 	useWebServer := false
 	switch oauthConfig.RedirectURL {
@@ -336,6 +368,13 @@ func doConfig(id, name string, oauthConfig *oauth2.Config, offline bool, opts []
 			oauthConfig.RedirectURL = RedirectURL
 		}
 	}
+	if flow == FlowOOB {
+		// copy the config and set to show the code in the browser
+		configCopy := *oauthConfig
+		oauthConfig = &configCopy
+		oauthConfig.RedirectURL = TitleBarRedirectURL
+		useWebServer = false
+	}
 
 	// Make random state
 	stateBytes := make([]byte, 16)
@@ -387,6 +426,12 @@ func doConfig(id, name string, oauthConfig *oauth2.Config, offline bool, opts []
 		return errors.Wrap(err, "failed to get token")
 	}
 
+	return saveToken(name, token, automatic)
+}
+
+// saveToken saves the token for the remote called name, printing it
+// to paste into the remote machine too if automatic is set
+func saveToken(name string, token *oauth2.Token, automatic bool) error {
 	// Print code if we do automatic retrieval
 	if automatic {
 		result, err := json.Marshal(token)
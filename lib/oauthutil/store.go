@@ -0,0 +1,45 @@
+package oauthutil
+
+import (
+	"github.com/ncw/rclone/fs/config"
+	"golang.org/x/oauth2"
+)
+
+// TokenStore reads and saves the OAuth tokens of remotes.
+//
+// It is shared by all the backends using oauthutil so that a token
+// refreshed by one rclone process is seen by the others using the
+// same remote rather than being refreshed again and clobbered.
+type TokenStore interface {
+	// Get reads the latest token saved for the remote name
+	Get(name string) (*oauth2.Token, error)
+	// Put saves the token for the remote name
+	Put(name string, token *oauth2.Token) error
+	// Lock locks the token for the remote name against other
+	// processes until the unlock function returned is called
+	Lock(name string) (unlock func(), err error)
+}
+
+// Store is the TokenStore used by the TokenSources
+var Store TokenStore = configStore{}
+
+// configStore stores the tokens in the config file
+type configStore struct{}
+
+// Get re-reads the token from the config file on disk
+func (configStore) Get(name string) (*oauth2.Token, error) {
+	return parseToken(name, config.FileGetFromDisk(name, config.ConfigToken))
+}
+
+// Put saves the token in the config file
+func (configStore) Put(name string, token *oauth2.Token) error {
+	return PutToken(name, token, false)
+}
+
+// Lock locks the whole config file since saving the token rewrites it
+func (configStore) Lock(name string) (unlock func(), err error) {
+	return config.LockConfig()
+}
+
+// Check interface satisfied
+var _ TokenStore = configStore{}
@@ -0,0 +1,85 @@
+package oauthutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// memoryStore is a TokenStore in memory for testing
+type memoryStore struct {
+	token  *oauth2.Token
+	puts   int
+	locks  int
+	locked bool
+}
+
+func (s *memoryStore) Get(name string) (*oauth2.Token, error) {
+	token := *s.token
+	return &token, nil
+}
+
+func (s *memoryStore) Put(name string, token *oauth2.Token) error {
+	s.puts++
+	s.token = token
+	return nil
+}
+
+func (s *memoryStore) Lock(name string) (func(), error) {
+	s.locks++
+	s.locked = true
+	return func() { s.locked = false }, nil
+}
+
+func TestTokenSourceUsesStoredToken(t *testing.T) {
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"refreshed","refresh_token":"refresh","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	oldStore := Store
+	defer func() {
+		Store = oldStore
+	}()
+	store := &memoryStore{}
+	Store = store
+
+	expired := &oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+	ts := &TokenSource{
+		name:   "test",
+		token:  expired,
+		config: &oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: server.URL}},
+		ctx:    Context(server.Client()),
+	}
+
+	// Another process has refreshed the token already
+	store.token = &oauth2.Token{AccessToken: "other", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	token, err := ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "other", token.AccessToken)
+	assert.Equal(t, 0, refreshes)
+	assert.Equal(t, 1, store.locks)
+	assert.False(t, store.locked)
+
+	// A valid token doesn't touch the store
+	_, err = ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.locks)
+
+	// An invalidated token is refreshed even though the store has it
+	ts.Invalidate()
+	token, err = ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "refreshed", token.AccessToken)
+	assert.Equal(t, 1, refreshes)
+	assert.Equal(t, 1, store.puts)
+	assert.Equal(t, "refreshed", store.token.AccessToken)
+}
@@ -3,11 +3,14 @@ package pacer
 
 import (
 	"math/rand"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/pkg/errors"
 )
 
 // Pacer state
@@ -24,6 +27,8 @@ type Pacer struct {
 	connTokens         chan struct{} // Connection tokens
 	calculatePace      func(bool)    // switchable pacing algorithm - call with mu held
 	consecutiveRetries int           // number of consecutive retries
+	configOnce         sync.Once     // for applying the --pacer flags
+	remote             string        // name of the remote for its pacer config, if set
 }
 
 // Type is for selecting different pacing algorithms
@@ -59,8 +64,89 @@ const (
 	//
 	// See https://developers.google.com/drive/v2/web/handle-errors#exponential-backoff
 	GoogleDrivePacer
+
+	// TokenBucketPacer paces operations at a fixed rate
+	//
+	// Operations are paced at the interval set with SetMinSleep
+	// but up to the number set with SetBurst can be made at once
+	// after a quiet period.  On retries the next operation waits
+	// for the interval set with SetMaxSleep.
+	//
+	// This suits providers which publish their rate limits.
+	TokenBucketPacer
+
+	// AIMDPacer is an additive increase, multiplicative decrease
+	// pacer as used for TCP congestion control.
+	//
+	// On retries the rate of operations is halved, on non errors
+	// the rate is increased by a fixed amount so it recovers slowly
+	// and linearly rather than exponentially.
+	//
+	// The sleep never goes below that set with SetMinSleep or
+	// above that set with SetMaxSleep.
+	AIMDPacer
 )
 
+// aimdIncrease is the number of operations per second the AIMDPacer
+// increases the rate by on each non error
+const aimdIncrease = 0.5
+
+// typeNames are the names of the pacer types for the --pacer flag
+var typeNames = map[string]Type{
+	"default":      DefaultPacer,
+	"amazon":       AmazonCloudDrivePacer,
+	"google":       GoogleDrivePacer,
+	"token-bucket": TokenBucketPacer,
+	"aimd":         AIMDPacer,
+}
+
+// Preset is the pacer settings for a provider with published rate
+// limits
+type Preset struct {
+	Type     Type
+	MinSleep time.Duration
+	MaxSleep time.Duration
+	Burst    int
+}
+
+// presets are the provider presets for the --pacer flag
+var presets = map[string]Preset{
+	// Box allows 1000 API calls per minute per user
+	"box": {Type: TokenBucketPacer, MinSleep: 60 * time.Millisecond, MaxSleep: 2 * time.Second, Burst: 10},
+	// Google Drive allows 1000 queries per 100 seconds per user by default
+	"drive": {Type: TokenBucketPacer, MinSleep: 100 * time.Millisecond, MaxSleep: 16 * time.Second, Burst: 10},
+}
+
+// RegisterPreset adds a named preset which can be selected with the
+// --pacer flag
+func RegisterPreset(name string, preset Preset) {
+	presets[name] = preset
+}
+
+// Names returns the names of the pacer types and presets which can be
+// passed to --pacer
+func Names() (names []string) {
+	for name := range typeNames {
+		names = append(names, name)
+	}
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckName returns an error if name isn't a pacer type or preset
+func CheckName(name string) error {
+	if _, ok := typeNames[name]; ok {
+		return nil
+	}
+	if _, ok := presets[name]; ok {
+		return nil
+	}
+	return errors.Errorf("unknown pacer %q - choose from %v", name, Names())
+}
+
 // Paced is a function which is called by the Call and CallNoRetry
 // methods.  It should return a boolean, true if it would like to be
 // retried, and an error.  This error may be returned or returned
@@ -120,6 +206,23 @@ func (p *Pacer) SetMaxSleep(t time.Duration) *Pacer {
 	return p
 }
 
+// SetBurst sets the number of operations which can be made at once
+// after a quiet period.  It defaults to 1.
+//
+// Should not be changed once you have started calling the pacer.
+func (p *Pacer) SetBurst(n int) *Pacer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if n < 1 {
+		n = 1
+	}
+	p.pacer = make(chan struct{}, n)
+	for i := 0; i < n; i++ {
+		p.pacer <- struct{}{}
+	}
+	return p
+}
+
 // SetMaxConnections sets the maximum number of concurrent connections.
 // Setting the value to 0 will allow unlimited number of connections.
 // Should not be changed once you have started calling the pacer.
@@ -185,18 +288,96 @@ func (p *Pacer) SetPacer(t Type) *Pacer {
 		p.calculatePace = p.acdPacer
 	case GoogleDrivePacer:
 		p.calculatePace = p.drivePacer
+	case TokenBucketPacer:
+		p.calculatePace = p.tokenBucketPacer
+	case AIMDPacer:
+		p.calculatePace = p.aimdPacer
 	default:
 		p.calculatePace = p.defaultPacer
 	}
 	return p
 }
 
+// SetPreset sets the pacer to the type or preset called name
+func (p *Pacer) SetPreset(name string) error {
+	if t, ok := typeNames[name]; ok {
+		p.SetPacer(t)
+		return nil
+	}
+	preset, ok := presets[name]
+	if !ok {
+		return CheckName(name)
+	}
+	p.SetPacer(preset.Type)
+	p.SetMinSleep(preset.MinSleep)
+	p.SetMaxSleep(preset.MaxSleep)
+	p.SetBurst(preset.Burst)
+	return nil
+}
+
+// SetRemote sets the name of the remote the pacer is for so the
+// pacer, pacer_min_sleep and pacer_burst lines in its section of the
+// config file are used in preference to the --pacer flags.
+//
+// Should not be changed once you have started calling the pacer.
+func (p *Pacer) SetRemote(name string) *Pacer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.remote = name
+	return p
+}
+
+// applyConfig applies the pacer config for the remote and the --pacer
+// flags.  This is done on the first call so they override the
+// settings made by the backend.
+func (p *Pacer) applyConfig() {
+	p.mu.Lock()
+	remote := p.remote
+	p.mu.Unlock()
+	name, minSleep, burst := fs.Config.Pacer, fs.Config.PacerMinSleep, fs.Config.PacerBurst
+	source := "--pacer"
+	if remote != "" {
+		if value := fs.ConfigFileGet(remote, "pacer"); value != "" {
+			name, source = value, "pacer for remote "+remote
+		}
+		if value := fs.ConfigFileGet(remote, "pacer_min_sleep"); value != "" {
+			d, err := fs.ParseDuration(value)
+			if err != nil {
+				fs.Errorf(remote+":", "Ignoring pacer_min_sleep: %v", err)
+			} else {
+				minSleep = d
+			}
+		}
+		if value := fs.ConfigFileGet(remote, "pacer_burst"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				fs.Errorf(remote+":", "Ignoring pacer_burst: %v", err)
+			} else {
+				burst = n
+			}
+		}
+	}
+	if name != "" {
+		if err := p.SetPreset(name); err != nil {
+			fs.Errorf("pacer", "Ignoring %s: %v", source, err)
+		}
+	}
+	if minSleep > 0 {
+		p.SetMinSleep(minSleep)
+	}
+	if burst > 0 {
+		p.SetBurst(burst)
+	}
+}
+
 // Start a call to the API
 //
 // This must be called as a pair with endCall
 //
 // This waits for the pacer token
 func (p *Pacer) beginCall() {
+	p.configOnce.Do(p.applyConfig)
+
 	// pacer starts with a token in and whenever we take one out
 	// XXX ms later we put another in.  We could do this with a
 	// Ticker more accurately, but then we'd have to work out how
@@ -309,6 +490,53 @@ func (p *Pacer) drivePacer(retry bool) {
 	}
 }
 
+// tokenBucketPacer paces at a fixed rate
+//
+// See the description for TokenBucketPacer
+//
+// This should calculate a new sleepTime.  It takes a boolean as to
+// whether the operation should be retried or not.
+//
+// Call with p.mu held
+func (p *Pacer) tokenBucketPacer(retry bool) {
+	if retry {
+		p.sleepTime = p.maxSleep
+		fs.Debugf("pacer", "Rate limited, sleeping for %v", p.sleepTime)
+	} else {
+		p.sleepTime = p.minSleep
+	}
+}
+
+// aimdPacer implements an additive increase, multiplicative decrease
+// of the rate
+//
+// See the description for AIMDPacer
+//
+// This should calculate a new sleepTime.  It takes a boolean as to
+// whether the operation should be retried or not.
+//
+// Call with p.mu held
+func (p *Pacer) aimdPacer(retry bool) {
+	oldSleepTime := p.sleepTime
+	if retry {
+		p.sleepTime *= 2
+		if p.sleepTime > p.maxSleep {
+			p.sleepTime = p.maxSleep
+		}
+		if p.sleepTime != oldSleepTime {
+			fs.Debugf("pacer", "Rate limited, increasing sleep to %v", p.sleepTime)
+		}
+	} else {
+		if p.sleepTime > 0 {
+			rate := float64(time.Second)/float64(p.sleepTime) + aimdIncrease
+			p.sleepTime = time.Duration(float64(time.Second) / rate)
+		}
+		if p.sleepTime < p.minSleep {
+			p.sleepTime = p.minSleep
+		}
+	}
+}
+
 // endCall implements the pacing algorithm
 //
 // This should calculate a new sleepTime.  It takes a boolean as to
@@ -340,6 +340,155 @@ func TestGoogleDrivePacer(t *testing.T) {
 	}
 }
 
+func TestTokenBucketPacer(t *testing.T) {
+	p := New().SetMinSleep(100 * time.Millisecond).SetPacer(TokenBucketPacer).SetMaxSleep(time.Second)
+	for _, test := range []struct {
+		in    time.Duration
+		retry bool
+		want  time.Duration
+	}{
+		{100 * time.Millisecond, false, 100 * time.Millisecond},
+		{100 * time.Millisecond, true, time.Second},
+		{time.Second, true, time.Second},
+		{time.Second, false, 100 * time.Millisecond},
+	} {
+		p.sleepTime = test.in
+		p.tokenBucketPacer(test.retry)
+		if p.sleepTime != test.want {
+			t.Errorf("%+v: want %v got %v", test, test.want, p.sleepTime)
+		}
+	}
+}
+
+func TestAIMDPacer(t *testing.T) {
+	p := New().SetMinSleep(100 * time.Millisecond).SetPacer(AIMDPacer).SetMaxSleep(time.Second)
+	for _, test := range []struct {
+		in    time.Duration
+		retry bool
+		want  time.Duration
+	}{
+		{100 * time.Millisecond, true, 200 * time.Millisecond},
+		{400 * time.Millisecond, true, 800 * time.Millisecond},
+		{800 * time.Millisecond, true, time.Second},
+		{time.Second, false, 666666666 * time.Nanosecond},
+		{500 * time.Millisecond, false, 400 * time.Millisecond},
+		{102 * time.Millisecond, false, 100 * time.Millisecond},
+		{100 * time.Millisecond, false, 100 * time.Millisecond},
+	} {
+		p.sleepTime = test.in
+		p.aimdPacer(test.retry)
+		if p.sleepTime != test.want {
+			t.Errorf("%+v: want %v got %v", test, test.want, p.sleepTime)
+		}
+	}
+}
+
+func TestSetBurst(t *testing.T) {
+	p := New().SetBurst(5)
+	if cap(p.pacer) != 5 {
+		t.Errorf("pacer cap want 5 got %d", cap(p.pacer))
+	}
+	if len(p.pacer) != 5 {
+		t.Errorf("pacer len want 5 got %d", len(p.pacer))
+	}
+	p.SetBurst(0)
+	if cap(p.pacer) != 1 || len(p.pacer) != 1 {
+		t.Errorf("pacer want 1 token got cap %d len %d", cap(p.pacer), len(p.pacer))
+	}
+}
+
+func TestSetPreset(t *testing.T) {
+	p := New()
+	if err := p.SetPreset("aimd"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.aimdPacer) {
+		t.Errorf("calculatePace not aimd")
+	}
+
+	RegisterPreset("test", Preset{Type: TokenBucketPacer, MinSleep: 20 * time.Millisecond, MaxSleep: 3 * time.Second, Burst: 4})
+	defer delete(presets, "test")
+	if err := p.SetPreset("test"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.tokenBucketPacer) {
+		t.Errorf("calculatePace not token bucket")
+	}
+	if p.minSleep != 20*time.Millisecond || p.maxSleep != 3*time.Second || cap(p.pacer) != 4 {
+		t.Errorf("preset not applied: minSleep %v maxSleep %v burst %d", p.minSleep, p.maxSleep, cap(p.pacer))
+	}
+
+	if err := p.SetPreset("potato"); err == nil {
+		t.Errorf("expecting error")
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	oldPacer, oldMinSleep, oldBurst := fs.Config.Pacer, fs.Config.PacerMinSleep, fs.Config.PacerBurst
+	defer func() {
+		fs.Config.Pacer, fs.Config.PacerMinSleep, fs.Config.PacerBurst = oldPacer, oldMinSleep, oldBurst
+	}()
+	fs.Config.Pacer = "token-bucket"
+	fs.Config.PacerMinSleep = 5 * time.Millisecond
+	fs.Config.PacerBurst = 3
+
+	p := New().SetMinSleep(time.Second)
+	p.beginCall()
+	p.endCall(false)
+	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.tokenBucketPacer) {
+		t.Errorf("calculatePace not token bucket")
+	}
+	if p.minSleep != 5*time.Millisecond {
+		t.Errorf("minSleep want 5ms got %v", p.minSleep)
+	}
+	if cap(p.pacer) != 3 {
+		t.Errorf("burst want 3 got %d", cap(p.pacer))
+	}
+}
+
+func TestApplyConfigRemote(t *testing.T) {
+	oldPacer, oldConfigFileGet := fs.Config.Pacer, fs.ConfigFileGet
+	defer func() {
+		fs.Config.Pacer, fs.ConfigFileGet = oldPacer, oldConfigFileGet
+	}()
+	fs.Config.Pacer = "aimd"
+	fs.ConfigFileGet = func(section, key string, defaultVal ...string) string {
+		if section != "remote" {
+			return ""
+		}
+		return map[string]string{
+			"pacer":           "token-bucket",
+			"pacer_min_sleep": "7ms",
+			"pacer_burst":     "4",
+		}[key]
+	}
+
+	// the remote's config takes precedence over the flags
+	p := New().SetRemote("remote").SetMinSleep(time.Second)
+	p.beginCall()
+	p.endCall(false)
+	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.tokenBucketPacer) {
+		t.Errorf("calculatePace not token bucket")
+	}
+	if p.minSleep != 7*time.Millisecond {
+		t.Errorf("minSleep want 7ms got %v", p.minSleep)
+	}
+	if cap(p.pacer) != 4 {
+		t.Errorf("burst want 4 got %d", cap(p.pacer))
+	}
+
+	// other remotes use the flags
+	p = New().SetRemote("other").SetMinSleep(time.Second)
+	p.beginCall()
+	p.endCall(false)
+	if fmt.Sprintf("%p", p.calculatePace) != fmt.Sprintf("%p", p.aimdPacer) {
+		t.Errorf("calculatePace not aimd")
+	}
+	if p.minSleep != time.Second {
+		t.Errorf("minSleep want 1s got %v", p.minSleep)
+	}
+}
+
 func TestEndCall(t *testing.T) {
 	p := New().SetMaxConnections(5)
 	emptyTokens(p)
@@ -0,0 +1,130 @@
+package pool
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makes the allocations be unreliable
+func makeUnreliable(bp *Pool) {
+	const maxFailsInARow = 3
+	var allocFails int
+	bp.alloc = func(size int) ([]byte, error) {
+		if allocFails < maxFailsInARow {
+			allocFails++
+			return nil, errors.New("failed to allocate memory")
+		}
+		allocFails = 0
+		return make([]byte, size), nil
+	}
+	var freeFails int
+	bp.free = func(b []byte) error {
+		if freeFails < maxFailsInARow {
+			freeFails++
+			return errors.New("failed to free memory")
+		}
+		freeFails = 0
+		return nil
+	}
+}
+
+func testGetPut(t *testing.T, useMmap bool, unreliable bool) {
+	bp := New(60*time.Second, 4096, 2, useMmap)
+	if unreliable {
+		makeUnreliable(bp)
+	}
+
+	assert.Equal(t, 0, bp.InUse())
+
+	b1 := bp.Get()
+	assert.Equal(t, 1, bp.InUse())
+	assert.Equal(t, 0, bp.InPool())
+	assert.Equal(t, 1, bp.Alloced())
+
+	b2 := bp.Get()
+	assert.Equal(t, 2, bp.InUse())
+	assert.Equal(t, 0, bp.InPool())
+	assert.Equal(t, 2, bp.Alloced())
+
+	b3 := bp.Get()
+	assert.Equal(t, 3, bp.InUse())
+	assert.Equal(t, 0, bp.InPool())
+	assert.Equal(t, 3, bp.Alloced())
+
+	bp.Put(b1)
+	assert.Equal(t, 2, bp.InUse())
+	assert.Equal(t, 1, bp.InPool())
+	assert.Equal(t, 3, bp.Alloced())
+
+	bp.Put(b2)
+	assert.Equal(t, 1, bp.InUse())
+	assert.Equal(t, 2, bp.InPool())
+	assert.Equal(t, 3, bp.Alloced())
+
+	// the pool is full so this is freed
+	bp.Put(b3)
+	assert.Equal(t, 0, bp.InUse())
+	assert.Equal(t, 2, bp.InPool())
+	assert.Equal(t, 2, bp.Alloced())
+
+	b1a := bp.Get()
+	assert.Equal(t, 1, bp.InUse())
+	assert.Equal(t, 1, bp.InPool())
+	assert.Equal(t, 2, bp.Alloced())
+	assert.Equal(t, 4096, len(b1a))
+
+	bp.Put(b1a)
+	bp.Flush()
+	assert.Equal(t, 0, bp.InUse())
+	assert.Equal(t, 0, bp.InPool())
+	assert.Equal(t, 0, bp.Alloced())
+
+	assert.Panics(t, func() {
+		bp.Put(make([]byte, 1))
+	})
+}
+
+func TestGetPut(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		useMmap    bool
+		unreliable bool
+	}{
+		{"normal", false, false},
+		{"mmap", true, false},
+		{"unreliable", false, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			testGetPut(t, test.useMmap, test.unreliable)
+		})
+	}
+}
+
+func TestFlusher(t *testing.T) {
+	bp := New(50*time.Millisecond, 4, 2, false)
+
+	b1 := bp.Get()
+	b2 := bp.Get()
+	bp.Put(b1)
+	bp.Put(b2)
+	assert.Equal(t, 2, bp.InPool())
+
+	// buffers unused for a whole flush interval are freed
+	deadline := time.Now().Add(5 * time.Second)
+	for bp.InPool() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, bp.InPool())
+	assert.Equal(t, 0, bp.Alloced())
+}
+
+func TestPools(t *testing.T) {
+	ps := NewPools(60*time.Second, 2, false)
+	bp := ps.Get(4096)
+	assert.True(t, bp == ps.Get(4096))
+	assert.False(t, bp == ps.Get(8192))
+	assert.Equal(t, 8192, len(ps.Get(8192).Get()))
+}
@@ -0,0 +1,50 @@
+// Middleware for the requests made by Client
+
+package rest
+
+import (
+	"net/http"
+)
+
+// DoFn does an HTTP request - (*http.Client).Do is one
+type DoFn func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the DoFn which does a request so it can change the
+// request before it is sent, change the response, or not send the
+// request at all.
+//
+// It is called after the headers and signer have been applied so it
+// sees the request as sent.  It may be called more than once for a
+// request if the backend retries.
+type Middleware func(next DoFn) DoFn
+
+// Use adds middleware to the chain for all requests.  The first
+// middleware added sees the request first and the response last.
+func (api *Client) Use(mw ...Middleware) *Client {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.middleware = append(api.middleware, mw...)
+	return api
+}
+
+// chain returns the DoFn which calls the middleware then do - call
+// with the mu held
+func (api *Client) chain(do DoFn) DoFn {
+	for i := len(api.middleware) - 1; i >= 0; i-- {
+		do = api.middleware[i](do)
+	}
+	return do
+}
+
+// HeaderMiddleware returns Middleware which sets the headers passed
+// in on every request, overriding any already set
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return func(next DoFn) DoFn {
+		return func(req *http.Request) (*http.Response, error) {
+			for k, v := range headers {
+				req.Header.Set(k, v)
+			}
+			return next(req)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	var gotHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Test")
+		w.Header().Set("X-Reply", "server")
+	}))
+	defer ts.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next DoFn) DoFn {
+			return func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				resp, err := next(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	api := NewClient(http.DefaultClient).SetRoot(ts.URL)
+	api.SetHeader("X-Test", "client")
+	api.Use(record("a"), HeaderMiddleware(map[string]string{"X-Test": "middleware"}))
+	api.Use(record("b"))
+
+	resp, err := api.Call(context.Background(), &Opts{Method: "GET", NoResponse: true})
+	require.NoError(t, err)
+	assert.Equal(t, "server", resp.Header.Get("X-Reply"))
+	assert.Equal(t, "middleware", gotHeader)
+	assert.Equal(t, []string{"a before", "b before", "b after", "a after"}, calls)
+
+	// Middleware can stop the request being sent
+	gotHeader = ""
+	stopErr := errors.New("stopped")
+	api.Use(func(next DoFn) DoFn {
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, stopErr
+		}
+	})
+	_, err = api.Call(context.Background(), &Opts{Method: "GET"})
+	assert.Equal(t, stopErr, err)
+	assert.Equal(t, "", gotHeader)
+}
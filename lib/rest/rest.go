@@ -5,6 +5,7 @@ package rest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"io"
@@ -26,6 +27,7 @@ type Client struct {
 	errorHandler func(resp *http.Response) error
 	headers      map[string]string
 	signer       SignerFn
+	middleware   []Middleware
 }
 
 // NewClient takes an oauth http.Client and makes a new api instance
@@ -194,7 +196,7 @@ func ClientWithHeaderReset(c *http.Client, headers map[string]string) *http.Clie
 // if err != nil then resp.Body will need to be closed
 //
 // it will return resp if at all possible, even if err is set
-func (api *Client) Call(opts *Opts) (resp *http.Response, err error) {
+func (api *Client) Call(ctx context.Context, opts *Opts) (resp *http.Response, err error) {
 	api.mu.RLock()
 	defer api.mu.RUnlock()
 	if opts == nil {
@@ -215,6 +217,7 @@ func (api *Client) Call(opts *Opts) (resp *http.Response, err error) {
 	if err != nil {
 		return
 	}
+	req = req.WithContext(ctx)
 	headers := make(map[string]string)
 	// Set default headers
 	for k, v := range api.headers {
@@ -259,8 +262,9 @@ func (api *Client) Call(opts *Opts) (resp *http.Response, err error) {
 			return nil, errors.Wrap(err, "signer failed")
 		}
 	}
+	do := api.chain(c.Do)
 	api.mu.RUnlock()
-	resp, err = c.Do(req)
+	resp, err = do(req)
 	api.mu.RLock()
 	if err != nil {
 		return nil, err
@@ -346,8 +350,8 @@ func MultipartUpload(in io.Reader, params url.Values, contentName, fileName stri
 // parameter name MultipartMetadataName.
 //
 // It will return resp if at all possible, even if err is set
-func (api *Client) CallJSON(opts *Opts, request interface{}, response interface{}) (resp *http.Response, err error) {
-	return api.callCodec(opts, request, response, json.Marshal, DecodeJSON, "application/json")
+func (api *Client) CallJSON(ctx context.Context, opts *Opts, request interface{}, response interface{}) (resp *http.Response, err error) {
+	return api.callCodec(ctx, opts, request, response, json.Marshal, DecodeJSON, "application/json")
 }
 
 // CallXML runs Call and decodes the body as a XML object into response (if not nil)
@@ -357,14 +361,21 @@ func (api *Client) CallJSON(opts *Opts, request interface{}, response interface{
 // See CallJSON for a description of MultipartParams and related opts
 //
 // It will return resp if at all possible, even if err is set
-func (api *Client) CallXML(opts *Opts, request interface{}, response interface{}) (resp *http.Response, err error) {
-	return api.callCodec(opts, request, response, xml.Marshal, DecodeXML, "application/xml")
+func (api *Client) CallXML(ctx context.Context, opts *Opts, request interface{}, response interface{}) (resp *http.Response, err error) {
+	return api.callCodec(ctx, opts, request, response, xml.Marshal, DecodeXML, "application/xml")
 }
 
 type marshalFn func(v interface{}) ([]byte, error)
 type decodeFn func(resp *http.Response, result interface{}) (err error)
 
-func (api *Client) callCodec(opts *Opts, request interface{}, response interface{}, marshal marshalFn, decode decodeFn, contentType string) (resp *http.Response, err error) {
+func (api *Client) callCodec(ctx context.Context, opts *Opts, request interface{}, response interface{}, marshal marshalFn, decode decodeFn, contentType string) (resp *http.Response, err error) {
+	if opts.Body == nil && (response != nil || opts.NoResponse) {
+		// No file data is transferred and the response is read
+		// here, so limit how long the whole call can take
+		var cancel context.CancelFunc
+		ctx, cancel = fs.CallContext(ctx)
+		defer cancel()
+	}
 	var requestBody []byte
 	// Marshal the request if given
 	if request != nil {
@@ -394,7 +405,7 @@ func (api *Client) callCodec(opts *Opts, request interface{}, response interface
 			return nil, err
 		}
 	}
-	resp, err = api.Call(opts)
+	resp, err = api.Call(ctx, opts)
 	if err != nil {
 		return resp, err
 	}
@@ -0,0 +1,8 @@
+// Package service tells the service manager, systemd or the Windows
+// service control manager, what state rclone is in and passes on its
+// requests to stop.
+//
+// Long running commands like mount and serve should call Ready when
+// they are ready to use, stop when the channel returned by Stop is
+// closed and call Stopping when they stop.
+package service
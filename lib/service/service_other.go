@@ -0,0 +1,30 @@
+// Service integration for non-Windows variants
+
+// +build !windows
+
+package service
+
+import (
+	"github.com/okzk/sdnotify"
+	"github.com/pkg/errors"
+)
+
+// Ready tells the service manager that rclone is ready to use
+func Ready() error {
+	if err := sdnotify.SdNotifyReady(); err != nil && err != sdnotify.SdNotifyNoSocket {
+		return errors.Wrap(err, "failed to notify systemd")
+	}
+	return nil
+}
+
+// Stopping tells the service manager that rclone is stopping
+func Stopping() {
+	_ = sdnotify.SdNotifyStopping()
+}
+
+// Stop returns a channel which is closed when the service manager
+// asks rclone to stop.  Systemd does this with signals so this
+// channel is never closed.
+func Stop() <-chan struct{} {
+	return nil
+}
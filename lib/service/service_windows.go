@@ -0,0 +1,122 @@
+// Service integration for Windows
+
+// +build windows
+
+package service
+
+import (
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"golang.org/x/sys/windows/svc"
+)
+
+// Globals
+var (
+	readyOnce sync.Once
+	doneOnce  sync.Once
+	stopOnce  sync.Once
+	isService bool
+	ready     = make(chan struct{}) // closed when rclone is ready
+	stop      = make(chan struct{}) // closed when the service manager asks rclone to stop
+	done      = make(chan struct{}) // closed when rclone is stopping
+	finished  = make(chan struct{}) // closed when svc.Run has returned
+)
+
+// errorFailedServiceControllerConnect is returned by svc.Run if
+// rclone wasn't started by the service manager
+const errorFailedServiceControllerConnect = syscall.Errno(1063)
+
+// handler reports the state of rclone to the service manager
+type handler struct{}
+
+// Execute is called by svc.Run when the service starts
+func (handler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown
+	status <- svc.Status{State: svc.StartPending}
+	readyChan := ready
+	for {
+		select {
+		case <-readyChan:
+			status <- svc.Status{State: svc.Running, Accepts: accepts}
+			readyChan = nil
+		case <-done:
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				stopOnce.Do(func() { close(stop) })
+			}
+		}
+	}
+}
+
+// The service manager gives a service 30 seconds to connect to it
+// after starting the process, so this is done straight away rather
+// than waiting for the command to be ready.
+func init() {
+	start()
+}
+
+// start connects to the service manager if rclone is running as a
+// Windows service.  It reports that rclone is starting until Ready is
+// called.
+func start() {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		fs.Errorf(nil, "Failed to find out if running as a Windows service: %v", err)
+		return
+	}
+	if interactive {
+		return
+	}
+	isService = true
+	go func() {
+		defer close(finished)
+		err := svc.Run("rclone", handler{})
+		if err == errorFailedServiceControllerConnect {
+			// Not started by the service manager, eg from a scheduled task
+			fs.Debugf(nil, "Not running as a Windows service")
+		} else if err != nil {
+			fs.Errorf(nil, "Windows service failed: %v", err)
+		}
+	}()
+}
+
+// Ready tells the service manager that rclone is ready to use, so it
+// reports it as running
+func Ready() error {
+	if isService {
+		readyOnce.Do(func() { close(ready) })
+	}
+	return nil
+}
+
+// Stopping tells the service manager that rclone is stopping
+func Stopping() {
+	if !isService {
+		return
+	}
+	doneOnce.Do(func() { close(done) })
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		fs.Errorf(nil, "Timed out waiting for the Windows service to stop")
+	}
+}
+
+// Stop returns a channel which is closed when the service manager
+// asks rclone to stop.  It is never closed if rclone isn't running as
+// a Windows service.
+func Stop() <-chan struct{} {
+	if !isService {
+		return nil
+	}
+	return stop
+}
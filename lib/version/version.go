@@ -0,0 +1,64 @@
+// Package version adds and removes the version suffixes which are
+// used to show old versions of objects in listings, eg
+//
+//	potato.txt -> potato-v2001-02-03-040506-123.txt
+//
+// The suffix is the time the version was made in UTC to millisecond
+// precision and is inserted before the extension.
+package version
+
+import (
+	"path"
+	"strings"
+	"time"
+)
+
+const versionFormat = "-v2006-01-02-150405.000"
+
+// Add adds the time t as a version string into the remote passed in
+func Add(remote string, t time.Time) string {
+	ext := path.Ext(remote)
+	base := remote[:len(remote)-len(ext)]
+	s := t.UTC().Format(versionFormat)
+	// Replace the '.' with a '-'
+	s = strings.Replace(s, ".", "-", -1)
+	return base + s + ext
+}
+
+// Remove removes the version string from the remote passed in.
+//
+// It returns the time of the version and the remote without the
+// version string, or a zero time and the remote unchanged if it
+// doesn't have a version string.
+func Remove(remote string) (t time.Time, newRemote string) {
+	newRemote = remote
+	ext := path.Ext(remote)
+	base := remote[:len(remote)-len(ext)]
+	if len(base) < len(versionFormat) {
+		return
+	}
+	versionStart := len(base) - len(versionFormat)
+	// Check it ends in -xxx
+	if base[len(base)-4] != '-' {
+		return
+	}
+	// Replace with .xxx for parsing
+	base = base[:len(base)-4] + "." + base[len(base)-3:]
+	newT, err := time.Parse(versionFormat, base[versionStart:])
+	if err != nil {
+		return
+	}
+	return newT, base[:versionStart] + ext
+}
+
+// Match returns true if the remote has a version string
+func Match(remote string) bool {
+	t, _ := Remove(remote)
+	return !t.IsZero()
+}
+
+// Equal returns true if the times a and b are the same version, ie
+// they are equal to the millisecond precision of the version string
+func Equal(a, b time.Time) bool {
+	return a.Truncate(time.Millisecond).Equal(b.Truncate(time.Millisecond))
+}
@@ -0,0 +1,58 @@
+package version
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	emptyT time.Time
+	t0     = time.Date(1970, 1, 1, 1, 1, 1, 123456789, time.UTC)
+	t0r    = time.Date(1970, 1, 1, 1, 1, 1, 123000000, time.UTC)
+	t1     = time.Date(2001, 2, 3, 4, 5, 6, 123000000, time.UTC)
+)
+
+func TestAdd(t *testing.T) {
+	for _, test := range []struct {
+		t        time.Time
+		in       string
+		expected string
+	}{
+		{t0, "potato.txt", "potato-v1970-01-01-010101-123.txt"},
+		{t1, "potato", "potato-v2001-02-03-040506-123"},
+		{t1, "", "-v2001-02-03-040506-123"},
+		{t1, "dir.d/potato", "dir.d/potato-v2001-02-03-040506-123"},
+		{t1.In(time.FixedZone("X", 3600)), "potato", "potato-v2001-02-03-040506-123"},
+	} {
+		actual := Add(test.in, test.t)
+		assert.Equal(t, test.expected, actual, test.in)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	for _, test := range []struct {
+		in             string
+		expectedT      time.Time
+		expectedRemote string
+	}{
+		{"potato.txt", emptyT, "potato.txt"},
+		{"potato-v1970-01-01-010101-123.txt", t0r, "potato.txt"},
+		{"potato-v2001-02-03-040506-123", t1, "potato"},
+		{"-v2001-02-03-040506-123", t1, ""},
+		{"potato-v2A01-02-03-040506-123", emptyT, "potato-v2A01-02-03-040506-123"},
+		{"potato-v2001-02-03-040506=123", emptyT, "potato-v2001-02-03-040506=123"},
+	} {
+		actualT, actualRemote := Remove(test.in)
+		assert.Equal(t, test.expectedT, actualT, test.in)
+		assert.Equal(t, test.expectedRemote, actualRemote, test.in)
+		assert.Equal(t, !test.expectedT.IsZero(), Match(test.in), test.in)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	assert.True(t, Equal(t0, t0r))
+	assert.True(t, Equal(t1, t1))
+	assert.False(t, Equal(t0, t1))
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/djherbis/times"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/lib/evict"
 	"github.com/pkg/errors"
 )
 
@@ -75,6 +76,7 @@ type cache struct {
 type cacheItem struct {
 	opens  int       // number of times file is open
 	atime  time.Time // last time file was accessed
+	size   int64     // size of the file on disk when last walked
 	isFile bool      // if this is a file or a directory
 }
 
@@ -211,6 +213,17 @@ func (c *cache) updateTime(name string, when time.Time) {
 	c.itemMu.Unlock()
 }
 
+// updateSize sets the size of the file name on disk
+//
+// name should be a remote path not an osPath
+func (c *cache) updateSize(name string, size int64) {
+	name = clean(name)
+	c.itemMu.Lock()
+	item, _ := c._get(true, name)
+	item.size = size
+	c.itemMu.Unlock()
+}
+
 // _open marks name as open, must be called with the lock held
 //
 // name should be a remote path not an osPath
@@ -345,6 +358,7 @@ func (c *cache) updateAtimes() error {
 			// Update the atime with that of the file
 			atime := times.Get(fi).AccessTime()
 			c.updateTime(name, atime)
+			c.updateSize(name, fi.Size())
 		} else {
 			c.cacheDir(name)
 		}
@@ -352,7 +366,8 @@ func (c *cache) updateAtimes() error {
 	})
 }
 
-// purgeOld gets rid of any files that are over age
+// purgeOld gets rid of any files that are over age, then any files
+// the eviction policy chooses
 func (c *cache) purgeOld(maxAge time.Duration) {
 	c._purgeOld(maxAge, c.remove, c.removeDir)
 }
@@ -373,6 +388,7 @@ func (c *cache) _purgeOld(maxAge time.Duration, remove func(name string), remove
 			}
 		}
 	}
+	c._evict(remove)
 	// now find any empty directories
 	var dirs []string
 	for name, item := range c.item {
@@ -391,6 +407,50 @@ func (c *cache) _purgeOld(maxAge time.Duration, remove func(name string), remove
 	}
 }
 
+// policy returns the eviction policy set by the options
+func (c *cache) policy() *evict.Policy {
+	return &evict.Policy{
+		MaxSize:      int64(c.opt.CacheMaxSize),
+		MinFreeSpace: int64(c.opt.CacheMinFreeSpace),
+		Pin:          c.opt.CachePin,
+	}
+}
+
+// _evict removes the least recently used files which aren't open or
+// pinned until the cache is within the size and free space limits
+//
+// must be called with itemMu held
+func (c *cache) _evict(remove func(name string)) {
+	policy := c.policy()
+	if policy.MaxSize <= 0 && policy.MinFreeSpace <= 0 {
+		return
+	}
+	free := int64(-1)
+	if policy.MinFreeSpace > 0 {
+		var err error
+		free, err = evict.FreeSpace(c.root)
+		if err != nil {
+			fs.Errorf(nil, "Ignoring --vfs-cache-min-free-space: %v", err)
+		}
+	}
+	var items []evict.Item
+	for name, item := range c.item {
+		if item.isFile {
+			items = append(items, evict.Item{
+				Name:  name,
+				Size:  item.size,
+				ATime: item.atime,
+				InUse: item.opens > 0,
+			})
+		}
+	}
+	for _, item := range policy.Choose(items, free) {
+		fs.Debugf(item.Name, "Evicting from cache (size %v)", fs.SizeSuffix(item.Size))
+		remove(item.Name)
+		delete(c.item, item.Name)
+	}
+}
+
 // clean empties the cache of stuff if it can
 func (c *cache) clean() {
 	// Cache may be empty so end
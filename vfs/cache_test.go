@@ -460,3 +460,39 @@ func TestCachePurgeOld(t *testing.T) {
 
 	assert.Equal(t, []string(nil), itemAsString(c))
 }
+
+func TestCachePurgeEvict(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	opt := DefaultOpt
+	opt.CacheMaxSize = 300
+	opt.CachePin = []string{"pinned"}
+	c, err := newCache(ctx, r.Fremote, &opt)
+	require.NoError(t, err)
+
+	var removed []string
+	removeFile := func(name string) {
+		removed = append(removed, name)
+	}
+	removeDir := func(name string) bool {
+		return false
+	}
+
+	t0 := time.Now().Add(-time.Minute)
+	for i, name := range []string{"pinned/old", "old", "open", "new", "newer"} {
+		c.updateTime(name, t0.Add(time.Duration(i)*time.Second))
+		c.updateSize(name, 100)
+	}
+	c.open("open")
+
+	c._purgeOld(time.Hour, removeFile, removeDir)
+	assert.Equal(t, []string{"old", "new"}, removed)
+
+	removed = nil
+	c._purgeOld(time.Hour, removeFile, removeDir)
+	assert.Equal(t, []string(nil), removed)
+}
@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"context"
 	"os"
 	"path"
 	"sort"
@@ -168,7 +169,7 @@ func (d *Dir) delObject(leaf string) {
 }
 
 // read the directory and sets d.items - must be called with the lock held
-func (d *Dir) _readDir() error {
+func (d *Dir) _readDir(ctx context.Context) error {
 	when := time.Now()
 	if d.read.IsZero() {
 		// fs.Debugf(d.path, "Reading directory")
@@ -179,7 +180,7 @@ func (d *Dir) _readDir() error {
 		}
 		fs.Debugf(d.path, "Re-reading directory (%v old)", age)
 	}
-	entries, err := list.DirSorted(d.f, false, d.path)
+	entries, err := list.DirSorted(ctx, d.f, false, d.path)
 	if err == fs.ErrorDirNotFound {
 		// We treat directory not found as empty because we
 		// create directories on the fly
@@ -231,10 +232,10 @@ func (d *Dir) _readDir() error {
 // stat a single item in the directory
 //
 // returns ENOENT if not found.
-func (d *Dir) stat(leaf string) (Node, error) {
+func (d *Dir) stat(ctx context.Context, leaf string) (Node, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	err := d._readDir()
+	err := d._readDir(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -246,10 +247,10 @@ func (d *Dir) stat(leaf string) (Node, error) {
 }
 
 // Check to see if a directory is empty
-func (d *Dir) isEmpty() (bool, error) {
+func (d *Dir) isEmpty(ctx context.Context) (bool, error) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	err := d._readDir()
+	err := d._readDir(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -286,7 +287,7 @@ func (d *Dir) SetModTime(modTime time.Time) error {
 // Stat need not to handle the names "." and "..".
 func (d *Dir) Stat(name string) (node Node, err error) {
 	// fs.Debugf(path, "Dir.Stat")
-	node, err = d.stat(name)
+	node, err = d.stat(context.TODO(), name)
 	if err != nil {
 		if err != ENOENT {
 			fs.Errorf(d, "Dir.Stat error: %v", err)
@@ -302,7 +303,7 @@ func (d *Dir) ReadDirAll() (items Nodes, err error) {
 	// fs.Debugf(d.path, "Dir.ReadDirAll")
 	d.mu.Lock()
 	defer d.mu.Unlock()
-	err = d._readDir()
+	err = d._readDir(context.TODO())
 	if err != nil {
 		fs.Debugf(d.path, "Dir.ReadDirAll error: %v", err)
 		return nil, err
@@ -345,7 +346,7 @@ func (d *Dir) Mkdir(name string) (*Dir, error) {
 	}
 	path := path.Join(d.path, name)
 	// fs.Debugf(path, "Dir.Mkdir")
-	err := d.f.Mkdir(path)
+	err := d.f.Mkdir(context.TODO(), path)
 	if err != nil {
 		fs.Errorf(d, "Dir.Mkdir failed to create directory: %v", err)
 		return nil, err
@@ -363,7 +364,7 @@ func (d *Dir) Remove() error {
 		return EROFS
 	}
 	// Check directory is empty first
-	empty, err := d.isEmpty()
+	empty, err := d.isEmpty(context.TODO())
 	if err != nil {
 		fs.Errorf(d, "Dir.Remove dir error: %v", err)
 		return err
@@ -373,7 +374,7 @@ func (d *Dir) Remove() error {
 		return ENOTEMPTY
 	}
 	// remove directory
-	err = d.f.Rmdir(d.path)
+	err = d.f.Rmdir(context.TODO(), d.path)
 	if err != nil {
 		fs.Errorf(d, "Dir.Remove failed to remove directory: %v", err)
 		return err
@@ -414,12 +415,12 @@ func (d *Dir) DirEntry() (entry fs.DirEntry) {
 // RemoveName removes the entry with the given name from the receiver,
 // which must be a directory.  The entry to be removed may correspond
 // to a file (unlink) or to a directory (rmdir).
-func (d *Dir) RemoveName(name string) error {
+func (d *Dir) RemoveName(ctx context.Context, name string) error {
 	if d.vfs.Opt.ReadOnly {
 		return EROFS
 	}
 	// fs.Debugf(path, "Dir.Remove")
-	node, err := d.stat(name)
+	node, err := d.stat(ctx, name)
 	if err != nil {
 		fs.Errorf(d, "Dir.Remove error: %v", err)
 		return err
@@ -435,7 +436,7 @@ func (d *Dir) Rename(oldName, newName string, destDir *Dir) error {
 	oldPath := path.Join(d.path, oldName)
 	newPath := path.Join(destDir.path, newName)
 	// fs.Debugf(oldPath, "Dir.Rename to %q", newPath)
-	oldNode, err := d.stat(oldName)
+	oldNode, err := d.stat(context.TODO(), oldName)
 	if err != nil {
 		fs.Errorf(oldPath, "Dir.Rename error: %v", err)
 		return err
@@ -454,7 +455,7 @@ func (d *Dir) Rename(oldName, newName string, destDir *Dir) error {
 			fs.Errorf(oldPath, "Dir.Rename error: %v", err)
 			return err
 		}
-		newObject, err := doMove(oldObject, newPath)
+		newObject, err := doMove(context.TODO(), oldObject, newPath)
 		if err != nil {
 			fs.Errorf(oldPath, "Dir.Rename error: %v", err)
 			return err
@@ -475,7 +476,7 @@ func (d *Dir) Rename(oldName, newName string, destDir *Dir) error {
 		}
 		srcRemote := x.Remote()
 		dstRemote := newPath
-		err = doDirMove(d.f, srcRemote, dstRemote)
+		err = doDirMove(context.TODO(), d.f, srcRemote, dstRemote)
 		if err != nil {
 			fs.Errorf(oldPath, "Dir.Rename error: %v", err)
 			return err
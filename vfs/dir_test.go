@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -403,7 +404,7 @@ func TestDirRemoveName(t *testing.T) {
 	defer r.Finalise()
 	vfs, dir, _ := dirCreate(t, r)
 
-	err := dir.RemoveName("file1")
+	err := dir.RemoveName(context.Background(), "file1")
 	require.NoError(t, err)
 	checkListing(t, dir, []string(nil))
 	root, err := vfs.Root()
@@ -415,7 +416,7 @@ func TestDirRemoveName(t *testing.T) {
 
 	// read only check
 	vfs.Opt.ReadOnly = true
-	err = dir.RemoveName("potato")
+	err = dir.RemoveName(context.Background(), "potato")
 	assert.Equal(t, EROFS, err)
 }
 
@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"context"
 	"os"
 	"path"
 	"sync"
@@ -234,7 +235,7 @@ func (f *File) SetModTime(modTime time.Time) error {
 
 	// Only update the ModTime when there are no writers, setObject will do it
 	if f.o != nil && len(f.writers) == 0 && !f.readWriterClosing {
-		return f.applyPendingModTime()
+		return f.applyPendingModTime(context.TODO())
 	}
 
 	// queue up for later, hoping f.o becomes available
@@ -242,7 +243,7 @@ func (f *File) SetModTime(modTime time.Time) error {
 }
 
 // call with the mutex held
-func (f *File) applyPendingModTime() error {
+func (f *File) applyPendingModTime(ctx context.Context) error {
 	defer func() { f.pendingModTime = time.Time{} }()
 
 	if f.pendingModTime.IsZero() {
@@ -253,7 +254,7 @@ func (f *File) applyPendingModTime() error {
 		return errors.New("Cannot apply ModTime, file object is not available")
 	}
 
-	err := f.o.SetModTime(f.pendingModTime)
+	err := f.o.SetModTime(ctx, f.pendingModTime)
 	switch err {
 	case nil:
 		fs.Debugf(f.o, "File.applyPendingModTime OK")
@@ -273,11 +274,11 @@ func (f *File) setSize(n int64) {
 }
 
 // Update the object when written and add it to the directory
-func (f *File) setObject(o fs.Object) {
+func (f *File) setObject(ctx context.Context, o fs.Object) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	f.o = o
-	_ = f.applyPendingModTime()
+	_ = f.applyPendingModTime(ctx)
 	f.d.addObject(f)
 }
 
@@ -360,14 +361,14 @@ func (f *File) openWrite(flags int) (fh *WriteFileHandle, err error) {
 // openRW open the file for read and write using a temporay file
 //
 // It uses the open flags passed in.
-func (f *File) openRW(flags int) (fh *RWFileHandle, err error) {
+func (f *File) openRW(ctx context.Context, flags int) (fh *RWFileHandle, err error) {
 	// FIXME chunked
 	if flags&accessModeMask != os.O_RDONLY && f.d.vfs.Opt.ReadOnly {
 		return nil, EROFS
 	}
 	// fs.Debugf(o, "File.openRW")
 
-	fh, err = newRWFileHandle(f.d, f, f.Path(), flags)
+	fh, err = newRWFileHandle(ctx, f.d, f, f.Path(), flags)
 	if err != nil {
 		fs.Errorf(f, "File.openRW failed: %v", err)
 		return nil, err
@@ -390,7 +391,7 @@ func (f *File) Remove() error {
 		return EROFS
 	}
 	if f.o != nil {
-		err := f.o.Remove()
+		err := f.o.Remove(context.TODO())
 		if err != nil {
 			fs.Errorf(f, "File.Remove file error: %v", err)
 			return err
@@ -482,10 +483,10 @@ func (f *File) Open(flags int) (fd Handle, err error) {
 	// Open the correct sort of handle
 	CacheMode := f.d.vfs.Opt.CacheMode
 	if CacheMode >= CacheModeMinimal && f.d.vfs.cache.opens(f.Path()) > 0 {
-		fd, err = f.openRW(flags)
+		fd, err = f.openRW(context.TODO(), flags)
 	} else if read && write {
 		if CacheMode >= CacheModeMinimal {
-			fd, err = f.openRW(flags)
+			fd, err = f.openRW(context.TODO(), flags)
 		} else {
 			// Open write only and hope the user doesn't
 			// want to read.  If they do they will get an
@@ -494,13 +495,13 @@ func (f *File) Open(flags int) (fd Handle, err error) {
 		}
 	} else if write {
 		if CacheMode >= CacheModeWrites {
-			fd, err = f.openRW(flags)
+			fd, err = f.openRW(context.TODO(), flags)
 		} else {
 			fd, err = f.openWrite(flags)
 		}
 	} else if read {
 		if CacheMode >= CacheModeFull {
-			fd, err = f.openRW(flags)
+			fd, err = f.openRW(context.TODO(), flags)
 		} else {
 			fd, err = f.openRead()
 		}
@@ -43,7 +43,10 @@ may find that you need one or the other or both.
 
     --cache-dir string                   Directory rclone will use for caching.
     --vfs-cache-max-age duration         Max age of objects in the cache. (default 1h0m0s)
+    --vfs-cache-max-size int             Max total size of objects in the cache. (default off)
+    --vfs-cache-min-free-space int       Evict objects from the cache until the disk has this much free. (default off)
     --vfs-cache-mode string              Cache mode off|minimal|writes|full (default "off")
+    --vfs-cache-pin stringArray          Never evict objects matching this glob from the cache.
     --vfs-cache-poll-interval duration   Interval to poll the cache for stale objects. (default 1m0s)
 
 If run with ` + "`-vv`" + ` rclone will print the location of the file cache.  The
@@ -51,6 +54,15 @@ files are stored in the user cache file area which is OS dependent but
 can be controlled with ` + "`--cache-dir`" + ` or setting the appropriate
 environment variable.
 
+Every ` + "`--vfs-cache-poll-interval`" + ` the cache removes files which
+haven't been accessed for ` + "`--vfs-cache-max-age`" + `.  It then removes
+the least recently accessed files until the cache is smaller than
+` + "`--vfs-cache-max-size`" + ` and the disk holding the cache has at least
+` + "`--vfs-cache-min-free-space`" + ` free, so a mount can't fill up the
+local disk.  Files which are open are never removed, nor are files
+matching a ` + "`--vfs-cache-pin`" + ` glob such as ` + "`--vfs-cache-pin \"photos/*\"`" + `.
+A glob matching a directory pins everything inside it.
+
 The cache has 4 different modes selected by ` + "`--vfs-cache-mode`" + `.
 The higher the cache mode the more compatible rclone becomes at the
 cost of using disk space.
@@ -115,4 +127,20 @@ This mode should support all normal file system operations.
 
 If an upload or download fails it will be retried up to
 --low-level-retries times.
+
+### VFS Profiles
+
+Rather than tuning the flags above one by one you can choose a preset
+with ` + "`--vfs-profile`" + `.  This sets any of the flags below which
+you haven't given on the command line - flags you give explicitly
+always win.
+
+  * ` + "`--vfs-profile media`" + ` - streaming audio and video
+    * ` + "`--vfs-cache-mode off --dir-cache-time 1h --buffer-size 64M --max-read-ahead 1M`" + `
+  * ` + "`--vfs-profile backup`" + ` - a target for backup programs
+    * ` + "`--vfs-cache-mode writes --dir-cache-time 1m --vfs-cache-max-age 1h --buffer-size 16M`" + `
+  * ` + "`--vfs-profile general`" + ` - general use by any application
+    * ` + "`--vfs-cache-mode writes --dir-cache-time 5m --vfs-cache-max-age 1h --buffer-size 32M --max-read-ahead 256k`" + `
+
+Flags a command doesn't have are left alone.
 `
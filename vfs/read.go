@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"context"
 	"io"
 	"os"
 	"sync"
@@ -59,16 +60,16 @@ func newReadFileHandle(f *File) (*ReadFileHandle, error) {
 
 // openPending opens the file if there is a pending open
 // call with the lock held
-func (fh *ReadFileHandle) openPending() (err error) {
+func (fh *ReadFileHandle) openPending(ctx context.Context) (err error) {
 	if fh.opened {
 		return nil
 	}
 	o := fh.file.getObject()
-	r, err := o.Open()
+	r, err := accounting.Open(ctx, o)
 	if err != nil {
 		return err
 	}
-	fh.r = accounting.NewAccount(r, o).WithBuffer() // account the transfer
+	fh.r = r.WithBuffer() // buffer the transfer
 	fh.opened = true
 	accounting.Stats.Transferring(o.Remote())
 	return nil
@@ -99,7 +100,7 @@ func (fh *ReadFileHandle) Node() Node {
 // if reopen is true, then we won't attempt to use an io.Seeker interface
 //
 // Must be called with fh.mu held
-func (fh *ReadFileHandle) seek(offset int64, reopen bool) (err error) {
+func (fh *ReadFileHandle) seek(ctx context.Context, offset int64, reopen bool) (err error) {
 	if fh.noSeek {
 		return ESPIPE
 	}
@@ -124,7 +125,7 @@ func (fh *ReadFileHandle) seek(offset int64, reopen bool) (err error) {
 		}
 		// re-open with a seek
 		o := fh.file.getObject()
-		r, err = o.Open(&fs.SeekOption{Offset: offset})
+		r, err = o.Open(ctx, &fs.SeekOption{Offset: offset})
 		if err != nil {
 			fs.Debugf(fh.remote, "ReadFileHandle.Read seek failed: %v", err)
 			return err
@@ -181,12 +182,12 @@ func (fh *ReadFileHandle) Seek(offset int64, whence int) (n int64, err error) {
 func (fh *ReadFileHandle) ReadAt(p []byte, off int64) (n int, err error) {
 	fh.mu.Lock()
 	defer fh.mu.Unlock()
-	return fh.readAt(p, off)
+	return fh.readAt(context.TODO(), p, off)
 }
 
 // Implementation of ReadAt - call with lock held
-func (fh *ReadFileHandle) readAt(p []byte, off int64) (n int, err error) {
-	err = fh.openPending() // FIXME pending open could be more efficient in the presense of seek (and retries)
+func (fh *ReadFileHandle) readAt(ctx context.Context, p []byte, off int64) (n int, err error) {
+	err = fh.openPending(ctx) // FIXME pending open could be more efficient in the presense of seek (and retries)
 	if err != nil {
 		return 0, err
 	}
@@ -213,7 +214,7 @@ func (fh *ReadFileHandle) readAt(p []byte, off int64) (n int, err error) {
 				return 0, io.EOF
 			}
 			// Otherwise do the seek
-			err = fh.seek(off, doReopen)
+			err = fh.seek(ctx, off, doReopen)
 		} else {
 			err = nil
 		}
@@ -312,7 +313,7 @@ func (fh *ReadFileHandle) Read(p []byte) (n int, err error) {
 	if fh.roffset >= fh.size {
 		return 0, io.EOF
 	}
-	n, err = fh.readAt(p, fh.roffset)
+	n, err = fh.readAt(context.TODO(), p, fh.roffset)
 	fh.roffset += int64(n)
 	return n, err
 }
@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -43,7 +44,7 @@ var (
 	_ io.Closer   = (*RWFileHandle)(nil)
 )
 
-func newRWFileHandle(d *Dir, f *File, remote string, flags int) (fh *RWFileHandle, err error) {
+func newRWFileHandle(ctx context.Context, d *Dir, f *File, remote string, flags int) (fh *RWFileHandle, err error) {
 	// if O_CREATE and O_EXCL are set and if path already exists, then return EEXIST
 	if flags&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL && f.exists() {
 		return nil, EEXIST
@@ -73,7 +74,7 @@ func newRWFileHandle(d *Dir, f *File, remote string, flags int) (fh *RWFileHandl
 
 	// truncate or create files immediately to prepare the cache
 	if fh.flags&os.O_TRUNC != 0 || fh.flags&(os.O_CREATE) != 0 && !f.exists() {
-		if err := fh.openPending(false); err != nil {
+		if err := fh.openPending(ctx, false); err != nil {
 			fh.file.delWriter(fh, false)
 			return nil, err
 		}
@@ -83,10 +84,10 @@ func newRWFileHandle(d *Dir, f *File, remote string, flags int) (fh *RWFileHandl
 }
 
 // copy an object to or from the remote while accounting for it
-func copyObj(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
-	if operations.NeedTransfer(dst, src) {
+func copyObj(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+	if operations.NeedTransfer(ctx, dst, src) {
 		accounting.Stats.Transferring(src.Remote())
-		newDst, err = operations.Copy(f, dst, remote, src)
+		newDst, err = operations.Copy(ctx, f, dst, remote, src)
 		accounting.Stats.DoneTransferring(src.Remote(), err == nil)
 	} else {
 		newDst = dst
@@ -97,7 +98,7 @@ func copyObj(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Ob
 // openPending opens the file if there is a pending open
 //
 // call with the lock held
-func (fh *RWFileHandle) openPending(truncate bool) (err error) {
+func (fh *RWFileHandle) openPending(ctx context.Context, truncate bool) (err error) {
 	if fh.opened {
 		return nil
 	}
@@ -114,9 +115,9 @@ func (fh *RWFileHandle) openPending(truncate bool) (err error) {
 		// If the remote object exists AND its cached file exists locally AND there are no
 		// other RW handles with it open, then attempt to update it.
 		if o != nil && fh.file.rwOpens() == 0 {
-			cacheObj, err := fh.d.vfs.cache.f.NewObject(fh.remote)
+			cacheObj, err := fh.d.vfs.cache.f.NewObject(ctx, fh.remote)
 			if err == nil && cacheObj != nil {
-				cacheObj, err = copyObj(fh.d.vfs.cache.f, cacheObj, fh.remote, o)
+				cacheObj, err = copyObj(ctx, fh.d.vfs.cache.f, cacheObj, fh.remote, o)
 				if err != nil {
 					return errors.Wrap(err, "open RW handle failed to update cached file")
 				}
@@ -129,7 +130,7 @@ func (fh *RWFileHandle) openPending(truncate bool) (err error) {
 			// cache file does not exist, so need to fetch it if we have an object to fetch
 			// it from
 			if o != nil {
-				_, err = copyObj(fh.d.vfs.cache.f, nil, fh.remote, o)
+				_, err = copyObj(ctx, fh.d.vfs.cache.f, nil, fh.remote, o)
 				if err != nil {
 					cause := errors.Cause(err)
 					if cause != fs.ErrorObjectNotFound && cause != fs.ErrorDirNotFound {
@@ -270,7 +271,7 @@ func (fh *RWFileHandle) close() (err error) {
 	// If we aren't creating or truncating the file then
 	// we haven't modified it so don't need to transfer it
 	if fh.flags&(os.O_CREATE|os.O_TRUNC) != 0 {
-		if err := fh.openPending(false); err != nil {
+		if err := fh.openPending(context.TODO(), false); err != nil {
 			return err
 		}
 	}
@@ -295,20 +296,20 @@ func (fh *RWFileHandle) close() (err error) {
 
 	if copy {
 		// Transfer the temp file to the remote
-		cacheObj, err := fh.d.vfs.cache.f.NewObject(fh.remote)
+		cacheObj, err := fh.d.vfs.cache.f.NewObject(context.TODO(), fh.remote)
 		if err != nil {
 			err = errors.Wrap(err, "failed to find cache file")
 			fs.Errorf(fh.logPrefix(), "%v", err)
 			return err
 		}
 
-		o, err := copyObj(fh.d.vfs.f, fh.file.getObject(), fh.remote, cacheObj)
+		o, err := copyObj(context.TODO(), fh.d.vfs.f, fh.file.getObject(), fh.remote, cacheObj)
 		if err != nil {
 			err = errors.Wrap(err, "failed to transfer file from cache to remote")
 			fs.Errorf(fh.logPrefix(), "%v", err)
 			return err
 		}
-		fh.file.setObject(o)
+		fh.file.setObject(context.TODO(), o)
 		fs.Debugf(o, "transferred to remote")
 	}
 
@@ -400,7 +401,7 @@ func (fh *RWFileHandle) Stat() (os.FileInfo, error) {
 
 // readFn is a general purpose read function - pass in a closure to do
 // the actual read
-func (fh *RWFileHandle) readFn(read func() (int, error)) (n int, err error) {
+func (fh *RWFileHandle) readFn(ctx context.Context, read func() (int, error)) (n int, err error) {
 	fh.mu.Lock()
 	defer fh.mu.Unlock()
 	if fh.closed {
@@ -409,7 +410,7 @@ func (fh *RWFileHandle) readFn(read func() (int, error)) (n int, err error) {
 	if fh.flags&accessModeMask == os.O_WRONLY {
 		return 0, EBADF
 	}
-	if err = fh.openPending(false); err != nil {
+	if err = fh.openPending(ctx, false); err != nil {
 		return n, err
 	}
 	return read()
@@ -417,14 +418,14 @@ func (fh *RWFileHandle) readFn(read func() (int, error)) (n int, err error) {
 
 // Read bytes from the file
 func (fh *RWFileHandle) Read(b []byte) (n int, err error) {
-	return fh.readFn(func() (int, error) {
+	return fh.readFn(context.TODO(), func() (int, error) {
 		return fh.File.Read(b)
 	})
 }
 
 // ReadAt bytes from the file at off
 func (fh *RWFileHandle) ReadAt(b []byte, off int64) (n int, err error) {
-	return fh.readFn(func() (int, error) {
+	return fh.readFn(context.TODO(), func() (int, error) {
 		return fh.File.ReadAt(b, off)
 	})
 }
@@ -439,7 +440,7 @@ func (fh *RWFileHandle) Seek(offset int64, whence int) (ret int64, err error) {
 	if !fh.opened && offset == 0 && whence != 2 {
 		return 0, nil
 	}
-	if err = fh.openPending(false); err != nil {
+	if err = fh.openPending(context.TODO(), false); err != nil {
 		return ret, err
 	}
 	return fh.File.Seek(offset, whence)
@@ -448,7 +449,7 @@ func (fh *RWFileHandle) Seek(offset int64, whence int) (ret int64, err error) {
 // writeFn general purpose write call
 //
 // Pass a closure to do the actual write
-func (fh *RWFileHandle) writeFn(write func() error) (err error) {
+func (fh *RWFileHandle) writeFn(ctx context.Context, write func() error) (err error) {
 	fh.mu.Lock()
 	defer fh.mu.Unlock()
 	if fh.closed {
@@ -457,7 +458,7 @@ func (fh *RWFileHandle) writeFn(write func() error) (err error) {
 	if fh.flags&accessModeMask == os.O_RDONLY {
 		return EBADF
 	}
-	if err = fh.openPending(false); err != nil {
+	if err = fh.openPending(ctx, false); err != nil {
 		return err
 	}
 	fh.writeCalled = true
@@ -475,7 +476,7 @@ func (fh *RWFileHandle) writeFn(write func() error) (err error) {
 
 // Write bytes to the file
 func (fh *RWFileHandle) Write(b []byte) (n int, err error) {
-	err = fh.writeFn(func() error {
+	err = fh.writeFn(context.TODO(), func() error {
 		n, err = fh.File.Write(b)
 		return err
 	})
@@ -484,7 +485,7 @@ func (fh *RWFileHandle) Write(b []byte) (n int, err error) {
 
 // WriteAt bytes to the file at off
 func (fh *RWFileHandle) WriteAt(b []byte, off int64) (n int, err error) {
-	err = fh.writeFn(func() error {
+	err = fh.writeFn(context.TODO(), func() error {
 		n, err = fh.File.WriteAt(b, off)
 		return err
 	})
@@ -493,7 +494,7 @@ func (fh *RWFileHandle) WriteAt(b []byte, off int64) (n int, err error) {
 
 // WriteString a string to the file
 func (fh *RWFileHandle) WriteString(s string) (n int, err error) {
-	err = fh.writeFn(func() error {
+	err = fh.writeFn(context.TODO(), func() error {
 		n, err = fh.File.WriteString(s)
 		return err
 	})
@@ -507,7 +508,7 @@ func (fh *RWFileHandle) Truncate(size int64) (err error) {
 	if fh.closed {
 		return ECLOSED
 	}
-	if err = fh.openPending(size == 0); err != nil {
+	if err = fh.openPending(context.TODO(), size == 0); err != nil {
 		return err
 	}
 	fh.changed = true
@@ -48,6 +48,8 @@ var DefaultOpt = Options{
 	CacheMode:         CacheModeOff,
 	CacheMaxAge:       3600 * time.Second,
 	CachePollInterval: 60 * time.Second,
+	CacheMaxSize:      -1,
+	CacheMinFreeSpace: -1,
 }
 
 // Node represents either a directory (*Dir) or a file (*File)
@@ -192,6 +194,9 @@ type Options struct {
 	CacheMode         CacheMode
 	CacheMaxAge       time.Duration
 	CachePollInterval time.Duration
+	CacheMaxSize      fs.SizeSuffix // evict files when the cache is larger than this
+	CacheMinFreeSpace fs.SizeSuffix // evict files when the disk has less free than this
+	CachePin          []string      // globs of files never to evict
 }
 
 // New creates a new VFS and root directory.  If opt is nil, then
@@ -473,7 +478,7 @@ func (vfs *VFS) Statfs() (total, used, free int64) {
 	}
 	if vfs.usageTime.IsZero() || time.Since(vfs.usageTime) >= vfs.Opt.DirCacheTime {
 		var err error
-		vfs.usage, err = doAbout()
+		vfs.usage, err = doAbout(context.TODO())
 		vfs.usageTime = time.Now()
 		if err != nil {
 			fs.Errorf(vfs.f, "Statfs failed: %v", err)
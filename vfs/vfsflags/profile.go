@@ -0,0 +1,114 @@
+// Presets of the VFS flags for common uses
+
+package vfsflags
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/spf13/pflag"
+)
+
+// profileValue is the value a profile sets a flag to
+type profileValue struct {
+	flag  string
+	value string
+}
+
+// Profile is a named set of flag values tuned for a common use of
+// the VFS
+type Profile struct {
+	Name   string
+	Help   string
+	values []profileValue
+}
+
+// Profiles are the presets which can be chosen with --vfs-profile
+var Profiles = []Profile{
+	{
+		Name: "media",
+		Help: "Streaming audio and video which is read from start to end and rarely changes",
+		values: []profileValue{
+			{"vfs-cache-mode", "off"},
+			{"dir-cache-time", "1h"},
+			{"buffer-size", "64M"},
+			{"max-read-ahead", "1M"},
+		},
+	},
+	{
+		Name: "backup",
+		Help: "A target for backup programs which write files, often in place, and read them rarely",
+		values: []profileValue{
+			{"vfs-cache-mode", "writes"},
+			{"dir-cache-time", "1m"},
+			{"vfs-cache-max-age", "1h"},
+			{"buffer-size", "16M"},
+		},
+	},
+	{
+		Name: "general",
+		Help: "General use by applications which read and write files in any way",
+		values: []profileValue{
+			{"vfs-cache-mode", "writes"},
+			{"dir-cache-time", "5m"},
+			{"vfs-cache-max-age", "1h"},
+			{"buffer-size", "32M"},
+			{"max-read-ahead", "256k"},
+		},
+	},
+}
+
+// findProfile returns the profile called name
+func findProfile(name string) (*Profile, error) {
+	var names []string
+	for i := range Profiles {
+		if Profiles[i].Name == name {
+			return &Profiles[i], nil
+		}
+		names = append(names, Profiles[i].Name)
+	}
+	return nil, fmt.Errorf("unknown VFS profile %q - choose one of %s", name, strings.Join(names, ", "))
+}
+
+// apply sets the flags in flagSet the profile has values for, unless
+// they were set on the command line
+func (p *Profile) apply(flagSet *pflag.FlagSet) error {
+	for _, v := range p.values {
+		flag := flagSet.Lookup(v.flag)
+		if flag == nil || flag.Changed {
+			continue
+		}
+		err := flag.Value.Set(v.value)
+		if err != nil {
+			return fmt.Errorf("VFS profile %q: bad value %q for --%s: %v", p.Name, v.value, v.flag, err)
+		}
+		fs.Debugf(nil, "VFS profile %q set --%s %s", p.Name, v.flag, v.value)
+	}
+	return nil
+}
+
+// String returns the flags set by the profile
+func (p *Profile) String() string {
+	var out []string
+	for _, v := range p.values {
+		out = append(out, fmt.Sprintf("--%s %s", v.flag, v.value))
+	}
+	return strings.Join(out, " ")
+}
+
+// applyProfile applies the --vfs-profile if one was given for the
+// command with flagSet
+func applyProfile(flagSet *pflag.FlagSet) {
+	if !flagSet.Changed("vfs-profile") {
+		return
+	}
+	p, err := findProfile(profile)
+	if err == nil {
+		err = p.apply(flagSet)
+	}
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package vfsflags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/vfs"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiles(t *testing.T) {
+	// Check all the profiles have values for the flags AddFlags
+	// makes or the mount and global flags
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddFlags(flagSet)
+	known := map[string]bool{"buffer-size": true, "max-read-ahead": true}
+	for _, p := range Profiles {
+		assert.NotEqual(t, "", p.Help, p.Name)
+		for _, v := range p.values {
+			assert.True(t, known[v.flag] || flagSet.Lookup(v.flag) != nil, "%s: --%s", p.Name, v.flag)
+		}
+	}
+
+	_, err := findProfile("potato")
+	assert.Error(t, err)
+}
+
+func TestProfileApply(t *testing.T) {
+	oldOpt := Opt
+	defer func() { Opt = oldOpt }()
+	Opt = vfs.DefaultOpt
+
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddFlags(flagSet)
+	readAhead := fs.SizeSuffix(0)
+	flags.FVarP(flagSet, &readAhead, "max-read-ahead", "", "")
+	require.NoError(t, flagSet.Parse([]string{"--vfs-profile", "media", "--dir-cache-time", "2m"}))
+
+	p, err := findProfile(profile)
+	require.NoError(t, err)
+	require.NoError(t, p.apply(flagSet))
+
+	assert.Equal(t, vfs.CacheModeOff, Opt.CacheMode)
+	assert.Equal(t, 2*time.Minute, Opt.DirCacheTime) // set on the command line
+	assert.Equal(t, fs.SizeSuffix(1048576), readAhead)
+	assert.False(t, flagSet.Lookup("max-read-ahead").Changed)
+}
@@ -4,12 +4,14 @@ package vfsflags
 import (
 	"github.com/ncw/rclone/fs/config/flags"
 	"github.com/ncw/rclone/vfs"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 // Options set by command line flags
 var (
-	Opt = vfs.DefaultOpt
+	Opt     = vfs.DefaultOpt
+	profile = ""
 )
 
 // AddFlags adds the non filing system specific flags to the command
@@ -23,5 +25,12 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.FVarP(flagSet, &Opt.CacheMode, "vfs-cache-mode", "", "Cache mode off|minimal|writes|full")
 	flags.DurationVarP(flagSet, &Opt.CachePollInterval, "vfs-cache-poll-interval", "", Opt.CachePollInterval, "Interval to poll the cache for stale objects.")
 	flags.DurationVarP(flagSet, &Opt.CacheMaxAge, "vfs-cache-max-age", "", Opt.CacheMaxAge, "Max age of objects in the cache.")
+	flags.FVarP(flagSet, &Opt.CacheMaxSize, "vfs-cache-max-size", "", "Max total size of objects in the cache.")
+	flags.FVarP(flagSet, &Opt.CacheMinFreeSpace, "vfs-cache-min-free-space", "", "Evict objects from the cache until the disk has this much free.")
+	flags.StringArrayVarP(flagSet, &Opt.CachePin, "vfs-cache-pin", "", Opt.CachePin, "Never evict objects matching this glob from the cache.")
+	flags.StringVarP(flagSet, &profile, "vfs-profile", "", profile, "Preset the VFS flags not given for media|backup|general use.")
 	platformFlags(flagSet)
+	cobra.OnInitialize(func() {
+		applyProfile(flagSet)
+	})
 }
@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"context"
 	"io"
 	"os"
 	"sync"
@@ -53,7 +54,7 @@ func (fh *WriteFileHandle) safeToTruncate() bool {
 // openPending opens the file if there is a pending open
 //
 // call with the lock held
-func (fh *WriteFileHandle) openPending() (err error) {
+func (fh *WriteFileHandle) openPending(ctx context.Context) (err error) {
 	if fh.opened {
 		return nil
 	}
@@ -65,7 +66,7 @@ func (fh *WriteFileHandle) openPending() (err error) {
 	pipeReader, fh.pipeWriter = io.Pipe()
 	go func() {
 		// NB Rcat deals with Stats.Transferring etc
-		o, err := operations.Rcat(fh.file.d.f, fh.remote, pipeReader, time.Now())
+		o, err := operations.Rcat(ctx, fh.file.d.f, fh.remote, pipeReader, time.Now())
 		if err != nil {
 			fs.Errorf(fh.remote, "WriteFileHandle.New Rcat failed: %v", err)
 		}
@@ -116,11 +117,11 @@ func (fh *WriteFileHandle) Node() Node {
 func (fh *WriteFileHandle) WriteAt(p []byte, off int64) (n int, err error) {
 	fh.mu.Lock()
 	defer fh.mu.Unlock()
-	return fh.writeAt(p, off)
+	return fh.writeAt(context.TODO(), p, off)
 }
 
 // Implementatino of WriteAt - call with lock held
-func (fh *WriteFileHandle) writeAt(p []byte, off int64) (n int, err error) {
+func (fh *WriteFileHandle) writeAt(ctx context.Context, p []byte, off int64) (n int, err error) {
 	// fs.Debugf(fh.remote, "WriteFileHandle.Write len=%d", len(p))
 	if fh.closed {
 		fs.Errorf(fh.remote, "WriteFileHandle.Write: error: %v", EBADF)
@@ -130,7 +131,7 @@ func (fh *WriteFileHandle) writeAt(p []byte, off int64) (n int, err error) {
 		fs.Errorf(fh.remote, "WriteFileHandle.Write: can't seek in file without --vfs-cache-mode >= writes")
 		return 0, ESPIPE
 	}
-	if err = fh.openPending(); err != nil {
+	if err = fh.openPending(ctx); err != nil {
 		return 0, err
 	}
 	fh.writeCalled = true
@@ -156,7 +157,7 @@ func (fh *WriteFileHandle) Write(p []byte) (n int, err error) {
 	fh.mu.Lock()
 	defer fh.mu.Unlock()
 	// Since we can't seek, just call WriteAt with the current offset
-	return fh.writeAt(p, fh.offset)
+	return fh.writeAt(context.TODO(), p, fh.offset)
 }
 
 // WriteString a string to the file
@@ -189,13 +190,13 @@ func (fh *WriteFileHandle) close() (err error) {
 	if !fh.opened && !fh.safeToTruncate() {
 		return nil
 	}
-	if err = fh.openPending(); err != nil {
+	if err = fh.openPending(context.TODO()); err != nil {
 		return err
 	}
 	writeCloseErr := fh.pipeWriter.Close()
 	err = <-fh.result
 	if err == nil {
-		fh.file.setObject(fh.o)
+		fh.file.setObject(context.TODO(), fh.o)
 		err = writeCloseErr
 	}
 	return err
@@ -235,7 +236,7 @@ func (fh *WriteFileHandle) Flush() error {
 	// will pick it up
 	if !fh.writeCalled {
 		fs.Debugf(fh.remote, "WriteFileHandle.Flush unwritten handle, writing 0 bytes to avoid race conditions")
-		_, err := fh.writeAt([]byte{}, fh.offset)
+		_, err := fh.writeAt(context.TODO(), []byte{}, fh.offset)
 		return err
 	}
 	err := fh.close()